@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	automation_dtos "sensio/domain/automation/dtos"
+	"sensio/domain/automation/usecases"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AutomationRuleController exposes CRUD and dry-run evaluation for automation rules
+type AutomationRuleController struct {
+	useCase *usecases.AutomationRuleUseCase
+}
+
+// Force Swaggo to detect DTOs
+var _ = automation_dtos.AutomationRuleRequestDTO{}
+
+func NewAutomationRuleController(useCase *usecases.AutomationRuleUseCase) *AutomationRuleController {
+	return &AutomationRuleController{
+		useCase: useCase,
+	}
+}
+
+// CreateRule handles POST /api/automations/:terminal_id/rules
+// @Summary Create an automation rule
+// @Description Run scenes or device commands when device status conditions become true
+// @Tags 09. Automations
+// @Accept json
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param rule body automation_dtos.AutomationRuleRequestDTO true "Rule definition"
+// @Success 201 {object} dtos.StandardResponse{data=automation_dtos.AutomationRuleIDResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/automations/{terminal_id}/rules [post]
+func (c *AutomationRuleController) CreateRule(ctx *gin.Context) {
+	var req automation_dtos.AutomationRuleRequestDTO
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.useCase.CreateRule(ctx.Param("terminal_id"), req)
+	if err != nil {
+		respondError(ctx, "CreateRule", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.StandardResponse{
+		Status:  true,
+		Message: "Rule created successfully",
+		Data:    result,
+	})
+}
+
+// ListRules handles GET /api/automations/:terminal_id/rules
+// @Summary List automation rules
+// @Tags 09. Automations
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Success 200 {object} dtos.StandardResponse{data=[]automation_dtos.AutomationRuleResponseDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/automations/{terminal_id}/rules [get]
+func (c *AutomationRuleController) ListRules(ctx *gin.Context) {
+	result, err := c.useCase.ListRules(ctx.Param("terminal_id"))
+	if err != nil {
+		respondError(ctx, "ListRules", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Rules retrieved successfully",
+		Data:    result,
+	})
+}
+
+// GetRule handles GET /api/automations/:terminal_id/rules/:rule_id
+// @Summary Get an automation rule
+// @Tags 09. Automations
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param rule_id path string true "Rule UUID"
+// @Success 200 {object} dtos.StandardResponse{data=automation_dtos.AutomationRuleResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/automations/{terminal_id}/rules/{rule_id} [get]
+func (c *AutomationRuleController) GetRule(ctx *gin.Context) {
+	result, err := c.useCase.GetRule(ctx.Param("terminal_id"), ctx.Param("rule_id"))
+	if err != nil {
+		respondError(ctx, "GetRule", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Rule retrieved successfully",
+		Data:    result,
+	})
+}
+
+// UpdateRule handles PUT /api/automations/:terminal_id/rules/:rule_id
+// @Summary Update an automation rule
+// @Description Replace a rule definition; its debounce state is reset
+// @Tags 09. Automations
+// @Accept json
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param rule_id path string true "Rule UUID"
+// @Param rule body automation_dtos.AutomationRuleRequestDTO true "Rule definition"
+// @Success 200 {object} dtos.StandardResponse{data=automation_dtos.AutomationRuleIDResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/automations/{terminal_id}/rules/{rule_id} [put]
+func (c *AutomationRuleController) UpdateRule(ctx *gin.Context) {
+	var req automation_dtos.AutomationRuleRequestDTO
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.useCase.UpdateRule(ctx.Param("terminal_id"), ctx.Param("rule_id"), req)
+	if err != nil {
+		respondError(ctx, "UpdateRule", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Rule updated successfully",
+		Data:    result,
+	})
+}
+
+// DeleteRule handles DELETE /api/automations/:terminal_id/rules/:rule_id
+// @Summary Delete an automation rule
+// @Tags 09. Automations
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param rule_id path string true "Rule UUID"
+// @Success 200 {object} dtos.StandardResponse "Rule deleted"
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/automations/{terminal_id}/rules/{rule_id} [delete]
+func (c *AutomationRuleController) DeleteRule(ctx *gin.Context) {
+	if err := c.useCase.DeleteRule(ctx.Param("terminal_id"), ctx.Param("rule_id")); err != nil {
+		respondError(ctx, "DeleteRule", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Rule deleted successfully",
+	})
+}
+
+// DryRun handles POST /api/automations/:terminal_id/rules/dry-run
+// @Summary Dry-run an automation rule
+// @Description Evaluate a stored rule (rule_id) or an unsaved definition (rule) against current device statuses, optionally overriding some values. No action is executed.
+// @Tags 09. Automations
+// @Accept json
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param request body automation_dtos.DryRunRequestDTO true "Dry-run request"
+// @Success 200 {object} dtos.StandardResponse{data=automation_dtos.DryRunResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/automations/{terminal_id}/rules/dry-run [post]
+func (c *AutomationRuleController) DryRun(ctx *gin.Context) {
+	var req automation_dtos.DryRunRequestDTO
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.useCase.DryRun(ctx.Param("terminal_id"), req)
+	if err != nil {
+		respondError(ctx, "DryRun", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Rule evaluated successfully",
+		Data:    result,
+	})
+}
+
+func bindJSON(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error",
+			Details: []utils.ValidationErrorDetail{
+				{Field: "payload", Message: "Invalid request body: " + err.Error()},
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func respondError(ctx *gin.Context, op string, err error) {
+	var valErr *utils.ValidationError
+	if errors.As(err, &valErr) {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: valErr.Message,
+			Details: valErr.Details,
+		})
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	if errors.Is(err, usecases.ErrRuleNotFound) {
+		statusCode = http.StatusNotFound
+	} else {
+		utils.LogError("AutomationRuleController.%s: %v", op, err)
+	}
+	ctx.JSON(statusCode, dtos.StandardResponse{
+		Status:  false,
+		Message: http.StatusText(statusCode),
+	})
+}
@@ -0,0 +1,90 @@
+package dtos
+
+// ConditionDTO compares a device status code against a value
+type ConditionDTO struct {
+	DeviceID string      `json:"device_id" example:"bf1234567890abcdef"`
+	Code     string      `json:"code" example:"va_temperature"`
+	Operator string      `json:"operator" example:"gt"` // eq, neq, gt, gte, lt, lte, contains
+	Value    interface{} `json:"value" swaggertype:"object,string"`
+}
+
+// ConditionGroupDTO combines conditions and nested groups with AND/OR
+type ConditionGroupDTO struct {
+	Logic      string              `json:"logic" example:"and"` // and, or
+	Conditions []ConditionDTO      `json:"conditions,omitempty"`
+	Groups     []ConditionGroupDTO `json:"groups,omitempty"`
+}
+
+// RuleActionDTO is an action performed when the rule fires
+type RuleActionDTO struct {
+	Type     string      `json:"type" example:"scene"` // scene, command
+	SceneID  string      `json:"scene_id,omitempty"`
+	DeviceID string      `json:"device_id,omitempty"`
+	Code     string      `json:"code,omitempty"`
+	RemoteID string      `json:"remote_id,omitempty"`
+	Value    interface{} `json:"value,omitempty" swaggertype:"object,string"`
+}
+
+// AutomationRuleRequestDTO for POST/PUT /api/automations/:terminal_id/rules
+type AutomationRuleRequestDTO struct {
+	Name            string            `json:"name" binding:"required" example:"Cool down the living room"`
+	Enabled         *bool             `json:"enabled,omitempty"` // Defaults to true
+	Conditions      ConditionGroupDTO `json:"conditions"`
+	Actions         []RuleActionDTO   `json:"actions"`
+	DebounceSeconds int               `json:"debounce_seconds,omitempty" example:"300"`
+	CooldownSeconds int               `json:"cooldown_seconds,omitempty" example:"1800"`
+}
+
+// AutomationRuleResponseDTO represents a stored rule
+type AutomationRuleResponseDTO struct {
+	ID              string            `json:"id"`
+	TerminalID      string            `json:"terminal_id"`
+	Name            string            `json:"name"`
+	Enabled         bool              `json:"enabled"`
+	Conditions      ConditionGroupDTO `json:"conditions"`
+	Actions         []RuleActionDTO   `json:"actions"`
+	DebounceSeconds int               `json:"debounce_seconds"`
+	CooldownSeconds int               `json:"cooldown_seconds"`
+	LastFiredAt     string            `json:"last_fired_at,omitempty"`
+	LastResult      string            `json:"last_result,omitempty"`
+}
+
+// AutomationRuleIDResponseDTO for returning just the rule ID
+type AutomationRuleIDResponseDTO struct {
+	RuleID string `json:"rule_id"`
+}
+
+// StatusOverrideDTO replaces a stored status value during a dry run
+type StatusOverrideDTO struct {
+	DeviceID string      `json:"device_id"`
+	Code     string      `json:"code"`
+	Value    interface{} `json:"value" swaggertype:"object,string"`
+}
+
+// DryRunRequestDTO for POST /api/automations/:terminal_id/rules/dry-run.
+// Either RuleID references a stored rule or Rule carries an unsaved definition.
+type DryRunRequestDTO struct {
+	RuleID   string                    `json:"rule_id,omitempty"`
+	Rule     *AutomationRuleRequestDTO `json:"rule,omitempty"`
+	Statuses []StatusOverrideDTO       `json:"statuses,omitempty"`
+}
+
+// ConditionResultDTO is the outcome of one condition during a dry run
+type ConditionResultDTO struct {
+	DeviceID string      `json:"device_id"`
+	Code     string      `json:"code"`
+	Operator string      `json:"operator"`
+	Expected interface{} `json:"expected" swaggertype:"object,string"`
+	Actual   *string     `json:"actual"` // nil when the status is unknown
+	Matched  bool        `json:"matched"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// DryRunResponseDTO reports what the engine would do, without executing any action
+type DryRunResponseDTO struct {
+	Matched    bool                 `json:"matched"`
+	WouldFire  bool                 `json:"would_fire"`
+	Reason     string               `json:"reason"`
+	Conditions []ConditionResultDTO `json:"conditions"`
+	Actions    []RuleActionDTO      `json:"actions"`
+}
@@ -0,0 +1,132 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Logical operators for combining conditions
+const (
+	LogicAnd = "and"
+	LogicOr  = "or"
+)
+
+// Comparison operators supported by a Condition
+const (
+	OpEqual          = "eq"
+	OpNotEqual       = "neq"
+	OpGreater        = "gt"
+	OpGreaterOrEqual = "gte"
+	OpLess           = "lt"
+	OpLessOrEqual    = "lte"
+	OpContains       = "contains"
+)
+
+// Action types a rule can perform when it fires
+const (
+	ActionTypeScene   = "scene"   // Run a stored scene of the rule's terminal
+	ActionTypeCommand = "command" // Send a single Tuya command
+)
+
+// Condition compares the current value of a device status code against a constant
+type Condition struct {
+	DeviceID string      `json:"device_id"`
+	Code     string      `json:"code"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+// ConditionGroup combines conditions and nested groups with AND/OR
+type ConditionGroup struct {
+	Logic      string           `json:"logic"`
+	Conditions []Condition      `json:"conditions,omitempty"`
+	Groups     []ConditionGroup `json:"groups,omitempty"`
+}
+
+func (g ConditionGroup) Value() (driver.Value, error) {
+	return json.Marshal(g)
+}
+
+func (g *ConditionGroup) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, g)
+	case string:
+		return json.Unmarshal([]byte(v), g)
+	default:
+		return fmt.Errorf("type assertion to []byte failed")
+	}
+}
+
+// RuleAction is a single effect of a fired rule
+type RuleAction struct {
+	Type     string      `json:"type"`
+	SceneID  string      `json:"scene_id,omitempty"`
+	DeviceID string      `json:"device_id,omitempty"`
+	Code     string      `json:"code,omitempty"`
+	RemoteID string      `json:"remote_id,omitempty"` // For IR devices
+	Value    interface{} `json:"value,omitempty"`
+}
+
+// RuleActions is a slice of RuleAction that implements Scanner and Valuer for GORM
+type RuleActions []RuleAction
+
+func (a RuleActions) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+func (a *RuleActions) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return fmt.Errorf("type assertion to []byte failed")
+	}
+}
+
+// AutomationRule runs actions when device status conditions become true
+type AutomationRule struct {
+	ID              string         `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID      string         `gorm:"type:char(36);not null;index" json:"terminal_id"`
+	Name            string         `gorm:"type:varchar(255);not null" json:"name"`
+	Enabled         bool           `gorm:"not null" json:"enabled"`
+	Conditions      ConditionGroup `gorm:"type:text;not null" json:"conditions"`
+	Actions         RuleActions    `gorm:"type:text;not null" json:"actions"`
+	DebounceSeconds int            `json:"debounce_seconds"` // Conditions must hold this long before firing
+	CooldownSeconds int            `json:"cooldown_seconds"` // Minimum time between two firings
+	LastFiredAt     *time.Time     `json:"last_fired_at,omitempty"`
+	LastResult      string         `gorm:"type:text" json:"last_result,omitempty"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// TableName specifies the table name for the AutomationRule model
+func (AutomationRule) TableName() string {
+	return "automation_rules"
+}
+
+// References reports whether any condition of the rule reads the given device status code
+func (r AutomationRule) References(deviceID, code string) bool {
+	return r.Conditions.references(deviceID, code)
+}
+
+func (g ConditionGroup) references(deviceID, code string) bool {
+	for _, c := range g.Conditions {
+		if c.DeviceID == deviceID && c.Code == code {
+			return true
+		}
+	}
+	for _, sub := range g.Groups {
+		if sub.references(deviceID, code) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package automation
+
+import (
+	"sensio/domain/automation/controllers"
+	"sensio/domain/automation/repositories"
+	"sensio/domain/automation/usecases"
+	"sensio/domain/common/infrastructure"
+	scene_repositories "sensio/domain/scene/repositories"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AutomationModule struct {
+	RuleController *controllers.AutomationRuleController
+	Engine         *usecases.AutomationEngine
+}
+
+func NewAutomationModule(
+	db *gorm.DB,
+	badger *infrastructure.BadgerService,
+	deviceRepo device_repositories.IDeviceRepository,
+	sceneRunner usecases.SceneRunner,
+	tuyaCmd usecases.DeviceCommandExecutor,
+	tuyaAuth usecases.AccessTokenProvider,
+) *AutomationModule {
+	repo := repositories.NewAutomationRuleRepository(db)
+	statusRepo := device_status_repositories.NewDeviceStatusRepository(badger)
+	sceneRepo := scene_repositories.NewSceneRepository(db)
+
+	engine := usecases.NewAutomationEngine(repo, statusRepo, sceneRunner, tuyaCmd, tuyaAuth)
+	ruleUC := usecases.NewAutomationRuleUseCase(repo, deviceRepo, sceneRepo, engine)
+
+	return &AutomationModule{
+		RuleController: controllers.NewAutomationRuleController(ruleUC),
+		Engine:         engine,
+	}
+}
+
+func (m *AutomationModule) RegisterRoutes(protected *gin.RouterGroup) {
+	group := protected.Group("/api/automations/:terminal_id/rules")
+	{
+		group.POST("", m.RuleController.CreateRule)
+		group.GET("", m.RuleController.ListRules)
+		group.POST("/dry-run", m.RuleController.DryRun)
+		group.GET("/:rule_id", m.RuleController.GetRule)
+		group.PUT("/:rule_id", m.RuleController.UpdateRule)
+		group.DELETE("/:rule_id", m.RuleController.DeleteRule)
+	}
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"sensio/domain/automation/entities"
+
+	"gorm.io/gorm"
+)
+
+// IAutomationRuleRepository defines the interface for automation rule storage operations
+type IAutomationRuleRepository interface {
+	Save(rule *entities.AutomationRule) error
+	GetByID(terminalID, id string) (*entities.AutomationRule, error)
+	GetAll(terminalID string) ([]entities.AutomationRule, error)
+	GetEnabled(terminalID string) ([]entities.AutomationRule, error)
+	Delete(terminalID, id string) error
+	MarkFired(rule *entities.AutomationRule) error
+}
+
+// AutomationRuleRepository handles persistent storage of automation rules using GORM
+type AutomationRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewAutomationRuleRepository creates a new instance of AutomationRuleRepository
+func NewAutomationRuleRepository(db *gorm.DB) *AutomationRuleRepository {
+	return &AutomationRuleRepository{db: db}
+}
+
+// Save persists a rule to the database (Upsert)
+func (r *AutomationRuleRepository) Save(rule *entities.AutomationRule) error {
+	return r.db.Save(rule).Error
+}
+
+// GetByID retrieves a rule by its ID and TerminalID
+func (r *AutomationRuleRepository) GetByID(terminalID, id string) (*entities.AutomationRule, error) {
+	var rule entities.AutomationRule
+	if err := r.db.Where("id = ? AND terminal_id = ?", id, terminalID).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAll retrieves all rules configured for a terminal
+func (r *AutomationRuleRepository) GetAll(terminalID string) ([]entities.AutomationRule, error) {
+	var rules []entities.AutomationRule
+	if err := r.db.Where("terminal_id = ?", terminalID).Order("created_at").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetEnabled retrieves the enabled rules of a terminal
+func (r *AutomationRuleRepository) GetEnabled(terminalID string) ([]entities.AutomationRule, error) {
+	var rules []entities.AutomationRule
+	if err := r.db.Where("terminal_id = ? AND enabled = ?", terminalID, true).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Delete removes a rule if it belongs to the specified terminal
+func (r *AutomationRuleRepository) Delete(terminalID, id string) error {
+	return r.db.Where("id = ? AND terminal_id = ?", id, terminalID).Delete(&entities.AutomationRule{}).Error
+}
+
+// MarkFired stores only the firing bookkeeping so a concurrent edit of the rule is not overwritten
+func (r *AutomationRuleRepository) MarkFired(rule *entities.AutomationRule) error {
+	return r.db.Model(&entities.AutomationRule{}).
+		Where("id = ?", rule.ID).
+		Updates(map[string]interface{}{
+			"last_fired_at": rule.LastFiredAt,
+			"last_result":   rule.LastResult,
+		}).Error
+}
@@ -0,0 +1,282 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sensio/domain/automation/entities"
+	"sensio/domain/automation/repositories"
+	"sensio/domain/common/utils"
+	scene_dtos "sensio/domain/scene/dtos"
+	scene_entities "sensio/domain/scene/entities"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SceneRunner executes a stored scene (implemented by scene ControlSceneUseCase)
+type SceneRunner interface {
+	RunScene(terminalID, id, accessToken, source string) (*scene_dtos.SceneRunReportDTO, error)
+}
+
+// DeviceCommandExecutor sends commands to Tuya devices
+type DeviceCommandExecutor interface {
+	SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error)
+	SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error)
+}
+
+// AccessTokenProvider supplies the Tuya access token used when a rule fires
+type AccessTokenProvider interface {
+	GetTuyaAccessToken() (string, error)
+}
+
+// ruleState is the in-memory edge detector of a rule: a rule fires once when its
+// conditions become true (after the debounce window) and re-arms when they turn false.
+type ruleState struct {
+	matched bool
+	timer   *time.Timer
+}
+
+// AutomationEngine evaluates rules whenever a device status is written.
+// It implements device_status_usecases.DeviceStatusListener.
+type AutomationEngine struct {
+	repo       repositories.IAutomationRuleRepository
+	statusRepo device_status_repositories.IDeviceStatusRepository
+	scenes     SceneRunner
+	commands   DeviceCommandExecutor
+	tokens     AccessTokenProvider
+	now        func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*ruleState
+}
+
+// NewAutomationEngine creates a new instance of AutomationEngine
+func NewAutomationEngine(
+	repo repositories.IAutomationRuleRepository,
+	statusRepo device_status_repositories.IDeviceStatusRepository,
+	scenes SceneRunner,
+	commands DeviceCommandExecutor,
+	tokens AccessTokenProvider,
+) *AutomationEngine {
+	return &AutomationEngine{
+		repo:       repo,
+		statusRepo: statusRepo,
+		scenes:     scenes,
+		commands:   commands,
+		tokens:     tokens,
+		now:        time.Now,
+		states:     make(map[string]*ruleState),
+	}
+}
+
+// OnDeviceStatusChanged evaluates the affected rules asynchronously so the write path is never blocked
+func (e *AutomationEngine) OnDeviceStatusChanged(change device_status_usecases.DeviceStatusChange) {
+	go e.HandleStatusChange(change)
+}
+
+// HandleStatusChange evaluates every enabled rule of the terminal that reads the changed status
+func (e *AutomationEngine) HandleStatusChange(change device_status_usecases.DeviceStatusChange) {
+	rules, err := e.repo.GetEnabled(change.TerminalID)
+	if err != nil {
+		utils.LogError("AutomationEngine: failed to load rules for terminal %s: %v", change.TerminalID, err)
+		return
+	}
+
+	lookup := e.lookupWith(change)
+	for _, rule := range rules {
+		if !rule.References(change.DeviceID, change.Code) {
+			continue
+		}
+		e.evaluate(rule, lookup)
+	}
+}
+
+// Reset forgets the in-memory state of a rule (after it was updated or deleted)
+func (e *AutomationEngine) Reset(ruleID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if st, ok := e.states[ruleID]; ok {
+		if st.timer != nil {
+			st.timer.Stop()
+		}
+		delete(e.states, ruleID)
+	}
+}
+
+// CooldownRemaining returns how long the rule must still wait before it may fire again
+func (e *AutomationEngine) CooldownRemaining(rule entities.AutomationRule) time.Duration {
+	if rule.CooldownSeconds <= 0 || rule.LastFiredAt == nil {
+		return 0
+	}
+	remaining := rule.LastFiredAt.Add(time.Duration(rule.CooldownSeconds) * time.Second).Sub(e.now())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// StoredStatus reads the current value of a device status from the repository
+func (e *AutomationEngine) StoredStatus(deviceID, code string) (string, bool) {
+	status, err := e.statusRepo.GetByDeviceIDAndCode(deviceID, code)
+	if err != nil || status == nil {
+		return "", false
+	}
+	return status.Value, true
+}
+
+func (e *AutomationEngine) lookupWith(change device_status_usecases.DeviceStatusChange) StatusLookup {
+	return func(deviceID, code string) (string, bool) {
+		if deviceID == change.DeviceID && code == change.Code {
+			return change.Value, true
+		}
+		return e.StoredStatus(deviceID, code)
+	}
+}
+
+func (e *AutomationEngine) evaluate(rule entities.AutomationRule, lookup StatusLookup) {
+	matched, _ := EvaluateConditions(rule.Conditions, lookup)
+
+	e.mu.Lock()
+	st, ok := e.states[rule.ID]
+	if !ok {
+		st = &ruleState{}
+		e.states[rule.ID] = st
+	}
+	if !matched {
+		st.matched = false
+		if st.timer != nil {
+			st.timer.Stop()
+			st.timer = nil
+		}
+		e.mu.Unlock()
+		return
+	}
+	if st.matched {
+		// Already fired (or waiting on debounce) for this true period
+		e.mu.Unlock()
+		return
+	}
+	st.matched = true
+
+	if rule.DebounceSeconds > 0 {
+		terminalID, ruleID := rule.TerminalID, rule.ID
+		st.timer = time.AfterFunc(time.Duration(rule.DebounceSeconds)*time.Second, func() {
+			e.confirm(terminalID, ruleID)
+		})
+		e.mu.Unlock()
+		return
+	}
+	e.mu.Unlock()
+
+	e.fire(rule)
+}
+
+// confirm re-checks a debounced rule against the stored statuses and fires it if it still holds
+func (e *AutomationEngine) confirm(terminalID, ruleID string) {
+	e.mu.Lock()
+	if st, ok := e.states[ruleID]; ok {
+		st.timer = nil
+	}
+	e.mu.Unlock()
+
+	rule, err := e.repo.GetByID(terminalID, ruleID)
+	if err != nil || !rule.Enabled {
+		e.Reset(ruleID)
+		return
+	}
+
+	matched, _ := EvaluateConditions(rule.Conditions, e.StoredStatus)
+	if !matched {
+		e.mu.Lock()
+		if st, ok := e.states[ruleID]; ok {
+			st.matched = false
+		}
+		e.mu.Unlock()
+		return
+	}
+	e.fire(*rule)
+}
+
+func (e *AutomationEngine) fire(rule entities.AutomationRule) {
+	if remaining := e.CooldownRemaining(rule); remaining > 0 {
+		utils.LogInfo("AutomationEngine: rule %s matched but is cooling down for %s", rule.ID, remaining.Round(time.Second))
+		return
+	}
+
+	utils.LogInfo("AutomationEngine: firing rule %s (%s)", rule.ID, rule.Name)
+	err := e.execute(rule)
+
+	firedAt := e.now()
+	rule.LastFiredAt = &firedAt
+	rule.LastResult = "ok"
+	if err != nil {
+		rule.LastResult = err.Error()
+		utils.LogError("AutomationEngine: rule %s finished with errors: %v", rule.ID, err)
+	}
+	if err := e.repo.MarkFired(&rule); err != nil {
+		utils.LogError("AutomationEngine: failed to record firing of rule %s: %v", rule.ID, err)
+	}
+}
+
+func (e *AutomationEngine) execute(rule entities.AutomationRule) error {
+	accessToken := ""
+	if e.tokens != nil {
+		token, err := e.tokens.GetTuyaAccessToken()
+		if err != nil {
+			return fmt.Errorf("failed to get access token: %w", err)
+		}
+		accessToken = token
+	}
+
+	var errs []string
+	for i, action := range rule.Actions {
+		if err := e.executeAction(rule.TerminalID, action, accessToken); err != nil {
+			errs = append(errs, fmt.Sprintf("action %d (%s): %v", i, action.Type, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (e *AutomationEngine) executeAction(terminalID string, action entities.RuleAction, accessToken string) error {
+	switch action.Type {
+	case entities.ActionTypeScene:
+		if e.scenes == nil {
+			return fmt.Errorf("scene execution is not available")
+		}
+		_, err := e.scenes.RunScene(terminalID, action.SceneID, accessToken, scene_entities.SceneRunSourceAutomation)
+		return err
+	case entities.ActionTypeCommand:
+		if e.commands == nil {
+			return fmt.Errorf("device control is not available")
+		}
+		var (
+			success bool
+			err     error
+		)
+		if action.RemoteID != "" {
+			valInt, ok := utils.ToInt(action.Value)
+			if !ok {
+				return fmt.Errorf("invalid value for IR command on device %s: %v", action.DeviceID, action.Value)
+			}
+			success, err = e.commands.SendIRACCommand(accessToken, action.DeviceID, action.RemoteID, map[string]int{action.Code: valInt})
+		} else {
+			cmd := tuya_dtos.TuyaCommandDTO{Code: action.Code, Value: action.Value}
+			success, err = e.commands.SendSwitchCommand(accessToken, action.DeviceID, []tuya_dtos.TuyaCommandDTO{cmd})
+		}
+		if err != nil {
+			return err
+		}
+		if !success {
+			return fmt.Errorf("unsuccessful response from Tuya")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported action type %q", action.Type)
+	}
+}
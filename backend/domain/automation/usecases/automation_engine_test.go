@@ -0,0 +1,216 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/automation/entities"
+	scene_dtos "sensio/domain/scene/dtos"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRuleRepo struct {
+	mu    sync.Mutex
+	rules map[string]entities.AutomationRule
+}
+
+func (r *fakeRuleRepo) Save(rule *entities.AutomationRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules[rule.ID] = *rule
+	return nil
+}
+
+func (r *fakeRuleRepo) GetByID(terminalID, id string) (*entities.AutomationRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rule, ok := r.rules[id]
+	if !ok || rule.TerminalID != terminalID {
+		return nil, errors.New("record not found")
+	}
+	return &rule, nil
+}
+
+func (r *fakeRuleRepo) GetAll(terminalID string) ([]entities.AutomationRule, error) {
+	return r.GetEnabled(terminalID)
+}
+
+func (r *fakeRuleRepo) GetEnabled(terminalID string) ([]entities.AutomationRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entities.AutomationRule
+	for _, rule := range r.rules {
+		if rule.TerminalID == terminalID && rule.Enabled {
+			out = append(out, rule)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRuleRepo) Delete(terminalID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rules, id)
+	return nil
+}
+
+func (r *fakeRuleRepo) MarkFired(rule *entities.AutomationRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.rules[rule.ID]
+	stored.LastFiredAt = rule.LastFiredAt
+	stored.LastResult = rule.LastResult
+	r.rules[rule.ID] = stored
+	return nil
+}
+
+// fakeStatusRepo only implements the lookup used by the engine
+type fakeStatusRepo struct {
+	device_status_repositories.IDeviceStatusRepository
+	values map[string]string
+}
+
+func (r *fakeStatusRepo) GetByDeviceIDAndCode(deviceID, code string) (*device_status_entities.DeviceStatus, error) {
+	v, ok := r.values[deviceID+"/"+code]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &device_status_entities.DeviceStatus{DeviceID: deviceID, Code: code, Value: v}, nil
+}
+
+type fakeSceneRunner struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *fakeSceneRunner) RunScene(terminalID, id, accessToken, source string) (*scene_dtos.SceneRunReportDTO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, id)
+	return &scene_dtos.SceneRunReportDTO{SceneID: id, Source: source}, nil
+}
+
+func hotAndOccupiedRule() entities.AutomationRule {
+	return entities.AutomationRule{
+		ID:         "rule-1",
+		TerminalID: "term-1",
+		Name:       "Cool down",
+		Enabled:    true,
+		Conditions: entities.ConditionGroup{
+			Logic: entities.LogicAnd,
+			Conditions: []entities.Condition{
+				{DeviceID: "thermo", Code: "va_temperature", Operator: entities.OpGreater, Value: float64(28)},
+			},
+			Groups: []entities.ConditionGroup{{
+				Logic: entities.LogicOr,
+				Conditions: []entities.Condition{
+					{DeviceID: "pir", Code: "presence_state", Operator: entities.OpEqual, Value: "presence"},
+					{DeviceID: "door", Code: "doorcontact_state", Operator: entities.OpEqual, Value: true},
+				},
+			}},
+		},
+		Actions: entities.RuleActions{{Type: entities.ActionTypeScene, SceneID: "scene-cool"}},
+	}
+}
+
+func newTestEngine(rule entities.AutomationRule, statuses map[string]string) (*AutomationEngine, *fakeRuleRepo, *fakeSceneRunner) {
+	repo := &fakeRuleRepo{rules: map[string]entities.AutomationRule{rule.ID: rule}}
+	runner := &fakeSceneRunner{}
+	engine := NewAutomationEngine(repo, &fakeStatusRepo{values: statuses}, runner, nil, nil)
+	return engine, repo, runner
+}
+
+func change(deviceID, code, value string) device_status_usecases.DeviceStatusChange {
+	return device_status_usecases.DeviceStatusChange{TerminalID: "term-1", DeviceID: deviceID, Code: code, Value: value}
+}
+
+func TestEvaluateConditions_AndOr(t *testing.T) {
+	rule := hotAndOccupiedRule()
+	statuses := map[string]string{"thermo/va_temperature": "29.5", "pir/presence_state": "none", "door/doorcontact_state": "true"}
+	engine, _, _ := newTestEngine(rule, statuses)
+
+	matched, results := EvaluateConditions(rule.Conditions, engine.StoredStatus)
+	assert.True(t, matched)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Matched)
+	assert.False(t, results[1].Matched)
+	assert.True(t, results[2].Matched)
+
+	statuses["thermo/va_temperature"] = "27"
+	matched, _ = EvaluateConditions(rule.Conditions, engine.StoredStatus)
+	assert.False(t, matched)
+}
+
+func TestEvaluateConditions_UnknownStatusAndBadNumber(t *testing.T) {
+	rule := hotAndOccupiedRule()
+	engine, _, _ := newTestEngine(rule, map[string]string{"thermo/va_temperature": "hot"})
+
+	matched, results := EvaluateConditions(rule.Conditions, engine.StoredStatus)
+	assert.False(t, matched)
+	assert.Contains(t, results[0].Error, "not numeric")
+	assert.Nil(t, results[1].Actual)
+	assert.Equal(t, "status not reported yet", results[1].Error)
+}
+
+func TestAutomationEngine_FiresOnceUntilConditionsClear(t *testing.T) {
+	statuses := map[string]string{"pir/presence_state": "presence"}
+	engine, repo, runner := newTestEngine(hotAndOccupiedRule(), statuses)
+
+	engine.HandleStatusChange(change("thermo", "va_temperature", "29"))
+	engine.HandleStatusChange(change("thermo", "va_temperature", "30"))
+	assert.Equal(t, []string{"scene-cool"}, runner.calls)
+	assert.Equal(t, "ok", repo.rules["rule-1"].LastResult)
+	require.NotNil(t, repo.rules["rule-1"].LastFiredAt)
+
+	// Drops below threshold, then rises again: re-armed and fires a second time
+	engine.HandleStatusChange(change("thermo", "va_temperature", "25"))
+	engine.HandleStatusChange(change("thermo", "va_temperature", "31"))
+	assert.Len(t, runner.calls, 2)
+}
+
+func TestAutomationEngine_IgnoresUnrelatedStatus(t *testing.T) {
+	engine, _, runner := newTestEngine(hotAndOccupiedRule(), map[string]string{"pir/presence_state": "presence", "thermo/va_temperature": "35"})
+
+	engine.HandleStatusChange(change("lamp", "switch_1", "true"))
+	assert.Empty(t, runner.calls)
+}
+
+func TestAutomationEngine_Cooldown(t *testing.T) {
+	rule := hotAndOccupiedRule()
+	rule.CooldownSeconds = 600
+	lastFired := time.Now().Add(-time.Minute)
+	rule.LastFiredAt = &lastFired
+	engine, _, runner := newTestEngine(rule, map[string]string{"pir/presence_state": "presence"})
+
+	engine.HandleStatusChange(change("thermo", "va_temperature", "29"))
+	assert.Empty(t, runner.calls)
+	assert.InDelta(t, (9 * time.Minute).Seconds(), engine.CooldownRemaining(rule).Seconds(), 2)
+}
+
+func TestAutomationEngine_DebounceRequiresConditionsToHold(t *testing.T) {
+	rule := hotAndOccupiedRule()
+	rule.DebounceSeconds = 300
+	statuses := map[string]string{"pir/presence_state": "presence", "thermo/va_temperature": "29"}
+	engine, _, runner := newTestEngine(rule, statuses)
+
+	engine.HandleStatusChange(change("thermo", "va_temperature", "29"))
+	assert.Empty(t, runner.calls, "must wait for the debounce window")
+
+	// Conditions no longer hold when the window elapses
+	statuses["thermo/va_temperature"] = "26"
+	engine.confirm("term-1", "rule-1")
+	assert.Empty(t, runner.calls)
+
+	// Holds again for the full window
+	statuses["thermo/va_temperature"] = "29"
+	engine.HandleStatusChange(change("thermo", "va_temperature", "29"))
+	engine.confirm("term-1", "rule-1")
+	assert.Equal(t, []string{"scene-cool"}, runner.calls)
+	engine.Reset("rule-1")
+}
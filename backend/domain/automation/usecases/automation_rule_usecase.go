@@ -0,0 +1,340 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sensio/domain/automation/dtos"
+	"sensio/domain/automation/entities"
+	"sensio/domain/automation/repositories"
+	"sensio/domain/common/utils"
+	scene_repositories "sensio/domain/scene/repositories"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrRuleNotFound is returned when a rule does not exist for the terminal
+var ErrRuleNotFound = errors.New("rule not found")
+
+const maxWindowSeconds = 7 * 24 * 60 * 60
+
+// AutomationRuleUseCase manages automation rules and dry-run evaluation
+type AutomationRuleUseCase struct {
+	repo      repositories.IAutomationRuleRepository
+	devRepo   device_repositories.IDeviceRepository
+	sceneRepo scene_repositories.ISceneRepository
+	engine    *AutomationEngine
+}
+
+// NewAutomationRuleUseCase creates a new instance of AutomationRuleUseCase
+func NewAutomationRuleUseCase(
+	repo repositories.IAutomationRuleRepository,
+	devRepo device_repositories.IDeviceRepository,
+	sceneRepo scene_repositories.ISceneRepository,
+	engine *AutomationEngine,
+) *AutomationRuleUseCase {
+	return &AutomationRuleUseCase{
+		repo:      repo,
+		devRepo:   devRepo,
+		sceneRepo: sceneRepo,
+		engine:    engine,
+	}
+}
+
+func (u *AutomationRuleUseCase) CreateRule(terminalID string, req dtos.AutomationRuleRequestDTO) (*dtos.AutomationRuleIDResponseDTO, error) {
+	rule := &entities.AutomationRule{
+		ID:         uuid.New().String(),
+		TerminalID: terminalID,
+	}
+	if err := u.apply(rule, req); err != nil {
+		return nil, err
+	}
+	if err := u.repo.Save(rule); err != nil {
+		return nil, err
+	}
+	return &dtos.AutomationRuleIDResponseDTO{RuleID: rule.ID}, nil
+}
+
+func (u *AutomationRuleUseCase) UpdateRule(terminalID, id string, req dtos.AutomationRuleRequestDTO) (*dtos.AutomationRuleIDResponseDTO, error) {
+	rule, err := u.load(terminalID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.apply(rule, req); err != nil {
+		return nil, err
+	}
+	if err := u.repo.Save(rule); err != nil {
+		return nil, err
+	}
+	u.engine.Reset(rule.ID)
+	return &dtos.AutomationRuleIDResponseDTO{RuleID: rule.ID}, nil
+}
+
+func (u *AutomationRuleUseCase) GetRule(terminalID, id string) (*dtos.AutomationRuleResponseDTO, error) {
+	rule, err := u.load(terminalID, id)
+	if err != nil {
+		return nil, err
+	}
+	dto := toRuleDTO(*rule)
+	return &dto, nil
+}
+
+func (u *AutomationRuleUseCase) ListRules(terminalID string) ([]dtos.AutomationRuleResponseDTO, error) {
+	rules, err := u.repo.GetAll(terminalID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.AutomationRuleResponseDTO, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, toRuleDTO(r))
+	}
+	return result, nil
+}
+
+func (u *AutomationRuleUseCase) DeleteRule(terminalID, id string) error {
+	if _, err := u.load(terminalID, id); err != nil {
+		return err
+	}
+	if err := u.repo.Delete(terminalID, id); err != nil {
+		return err
+	}
+	u.engine.Reset(id)
+	return nil
+}
+
+// DryRun evaluates a stored or inline rule against the current statuses (optionally overridden)
+// and reports whether it would fire. No action is executed and no state is changed.
+func (u *AutomationRuleUseCase) DryRun(terminalID string, req dtos.DryRunRequestDTO) (*dtos.DryRunResponseDTO, error) {
+	var rule *entities.AutomationRule
+	switch {
+	case req.RuleID != "":
+		stored, err := u.load(terminalID, req.RuleID)
+		if err != nil {
+			return nil, err
+		}
+		rule = stored
+	case req.Rule != nil:
+		rule = &entities.AutomationRule{TerminalID: terminalID}
+		if err := u.apply(rule, *req.Rule); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "rule_id", Message: "either rule_id or rule is required"},
+		})
+	}
+
+	overrides := make(map[string]string, len(req.Statuses))
+	for _, s := range req.Statuses {
+		overrides[s.DeviceID+"\x00"+s.Code] = fmt.Sprintf("%v", s.Value)
+	}
+	lookup := func(deviceID, code string) (string, bool) {
+		if v, ok := overrides[deviceID+"\x00"+code]; ok {
+			return v, true
+		}
+		return u.engine.StoredStatus(deviceID, code)
+	}
+
+	matched, results := EvaluateConditions(rule.Conditions, lookup)
+	resp := &dtos.DryRunResponseDTO{
+		Matched:    matched,
+		Conditions: results,
+		Actions:    toActionDTOs(rule.Actions),
+	}
+
+	switch remaining := u.engine.CooldownRemaining(*rule); {
+	case !rule.Enabled:
+		resp.Reason = "rule is disabled"
+	case !matched:
+		resp.Reason = "conditions are not met"
+	case remaining > 0:
+		resp.Reason = fmt.Sprintf("cooldown active for another %s", remaining.Round(time.Second))
+	case rule.DebounceSeconds > 0:
+		resp.WouldFire = true
+		resp.Reason = fmt.Sprintf("would fire once conditions have held for %ds", rule.DebounceSeconds)
+	default:
+		resp.WouldFire = true
+		resp.Reason = "would fire immediately"
+	}
+	return resp, nil
+}
+
+// apply validates the request and copies it onto the rule
+func (u *AutomationRuleUseCase) apply(rule *entities.AutomationRule, req dtos.AutomationRuleRequestDTO) error {
+	var details []utils.ValidationErrorDetail
+
+	conditions := toConditionGroup(req.Conditions)
+	if countConditions(conditions) == 0 {
+		details = append(details, utils.ValidationErrorDetail{Field: "conditions", Message: "at least one condition is required"})
+	}
+	details = append(details, u.validateGroup(rule.TerminalID, conditions, "conditions")...)
+
+	if len(req.Actions) == 0 {
+		details = append(details, utils.ValidationErrorDetail{Field: "actions", Message: "at least one action is required"})
+	}
+	for i, a := range req.Actions {
+		details = append(details, u.validateAction(rule.TerminalID, a, fmt.Sprintf("actions[%d]", i))...)
+	}
+
+	if req.DebounceSeconds < 0 || req.DebounceSeconds > maxWindowSeconds {
+		details = append(details, utils.ValidationErrorDetail{Field: "debounce_seconds", Message: fmt.Sprintf("must be between 0 and %d", maxWindowSeconds)})
+	}
+	if req.CooldownSeconds < 0 || req.CooldownSeconds > maxWindowSeconds {
+		details = append(details, utils.ValidationErrorDetail{Field: "cooldown_seconds", Message: fmt.Sprintf("must be between 0 and %d", maxWindowSeconds)})
+	}
+
+	if len(details) > 0 {
+		return utils.NewValidationError("Validation Error", details)
+	}
+
+	rule.Name = req.Name
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	rule.Conditions = conditions
+	rule.Actions = toRuleActions(req.Actions)
+	rule.DebounceSeconds = req.DebounceSeconds
+	rule.CooldownSeconds = req.CooldownSeconds
+	return nil
+}
+
+func (u *AutomationRuleUseCase) validateGroup(terminalID string, group entities.ConditionGroup, field string) []utils.ValidationErrorDetail {
+	var details []utils.ValidationErrorDetail
+	if group.Logic != entities.LogicAnd && group.Logic != entities.LogicOr {
+		details = append(details, utils.ValidationErrorDetail{Field: field + ".logic", Message: "must be 'and' or 'or'"})
+	}
+	for i, c := range group.Conditions {
+		f := fmt.Sprintf("%s.conditions[%d]", field, i)
+		if msg := u.checkDevice(terminalID, c.DeviceID); msg != "" {
+			details = append(details, utils.ValidationErrorDetail{Field: f + ".device_id", Message: msg})
+		}
+		if c.Code == "" {
+			details = append(details, utils.ValidationErrorDetail{Field: f + ".code", Message: "code is required"})
+		}
+		switch c.Operator {
+		case entities.OpEqual, entities.OpNotEqual, entities.OpContains:
+		case entities.OpGreater, entities.OpGreaterOrEqual, entities.OpLess, entities.OpLessOrEqual:
+			if _, ok := utils.ToFloat(c.Value); !ok {
+				details = append(details, utils.ValidationErrorDetail{Field: f + ".value", Message: "must be numeric for operator " + c.Operator})
+			}
+		default:
+			details = append(details, utils.ValidationErrorDetail{Field: f + ".operator", Message: "must be one of: eq, neq, gt, gte, lt, lte, contains"})
+		}
+	}
+	for i, sub := range group.Groups {
+		details = append(details, u.validateGroup(terminalID, sub, fmt.Sprintf("%s.groups[%d]", field, i))...)
+	}
+	return details
+}
+
+func (u *AutomationRuleUseCase) validateAction(terminalID string, a dtos.RuleActionDTO, field string) []utils.ValidationErrorDetail {
+	var details []utils.ValidationErrorDetail
+	switch a.Type {
+	case entities.ActionTypeScene:
+		if _, err := u.sceneRepo.GetByID(terminalID, a.SceneID); err != nil {
+			details = append(details, utils.ValidationErrorDetail{Field: field + ".scene_id", Message: "scene not found for this terminal"})
+		}
+	case entities.ActionTypeCommand:
+		if msg := u.checkDevice(terminalID, a.DeviceID); msg != "" {
+			details = append(details, utils.ValidationErrorDetail{Field: field + ".device_id", Message: msg})
+		}
+		if a.Code == "" {
+			details = append(details, utils.ValidationErrorDetail{Field: field + ".code", Message: "code is required"})
+		}
+	default:
+		details = append(details, utils.ValidationErrorDetail{Field: field + ".type", Message: "must be 'scene' or 'command'"})
+	}
+	return details
+}
+
+func (u *AutomationRuleUseCase) checkDevice(terminalID, deviceID string) string {
+	if deviceID == "" {
+		return "device_id is required"
+	}
+	device, err := u.devRepo.GetByID(deviceID)
+	if err != nil {
+		return "device not found"
+	}
+	if device.TerminalID != terminalID {
+		return "device does not belong to this terminal"
+	}
+	return ""
+}
+
+func (u *AutomationRuleUseCase) load(terminalID, id string) (*entities.AutomationRule, error) {
+	rule, err := u.repo.GetByID(terminalID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+func countConditions(g entities.ConditionGroup) int {
+	n := len(g.Conditions)
+	for _, sub := range g.Groups {
+		n += countConditions(sub)
+	}
+	return n
+}
+
+func toConditionGroup(d dtos.ConditionGroupDTO) entities.ConditionGroup {
+	g := entities.ConditionGroup{Logic: d.Logic}
+	if g.Logic == "" {
+		g.Logic = entities.LogicAnd
+	}
+	for _, c := range d.Conditions {
+		g.Conditions = append(g.Conditions, entities.Condition(c))
+	}
+	for _, sub := range d.Groups {
+		g.Groups = append(g.Groups, toConditionGroup(sub))
+	}
+	return g
+}
+
+func toConditionGroupDTO(g entities.ConditionGroup) dtos.ConditionGroupDTO {
+	d := dtos.ConditionGroupDTO{Logic: g.Logic}
+	for _, c := range g.Conditions {
+		d.Conditions = append(d.Conditions, dtos.ConditionDTO(c))
+	}
+	for _, sub := range g.Groups {
+		d.Groups = append(d.Groups, toConditionGroupDTO(sub))
+	}
+	return d
+}
+
+func toRuleActions(in []dtos.RuleActionDTO) entities.RuleActions {
+	out := make(entities.RuleActions, len(in))
+	for i, a := range in {
+		out[i] = entities.RuleAction(a)
+	}
+	return out
+}
+
+func toActionDTOs(in entities.RuleActions) []dtos.RuleActionDTO {
+	out := make([]dtos.RuleActionDTO, len(in))
+	for i, a := range in {
+		out[i] = dtos.RuleActionDTO(a)
+	}
+	return out
+}
+
+func toRuleDTO(r entities.AutomationRule) dtos.AutomationRuleResponseDTO {
+	dto := dtos.AutomationRuleResponseDTO{
+		ID:              r.ID,
+		TerminalID:      r.TerminalID,
+		Name:            r.Name,
+		Enabled:         r.Enabled,
+		Conditions:      toConditionGroupDTO(r.Conditions),
+		Actions:         toActionDTOs(r.Actions),
+		DebounceSeconds: r.DebounceSeconds,
+		CooldownSeconds: r.CooldownSeconds,
+		LastResult:      r.LastResult,
+	}
+	if r.LastFiredAt != nil {
+		dto.LastFiredAt = r.LastFiredAt.UTC().Format(time.RFC3339)
+	}
+	return dto
+}
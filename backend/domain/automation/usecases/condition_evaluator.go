@@ -0,0 +1,71 @@
+package usecases
+
+import (
+	"sensio/domain/automation/dtos"
+	"sensio/domain/automation/entities"
+	"sensio/domain/common/utils"
+)
+
+// StatusLookup returns the current stored value of a device status code
+type StatusLookup func(deviceID, code string) (string, bool)
+
+// EvaluateConditions evaluates a condition group and returns the per-condition results in declaration order.
+// Every condition is evaluated (no short-circuit) so dry runs can show the full picture.
+func EvaluateConditions(group entities.ConditionGroup, lookup StatusLookup) (bool, []dtos.ConditionResultDTO) {
+	var results []dtos.ConditionResultDTO
+	matched := evaluateGroup(group, lookup, &results)
+	return matched, results
+}
+
+func evaluateGroup(group entities.ConditionGroup, lookup StatusLookup, results *[]dtos.ConditionResultDTO) bool {
+	var outcomes []bool
+	for _, c := range group.Conditions {
+		result := evaluateCondition(c, lookup)
+		*results = append(*results, result)
+		outcomes = append(outcomes, result.Matched)
+	}
+	for _, sub := range group.Groups {
+		outcomes = append(outcomes, evaluateGroup(sub, lookup, results))
+	}
+
+	if len(outcomes) == 0 {
+		return false
+	}
+	if group.Logic == entities.LogicOr {
+		for _, o := range outcomes {
+			if o {
+				return true
+			}
+		}
+		return false
+	}
+	for _, o := range outcomes {
+		if !o {
+			return false
+		}
+	}
+	return true
+}
+
+func evaluateCondition(c entities.Condition, lookup StatusLookup) dtos.ConditionResultDTO {
+	result := dtos.ConditionResultDTO{
+		DeviceID: c.DeviceID,
+		Code:     c.Code,
+		Operator: c.Operator,
+		Expected: c.Value,
+	}
+	actual, ok := lookup(c.DeviceID, c.Code)
+	if !ok {
+		result.Error = "status not reported yet"
+		return result
+	}
+	result.Actual = &actual
+
+	matched, err := utils.CompareStatusValue(actual, c.Operator, c.Value)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Matched = matched
+	return result
+}
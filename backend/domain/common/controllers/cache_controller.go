@@ -12,11 +12,11 @@ import (
 // CacheController handles cache-related operations
 type CacheController struct {
 	cache  *infrastructure.BadgerService
-	vector *infrastructure.VectorService
+	vector infrastructure.VectorStore
 }
 
 // NewCacheController creates a new CacheController instance
-func NewCacheController(cache *infrastructure.BadgerService, vector *infrastructure.VectorService) *CacheController {
+func NewCacheController(cache *infrastructure.BadgerService, vector infrastructure.VectorStore) *CacheController {
 	return &CacheController{
 		cache:  cache,
 		vector: vector,
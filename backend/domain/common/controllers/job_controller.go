@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/tasks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobController exposes the background job queue shared by transcription, translation,
+// summary, pipeline and mail tasks
+type JobController struct {
+	jobs *tasks.JobQueue
+}
+
+// NewJobController creates a new JobController instance
+func NewJobController(jobs *tasks.JobQueue) *JobController {
+	return &JobController{
+		jobs: jobs,
+	}
+}
+
+// jobPayloadFields are the payload fields shown as-is; every other string (transcripts, mail bodies,
+// recipients, summaries) is redacted, since job payloads carry user content
+var jobPayloadFields = map[string]bool{
+	"input_path":      true,
+	"file_path":       true,
+	"language":        true,
+	"target_language": true,
+	"style":           true,
+	"template":        true,
+	"mac_address":     true,
+	"terminal_id":     true,
+	"parent_task_id":  true,
+	"from_stage":      true,
+	"provider":        true,
+}
+
+// redactJobPayload keeps the shape of a payload but replaces user content with "[redacted]"
+func redactJobPayload(payload json.RawMessage) json.RawMessage {
+	if len(payload) == 0 {
+		return payload
+	}
+	var value any
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(redactJobValue(value, false))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func redactJobValue(value any, keep bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			v[key] = redactJobValue(field, jobPayloadFields[key])
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactJobValue(item, keep)
+		}
+		return v
+	case string:
+		if keep || v == "" {
+			return v
+		}
+		return "[redacted]"
+	}
+	return value
+}
+
+// toJobDTO maps a job; the redacted payload is only included when a single job is inspected
+func toJobDTO(job tasks.Job, withPayload bool) dtos.JobDTO {
+	dto := dtos.JobDTO{
+		ID:              job.ID,
+		Type:            job.Type,
+		Status:          job.Status,
+		Attempts:        job.Attempts,
+		MaxAttempts:     job.MaxAttempts,
+		LastError:       job.LastError,
+		CancelRequested: job.CancelRequested,
+		CreatedAt:       job.CreatedAt,
+		UpdatedAt:       job.UpdatedAt,
+		RunAfter:        job.RunAfter,
+		StartedAt:       job.StartedAt,
+		FinishedAt:      job.FinishedAt,
+	}
+	if withPayload {
+		dto.Payload = redactJobPayload(job.Payload)
+	}
+	return dto
+}
+
+// ListJobs handles GET /api/jobs
+// @Summary List background jobs
+// @Description List queued, running and recently finished background jobs, newest first. Finished jobs are kept for JOB_RETENTION.
+// @Tags 08. Common
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Only jobs of this type (e.g. transcription, summary, translation, pipeline, mail)"
+// @Param status query string false "Only jobs in this status (queued, running, completed, failed, cancelled)"
+// @Success 200 {object} dtos.StandardResponse{data=dtos.JobListResponseDTO}
+// @Router /api/jobs [get]
+func (c *JobController) ListJobs(ctx *gin.Context) {
+	jobs := c.jobs.List(ctx.Query("type"), ctx.Query("status"))
+	items := make([]dtos.JobDTO, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, toJobDTO(job, false))
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Jobs retrieved successfully",
+		Data: dtos.JobListResponseDTO{
+			Total: len(items),
+			Jobs:  items,
+		},
+	})
+}
+
+// GetJob handles GET /api/jobs/:id
+// @Summary Inspect a background job
+// @Description Get the state, attempts, last error and payload of a job. User content in the payload (texts, transcripts, mail recipients and data) is redacted. Job ids are the task ids returned by the task endpoints.
+// @Tags 08. Common
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} dtos.StandardResponse{data=dtos.JobDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Router /api/jobs/{id} [get]
+func (c *JobController) GetJob(ctx *gin.Context) {
+	job, err := c.jobs.Get(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dtos.StandardResponse{
+			Status:  false,
+			Message: "Job not found",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Job retrieved successfully",
+		Data:    toJobDTO(*job, true),
+	})
+}
+
+// CancelJob handles POST /api/jobs/:id/cancel
+// @Summary Cancel a background job
+// @Description Cancel a queued job, or ask a running job to stop. A running job is marked cancelled once its handler returns; cancelling a finished job is a no-op.
+// @Tags 08. Common
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} dtos.StandardResponse{data=dtos.JobDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Router /api/jobs/{id}/cancel [post]
+func (c *JobController) CancelJob(ctx *gin.Context) {
+	job, err := c.jobs.Cancel(ctx.Param("id"))
+	if errors.Is(err, tasks.ErrJobNotFound) {
+		ctx.JSON(http.StatusNotFound, dtos.StandardResponse{
+			Status:  false,
+			Message: "Job not found",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Job cancellation requested",
+		Data:    toJobDTO(*job, false),
+	})
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactJobPayload(t *testing.T) {
+	payload := json.RawMessage(`{
+		"text": "Budi will send the budget to finance",
+		"language": "id",
+		"request": {"to": ["budi@example.com"], "subject": "Minutes", "template": "summary", "data": {"pin": "1234"}},
+		"rerun": {"from_stage": "summary", "transcription": {"transcription": "secret", "utterances": [{"text": "hello", "start_ms": 0}]}},
+		"diarize": true
+	}`)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(redactJobPayload(payload), &got))
+
+	assert.Equal(t, "[redacted]", got["text"])
+	assert.Equal(t, "id", got["language"])
+	assert.Equal(t, true, got["diarize"])
+
+	request := got["request"].(map[string]any)
+	assert.Equal(t, []any{"[redacted]"}, request["to"])
+	assert.Equal(t, "[redacted]", request["subject"])
+	assert.Equal(t, "summary", request["template"])
+	assert.Equal(t, "[redacted]", request["data"].(map[string]any)["pin"])
+
+	rerun := got["rerun"].(map[string]any)
+	assert.Equal(t, "summary", rerun["from_stage"])
+	transcription := rerun["transcription"].(map[string]any)
+	assert.Equal(t, "[redacted]", transcription["transcription"])
+	utterance := transcription["utterances"].([]any)[0].(map[string]any)
+	assert.Equal(t, "[redacted]", utterance["text"])
+	assert.Equal(t, float64(0), utterance["start_ms"])
+
+	assert.Nil(t, redactJobPayload(json.RawMessage(`not json`)))
+	assert.Empty(t, redactJobPayload(nil))
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MqttOutboxController lets operators inspect and purge MQTT publishes queued during broker outages
+type MqttOutboxController struct {
+	mqttSvc *infrastructure.MqttService
+}
+
+// NewMqttOutboxController creates a new MqttOutboxController instance
+func NewMqttOutboxController(mqttSvc *infrastructure.MqttService) *MqttOutboxController {
+	return &MqttOutboxController{
+		mqttSvc: mqttSvc,
+	}
+}
+
+func (c *MqttOutboxController) outbox(ctx *gin.Context) *infrastructure.MqttOutbox {
+	if c.mqttSvc == nil || c.mqttSvc.Outbox() == nil {
+		ctx.JSON(http.StatusServiceUnavailable, dtos.StandardResponse{
+			Status:  false,
+			Message: "MQTT outbox is disabled",
+		})
+		return nil
+	}
+	return c.mqttSvc.Outbox()
+}
+
+// ListMessages handles GET /api/mqtt/outbox
+// @Summary List queued MQTT messages
+// @Description List publishes queued while the MQTT broker was unreachable, in replay order. Expired messages are omitted.
+// @Tags 08. Common
+// @Produce json
+// @Security BearerAuth
+// @Param topic query string false "Only messages whose topic starts with this prefix"
+// @Success 200 {object} dtos.StandardResponse{data=dtos.MqttOutboxListResponseDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Failure      503  {object}  dtos.ErrorResponse
+// @Router /api/mqtt/outbox [get]
+func (c *MqttOutboxController) ListMessages(ctx *gin.Context) {
+	outbox := c.outbox(ctx)
+	if outbox == nil {
+		return
+	}
+
+	messages, err := outbox.List()
+	if err != nil {
+		utils.LogError("MqttOutboxController.ListMessages: %v", err)
+		ctx.JSON(http.StatusInternalServerError, dtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		return
+	}
+
+	topicPrefix := ctx.Query("topic")
+	items := make([]dtos.MqttOutboxMessageDTO, 0, len(messages))
+	for _, m := range messages {
+		if topicPrefix != "" && !strings.HasPrefix(m.Topic, topicPrefix) {
+			continue
+		}
+		items = append(items, dtos.MqttOutboxMessageDTO{
+			ID:          m.ID,
+			Topic:       m.Topic,
+			QoS:         m.QoS,
+			Retained:    m.Retained,
+			Payload:     string(m.Payload),
+			PayloadSize: len(m.Payload),
+			DedupKey:    m.DedupKey,
+			EnqueuedAt:  m.EnqueuedAt,
+			ExpiresAt:   m.ExpiresAt,
+			Attempts:    m.Attempts,
+			LastError:   m.LastError,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Outbox retrieved successfully",
+		Data: dtos.MqttOutboxListResponseDTO{
+			Connected: c.mqttSvc.IsConnected(),
+			Total:     len(items),
+			Messages:  items,
+		},
+	})
+}
+
+// PurgeMessages handles DELETE /api/mqtt/outbox
+// @Summary Purge queued MQTT messages
+// @Description Remove all queued publishes, or only those whose topic starts with the given prefix. Purged messages are never replayed.
+// @Tags 08. Common
+// @Produce json
+// @Security BearerAuth
+// @Param topic query string false "Only purge messages whose topic starts with this prefix"
+// @Success 200 {object} dtos.StandardResponse{data=dtos.MqttOutboxPurgeResponseDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Failure      503  {object}  dtos.ErrorResponse
+// @Router /api/mqtt/outbox [delete]
+func (c *MqttOutboxController) PurgeMessages(ctx *gin.Context) {
+	outbox := c.outbox(ctx)
+	if outbox == nil {
+		return
+	}
+
+	purged, err := outbox.Purge(ctx.Query("topic"))
+	if err != nil {
+		utils.LogError("MqttOutboxController.PurgeMessages: %v", err)
+		ctx.JSON(http.StatusInternalServerError, dtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		return
+	}
+
+	utils.LogInfo("MqttOutboxController.PurgeMessages: Purged %d queued message(s)", purged)
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Outbox purged successfully",
+		Data:    dtos.MqttOutboxPurgeResponseDTO{Purged: purged},
+	})
+}
+
+// DeleteMessage handles DELETE /api/mqtt/outbox/:id
+// @Summary Delete a queued MQTT message
+// @Description Remove a single queued publish so it is never replayed.
+// @Tags 08. Common
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Outbox message ID"
+// @Success 200 {object} dtos.StandardResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Failure      503  {object}  dtos.ErrorResponse
+// @Router /api/mqtt/outbox/{id} [delete]
+func (c *MqttOutboxController) DeleteMessage(ctx *gin.Context) {
+	outbox := c.outbox(ctx)
+	if outbox == nil {
+		return
+	}
+
+	found, err := outbox.Delete(ctx.Param("id"))
+	if err != nil {
+		utils.LogError("MqttOutboxController.DeleteMessage: %v", err)
+		ctx.JSON(http.StatusInternalServerError, dtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	if !found {
+		ctx.JSON(http.StatusNotFound, dtos.StandardResponse{
+			Status:  false,
+			Message: "Message not found",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Message deleted successfully",
+	})
+}
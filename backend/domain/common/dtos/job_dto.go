@@ -0,0 +1,29 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobDTO is a background job of the job queue
+type JobDTO struct {
+	ID              string          `json:"id" example:"4f9c0d1e-8a7b-4c3d-9e2f-1a2b3c4d5e6f"`
+	Type            string          `json:"type" example:"transcription"`
+	Status          string          `json:"status" example:"queued"` // queued, running, completed, failed, cancelled
+	Attempts        int             `json:"attempts" example:"1"`
+	MaxAttempts     int             `json:"max_attempts" example:"3"`
+	LastError       string          `json:"last_error,omitempty"`
+	CancelRequested bool            `json:"cancel_requested,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	RunAfter        time.Time       `json:"run_after"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+	Payload         json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+}
+
+// JobListResponseDTO represents the jobs of the job queue
+type JobListResponseDTO struct {
+	Total int      `json:"total" example:"1"`
+	Jobs  []JobDTO `json:"jobs"`
+}
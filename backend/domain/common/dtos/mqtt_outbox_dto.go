@@ -0,0 +1,30 @@
+package dtos
+
+import "time"
+
+// MqttOutboxMessageDTO is a publish waiting in the MQTT outbox
+type MqttOutboxMessageDTO struct {
+	ID          string    `json:"id" example:"00000000000000000042"`
+	Topic       string    `json:"topic" example:"users/AABBCCDDEEFF/dev/task"`
+	QoS         byte      `json:"qos" example:"0"`
+	Retained    bool      `json:"retained" example:"false"`
+	Payload     string    `json:"payload" example:"{\"event\":\"completed\"}"`
+	PayloadSize int       `json:"payload_size" example:"21"`
+	DedupKey    string    `json:"dedup_key,omitempty" example:"task:4f9c0d1e"`
+	EnqueuedAt  time.Time `json:"enqueued_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Attempts    int       `json:"attempts" example:"1"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// MqttOutboxListResponseDTO represents the queued MQTT messages
+type MqttOutboxListResponseDTO struct {
+	Connected bool                   `json:"connected" example:"false"`
+	Total     int                    `json:"total" example:"1"`
+	Messages  []MqttOutboxMessageDTO `json:"messages"`
+}
+
+// MqttOutboxPurgeResponseDTO represents the result of purging the MQTT outbox
+type MqttOutboxPurgeResponseDTO struct {
+	Purged int `json:"purged" example:"3"`
+}
@@ -3,9 +3,12 @@ package infrastructure
 import (
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"sensio/domain/common/utils"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -14,15 +17,38 @@ import (
 // DB is the global database instance
 var DB *gorm.DB
 
-// InitDB initializes the database connection pool using MySQL.
+// Supported values of DB_DRIVER
+const (
+	DBDriverMySQL  = "mysql"
+	DBDriverSQLite = "sqlite"
+)
+
+// InitDB initializes the database connection pool using the driver selected by DB_DRIVER:
+// MySQL (default) or an embedded SQLite file database in WAL mode.
 // Returns the database instance and any error encountered.
 func InitDB() (*gorm.DB, error) {
 	cfg := utils.GetConfig()
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC",
-		cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
-	dialector := mysql.Open(dsn)
-	log.Printf("📡 Initializing database using MySQL at %s:%s", cfg.DBHost, cfg.DBPort)
+	var dialector gorm.Dialector
+	switch cfg.DBDriver {
+	case DBDriverMySQL, "":
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC",
+			cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
+		dialector = mysql.Open(dsn)
+		log.Printf("📡 Initializing database using MySQL at %s:%s", cfg.DBHost, cfg.DBPort)
+	case DBDriverSQLite:
+		if dir := filepath.Dir(cfg.SQLitePath); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create SQLite directory: %w", err)
+			}
+		}
+		// WAL lets API reads proceed while MQTT status reports are written; writers wait on the busy timeout
+		dsn := cfg.SQLitePath + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=synchronous(NORMAL)"
+		dialector = sqlite.Open(dsn)
+		log.Printf("📡 Initializing database using SQLite at %s", cfg.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q (expected %s or %s)", cfg.DBDriver, DBDriverMySQL, DBDriverSQLite)
+	}
 
 	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.New(
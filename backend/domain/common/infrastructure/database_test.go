@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"os"
+	"path/filepath"
 	"sensio/domain/common/utils"
 	"testing"
 )
@@ -30,6 +31,39 @@ func TestInitDB(t *testing.T) {
 			t.Fatal("Expected error when initializing with invalid MySQL config, got nil")
 		}
 	})
+
+	t.Run("SQLite File", func(t *testing.T) {
+		t.Setenv("GO_TEST", "true")
+		t.Setenv("DB_DRIVER", "sqlite")
+		t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "data", "sensio.db"))
+		utils.AppConfig = nil
+		defer func() { utils.AppConfig = nil }()
+
+		db, err := InitDB()
+		if err != nil {
+			t.Fatalf("Expected SQLite to initialize, got: %v", err)
+		}
+		defer func() { _ = CloseDB() }()
+
+		var journalMode string
+		if err := db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error; err != nil || journalMode != "wal" {
+			t.Errorf("Expected WAL journal mode, got %q (err=%v)", journalMode, err)
+		}
+		if err := PingDB(); err != nil {
+			t.Errorf("Expected ping to succeed, got: %v", err)
+		}
+	})
+
+	t.Run("Unsupported Driver", func(t *testing.T) {
+		t.Setenv("GO_TEST", "true")
+		t.Setenv("DB_DRIVER", "postgres")
+		utils.AppConfig = nil
+		defer func() { utils.AppConfig = nil }()
+
+		if _, err := InitDB(); err == nil {
+			t.Fatal("Expected error for an unsupported DB_DRIVER, got nil")
+		}
+	})
 }
 
 func TestPingDB(t *testing.T) {
@@ -0,0 +1,327 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sensio/domain/common/utils"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrDirtyDatabase is returned when a previous migration failed halfway. The schema must be repaired
+// by hand and the version set with `migrate force N` before migrations can run again.
+var ErrDirtyDatabase = errors.New("database schema is dirty")
+
+// schemaMigrationsTable holds a single row with the applied version, in the layout used by golang-migrate
+const schemaMigrationsTable = "schema_migrations"
+
+// migrationFilePattern matches 000001_create_terminal_table.up.sql and driver-specific
+// variants such as 000007_create_meetings_tables.up.sqlite.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)(?:\.(mysql|sqlite))?\.sql$`)
+
+// Migration is one numbered schema change of the migrations directory
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	up      string
+	down    string
+}
+
+// MigrationStatus describes the applied version and the migrations still to apply
+type MigrationStatus struct {
+	Version int         `json:"version"` // 0 when nothing has been applied
+	Dirty   bool        `json:"dirty"`
+	Latest  int         `json:"latest"`
+	Pending []Migration `json:"pending"`
+}
+
+type schemaMigration struct {
+	Version int64 `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Dirty   bool  `gorm:"column:dirty;not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return schemaMigrationsTable
+}
+
+// Migrator applies the numbered SQL files of the migrations directory in order and records
+// the applied version, so the schema can be moved forward and rolled back one step at a time
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration // sorted by version
+}
+
+// NewMigrator loads the migrations in dir for the database driver. A file with a driver suffix
+// replaces the generic file of the same version and direction for that driver.
+func NewMigrator(db *gorm.DB, dir fs.FS, driver string) (*Migrator, error) {
+	if driver == "" {
+		driver = DBDriverMySQL
+	}
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	type source struct {
+		name     string
+		file     string
+		specific bool
+	}
+	ups := make(map[int]source)
+	downs := make(map[int]source)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		if match[4] != "" && match[4] != driver {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		files := ups
+		if match[3] == "down" {
+			files = downs
+		}
+		existing, found := files[version]
+		specific := match[4] != ""
+		if found && existing.name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, existing.file, entry.Name())
+		}
+		if found && existing.specific == specific {
+			return nil, fmt.Errorf("duplicate migration file %s", entry.Name())
+		}
+		if !found || specific {
+			files[version] = source{name: match[2], file: entry.Name(), specific: specific}
+		}
+	}
+
+	m := &Migrator{db: db}
+	for version, up := range ups {
+		down, ok := downs[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no down file", up.file)
+		}
+		upSQL, err := fs.ReadFile(dir, up.file)
+		if err != nil {
+			return nil, err
+		}
+		downSQL, err := fs.ReadFile(dir, down.file)
+		if err != nil {
+			return nil, err
+		}
+		m.migrations = append(m.migrations, Migration{Version: version, Name: up.name, up: string(upSQL), down: string(downSQL)})
+	}
+	for version, down := range downs {
+		if _, ok := ups[version]; !ok {
+			return nil, fmt.Errorf("migration %s has no up file", down.file)
+		}
+	}
+	sort.Slice(m.migrations, func(i, j int) bool { return m.migrations[i].Version < m.migrations[j].Version })
+	return m, nil
+}
+
+// NewDefaultMigrator returns the migrator of the initialized database, reading MIGRATIONS_PATH
+func NewDefaultMigrator() (*Migrator, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	cfg := utils.GetConfig()
+	return NewMigrator(DB, os.DirFS(cfg.MigrationsPath), cfg.DBDriver)
+}
+
+// Migrations returns the known migrations in version order
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Version returns the applied version (0 when none) and whether the last migration failed halfway
+func (m *Migrator) Version() (int, bool, error) {
+	if err := m.db.AutoMigrate(&schemaMigration{}); err != nil {
+		return 0, false, fmt.Errorf("failed to create %s table: %w", schemaMigrationsTable, err)
+	}
+	var rows []schemaMigration
+	if err := m.db.Limit(1).Find(&rows).Error; err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, false, nil
+	}
+	return int(rows[0].Version), rows[0].Dirty, nil
+}
+
+// Status reports the applied version and the pending migrations
+func (m *Migrator) Status() (*MigrationStatus, error) {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+	status := &MigrationStatus{Version: version, Dirty: dirty, Pending: []Migration{}}
+	for _, migration := range m.migrations {
+		status.Latest = migration.Version
+		if migration.Version > version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Up applies every pending migration and returns how many were applied
+func (m *Migrator) Up() (int, error) {
+	if len(m.migrations) == 0 {
+		return 0, nil
+	}
+	return m.To(m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the last applied migration
+func (m *Migrator) Down() error {
+	version, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		return errors.New("no migration to roll back")
+	}
+	_, err = m.To(m.previousVersion(version))
+	return err
+}
+
+// To migrates up or down until the given version is applied (0 rolls back everything)
+// and returns how many migrations were run
+func (m *Migrator) To(target int) (int, error) {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d", ErrDirtyDatabase, version)
+	}
+	if target != 0 && m.find(target) == nil {
+		return 0, fmt.Errorf("unknown migration version %d", target)
+	}
+	if version != 0 && m.find(version) == nil {
+		return 0, fmt.Errorf("applied version %d has no migration file", version)
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if migration.Version <= version || migration.Version > target {
+			continue
+		}
+		if err := m.run(migration.Version, migration, migration.up); err != nil {
+			return count, err
+		}
+		count++
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version > version || migration.Version <= target {
+			continue
+		}
+		if err := m.run(m.previousVersion(migration.Version), migration, migration.down); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Force records the version as applied and clean without running any migration.
+// Used after repairing a dirty schema by hand, or to adopt a database created before migrations were tracked.
+func (m *Migrator) Force(version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	if _, _, err := m.Version(); err != nil {
+		return err
+	}
+	return m.setVersion(version, false)
+}
+
+// AdoptBaseline records baseline as applied on a database whose markerTable exists but that has no
+// schema version, i.e. whose tables were created before versions were tracked. Up then only runs the
+// migrations after baseline. It reports whether the version was set.
+func (m *Migrator) AdoptBaseline(baseline int, markerTable string) (bool, error) {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return false, err
+	}
+	if version != 0 || dirty || !m.db.Migrator().HasTable(markerTable) {
+		return false, nil
+	}
+	if err := m.Force(baseline); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// run executes the statements of one migration file. The version is marked dirty first, so a failure
+// halfway (DDL is not transactional on MySQL) leaves the database flagged instead of silently half-migrated.
+func (m *Migrator) run(resultVersion int, migration Migration, sql string) error {
+	if err := m.setVersion(resultVersion, true); err != nil {
+		return err
+	}
+	for _, statement := range splitStatements(sql) {
+		if err := m.db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("migration %06d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return m.setVersion(resultVersion, false)
+}
+
+func (m *Migrator) setVersion(version int, dirty bool) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&schemaMigration{}).Error; err != nil {
+			return err
+		}
+		if version == 0 && !dirty {
+			return nil
+		}
+		return tx.Create(&schemaMigration{Version: int64(version), Dirty: dirty}).Error
+	})
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) previousVersion(version int) int {
+	previous := 0
+	for _, migration := range m.migrations {
+		if migration.Version >= version {
+			break
+		}
+		previous = migration.Version
+	}
+	return previous
+}
+
+// splitStatements splits a migration file into statements, dropping `--` comment lines.
+// Migration files must not contain semicolons inside string literals.
+func splitStatements(sql string) []string {
+	var lines []string
+	for _, line := range strings.Split(sql, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
@@ -0,0 +1,176 @@
+package infrastructure
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestMigrator(t *testing.T, files fstest.MapFS) (*Migrator, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	m, err := NewMigrator(db, files, DBDriverSQLite)
+	if err != nil {
+		t.Fatalf("NewMigrator failed: %v", err)
+	}
+	return m, db
+}
+
+func migrationFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_create_items.up.sql":          {Data: []byte("-- items; with a semicolon in a comment\nCREATE TABLE items (id INTEGER PRIMARY KEY AUTO_INCREMENT);")},
+		"000001_create_items.up.sqlite.sql":   {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT);")},
+		"000001_create_items.down.sql":        {Data: []byte("DROP TABLE items;")},
+		"000002_add_item_name.up.sql":         {Data: []byte("ALTER TABLE items ADD COLUMN name TEXT;\nCREATE INDEX idx_items_name ON items(name);")},
+		"000002_add_item_name.down.sql":       {Data: []byte("DROP INDEX idx_items_name;\nALTER TABLE items DROP COLUMN name;")},
+		"000003_create_tags.up.sql":           {Data: []byte("CREATE TABLE tags (id TEXT PRIMARY KEY);")},
+		"000003_create_tags.down.sql":         {Data: []byte("DROP TABLE tags;")},
+		"000003_create_tags.down.mysql.sql":   {Data: []byte("DROP TABLE tags CASCADE;")},
+		"README.md":                           {Data: []byte("not a migration")},
+		"000004_broken_for_other_driver.keep": {Data: []byte("ignored")},
+	}
+}
+
+func TestMigrator_UpDownTo(t *testing.T) {
+	m, db := newTestMigrator(t, migrationFiles())
+
+	applied, err := m.Up()
+	if err != nil || applied != 3 {
+		t.Fatalf("Up() = %d, %v; want 3 migrations", applied, err)
+	}
+	if !db.Migrator().HasColumn("items", "name") || !db.Migrator().HasTable("tags") {
+		t.Fatal("expected every migration to be applied")
+	}
+	if version, dirty, _ := m.Version(); version != 3 || dirty {
+		t.Fatalf("Version() = %d dirty=%v; want 3 clean", version, dirty)
+	}
+	if applied, _ := m.Up(); applied != 0 {
+		t.Fatalf("second Up() applied %d migrations; want 0", applied)
+	}
+
+	if err := m.Down(); err != nil {
+		t.Fatalf("Down() failed: %v", err)
+	}
+	if db.Migrator().HasTable("tags") {
+		t.Fatal("expected Down() to roll back the last migration only")
+	}
+
+	if count, err := m.To(0); err != nil || count != 2 {
+		t.Fatalf("To(0) = %d, %v; want 2 rollbacks", count, err)
+	}
+	if db.Migrator().HasTable("items") {
+		t.Fatal("expected To(0) to roll back every migration")
+	}
+	if version, _, _ := m.Version(); version != 0 {
+		t.Fatalf("Version() = %d after To(0); want 0", version)
+	}
+
+	if count, err := m.To(2); err != nil || count != 2 {
+		t.Fatalf("To(2) = %d, %v; want 2 migrations", count, err)
+	}
+	status, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	if status.Version != 2 || status.Latest != 3 || len(status.Pending) != 1 || status.Pending[0].Name != "create_tags" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if _, err := m.To(7); err == nil {
+		t.Fatal("expected an error for an unknown version")
+	}
+}
+
+func TestMigrator_AdoptBaseline(t *testing.T) {
+	m, db := newTestMigrator(t, migrationFiles())
+
+	// A fresh database is migrated from scratch
+	if adopted, err := m.AdoptBaseline(2, "items"); err != nil || adopted {
+		t.Fatalf("AdoptBaseline() on an empty database = %v, %v; want false", adopted, err)
+	}
+
+	// Tables created before versions were tracked hold the baseline schema only
+	if err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)").Error; err != nil {
+		t.Fatalf("failed to create legacy table: %v", err)
+	}
+	if adopted, err := m.AdoptBaseline(2, "items"); err != nil || !adopted {
+		t.Fatalf("AdoptBaseline() on a legacy database = %v, %v; want true", adopted, err)
+	}
+	if applied, err := m.Up(); err != nil || applied != 1 {
+		t.Fatalf("Up() after adoption = %d, %v; want only the migration after the baseline", applied, err)
+	}
+	if !db.Migrator().HasTable("tags") {
+		t.Fatal("expected the tables after the baseline to be created")
+	}
+
+	// A versioned database is left alone
+	if adopted, err := m.AdoptBaseline(2, "items"); err != nil || adopted {
+		t.Fatalf("AdoptBaseline() on a versioned database = %v, %v; want false", adopted, err)
+	}
+	if version, _, _ := m.Version(); version != 3 {
+		t.Fatalf("Version() = %d; want 3", version)
+	}
+}
+
+func TestMigrator_RefusesDirtyDatabase(t *testing.T) {
+	files := migrationFiles()
+	files["000003_create_tags.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE tags (id TEXT PRIMARY KEY);\nCREATE TABLE broken (")}
+	m, db := newTestMigrator(t, files)
+
+	if _, err := m.Up(); err == nil {
+		t.Fatal("expected the broken migration to fail")
+	}
+	version, dirty, err := m.Version()
+	if err != nil || version != 3 || !dirty {
+		t.Fatalf("Version() = %d dirty=%v err=%v; want 3 dirty", version, dirty, err)
+	}
+	if _, err := m.Up(); !errors.Is(err, ErrDirtyDatabase) {
+		t.Fatalf("Up() on a dirty database = %v; want ErrDirtyDatabase", err)
+	}
+	if err := m.Down(); !errors.Is(err, ErrDirtyDatabase) {
+		t.Fatalf("Down() on a dirty database = %v; want ErrDirtyDatabase", err)
+	}
+
+	// Repair by hand, then mark the version clean
+	if err := db.Exec("DROP TABLE tags").Error; err != nil {
+		t.Fatalf("repair failed: %v", err)
+	}
+	if err := m.Force(2); err != nil {
+		t.Fatalf("Force(2) failed: %v", err)
+	}
+	if version, dirty, _ := m.Version(); version != 2 || dirty {
+		t.Fatalf("Version() = %d dirty=%v after Force; want 2 clean", version, dirty)
+	}
+}
+
+func TestNewMigrator_InvalidFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down": {
+			"000001_create_items.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"version reused": {
+			"000001_create_items.up.sql":   {Data: []byte("SELECT 1;")},
+			"000001_create_items.down.sql": {Data: []byte("SELECT 1;")},
+			"000001_create_tags.up.sql":    {Data: []byte("SELECT 1;")},
+			"000001_create_tags.down.sql":  {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewMigrator(nil, files, DBDriverSQLite); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
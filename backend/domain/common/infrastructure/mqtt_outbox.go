@@ -0,0 +1,310 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sensio/domain/common/utils"
+)
+
+const (
+	mqttOutboxMessagePrefix = "mqtt:outbox:msg:"
+	mqttOutboxDedupPrefix   = "mqtt:outbox:dedup:"
+)
+
+// MqttPublishOptions tunes how a publish is queued when the broker is unreachable
+type MqttPublishOptions struct {
+	// DedupKey makes a newer queued message replace an older one with the same key (latest wins)
+	DedupKey string
+	// TTL overrides the outbox default lifetime; expired messages are never replayed
+	TTL time.Duration
+	// NoQueue publishes only while the broker is reachable and never queues the message,
+	// for publishes that would be stale by the time they are replayed
+	NoQueue bool
+}
+
+// MqttOutboxMessage is a publish waiting in the outbox
+type MqttOutboxMessage struct {
+	ID         string    `json:"id"`
+	Topic      string    `json:"topic"`
+	QoS        byte      `json:"qos"`
+	Retained   bool      `json:"retained"`
+	Payload    []byte    `json:"payload"`
+	DedupKey   string    `json:"dedup_key,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// MqttOutbox is a Badger-backed FIFO of publishes that could not be delivered.
+// Message keys embed a zero-padded sequence number so Badger's key order is the publish order.
+// Keys are outside the "cache:" prefix, so flushing the cache keeps the queue.
+type MqttOutbox struct {
+	badger      *BadgerService
+	defaultTTL  time.Duration
+	maxMessages int
+
+	mu  sync.Mutex
+	seq uint64
+}
+
+// NewMqttOutbox creates an outbox and resumes the sequence of messages persisted by a previous run
+func NewMqttOutbox(badger *BadgerService, defaultTTL time.Duration, maxMessages int) *MqttOutbox {
+	if defaultTTL <= 0 {
+		defaultTTL = 24 * time.Hour
+	}
+	o := &MqttOutbox{
+		badger:      badger,
+		defaultTTL:  defaultTTL,
+		maxMessages: maxMessages,
+	}
+
+	keys, _ := badger.GetAllKeysWithPrefix(mqttOutboxMessagePrefix)
+	for _, k := range keys {
+		if seq, err := strconv.ParseUint(strings.TrimPrefix(k, mqttOutboxMessagePrefix), 10, 64); err == nil && seq > o.seq {
+			o.seq = seq
+		}
+	}
+	if len(keys) > 0 {
+		utils.LogInfo("MQTT Outbox: %d queued message(s) restored from disk", len(keys))
+	}
+	return o
+}
+
+// Enqueue persists a publish. payload may be []byte, string or any JSON-marshalable value.
+func (o *MqttOutbox) Enqueue(topic string, qos byte, retained bool, payload interface{}, opts MqttPublishOptions) (*MqttOutboxMessage, error) {
+	body, err := outboxPayloadBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = o.defaultTTL
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if opts.DedupKey != "" {
+		if prev, _ := o.badger.Get(mqttOutboxDedupPrefix + opts.DedupKey); prev != nil {
+			_ = o.badger.Delete(string(prev))
+			utils.LogDebug("MQTT Outbox: Replaced queued message %s (dedup_key=%s)", string(prev), opts.DedupKey)
+		}
+	}
+
+	o.seq++
+	now := time.Now()
+	msg := &MqttOutboxMessage{
+		ID:         fmt.Sprintf("%020d", o.seq),
+		Topic:      topic,
+		QoS:        qos,
+		Retained:   retained,
+		Payload:    body,
+		DedupKey:   opts.DedupKey,
+		EnqueuedAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := o.save(msg); err != nil {
+		return nil, err
+	}
+	if opts.DedupKey != "" {
+		_ = o.badger.SetWithTTL(mqttOutboxDedupPrefix+opts.DedupKey, []byte(mqttOutboxMessagePrefix+msg.ID), ttl)
+	}
+
+	o.trim()
+	return msg, nil
+}
+
+// List returns the queued messages in publish order, skipping expired ones
+func (o *MqttOutbox) List() ([]MqttOutboxMessage, error) {
+	keys, err := o.badger.GetAllKeysWithPrefix(mqttOutboxMessagePrefix)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	messages := make([]MqttOutboxMessage, 0, len(keys))
+	for _, k := range keys {
+		msg, err := o.load(k)
+		if err != nil || msg == nil || now.After(msg.ExpiresAt) {
+			continue
+		}
+		messages = append(messages, *msg)
+	}
+	return messages, nil
+}
+
+// Len returns the number of messages stored in the outbox (expired ones may be counted until Badger drops them)
+func (o *MqttOutbox) Len() int {
+	keys, _ := o.badger.GetAllKeysWithPrefix(mqttOutboxMessagePrefix)
+	return len(keys)
+}
+
+// Delete removes a single message; it returns false when the message does not exist
+func (o *MqttOutbox) Delete(id string) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msg, err := o.load(mqttOutboxMessagePrefix + id)
+	if err != nil || msg == nil {
+		return false, err
+	}
+	o.remove(msg)
+	return true, nil
+}
+
+// Purge removes all messages, or only those whose topic starts with topicPrefix, and returns how many were removed
+func (o *MqttOutbox) Purge(topicPrefix string) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	keys, err := o.badger.GetAllKeysWithPrefix(mqttOutboxMessagePrefix)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, k := range keys {
+		msg, err := o.load(k)
+		if err != nil || msg == nil {
+			continue
+		}
+		if topicPrefix != "" && !strings.HasPrefix(msg.Topic, topicPrefix) {
+			continue
+		}
+		o.remove(msg)
+		removed++
+	}
+	return removed, nil
+}
+
+// Drain publishes queued messages in order and removes each one once publish succeeds.
+// It stops at the first failure so later messages never overtake it, and returns how many were sent.
+// Messages enqueued while draining are sent in the same call. The lock is not held while publishing,
+// so callers can keep enqueueing; Drain itself must not run concurrently.
+func (o *MqttOutbox) Drain(publish func(msg MqttOutboxMessage) error) (int, error) {
+	sent := 0
+	for {
+		keys, err := o.badger.GetAllKeysWithPrefix(mqttOutboxMessagePrefix)
+		if err != nil {
+			return sent, err
+		}
+		if len(keys) == 0 {
+			return sent, nil
+		}
+
+		progressed := false
+		for _, k := range keys {
+			o.mu.Lock()
+			msg, err := o.load(k)
+			if err == nil && msg != nil && time.Now().After(msg.ExpiresAt) {
+				o.remove(msg)
+				msg = nil
+			}
+			o.mu.Unlock()
+			if err != nil || msg == nil {
+				continue
+			}
+
+			if err := publish(*msg); err != nil {
+				o.mu.Lock()
+				// Keep the attempt count unless the message was replaced or purged meanwhile
+				if current, _ := o.load(k); current != nil {
+					current.Attempts++
+					current.LastError = err.Error()
+					_ = o.save(current)
+				}
+				o.mu.Unlock()
+				return sent, err
+			}
+
+			o.mu.Lock()
+			o.remove(msg)
+			o.mu.Unlock()
+			sent++
+			progressed = true
+		}
+		if !progressed {
+			return sent, nil
+		}
+	}
+}
+
+// trim drops the oldest messages beyond maxMessages; callers hold o.mu
+func (o *MqttOutbox) trim() {
+	if o.maxMessages <= 0 {
+		return
+	}
+	keys, _ := o.badger.GetAllKeysWithPrefix(mqttOutboxMessagePrefix)
+	for i := 0; i < len(keys)-o.maxMessages; i++ {
+		msg, err := o.load(keys[i])
+		if err != nil || msg == nil {
+			_ = o.badger.Delete(keys[i])
+			continue
+		}
+		utils.LogWarn("MQTT Outbox: Queue full (%d), dropping oldest message %s for %s", o.maxMessages, msg.ID, msg.Topic)
+		o.remove(msg)
+	}
+}
+
+func (o *MqttOutbox) save(msg *MqttOutboxMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox message: %w", err)
+	}
+	ttl := time.Until(msg.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return o.badger.SetWithTTL(mqttOutboxMessagePrefix+msg.ID, data, ttl)
+}
+
+func (o *MqttOutbox) load(key string) (*MqttOutboxMessage, error) {
+	data, err := o.badger.Get(key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var msg MqttOutboxMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		utils.LogWarn("MQTT Outbox: Dropping corrupted message %s: %v", key, err)
+		_ = o.badger.Delete(key)
+		return nil, nil
+	}
+	return &msg, nil
+}
+
+// remove deletes a message and its dedup index entry (if it still points at this message)
+func (o *MqttOutbox) remove(msg *MqttOutboxMessage) {
+	key := mqttOutboxMessagePrefix + msg.ID
+	_ = o.badger.Delete(key)
+	if msg.DedupKey != "" {
+		if current, _ := o.badger.Get(mqttOutboxDedupPrefix + msg.DedupKey); string(current) == key {
+			_ = o.badger.Delete(mqttOutboxDedupPrefix + msg.DedupKey)
+		}
+	}
+}
+
+func outboxPayloadBytes(payload interface{}) ([]byte, error) {
+	switch p := payload.(type) {
+	case []byte:
+		return p, nil
+	case string:
+		return []byte(p), nil
+	case bytes.Buffer:
+		return p.Bytes(), nil
+	case *bytes.Buffer:
+		return p.Bytes(), nil
+	case nil:
+		return []byte{}, nil
+	default:
+		data, err := json.Marshal(p)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported MQTT payload type %T: %w", payload, err)
+		}
+		return data, nil
+	}
+}
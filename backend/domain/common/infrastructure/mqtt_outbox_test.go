@@ -0,0 +1,152 @@
+package infrastructure
+
+import (
+	"errors"
+	"sensio/domain/common/utils"
+	"testing"
+	"time"
+)
+
+func newTestOutbox(t *testing.T, maxMessages int) (*MqttOutbox, *BadgerService) {
+	t.Helper()
+	utils.AppConfig = nil
+	_ = utils.GetConfig()
+
+	badger, err := NewBadgerService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewBadgerService failed: %v", err)
+	}
+	t.Cleanup(func() { _ = badger.Close() })
+	return NewMqttOutbox(badger, time.Hour, maxMessages), badger
+}
+
+func TestMqttOutbox_DrainPreservesOrder(t *testing.T) {
+	outbox, _ := newTestOutbox(t, 0)
+	for _, topic := range []string{"a", "b", "c"} {
+		if _, err := outbox.Enqueue(topic, 0, false, topic, MqttPublishOptions{}); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+
+	var got []string
+	sent, err := outbox.Drain(func(msg MqttOutboxMessage) error {
+		got = append(got, msg.Topic)
+		return nil
+	})
+	if err != nil || sent != 3 {
+		t.Fatalf("expected 3 sent, got %d (%v)", sent, err)
+	}
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("unexpected replay order: %v", got)
+	}
+	if outbox.Len() != 0 {
+		t.Errorf("expected empty outbox, got %d", outbox.Len())
+	}
+}
+
+func TestMqttOutbox_DedupKeepsLatest(t *testing.T) {
+	outbox, _ := newTestOutbox(t, 0)
+	_, _ = outbox.Enqueue("users/AA/dev/task", 0, false, `{"status":"running"}`, MqttPublishOptions{DedupKey: "task:1"})
+	_, _ = outbox.Enqueue("other", 0, false, "x", MqttPublishOptions{})
+	_, _ = outbox.Enqueue("users/AA/dev/task", 0, false, `{"status":"completed"}`, MqttPublishOptions{DedupKey: "task:1"})
+
+	messages, err := outbox.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if messages[0].Topic != "other" || string(messages[1].Payload) != `{"status":"completed"}` {
+		t.Errorf("expected the latest dedup message to be queued last, got %+v", messages)
+	}
+}
+
+func TestMqttOutbox_SkipsExpiredMessages(t *testing.T) {
+	outbox, _ := newTestOutbox(t, 0)
+	_, _ = outbox.Enqueue("short", 0, false, "x", MqttPublishOptions{TTL: 50 * time.Millisecond})
+	_, _ = outbox.Enqueue("long", 0, false, "y", MqttPublishOptions{})
+	time.Sleep(100 * time.Millisecond)
+
+	var got []string
+	_, _ = outbox.Drain(func(msg MqttOutboxMessage) error {
+		got = append(got, msg.Topic)
+		return nil
+	})
+	if len(got) != 1 || got[0] != "long" {
+		t.Errorf("expected only the unexpired message, got %v", got)
+	}
+}
+
+func TestMqttOutbox_TrimDropsOldest(t *testing.T) {
+	outbox, _ := newTestOutbox(t, 2)
+	for _, topic := range []string{"a", "b", "c"} {
+		_, _ = outbox.Enqueue(topic, 0, false, topic, MqttPublishOptions{})
+	}
+
+	messages, _ := outbox.List()
+	if len(messages) != 2 || messages[0].Topic != "b" || messages[1].Topic != "c" {
+		t.Errorf("expected [b c], got %+v", messages)
+	}
+}
+
+func TestMqttOutbox_DrainStopsAtFirstFailure(t *testing.T) {
+	outbox, _ := newTestOutbox(t, 0)
+	for _, topic := range []string{"a", "b", "c"} {
+		_, _ = outbox.Enqueue(topic, 0, false, topic, MqttPublishOptions{})
+	}
+
+	sent, err := outbox.Drain(func(msg MqttOutboxMessage) error {
+		if msg.Topic == "b" {
+			return errors.New("not connected")
+		}
+		return nil
+	})
+	if err == nil || sent != 1 {
+		t.Fatalf("expected 1 sent and an error, got %d (%v)", sent, err)
+	}
+
+	messages, _ := outbox.List()
+	if len(messages) != 2 || messages[0].Topic != "b" {
+		t.Fatalf("expected b to stay at the head, got %+v", messages)
+	}
+	if messages[0].Attempts != 1 || messages[0].LastError != "not connected" {
+		t.Errorf("expected the failed attempt to be recorded, got %+v", messages[0])
+	}
+}
+
+func TestMqttOutbox_PurgeAndDelete(t *testing.T) {
+	outbox, _ := newTestOutbox(t, 0)
+	first, _ := outbox.Enqueue("users/AA/dev/task", 0, false, "x", MqttPublishOptions{})
+	_, _ = outbox.Enqueue("users/BB/dev/task", 0, false, "y", MqttPublishOptions{})
+	_, _ = outbox.Enqueue("users/BB/dev/chat", 0, false, "z", MqttPublishOptions{})
+
+	if found, err := outbox.Delete(first.ID); err != nil || !found {
+		t.Fatalf("expected Delete to find %s, got %v (%v)", first.ID, found, err)
+	}
+	if found, _ := outbox.Delete(first.ID); found {
+		t.Error("expected second Delete to report not found")
+	}
+
+	purged, err := outbox.Purge("users/BB/dev/task")
+	if err != nil || purged != 1 {
+		t.Fatalf("expected 1 purged, got %d (%v)", purged, err)
+	}
+	if outbox.Len() != 1 {
+		t.Errorf("expected 1 remaining message, got %d", outbox.Len())
+	}
+}
+
+func TestMqttOutbox_RestoresSequence(t *testing.T) {
+	outbox, badger := newTestOutbox(t, 0)
+	_, _ = outbox.Enqueue("a", 0, false, "x", MqttPublishOptions{})
+	_, _ = outbox.Enqueue("b", 0, false, "y", MqttPublishOptions{})
+
+	restored := NewMqttOutbox(badger, time.Hour, 0)
+	_, _ = restored.Enqueue("c", 0, false, "z", MqttPublishOptions{})
+
+	messages, _ := restored.List()
+	if len(messages) != 3 || messages[2].Topic != "c" {
+		t.Errorf("expected new messages after restored ones, got %+v", messages)
+	}
+}
@@ -0,0 +1,374 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"sensio/domain/common/utils"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+)
+
+// Error codes carried by MQTT RPC responses
+const (
+	MqttRpcCodeOK           = "ok"
+	MqttRpcCodeBadRequest   = "bad_request"
+	MqttRpcCodeUnauthorized = "unauthorized"
+	MqttRpcCodeNotFound     = "not_found"
+	MqttRpcCodeInternal     = "internal_error"
+)
+
+// MqttRpcReplySuffix is appended to a request topic to build its reply topic (users/{mac}/{env}/chat -> .../chat/answer)
+const MqttRpcReplySuffix = "answer"
+
+// MqttRpcTerminal is the terminal a request was published from, resolved from the MAC in the topic
+type MqttRpcTerminal struct {
+	ID         string
+	MacAddress string
+	TuyaUID    string
+}
+
+// MqttRpcTerminalResolver looks up a registered terminal by MAC address.
+// It returns nil without error when the MAC is not registered.
+type MqttRpcTerminalResolver interface {
+	ResolveTerminal(mac string) (*MqttRpcTerminal, error)
+}
+
+// MqttRpcRequest is a single request received on a routed topic
+type MqttRpcRequest struct {
+	Topic     string
+	MAC       string
+	RequestID string
+	Vars      map[string]string
+	Payload   []byte
+	// Terminal is set for routes registered with HandleTerminal
+	Terminal *MqttRpcTerminal
+}
+
+// Bind decodes the payload into v; a malformed payload is reported as bad_request
+func (r *MqttRpcRequest) Bind(v interface{}) error {
+	if err := json.Unmarshal(r.Payload, v); err != nil {
+		return &MqttRpcError{
+			Code:    MqttRpcCodeBadRequest,
+			Message: "Validation Error",
+			Details: []utils.ValidationErrorDetail{{Field: "payload", Message: "Invalid request body: " + err.Error()}},
+		}
+	}
+	return nil
+}
+
+// MqttRpcResult is the successful outcome of a handler
+type MqttRpcResult struct {
+	Message string
+	Data    interface{}
+}
+
+// MqttRpcError is a failed outcome of a handler. Data may carry a partial result (e.g. a scene run report).
+type MqttRpcError struct {
+	Code    string
+	Message string
+	Details []utils.ValidationErrorDetail
+	Data    interface{}
+}
+
+func (e *MqttRpcError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// NewMqttRpcError creates a new MqttRpcError
+func NewMqttRpcError(code, message string) *MqttRpcError {
+	return &MqttRpcError{Code: code, Message: message}
+}
+
+// MqttRpcResponse is the envelope published on the reply topic.
+// It is a superset of dtos.StandardResponse so existing clients keep reading status/message/data/details.
+type MqttRpcResponse struct {
+	RequestID string      `json:"request_id"`
+	Code      string      `json:"code"`
+	Status    bool        `json:"status"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// MqttRpcHandler handles a routed request. The router publishes the returned result or error on the reply topic;
+// a nil result and nil error means the handler replied (or chose not to reply) by itself.
+type MqttRpcHandler func(req *MqttRpcRequest) (*MqttRpcResult, error)
+
+type mqttRpcRoute struct {
+	pattern         []string
+	filter          string
+	handler         MqttRpcHandler
+	requireTerminal bool
+}
+
+// MqttRpcRouter dispatches messages published on topic patterns such as users/{mac}/{env}/scene/run
+// to handlers, resolves the publishing terminal from the {mac} segment and replies on <topic>/answer.
+type MqttRpcRouter struct {
+	mqttSvc  IMqttService
+	env      string
+	resolver MqttRpcTerminalResolver
+
+	mu     sync.RWMutex
+	routes []*mqttRpcRoute
+}
+
+// NewMqttRpcRouter creates a router. {env} in patterns is replaced with env; mqttSvc may be nil (routes are then only dispatchable directly).
+func NewMqttRpcRouter(mqttSvc IMqttService, env string, resolver MqttRpcTerminalResolver) *MqttRpcRouter {
+	return &MqttRpcRouter{
+		mqttSvc:  mqttSvc,
+		env:      env,
+		resolver: resolver,
+	}
+}
+
+// Handle registers a handler for a topic pattern and subscribes to it.
+// Segments written as {name} match any single topic level and are exposed in MqttRpcRequest.Vars.
+func (r *MqttRpcRouter) Handle(pattern string, handler MqttRpcHandler) error {
+	return r.add(pattern, handler, false)
+}
+
+// HandleTerminal is like Handle, but rejects requests whose {mac} is not a registered terminal
+// and exposes the terminal in MqttRpcRequest.Terminal.
+func (r *MqttRpcRouter) HandleTerminal(pattern string, handler MqttRpcHandler) error {
+	return r.add(pattern, handler, true)
+}
+
+func (r *MqttRpcRouter) add(pattern string, handler MqttRpcHandler, requireTerminal bool) error {
+	pattern = strings.ReplaceAll(pattern, "{env}", r.env)
+	segments := strings.Split(pattern, "/")
+	filterParts := make([]string, len(segments))
+	for i, s := range segments {
+		if isMqttRpcVar(s) {
+			filterParts[i] = "+"
+		} else {
+			filterParts[i] = s
+		}
+	}
+	route := &mqttRpcRoute{
+		pattern:         segments,
+		filter:          strings.Join(filterParts, "/"),
+		handler:         handler,
+		requireTerminal: requireTerminal,
+	}
+
+	r.mu.Lock()
+	r.routes = append(r.routes, route)
+	r.mu.Unlock()
+
+	if r.mqttSvc == nil {
+		return nil
+	}
+	err := r.mqttSvc.Subscribe(route.filter, 0, func(client mqtt.Client, msg mqtt.Message) {
+		// Handlers may call LLMs or Tuya; never block the MQTT client goroutine
+		go r.Dispatch(msg.Topic(), msg.Payload())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", route.filter, err)
+	}
+	utils.LogInfo("MQTT RPC: Routed %s", route.filter)
+	return nil
+}
+
+// Dispatch runs the handler matching topic and publishes its reply. It returns false when no route matches.
+func (r *MqttRpcRouter) Dispatch(topic string, payload []byte) bool {
+	topic = stripSharedPrefix(topic)
+
+	route, vars := r.match(topic)
+	if route == nil {
+		utils.LogWarn("MQTT RPC: No route for topic %s", topic)
+		return false
+	}
+	if len(payload) == 0 {
+		return true
+	}
+
+	req := &MqttRpcRequest{
+		Topic:     topic,
+		MAC:       vars["mac"],
+		RequestID: requestIDFromPayload(payload),
+		Vars:      vars,
+		Payload:   payload,
+	}
+	if req.RequestID == "" {
+		req.RequestID = uuid.New().String()
+	}
+
+	startedAt := time.Now()
+	utils.LogInfo("[%s] MQTT RPC: Received %s (%d bytes)", req.RequestID, topic, len(payload))
+
+	var (
+		result *MqttRpcResult
+		err    error
+	)
+	if route.requireTerminal {
+		err = r.resolveTerminal(req)
+	}
+	if err == nil {
+		result, err = route.handler(req)
+	}
+
+	if err != nil {
+		r.reply(req, errorResponse(req.RequestID, err))
+		utils.LogWarn("[%s] MQTT RPC: %s failed: %v | total_duration_ms=%d", req.RequestID, topic, err, time.Since(startedAt).Milliseconds())
+		return true
+	}
+	if result != nil {
+		r.reply(req, MqttRpcResponse{
+			RequestID: req.RequestID,
+			Code:      MqttRpcCodeOK,
+			Status:    true,
+			Message:   result.Message,
+			Data:      result.Data,
+		})
+	}
+	utils.LogInfo("[%s] MQTT RPC: %s completed | total_duration_ms=%d", req.RequestID, topic, time.Since(startedAt).Milliseconds())
+	return true
+}
+
+// Reply publishes a response for req on its reply topic. Handlers use it to send acknowledgements before their final result.
+func (r *MqttRpcRouter) Reply(req *MqttRpcRequest, resp MqttRpcResponse) {
+	if resp.RequestID == "" {
+		resp.RequestID = req.RequestID
+	}
+	r.reply(req, resp)
+}
+
+func (r *MqttRpcRouter) reply(req *MqttRpcRequest, resp MqttRpcResponse) {
+	if r.mqttSvc == nil || req.MAC == "" {
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		utils.LogError("[%s] MQTT RPC: Failed to marshal reply: %v", req.RequestID, err)
+		return
+	}
+	if err := r.mqttSvc.Publish(ReplyTopic(req.Topic), 0, false, data); err != nil {
+		utils.LogError("[%s] MQTT RPC: Failed to publish reply: %v", req.RequestID, err)
+	}
+}
+
+func (r *MqttRpcRouter) resolveTerminal(req *MqttRpcRequest) error {
+	if r.resolver == nil || req.MAC == "" {
+		return NewMqttRpcError(MqttRpcCodeUnauthorized, "Terminal not registered")
+	}
+	terminal, err := r.resolver.ResolveTerminal(req.MAC)
+	if err != nil {
+		utils.LogError("[%s] MQTT RPC: Terminal lookup failed for %s: %v", req.RequestID, req.MAC, err)
+		return NewMqttRpcError(MqttRpcCodeInternal, "Internal Server Error")
+	}
+	if terminal == nil {
+		return NewMqttRpcError(MqttRpcCodeUnauthorized, "Terminal not registered")
+	}
+	req.Terminal = terminal
+	return nil
+}
+
+func (r *MqttRpcRouter) match(topic string) (*mqttRpcRoute, map[string]string) {
+	levels := strings.Split(topic, "/")
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, route := range r.routes {
+		if len(route.pattern) != len(levels) {
+			continue
+		}
+		vars := map[string]string{}
+		matched := true
+		for i, seg := range route.pattern {
+			if isMqttRpcVar(seg) {
+				vars[seg[1:len(seg)-1]] = levels[i]
+				continue
+			}
+			if seg != levels[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return route, vars
+		}
+	}
+	return nil, nil
+}
+
+// ReplyTopic returns the topic replies to a request published on topic are sent to
+func ReplyTopic(topic string) string {
+	return stripSharedPrefix(topic) + "/" + MqttRpcReplySuffix
+}
+
+func errorResponse(requestID string, err error) MqttRpcResponse {
+	resp := MqttRpcResponse{RequestID: requestID, Status: false}
+
+	var rpcErr *MqttRpcError
+	var valErr *utils.ValidationError
+	var apiErr *utils.APIError
+	switch {
+	case errors.As(err, &rpcErr):
+		resp.Code = rpcErr.Code
+		resp.Message = rpcErr.Message
+		resp.Data = rpcErr.Data
+		if len(rpcErr.Details) > 0 {
+			resp.Details = rpcErr.Details
+		}
+	case errors.As(err, &valErr):
+		resp.Code = MqttRpcCodeBadRequest
+		resp.Message = valErr.Message
+		resp.Details = valErr.Details
+	case errors.As(err, &apiErr):
+		resp.Code = codeForStatus(apiErr.StatusCode)
+		resp.Message = apiErr.Message
+	case strings.Contains(strings.ToLower(err.Error()), "not found"):
+		resp.Code = MqttRpcCodeNotFound
+		resp.Message = "Not Found"
+	default:
+		resp.Code = MqttRpcCodeInternal
+		resp.Message = "Internal Server Error"
+	}
+	return resp
+}
+
+func codeForStatus(status int) string {
+	switch {
+	case status == http.StatusNotFound:
+		return MqttRpcCodeNotFound
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return MqttRpcCodeUnauthorized
+	case status >= 400 && status < 500:
+		return MqttRpcCodeBadRequest
+	default:
+		return MqttRpcCodeInternal
+	}
+}
+
+// requestIDFromPayload reads the correlation id a client may put in request_id
+func requestIDFromPayload(payload []byte) string {
+	var envelope struct {
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(envelope.RequestID)
+}
+
+func isMqttRpcVar(segment string) bool {
+	return len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// stripSharedPrefix removes a $share/<group>/ prefix from a topic
+func stripSharedPrefix(topic string) string {
+	if strings.HasPrefix(topic, "$share/") {
+		parts := strings.SplitN(topic, "/", 3)
+		if len(parts) == 3 {
+			return parts[2]
+		}
+	}
+	return topic
+}
@@ -0,0 +1,201 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+	"sensio/domain/common/utils"
+	"sync"
+	"testing"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+type fakeMqttService struct {
+	mu        sync.Mutex
+	filters   []string
+	published map[string][][]byte
+}
+
+func newFakeMqttService() *fakeMqttService {
+	return &fakeMqttService{published: map[string][][]byte{}}
+}
+
+func (f *fakeMqttService) Connect() error { return nil }
+func (f *fakeMqttService) Subscribe(topic string, qos byte, handler mqtt.MessageHandler) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.filters = append(f.filters, topic)
+	return nil
+}
+func (f *fakeMqttService) Unsubscribe(topic string) error { return nil }
+func (f *fakeMqttService) Publish(topic string, qos byte, retained bool, payload interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published[topic] = append(f.published[topic], payload.([]byte))
+	return nil
+}
+func (f *fakeMqttService) IsConnected() bool { return true }
+func (f *fakeMqttService) Close()            {}
+
+func (f *fakeMqttService) lastReply(t *testing.T, topic string) MqttRpcResponse {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	msgs := f.published[topic]
+	if len(msgs) == 0 {
+		t.Fatalf("expected a reply on %s, got topics %v", topic, f.published)
+	}
+	var resp MqttRpcResponse
+	if err := json.Unmarshal(msgs[len(msgs)-1], &resp); err != nil {
+		t.Fatalf("failed to decode reply: %v", err)
+	}
+	return resp
+}
+
+type fakeTerminalResolver map[string]*MqttRpcTerminal
+
+func (f fakeTerminalResolver) ResolveTerminal(mac string) (*MqttRpcTerminal, error) {
+	if mac == "BROKEN" {
+		return nil, errors.New("database not initialized")
+	}
+	return f[mac], nil
+}
+
+func TestMqttRpcRouter_SubscribesWithWildcards(t *testing.T) {
+	svc := newFakeMqttService()
+	router := NewMqttRpcRouter(svc, "dev", nil)
+
+	_ = router.Handle("users/{mac}/{env}/rpc/scene/run", func(req *MqttRpcRequest) (*MqttRpcResult, error) { return nil, nil })
+
+	if len(svc.filters) != 1 || svc.filters[0] != "users/+/dev/rpc/scene/run" {
+		t.Errorf("expected users/+/dev/rpc/scene/run, got %v", svc.filters)
+	}
+}
+
+func TestMqttRpcRouter_DispatchRepliesWithRequestID(t *testing.T) {
+	svc := newFakeMqttService()
+	router := NewMqttRpcRouter(svc, "dev", nil)
+
+	var got *MqttRpcRequest
+	_ = router.Handle("users/{mac}/{env}/chat", func(req *MqttRpcRequest) (*MqttRpcResult, error) {
+		got = req
+		return &MqttRpcResult{Message: "ok", Data: map[string]string{"answer": "hi"}}, nil
+	})
+
+	if !router.Dispatch("$share/sensio/users/AABBCC/dev/chat", []byte(`{"request_id":"req-1","prompt":"hello"}`)) {
+		t.Fatal("expected the topic to be routed")
+	}
+	if got == nil || got.MAC != "AABBCC" || got.RequestID != "req-1" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+
+	resp := svc.lastReply(t, "users/AABBCC/dev/chat/answer")
+	if !resp.Status || resp.Code != MqttRpcCodeOK || resp.RequestID != "req-1" || resp.Message != "ok" {
+		t.Errorf("unexpected reply: %+v", resp)
+	}
+}
+
+func TestMqttRpcRouter_GeneratesRequestID(t *testing.T) {
+	svc := newFakeMqttService()
+	router := NewMqttRpcRouter(svc, "dev", nil)
+	_ = router.Handle("users/{mac}/{env}/chat", func(req *MqttRpcRequest) (*MqttRpcResult, error) {
+		return &MqttRpcResult{Message: "ok"}, nil
+	})
+
+	router.Dispatch("users/AABBCC/dev/chat", []byte(`{"prompt":"hello"}`))
+	if resp := svc.lastReply(t, "users/AABBCC/dev/chat/answer"); resp.RequestID == "" {
+		t.Error("expected a generated request_id")
+	}
+}
+
+func TestMqttRpcRouter_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    string
+		message string
+	}{
+		{"validation", utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{{Field: "scene_id", Message: "scene_id is required"}}), MqttRpcCodeBadRequest, "Validation Error"},
+		{"rpc error", NewMqttRpcError(MqttRpcCodeNotFound, "Scene not found"), MqttRpcCodeNotFound, "Scene not found"},
+		{"api error", utils.NewAPIError(401, "token expired"), MqttRpcCodeUnauthorized, "token expired"},
+		{"not found", errors.New("record not found"), MqttRpcCodeNotFound, "Not Found"},
+		{"internal", errors.New("boom"), MqttRpcCodeInternal, "Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newFakeMqttService()
+			router := NewMqttRpcRouter(svc, "dev", nil)
+			_ = router.Handle("users/{mac}/{env}/rpc/scene/run", func(req *MqttRpcRequest) (*MqttRpcResult, error) {
+				return nil, tt.err
+			})
+
+			router.Dispatch("users/AABBCC/dev/rpc/scene/run", []byte(`{}`))
+			resp := svc.lastReply(t, "users/AABBCC/dev/rpc/scene/run/answer")
+			if resp.Status || resp.Code != tt.code || resp.Message != tt.message {
+				t.Errorf("expected %s/%q, got %+v", tt.code, tt.message, resp)
+			}
+		})
+	}
+}
+
+func TestMqttRpcRouter_BindRejectsMalformedPayload(t *testing.T) {
+	svc := newFakeMqttService()
+	router := NewMqttRpcRouter(svc, "dev", nil)
+	_ = router.Handle("users/{mac}/{env}/rpc/scene/run", func(req *MqttRpcRequest) (*MqttRpcResult, error) {
+		var body struct{ SceneID string }
+		if err := req.Bind(&body); err != nil {
+			return nil, err
+		}
+		return &MqttRpcResult{Message: "ok"}, nil
+	})
+
+	router.Dispatch("users/AABBCC/dev/rpc/scene/run", []byte(`not json`))
+	resp := svc.lastReply(t, "users/AABBCC/dev/rpc/scene/run/answer")
+	if resp.Code != MqttRpcCodeBadRequest || resp.Details == nil {
+		t.Errorf("expected bad_request with details, got %+v", resp)
+	}
+}
+
+func TestMqttRpcRouter_HandleTerminalResolvesMAC(t *testing.T) {
+	svc := newFakeMqttService()
+	resolver := fakeTerminalResolver{"AABBCC": {ID: "term-1", MacAddress: "AABBCC"}}
+	router := NewMqttRpcRouter(svc, "dev", resolver)
+
+	var terminalID string
+	_ = router.HandleTerminal("users/{mac}/{env}/rpc/terminal/config", func(req *MqttRpcRequest) (*MqttRpcResult, error) {
+		terminalID = req.Terminal.ID
+		return &MqttRpcResult{Message: "ok"}, nil
+	})
+
+	router.Dispatch("users/AABBCC/dev/rpc/terminal/config", []byte(`{}`))
+	if terminalID != "term-1" {
+		t.Errorf("expected terminal term-1, got %q", terminalID)
+	}
+
+	router.Dispatch("users/UNKNOWN/dev/rpc/terminal/config", []byte(`{}`))
+	if resp := svc.lastReply(t, "users/UNKNOWN/dev/rpc/terminal/config/answer"); resp.Code != MqttRpcCodeUnauthorized {
+		t.Errorf("expected unauthorized for unknown MAC, got %+v", resp)
+	}
+
+	router.Dispatch("users/BROKEN/dev/rpc/terminal/config", []byte(`{}`))
+	if resp := svc.lastReply(t, "users/BROKEN/dev/rpc/terminal/config/answer"); resp.Code != MqttRpcCodeInternal {
+		t.Errorf("expected internal_error when lookup fails, got %+v", resp)
+	}
+}
+
+func TestMqttRpcRouter_IgnoresUnroutedAndReplyTopics(t *testing.T) {
+	svc := newFakeMqttService()
+	router := NewMqttRpcRouter(svc, "dev", nil)
+	_ = router.Handle("users/{mac}/{env}/chat", func(req *MqttRpcRequest) (*MqttRpcResult, error) {
+		t.Fatal("handler must not run")
+		return nil, nil
+	})
+
+	if router.Dispatch("users/AABBCC/dev/chat/answer", []byte(`{}`)) {
+		t.Error("reply topics must not be routed")
+	}
+	if router.Dispatch("users/AABBCC/prod/chat", []byte(`{}`)) {
+		t.Error("other environments must not be routed")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"sensio/domain/common/utils"
@@ -27,6 +28,12 @@ type IMqttService interface {
 type MqttService struct {
 	client mqtt.Client
 	config *utils.Config
+
+	// Optional durable queue for publishes made while the broker is unreachable
+	outbox        *MqttOutbox
+	outboxPending atomic.Bool
+	draining      atomic.Bool
+	stopRetry     chan struct{}
 }
 
 // NewMqttService initializes a new MQTT service
@@ -116,6 +123,7 @@ func NewMqttService(cfg *utils.Config) *MqttService {
 
 	opts.SetOnConnectHandler(func(c mqtt.Client) {
 		utils.LogInfo("Common MQTT Connected to %s", cfg.MqttBroker)
+		go s.drainOutbox()
 	})
 
 	opts.SetConnectionLostHandler(func(c mqtt.Client, err error) {
@@ -182,28 +190,139 @@ func (s *MqttService) Unsubscribe(topic string) error {
 	return nil
 }
 
+// SetOutbox enables queueing publishes in outbox while the broker is unreachable.
+// Queued messages are replayed in order on (re)connect and retried every retryInterval.
+func (s *MqttService) SetOutbox(outbox *MqttOutbox, retryInterval time.Duration) {
+	s.outbox = outbox
+	if outbox == nil {
+		return
+	}
+	s.outboxPending.Store(outbox.Len() > 0)
+	if retryInterval <= 0 {
+		retryInterval = 10 * time.Second
+	}
+	s.stopRetry = make(chan struct{})
+	go s.retryOutbox(retryInterval, s.stopRetry)
+	if s.client.IsConnected() {
+		go s.drainOutbox()
+	}
+}
+
+// Outbox returns the outbox, or nil when publishes are not queued
+func (s *MqttService) Outbox() *MqttOutbox {
+	return s.outbox
+}
+
 // Publish publishes a message to a topic
 func (s *MqttService) Publish(topic string, qos byte, retained bool, payload interface{}) error {
+	return s.PublishWithOptions(topic, qos, retained, payload, MqttPublishOptions{})
+}
+
+// PublishWithOptions publishes a message; when an outbox is configured and the broker is unreachable
+// (or older messages are still queued) the message is queued with the given dedup key and TTL instead.
+func (s *MqttService) PublishWithOptions(topic string, qos byte, retained bool, payload interface{}, opts MqttPublishOptions) error {
+	_, err := s.PublishOrQueue(topic, qos, retained, payload, opts)
+	return err
+}
+
+// PublishOrQueue is PublishWithOptions that also reports whether the message was queued in the outbox
+// rather than delivered to the broker.
+func (s *MqttService) PublishOrQueue(topic string, qos byte, retained bool, payload interface{}, opts MqttPublishOptions) (bool, error) {
+	queue := s.outbox != nil && !opts.NoQueue
+	if queue && (!s.client.IsConnected() || s.outboxPending.Load()) {
+		return true, s.enqueue(topic, qos, retained, payload, opts)
+	}
+
 	if !s.client.IsConnected() {
 		// Debug log only - MQTT is optional for push notifications
 		utils.LogDebug("MQTT publish skipped: client not connected (broker: %s)", s.config.MqttBroker)
-		return fmt.Errorf("MQTT client not connected")
+		return false, fmt.Errorf("MQTT client not connected")
 	}
 
 	utils.LogDebug("MQTT publishing to topic: %s (payload size: %d bytes)", topic, len(fmt.Sprintf("%v", payload)))
 
-	token := s.client.Publish(topic, qos, retained, payload)
-	token.Wait()
-
-	if token.Error() != nil {
-		utils.LogError("MQTT publish to %s failed: %v", topic, token.Error())
-		return token.Error()
+	if err := s.publishNow(topic, qos, retained, payload); err != nil {
+		utils.LogError("MQTT publish to %s failed: %v", topic, err)
+		if queue {
+			return true, s.enqueue(topic, qos, retained, payload, opts)
+		}
+		return false, err
 	}
 
 	utils.LogDebug("MQTT publish success to topic: %s", topic)
+	return false, nil
+}
+
+// PublishWithOptions publishes through pub and passes opts when pub supports outbox options (e.g. *MqttService);
+// other publishers, such as test doubles, get a plain Publish.
+func PublishWithOptions(pub interface {
+	Publish(topic string, qos byte, retained bool, payload interface{}) error
+}, topic string, qos byte, retained bool, payload interface{}, opts MqttPublishOptions) error {
+	if p, ok := pub.(interface {
+		PublishWithOptions(topic string, qos byte, retained bool, payload interface{}, opts MqttPublishOptions) error
+	}); ok {
+		return p.PublishWithOptions(topic, qos, retained, payload, opts)
+	}
+	return pub.Publish(topic, qos, retained, payload)
+}
+
+func (s *MqttService) publishNow(topic string, qos byte, retained bool, payload interface{}) error {
+	token := s.client.Publish(topic, qos, retained, payload)
+	if !token.WaitTimeout(30 * time.Second) {
+		return fmt.Errorf("MQTT publish to %s timed out", topic)
+	}
+	return token.Error()
+}
+
+func (s *MqttService) enqueue(topic string, qos byte, retained bool, payload interface{}, opts MqttPublishOptions) error {
+	msg, err := s.outbox.Enqueue(topic, qos, retained, payload, opts)
+	if err != nil {
+		utils.LogError("MQTT Outbox: Failed to queue publish to %s: %v", topic, err)
+		return fmt.Errorf("MQTT client not connected and outbox unavailable: %w", err)
+	}
+	s.outboxPending.Store(true)
+	utils.LogDebug("MQTT Outbox: Queued message %s for %s", msg.ID, topic)
+	if s.client.IsConnected() {
+		go s.drainOutbox()
+	}
 	return nil
 }
 
+// drainOutbox replays queued messages in order; only one replay runs at a time
+func (s *MqttService) drainOutbox() {
+	if s.outbox == nil || !s.client.IsConnected() || !s.draining.CompareAndSwap(false, true) {
+		return
+	}
+	defer s.draining.Store(false)
+
+	sent, err := s.outbox.Drain(func(msg MqttOutboxMessage) error {
+		return s.publishNow(msg.Topic, msg.QoS, msg.Retained, msg.Payload)
+	})
+	if sent > 0 {
+		utils.LogInfo("MQTT Outbox: Replayed %d queued message(s)", sent)
+	}
+	if err != nil {
+		utils.LogWarn("MQTT Outbox: Replay interrupted, will retry: %v", err)
+		return
+	}
+	s.outboxPending.Store(s.outbox.Len() > 0)
+}
+
+func (s *MqttService) retryOutbox(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if s.outboxPending.Load() {
+				s.drainOutbox()
+			}
+		}
+	}
+}
+
 // IsConnected checks if the client is connected
 func (s *MqttService) IsConnected() bool {
 	return s.client.IsConnected()
@@ -211,6 +330,10 @@ func (s *MqttService) IsConnected() bool {
 
 // Close disconnects the client
 func (s *MqttService) Close() {
+	if s.stopRetry != nil {
+		close(s.stopRetry)
+		s.stopRetry = nil
+	}
 	if s.client != nil && s.client.IsConnected() {
 		s.client.Disconnect(250)
 		utils.LogInfo("Common MQTT Disconnected")
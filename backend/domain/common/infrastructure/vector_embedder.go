@@ -0,0 +1,127 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Embedder turns texts into dense vectors.
+// Name identifies the vector space (provider and model); vectors produced by
+// embedders with different names are never compared with each other.
+type Embedder interface {
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// HashEmbedderDimension is the vector size produced by HashEmbedder.
+const HashEmbedderDimension = 512
+
+// hashSynonyms maps device words to a shared concept, so the hashing space still matches
+// abbreviations and translations an embedding model would ("AC" / "Air Conditioner").
+var hashSynonyms = map[string]string{
+	"ac":          "ac",
+	"aircon":      "ac",
+	"conditioner": "ac",
+	"tv":          "tv",
+	"television":  "tv",
+	"televisi":    "tv",
+	"lamp":        "lamp",
+	"lampu":       "lamp",
+	"light":       "lamp",
+	"lights":      "lamp",
+}
+
+// HashEmbedder is a deterministic, dependency-free embedder based on feature hashing.
+// Each word and each character trigram of a word is hashed into a fixed-size vector,
+// so texts sharing words or word fragments ("lamp" / "lampu") end up close to each other.
+// Known synonyms also share a concept feature (see hashSynonyms).
+// It is the fallback whenever no embedding provider is configured or reachable.
+type HashEmbedder struct {
+	dim int
+}
+
+// NewHashEmbedder creates a HashEmbedder with HashEmbedderDimension dimensions.
+func NewHashEmbedder() *HashEmbedder {
+	return &HashEmbedder{dim: HashEmbedderDimension}
+}
+
+// Name returns the identifier of the hashing vector space.
+func (e *HashEmbedder) Name() string {
+	return fmt.Sprintf("hash-%d", e.dim)
+}
+
+// Embed returns one L2-normalized vector per text. It never fails.
+func (e *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i] = e.embed(t)
+	}
+	return out, nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	vec := make([]float32, e.dim)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		e.add(vec, "w:"+w, 1)
+		if concept, ok := hashSynonyms[w]; ok {
+			e.add(vec, "c:"+concept, 2)
+		}
+
+		runes := []rune("#" + w + "#")
+		for j := 0; j+3 <= len(runes); j++ {
+			e.add(vec, "t:"+string(runes[j:j+3]), 0.5)
+		}
+	}
+	normalize(vec)
+	return vec
+}
+
+// add hashes a feature into the vector; one hash bit picks the sign so collisions tend to cancel out.
+func (e *HashEmbedder) add(vec []float32, feature string, weight float32) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum32()
+	idx := int(sum % uint32(e.dim))
+	if sum&(1<<31) != 0 {
+		weight = -weight
+	}
+	vec[idx] += weight
+}
+
+func normalize(vec []float32) {
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return
+	}
+	inv := float32(1 / math.Sqrt(norm))
+	for i := range vec {
+		vec[i] *= inv
+	}
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b (0 when sizes differ or a vector is zero).
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -1,41 +1,148 @@
 package infrastructure
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"sensio/domain/common/utils"
+	"sort"
 	"sync"
+	"time"
 )
 
-// VectorService provides a simple abstraction for vector DB operations.
-// It is intentionally lightweight to allow swapping implementations later
-// (e.g., Milvus, Qdrant, Pinecone, etc.). For now this is an in-memory
-// placeholder implementation used for development and testing.
+// VectorStore is the document index used by the assistant.
+// Documents are looked up by id (Get) or by meaning (Query), optionally narrowed by
+// metadata filters such as terminal_id, category or room.
+type VectorStore interface {
+	// Upsert stores a document and indexes it for similarity search.
+	Upsert(id string, content string, metadata map[string]interface{}) error
+	// Put stores a document that is only retrievable by id (e.g. large JSON snapshots).
+	Put(id string, content string) error
+	Get(id string) (string, bool)
+	Delete(id string) error
+	// Query returns the indexed documents most similar to query, best match first.
+	Query(query string, opts VectorQueryOptions) ([]VectorMatch, error)
+	// Search returns the ids of the documents matching query, best match first.
+	Search(query string) ([]string, error)
+	Count() int
+	FlushAll() error
+}
+
+// VectorQueryOptions narrows a similarity query.
+type VectorQueryOptions struct {
+	TopK     int               // Maximum number of matches (default 10)
+	MinScore float64           // Matches scoring below this cosine similarity are dropped
+	Filter   map[string]string // Every key must equal the document's metadata value
+}
+
+// VectorMatch is one similarity query result.
+type VectorMatch struct {
+	ID       string
+	Content  string
+	Metadata map[string]string
+	Score    float64
+}
+
+const (
+	defaultVectorTopK  = 10
+	maxEmbedChars      = 2048 // Longer documents are truncated before embedding
+	embedBatchSize     = 64
+	embedRequestBudget = 15 * time.Second
+	vectorFileVersion  = 2
+	maxCachedQueries   = 256 // Provider query vectors kept in memory
+)
+
+// defaultHasher serves zero-value VectorService instances
+var defaultHasher = NewHashEmbedder()
+
+type vectorDocument struct {
+	Content  string               `json:"content"`
+	Metadata map[string]string    `json:"metadata,omitempty"`
+	Indexed  bool                 `json:"indexed"`
+	Vectors  map[string][]float32 `json:"vectors,omitempty"` // Provider vectors by embedder name
+
+	hash []float32 // Hashing vector, recomputed on load
+}
+
+type vectorFile struct {
+	Version   int                        `json:"version"`
+	Documents map[string]*vectorDocument `json:"documents"`
+}
+
+// VectorService is the default VectorStore: an in-process cosine-similarity index
+// persisted to a JSON file.
+//
+// Every indexed document carries a vector from the deterministic HashEmbedder. When an
+// embedding provider is configured, documents also get provider vectors and queries are
+// answered in that space; if the provider fails, the query falls back to the hashing space.
 type VectorService struct {
 	mu       sync.RWMutex
-	store    map[string]string // id -> content (json/text)
+	docs     map[string]*vectorDocument
 	filePath string
+	embedder Embedder // Optional provider embedder
+	hasher   *HashEmbedder
+
+	// Provider vectors of recent queries, oldest first in queryOrder; assistants repeat the
+	// same device phrases, so most queries skip the provider round trip
+	queryMu    sync.Mutex
+	queryVecs  map[string][]float32
+	queryOrder []string
 }
 
-// NewVectorService initializes a new VectorService instance with persistence.
+// NewVectorService initializes a VectorService that only uses the local hashing embedder.
 func NewVectorService(filePath string) *VectorService {
+	return NewVectorServiceWithEmbedder(filePath, nil)
+}
+
+// NewVectorServiceWithEmbedder initializes a VectorService backed by the given embedding provider.
+// A nil embedder means hashing only.
+func NewVectorServiceWithEmbedder(filePath string, embedder Embedder) *VectorService {
 	vs := &VectorService{
-		store:    make(map[string]string),
+		docs:     make(map[string]*vectorDocument),
 		filePath: filePath,
+		embedder: embedder,
+		hasher:   NewHashEmbedder(),
 	}
 
-	// Load existing data if file exists
 	if filePath != "" {
 		if data, err := os.ReadFile(filePath); err == nil {
-			_ = json.Unmarshal(data, &vs.store)
+			vs.load(data)
 		}
 	}
 
 	return vs
 }
 
-// save persists the current store to the file.
+// load reads the persisted documents. The legacy format (a plain id -> content map) is
+// still accepted; its documents are indexed on load.
+func (s *VectorService) load(data []byte) {
+	var file vectorFile
+	if err := json.Unmarshal(data, &file); err == nil && file.Version >= vectorFileVersion {
+		for id, doc := range file.Documents {
+			if doc == nil {
+				continue
+			}
+			if doc.Indexed {
+				doc.hash = s.hashVector(doc.Content)
+			}
+			s.docs[id] = doc
+		}
+		return
+	}
+
+	var legacy map[string]string
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		utils.LogWarn("VectorService: ignoring unreadable store %s: %v", s.filePath, err)
+		return
+	}
+	for id, content := range legacy {
+		s.docs[id] = &vectorDocument{Content: content, Indexed: true, hash: s.hashVector(content)}
+	}
+}
+
+// save persists the current store to the file. Callers must hold the write lock.
 func (s *VectorService) save() error {
 	if s.filePath == "" {
 		return nil
@@ -47,19 +154,44 @@ func (s *VectorService) save() error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(s.store, "", "  ")
+	data, err := json.Marshal(vectorFile{Version: vectorFileVersion, Documents: s.docs})
 	if err != nil {
 		return err
 	}
 	return os.WriteFile(s.filePath, data, 0644)
 }
 
-// Upsert stores or updates a document in the vector store.
+// Upsert stores or updates a document and indexes it for similarity search.
 // id should be globally unique (we recommend namespaced IDs like "tuya:device:{id}").
-func (s *VectorService) Upsert(id string, content string, _map map[string]interface{}) error {
+// Metadata values are stored as strings and can be used as query filters.
+func (s *VectorService) Upsert(id string, content string, metadata map[string]interface{}) error {
+	doc := &vectorDocument{
+		Content:  content,
+		Metadata: toStringMetadata(metadata),
+		Indexed:  true,
+		Vectors:  make(map[string][]float32),
+		hash:     s.hashVector(content),
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.store[id] = content
+	s.ensureDocs()
+	// Provider vectors are computed in batches by the next query; keep the current one if the content is unchanged
+	if prev, ok := s.docs[id]; ok && prev.Content == content {
+		for name, vec := range prev.Vectors {
+			doc.Vectors[name] = vec
+		}
+	}
+	s.docs[id] = doc
+	return s.save()
+}
+
+// Put stores a document without indexing it; it is only retrievable by id.
+func (s *VectorService) Put(id string, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ensureDocs()
+	s.docs[id] = &vectorDocument{Content: content}
 	return s.save()
 }
 
@@ -67,122 +199,265 @@ func (s *VectorService) Upsert(id string, content string, _map map[string]interf
 func (s *VectorService) Get(id string) (string, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	val, ok := s.store[id]
-	return val, ok
+	doc, ok := s.docs[id]
+	if !ok {
+		return "", false
+	}
+	return doc.Content, true
 }
 
-// Search performs a simple keyword-based matching over stored contents.
-func (s *VectorService) Search(query string) ([]string, error) {
-	query = strings.ToLower(query)
-	words := strings.Fields(query) // Split query into words
+// Delete removes a document.
+func (s *VectorService) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.docs[id]; !ok {
+		return nil
+	}
+	delete(s.docs, id)
+	return s.save()
+}
 
-	type match struct {
-		id    string
-		score int
+// Query ranks the indexed documents matching opts.Filter by cosine similarity to query.
+func (s *VectorService) Query(query string, opts VectorQueryOptions) ([]VectorMatch, error) {
+	if opts.TopK <= 0 {
+		opts.TopK = defaultVectorTopK
+	}
+
+	if s.embedder != nil {
+		matches, err := s.queryProvider(query, opts)
+		if err == nil {
+			return matches, nil
+		}
+		utils.LogWarn("VectorService: %s query failed, using hashing embedder: %v", s.embedder.Name(), err)
 	}
 
-	matchScores := make(map[string]int) // Track match scores
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	queryVec := s.hashVector(query)
+	return s.rank(opts, func(doc *vectorDocument) []float32 { return doc.hash }, queryVec), nil
+}
 
-	for id, content := range s.store {
-		contentLower := strings.ToLower(content)
-		score := 0
+// queryProvider answers a query in the provider's vector space, embedding any document
+// that does not have a provider vector yet.
+func (s *VectorService) queryProvider(query string, opts VectorQueryOptions) ([]VectorMatch, error) {
+	name := s.embedder.Name()
+	queryVec, err := s.queryVector(name, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.embedMissing(name, opts.Filter); err != nil {
+		return nil, err
+	}
 
-		// Check each word in the query
-		for _, word := range words {
-			// Ignore noise and common smart home terms
-			if len(word) <= 1 || word == "on" || word == "off" || word == "to" || word == "in" || word == "is" ||
-				word == "dan" || word == "set" || word == "ke" || word == "nyalakan" || word == "matikan" ||
-				word == "turn" || word == "the" {
-				continue
-			}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	missing := false
+	matches := s.rank(opts, func(doc *vectorDocument) []float32 {
+		vec, ok := doc.Vectors[name]
+		if !ok {
+			missing = true
+		}
+		return vec
+	}, queryVec)
+	if missing {
+		return nil, fmt.Errorf("documents changed while embedding")
+	}
+	return matches, nil
+}
 
-			// Synonym Expansion
-			targets := []string{word}
-			switch word {
-			case "ac":
-				targets = append(targets, "air conditioner", "conditioner")
-			case "lamp", "light":
-				targets = append(targets, "lamp", "light", "switch")
-			case "tv":
-				targets = append(targets, "television")
-			}
+// queryVector returns the provider vector of a query, embedding it only on a cache miss.
+func (s *VectorService) queryVector(name, query string) ([]float32, error) {
+	key := name + "\x00" + query
+	s.queryMu.Lock()
+	vec, ok := s.queryVecs[key]
+	s.queryMu.Unlock()
+	if ok {
+		return vec, nil
+	}
 
-			// Check if any target matches
-			for _, t := range targets {
-				if strings.Contains(contentLower, t) {
-					// Higher score for exact word match in device name
-					if strings.Contains(contentLower, "device: "+t) || strings.Contains(contentLower, t+" ") {
-						score += 10 // Exact match in name
-					} else {
-						score += 1 // Generic match
-					}
-					break
-				}
-			}
+	vecs, err := s.embed([]string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	s.queryMu.Lock()
+	defer s.queryMu.Unlock()
+	if s.queryVecs == nil {
+		s.queryVecs = make(map[string][]float32)
+	}
+	if _, ok := s.queryVecs[key]; !ok {
+		if len(s.queryOrder) >= maxCachedQueries {
+			delete(s.queryVecs, s.queryOrder[0])
+			s.queryOrder = s.queryOrder[1:]
 		}
+		s.queryOrder = append(s.queryOrder, key)
+	}
+	s.queryVecs[key] = vecs[0]
+	return vecs[0], nil
+}
 
-		// Only include devices with positive scores
-		if score > 0 {
-			matchScores[id] = score
+// rank scores the filtered, indexed documents. Callers must hold the read lock.
+func (s *VectorService) rank(opts VectorQueryOptions, vectorOf func(*vectorDocument) []float32, queryVec []float32) []VectorMatch {
+	var matches []VectorMatch
+	for id, doc := range s.docs {
+		if !doc.Indexed || !matchesFilter(doc.Metadata, opts.Filter) {
+			continue
+		}
+		score := cosineSimilarity(queryVec, vectorOf(doc))
+		if score <= 0 || score < opts.MinScore {
+			continue
 		}
+		matches = append(matches, VectorMatch{ID: id, Content: doc.Content, Metadata: doc.Metadata, Score: score})
 	}
 
-	// Sort matches by score (highest first)
-	matches := make([]match, 0, len(matchScores))
-	for id, score := range matchScores {
-		matches = append(matches, match{id: id, score: score})
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if len(matches) > opts.TopK {
+		matches = matches[:opts.TopK]
 	}
+	return matches
+}
 
-	// Sort by score descending
-	for i := 0; i < len(matches); i++ {
-		for j := i + 1; j < len(matches); j++ {
-			if matches[j].score > matches[i].score {
-				matches[i], matches[j] = matches[j], matches[i]
+// embedMissing computes provider vectors for the filtered documents that lack one.
+func (s *VectorService) embedMissing(name string, filter map[string]string) error {
+	s.mu.RLock()
+	var ids, texts []string
+	for id, doc := range s.docs {
+		if doc.Indexed && matchesFilter(doc.Metadata, filter) {
+			if _, ok := doc.Vectors[name]; !ok {
+				ids = append(ids, id)
+				texts = append(texts, doc.Content)
 			}
 		}
 	}
+	s.mu.RUnlock()
+	if len(ids) == 0 {
+		return nil
+	}
 
-	// Return only IDs, prioritizing higher scores
-	// If top score is high (>= 10), only return those with the MAXIMUM score
-	// This avoids "generic" matches (like 'temperature' matching a sensor)
-	// from cluttering results when a specific match (like 'Sharp AC') is found.
-	var result []string
-	if len(matches) > 0 {
-		topScore := matches[0].score
-		if topScore >= 10 {
-			// Return only matches with the TOP score
-			for _, m := range matches {
-				if m.score == topScore {
-					result = append(result, m.id)
-				}
-			}
-		} else {
-			// Return matches with score >= 1 (at least one word matched via synonym or exact)
-			// Changed from >= 2 to allow single-word synonym matches like "light" -> "lamp"
-			for _, m := range matches {
-				if m.score >= 1 {
-					result = append(result, m.id)
-				}
-			}
+	vecs, err := s.embed(texts)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, id := range ids {
+		doc, ok := s.docs[id]
+		if !ok || doc.Content != texts[i] {
+			continue
+		}
+		if doc.Vectors == nil {
+			doc.Vectors = make(map[string][]float32)
 		}
+		doc.Vectors[name] = vecs[i]
 	}
+	if err := s.save(); err != nil {
+		utils.LogWarn("VectorService: failed to persist embeddings: %v", err)
+	}
+	return nil
+}
 
-	return result, nil
+// embed calls the provider embedder in batches.
+func (s *VectorService) embed(texts []string) ([][]float32, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), embedRequestBudget)
+	defer cancel()
+
+	out := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		batch := make([]string, 0, end-start)
+		for _, t := range texts[start:end] {
+			batch = append(batch, truncateForEmbedding(t))
+		}
+		vecs, err := s.embedder.Embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(vecs) != len(batch) {
+			return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vecs), len(batch))
+		}
+		out = append(out, vecs...)
+	}
+	return out, nil
+}
+
+func (s *VectorService) hashVector(text string) []float32 {
+	if s.hasher == nil {
+		return defaultHasher.embed(text)
+	}
+	return s.hasher.embed(text)
+}
+
+// ensureDocs makes the zero value usable. Callers must hold the write lock.
+func (s *VectorService) ensureDocs() {
+	if s.docs == nil {
+		s.docs = make(map[string]*vectorDocument)
+	}
+}
+
+// Search returns the ids of the indexed documents similar to query, best match first.
+func (s *VectorService) Search(query string) ([]string, error) {
+	matches, err := s.Query(query, VectorQueryOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
 }
 
 // Count returns the number of documents in the store.
 func (s *VectorService) Count() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return len(s.store)
+	return len(s.docs)
 }
 
 // FlushAll clears all stored documents from the vector store and persists the change.
 func (s *VectorService) FlushAll() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.store = make(map[string]string)
+	s.docs = make(map[string]*vectorDocument)
 	return s.save()
 }
+
+func toStringMetadata(metadata map[string]interface{}) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if v == nil {
+			continue
+		}
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
+
+func matchesFilter(metadata, filter map[string]string) bool {
+	for k, v := range filter {
+		if metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func truncateForEmbedding(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxEmbedChars {
+		return text
+	}
+	return string(runes[:maxEmbedChars])
+}
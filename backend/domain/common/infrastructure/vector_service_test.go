@@ -1,6 +1,49 @@
 package infrastructure
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// conceptEmbedder is a fake provider embedder that places synonyms on the same axis,
+// the way a real embedding model would.
+type conceptEmbedder struct {
+	fail  bool
+	calls int
+}
+
+var testConcepts = [][]string{
+	{"ac", "air conditioner", "conditioner"},
+	{"tv", "television"},
+	{"lamp", "light", "lampu"},
+}
+
+func (e *conceptEmbedder) Name() string { return "concept" }
+
+func (e *conceptEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	if e.fail {
+		return nil, errors.New("provider unavailable")
+	}
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		vec := make([]float32, len(testConcepts))
+		words := " " + strings.ToLower(t) + " "
+		for axis, synonyms := range testConcepts {
+			for _, s := range synonyms {
+				if strings.Contains(words, " "+s+" ") {
+					vec[axis] = 1
+				}
+			}
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
 
 func TestVectorService_Search_Synonyms(t *testing.T) {
 	svc := NewVectorService("")
@@ -29,3 +72,132 @@ func TestVectorService_Search_Synonyms(t *testing.T) {
 		}
 	})
 }
+
+func TestVectorService_Search_SynonymsWithProvider(t *testing.T) {
+	svc := NewVectorServiceWithEmbedder("", &conceptEmbedder{})
+	_ = svc.Upsert("ac1", "This is an Air Conditioner in my room", nil)
+	_ = svc.Upsert("tv1", "Smart Television in the living room", nil)
+	_ = svc.Upsert("lamp1", "Desk Lamp", nil)
+
+	t.Run("Match AC", func(t *testing.T) {
+		res, _ := svc.Search("Turn on the AC")
+		if len(res) == 0 || res[0] != "ac1" {
+			t.Errorf("expected ac1, got %v", res)
+		}
+	})
+
+	t.Run("Match TV", func(t *testing.T) {
+		res, _ := svc.Search("Matikan TV")
+		if len(res) == 0 || res[0] != "tv1" {
+			t.Errorf("expected tv1, got %v", res)
+		}
+	})
+
+	t.Run("Match Lamp from Light", func(t *testing.T) {
+		res, _ := svc.Search("Nyalakan Light")
+		if len(res) == 0 || res[0] != "lamp1" {
+			t.Errorf("expected lamp1, got %v", res)
+		}
+	})
+}
+
+func TestVectorService_Query_FiltersByMetadata(t *testing.T) {
+	svc := NewVectorServiceWithEmbedder("", &conceptEmbedder{})
+	_ = svc.Upsert("lamp-bedroom", "Bedroom Lamp", map[string]interface{}{"terminal_id": "t1", "room": "bedroom", "category": "dj"})
+	_ = svc.Upsert("lamp-kitchen", "Kitchen Lamp", map[string]interface{}{"terminal_id": "t2", "room": "kitchen", "category": "dj"})
+
+	matches, err := svc.Query("turn on the light", VectorQueryOptions{Filter: map[string]string{"room": "kitchen"}})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != "lamp-kitchen" {
+		t.Fatalf("expected only lamp-kitchen, got %+v", matches)
+	}
+	if matches[0].Metadata["terminal_id"] != "t2" {
+		t.Errorf("expected metadata to be returned, got %v", matches[0].Metadata)
+	}
+}
+
+func TestVectorService_Query_FallsBackToHashing(t *testing.T) {
+	embedder := &conceptEmbedder{fail: true}
+	svc := NewVectorServiceWithEmbedder("", embedder)
+	_ = svc.Upsert("lamp1", "Device: Lampu Teras | Category: Light", nil)
+	_ = svc.Upsert("fan1", "Device: Kipas Angin | Category: Fan", nil)
+
+	matches, err := svc.Query("nyalakan lampu teras", VectorQueryOptions{TopK: 1})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != "lamp1" {
+		t.Fatalf("expected lamp1 from hashing fallback, got %+v", matches)
+	}
+	if embedder.calls == 0 {
+		t.Error("expected the provider to be tried first")
+	}
+}
+
+func TestVectorService_EmbedsDocumentsOnlyOnce(t *testing.T) {
+	embedder := &conceptEmbedder{}
+	svc := NewVectorServiceWithEmbedder("", embedder)
+	_ = svc.Upsert("lamp1", "Desk Lamp", nil)
+	_ = svc.Upsert("tv1", "Television", nil)
+
+	_, _ = svc.Query("light", VectorQueryOptions{})
+	first := embedder.calls // query + one batch for both documents
+	if first != 2 {
+		t.Fatalf("expected 2 embed calls, got %d", first)
+	}
+
+	_ = svc.Upsert("lamp1", "Desk Lamp", nil) // unchanged content keeps its vector
+	_, _ = svc.Query("light", VectorQueryOptions{})
+	if embedder.calls != first {
+		t.Errorf("expected the repeated query to be served from cache, got %d calls", embedder.calls-first)
+	}
+
+	_, _ = svc.Query("television", VectorQueryOptions{})
+	if embedder.calls != first+1 {
+		t.Errorf("expected only the new query to be embedded, got %d calls", embedder.calls-first)
+	}
+}
+
+func TestVectorService_PutIsNotIndexed(t *testing.T) {
+	svc := NewVectorService("")
+	_ = svc.Put("tuya:devices:uid:u1", `{"devices":[{"name":"Desk Lamp"}]}`)
+	_ = svc.Upsert("lamp1", "Desk Lamp", nil)
+
+	ids, _ := svc.Search("desk lamp")
+	if len(ids) != 1 || ids[0] != "lamp1" {
+		t.Errorf("expected only the indexed document, got %v", ids)
+	}
+	if content, ok := svc.Get("tuya:devices:uid:u1"); !ok || !strings.Contains(content, "Desk Lamp") {
+		t.Error("expected Put document to be retrievable by id")
+	}
+}
+
+func TestVectorService_PersistsAndLoadsLegacyFormat(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "store.json")
+	svc := NewVectorService(path)
+	_ = svc.Upsert("lamp1", "Desk Lamp", map[string]interface{}{"room": "office"})
+	_ = svc.Put("agg", "{}")
+
+	reloaded := NewVectorService(path)
+	if reloaded.Count() != 2 {
+		t.Fatalf("expected 2 documents after reload, got %d", reloaded.Count())
+	}
+	matches, _ := reloaded.Query("lamp", VectorQueryOptions{Filter: map[string]string{"room": "office"}})
+	if len(matches) != 1 || matches[0].ID != "lamp1" {
+		t.Errorf("expected lamp1 after reload, got %+v", matches)
+	}
+
+	legacyPath := filepath.Join(dir, "legacy.json")
+	if err := os.WriteFile(legacyPath, []byte(`{"tuya:device:1":"Device: Desk Lamp | ID: 1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	legacy := NewVectorService(legacyPath)
+	ids, _ := legacy.Search("desk lamp")
+	if len(ids) != 1 || ids[0] != "tuya:device:1" {
+		t.Errorf("expected legacy document to be indexed, got %v", ids)
+	}
+}
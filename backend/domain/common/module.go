@@ -5,6 +5,7 @@ import (
 	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/routes"
 	"sensio/domain/common/services"
+	"sensio/domain/common/tasks"
 	terminal_repositories "sensio/domain/terminal/terminal/repositories"
 
 	"github.com/gin-gonic/gin"
@@ -18,10 +19,12 @@ type CommonModule struct {
 	MqttService                    *infrastructure.MqttService
 	DeviceInfoExternalController   *controllers.DeviceInfoExternalController
 	NotificationExternalController *controllers.NotificationExternalController
+	MqttOutboxController           *controllers.MqttOutboxController
+	JobController                  *controllers.JobController
 }
 
 // NewCommonModule initializes the common domain components
-func NewCommonModule(badger *infrastructure.BadgerService, vector *infrastructure.VectorService, mqttSvc *infrastructure.MqttService, terminalRepo terminal_repositories.ITerminalRepository) *CommonModule {
+func NewCommonModule(badger *infrastructure.BadgerService, vector infrastructure.VectorStore, mqttSvc *infrastructure.MqttService, terminalRepo terminal_repositories.ITerminalRepository, jobs *tasks.JobQueue) *CommonModule {
 	bigSvc := services.NewDeviceInfoExternalService()
 
 	// Initialize notification service
@@ -34,6 +37,8 @@ func NewCommonModule(badger *infrastructure.BadgerService, vector *infrastructur
 		MqttService:                    mqttSvc,
 		DeviceInfoExternalController:   controllers.NewDeviceInfoExternalController(bigSvc),
 		NotificationExternalController: controllers.NewNotificationExternalController(notificationSvc),
+		MqttOutboxController:           controllers.NewMqttOutboxController(mqttSvc),
+		JobController:                  controllers.NewJobController(jobs),
 	}
 }
 
@@ -58,4 +63,6 @@ func (m *CommonModule) RegisterRoutes(router *gin.Engine, protected *gin.RouterG
 	routes.SetupCacheRoutes(protected, m.CacheController)
 	routes.SetupDeviceInfoExternalRoutes(protected, m.DeviceInfoExternalController)
 	routes.SetupNotificationExternalRoutes(protected, m.NotificationExternalController)
+	routes.SetupMqttOutboxRoutes(protected, m.MqttOutboxController)
+	routes.SetupJobRoutes(protected, m.JobController)
 }
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sensio/domain/common/utils"
+	"time"
+)
+
+type pinnedProviderKey struct{}
+
+// WithProvider returns a context whose LLM calls use only the given provider, ignoring the terminal
+// preference and the health-aware fallback chain. Used when a caller overrides the provider of a run.
+func WithProvider(ctx context.Context, provider string) context.Context {
+	return context.WithValue(ctx, pinnedProviderKey{}, NormalizeProvider(provider))
+}
+
+// ProviderFromContext returns the provider pinned with WithProvider, or an empty string
+func ProviderFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	provider, _ := ctx.Value(pinnedProviderKey{}).(string)
+	return provider
+}
+
+// ForContext returns the resolver for calls made with ctx: r itself, or r restricted to the
+// provider pinned with WithProvider
+func ForContext(ctx context.Context, r ProviderResolver) ProviderResolver {
+	provider := ProviderFromContext(ctx)
+	if provider == "" {
+		return r
+	}
+	return &pinnedProviderResolver{ProviderResolver: r, provider: provider}
+}
+
+// pinnedProviderResolver runs every execution on one provider, like an explicit terminal choice
+type pinnedProviderResolver struct {
+	ProviderResolver
+	provider string
+}
+
+func (r *pinnedProviderResolver) ExecuteWithFallback(executable func(resolvedSet *ResolvedProviderSet) error, skipProviders ...string) error {
+	return r.execute(executable)
+}
+
+func (r *pinnedProviderResolver) ExecuteWithFallbackByTerminal(terminalID string, executable func(resolvedSet *ResolvedProviderSet) error) error {
+	return r.execute(executable)
+}
+
+func (r *pinnedProviderResolver) ExecuteWithFallbackByMac(macAddress string, executable func(resolvedSet *ResolvedProviderSet) error) error {
+	return r.execute(executable)
+}
+
+func (r *pinnedProviderResolver) execute(executable func(resolvedSet *ResolvedProviderSet) error) error {
+	resolved := r.ResolveProvider(r.provider)
+	if resolved == nil || resolved.LLM == nil {
+		return fmt.Errorf("provider %s is not available", r.provider)
+	}
+	resolved.IsExplicit = true
+
+	health := r.GetHealthAwareResolver()
+	attemptStart := time.Now()
+	err := executable(resolved)
+	if health != nil {
+		if err == nil {
+			health.RecordSuccess(r.provider, time.Since(attemptStart).Milliseconds())
+		} else {
+			health.RecordFailure(r.provider)
+		}
+	}
+	if err != nil {
+		utils.LogError("ProviderResolver: Pinned provider %s failed: %v (no fallback)", r.provider, err)
+	}
+	return err
+}
@@ -19,6 +19,11 @@ var SupportedProviders = map[string]bool{
 	"orion":  true,
 }
 
+// WhisperCppProvider transcribes locally with whisper.cpp. It is transcription-only: a terminal
+// that selects it still runs refine, translation and summary on the default LLM provider.
+// Available when WHISPER_LOCAL_MODEL is set.
+const WhisperCppProvider = "whispercpp"
+
 // IsValidProvider checks if a provider name is supported as a user-facing provider
 func IsValidProvider(provider string) bool {
 	if provider == "" {
@@ -27,6 +32,11 @@ func IsValidProvider(provider string) bool {
 	return SupportedProviders[strings.ToLower(provider)]
 }
 
+// IsValidTerminalProvider checks if a terminal can prefer the provider: any user-facing provider or whisper.cpp
+func IsValidTerminalProvider(provider string) bool {
+	return IsValidProvider(provider) || NormalizeProvider(provider) == WhisperCppProvider
+}
+
 // NormalizeProvider normalizes a provider name to lowercase
 func NormalizeProvider(provider string) string {
 	return strings.ToLower(strings.TrimSpace(provider))
@@ -81,6 +91,7 @@ type providerResolverImpl struct {
 	openaiService *services.OpenAIService
 	groqService   *services.GroqService
 	orionService  *services.OrionService
+	whisperCpp    WhisperProvider // nil unless WHISPER_LOCAL_MODEL is set
 
 	// Terminal repository for looking up terminal preferences
 	terminalRepo TerminalRepository
@@ -107,6 +118,7 @@ func NewProviderResolver(
 	openaiService *services.OpenAIService,
 	groqService *services.GroqService,
 	orionService *services.OrionService,
+	whisperCpp WhisperProvider,
 	terminalRepo TerminalRepository,
 ) ProviderResolver {
 	healthAwareResolver := NewHealthAwareResolver(cfg)
@@ -117,6 +129,7 @@ func NewProviderResolver(
 		openaiService:       openaiService,
 		groqService:         groqService,
 		orionService:        orionService,
+		whisperCpp:          whisperCpp,
 		terminalRepo:        terminalRepo,
 		healthAwareResolver: healthAwareResolver,
 	}
@@ -183,7 +196,7 @@ func (r *providerResolverImpl) resolveFromTerminal(terminal *Terminal) (*Resolve
 	if terminal.AiProvider != nil && *terminal.AiProvider != "" {
 		provider := NormalizeProvider(*terminal.AiProvider)
 
-		if IsValidProvider(provider) {
+		if IsValidTerminalProvider(provider) {
 			utils.LogDebug("ProviderResolver: Using terminal provider '%s' | duration_ms=%d", provider, time.Since(start).Milliseconds())
 			result := r.ResolveProvider(provider)
 			result.IsExplicit = true
@@ -277,6 +290,14 @@ func (r *providerResolverImpl) ResolveProvider(provider string) *ResolvedProvide
 		utils.LogDebug("ProviderResolver: Using Orion provider | duration_ms=%d", time.Since(start).Milliseconds())
 		llm = r.orionService
 		whisper = r.orionService
+	case WhisperCppProvider:
+		if r.whisperCpp == nil {
+			utils.LogError("ProviderResolver: whispercpp selected but WHISPER_LOCAL_MODEL is not set | duration_ms=%d", time.Since(start).Milliseconds())
+			return &ResolvedProviderSet{}
+		}
+		utils.LogDebug("ProviderResolver: Using whisper.cpp for transcription and the default LLM | duration_ms=%d", time.Since(start).Milliseconds())
+		llm = r.ResolveDefault().LLM
+		whisper = r.whisperCpp
 	default:
 		// Invalid provider - return nil
 		utils.LogError("ProviderResolver: Invalid provider '%s' | duration_ms=%d", provider, time.Since(start).Milliseconds())
@@ -479,6 +500,10 @@ func ValidateProviderConfig(provider string, cfg *utils.Config) error {
 		if cfg.OrionApiKey == "" {
 			return fmt.Errorf("orion provider requires ORION_API_KEY")
 		}
+	case WhisperCppProvider:
+		if cfg.WhisperLocalModel == "" {
+			return fmt.Errorf("whispercpp provider requires WHISPER_LOCAL_MODEL")
+		}
 	default:
 		return fmt.Errorf("unsupported provider: %s", provider)
 	}
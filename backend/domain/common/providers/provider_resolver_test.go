@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sensio/domain/common/utils"
+	whisperdtos "sensio/domain/models/whisper/dtos"
+	"testing"
+)
+
+type fakeWhisper struct{}
+
+func (fakeWhisper) Transcribe(ctx context.Context, audioPath string, language string, diarize bool) (*whisperdtos.WhisperResult, error) {
+	return &whisperdtos.WhisperResult{Transcription: "local", AlignedTimestamps: true}, nil
+}
+
+type fakeTerminalRepo map[string]*Terminal
+
+func (r fakeTerminalRepo) GetByID(id string) (*Terminal, error) {
+	if t, ok := r[id]; ok {
+		return t, nil
+	}
+	return nil, errors.New("terminal not found")
+}
+
+func (r fakeTerminalRepo) GetByMacAddress(macAddress string) (*Terminal, error) {
+	return r.GetByID(macAddress)
+}
+
+func newTestResolver(whisperCpp WhisperProvider) ProviderResolver {
+	cfg := &utils.Config{LLMProvider: "openai", OpenAIApiKey: "test-key"}
+	gemini, openai, groq, orion := GetProviderServices(cfg)
+	whispercpp := WhisperCppProvider
+	terminals := fakeTerminalRepo{"aa:bb": {AiProvider: &whispercpp}}
+	return NewProviderResolver(cfg, gemini, openai, groq, orion, whisperCpp, terminals)
+}
+
+func TestProviderResolver_WhisperCpp(t *testing.T) {
+	if set := newTestResolver(nil).ResolveProvider(WhisperCppProvider); set.ProviderName != "" || set.WhisperClient != nil {
+		t.Fatalf("expected whispercpp to be unavailable without WHISPER_LOCAL_MODEL, got %+v", set)
+	}
+
+	resolver := newTestResolver(fakeWhisper{})
+	set := resolver.ResolveProvider(WhisperCppProvider)
+	if set.ProviderName != WhisperCppProvider {
+		t.Fatalf("expected provider whispercpp, got %q", set.ProviderName)
+	}
+	if _, ok := set.WhisperClient.(fakeWhisper); !ok {
+		t.Errorf("expected the whisper.cpp client, got %T", set.WhisperClient)
+	}
+	if set.LLM == nil || set.LLM != resolver.ResolveDefault().LLM {
+		t.Error("expected LLM stages to use the default provider")
+	}
+
+	// A terminal preferring whispercpp transcribes locally, without falling back
+	var used string
+	err := resolver.ExecuteWithFallbackByMac("aa:bb", func(resolved *ResolvedProviderSet) error {
+		used = resolved.ProviderName
+		return nil
+	})
+	if err != nil || used != WhisperCppProvider {
+		t.Errorf("expected whispercpp for the terminal, got %q (err=%v)", used, err)
+	}
+}
+
+func TestIsValidTerminalProvider(t *testing.T) {
+	if !IsValidTerminalProvider("WhisperCpp") || !IsValidTerminalProvider("openai") {
+		t.Error("expected whispercpp and remote providers to be valid terminal providers")
+	}
+	if IsValidProvider(WhisperCppProvider) {
+		t.Error("whispercpp is transcription-only and must not be a default LLM provider")
+	}
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"sensio/domain/common/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupJobRoutes registers endpoints for inspecting and cancelling background jobs.
+//
+// param rg The router group to attach the job routes to.
+// param controller The controller handling job operations.
+func SetupJobRoutes(rg *gin.RouterGroup, controller *controllers.JobController) {
+	jobGroup := rg.Group("/api/jobs")
+	{
+		// GET /api/jobs
+		// Lists jobs (optionally filtered by type and status)
+		jobGroup.GET("", controller.ListJobs)
+
+		// GET /api/jobs/:id
+		// Returns a single job with its payload
+		jobGroup.GET("/:id", controller.GetJob)
+
+		// POST /api/jobs/:id/cancel
+		// Cancels a queued or running job
+		jobGroup.POST("/:id/cancel", controller.CancelJob)
+	}
+}
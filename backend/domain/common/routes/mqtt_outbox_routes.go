@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"sensio/domain/common/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupMqttOutboxRoutes registers endpoints for inspecting the MQTT outbox.
+//
+// param rg The router group to attach the outbox routes to.
+// param controller The controller handling outbox operations.
+func SetupMqttOutboxRoutes(rg *gin.RouterGroup, controller *controllers.MqttOutboxController) {
+	outboxGroup := rg.Group("/api/mqtt/outbox")
+	{
+		// GET /api/mqtt/outbox
+		// Lists publishes queued while the broker was unreachable
+		outboxGroup.GET("", controller.ListMessages)
+
+		// DELETE /api/mqtt/outbox
+		// Purges all queued publishes (optionally filtered by topic prefix)
+		outboxGroup.DELETE("", controller.PurgeMessages)
+
+		// DELETE /api/mqtt/outbox/:id
+		// Removes a single queued publish
+		outboxGroup.DELETE("/:id", controller.DeleteMessage)
+	}
+}
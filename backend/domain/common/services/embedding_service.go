@@ -0,0 +1,296 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"strings"
+)
+
+const (
+	defaultGeminiEmbeddingModel = "text-embedding-004"
+	defaultOpenAIEmbeddingModel = "text-embedding-3-small"
+	llamaEmbeddingSeparator     = "<#sensio#>"
+)
+
+// NewEmbedderFromConfig returns the embedding provider for the vector store.
+//
+// VECTOR_EMBEDDER selects it explicitly ("gemini", "openai", "local" or "hash").
+// When empty, the LLM_PROVIDER is followed if it offers embeddings, then any provider
+// with credentials. nil means the vector store only uses its local hashing embedder.
+func NewEmbedderFromConfig(cfg *utils.Config) infrastructure.Embedder {
+	choice := strings.ToLower(strings.TrimSpace(cfg.VectorEmbedder))
+	switch choice {
+	case "hash":
+		return nil
+	case "gemini":
+		return NewGeminiEmbedder(cfg)
+	case "openai":
+		return NewOpenAIEmbedder(cfg)
+	case "local":
+		return NewLlamaLocalEmbedder(cfg)
+	case "":
+	default:
+		utils.LogWarn("Embedder: unknown VECTOR_EMBEDDER '%s', selecting automatically", cfg.VectorEmbedder)
+	}
+
+	switch strings.ToLower(cfg.LLMProvider) {
+	case "gemini":
+		if cfg.GeminiApiKey != "" {
+			return NewGeminiEmbedder(cfg)
+		}
+	case "openai":
+		if cfg.OpenAIApiKey != "" {
+			return NewOpenAIEmbedder(cfg)
+		}
+	}
+	switch {
+	case cfg.GeminiApiKey != "":
+		return NewGeminiEmbedder(cfg)
+	case cfg.OpenAIApiKey != "":
+		return NewOpenAIEmbedder(cfg)
+	case cfg.LlamaLocalEmbeddingModel != "":
+		return NewLlamaLocalEmbedder(cfg)
+	}
+	return nil
+}
+
+// GeminiEmbedder embeds texts with the Gemini batchEmbedContents API.
+type GeminiEmbedder struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func NewGeminiEmbedder(cfg *utils.Config) *GeminiEmbedder {
+	model := cfg.GeminiModelEmbedding
+	if model == "" {
+		model = defaultGeminiEmbeddingModel
+	}
+	return &GeminiEmbedder{apiKey: cfg.GeminiApiKey, model: model, client: &http.Client{}}
+}
+
+func (e *GeminiEmbedder) Name() string {
+	return "gemini:" + e.model
+}
+
+type geminiEmbedRequest struct {
+	Requests []geminiEmbedContentRequest `json:"requests"`
+}
+
+type geminiEmbedContentRequest struct {
+	Model   string        `json:"model"`
+	Content geminiContent `json:"content"`
+}
+
+type geminiEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+}
+
+func (e *GeminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY is not configured")
+	}
+
+	reqBody := geminiEmbedRequest{Requests: make([]geminiEmbedContentRequest, len(texts))}
+	for i, t := range texts {
+		reqBody.Requests[i] = geminiEmbedContentRequest{
+			Model:   "models/" + e.model,
+			Content: geminiContent{Parts: []geminiPart{{Text: t}}},
+		}
+	}
+
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:batchEmbedContents?key=%s", e.model, e.apiKey)
+	body, err := postJSON(ctx, e.client, url, reqBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gemini embeddings: %w", err)
+	}
+
+	var resp geminiEmbedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal gemini embeddings: %w", err)
+	}
+	out := make([][]float32, len(resp.Embeddings))
+	for i, emb := range resp.Embeddings {
+		out[i] = emb.Values
+	}
+	return out, nil
+}
+
+// OpenAIEmbedder embeds texts with the OpenAI embeddings API.
+type OpenAIEmbedder struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func NewOpenAIEmbedder(cfg *utils.Config) *OpenAIEmbedder {
+	model := cfg.OpenAIModelEmbedding
+	if model == "" {
+		model = defaultOpenAIEmbeddingModel
+	}
+	return &OpenAIEmbedder{apiKey: cfg.OpenAIApiKey, model: model, client: &http.Client{}}
+}
+
+func (e *OpenAIEmbedder) Name() string {
+	return "openai:" + e.model
+}
+
+type openaiEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openaiEmbedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY is not configured")
+	}
+
+	headers := map[string]string{"Authorization": "Bearer " + e.apiKey}
+	body, err := postJSON(ctx, e.client, "https://api.openai.com/v1/embeddings", openaiEmbedRequest{Model: e.model, Input: texts}, headers)
+	if err != nil {
+		return nil, fmt.Errorf("openai embeddings: %w", err)
+	}
+
+	var resp openaiEmbedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal openai embeddings: %w", err)
+	}
+	out := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(out) {
+			return nil, fmt.Errorf("openai embeddings returned unexpected index %d", d.Index)
+		}
+		out[d.Index] = d.Embedding
+	}
+	return out, nil
+}
+
+// LlamaLocalEmbedder embeds texts with the llama.cpp llama-embedding binary.
+type LlamaLocalEmbedder struct {
+	modelPath string
+}
+
+func NewLlamaLocalEmbedder(cfg *utils.Config) *LlamaLocalEmbedder {
+	modelPath := cfg.LlamaLocalEmbeddingModel
+	if modelPath == "" {
+		modelPath = cfg.LlamaLocalModel
+	}
+	return &LlamaLocalEmbedder{modelPath: modelPath}
+}
+
+func (e *LlamaLocalEmbedder) Name() string {
+	return "local:" + e.modelPath
+}
+
+type llamaEmbedOutput struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *LlamaLocalEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.modelPath == "" {
+		return nil, fmt.Errorf("LLAMA_LOCAL_EMBEDDING_MODEL is not configured")
+	}
+
+	// Find llama-embedding: try local bin first, then PATH
+	bin := "./bin/llama-embedding"
+	if _, err := os.Stat(bin); os.IsNotExist(err) {
+		binInPath, err := exec.LookPath("llama-embedding")
+		if err != nil {
+			return nil, fmt.Errorf("llama-embedding not found in ./bin or PATH: %w", err)
+		}
+		bin = binInPath
+	}
+
+	// One prompt per text; newlines inside a text would otherwise split it
+	prompts := make([]string, len(texts))
+	for i, t := range texts {
+		prompts[i] = strings.ReplaceAll(t, "\n", " ")
+	}
+
+	args := []string{
+		"-m", e.modelPath,
+		"-p", strings.Join(prompts, llamaEmbeddingSeparator),
+		"--embd-separator", llamaEmbeddingSeparator,
+		"--embd-output-format", "json",
+		"--embd-normalize", "2",
+		"--log-disable",
+	}
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Env = append(os.Environ(), "TERM=dumb")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		utils.LogDebug("LlamaLocalEmbedder: raw failure output: %s", stderr.String())
+		return nil, fmt.Errorf("llama-embedding failed: %w", err)
+	}
+
+	raw := stdout.Bytes()
+	if idx := bytes.IndexByte(raw, '{'); idx > 0 {
+		raw = raw[idx:]
+	}
+	var out llamaEmbedOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse llama-embedding output: %w", err)
+	}
+	vecs := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vecs) {
+			return nil, fmt.Errorf("llama-embedding returned unexpected index %d", d.Index)
+		}
+		vecs[d.Index] = d.Embedding
+	}
+	return vecs, nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}, headers map[string]string) ([]byte, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, utils.NewAPIError(resp.StatusCode, fmt.Sprintf("status %d: %s", resp.StatusCode, string(body)))
+	}
+	return body, nil
+}
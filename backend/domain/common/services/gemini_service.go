@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"sensio/domain/common/utils"
 	"sensio/domain/models/whisper/dtos"
+	"strings"
 )
 
 type GeminiService struct {
@@ -76,15 +77,7 @@ func (s *GeminiService) CallModel(ctx context.Context, prompt string, model stri
 		return "", fmt.Errorf("GEMINI_API_KEY is not configured")
 	}
 
-	actualModel := model
-	switch {
-	case model == "high":
-		actualModel = s.config.GeminiModelHigh
-	case model == "low":
-		actualModel = s.config.GeminiModelLow
-	case model == "default" || model == "":
-		actualModel = s.config.GeminiModelLow
-	}
+	actualModel := s.resolveModel(model)
 
 	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", actualModel, s.apiKey)
 	utils.LogDebug("Gemini: Calling URL: https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent", actualModel)
@@ -140,6 +133,63 @@ func (s *GeminiService) CallModel(ctx context.Context, prompt string, model stri
 	return responseText, nil
 }
 
+// StreamModel streams the generation, calling onChunk with the text of each streamed candidate
+func (s *GeminiService) StreamModel(ctx context.Context, prompt string, model string, onChunk func(chunk string)) (string, error) {
+	if s.apiKey == "" {
+		return "", fmt.Errorf("GEMINI_API_KEY is not configured")
+	}
+
+	actualModel := s.resolveModel(model)
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:streamGenerateContent?alt=sse&key=%s", actualModel, s.apiKey)
+	utils.LogDebug("Gemini: Streaming URL: https://generativelanguage.googleapis.com/v1beta/models/%s:streamGenerateContent", actualModel)
+
+	resp, err := postLLMStream(ctx, "gemini", url, nil, geminiRequest{
+		Contents: []geminiContent{{Parts: []geminiPart{{Text: prompt}}}},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var full strings.Builder
+	var parseErr error
+	err = readSSEEvents(resp.Body, func(_, data string) bool {
+		var chunk geminiResponse
+		if parseErr = json.Unmarshal([]byte(data), &chunk); parseErr != nil {
+			return false
+		}
+		for _, candidate := range chunk.Candidates {
+			for _, part := range candidate.Content.Parts {
+				if part.Text != "" {
+					full.WriteString(part.Text)
+					onChunk(part.Text)
+				}
+			}
+		}
+		return true
+	})
+	if err == nil {
+		err = parseErr
+	}
+	if err != nil {
+		return full.String(), fmt.Errorf("failed to read gemini stream: %w", err)
+	}
+	if full.Len() == 0 {
+		return "", fmt.Errorf("gemini api returned no candidates")
+	}
+	return full.String(), nil
+}
+
+func (s *GeminiService) resolveModel(model string) string {
+	switch {
+	case model == "high":
+		return s.config.GeminiModelHigh
+	case model == "low", model == "default", model == "":
+		return s.config.GeminiModelLow
+	}
+	return model
+}
+
 // Whisper Implementation
 
 // GeminiDirectUploadLimitBytes is the maximum file size for direct Gemini Whisper uploads.
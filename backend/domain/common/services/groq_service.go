@@ -56,19 +56,7 @@ func (s *GroqService) CallModel(ctx context.Context, prompt string, model string
 		return "", fmt.Errorf("GROQ_API_KEY is not configured")
 	}
 
-	actualModel := model
-	switch {
-	case model == "high":
-		actualModel = s.config.GroqModelHigh
-	case model == "low":
-		actualModel = s.config.GroqModelLow
-	case model == "default" || model == "":
-		actualModel = s.config.GroqModelLow
-	}
-
-	if actualModel == "" {
-		actualModel = "llama3-8b-8192" // Safe default for Groq
-	}
+	actualModel := s.resolveModel(model)
 
 	url := "https://api.groq.com/openai/v1/chat/completions"
 	reqBody := map[string]interface{}{
@@ -127,6 +115,43 @@ func (s *GroqService) CallModel(ctx context.Context, prompt string, model string
 	return result, nil
 }
 
+// StreamModel streams the completion, calling onChunk with each content delta
+func (s *GroqService) StreamModel(ctx context.Context, prompt string, model string, onChunk func(chunk string)) (string, error) {
+	if s.config.GroqApiKey == "" {
+		return "", fmt.Errorf("GROQ_API_KEY is not configured")
+	}
+
+	result, err := streamChatCompletions(ctx, "groq", "https://api.groq.com/openai/v1/chat/completions", s.config.GroqApiKey, map[string]interface{}{
+		"model": s.resolveModel(model),
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"stream": true,
+	}, onChunk)
+	if err != nil {
+		return "", err
+	}
+	utils.LogDebug("Groq: Stream completed (%d chars)", len(result))
+	return result, nil
+}
+
+func (s *GroqService) resolveModel(model string) string {
+	actualModel := model
+	switch {
+	case model == "high":
+		actualModel = s.config.GroqModelHigh
+	case model == "low":
+		actualModel = s.config.GroqModelLow
+	case model == "default" || model == "":
+		actualModel = s.config.GroqModelLow
+	}
+
+	if actualModel == "" {
+		actualModel = "llama3-8b-8192" // Safe default for Groq
+	}
+	return actualModel
+}
+
 // Whisper Implementation
 
 // GroqDirectUploadLimitBytes is the maximum file size for direct Groq Whisper uploads.
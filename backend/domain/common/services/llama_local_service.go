@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"sensio/domain/common/utils"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type LlamaLocalService struct {
@@ -37,9 +39,19 @@ func (s *LlamaLocalService) HealthCheck() bool {
 	return true
 }
 
-func (s *LlamaLocalService) CallModel(ctx context.Context, prompt string, model string) (string, error) {
+// llamaTrailerMarkers start the metrics and log lines llama-cli prints after the generated text
+var llamaTrailerMarkers = []string{"llama_", "[ Prompt:", "Exiting..."}
+
+// llamaCommand is a prepared llama-cli invocation bound to its timeout context
+type llamaCommand struct {
+	*exec.Cmd
+	ctx context.Context
+}
+
+// command resolves llama-cli and prepares a non-interactive run for prompt
+func (s *LlamaLocalService) command(ctx context.Context, prompt string) (*llamaCommand, context.CancelFunc, error) {
 	if s.modelPath == "" {
-		return "", fmt.Errorf("LLAMA_LOCAL_MODEL is not configured")
+		return nil, nil, fmt.Errorf("LLAMA_LOCAL_MODEL is not configured")
 	}
 
 	// Find llama-cli: try local bin first, then PATH
@@ -47,7 +59,7 @@ func (s *LlamaLocalService) CallModel(ctx context.Context, prompt string, model
 	if _, err := os.Stat(bin); os.IsNotExist(err) {
 		binInPath, err := exec.LookPath("llama-cli")
 		if err != nil {
-			return "", fmt.Errorf("llama-cli not found in ./bin or PATH: %w", err)
+			return nil, nil, fmt.Errorf("llama-cli not found in ./bin or PATH: %w", err)
 		}
 		bin = binInPath
 	}
@@ -69,14 +81,23 @@ func (s *LlamaLocalService) CallModel(ctx context.Context, prompt string, model
 		ctx = context.Background()
 	}
 	ctx, cancel := context.WithTimeout(ctx, 120*time.Second) // Increased timeout for loading
-	defer cancel()
-
-	utils.LogDebug("LlamaLocal: Running %s (no-cnv)", bin)
 
 	cmd := exec.CommandContext(ctx, bin, args...)
 
 	// Force non-interactive environment
 	cmd.Env = append(os.Environ(), "TERM=dumb")
+	return &llamaCommand{Cmd: cmd, ctx: ctx}, cancel, nil
+}
+
+func (s *LlamaLocalService) CallModel(ctx context.Context, prompt string, model string) (string, error) {
+	cmd, cancel, err := s.command(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	ctx = cmd.ctx
+
+	utils.LogDebug("LlamaLocal: Running %s (no-cnv)", cmd.Path)
 
 	// Capture BOTH stdout and stderr to ensure absolutely nothing leaks to the terminal
 	// and we can clean the whole stream.
@@ -112,18 +133,108 @@ func (s *LlamaLocalService) CallModel(ctx context.Context, prompt string, model
 	}
 
 	// 3. Cut off at metrics/logs that start with "llama_" or bracketed timings
-	if idx := strings.Index(result, "llama_"); idx != -1 {
-		result = result[:idx]
+	result = cutLlamaTrailer(result)
+
+	result = strings.TrimSpace(result)
+
+	utils.LogDebug("LlamaLocal: Processed result (length: %d)", len(result))
+	return result, nil
+}
+
+// StreamModel streams llama-cli stdout as it is generated. The prompt echo and the trailing
+// metrics are never emitted; a short tail is held back so a marker split across reads is caught.
+func (s *LlamaLocalService) StreamModel(ctx context.Context, prompt string, model string, onChunk func(chunk string)) (string, error) {
+	cmd, cancel, err := s.command(ctx, prompt)
+	if err != nil {
+		return "", err
 	}
-	if idx := strings.Index(result, "[ Prompt:"); idx != -1 {
-		result = result[:idx]
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("llama-cli failed: %w", err)
 	}
-	if idx := strings.Index(result, "Exiting..."); idx != -1 {
-		result = result[:idx]
+	utils.LogDebug("LlamaLocal: Streaming %s (no-cnv)", cmd.Path)
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("llama-cli failed: %w", err)
 	}
 
-	result = strings.TrimSpace(result)
+	var raw strings.Builder
+	emitted := 0
+	buf := make([]byte, 256)
+	for {
+		n, readErr := stdout.Read(buf)
+		if n > 0 {
+			raw.Write(buf[:n])
+			if visible := streamableLlamaText(raw.String(), prompt, false); len(visible) > emitted {
+				onChunk(visible[emitted:])
+				emitted = len(visible)
+			}
+		}
+		if readErr != nil {
+			break
+		}
+	}
 
-	utils.LogDebug("LlamaLocal: Processed result (length: %d)", len(result))
+	if err := cmd.Wait(); err != nil {
+		if cmd.ctx.Err() == context.DeadlineExceeded {
+			utils.LogError("LlamaLocal: Execution timed out")
+			return "", fmt.Errorf("llama-cli timed out after 120s")
+		}
+		utils.LogDebug("LlamaLocal: raw failure output: %s%s", raw.String(), stderr.String())
+		return "", fmt.Errorf("llama-cli failed: %w", err)
+	}
+
+	visible := streamableLlamaText(raw.String(), prompt, true)
+	if len(visible) > emitted {
+		onChunk(visible[emitted:])
+	}
+	result := strings.TrimSpace(visible)
+	utils.LogDebug("LlamaLocal: Streamed result (length: %d)", len(result))
 	return result, nil
 }
+
+// streamableLlamaText returns the part of the stdout read so far that is safe to show.
+// Until done it withholds a possible prompt echo and a tail that may be the start of a marker.
+func streamableLlamaText(raw, prompt string, done bool) string {
+	text := strings.TrimLeft(raw, " \t\r\n")
+	trimmedPrompt := strings.TrimSpace(prompt)
+	if strings.HasPrefix(text, trimmedPrompt) {
+		text = strings.TrimLeft(strings.TrimPrefix(text, trimmedPrompt), " \t\r\n")
+	} else if !done && strings.HasPrefix(trimmedPrompt, text) {
+		// Still could be the prompt echo
+		return ""
+	}
+
+	cut := cutLlamaTrailer(text)
+	if done || len(cut) < len(text) {
+		return cut
+	}
+
+	holdBack := 0
+	for _, marker := range llamaTrailerMarkers {
+		if len(marker) > holdBack {
+			holdBack = len(marker)
+		}
+	}
+	end := len(text) - holdBack
+	if end <= 0 {
+		return ""
+	}
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
+
+// cutLlamaTrailer drops everything from the first metrics/log marker onwards
+func cutLlamaTrailer(text string) string {
+	for _, marker := range llamaTrailerMarkers {
+		if idx := strings.Index(text, marker); idx != -1 {
+			text = text[:idx]
+		}
+	}
+	return text
+}
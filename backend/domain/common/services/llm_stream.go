@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sensio/domain/common/utils"
+	"strings"
+)
+
+// maxSSELineBytes bounds a single Server-Sent Events line from an LLM provider
+const maxSSELineBytes = 1024 * 1024
+
+// readSSEEvents calls onEvent for every event of a Server-Sent Events stream until the stream
+// ends or onEvent returns false. Multi-line data fields are joined with newlines.
+func readSSEEvents(r io.Reader, onEvent func(event, data string) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineBytes)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 && !onEvent(event, strings.Join(data, "\n")) {
+				return nil
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		onEvent(event, strings.Join(data, "\n"))
+	}
+	return nil
+}
+
+// postLLMStream sends a streaming request and returns the response once the provider accepted it.
+// Non-200 responses are read in full and returned as API errors, like the blocking calls.
+func postLLMStream(ctx context.Context, provider, url string, headers map[string]string, body interface{}) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s stream request: %w", provider, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s stream request: %w", provider, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	// No client timeout: a stream legitimately stays open while tokens arrive; ctx bounds it
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s stream api: %w", provider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, utils.NewAPIError(resp.StatusCode, fmt.Sprintf("%s api returned status %d: %s", provider, resp.StatusCode, string(respBody)))
+	}
+	return resp, nil
+}
+
+// streamChatCompletions streams an OpenAI-compatible /chat/completions request (OpenAI, Groq)
+func streamChatCompletions(ctx context.Context, provider, url, apiKey string, body interface{}, onChunk func(chunk string)) (string, error) {
+	resp, err := postLLMStream(ctx, provider, url, map[string]string{"Authorization": "Bearer " + apiKey}, body)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var full strings.Builder
+	var parseErr error
+	err = readSSEEvents(resp.Body, func(_, data string) bool {
+		if data == "[DONE]" {
+			return false
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if parseErr = json.Unmarshal([]byte(data), &chunk); parseErr != nil {
+			return false
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				full.WriteString(choice.Delta.Content)
+				onChunk(choice.Delta.Content)
+			}
+		}
+		return true
+	})
+	if err == nil {
+		err = parseErr
+	}
+	if err != nil {
+		return full.String(), fmt.Errorf("failed to read %s stream: %w", provider, err)
+	}
+	if full.Len() == 0 {
+		return "", fmt.Errorf("%s api returned no choices", provider)
+	}
+	return full.String(), nil
+}
@@ -85,7 +85,14 @@ func (s *NotificationExternalService) PublishNotificationToRoom(req terminal_dto
 	for _, t := range terminals {
 		topic := fmt.Sprintf("users/%s/%s/notification", t.MacAddress, utils.GetConfig().ApplicationEnvironment)
 
-		err := s.mqttSvc.Publish(topic, 1, false, payloadBytes)
+		// If the broker is down the notification is queued until the meeting ends; a newer one for the same terminal replaces it.
+		// Once the meeting has ended it is only sent live, never queued with the outbox default TTL.
+		ttl := time.Until(dateTimeEnd)
+		err := infrastructure.PublishWithOptions(s.mqttSvc, topic, 1, false, payloadBytes, infrastructure.MqttPublishOptions{
+			DedupKey: "notification:" + t.MacAddress,
+			TTL:      ttl,
+			NoQueue:  ttl <= 0,
+		})
 		if err != nil {
 			utils.LogError("NotificationExternalService: Failed to publish to %s: %v", topic, err)
 			// According to the plan, we treat any failure as request failure
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/utils"
 	terminal_dtos "sensio/domain/terminal/terminal/dtos"
 	"sensio/domain/terminal/terminal/entities"
@@ -393,3 +394,46 @@ func TestPublishNotificationToRoom(t *testing.T) {
 		assert.Contains(t, err.Error(), "mqtt error")
 	})
 }
+
+// optionsMqttService records the outbox options of each publish
+type optionsMqttService struct {
+	MockMqttService
+	opts []infrastructure.MqttPublishOptions
+}
+
+func (m *optionsMqttService) PublishWithOptions(topic string, qos byte, retained bool, payload interface{}, opts infrastructure.MqttPublishOptions) error {
+	m.opts = append(m.opts, opts)
+	return nil
+}
+
+func TestPublishNotificationToRoom_OutboxOptions(t *testing.T) {
+	roomID := "room-123"
+	for _, tc := range []struct {
+		name    string
+		end     time.Time
+		noQueue bool
+	}{
+		{"upcoming meeting is queued until it ends", time.Now().Add(time.Hour), false},
+		{"ended meeting is only sent live", time.Now().Add(-time.Minute), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockTerminalRepository)
+			mockMqtt := &optionsMqttService{}
+			service := NewNotificationExternalService(mockRepo, mockMqtt)
+			mockRepo.On("GetByRoomID", roomID).Return([]entities.Terminal{{MacAddress: "AA:BB:CC:DD:EE:FF", RoomID: roomID}}, nil)
+
+			resp, err := service.PublishNotificationToRoom(terminal_dtos.NotificationPublishRequest{
+				RoomID:       roomID,
+				DateTimeEnd:  tc.end.Format(time.RFC3339),
+				IntervalTime: 15,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, resp.PublishedCount)
+			if assert.Len(t, mockMqtt.opts, 1) {
+				assert.Equal(t, "notification:AA:BB:CC:DD:EE:FF", mockMqtt.opts[0].DedupKey)
+				assert.Equal(t, tc.noQueue, mockMqtt.opts[0].NoQueue)
+			}
+		})
+	}
+}
@@ -36,6 +36,7 @@ type openaiMessage struct {
 type openaiRequest struct {
 	Model    string          `json:"model"`
 	Messages []openaiMessage `json:"messages"`
+	Stream   bool            `json:"stream,omitempty"`
 }
 
 type openaiResponse struct {
@@ -77,19 +78,7 @@ func (s *OpenAIService) CallModel(ctx context.Context, prompt string, model stri
 	promptChars := len(prompt)
 	approxTokens := (promptChars + 3) / 4
 
-	actualModel := model
-	switch {
-	case model == "high":
-		actualModel = s.config.OpenAIModelHigh
-	case model == "low":
-		actualModel = s.config.OpenAIModelLow
-	case model == "default" || model == "":
-		actualModel = s.config.OpenAIModelLow
-	}
-
-	if actualModel == "" {
-		actualModel = "gpt-3.5-turbo" // Safe default
-	}
+	actualModel := s.resolveModel(model)
 
 	url := "https://api.openai.com/v1/chat/completions"
 	startTime := time.Now()
@@ -185,6 +174,44 @@ func (s *OpenAIService) CallModel(ctx context.Context, prompt string, model stri
 	return result, nil
 }
 
+// StreamModel streams the completion, calling onChunk with each content delta
+func (s *OpenAIService) StreamModel(ctx context.Context, prompt string, model string, onChunk func(chunk string)) (string, error) {
+	if s.config.OpenAIApiKey == "" {
+		return "", fmt.Errorf("OPENAI_API_KEY is not configured")
+	}
+
+	actualModel := s.resolveModel(model)
+	startTime := time.Now()
+	result, err := streamChatCompletions(ctx, "openai", "https://api.openai.com/v1/chat/completions", s.config.OpenAIApiKey, openaiRequest{
+		Model:    actualModel,
+		Messages: []openaiMessage{{Role: "user", Content: prompt}},
+		Stream:   true,
+	}, onChunk)
+	if err != nil {
+		utils.LogWarn("OpenAI StreamModel failed: model=%s duration=%s err=%v", actualModel, time.Since(startTime), err)
+		return "", err
+	}
+	utils.LogDebug("OpenAI StreamModel success: model=%s duration=%s resp_chars=%d", actualModel, time.Since(startTime), len(result))
+	return result, nil
+}
+
+func (s *OpenAIService) resolveModel(model string) string {
+	actualModel := model
+	switch {
+	case model == "high":
+		actualModel = s.config.OpenAIModelHigh
+	case model == "low":
+		actualModel = s.config.OpenAIModelLow
+	case model == "default" || model == "":
+		actualModel = s.config.OpenAIModelLow
+	}
+
+	if actualModel == "" {
+		actualModel = "gpt-3.5-turbo" // Safe default
+	}
+	return actualModel
+}
+
 // Whisper Implementation
 
 // OpenAIDirectUploadLimitBytes is the maximum file size for direct OpenAI Whisper uploads.
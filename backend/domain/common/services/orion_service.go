@@ -145,6 +145,48 @@ func (s *OrionService) CallModel(ctx context.Context, prompt string, model strin
 	return "", fmt.Errorf("orion api returned no text content")
 }
 
+// StreamModel streams the response, calling onChunk with each output_text delta event
+func (s *OrionService) StreamModel(ctx context.Context, prompt string, model string, onChunk func(chunk string)) (string, error) {
+	if s.config.OrionApiKey == "" {
+		return "", fmt.Errorf("ORION_API_KEY is not configured")
+	}
+
+	url := fmt.Sprintf("%s/v1/responses", strings.TrimSuffix(s.config.OrionBaseURL, "/"))
+	resp, err := postLLMStream(ctx, "orion", url, map[string]string{"Authorization": "Bearer " + s.config.OrionApiKey}, map[string]interface{}{
+		"model":  s.config.OrionModel,
+		"input":  prompt,
+		"stream": true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var full strings.Builder
+	err = readSSEEvents(resp.Body, func(_, data string) bool {
+		var event struct {
+			Type  string `json:"type"`
+			Delta string `json:"delta"`
+		}
+		if json.Unmarshal([]byte(data), &event) != nil {
+			// Unknown event shapes are skipped, only text deltas matter
+			return true
+		}
+		if event.Type == "response.output_text.delta" && event.Delta != "" {
+			full.WriteString(event.Delta)
+			onChunk(event.Delta)
+		}
+		return event.Type != "response.completed"
+	})
+	if err != nil {
+		return full.String(), fmt.Errorf("failed to read orion stream: %w", err)
+	}
+	if full.Len() == 0 {
+		return "", fmt.Errorf("orion api returned no text content")
+	}
+	return full.String(), nil
+}
+
 // Whisper Implementation (Orion)
 
 // OrionDirectUploadLimitBytes is the maximum file size for direct Orion Whisper uploads.
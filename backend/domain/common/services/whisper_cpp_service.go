@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sensio/domain/common/utils"
+	"sensio/domain/models/whisper/dtos"
+	"strings"
+	"time"
+)
+
+// WhisperCppService transcribes audio locally with the whisper.cpp whisper-cli binary.
+// Unlike the remote providers it returns the decoder's segment timestamps, and labels
+// speakers by clustering the voice of each segment when diarization is requested.
+type WhisperCppService struct {
+	modelPath   string
+	maxSpeakers int
+}
+
+func NewWhisperCppService(cfg *utils.Config) *WhisperCppService {
+	return &WhisperCppService{
+		modelPath:   cfg.WhisperLocalModel,
+		maxSpeakers: cfg.DiarizationMaxSpeakers,
+	}
+}
+
+func (s *WhisperCppService) HealthCheck() bool {
+	if s.modelPath == "" {
+		return false
+	}
+	if _, err := os.Stat(s.modelPath); os.IsNotExist(err) {
+		return false
+	}
+	_, err := whisperCliPath()
+	return err == nil
+}
+
+// whisperCliPath finds whisper-cli: local bin first, then PATH
+func whisperCliPath() (string, error) {
+	bin := "./bin/whisper-cli"
+	if _, err := os.Stat(bin); os.IsNotExist(err) {
+		binInPath, err := exec.LookPath("whisper-cli")
+		if err != nil {
+			return "", fmt.Errorf("whisper-cli not found in ./bin or PATH: %w", err)
+		}
+		return binInPath, nil
+	}
+	return bin, nil
+}
+
+// whisperCppOutput is the file written by whisper-cli --output-json
+type whisperCppOutput struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+func (s *WhisperCppService) Transcribe(ctx context.Context, audioPath string, language string, diarize bool) (*dtos.WhisperResult, error) {
+	if s.modelPath == "" {
+		return nil, fmt.Errorf("WHISPER_LOCAL_MODEL is not configured")
+	}
+	bin, err := whisperCliPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// whisper-cli reads 16 kHz mono WAV, which the speaker features are computed from as well
+	wavPath, cleanup, err := utils.NormalizeToWavPCM16k(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	outputBase := fmt.Sprintf("%s.%d.whisper", wavPath, time.Now().UnixNano())
+	defer func() { _ = os.Remove(outputBase + ".json") }()
+
+	lang := language
+	if lang == "" {
+		lang = "auto"
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, bin,
+		"-m", s.modelPath,
+		"-f", wavPath,
+		"-l", lang,
+		"--output-json",
+		"--output-file", outputBase,
+		"--no-prints",
+	)
+	utils.LogDebug("WhisperCpp: Running %s on %s", cmd.Path, filepath.Base(wavPath))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("whisper-cli interrupted: %w", ctx.Err())
+		}
+		utils.LogDebug("WhisperCpp: raw failure output: %s", string(out))
+		return nil, fmt.Errorf("whisper-cli failed: %w", err)
+	}
+
+	data, err := os.ReadFile(outputBase + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read whisper-cli output: %w", err)
+	}
+	result, err := parseWhisperCppOutput(data, language)
+	if err != nil {
+		return nil, err
+	}
+
+	if diarize && len(result.Segments) > 0 {
+		samples, sampleRate, err := utils.ReadWavPCM16(wavPath)
+		if err != nil {
+			utils.LogWarn("WhisperCpp: Diarization skipped, cannot read %s: %v", wavPath, err)
+		} else {
+			diarizeSegments(result, samples, sampleRate, s.maxSpeakers)
+		}
+	}
+	return result, nil
+}
+
+// parseWhisperCppOutput turns whisper-cli JSON into a result whose Segments carry the decoder timestamps
+func parseWhisperCppOutput(data []byte, language string) (*dtos.WhisperResult, error) {
+	var output whisperCppOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to decode whisper-cli output: %w", err)
+	}
+
+	var segments []dtos.TranscriptSegment
+	var texts []string
+	for _, piece := range output.Transcription {
+		text := strings.TrimSpace(piece.Text)
+		// whisper.cpp marks silence and noise with bracketed tokens such as [BLANK_AUDIO]
+		if text == "" || (strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]")) {
+			continue
+		}
+		segments = append(segments, dtos.TranscriptSegment{
+			Index:   len(segments),
+			StartMs: piece.Offsets.From,
+			EndMs:   piece.Offsets.To,
+			Text:    text,
+		})
+		texts = append(texts, text)
+	}
+
+	detected := output.Result.Language
+	if detected == "" {
+		detected = language
+	}
+	return &dtos.WhisperResult{
+		Transcription:     strings.Join(texts, " "),
+		DetectedLanguage:  detected,
+		Source:            "Whisper.cpp",
+		Segments:          segments,
+		TranscriptFormat:  dtos.TranscriptFormatPlainText,
+		AlignedTimestamps: true,
+	}, nil
+}
+
+// diarizeSegments attributes every segment to a speaker and fills the result's speaker turns.
+// Each segment keeps its own utterance (with the voice embedding) so turns can be re-clustered
+// after segments of split audio are merged.
+func diarizeSegments(result *dtos.WhisperResult, samples []float64, sampleRate int, maxSpeakers int) {
+	for i, seg := range result.Segments {
+		result.Segments[i].Utterances = []dtos.Utterance{{
+			StartMs:          seg.StartMs,
+			EndMs:            seg.EndMs,
+			Text:             seg.Text,
+			SpeakerEmbedding: utils.SpeakerEmbedding(samples, sampleRate, seg.StartMs, seg.EndMs),
+		}}
+	}
+	turns := utils.DiarizeSegments(result.Segments, maxSpeakers)
+	if len(turns) == 0 {
+		// Not enough speech in any segment to tell voices apart
+		for i := range result.Segments {
+			result.Segments[i].Utterances = nil
+		}
+		return
+	}
+
+	result.Utterances = turns
+	result.Diarized = true
+	result.TranscriptFormat = dtos.TranscriptFormatUtteranceList
+	result.ConfidenceSummary = utils.BuildConfidenceSummary(turns, len(result.Segments))
+}
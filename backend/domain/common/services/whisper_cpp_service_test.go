@@ -0,0 +1,33 @@
+package services
+
+import (
+	"sensio/domain/models/whisper/dtos"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWhisperCppOutput(t *testing.T) {
+	output := []byte(`{
+		"result": {"language": "id"},
+		"transcription": [
+			{"offsets": {"from": 0, "to": 2500}, "text": " Selamat pagi semua."},
+			{"offsets": {"from": 2500, "to": 4000}, "text": " [BLANK_AUDIO]"},
+			{"offsets": {"from": 4000, "to": 7200}, "text": " Mari kita mulai rapatnya."}
+		]
+	}`)
+
+	result, err := parseWhisperCppOutput(output, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Selamat pagi semua. Mari kita mulai rapatnya.", result.Transcription)
+	assert.Equal(t, "id", result.DetectedLanguage)
+	assert.True(t, result.AlignedTimestamps)
+	assert.Equal(t, dtos.TranscriptFormatPlainText, result.TranscriptFormat)
+	assert.Equal(t, []dtos.TranscriptSegment{
+		{Index: 0, StartMs: 0, EndMs: 2500, Text: "Selamat pagi semua."},
+		{Index: 1, StartMs: 4000, EndMs: 7200, Text: "Mari kita mulai rapatnya."},
+	}, result.Segments)
+
+	_, err = parseWhisperCppOutput([]byte("not json"), "en")
+	assert.Error(t, err)
+}
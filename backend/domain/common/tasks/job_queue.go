@@ -0,0 +1,621 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sensio/domain/common/utils"
+)
+
+// jobKeyPrefix is outside the "cache:" prefix, so flushing the cache keeps queued jobs
+const jobKeyPrefix = "job:"
+
+// maxJobRetryBackoff caps the exponential delay between two attempts
+const maxJobRetryBackoff = time.Hour
+
+// Job states
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// ErrJobNotFound is returned for unknown or expired job ids
+var ErrJobNotFound = errors.New("job not found")
+
+// Job is one unit of background work. The payload carries everything the handler needs,
+// so a job queued or running when the process stopped is run again by the next one.
+type Job struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Status          string          `json:"status"`
+	Payload         json.RawMessage `json:"payload,omitempty"`
+	Attempts        int             `json:"attempts"`
+	MaxAttempts     int             `json:"max_attempts"`
+	LastError       string          `json:"last_error,omitempty"`
+	CancelRequested bool            `json:"cancel_requested,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	RunAfter        time.Time       `json:"run_after"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+}
+
+// Decode unmarshals the job payload into out
+func (j *Job) Decode(out any) error {
+	return json.Unmarshal(j.Payload, out)
+}
+
+// LastAttempt reports whether a failure of the current run is final
+func (j *Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// Finished reports whether the job reached a final state
+func (j *Job) Finished() bool {
+	switch j.Status {
+	case JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// JobHandler runs one attempt of a job. The context is cancelled when the job is cancelled or its
+// attempt times out. A returned error schedules a retry with backoff, unless the attempt was the
+// last one or the error is wrapped with Permanent.
+type JobHandler func(ctx context.Context, job *Job) error
+
+// JobTypeOptions tunes how the jobs of one type run
+type JobTypeOptions struct {
+	// MaxAttempts overrides the queue default when > 0
+	MaxAttempts int
+	// Timeout is the deadline of each attempt; 0 means none
+	Timeout time.Duration
+	// OnCancel lets the owner update its own task status once a cancelled job is no longer running
+	OnCancel func(job *Job)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error that retrying cannot fix, so the job fails without further attempts
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// JobStore persists job records (implemented by infrastructure.BadgerService)
+type JobStore interface {
+	SetPersistent(key string, value []byte) error
+	SetWithTTL(key string, value []byte, ttl time.Duration) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+	GetAllKeysWithPrefix(prefix string) ([]string, error)
+}
+
+// JobQueueConfig holds the limits shared by all job types
+type JobQueueConfig struct {
+	// Concurrency is the number of workers per job type. Sub-types ("mail.by_mac") fall back to
+	// the entry of their family ("mail"), then to DefaultConcurrency.
+	Concurrency        map[string]int
+	DefaultConcurrency int
+	MaxAttempts        int
+	RetryBackoff       time.Duration // Delay before the first retry, doubled on each attempt
+	Retention          time.Duration // How long finished jobs stay listed
+}
+
+type jobWorker struct {
+	handler JobHandler
+	opts    JobTypeOptions
+	limit   int
+	running int
+}
+
+// JobQueue runs background work (transcription, translation, summary, pipeline, mail) through
+// per-type worker pools. Jobs are kept in memory and written through to the store, so queued
+// jobs survive a restart: jobs still marked running were interrupted and are queued again,
+// or failed when the interrupted attempt was their last.
+//
+// Stop only stops dispatching; attempts in flight are not interrupted, and are resumed by the
+// next process if it exits before they finish.
+type JobQueue struct {
+	store  JobStore
+	config JobQueueConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	workers map[string]*jobWorker
+	cancels map[string]context.CancelFunc
+
+	wake     chan struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+	started  bool
+	stopOnce sync.Once
+}
+
+// NewJobQueue creates a queue and restores the jobs persisted by a previous run.
+//
+// param store Where job records are persisted; nil keeps them in memory only.
+// param config The concurrency, retry and retention limits.
+// return *JobQueue A pointer to the initialized queue; call Start once handlers are registered.
+func NewJobQueue(store JobStore, config JobQueueConfig) *JobQueue {
+	if config.DefaultConcurrency <= 0 {
+		config.DefaultConcurrency = 1
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 30 * time.Second
+	}
+	if config.Retention <= 0 {
+		config.Retention = 24 * time.Hour
+	}
+	q := &JobQueue{
+		store:   store,
+		config:  config,
+		now:     time.Now,
+		jobs:    make(map[string]*Job),
+		workers: make(map[string]*jobWorker),
+		cancels: make(map[string]context.CancelFunc),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	q.restore()
+	return q
+}
+
+// NewJobQueueFromConfig creates a queue with the JOB_* settings of the application config
+func NewJobQueueFromConfig(store JobStore, cfg *utils.Config) *JobQueue {
+	backoff, err := time.ParseDuration(cfg.JobRetryBackoff)
+	if err != nil {
+		backoff = 30 * time.Second
+	}
+	retention, err := time.ParseDuration(cfg.JobRetention)
+	if err != nil {
+		retention = 24 * time.Hour
+	}
+	return NewJobQueue(store, JobQueueConfig{
+		Concurrency:        ParseJobConcurrency(cfg.JobConcurrency),
+		DefaultConcurrency: cfg.JobDefaultConcurrency,
+		MaxAttempts:        cfg.JobMaxAttempts,
+		RetryBackoff:       backoff,
+		Retention:          retention,
+	})
+}
+
+// ParseJobConcurrency reads "type=n" pairs separated by commas; invalid entries are skipped
+func ParseJobConcurrency(spec string) map[string]int {
+	limits := make(map[string]int)
+	for _, part := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n <= 0 {
+			utils.LogWarn("JobQueue: ignoring invalid concurrency entry %q", part)
+			continue
+		}
+		limits[strings.TrimSpace(name)] = n
+	}
+	return limits
+}
+
+// Register sets the handler of a job type. Handlers may be registered before or after Start;
+// restored jobs of a type wait in the queue until its handler is registered.
+func (q *JobQueue) Register(jobType string, handler JobHandler, opts JobTypeOptions) {
+	q.mu.Lock()
+	q.workers[jobType] = &jobWorker{
+		handler: handler,
+		opts:    opts,
+		limit:   q.concurrency(jobType),
+	}
+	q.mu.Unlock()
+	q.signal()
+}
+
+// Enqueue persists a new job and wakes the dispatcher.
+//
+// param jobType A registered job type.
+// param id The job id; callers pass their task id so both can be looked up with the same value. Empty generates one.
+// param payload Any JSON-marshalable value, handed back to the handler through Job.Decode.
+// return *Job A copy of the queued job.
+// return error An error if the type is unknown, the id is already active or the payload cannot be encoded.
+func (q *JobQueue) Enqueue(jobType string, id string, payload any) (*Job, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job payload: %w", err)
+	}
+	if id == "" {
+		id = utils.GenerateUUID()
+	}
+
+	q.mu.Lock()
+	worker, ok := q.workers[jobType]
+	if !ok {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("job type %q is not registered", jobType)
+	}
+	if existing, ok := q.jobs[id]; ok && !existing.Finished() {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("job %s is already %s", id, existing.Status)
+	}
+	maxAttempts := worker.opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = q.config.MaxAttempts
+	}
+	now := q.now()
+	job := &Job{
+		ID:          id,
+		Type:        jobType,
+		Status:      JobStatusQueued,
+		Payload:     body,
+		MaxAttempts: maxAttempts,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		RunAfter:    now,
+	}
+	q.jobs[id] = job
+	q.save(job)
+	out := *job
+	q.mu.Unlock()
+
+	utils.LogDebug("JobQueue: queued %s job %s", jobType, id)
+	q.signal()
+	return &out, nil
+}
+
+// Get returns a copy of a job
+func (q *JobQueue) Get(id string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	out := *job
+	return &out, nil
+}
+
+// List returns the jobs of a type and/or status (empty matches all), newest first
+func (q *JobQueue) List(jobType string, status string) []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.prune()
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		if jobType != "" && job.Type != jobType {
+			continue
+		}
+		if status != "" && job.Status != status {
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs
+}
+
+// Cancel stops a job. A queued job is cancelled at once; a running job has its context
+// cancelled and is marked cancelled when its handler returns. Cancelling a finished job is a no-op.
+func (q *JobQueue) Cancel(id string) (*Job, error) {
+	q.mu.Lock()
+	job, ok := q.jobs[id]
+	if !ok {
+		q.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+
+	var onCancel func(job *Job)
+	switch job.Status {
+	case JobStatusQueued:
+		q.finish(job, JobStatusCancelled)
+		if worker, ok := q.workers[job.Type]; ok {
+			onCancel = worker.opts.OnCancel
+		}
+	case JobStatusRunning:
+		if !job.CancelRequested {
+			job.CancelRequested = true
+			job.UpdatedAt = q.now()
+			q.save(job)
+		}
+		if cancel, ok := q.cancels[id]; ok {
+			cancel()
+		}
+	}
+	out := *job
+	q.mu.Unlock()
+
+	if onCancel != nil {
+		onCancel(&out)
+	}
+	utils.LogInfo("JobQueue: cancel requested for %s job %s (status=%s)", out.Type, id, out.Status)
+	return &out, nil
+}
+
+// Start dispatches queued jobs in the background until Stop is called
+func (q *JobQueue) Start() {
+	q.mu.Lock()
+	if q.started {
+		q.mu.Unlock()
+		return
+	}
+	q.started = true
+	q.mu.Unlock()
+
+	q.wg.Add(1)
+	go q.loop()
+	utils.LogInfo("JobQueue: started (default_concurrency=%d, max_attempts=%d)", q.config.DefaultConcurrency, q.config.MaxAttempts)
+}
+
+// Stop stops dispatching new attempts and waits for the dispatcher to exit
+func (q *JobQueue) Stop() {
+	q.stopOnce.Do(func() {
+		close(q.done)
+		q.wg.Wait()
+	})
+}
+
+func (q *JobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *JobQueue) loop() {
+	defer q.wg.Done()
+	for {
+		wait := q.dispatch()
+		timer := time.NewTimer(wait)
+		select {
+		case <-q.done:
+			timer.Stop()
+			return
+		case <-q.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// dispatch starts every due job a worker slot is free for, oldest first, and returns how long
+// to sleep until the next retry becomes due
+func (q *JobQueue) dispatch() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	wait := time.Minute
+	queued := make([]*Job, 0)
+	for _, job := range q.jobs {
+		if job.Status == JobStatusQueued {
+			queued = append(queued, job)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool { return queued[i].CreatedAt.Before(queued[j].CreatedAt) })
+
+	for _, job := range queued {
+		worker, ok := q.workers[job.Type]
+		if !ok {
+			continue
+		}
+		if job.RunAfter.After(now) {
+			if d := job.RunAfter.Sub(now); d < wait {
+				wait = d
+			}
+			continue
+		}
+		if worker.running >= worker.limit {
+			continue
+		}
+
+		startedAt := now
+		job.Status = JobStatusRunning
+		job.Attempts++
+		job.StartedAt = &startedAt
+		job.UpdatedAt = now
+		q.save(job)
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if worker.opts.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), worker.opts.Timeout)
+		} else {
+			ctx, cancel = context.WithCancel(context.Background())
+		}
+		q.cancels[job.ID] = cancel
+		worker.running++
+		attempt := *job
+		go q.execute(ctx, cancel, worker, &attempt)
+	}
+	return wait
+}
+
+func (q *JobQueue) execute(ctx context.Context, cancel context.CancelFunc, worker *jobWorker, attempt *Job) {
+	defer cancel()
+	utils.LogDebug("JobQueue: running %s job %s (attempt %d/%d)", attempt.Type, attempt.ID, attempt.Attempts, attempt.MaxAttempts)
+	err := runJobHandler(ctx, worker.handler, attempt)
+
+	q.mu.Lock()
+	worker.running--
+	delete(q.cancels, attempt.ID)
+	job, ok := q.jobs[attempt.ID]
+	if !ok {
+		q.mu.Unlock()
+		q.signal()
+		return
+	}
+
+	var onCancel func(job *Job)
+	switch {
+	case job.CancelRequested:
+		q.finish(job, JobStatusCancelled)
+		onCancel = worker.opts.OnCancel
+	case err == nil:
+		job.LastError = ""
+		q.finish(job, JobStatusCompleted)
+	case IsPermanent(err) || job.LastAttempt():
+		job.LastError = err.Error()
+		q.finish(job, JobStatusFailed)
+		utils.LogError("JobQueue: %s job %s failed after %d attempt(s): %v", job.Type, job.ID, job.Attempts, err)
+	default:
+		backoff := q.backoff(job.Attempts)
+		job.Status = JobStatusQueued
+		job.LastError = err.Error()
+		job.RunAfter = q.now().Add(backoff)
+		job.UpdatedAt = q.now()
+		q.save(job)
+		utils.LogWarn("JobQueue: %s job %s attempt %d/%d failed, retrying in %s: %v", job.Type, job.ID, job.Attempts, job.MaxAttempts, backoff, err)
+	}
+	out := *job
+	q.mu.Unlock()
+
+	if onCancel != nil {
+		onCancel(&out)
+	}
+	q.signal()
+}
+
+// runJobHandler turns a handler panic into an error so the worker slot is always released
+func runJobHandler(ctx context.Context, handler JobHandler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+func (q *JobQueue) backoff(attempts int) time.Duration {
+	backoff := q.config.RetryBackoff
+	for i := 1; i < attempts && backoff < maxJobRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxJobRetryBackoff {
+		backoff = maxJobRetryBackoff
+	}
+	return backoff
+}
+
+// concurrency returns the worker limit of a type, falling back to its family and then the default
+func (q *JobQueue) concurrency(jobType string) int {
+	if n, ok := q.config.Concurrency[jobType]; ok {
+		return n
+	}
+	if family, _, ok := strings.Cut(jobType, "."); ok {
+		if n, ok := q.config.Concurrency[family]; ok {
+			return n
+		}
+	}
+	return q.config.DefaultConcurrency
+}
+
+// finish moves a job to a final state; its record then expires after the retention period
+func (q *JobQueue) finish(job *Job, status string) {
+	now := q.now()
+	job.Status = status
+	job.FinishedAt = &now
+	job.UpdatedAt = now
+	q.save(job)
+}
+
+// prune forgets finished jobs older than the retention period (their records expired in the store)
+func (q *JobQueue) prune() {
+	cutoff := q.now().Add(-q.config.Retention)
+	for id, job := range q.jobs {
+		if job.Finished() && job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+func (q *JobQueue) save(job *Job) {
+	if q.store == nil {
+		return
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		utils.LogError("JobQueue: failed to encode job %s: %v", job.ID, err)
+		return
+	}
+	if job.Finished() {
+		err = q.store.SetWithTTL(jobKeyPrefix+job.ID, data, q.config.Retention)
+	} else {
+		err = q.store.SetPersistent(jobKeyPrefix+job.ID, data)
+	}
+	if err != nil {
+		utils.LogError("JobQueue: failed to persist job %s: %v", job.ID, err)
+	}
+}
+
+// restore loads the persisted jobs. A job interrupted while running keeps the attempt it lost, so a job
+// that crashes the process cannot retry forever: it is queued again, or failed once it used its last attempt.
+func (q *JobQueue) restore() {
+	if q.store == nil {
+		return
+	}
+	keys, err := q.store.GetAllKeysWithPrefix(jobKeyPrefix)
+	if err != nil {
+		utils.LogError("JobQueue: failed to list persisted jobs: %v", err)
+		return
+	}
+	resumed := 0
+	for _, key := range keys {
+		data, err := q.store.Get(key)
+		if err != nil || data == nil {
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			utils.LogWarn("JobQueue: dropping unreadable job record %s: %v", key, err)
+			_ = q.store.Delete(key)
+			continue
+		}
+		if job.Status == JobStatusRunning {
+			switch {
+			case job.CancelRequested:
+				q.finish(&job, JobStatusCancelled)
+			case job.LastAttempt():
+				job.LastError = "interrupted by a restart on its last attempt"
+				q.finish(&job, JobStatusFailed)
+				utils.LogError("JobQueue: %s job %s failed after %d attempt(s): %s", job.Type, job.ID, job.Attempts, job.LastError)
+			default:
+				job.Status = JobStatusQueued
+				job.RunAfter = q.now()
+				q.save(&job)
+			}
+		}
+		if !job.Finished() {
+			resumed++
+		}
+		q.jobs[job.ID] = &job
+	}
+	if resumed > 0 {
+		utils.LogInfo("JobQueue: %d unfinished job(s) restored from disk", resumed)
+	}
+}
@@ -0,0 +1,262 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeJobStore keeps job records in memory, ignoring TTLs
+type fakeJobStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newFakeJobStore() *fakeJobStore {
+	return &fakeJobStore{data: make(map[string][]byte)}
+}
+
+func (s *fakeJobStore) SetPersistent(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func (s *fakeJobStore) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	return s.SetPersistent(key, value)
+}
+
+func (s *fakeJobStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *fakeJobStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *fakeJobStore) GetAllKeysWithPrefix(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func startJobQueue(t *testing.T, store JobStore, config JobQueueConfig) *JobQueue {
+	q := NewJobQueue(store, config)
+	q.Start()
+	t.Cleanup(q.Stop)
+	return q
+}
+
+// waitForJob polls until the job reaches the status
+func waitForJob(t *testing.T, q *JobQueue, id string, status string) *Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, err := q.Get(id); err == nil && job.Status == status {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := q.Get(id)
+	t.Fatalf("job %s did not reach status %s: %+v", id, status, job)
+	return nil
+}
+
+func TestJobQueue_RespectsConcurrencyLimit(t *testing.T) {
+	q := startJobQueue(t, nil, JobQueueConfig{Concurrency: map[string]int{"work": 2}})
+
+	var running, peak int32
+	release := make(chan struct{})
+	q.Register("work", func(ctx context.Context, job *Job) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		return nil
+	}, JobTypeOptions{})
+
+	ids := []string{"a", "b", "c", "d", "e"}
+	for _, id := range ids {
+		if _, err := q.Enqueue("work", id, nil); err != nil {
+			t.Fatalf("Enqueue(%s) failed: %v", id, err)
+		}
+	}
+	waitForJob(t, q, "b", JobStatusRunning)
+	if got := len(q.List("work", JobStatusQueued)); got != 3 {
+		t.Errorf("Expected 3 queued jobs behind the limit, got %d", got)
+	}
+
+	close(release)
+	for _, id := range ids {
+		waitForJob(t, q, id, JobStatusCompleted)
+	}
+	if got := atomic.LoadInt32(&peak); got != 2 {
+		t.Errorf("Expected at most 2 jobs running at once, got %d", got)
+	}
+
+	if _, err := q.Enqueue("unknown", "", nil); err == nil {
+		t.Error("Expected an error for an unregistered job type")
+	}
+}
+
+func TestJobQueue_RetriesWithBackoff(t *testing.T) {
+	q := startJobQueue(t, nil, JobQueueConfig{MaxAttempts: 3, RetryBackoff: 10 * time.Millisecond})
+
+	var calls int32
+	q.Register("flaky", func(ctx context.Context, job *Job) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("provider unavailable")
+		}
+		return nil
+	}, JobTypeOptions{})
+	q.Register("invalid", func(ctx context.Context, job *Job) error {
+		return Permanent(errors.New("file not found"))
+	}, JobTypeOptions{})
+
+	_, _ = q.Enqueue("flaky", "flaky-1", nil)
+	job := waitForJob(t, q, "flaky-1", JobStatusCompleted)
+	if job.Attempts != 3 || job.LastError != "" {
+		t.Errorf("Expected success on the third attempt, got attempts=%d last_error=%q", job.Attempts, job.LastError)
+	}
+
+	_, _ = q.Enqueue("invalid", "invalid-1", nil)
+	job = waitForJob(t, q, "invalid-1", JobStatusFailed)
+	if job.Attempts != 1 || job.LastError != "file not found" {
+		t.Errorf("Expected a permanent error to fail at once, got attempts=%d last_error=%q", job.Attempts, job.LastError)
+	}
+
+	if got := q.backoff(1); got != 10*time.Millisecond {
+		t.Errorf("Expected first backoff of 10ms, got %s", got)
+	}
+	if got := q.backoff(3); got != 40*time.Millisecond {
+		t.Errorf("Expected backoff to double per attempt, got %s", got)
+	}
+	if got := q.backoff(64); got != maxJobRetryBackoff {
+		t.Errorf("Expected backoff to be capped at %s, got %s", maxJobRetryBackoff, got)
+	}
+}
+
+func TestJobQueue_Cancel(t *testing.T) {
+	q := startJobQueue(t, nil, JobQueueConfig{})
+
+	var mu sync.Mutex
+	var cancelled []string
+	q.Register("work", func(ctx context.Context, job *Job) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, JobTypeOptions{OnCancel: func(job *Job) {
+		mu.Lock()
+		cancelled = append(cancelled, job.ID)
+		mu.Unlock()
+	}})
+
+	_, _ = q.Enqueue("work", "running", nil)
+	waitForJob(t, q, "running", JobStatusRunning)
+	_, _ = q.Enqueue("work", "queued", nil)
+
+	job, err := q.Cancel("queued")
+	if err != nil || job.Status != JobStatusCancelled {
+		t.Fatalf("Expected a queued job to be cancelled at once, got %+v (err=%v)", job, err)
+	}
+
+	job, _ = q.Cancel("running")
+	if !job.CancelRequested {
+		t.Error("Expected cancellation to be requested for the running job")
+	}
+	waitForJob(t, q, "running", JobStatusCancelled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(cancelled) != 2 || cancelled[0] != "queued" || cancelled[1] != "running" {
+		t.Errorf("Expected OnCancel for both jobs, got %v", cancelled)
+	}
+	if _, err := q.Cancel("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestJobQueue_ResumesJobsAfterRestart(t *testing.T) {
+	store := newFakeJobStore()
+
+	// First process: the job is interrupted while running
+	first := startJobQueue(t, store, JobQueueConfig{MaxAttempts: 3})
+	started := make(chan struct{})
+	first.Register("transcription", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, JobTypeOptions{})
+	if _, err := first.Enqueue("transcription", "task-1", map[string]string{"input_path": "/tmp/a.wav"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	<-started
+
+	// Second process: the job is queued again, still counting the lost attempt
+	second := NewJobQueue(store, JobQueueConfig{MaxAttempts: 3})
+	job, err := second.Get("task-1")
+	if err != nil {
+		t.Fatalf("Expected the job to be restored: %v", err)
+	}
+	if job.Status != JobStatusQueued || job.Attempts != 1 {
+		t.Errorf("Expected a queued job with 1 attempt, got status=%s attempts=%d", job.Status, job.Attempts)
+	}
+
+	var payload map[string]string
+	second.Register("transcription", func(ctx context.Context, job *Job) error {
+		return job.Decode(&payload)
+	}, JobTypeOptions{})
+	second.Start()
+	t.Cleanup(second.Stop)
+
+	waitForJob(t, second, "task-1", JobStatusCompleted)
+	if payload["input_path"] != "/tmp/a.wav" {
+		t.Errorf("Expected the payload to survive the restart, got %v", payload)
+	}
+}
+
+func TestJobQueue_FailsJobInterruptedOnLastAttempt(t *testing.T) {
+	store := newFakeJobStore()
+
+	// A job that takes the process down on every attempt
+	first := startJobQueue(t, store, JobQueueConfig{MaxAttempts: 1})
+	started := make(chan struct{})
+	first.Register("transcription", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, JobTypeOptions{})
+	if _, err := first.Enqueue("transcription", "task-1", nil); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	<-started
+
+	second := NewJobQueue(store, JobQueueConfig{MaxAttempts: 1})
+	job, err := second.Get("task-1")
+	if err != nil {
+		t.Fatalf("Expected the job to be restored: %v", err)
+	}
+	if job.Status != JobStatusFailed || job.Attempts != 1 || job.LastError == "" {
+		t.Errorf("Expected the job to fail after its last attempt, got status=%s attempts=%d error=%q", job.Status, job.Attempts, job.LastError)
+	}
+}
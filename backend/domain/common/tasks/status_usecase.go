@@ -1,17 +1,28 @@
 package tasks
 
 import (
+	"errors"
 	"fmt"
 	"sensio/domain/common/utils"
 	"time"
 )
 
+// errTaskNotFound is returned when neither the store nor the cache holds the task
+var errTaskNotFound = errors.New("task not found")
+
 // StatusWithExpiry is an interface for status DTOs that support expiration tracking.
 // Implement this interface to enable automatic TTL info population.
 type StatusWithExpiry interface {
 	SetExpiry(expiresAt string, expiresInSeconds int64)
 }
 
+// StatusWithJob is implemented by status DTOs of tasks run on the JobQueue. The job record decides
+// whether the task failed or was cancelled, e.g. when a restart interrupted it after its last attempt;
+// status is in task terms (pending, processing, completed, failed, cancelled).
+type StatusWithJob interface {
+	ApplyJobStatus(status string, lastError string)
+}
+
 // GenericStatusUseCase provides common task status retrieval logic.
 // It handles both in-memory and persistent storage with TTL support.
 type GenericStatusUseCase[T any] interface {
@@ -21,6 +32,7 @@ type GenericStatusUseCase[T any] interface {
 type genericStatusUseCase[T any] struct {
 	cache *BadgerTaskCache
 	store *StatusStore[T]
+	jobs  *JobQueue
 }
 
 // NewGenericStatusUseCase creates a new generic status usecase.
@@ -40,6 +52,18 @@ func NewGenericStatusUseCase[T any](cache *BadgerTaskCache, store *StatusStore[T
 	}
 }
 
+// NewJobStatusUseCase is NewGenericStatusUseCase for tasks run on the JobQueue. The stored status
+// still carries the task progress and result, but the job record is the source of truth for its
+// lifecycle: a failed or cancelled job overrides the stored status, and a job whose status was lost
+// is reported from the job record alone.
+func NewJobStatusUseCase[T any](cache *BadgerTaskCache, store *StatusStore[T], jobs *JobQueue) GenericStatusUseCase[T] {
+	return &genericStatusUseCase[T]{
+		cache: cache,
+		store: store,
+		jobs:  jobs,
+	}
+}
+
 // GetTaskStatus retrieves task status from storage.
 // It first checks the in-memory store, then falls back to persistent cache.
 // For status DTOs that implement StatusWithExpiry interface, TTL info is automatically populated.
@@ -51,6 +75,40 @@ func NewGenericStatusUseCase[T any](cache *BadgerTaskCache, store *StatusStore[T
 //   - *T: The task status DTO
 //   - error: Error if task not found or retrieval fails
 func (u *genericStatusUseCase[T]) GetTaskStatus(taskID string) (*T, error) {
+	status, err := u.storedStatus(taskID)
+	if u.jobs == nil {
+		return status, err
+	}
+	job, jobErr := u.jobs.Get(taskID)
+	if jobErr != nil {
+		return status, err
+	}
+	if status == nil {
+		if !errors.Is(err, errTaskNotFound) {
+			return nil, err
+		}
+		status = new(T)
+	} else if job.Status != JobStatusFailed && job.Status != JobStatusCancelled {
+		return status, nil
+	}
+	if withJob, ok := any(status).(StatusWithJob); ok {
+		withJob.ApplyJobStatus(taskStatusOf(job.Status), job.LastError)
+	}
+	return status, nil
+}
+
+// taskStatusOf maps a job status to the status vocabulary of the task endpoints
+func taskStatusOf(jobStatus string) string {
+	switch jobStatus {
+	case JobStatusQueued:
+		return "pending"
+	case JobStatusRunning:
+		return "processing"
+	}
+	return jobStatus
+}
+
+func (u *genericStatusUseCase[T]) storedStatus(taskID string) (*T, error) {
 	// First try in-memory map from store
 	if s, ok := u.store.Get(taskID); ok {
 		// Augment with TTL info if available and if type supports it
@@ -95,5 +153,5 @@ func (u *genericStatusUseCase[T]) GetTaskStatus(taskID string) (*T, error) {
 		utils.LogDebug("Task %s: not found in cache", taskID)
 	}
 
-	return nil, fmt.Errorf("task not found")
+	return nil, errTaskNotFound
 }
@@ -220,3 +220,48 @@ func TestGenericStatusUseCase_NilCache(t *testing.T) {
 		t.Errorf("expected status 'completed', got '%s'", retrieved.Status)
 	}
 }
+
+func (s *TestStatusWithExpiry) ApplyJobStatus(status string, lastError string) {
+	s.Status = status
+	if s.Message == "" {
+		s.Message = lastError
+	}
+}
+
+func TestJobStatusUseCase_JobRecordDecidesLifecycle(t *testing.T) {
+	// Two jobs interrupted by a restart on their last attempt
+	jobStore := newFakeJobStore()
+	for _, id := range []string{"task-failed", "task-lost"} {
+		data, _ := json.Marshal(Job{ID: id, Type: "transcription", Status: JobStatusRunning, Attempts: 1, MaxAttempts: 1})
+		_ = jobStore.SetPersistent(jobKeyPrefix+id, data)
+	}
+	data, _ := json.Marshal(Job{ID: "task-queued", Type: "transcription", Status: JobStatusQueued, MaxAttempts: 1})
+	_ = jobStore.SetPersistent(jobKeyPrefix+"task-queued", data)
+	jobs := NewJobQueue(jobStore, JobQueueConfig{})
+
+	store := NewStatusStore[TestStatusWithExpiry]()
+	store.Set("task-failed", &TestStatusWithExpiry{Status: "processing"})
+	store.Set("task-queued", &TestStatusWithExpiry{Status: "processing", Message: "50%"})
+	usecase := NewJobStatusUseCase(nil, store, jobs)
+
+	retrieved, err := usecase.GetTaskStatus("task-failed")
+	if err != nil || retrieved.Status != "failed" || retrieved.Message == "" {
+		t.Errorf("expected the failed job to override the stored status, got %+v (err=%v)", retrieved, err)
+	}
+
+	// The stored status keeps the progress of a job that is still pending
+	retrieved, err = usecase.GetTaskStatus("task-queued")
+	if err != nil || retrieved.Status != "processing" || retrieved.Message != "50%" {
+		t.Errorf("expected the stored status, got %+v (err=%v)", retrieved, err)
+	}
+
+	// A status lost with the in-memory store is derived from the job
+	retrieved, err = usecase.GetTaskStatus("task-lost")
+	if err != nil || retrieved.Status != "failed" {
+		t.Errorf("expected a status derived from the job, got %+v (err=%v)", retrieved, err)
+	}
+
+	if _, err := usecase.GetTaskStatus("missing"); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+}
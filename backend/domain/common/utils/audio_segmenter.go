@@ -9,9 +9,12 @@ import (
 )
 
 // AudioSegment represents a split portion of an audio file.
+// StartMs and EndMs locate the segment, overlap included, in the original audio.
 type AudioSegment struct {
-	Index int
-	Path  string
+	Index   int
+	Path    string
+	StartMs int64
+	EndMs   int64
 }
 
 // SplitAudioSegments splits an audio file into chunks of segmentSec duration with overlapSec overlap.
@@ -71,9 +74,15 @@ func SplitAudioSegments(inputPath string, segmentSec int, overlapSec int) ([]Aud
 			return nil, fmt.Errorf("ffmpeg split error at segment %d: %v", index, err)
 		}
 
+		end := start + duration
+		if end > totalDuration {
+			end = totalDuration
+		}
 		segments = append(segments, AudioSegment{
-			Index: index,
-			Path:  outPath,
+			Index:   index,
+			Path:    outPath,
+			StartMs: int64(start * 1000),
+			EndMs:   int64(end * 1000),
 		})
 
 		index++
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -18,6 +19,7 @@ type Config struct {
 	TuyaClientSecret       string
 	TuyaBaseURL            string
 	TuyaUserID             string
+	TuyaSpecCacheTTL       string // How long parsed device specifications are cached (Go duration)
 	ApiKey                 string
 	CacheTTL               string
 	ApplicationEnvironment string
@@ -27,16 +29,18 @@ type Config struct {
 	LLMProvider string // "gemini", "orion"
 
 	// Gemini
-	GeminiApiKey       string
-	GeminiModelHigh    string // High reasoning
-	GeminiModelLow     string // Fast/Low cost
-	GeminiModelWhisper string // STT
+	GeminiApiKey         string
+	GeminiModelHigh      string // High reasoning
+	GeminiModelLow       string // Fast/Low cost
+	GeminiModelWhisper   string // STT
+	GeminiModelEmbedding string
 
 	// OpenAI
-	OpenAIApiKey       string
-	OpenAIModelHigh    string
-	OpenAIModelLow     string
-	OpenAIModelWhisper string
+	OpenAIApiKey         string
+	OpenAIModelHigh      string
+	OpenAIModelLow       string
+	OpenAIModelWhisper   string
+	OpenAIModelEmbedding string
 
 	// Groq
 	GroqApiKey       string
@@ -50,11 +54,15 @@ type Config struct {
 	OrionModel   string
 
 	// Local Models
-	WhisperLocalModel   string // Path to whisper ggml model
-	LlamaLocalModel     string // Path to llama gguf model (e.g., bin/ggml-base.bin)
-	OrionWhisperBaseURL string // URL for remote transcription service
-	MaxFileSize         int64  // bytes
-	Port                string
+	WhisperLocalModel        string // Path to whisper ggml model
+	LlamaLocalModel          string // Path to llama gguf model (e.g., bin/ggml-base.bin)
+	LlamaLocalEmbeddingModel string // Path to llama gguf embedding model (defaults to LlamaLocalModel)
+	OrionWhisperBaseURL      string // URL for remote transcription service
+
+	// Vector Store
+	VectorEmbedder string // "gemini", "openai", "local", "hash"; empty follows LLMProvider
+	MaxFileSize    int64  // bytes
+	Port           string
 
 	// Python Services (for models-v1 proxy)
 	PythonWhisperServiceURL  string // URL for Python Whisper service (HTTP - legacy)
@@ -69,6 +77,12 @@ type Config struct {
 	EmqxAuthBaseURL string
 	EmqxAuthApiKey  string
 
+	// MQTT outbox (publishes queued in Badger while the broker is unreachable)
+	MqttOutboxEnabled       bool
+	MqttOutboxTTL           string // Default lifetime of a queued message (Go duration)
+	MqttOutboxMaxMessages   int    // Oldest messages are dropped beyond this size
+	MqttOutboxRetryInterval string // Delay before retrying a replay that failed while connected
+
 	// SMTP
 	SMTPHost     string
 	SMTPPort     string
@@ -80,12 +94,15 @@ type Config struct {
 	LogLevel string
 
 	// Database
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	JWTSecret  string
+	DBDriver       string // mysql or sqlite
+	SQLitePath     string
+	MigrationsPath string // Directory of the numbered SQL migrations
+	DBHost         string
+	DBPort         string
+	DBUser         string
+	DBPassword     string
+	DBName         string
+	JWTSecret      string
 
 	// Chunk Upload & Async Tasks
 	EnableChunkUpload          bool
@@ -109,8 +126,59 @@ type Config struct {
 	AudioSegmentSec            int
 	AudioSegmentOverlapSec     int
 	AudioSegmentMaxConcurrency int
+	DiarizationMaxSpeakers     int // Upper bound of speakers labeled by local diarization (0: no limit)
 	TaskEventPublishEnabled    bool
 	OrionTranscribeTimeout     string
+
+	// Scene Scheduler
+	SceneSchedulerEnabled         bool
+	SceneSchedulerInterval        string // How often due triggers are polled (Go duration)
+	SceneSchedulerCatchUpWindow   string // Runs missed by less than this after downtime still fire
+	SceneSchedulerDefaultTimezone string // IANA timezone of scene triggers whose terminal has none
+
+	// Door Lock password sync
+	DoorLockSyncEnabled    bool
+	DoorLockSyncInterval   string // How often pending passwords are pushed to locks (Go duration)
+	DoorLockSyncMaxRetries int    // Attempts before a pending password is marked failed
+
+	// Door Lock events and alarms
+	DoorLockEventPollInterval string // How often lock statuses are read back from Tuya (Go duration, "0" disables)
+	DoorLockAlertEmailEnabled bool
+	DoorLockAlertCooldown     string // Minimum time between two notifications of the same alarm of a lock
+
+	// Door Lock guest access
+	DoorLockGuestEnabled  bool
+	DoorLockGuestInterval string // How often guest codes are emailed and revoked at check-out (Go duration)
+
+	// Home snapshots and undo
+	SnapshotUndoDepth int    // Changes kept per terminal for "undo"
+	SnapshotUndoTTL   string // How long a change stays undoable (Go duration)
+
+	// Conversational memory
+	MemoryRecentTurns  int // User/assistant exchanges passed verbatim to the model
+	MemorySummaryBatch int // Older messages folded into the rolling summary at once
+	MemoryMaxFacts     int // Facts/preferences kept per user and terminal
+
+	// Rooms and zones
+	RoomControlConcurrency int // Devices commanded at once by a room/zone group command
+
+	// Tuya message service (real-time device status)
+	TuyaMQEnabled        bool
+	TuyaMQURL            string // Pulsar websocket endpoint of the region (wss://mqe.tuyaus.com:8285/)
+	TuyaMQEnv            string // "event" for production, "event-test" for the test channel
+	TuyaMQReconnectDelay string // Initial backoff before reconnecting a dropped subscription (Go duration)
+
+	// Tuya LAN control (offline operation)
+	TuyaLocalEnabled      bool
+	TuyaLocalTimeout      string // Connect and reply deadline of a local command (Go duration)
+	TuyaLocalDiscoveryTTL string // How long a UDP broadcast keeps a device locally reachable (Go duration)
+
+	// Background jobs (transcription, translation, summary, pipeline, mail)
+	JobConcurrency        string // Workers per job type as "type=n" pairs ("transcription=2,pipeline=1")
+	JobDefaultConcurrency int    // Workers of job types not listed in JobConcurrency
+	JobMaxAttempts        int    // Runs of a failing job before it is marked failed
+	JobRetryBackoff       string // Delay before the first retry, doubled on each attempt (Go duration)
+	JobRetention          string // How long finished jobs stay listed (Go duration)
 }
 
 // AppConfig is the global configuration instance.
@@ -169,21 +237,24 @@ func LoadConfig() {
 		TuyaClientSecret:       os.Getenv("TUYA_ACCESS_SECRET"),
 		TuyaBaseURL:            os.Getenv("TUYA_BASE_URL"),
 		TuyaUserID:             os.Getenv("TUYA_USER_ID"),
+		TuyaSpecCacheTTL:       getEnvAsDefault("TUYA_SPEC_CACHE_TTL", "24h"),
 		ApiKey:                 os.Getenv("API_KEY"),
 		JWTSecret:              os.Getenv("JWT_SECRET"),
 		LogLevel:               os.Getenv("LOG_LEVEL"),
 		ApplicationEnvironment: os.Getenv("APPLICATION_ENVIRONMENT"),
 		LLMProvider:            os.Getenv("LLM_PROVIDER"),
 
-		GeminiApiKey:       os.Getenv("GEMINI_API_KEY"),
-		GeminiModelHigh:    os.Getenv("GEMINI_MODEL_HIGH"),
-		GeminiModelLow:     os.Getenv("GEMINI_MODEL_LOW"),
-		GeminiModelWhisper: os.Getenv("GEMINI_MODEL_WHISPER"),
+		GeminiApiKey:         os.Getenv("GEMINI_API_KEY"),
+		GeminiModelHigh:      os.Getenv("GEMINI_MODEL_HIGH"),
+		GeminiModelLow:       os.Getenv("GEMINI_MODEL_LOW"),
+		GeminiModelWhisper:   os.Getenv("GEMINI_MODEL_WHISPER"),
+		GeminiModelEmbedding: os.Getenv("GEMINI_MODEL_EMBEDDING"),
 
-		OpenAIApiKey:       os.Getenv("OPENAI_API_KEY"),
-		OpenAIModelHigh:    os.Getenv("OPENAI_MODEL_HIGH"),
-		OpenAIModelLow:     os.Getenv("OPENAI_MODEL_LOW"),
-		OpenAIModelWhisper: os.Getenv("OPENAI_MODEL_WHISPER"),
+		OpenAIApiKey:         os.Getenv("OPENAI_API_KEY"),
+		OpenAIModelHigh:      os.Getenv("OPENAI_MODEL_HIGH"),
+		OpenAIModelLow:       os.Getenv("OPENAI_MODEL_LOW"),
+		OpenAIModelWhisper:   os.Getenv("OPENAI_MODEL_WHISPER"),
+		OpenAIModelEmbedding: os.Getenv("OPENAI_MODEL_EMBEDDING"),
 
 		GroqApiKey:       os.Getenv("GROQ_API_KEY"),
 		GroqModelHigh:    os.Getenv("GROQ_MODEL_HIGH"),
@@ -194,11 +265,13 @@ func LoadConfig() {
 		OrionApiKey:  os.Getenv("ORION_API_KEY"),
 		OrionModel:   os.Getenv("ORION_MODEL"),
 		// Local Models
-		WhisperLocalModel:   os.Getenv("WHISPER_LOCAL_MODEL"),
-		LlamaLocalModel:     os.Getenv("LLAMA_LOCAL_MODEL"),
-		OrionWhisperBaseURL: os.Getenv("ORION_WHISPER_BASE_URL"),
-		MaxFileSize:         maxFileSize,
-		Port:                os.Getenv("PORT"),
+		WhisperLocalModel:        os.Getenv("WHISPER_LOCAL_MODEL"),
+		LlamaLocalModel:          os.Getenv("LLAMA_LOCAL_MODEL"),
+		LlamaLocalEmbeddingModel: os.Getenv("LLAMA_LOCAL_EMBEDDING_MODEL"),
+		OrionWhisperBaseURL:      os.Getenv("ORION_WHISPER_BASE_URL"),
+		VectorEmbedder:           os.Getenv("VECTOR_EMBEDDER"),
+		MaxFileSize:              maxFileSize,
+		Port:                     os.Getenv("PORT"),
 
 		// Python Services (for models-v1 proxy)
 		PythonWhisperServiceURL:  getEnvAsDefault("PYTHON_WHISPER_SERVICE_URL", "http://localhost:8001"),
@@ -212,6 +285,11 @@ func LoadConfig() {
 		EmqxAuthBaseURL: os.Getenv("EMQX_AUTH_BASE_URL"),
 		EmqxAuthApiKey:  os.Getenv("EMQX_AUTH_API_KEY"),
 
+		MqttOutboxEnabled:       os.Getenv("MQTT_OUTBOX_ENABLED") != "false",
+		MqttOutboxTTL:           getEnvAsDefault("MQTT_OUTBOX_TTL", "24h"),
+		MqttOutboxMaxMessages:   getEnvAsInt("MQTT_OUTBOX_MAX_MESSAGES", 10000),
+		MqttOutboxRetryInterval: getEnvAsDefault("MQTT_OUTBOX_RETRY_INTERVAL", "10s"),
+
 		// SMTP
 		SMTPHost:     os.Getenv("SMTP_HOST"),
 		SMTPPort:     os.Getenv("SMTP_PORT"),
@@ -222,11 +300,14 @@ func LoadConfig() {
 		// Runtime
 
 		// Database
-		DBHost:     os.Getenv("MYSQL_HOST"),
-		DBPort:     os.Getenv("MYSQL_PORT"),
-		DBUser:     os.Getenv("MYSQL_USER"),
-		DBPassword: os.Getenv("MYSQL_PASSWORD"),
-		DBName:     os.Getenv("MYSQL_DATABASE"),
+		DBDriver:       strings.ToLower(getEnvAsDefault("DB_DRIVER", "mysql")),
+		SQLitePath:     getEnvAsDefault("SQLITE_PATH", "./tmp/sensio.db"),
+		MigrationsPath: getEnvAsDefault("MIGRATIONS_PATH", "./migrations"),
+		DBHost:         os.Getenv("MYSQL_HOST"),
+		DBPort:         os.Getenv("MYSQL_PORT"),
+		DBUser:         os.Getenv("MYSQL_USER"),
+		DBPassword:     os.Getenv("MYSQL_PASSWORD"),
+		DBName:         os.Getenv("MYSQL_DATABASE"),
 
 		// Chunk Upload & Async Tasks
 		EnableChunkUpload:          os.Getenv("ENABLE_CHUNK_UPLOAD") == "true",
@@ -263,8 +344,59 @@ func LoadConfig() {
 		AudioSegmentSec:            getEnvAsInt("AUDIO_SEGMENT_SEC", 600),
 		AudioSegmentOverlapSec:     getEnvAsInt("AUDIO_SEGMENT_OVERLAP_SEC", 2),
 		AudioSegmentMaxConcurrency: getEnvAsInt("AUDIO_SEGMENT_MAX_CONCURRENCY", 2),
+		DiarizationMaxSpeakers:     getEnvAsInt("DIARIZATION_MAX_SPEAKERS", 8),
 		TaskEventPublishEnabled:    os.Getenv("TASK_EVENT_PUBLISH_ENABLED") == "true",
 		OrionTranscribeTimeout:     getEnvAsDefault("ORION_TRANSCRIBE_TIMEOUT", "360s"),
+
+		// Scene Scheduler
+		SceneSchedulerEnabled:         os.Getenv("SCENE_SCHEDULER_ENABLED") != "false",
+		SceneSchedulerInterval:        getEnvAsDefault("SCENE_SCHEDULER_INTERVAL", "15s"),
+		SceneSchedulerCatchUpWindow:   getEnvAsDefault("SCENE_SCHEDULER_CATCHUP_WINDOW", "5m"),
+		SceneSchedulerDefaultTimezone: getEnvAsDefault("SCENE_SCHEDULER_DEFAULT_TIMEZONE", "UTC"),
+
+		// Door Lock password sync
+		DoorLockSyncEnabled:    os.Getenv("DOORLOCK_SYNC_ENABLED") != "false",
+		DoorLockSyncInterval:   getEnvAsDefault("DOORLOCK_SYNC_INTERVAL", "1m"),
+		DoorLockSyncMaxRetries: getEnvAsInt("DOORLOCK_SYNC_MAX_RETRIES", 10),
+
+		// Door Lock events and alarms
+		DoorLockEventPollInterval: getEnvAsDefault("DOORLOCK_EVENT_POLL_INTERVAL", "2m"),
+		DoorLockAlertEmailEnabled: os.Getenv("DOORLOCK_ALERT_EMAIL_ENABLED") != "false",
+		DoorLockAlertCooldown:     getEnvAsDefault("DOORLOCK_ALERT_COOLDOWN", "5m"),
+
+		// Door Lock guest access
+		DoorLockGuestEnabled:  os.Getenv("DOORLOCK_GUEST_ENABLED") != "false",
+		DoorLockGuestInterval: getEnvAsDefault("DOORLOCK_GUEST_INTERVAL", "1m"),
+
+		// Home snapshots and undo
+		SnapshotUndoDepth: getEnvAsInt("SNAPSHOT_UNDO_DEPTH", 20),
+		SnapshotUndoTTL:   getEnvAsDefault("SNAPSHOT_UNDO_TTL", "24h"),
+
+		// Conversational memory
+		MemoryRecentTurns:  getEnvAsInt("MEMORY_RECENT_TURNS", 5),
+		MemorySummaryBatch: getEnvAsInt("MEMORY_SUMMARY_BATCH", 10),
+		MemoryMaxFacts:     getEnvAsInt("MEMORY_MAX_FACTS", 30),
+
+		// Rooms and zones
+		RoomControlConcurrency: getEnvAsInt("ROOM_CONTROL_CONCURRENCY", 4),
+
+		// Tuya message service (real-time device status)
+		TuyaMQEnabled:        os.Getenv("TUYA_MQ_ENABLED") == "true",
+		TuyaMQURL:            getEnvAsDefault("TUYA_MQ_URL", "wss://mqe.tuyaus.com:8285/"),
+		TuyaMQEnv:            getEnvAsDefault("TUYA_MQ_ENV", "event"),
+		TuyaMQReconnectDelay: getEnvAsDefault("TUYA_MQ_RECONNECT_DELAY", "5s"),
+
+		// Tuya LAN control (offline operation)
+		TuyaLocalEnabled:      os.Getenv("TUYA_LOCAL_ENABLED") == "true",
+		TuyaLocalTimeout:      getEnvAsDefault("TUYA_LOCAL_TIMEOUT", "3s"),
+		TuyaLocalDiscoveryTTL: getEnvAsDefault("TUYA_LOCAL_DISCOVERY_TTL", "2m"),
+
+		// Background jobs
+		JobConcurrency:        getEnvAsDefault("JOB_CONCURRENCY", "transcription=2,pipeline=1,summary=2,translation=4,mail=4"),
+		JobDefaultConcurrency: getEnvAsInt("JOB_DEFAULT_CONCURRENCY", 2),
+		JobMaxAttempts:        getEnvAsInt("JOB_MAX_ATTEMPTS", 3),
+		JobRetryBackoff:       getEnvAsDefault("JOB_RETRY_BACKOFF", "30s"),
+		JobRetention:          getEnvAsDefault("JOB_RETENTION", "24h"),
 	}
 
 	// Defaults are removed to enforce explicit configuration via environment variables
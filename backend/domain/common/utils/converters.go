@@ -2,6 +2,7 @@ package utils
 
 import (
 	"strconv"
+	"strings"
 )
 
 // ToInt converts an interface{} value to int.
@@ -23,6 +24,27 @@ func ToInt(v interface{}) (int, bool) {
 	return 0, false
 }
 
+// ToFloat converts an interface{} value to float64.
+// It supports the numeric types produced by JSON decoding and numeric strings.
+// Returns 0 and false if conversion fails.
+func ToFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
 // MaxInt returns the larger of two integers.
 func MaxInt(a, b int) int {
 	if a > b {
@@ -0,0 +1,420 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"os"
+	"sensio/domain/models/whisper/dtos"
+	"strconv"
+	"strings"
+)
+
+const (
+	diarizationFrameMs   = 25
+	diarizationHopMs     = 10
+	diarizationMelBands  = 24
+	diarizationCepstra   = 12 // c1..c12; c0 (loudness) is left out
+	diarizationMinFrames = 30 // 0.3s of voiced audio below which a turn has no usable embedding
+
+	// speakerDistanceThreshold is the average-linkage distance (RMS difference of the embeddings, in
+	// natural-log cepstral units) above which two clusters are kept as different speakers
+	speakerDistanceThreshold = 1.0
+)
+
+// ReadWavPCM16 reads a 16-bit PCM WAV file, such as the output of NormalizeToWavPCM16k,
+// into mono samples in [-1, 1]
+func ReadWavPCM16(path string) ([]float64, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var header [12]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil {
+		return nil, 0, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, 0, fmt.Errorf("%s is not a WAV file", path)
+	}
+
+	var channels, bitsPerSample int
+	sampleRate := 0
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(f, chunk[:]); err != nil {
+			return nil, 0, fmt.Errorf("WAV file has no data chunk")
+		}
+		id, size := string(chunk[0:4]), int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		switch id {
+		case "fmt ":
+			format := make([]byte, size)
+			if _, err := io.ReadFull(f, format); err != nil || size < 16 {
+				return nil, 0, fmt.Errorf("invalid WAV format chunk")
+			}
+			if binary.LittleEndian.Uint16(format[0:2]) != 1 {
+				return nil, 0, fmt.Errorf("WAV file is not PCM")
+			}
+			channels = int(binary.LittleEndian.Uint16(format[2:4]))
+			sampleRate = int(binary.LittleEndian.Uint32(format[4:8]))
+			bitsPerSample = int(binary.LittleEndian.Uint16(format[14:16]))
+		case "data":
+			if bitsPerSample != 16 || channels < 1 {
+				return nil, 0, fmt.Errorf("unsupported WAV layout: %d-bit, %d channel(s)", bitsPerSample, channels)
+			}
+			data, err := io.ReadAll(io.LimitReader(f, size))
+			if err != nil {
+				return nil, 0, err
+			}
+			frames := len(data) / (2 * channels)
+			samples := make([]float64, frames)
+			for i := 0; i < frames; i++ {
+				var sum float64
+				for c := 0; c < channels; c++ {
+					offset := (i*channels + c) * 2
+					sum += float64(int16(binary.LittleEndian.Uint16(data[offset:offset+2]))) / 32768
+				}
+				samples[i] = sum / float64(channels)
+			}
+			return samples, sampleRate, nil
+		default:
+			if _, err := f.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+}
+
+// SpeakerEmbedding returns the voice features of samples between startMs and endMs: the mean and
+// spread of the mel cepstrum over the voiced frames. Returns nil when the range holds too little speech.
+func SpeakerEmbedding(samples []float64, sampleRate int, startMs, endMs int64) []float64 {
+	if sampleRate <= 0 {
+		return nil
+	}
+	from := int(startMs * int64(sampleRate) / 1000)
+	to := int(endMs * int64(sampleRate) / 1000)
+	if from < 0 {
+		from = 0
+	}
+	if to > len(samples) {
+		to = len(samples)
+	}
+	frameLen := sampleRate * diarizationFrameMs / 1000
+	hop := sampleRate * diarizationHopMs / 1000
+	if to-from < frameLen || hop == 0 {
+		return nil
+	}
+
+	fftSize := 1
+	for fftSize < frameLen {
+		fftSize <<= 1
+	}
+	filters := melFilterBank(fftSize, sampleRate)
+	window := make([]float64, frameLen)
+	for i := range window {
+		window[i] = 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(frameLen-1))
+	}
+
+	type frame struct {
+		energy float64
+		bands  []float64
+	}
+	var frames []frame
+	var totalEnergy float64
+	buffer := make([]complex128, fftSize)
+	for start := from; start+frameLen <= to; start += hop {
+		var energy float64
+		for i := range buffer {
+			buffer[i] = 0
+		}
+		for i := 0; i < frameLen; i++ {
+			v := samples[start+i]
+			energy += v * v
+			buffer[i] = complex(v*window[i], 0)
+		}
+		fft(buffer)
+		power := make([]float64, fftSize/2+1)
+		for i := range power {
+			power[i] = real(buffer[i])*real(buffer[i]) + imag(buffer[i])*imag(buffer[i])
+		}
+		bands := make([]float64, diarizationMelBands)
+		for b, filter := range filters {
+			var sum float64
+			for i, weight := range filter {
+				sum += weight * power[i]
+			}
+			bands[b] = math.Log(sum + 1e-10)
+		}
+		frames = append(frames, frame{energy: energy / float64(frameLen), bands: bands})
+		totalEnergy += energy / float64(frameLen)
+	}
+
+	// Voiced frames carry at least a fifth of the average energy of the range
+	threshold := math.Max(totalEnergy/float64(len(frames))*0.2, 1e-7)
+	var cepstra [][]float64
+	for _, f := range frames {
+		if f.energy >= threshold {
+			cepstra = append(cepstra, dctCepstrum(f.bands))
+		}
+	}
+	if len(cepstra) < diarizationMinFrames {
+		return nil
+	}
+
+	embedding := make([]float64, 2*diarizationCepstra)
+	for _, c := range cepstra {
+		for i, v := range c {
+			embedding[i] += v
+		}
+	}
+	for i := 0; i < diarizationCepstra; i++ {
+		embedding[i] /= float64(len(cepstra))
+	}
+	for _, c := range cepstra {
+		for i, v := range c {
+			d := v - embedding[i]
+			embedding[diarizationCepstra+i] += d * d
+		}
+	}
+	for i := 0; i < diarizationCepstra; i++ {
+		embedding[diarizationCepstra+i] = math.Sqrt(embedding[diarizationCepstra+i] / float64(len(cepstra)))
+	}
+	return embedding
+}
+
+// ClusterSpeakers labels the utterances "Speaker N" by grouping their SpeakerEmbedding with
+// average-linkage clustering, numbered in order of first appearance.
+// At most maxSpeakers are kept (0 means no limit). Utterances without an embedding take the label
+// of the previous utterance, or of the next one at the start.
+func ClusterSpeakers(utterances []dtos.Utterance, maxSpeakers int) {
+	var indices []int
+	for i, u := range utterances {
+		if len(u.SpeakerEmbedding) > 0 {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) == 0 {
+		return
+	}
+
+	n := len(indices)
+	distance := make([][]float64, n)
+	for i := range distance {
+		distance[i] = make([]float64, n)
+		for j := 0; j < i; j++ {
+			distance[i][j] = embeddingDistance(utterances[indices[i]].SpeakerEmbedding, utterances[indices[j]].SpeakerEmbedding)
+			distance[j][i] = distance[i][j]
+		}
+	}
+
+	// clusters holds the members of each live cluster; merged clusters are set to nil.
+	// distance keeps the average-linkage distance between live clusters (Lance-Williams update).
+	clusters := make([][]int, n)
+	for i := range clusters {
+		clusters[i] = []int{i}
+	}
+	live := n
+	for live > 1 {
+		bestA, bestB, best := -1, -1, math.MaxFloat64
+		for a := 0; a < n; a++ {
+			if clusters[a] == nil {
+				continue
+			}
+			for b := a + 1; b < n; b++ {
+				if clusters[b] != nil && distance[a][b] < best {
+					bestA, bestB, best = a, b, distance[a][b]
+				}
+			}
+		}
+		if best > speakerDistanceThreshold && (maxSpeakers <= 0 || live <= maxSpeakers) {
+			break
+		}
+		sizeA, sizeB := float64(len(clusters[bestA])), float64(len(clusters[bestB]))
+		for c := 0; c < n; c++ {
+			if clusters[c] == nil || c == bestA || c == bestB {
+				continue
+			}
+			d := (sizeA*distance[bestA][c] + sizeB*distance[bestB][c]) / (sizeA + sizeB)
+			distance[bestA][c], distance[c][bestA] = d, d
+		}
+		clusters[bestA] = append(clusters[bestA], clusters[bestB]...)
+		clusters[bestB] = nil
+		live--
+	}
+
+	clusterOf := make([]int, n)
+	for c, members := range clusters {
+		for _, m := range members {
+			clusterOf[m] = c
+		}
+	}
+	labels := make(map[int]string)
+	for k, i := range indices {
+		label, ok := labels[clusterOf[k]]
+		if !ok {
+			label = "Speaker " + strconv.Itoa(len(labels)+1)
+			labels[clusterOf[k]] = label
+		}
+		utterances[i].SpeakerLabel = label
+	}
+
+	previous := utterances[indices[0]].SpeakerLabel
+	for i := range utterances {
+		if len(utterances[i].SpeakerEmbedding) > 0 {
+			previous = utterances[i].SpeakerLabel
+		} else {
+			utterances[i].SpeakerLabel = previous
+		}
+	}
+}
+
+// DiarizeSegments clusters the utterances of the transcript pieces as one conversation, writes the
+// speaker labels back into the pieces and returns the speaker turns. Re-clustering after split audio is
+// merged keeps "Speaker N" consistent across segments. It returns nil when no piece had enough speech
+// to tell voices apart.
+func DiarizeSegments(segments []dtos.TranscriptSegment, maxSpeakers int) []dtos.Utterance {
+	var utterances []dtos.Utterance
+	for _, seg := range segments {
+		utterances = append(utterances, seg.Utterances...)
+	}
+	if len(utterances) == 0 {
+		return nil
+	}
+	ClusterSpeakers(utterances, maxSpeakers)
+	if utterances[0].SpeakerLabel == "" {
+		return nil
+	}
+
+	next := 0
+	for i := range segments {
+		for j := range segments[i].Utterances {
+			segments[i].Utterances[j].SpeakerLabel = utterances[next].SpeakerLabel
+			next++
+		}
+	}
+	return MergeSpeakerTurns(utterances)
+}
+
+// MergeSpeakerTurns joins consecutive utterances of the same speaker into one turn.
+// The embedding of a turn is the duration-weighted mean of its parts.
+func MergeSpeakerTurns(utterances []dtos.Utterance) []dtos.Utterance {
+	var turns []dtos.Utterance
+	for _, u := range utterances {
+		if len(turns) > 0 && turns[len(turns)-1].SpeakerLabel == u.SpeakerLabel && u.SpeakerLabel != "" {
+			last := &turns[len(turns)-1]
+			last.SpeakerEmbedding = weightedMean(last.SpeakerEmbedding, last.EndMs-last.StartMs, u.SpeakerEmbedding, u.EndMs-u.StartMs)
+			last.Text = strings.TrimSpace(last.Text + " " + u.Text)
+			if u.EndMs > last.EndMs {
+				last.EndMs = u.EndMs
+			}
+			if last.Confidence > 0 && u.Confidence > 0 {
+				last.Confidence = (last.Confidence + u.Confidence) / 2
+			}
+			continue
+		}
+		turns = append(turns, u)
+	}
+	return turns
+}
+
+// embeddingDistance is the root mean square difference of two embeddings
+func embeddingDistance(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return math.MaxFloat64
+	}
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum / float64(len(a)))
+}
+
+func weightedMean(a []float64, weightA int64, b []float64, weightB int64) []float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		if len(a) == 0 {
+			return b
+		}
+		return a
+	}
+	wa, wb := float64(weightA), float64(weightB)
+	if wa+wb <= 0 {
+		wa, wb = 1, 1
+	}
+	mean := make([]float64, len(a))
+	for i := range a {
+		mean[i] = (a[i]*wa + b[i]*wb) / (wa + wb)
+	}
+	return mean
+}
+
+// melFilterBank returns triangular filters over the power spectrum bins, evenly spaced on the mel scale
+func melFilterBank(fftSize, sampleRate int) [][]float64 {
+	toMel := func(hz float64) float64 { return 2595 * math.Log10(1+hz/700) }
+	toHz := func(mel float64) float64 { return 700 * (math.Pow(10, mel/2595) - 1) }
+
+	low, high := toMel(80), toMel(math.Min(7600, float64(sampleRate)/2))
+	bins := make([]int, diarizationMelBands+2)
+	for i := range bins {
+		hz := toHz(low + (high-low)*float64(i)/float64(diarizationMelBands+1))
+		bins[i] = int(math.Floor(float64(fftSize+1) * hz / float64(sampleRate)))
+	}
+
+	filters := make([][]float64, diarizationMelBands)
+	for b := range filters {
+		filter := make([]float64, fftSize/2+1)
+		left, center, right := bins[b], bins[b+1], bins[b+2]
+		for i := left; i < center && i < len(filter); i++ {
+			filter[i] = float64(i-left) / float64(center-left)
+		}
+		for i := center; i < right && i < len(filter); i++ {
+			filter[i] = float64(right-i) / float64(right-center)
+		}
+		filters[b] = filter
+	}
+	return filters
+}
+
+// dctCepstrum returns cepstral coefficients c1..c12 of the log mel band energies (orthonormal DCT-II)
+func dctCepstrum(bands []float64) []float64 {
+	scale := math.Sqrt(2 / float64(len(bands)))
+	cepstrum := make([]float64, diarizationCepstra)
+	for k := 1; k <= diarizationCepstra; k++ {
+		var sum float64
+		for n, v := range bands {
+			sum += v * math.Cos(math.Pi*float64(k)*(float64(n)+0.5)/float64(len(bands)))
+		}
+		cepstrum[k-1] = scale * sum
+	}
+	return cepstrum
+}
+
+// fft computes the discrete Fourier transform in place; len(x) must be a power of two
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := x[start+k], w*x[start+k+size/2]
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sensio/domain/models/whisper/dtos"
+	"testing"
+)
+
+// voice is a synthetic speaker: a harmonic tone shaped by two formants
+type voice struct {
+	pitch    float64
+	formants [2]float64
+}
+
+var (
+	lowVoice  = voice{pitch: 110, formants: [2]float64{600, 1100}}
+	highVoice = voice{pitch: 230, formants: [2]float64{350, 2600}}
+)
+
+func synthesize(v voice, sampleRate int, durationMs int64, rng *rand.Rand) []float64 {
+	n := int(durationMs) * sampleRate / 1000
+	samples := make([]float64, n)
+	for h := 1; float64(h)*v.pitch < float64(sampleRate)/2; h++ {
+		freq := float64(h) * v.pitch
+		var gain float64
+		for _, f := range v.formants {
+			gain += math.Exp(-math.Pow((freq-f)/200, 2))
+		}
+		phase := rng.Float64() * 2 * math.Pi
+		for i := range samples {
+			samples[i] += 0.2 * gain * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)+phase)
+		}
+	}
+	for i := range samples {
+		samples[i] += 0.01 * rng.NormFloat64()
+	}
+	return samples
+}
+
+func writeTestWav(t *testing.T, samples []float64, sampleRate int) string {
+	t.Helper()
+	data := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(int16(math.Max(-1, math.Min(1, s))*32767)))
+	}
+	header := make([]byte, 44)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(36+len(data)))
+	copy(header[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], 1)
+	binary.LittleEndian.PutUint16(header[22:], 1)
+	binary.LittleEndian.PutUint32(header[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(2*sampleRate))
+	binary.LittleEndian.PutUint16(header[32:], 2)
+	binary.LittleEndian.PutUint16(header[34:], 16)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(len(data)))
+
+	path := filepath.Join(t.TempDir(), "voices.wav")
+	if err := os.WriteFile(path, append(header, data...), 0644); err != nil {
+		t.Fatalf("failed to write WAV: %v", err)
+	}
+	return path
+}
+
+// conversation renders the turns one after another and returns utterances timed on the audio
+func conversation(t *testing.T, turns []voice, turnMs int64) ([]float64, int, []dtos.Utterance) {
+	t.Helper()
+	const sampleRate = 16000
+	rng := rand.New(rand.NewSource(1))
+	var audio []float64
+	utterances := make([]dtos.Utterance, len(turns))
+	for i, v := range turns {
+		audio = append(audio, synthesize(v, sampleRate, turnMs, rng)...)
+		utterances[i] = dtos.Utterance{StartMs: int64(i) * turnMs, EndMs: int64(i+1) * turnMs, Text: "turn"}
+	}
+
+	samples, rate, err := ReadWavPCM16(writeTestWav(t, audio, sampleRate))
+	if err != nil {
+		t.Fatalf("ReadWavPCM16 failed: %v", err)
+	}
+	if rate != sampleRate || len(samples) != len(audio) {
+		t.Fatalf("ReadWavPCM16 = %d samples at %d Hz; want %d at %d Hz", len(samples), rate, len(audio), sampleRate)
+	}
+	return samples, rate, utterances
+}
+
+func TestClusterSpeakers_SeparatesVoices(t *testing.T) {
+	turns := []voice{lowVoice, highVoice, lowVoice, lowVoice, highVoice, lowVoice}
+	samples, rate, utterances := conversation(t, turns, 1500)
+	for i := range utterances {
+		utterances[i].SpeakerEmbedding = SpeakerEmbedding(samples, rate, utterances[i].StartMs, utterances[i].EndMs)
+		if utterances[i].SpeakerEmbedding == nil {
+			t.Fatalf("utterance %d has no embedding", i)
+		}
+	}
+
+	ClusterSpeakers(utterances, 8)
+	want := []string{"Speaker 1", "Speaker 2", "Speaker 1", "Speaker 1", "Speaker 2", "Speaker 1"}
+	for i, u := range utterances {
+		if u.SpeakerLabel != want[i] {
+			t.Fatalf("utterance %d labelled %q; want %q", i, u.SpeakerLabel, want[i])
+		}
+	}
+
+	merged := MergeSpeakerTurns(utterances)
+	if len(merged) != 5 {
+		t.Fatalf("MergeSpeakerTurns returned %d turns; want 5", len(merged))
+	}
+	if merged[2].StartMs != 3000 || merged[2].EndMs != 6000 || merged[2].Text != "turn turn" {
+		t.Fatalf("unexpected merged turn: %+v", merged[2])
+	}
+
+	ClusterSpeakers(utterances, 1)
+	for i, u := range utterances {
+		if u.SpeakerLabel != "Speaker 1" {
+			t.Fatalf("utterance %d labelled %q with maxSpeakers=1", i, u.SpeakerLabel)
+		}
+	}
+}
+
+func TestSpeakerEmbedding_Silence(t *testing.T) {
+	silence := make([]float64, 16000)
+	if embedding := SpeakerEmbedding(silence, 16000, 0, 1000); embedding != nil {
+		t.Fatalf("expected no embedding for silence, got %d values", len(embedding))
+	}
+}
+
+func TestClusterSpeakers_InheritsLabelWithoutEmbedding(t *testing.T) {
+	utterances := []dtos.Utterance{
+		{SpeakerEmbedding: []float64{1, 0}},
+		{},
+		{SpeakerEmbedding: []float64{-1, 0}},
+	}
+	ClusterSpeakers(utterances, 0)
+	if utterances[1].SpeakerLabel != utterances[0].SpeakerLabel {
+		t.Fatalf("utterance without embedding labelled %q; want %q", utterances[1].SpeakerLabel, utterances[0].SpeakerLabel)
+	}
+}
+
+func TestMergeAlignedSegments_OffsetsAndOverlap(t *testing.T) {
+	// Two 10s segments overlapping on 8s-10s; the overlap is split at 9s
+	audioSegments := []AudioSegment{
+		{Index: 0, StartMs: 0, EndMs: 10000},
+		{Index: 1, StartMs: 8000, EndMs: 18000},
+	}
+	perSegment := [][]dtos.TranscriptSegment{
+		{
+			{Text: "first", StartMs: 0, EndMs: 4000},
+			{Text: "shared", StartMs: 7600, EndMs: 9600, Utterances: []dtos.Utterance{{StartMs: 7600, EndMs: 9600}}},
+			{Text: "tail", StartMs: 9400, EndMs: 10000},
+		},
+		{
+			{Text: "shared", StartMs: 0, EndMs: 1600},
+			{Text: "tail", StartMs: 1400, EndMs: 2000},
+			{Text: "last", StartMs: 3000, EndMs: 6000, Utterances: []dtos.Utterance{{StartMs: 3000, EndMs: 6000}}},
+		},
+	}
+
+	merged := MergeAlignedSegments(audioSegments, perSegment)
+	want := []struct {
+		text       string
+		start, end int64
+	}{
+		{"first", 0, 4000},
+		{"shared", 7600, 9600},
+		{"tail", 9400, 10000},
+		{"last", 11000, 14000},
+	}
+	if len(merged) != len(want) {
+		t.Fatalf("merged %d pieces; want %d: %+v", len(merged), len(want), merged)
+	}
+	for i, w := range want {
+		if merged[i].Index != i || merged[i].Text != w.text || merged[i].StartMs != w.start || merged[i].EndMs != w.end {
+			t.Fatalf("piece %d = %+v; want %s [%d, %d]", i, merged[i], w.text, w.start, w.end)
+		}
+	}
+	if u := merged[3].Utterances[0]; u.StartMs != 11000 || u.EndMs != 14000 {
+		t.Fatalf("nested utterance not shifted: %+v", u)
+	}
+	if perSegment[1][2].Utterances[0].StartMs != 3000 {
+		t.Fatal("MergeAlignedSegments modified its input")
+	}
+}
+
+func TestMergeAlignedResults_ClustersAcrossSegments(t *testing.T) {
+	// The high voice opens the second segment, so per-segment labels would disagree
+	turns := []voice{lowVoice, highVoice, highVoice, lowVoice}
+	samples, rate, utterances := conversation(t, turns, 2000)
+	audioSegments := []AudioSegment{
+		{Index: 0, StartMs: 0, EndMs: 4000},
+		{Index: 1, StartMs: 4000, EndMs: 8000},
+	}
+	results := make([]*dtos.WhisperResult, len(audioSegments))
+	for i, seg := range audioSegments {
+		result := &dtos.WhisperResult{DetectedLanguage: "en", Source: "Whisper.cpp", AlignedTimestamps: true}
+		for _, u := range utterances[2*i : 2*i+2] {
+			u.SpeakerEmbedding = SpeakerEmbedding(samples, rate, u.StartMs, u.EndMs)
+			u.StartMs -= seg.StartMs
+			u.EndMs -= seg.StartMs
+			result.Segments = append(result.Segments, dtos.TranscriptSegment{
+				StartMs: u.StartMs, EndMs: u.EndMs, Text: u.Text, Utterances: []dtos.Utterance{u},
+			})
+		}
+		results[i] = result
+	}
+
+	merged := MergeAlignedResults(audioSegments, results, true, 8)
+	if !merged.Diarized || !merged.AlignedTimestamps || merged.TranscriptFormat != dtos.TranscriptFormatUtteranceList {
+		t.Fatalf("unexpected result flags: %+v", merged)
+	}
+	if merged.Transcription != "turn turn turn turn" || merged.DetectedLanguage != "en" {
+		t.Fatalf("unexpected transcription %q (%s)", merged.Transcription, merged.DetectedLanguage)
+	}
+	if len(merged.Utterances) != 3 {
+		t.Fatalf("got %d speaker turns; want 3: %+v", len(merged.Utterances), merged.Utterances)
+	}
+	wantTurns := []struct {
+		label      string
+		start, end int64
+	}{
+		{"Speaker 1", 0, 2000},
+		{"Speaker 2", 2000, 6000},
+		{"Speaker 1", 6000, 8000},
+	}
+	for i, w := range wantTurns {
+		u := merged.Utterances[i]
+		if u.SpeakerLabel != w.label || u.StartMs != w.start || u.EndMs != w.end {
+			t.Fatalf("turn %d = %s [%d, %d]; want %s [%d, %d]", i, u.SpeakerLabel, u.StartMs, u.EndMs, w.label, w.start, w.end)
+		}
+	}
+	if merged.Segments[2].Utterances[0].SpeakerLabel != "Speaker 2" {
+		t.Fatalf("segment labels not updated: %+v", merged.Segments[2].Utterances)
+	}
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"math"
+	"regexp"
+	"sensio/domain/models/whisper/dtos"
+	"sort"
+	"strings"
+)
+
+// introSearchChars limits the search for a self-introduction to the opening of each turn
+const introSearchChars = 160
+
+// introName captures up to three capitalized words, e.g. "Budi" or "Budi Santoso"
+const introName = `(\p{Lu}[\p{L}'-]*(?:\s+\p{Lu}[\p{L}'-]*){0,2})`
+
+var (
+	// Explicit introductions name the speaker even when the name is not a listed participant
+	strongIntroPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i:\bmy name is|\bmy name's|\bcall me|\bnama saya|\bnamaku|\bperkenalkan,?\s+(?:nama\s+)?(?:saya|aku))\s+` + introName),
+	}
+	// Looser phrasings ("I'm fine", "saya setuju") are only trusted when they name a listed participant
+	weakIntroPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i:\bi['’]m|\bi am|\bthis is|\bsaya|\baku)\s+` + introName),
+		regexp.MustCompile(`^` + introName + `\s+(?i:here)\b`),
+	}
+)
+
+// SpeakerNameSuggestion is a participant name proposed for a diarized speaker label
+type SpeakerNameSuggestion struct {
+	SpeakerLabel string
+	Name         string
+	Confidence   float64
+	Evidence     string // The introduction the name was found in
+}
+
+// SuggestSpeakerNames proposes a name for speakers who introduce themselves, such as "my name is Budi"
+// or "nama saya Budi". Names are matched against the listed participants, whose spelling is preferred;
+// each label and each name is suggested at most once, keeping the most confident introduction.
+func SuggestSpeakerNames(utterances []dtos.Utterance, participants []string) []SpeakerNameSuggestion {
+	var candidates []SpeakerNameSuggestion
+	for _, u := range utterances {
+		if u.SpeakerLabel == "" {
+			continue
+		}
+		if c, ok := findIntroduction(openingOf(u.Text), participants); ok && !strings.EqualFold(c.Name, u.SpeakerLabel) {
+			c.SpeakerLabel = u.SpeakerLabel
+			candidates = append(candidates, c)
+		}
+	}
+
+	// Most confident first; the stable sort keeps the earliest introduction on ties
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+	labels := make(map[string]bool)
+	names := make(map[string]bool)
+	var suggestions []SpeakerNameSuggestion
+	for _, c := range candidates {
+		key := strings.ToLower(c.Name)
+		if labels[c.SpeakerLabel] || names[key] {
+			continue
+		}
+		labels[c.SpeakerLabel] = true
+		names[key] = true
+		suggestions = append(suggestions, c)
+	}
+
+	order := speakerOrder(utterances)
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return order[suggestions[i].SpeakerLabel] < order[suggestions[j].SpeakerLabel]
+	})
+	return suggestions
+}
+
+// openingOf returns about the first introSearchChars bytes of text, cut at a rune boundary
+func openingOf(text string) string {
+	if len(text) <= introSearchChars {
+		return text
+	}
+	cut := 0
+	for i := range text {
+		if i > introSearchChars {
+			break
+		}
+		cut = i
+	}
+	return text[:cut]
+}
+
+// introducedName trims words captured after the name, as "I" in "I'm Budi I lead finance"
+func introducedName(captured string) string {
+	words := strings.Fields(captured)
+	for len(words) > 1 && words[len(words)-1] == "I" {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// findIntroduction returns the name a speaker introduces themselves with in text
+func findIntroduction(text string, participants []string) (SpeakerNameSuggestion, bool) {
+	for _, pattern := range strongIntroPatterns {
+		if m := pattern.FindStringSubmatch(text); m != nil {
+			name := introducedName(m[1])
+			if participant, ok := MatchParticipant(name, participants); ok {
+				return SpeakerNameSuggestion{Name: participant, Confidence: 0.95, Evidence: m[0]}, true
+			}
+			confidence := 0.8
+			if len(participants) > 0 {
+				// Introduced by a name nobody listed: a guest, or a misheard name
+				confidence = 0.5
+			}
+			return SpeakerNameSuggestion{Name: name, Confidence: confidence, Evidence: m[0]}, true
+		}
+	}
+	for _, pattern := range weakIntroPatterns {
+		if m := pattern.FindStringSubmatch(text); m != nil {
+			if participant, ok := MatchParticipant(introducedName(m[1]), participants); ok {
+				return SpeakerNameSuggestion{Name: participant, Confidence: 0.7, Evidence: m[0]}, true
+			}
+		}
+	}
+	return SpeakerNameSuggestion{}, false
+}
+
+// MatchParticipant finds the participant a spoken name refers to: the same full name, or the same
+// first name when only one participant has it
+func MatchParticipant(name string, participants []string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", false
+	}
+	firstName := strings.Fields(name)[0]
+	var match string
+	matches := 0
+	for _, p := range participants {
+		candidate := strings.ToLower(strings.TrimSpace(p))
+		if candidate == "" {
+			continue
+		}
+		if candidate == name {
+			return strings.TrimSpace(p), true
+		}
+		if strings.Fields(candidate)[0] == firstName {
+			match = strings.TrimSpace(p)
+			matches++
+		}
+	}
+	return match, matches == 1
+}
+
+// RenameSpeakers returns a copy of the utterances with their speaker labels replaced by the mapped names
+func RenameSpeakers(utterances []dtos.Utterance, names map[string]string) []dtos.Utterance {
+	if len(utterances) == 0 {
+		return utterances
+	}
+	renamed := make([]dtos.Utterance, len(utterances))
+	for i, u := range utterances {
+		renamed[i] = u
+		if name := names[u.SpeakerLabel]; name != "" {
+			renamed[i].SpeakerLabel = name
+		}
+	}
+	return renamed
+}
+
+// ReplaceSpeakerLabels replaces every whole-word occurrence of a mapped speaker label in text, such as
+// "Speaker 1" in a summary, with its name. Labels are replaced in a single pass, so swapping two labels
+// is safe, and "Speaker 1" never matches inside "Speaker 10".
+func ReplaceSpeakerLabels(text string, names map[string]string) string {
+	if text == "" || len(names) == 0 {
+		return text
+	}
+	labels := make([]string, 0, len(names))
+	for label, name := range names {
+		if label != "" && name != "" {
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return text
+	}
+	// Longest first so a label is never shadowed by one of its prefixes
+	sort.Slice(labels, func(i, j int) bool { return len(labels[i]) > len(labels[j]) })
+	quoted := make([]string, len(labels))
+	for i, label := range labels {
+		quoted[i] = regexp.QuoteMeta(label)
+	}
+	pattern := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		for label, name := range names {
+			if strings.EqualFold(label, match) && name != "" {
+				return name
+			}
+		}
+		return match
+	})
+}
+
+// BuildSpeakerStats sums the talk time, turns and words of each speaker, in order of first appearance.
+// It returns nil when no utterance has a speaker label.
+func BuildSpeakerStats(utterances []dtos.Utterance) []dtos.SpeakerStats {
+	var stats []dtos.SpeakerStats
+	index := make(map[string]int)
+	var total int64
+	for _, u := range utterances {
+		if u.SpeakerLabel == "" {
+			continue
+		}
+		i, ok := index[u.SpeakerLabel]
+		if !ok {
+			i = len(stats)
+			index[u.SpeakerLabel] = i
+			stats = append(stats, dtos.SpeakerStats{SpeakerLabel: u.SpeakerLabel})
+		}
+		if d := u.EndMs - u.StartMs; d > 0 {
+			stats[i].TalkTimeMs += d
+			total += d
+		}
+		stats[i].Turns++
+		stats[i].Words += len(strings.Fields(u.Text))
+	}
+	if total > 0 {
+		for i := range stats {
+			stats[i].TalkTimePercent = math.Round(1000*float64(stats[i].TalkTimeMs)/float64(total)) / 10
+		}
+	}
+	return stats
+}
+
+// speakerOrder ranks speaker labels by their first turn
+func speakerOrder(utterances []dtos.Utterance) map[string]int {
+	order := make(map[string]int)
+	for _, u := range utterances {
+		if _, ok := order[u.SpeakerLabel]; !ok {
+			order[u.SpeakerLabel] = len(order)
+		}
+	}
+	return order
+}
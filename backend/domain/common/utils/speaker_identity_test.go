@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"sensio/domain/models/whisper/dtos"
+	"testing"
+)
+
+func TestSuggestSpeakerNames(t *testing.T) {
+	utterances := []dtos.Utterance{
+		{SpeakerLabel: "Speaker 1", Text: "Selamat pagi semuanya, nama saya Budi dari tim finance."},
+		{SpeakerLabel: "Speaker 2", Text: "Saya setuju. Hi, I'm Alice I lead the product team."},
+		{SpeakerLabel: "Speaker 3", Text: "This is really important, let's move on."},
+		{SpeakerLabel: "Speaker 3", Text: "My name is Charlie by the way."},
+		{SpeakerLabel: "Speaker 4", Text: "And I'm Budi too, I think."},
+	}
+	participants := []string{"Budi Santoso", "Alice Wong"}
+
+	suggestions := SuggestSpeakerNames(utterances, participants)
+	want := []SpeakerNameSuggestion{
+		{SpeakerLabel: "Speaker 1", Name: "Budi Santoso", Confidence: 0.95},
+		{SpeakerLabel: "Speaker 2", Name: "Alice Wong", Confidence: 0.7},
+		{SpeakerLabel: "Speaker 3", Name: "Charlie", Confidence: 0.5},
+	}
+	if len(suggestions) != len(want) {
+		t.Fatalf("got %d suggestions; want %d: %+v", len(suggestions), len(want), suggestions)
+	}
+	for i, w := range want {
+		s := suggestions[i]
+		if s.SpeakerLabel != w.SpeakerLabel || s.Name != w.Name || s.Confidence != w.Confidence {
+			t.Fatalf("suggestion %d = %+v; want %+v", i, s, w)
+		}
+	}
+	if suggestions[0].Evidence != "nama saya Budi" {
+		t.Fatalf("unexpected evidence %q", suggestions[0].Evidence)
+	}
+
+	// Without a participant list only explicit introductions are trusted
+	suggestions = SuggestSpeakerNames(utterances, nil)
+	if len(suggestions) != 2 || suggestions[0].Name != "Budi" || suggestions[1].Name != "Charlie" || suggestions[1].Confidence != 0.8 {
+		t.Fatalf("unexpected suggestions without participants: %+v", suggestions)
+	}
+}
+
+func TestMatchParticipant_AmbiguousFirstName(t *testing.T) {
+	if name, ok := MatchParticipant("Budi", []string{"Budi Santoso", "Budi Hartono"}); ok {
+		t.Fatalf("ambiguous first name matched %q", name)
+	}
+	if name, ok := MatchParticipant("budi hartono", []string{"Budi Santoso", "Budi Hartono"}); !ok || name != "Budi Hartono" {
+		t.Fatalf("full name matched %q, %v", name, ok)
+	}
+}
+
+func TestReplaceSpeakerLabels(t *testing.T) {
+	names := map[string]string{"Speaker 1": "Alice", "Speaker 2": "Speaker 1", "Speaker 3": ""}
+	got := ReplaceSpeakerLabels("Speaker 1 asked speaker 2; Speaker 10 and Speaker 3 stayed quiet.", names)
+	want := "Alice asked Speaker 1; Speaker 10 and Speaker 3 stayed quiet."
+	if got != want {
+		t.Fatalf("ReplaceSpeakerLabels = %q; want %q", got, want)
+	}
+
+	renamed := RenameSpeakers([]dtos.Utterance{{SpeakerLabel: "Speaker 1"}, {SpeakerLabel: "Speaker 3"}}, names)
+	if renamed[0].SpeakerLabel != "Alice" || renamed[1].SpeakerLabel != "Speaker 3" {
+		t.Fatalf("unexpected renamed utterances: %+v", renamed)
+	}
+}
+
+func TestBuildSpeakerStats(t *testing.T) {
+	stats := BuildSpeakerStats([]dtos.Utterance{
+		{SpeakerLabel: "Speaker 1", StartMs: 0, EndMs: 3000, Text: "one two three"},
+		{SpeakerLabel: "Speaker 2", StartMs: 3000, EndMs: 4000, Text: "four"},
+		{SpeakerLabel: "Speaker 1", StartMs: 4000, EndMs: 6000, Text: "five six"},
+		{Text: "unlabelled"},
+	})
+	want := []dtos.SpeakerStats{
+		{SpeakerLabel: "Speaker 1", TalkTimeMs: 5000, TalkTimePercent: 83.3, Turns: 2, Words: 5},
+		{SpeakerLabel: "Speaker 2", TalkTimeMs: 1000, TalkTimePercent: 16.7, Turns: 1, Words: 1},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("got %d speakers; want %d", len(stats), len(want))
+	}
+	for i, w := range want {
+		if stats[i] != w {
+			t.Fatalf("stats %d = %+v; want %+v", i, stats[i], w)
+		}
+	}
+	if BuildSpeakerStats([]dtos.Utterance{{Text: "plain"}}) != nil {
+		t.Fatal("expected no stats without speaker labels")
+	}
+}
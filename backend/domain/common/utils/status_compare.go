@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompareStatusValue compares a stored device status value (always persisted as a string)
+// with a JSON-decoded expected value. Supported operators: eq, neq, gt, gte, lt, lte, contains.
+// Numbers and booleans are compared by value, everything else as trimmed strings.
+func CompareStatusValue(actual, operator string, expected interface{}) (bool, error) {
+	actual = strings.Trim(strings.TrimSpace(actual), `"`)
+
+	switch operator {
+	case "eq", "neq":
+		equal := statusValuesEqual(actual, expected)
+		if operator == "eq" {
+			return equal, nil
+		}
+		return !equal, nil
+	case "gt", "gte", "lt", "lte":
+		a, ok := ToFloat(actual)
+		if !ok {
+			return false, fmt.Errorf("current value %q is not numeric", actual)
+		}
+		e, ok := ToFloat(expected)
+		if !ok {
+			return false, fmt.Errorf("expected value %v is not numeric", expected)
+		}
+		switch operator {
+		case "gt":
+			return a > e, nil
+		case "gte":
+			return a >= e, nil
+		case "lt":
+			return a < e, nil
+		default:
+			return a <= e, nil
+		}
+	case "contains":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(fmt.Sprintf("%v", expected))), nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q", operator)
+	}
+}
+
+func statusValuesEqual(actual string, expected interface{}) bool {
+	switch e := expected.(type) {
+	case bool:
+		b, err := strconv.ParseBool(actual)
+		return err == nil && b == e
+	case float64, float32, int, int64:
+		a, ok := ToFloat(actual)
+		ev, _ := ToFloat(e)
+		return ok && a == ev
+	case nil:
+		return actual == "" || actual == "<nil>"
+	default:
+		return actual == strings.TrimSpace(fmt.Sprintf("%v", e))
+	}
+}
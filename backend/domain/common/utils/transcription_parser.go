@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"math"
 	"regexp"
 	"sensio/domain/models/whisper/dtos"
 	"strconv"
@@ -154,6 +155,80 @@ func MergeUtterances(segments []dtos.TranscriptSegment) []dtos.Utterance {
 	return allUtterances
 }
 
+// MergeAlignedSegments merges the audio-aligned transcript pieces of split audio into one timeline.
+// perSegment[i] holds the pieces transcribed from audioSegments[i], timed relative to that segment; they are
+// shifted by the segment start. Where two segments overlap, each keeps the pieces whose midpoint falls on its
+// side of the middle of the overlap, so speech in the overlap is neither dropped nor duplicated.
+func MergeAlignedSegments(audioSegments []AudioSegment, perSegment [][]dtos.TranscriptSegment) []dtos.TranscriptSegment {
+	var merged []dtos.TranscriptSegment
+	for i, seg := range audioSegments {
+		if i >= len(perSegment) {
+			break
+		}
+		ownedFrom, ownedTo := int64(math.MinInt64), int64(math.MaxInt64)
+		if i > 0 {
+			ownedFrom = (seg.StartMs + audioSegments[i-1].EndMs) / 2
+		}
+		if i+1 < len(audioSegments) {
+			ownedTo = (audioSegments[i+1].StartMs + seg.EndMs) / 2
+		}
+
+		for _, piece := range perSegment[i] {
+			piece.StartMs += seg.StartMs
+			piece.EndMs += seg.StartMs
+			if mid := (piece.StartMs + piece.EndMs) / 2; mid < ownedFrom || mid >= ownedTo {
+				continue
+			}
+			if len(piece.Utterances) > 0 {
+				utterances := make([]dtos.Utterance, len(piece.Utterances))
+				for j, u := range piece.Utterances {
+					u.StartMs += seg.StartMs
+					u.EndMs += seg.StartMs
+					utterances[j] = u
+				}
+				piece.Utterances = utterances
+			}
+			piece.Index = len(merged)
+			merged = append(merged, piece)
+		}
+	}
+	return merged
+}
+
+// MergeAlignedResults combines the transcriptions of split audio whose providers returned audio-aligned
+// segments (see MergeAlignedSegments). With diarize, speakers are clustered across the whole recording.
+func MergeAlignedResults(audioSegments []AudioSegment, results []*dtos.WhisperResult, diarize bool, maxSpeakers int) *dtos.WhisperResult {
+	perSegment := make([][]dtos.TranscriptSegment, len(results))
+	merged := &dtos.WhisperResult{AlignedTimestamps: true}
+	for i, r := range results {
+		perSegment[i] = r.Segments
+		if merged.DetectedLanguage == "" {
+			merged.DetectedLanguage = r.DetectedLanguage
+		}
+		if merged.Source == "" {
+			merged.Source = r.Source
+		}
+	}
+
+	merged.Segments = MergeAlignedSegments(audioSegments, perSegment)
+	texts := make([]string, len(merged.Segments))
+	for i, seg := range merged.Segments {
+		texts[i] = seg.Text
+	}
+	merged.Transcription = strings.Join(texts, " ")
+
+	merged.TranscriptFormat = dtos.TranscriptFormatPlainText
+	if diarize {
+		if turns := DiarizeSegments(merged.Segments, maxSpeakers); len(turns) > 0 {
+			merged.Utterances = turns
+			merged.Diarized = true
+			merged.TranscriptFormat = dtos.TranscriptFormatUtteranceList
+			merged.ConfidenceSummary = BuildConfidenceSummary(turns, len(merged.Segments))
+		}
+	}
+	return merged
+}
+
 // MergeTranscriptions merges text from multiple segments, handling overlap regions
 // to avoid duplicate text at boundaries.
 func MergeTranscriptions(segments []dtos.TranscriptSegment) string {
@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+	doorlock_dtos "sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	"sensio/domain/doorlock/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DoorLockController exposes lock control and password management for the locks of a terminal
+type DoorLockController struct {
+	lockUC     *usecases.DoorLockUseCase
+	passwordUC *usecases.DoorLockPasswordUseCase
+	eventUC    *usecases.DoorLockEventUseCase
+}
+
+// Force Swaggo to detect DTOs
+var _ = doorlock_dtos.TemporaryPasswordRequestDTO{}
+
+func NewDoorLockController(lockUC *usecases.DoorLockUseCase, passwordUC *usecases.DoorLockPasswordUseCase, eventUC *usecases.DoorLockEventUseCase) *DoorLockController {
+	return &DoorLockController{
+		lockUC:     lockUC,
+		passwordUC: passwordUC,
+		eventUC:    eventUC,
+	}
+}
+
+// GetState handles GET /api/doorlocks/:terminal_id/:device_id/state
+// @Summary Get door lock state
+// @Description Return the lock state last recorded in the device statuses (lock_motor_state)
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Success 200 {object} dtos.StandardResponse{data=doorlock_dtos.DoorLockStateResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/state [get]
+func (c *DoorLockController) GetState(ctx *gin.Context) {
+	result, err := c.lockUC.GetState(ctx.Param("terminal_id"), ctx.Param("device_id"))
+	if err != nil {
+		respondError(ctx, "GetState", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Lock state retrieved successfully",
+		Data:    result,
+	})
+}
+
+// Lock handles POST /api/doorlocks/:terminal_id/:device_id/lock
+// @Summary Lock a door
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Success 200 {object} dtos.StandardResponse{data=doorlock_dtos.DoorLockStateResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/lock [post]
+func (c *DoorLockController) Lock(ctx *gin.Context) {
+	result, err := c.lockUC.Lock(ctx.Param("terminal_id"), ctx.Param("device_id"), ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "Lock", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Door locked successfully",
+		Data:    result,
+	})
+}
+
+// Unlock handles POST /api/doorlocks/:terminal_id/:device_id/unlock
+// @Summary Unlock a door
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Success 200 {object} dtos.StandardResponse{data=doorlock_dtos.DoorLockStateResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/unlock [post]
+func (c *DoorLockController) Unlock(ctx *gin.Context) {
+	result, err := c.lockUC.Unlock(ctx.Param("terminal_id"), ctx.Param("device_id"), ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "Unlock", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Door unlocked successfully",
+		Data:    result,
+	})
+}
+
+// CreateDynamicPassword handles POST /api/doorlocks/:terminal_id/:device_id/passwords/dynamic
+// @Summary Create a dynamic password
+// @Description Generate a one-time password valid for five minutes. Works while the lock is offline.
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Success 201 {object} dtos.StandardResponse{data=doorlock_dtos.DoorLockPasswordResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/passwords/dynamic [post]
+func (c *DoorLockController) CreateDynamicPassword(ctx *gin.Context) {
+	result, err := c.passwordUC.CreateDynamicPassword(ctx.Param("terminal_id"), ctx.Param("device_id"), ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "CreateDynamicPassword", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.StandardResponse{
+		Status:  true,
+		Message: "Dynamic password created successfully",
+		Data:    result,
+	})
+}
+
+// CreateTemporaryPassword handles POST /api/doorlocks/:terminal_id/:device_id/passwords/temporary
+// @Summary Create a temporary password
+// @Description Register a reusable password for a validity window. When the lock is offline the password is stored as pending_sync (202) and pushed once the lock is online.
+// @Tags 10. Door Locks
+// @Accept json
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Param request body doorlock_dtos.TemporaryPasswordRequestDTO true "Password definition"
+// @Success 201 {object} dtos.StandardResponse{data=doorlock_dtos.DoorLockPasswordResponseDTO}
+// @Success 202 {object} dtos.StandardResponse{data=doorlock_dtos.DoorLockPasswordResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/passwords/temporary [post]
+func (c *DoorLockController) CreateTemporaryPassword(ctx *gin.Context) {
+	var req doorlock_dtos.TemporaryPasswordRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error",
+			Details: []utils.ValidationErrorDetail{
+				{Field: "payload", Message: "Invalid request body: " + err.Error()},
+			},
+		})
+		return
+	}
+
+	result, err := c.passwordUC.CreateTemporaryPassword(ctx.Param("terminal_id"), ctx.Param("device_id"), req, ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "CreateTemporaryPassword", err)
+		return
+	}
+
+	if result.Status == string(entities.SyncStatusPending) {
+		ctx.JSON(http.StatusAccepted, dtos.StandardResponse{
+			Status:  true,
+			Message: "Lock is offline, password queued for sync",
+			Data:    result,
+		})
+		return
+	}
+	ctx.JSON(http.StatusCreated, dtos.StandardResponse{
+		Status:  true,
+		Message: "Temporary password created successfully",
+		Data:    result,
+	})
+}
+
+// ListPasswords handles GET /api/doorlocks/:terminal_id/:device_id/passwords
+// @Summary List door lock passwords
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Success 200 {object} dtos.StandardResponse{data=[]doorlock_dtos.DoorLockPasswordResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/passwords [get]
+func (c *DoorLockController) ListPasswords(ctx *gin.Context) {
+	result, err := c.passwordUC.ListPasswords(ctx.Param("terminal_id"), ctx.Param("device_id"))
+	if err != nil {
+		respondError(ctx, "ListPasswords", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Passwords retrieved successfully",
+		Data:    result,
+	})
+}
+
+// ListEvents handles GET /api/doorlocks/:terminal_id/:device_id/events
+// @Summary List door lock events
+// @Description Return the unlocks, alarms, hijack, doorbell and battery events recorded for a lock, newest first
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Param category query string false "Filter by category" Enums(unlock, state, alarm, hijack, battery, doorbell)
+// @Param limit query int false "Maximum number of events" default(50)
+// @Success 200 {object} dtos.StandardResponse{data=[]doorlock_dtos.DoorLockEventResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/events [get]
+func (c *DoorLockController) ListEvents(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	result, err := c.eventUC.ListEvents(ctx.Param("terminal_id"), ctx.Param("device_id"), ctx.Query("category"), limit)
+	if err != nil {
+		respondError(ctx, "ListEvents", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Events retrieved successfully",
+		Data:    result,
+	})
+}
+
+// SyncPasswords handles POST /api/doorlocks/:terminal_id/:device_id/passwords/sync
+// @Summary Sync pending passwords
+// @Description Immediately push the pending passwords of a lock instead of waiting for the sync worker
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Success 200 {object} dtos.StandardResponse{data=doorlock_dtos.DoorLockSyncResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/passwords/sync [post]
+func (c *DoorLockController) SyncPasswords(ctx *gin.Context) {
+	result, err := c.passwordUC.SyncPendingPasswords(ctx.Param("terminal_id"), ctx.Param("device_id"), ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "SyncPasswords", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Pending passwords synced",
+		Data:    result,
+	})
+}
+
+func respondError(ctx *gin.Context, op string, err error) {
+	var valErr *utils.ValidationError
+	if errors.As(err, &valErr) {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: valErr.Message,
+			Details: valErr.Details,
+		})
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	if errors.Is(err, usecases.ErrLockNotFound) || errors.Is(err, usecases.ErrGuestAccessNotFound) {
+		statusCode = http.StatusNotFound
+	} else {
+		utils.LogError("DoorLockController.%s: %v", op, err)
+	}
+	ctx.JSON(statusCode, dtos.StandardResponse{
+		Status:  false,
+		Message: http.StatusText(statusCode),
+	})
+}
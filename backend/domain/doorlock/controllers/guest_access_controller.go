@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+	doorlock_dtos "sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	"sensio/domain/doorlock/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GuestAccessController manages the time-windowed access codes given to guests
+type GuestAccessController struct {
+	useCase *usecases.GuestAccessUseCase
+}
+
+// Force Swaggo to detect DTOs
+var _ = doorlock_dtos.CreateGuestAccessRequestDTO{}
+
+func NewGuestAccessController(useCase *usecases.GuestAccessUseCase) *GuestAccessController {
+	return &GuestAccessController{useCase: useCase}
+}
+
+// CreateGuestAccess handles POST /api/doorlocks/:terminal_id/:device_id/guests
+// @Summary Create a guest access code
+// @Description Create a code valid from check-in to check-out. It is written to the lock ahead of time (queued while the lock is offline), emailed to the guest once on the lock and revoked at check-out.
+// @Tags 10. Door Locks
+// @Accept json
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Param request body doorlock_dtos.CreateGuestAccessRequestDTO true "Guest and stay"
+// @Success 201 {object} dtos.StandardResponse{data=doorlock_dtos.GuestAccessResponseDTO}
+// @Success 202 {object} dtos.StandardResponse{data=doorlock_dtos.GuestAccessResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/guests [post]
+func (c *GuestAccessController) CreateGuestAccess(ctx *gin.Context) {
+	var req doorlock_dtos.CreateGuestAccessRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error",
+			Details: []utils.ValidationErrorDetail{
+				{Field: "payload", Message: "Invalid request body: " + err.Error()},
+			},
+		})
+		return
+	}
+
+	result, err := c.useCase.CreateGuestAccess(ctx.Param("terminal_id"), ctx.Param("device_id"), req, ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "CreateGuestAccess", err)
+		return
+	}
+
+	if result.CodeStatus == string(entities.SyncStatusPending) {
+		ctx.JSON(http.StatusAccepted, dtos.StandardResponse{
+			Status:  true,
+			Message: "Lock is offline, guest code queued for sync",
+			Data:    result,
+		})
+		return
+	}
+	ctx.JSON(http.StatusCreated, dtos.StandardResponse{
+		Status:  true,
+		Message: "Guest access created successfully",
+		Data:    result,
+	})
+}
+
+// ListGuestAccesses handles GET /api/doorlocks/:terminal_id/:device_id/guests
+// @Summary List guest accesses
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Success 200 {object} dtos.StandardResponse{data=[]doorlock_dtos.GuestAccessResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/guests [get]
+func (c *GuestAccessController) ListGuestAccesses(ctx *gin.Context) {
+	result, err := c.useCase.ListGuestAccesses(ctx.Param("terminal_id"), ctx.Param("device_id"))
+	if err != nil {
+		respondError(ctx, "ListGuestAccesses", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Guest accesses retrieved successfully",
+		Data:    result,
+	})
+}
+
+// RevokeGuestAccess handles DELETE /api/doorlocks/:terminal_id/:device_id/guests/:guest_id
+// @Summary Revoke a guest access code
+// @Description Remove the guest's code from the lock before check-out. Returns 202 when the lock could not be reached; the revocation is retried in the background.
+// @Tags 10. Door Locks
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param device_id path string true "Lock device ID"
+// @Param guest_id path string true "Guest access ID"
+// @Success 200 {object} dtos.StandardResponse{data=doorlock_dtos.GuestAccessResponseDTO}
+// @Success 202 {object} dtos.StandardResponse{data=doorlock_dtos.GuestAccessResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/doorlocks/{terminal_id}/{device_id}/guests/{guest_id} [delete]
+func (c *GuestAccessController) RevokeGuestAccess(ctx *gin.Context) {
+	result, err := c.useCase.RevokeGuestAccess(ctx.Param("terminal_id"), ctx.Param("device_id"), ctx.Param("guest_id"), ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "RevokeGuestAccess", err)
+		return
+	}
+
+	if result.Status == string(entities.GuestAccessRevoking) {
+		ctx.JSON(http.StatusAccepted, dtos.StandardResponse{
+			Status:  true,
+			Message: "Lock is unreachable, revocation will be retried",
+			Data:    result,
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Guest access revoked successfully",
+		Data:    result,
+	})
+}
@@ -0,0 +1,99 @@
+package dtos
+
+// DoorLockStateResponseDTO represents the lock state recorded in the device statuses
+type DoorLockStateResponseDTO struct {
+	DeviceID  string `json:"device_id" example:"bf1234567890abcdef"`
+	State     string `json:"state" example:"locked"` // locked, unlocked, unknown
+	Battery   string `json:"battery,omitempty" example:"high"`
+	UpdatedAt string `json:"updated_at,omitempty" example:"2026-10-17T08:00:00Z"`
+}
+
+// TemporaryPasswordRequestDTO for POST /api/doorlocks/:terminal_id/:device_id/passwords/temporary
+type TemporaryPasswordRequestDTO struct {
+	Name            string `json:"name,omitempty" example:"Guest"`
+	DurationMinutes int    `json:"duration_minutes" example:"120"`
+	Password        string `json:"password,omitempty" example:"1234567"`                  // Generated when omitted
+	EffectiveAt     string `json:"effective_at,omitempty" example:"2026-10-17T14:00:00Z"` // RFC3339, defaults to now
+}
+
+// DoorLockPasswordResponseDTO represents a password created for a lock
+type DoorLockPasswordResponseDTO struct {
+	ID           string `json:"id"`
+	DeviceID     string `json:"device_id"`
+	Name         string `json:"name,omitempty"`
+	Type         string `json:"type" example:"temporary"` // dynamic, temporary
+	Value        string `json:"value" example:"1234567"`
+	ValidMinutes int    `json:"valid_minutes" example:"120"`
+	EffectiveAt  string `json:"effective_at"`
+	ExpireAt     string `json:"expire_at"`
+	Status       string `json:"status" example:"active"` // active, pending_sync, failed, expired
+	RetryCount   int    `json:"retry_count"`
+	LastError    string `json:"last_error,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// DoorLockSyncResponseDTO summarizes a manual pending-password sync
+type DoorLockSyncResponseDTO struct {
+	Online  bool `json:"online"`
+	Synced  int  `json:"synced"`
+	Pending int  `json:"pending"`
+	Failed  int  `json:"failed"`
+	Expired int  `json:"expired"`
+}
+
+// DoorLockEventResponseDTO represents an event recorded for a lock
+type DoorLockEventResponseDTO struct {
+	ID           string `json:"id"`
+	DeviceID     string `json:"device_id"`
+	Code         string `json:"code" example:"unlock_fingerprint"`
+	Value        string `json:"value" example:"3"`
+	Category     string `json:"category" example:"unlock"` // unlock, state, alarm, hijack, battery, doorbell
+	Severity     string `json:"severity" example:"info"`   // info, warning, critical
+	UnlockMethod string `json:"unlock_method,omitempty" example:"fingerprint"`
+	UserID       string `json:"user_id,omitempty" example:"3"`
+	AlarmType    string `json:"alarm_type,omitempty" example:"wrong_finger"`
+	Source       string `json:"source" example:"mqtt"` // api, mqtt, poll
+	Notified     bool   `json:"notified"`
+	OccurredAt   string `json:"occurred_at"`
+}
+
+// DoorLockAlertPayload is published to users/{mac}/{env}/doorlock/alert for warning and critical events
+type DoorLockAlertPayload struct {
+	EventID    string `json:"event_id"`
+	TerminalID string `json:"terminal_id"`
+	DeviceID   string `json:"device_id"`
+	DeviceName string `json:"device_name,omitempty"`
+	Category   string `json:"category"`
+	AlarmType  string `json:"alarm_type,omitempty"`
+	Severity   string `json:"severity"`
+	Value      string `json:"value"`
+	OccurredAt string `json:"occurred_at"`
+}
+
+// CreateGuestAccessRequestDTO for POST /api/doorlocks/:terminal_id/:device_id/guests
+type CreateGuestAccessRequestDTO struct {
+	GuestName  string `json:"guest_name" binding:"required" example:"Jane Doe"`
+	GuestEmail string `json:"guest_email" binding:"required" example:"jane@example.com"`
+	CheckIn    string `json:"check_in" binding:"required" example:"2026-10-20T14:00:00+07:00"`  // RFC3339
+	CheckOut   string `json:"check_out" binding:"required" example:"2026-10-22T12:00:00+07:00"` // RFC3339
+	Password   string `json:"password,omitempty" example:"4826153"`                             // Generated when omitted
+}
+
+// GuestAccessResponseDTO represents a guest's access code and its lifecycle
+type GuestAccessResponseDTO struct {
+	ID         string `json:"id"`
+	DeviceID   string `json:"device_id"`
+	RoomID     string `json:"room_id,omitempty"`
+	GuestName  string `json:"guest_name"`
+	GuestEmail string `json:"guest_email"`
+	CheckIn    string `json:"check_in"`
+	CheckOut   string `json:"check_out"`
+	Status     string `json:"status" example:"scheduled"` // scheduled, revoking, revoked, failed
+	Code       string `json:"code" example:"4826153"`
+	CodeStatus string `json:"code_status" example:"active"` // Lock password status: active, pending_sync, failed, expired, revoked
+	PasswordID string `json:"password_id"`
+	EmailSent  bool   `json:"email_sent"`
+	LastError  string `json:"last_error,omitempty"`
+	RevokedAt  string `json:"revoked_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Data point codes of Tuya smart door locks
+const (
+	CodeLockMotorState = "lock_motor_state" // true = unlocked, false = locked
+	CodeBatteryState   = "battery_state"
+	CodeResidualPower  = "residual_electricity"
+)
+
+// LockState is the physical state of a lock as last recorded in its device statuses
+type LockState string
+
+const (
+	LockStateUnknown  LockState = "unknown"
+	LockStateLocked   LockState = "locked"
+	LockStateUnlocked LockState = "unlocked"
+)
+
+// LockStateFromStatus maps the stored lock_motor_state value to a LockState
+func LockStateFromStatus(value string) LockState {
+	switch value {
+	case "true":
+		return LockStateUnlocked
+	case "false":
+		return LockStateLocked
+	default:
+		return LockStateUnknown
+	}
+}
+
+// PasswordType is the kind of door lock password
+type PasswordType string
+
+const (
+	// PasswordTypeDynamic is a one-time password computed by the Tuya cloud, valid for 5 minutes
+	PasswordTypeDynamic PasswordType = "dynamic"
+	// PasswordTypeTemporary is a reusable password with a custom validity window
+	PasswordTypeTemporary PasswordType = "temporary"
+)
+
+// SyncStatus is the synchronization state of a password with the lock
+type SyncStatus string
+
+const (
+	// SyncStatusActive means the password is created and usable on the lock
+	SyncStatusActive SyncStatus = "active"
+	// SyncStatusPending means the password is stored locally, waiting for the lock to come online
+	SyncStatusPending SyncStatus = "pending_sync"
+	// SyncStatusFailed means creating the password failed permanently
+	SyncStatusFailed SyncStatus = "failed"
+	// SyncStatusExpired means the password expired before it could be synced
+	SyncStatusExpired SyncStatus = "expired"
+	// SyncStatusRevoked means the password was removed from the lock (or never pushed) before it expired
+	SyncStatusRevoked SyncStatus = "revoked"
+)
+
+// DynamicPasswordValidity is how long a Tuya dynamic password stays valid
+const DynamicPasswordValidity = 5 * time.Minute
+
+// DoorLockPassword is a password created for a lock. Temporary passwords requested while the
+// lock is offline are kept as pending_sync and pushed by the sync worker once it is reachable.
+type DoorLockPassword struct {
+	ID               string         `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID       string         `gorm:"type:char(36);not null;index" json:"terminal_id"`
+	DeviceID         string         `gorm:"type:varchar(64);not null;index" json:"device_id"`
+	Name             string         `gorm:"type:varchar(255)" json:"name"`
+	Type             PasswordType   `gorm:"type:varchar(20);not null" json:"type"`
+	Value            string         `gorm:"type:varchar(32);not null" json:"value"`
+	ValidMinutes     int            `json:"valid_minutes"`
+	EffectiveAt      time.Time      `json:"effective_at"`
+	ExpireAt         time.Time      `gorm:"index" json:"expire_at"`
+	Status           SyncStatus     `gorm:"type:varchar(20);not null;index" json:"status"`
+	RemotePasswordID string         `gorm:"type:varchar(64)" json:"remote_password_id,omitempty"` // ID assigned by Tuya once synced
+	RetryCount       int            `json:"retry_count"`
+	LastError        string         `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// TableName specifies the table name for the DoorLockPassword model
+func (DoorLockPassword) TableName() string {
+	return "door_lock_passwords"
+}
+
+// IsExpired reports whether the password validity window has passed
+func (p DoorLockPassword) IsExpired(now time.Time) bool {
+	return !now.Before(p.ExpireAt)
+}
+
+// GeneratedPassword is a password accepted by the Tuya cloud
+type GeneratedPassword struct {
+	Value            string
+	RemotePasswordID string
+	ExpireAt         time.Time
+}
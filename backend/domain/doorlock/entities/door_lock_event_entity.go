@@ -0,0 +1,144 @@
+package entities
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event data point codes reported by Tuya smart door locks
+const (
+	CodeAlarmLock = "alarm_lock"
+	CodeHijack    = "hijack"
+	CodeDoorbell  = "doorbell"
+
+	unlockCodePrefix = "unlock_"
+)
+
+// Event categories
+const (
+	EventCategoryUnlock   = "unlock"
+	EventCategoryState    = "state"
+	EventCategoryAlarm    = "alarm"
+	EventCategoryHijack   = "hijack"
+	EventCategoryBattery  = "battery"
+	EventCategoryDoorbell = "doorbell"
+)
+
+// Event severities; warning and critical events are sent as room notifications
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// LowBatteryPercent is the residual_electricity level at or below which the battery is reported low
+const LowBatteryPercent = 20
+
+// lockCategories are the Tuya product categories of door locks
+var lockCategories = map[string]bool{
+	"ms":        true, // Residential lock
+	"jtmspro":   true, // Residential lock pro
+	"jtmsbh":    true, // Smart lock (keep alive)
+	"gyms":      true, // Business lock
+	"videolock": true, // Video lock
+}
+
+// IsLockCategory reports whether a Tuya category is a door lock
+func IsLockCategory(category string) bool {
+	return lockCategories[category]
+}
+
+// DoorLockEvent is a classified status reported by a lock
+type DoorLockEvent struct {
+	ID           string    `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID   string    `gorm:"type:char(36);not null;index" json:"terminal_id"`
+	DeviceID     string    `gorm:"type:varchar(64);not null;index:idx_door_lock_events_device_time" json:"device_id"`
+	Code         string    `gorm:"type:varchar(64);not null" json:"code"`
+	Value        string    `gorm:"type:varchar(255)" json:"value"`
+	Category     string    `gorm:"type:varchar(20);not null;index" json:"category"`
+	Severity     string    `gorm:"type:varchar(20);not null" json:"severity"`
+	UnlockMethod string    `gorm:"type:varchar(32)" json:"unlock_method,omitempty"` // fingerprint, password, card, ...
+	UserID       string    `gorm:"type:varchar(64)" json:"user_id,omitempty"`       // Lock member number that unlocked
+	AlarmType    string    `gorm:"type:varchar(64)" json:"alarm_type,omitempty"`    // wrong_finger, pry, low_battery, ...
+	Source       string    `gorm:"type:varchar(20);not null" json:"source"`         // api, mqtt, poll
+	Notified     bool      `json:"notified"`
+	OccurredAt   time.Time `gorm:"not null;index:idx_door_lock_events_device_time" json:"occurred_at"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the DoorLockEvent model
+func (DoorLockEvent) TableName() string {
+	return "door_lock_events"
+}
+
+// ShouldNotify reports whether the event is sent as a room notification
+func (e DoorLockEvent) ShouldNotify() bool {
+	return e.Severity == SeverityWarning || e.Severity == SeverityCritical
+}
+
+// IsMomentary reports whether every report of the code is a new occurrence (e.g. each unlock),
+// as opposed to a level whose repeated value means nothing happened.
+func IsMomentary(code string) bool {
+	return strings.HasPrefix(code, unlockCodePrefix)
+}
+
+// IsEdge reports whether the event marks an occurrence rather than a level: a lock reports the
+// same alarm, hijack or doorbell value again when it happens again, so repeats are not deduplicated.
+func (e DoorLockEvent) IsEdge() bool {
+	return e.Category == EventCategoryAlarm || e.Category == EventCategoryHijack || e.Category == EventCategoryDoorbell
+}
+
+// ClassifyStatus turns a lock status into an event. It returns false for codes that are not
+// lock events and for values that mean "nothing happened" (doorbell/hijack false, empty alarm).
+func ClassifyStatus(code, value string) (DoorLockEvent, bool) {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	e := DoorLockEvent{Code: code, Value: value, Severity: SeverityInfo}
+
+	switch {
+	case strings.HasPrefix(code, unlockCodePrefix):
+		e.Category = EventCategoryUnlock
+		e.UnlockMethod = strings.TrimPrefix(code, unlockCodePrefix)
+		e.UserID = value
+	case code == CodeLockMotorState:
+		e.Category = EventCategoryState
+		e.Value = string(LockStateFromStatus(value))
+	case code == CodeAlarmLock:
+		if value == "" {
+			return e, false
+		}
+		e.Category = EventCategoryAlarm
+		e.AlarmType = value
+		e.Severity = SeverityWarning
+		if value == "pry" || value == "shock" {
+			e.Severity = SeverityCritical
+		}
+	case code == CodeHijack:
+		if value != "true" {
+			return e, false
+		}
+		e.Category = EventCategoryHijack
+		e.AlarmType = "hijack"
+		e.Severity = SeverityCritical
+	case code == CodeDoorbell:
+		if value != "true" {
+			return e, false
+		}
+		e.Category = EventCategoryDoorbell
+	case code == CodeBatteryState:
+		e.Category = EventCategoryBattery
+		if value == "low" || value == "poweroff" {
+			e.AlarmType = "low_battery"
+			e.Severity = SeverityWarning
+		}
+	case code == CodeResidualPower:
+		e.Category = EventCategoryBattery
+		if percent, err := strconv.Atoi(value); err == nil && percent <= LowBatteryPercent {
+			e.AlarmType = "low_battery"
+			e.Severity = SeverityWarning
+		}
+	default:
+		return e, false
+	}
+	return e, true
+}
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GuestAccessStatus is the lifecycle state of a guest's access code
+type GuestAccessStatus string
+
+const (
+	// GuestAccessScheduled means the code is created (or queued for the lock) and waits for check-out
+	GuestAccessScheduled GuestAccessStatus = "scheduled"
+	// GuestAccessRevoking means removing the code from the lock failed and is retried
+	GuestAccessRevoking GuestAccessStatus = "revoking"
+	// GuestAccessRevoked means the code was removed at check-out or cancelled early
+	GuestAccessRevoked GuestAccessStatus = "revoked"
+	// GuestAccessFailed means the code could not be written to or removed from the lock, or could
+	// not be emailed to the guest (the code is then removed from the lock)
+	GuestAccessFailed GuestAccessStatus = "failed"
+)
+
+// GuestAccess ties a temporary lock password to a guest and their stay. The password itself
+// lives in door_lock_passwords, so offline locks are handled by the pending-password queue.
+type GuestAccess struct {
+	ID             string            `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID     string            `gorm:"type:char(36);not null;index" json:"terminal_id"`
+	DeviceID       string            `gorm:"type:varchar(64);not null;index" json:"device_id"`
+	RoomID         string            `gorm:"type:varchar(255)" json:"room_id"`
+	GuestName      string            `gorm:"type:varchar(255);not null" json:"guest_name"`
+	GuestEmail     string            `gorm:"type:varchar(255);not null" json:"guest_email"`
+	CheckInAt      time.Time         `gorm:"not null" json:"check_in_at"`
+	CheckOutAt     time.Time         `gorm:"not null;index" json:"check_out_at"`
+	PasswordID     string            `gorm:"type:char(36);not null" json:"password_id"`
+	Status         GuestAccessStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	EmailSent      bool              `json:"email_sent"`
+	EmailAttempts  int               `json:"email_attempts"`
+	RevokeAttempts int               `json:"revoke_attempts"`
+	LastError      string            `gorm:"type:text" json:"last_error,omitempty"`
+	RevokedAt      *time.Time        `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt    `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// TableName specifies the table name for the GuestAccess model
+func (GuestAccess) TableName() string {
+	return "door_lock_guest_accesses"
+}
+
+// IsOpen reports whether the access still needs work from the guest worker
+func (g GuestAccess) IsOpen() bool {
+	return g.Status == GuestAccessScheduled || g.Status == GuestAccessRevoking
+}
@@ -0,0 +1,113 @@
+package doorlock
+
+import (
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/controllers"
+	"sensio/domain/doorlock/repositories"
+	"sensio/domain/doorlock/services"
+	"sensio/domain/doorlock/usecases"
+	mail_usecases "sensio/domain/mail/usecases"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DoorLockModule struct {
+	Controller      *controllers.DoorLockController
+	GuestController *controllers.GuestAccessController
+	LockUseCase     *usecases.DoorLockUseCase
+	SyncWorker      *usecases.DoorLockSyncWorker
+	PasswordRepo    repositories.IDoorLockPasswordRepository
+	EventUseCase    *usecases.DoorLockEventUseCase
+	EventPoller     *usecases.DoorLockEventPoller // nil when DOORLOCK_EVENT_POLL_INTERVAL is "0"
+	GuestWorker     *usecases.GuestAccessWorker   // nil when DOORLOCK_GUEST_ENABLED is "false" or SMTP is not configured
+}
+
+func NewDoorLockModule(
+	db *gorm.DB,
+	badger *infrastructure.BadgerService,
+	deviceRepo device_repositories.IDeviceRepository,
+	tuyaCmd usecases.DeviceCommandExecutor,
+	tuyaDevices usecases.DeviceOnlineChecker,
+	tuyaAuth usecases.AccessTokenProvider,
+	terminalRepo usecases.TerminalLookup,
+	mqttSvc usecases.MqttPublisher,
+	mailUC mail_usecases.MailSendByMacUseCase,
+	guestMailer usecases.GuestMailer,
+) *DoorLockModule {
+	repo := repositories.NewDoorLockPasswordRepository(db)
+	eventRepo := repositories.NewDoorLockEventRepository(db)
+	guestRepo := repositories.NewGuestAccessRepository(db)
+	statusRepo := device_status_repositories.NewDeviceStatusRepository(badger)
+	lockService := services.NewTuyaDoorLockService()
+
+	cfg := utils.GetConfig()
+	interval, err := time.ParseDuration(cfg.DoorLockSyncInterval)
+	if err != nil {
+		interval = time.Minute
+	}
+
+	worker := usecases.NewDoorLockSyncWorker(repo, tuyaDevices, lockService, tuyaAuth, interval, cfg.DoorLockSyncMaxRetries)
+	lockUC := usecases.NewDoorLockUseCase(deviceRepo, statusRepo, tuyaCmd)
+	passwordUC := usecases.NewDoorLockPasswordUseCase(repo, deviceRepo, tuyaDevices, lockService, worker)
+
+	var mailer usecases.MailByMacSender
+	if cfg.DoorLockAlertEmailEnabled && mailUC != nil {
+		mailer = mailUC
+	}
+	cooldown, err := time.ParseDuration(cfg.DoorLockAlertCooldown)
+	if err != nil {
+		cooldown = 5 * time.Minute
+	}
+	notifier := usecases.NewDoorLockAlertNotifier(terminalRepo, mqttSvc, mailer, cfg.ApplicationEnvironment)
+	eventUC := usecases.NewDoorLockEventUseCase(eventRepo, deviceRepo, notifier, cooldown)
+
+	var poller *usecases.DoorLockEventPoller
+	if pollInterval, err := time.ParseDuration(cfg.DoorLockEventPollInterval); err != nil || pollInterval > 0 {
+		poller = usecases.NewDoorLockEventPoller(deviceRepo, statusRepo, tuyaDevices, tuyaAuth, pollInterval)
+	}
+
+	module := &DoorLockModule{
+		Controller:   controllers.NewDoorLockController(lockUC, passwordUC, eventUC),
+		LockUseCase:  lockUC,
+		SyncWorker:   worker,
+		PasswordRepo: repo,
+		EventUseCase: eventUC,
+		EventPoller:  poller,
+	}
+
+	// Guests get their code by email, so guest access is only offered when mail can be sent
+	if cfg.DoorLockGuestEnabled && cfg.SMTPHost != "" && guestMailer != nil {
+		guestInterval, err := time.ParseDuration(cfg.DoorLockGuestInterval)
+		if err != nil {
+			guestInterval = time.Minute
+		}
+		module.GuestWorker = usecases.NewGuestAccessWorker(guestRepo, repo, passwordUC, deviceRepo, guestMailer, tuyaAuth, guestInterval, cfg.DoorLockSyncMaxRetries)
+		guestUC := usecases.NewGuestAccessUseCase(guestRepo, repo, passwordUC, deviceRepo, terminalRepo, module.GuestWorker)
+		module.GuestController = controllers.NewGuestAccessController(guestUC)
+	}
+	return module
+}
+
+func (m *DoorLockModule) RegisterRoutes(protected *gin.RouterGroup) {
+	group := protected.Group("/api/doorlocks/:terminal_id/:device_id")
+	{
+		group.GET("/state", m.Controller.GetState)
+		group.POST("/lock", m.Controller.Lock)
+		group.POST("/unlock", m.Controller.Unlock)
+		group.GET("/passwords", m.Controller.ListPasswords)
+		group.POST("/passwords/dynamic", m.Controller.CreateDynamicPassword)
+		group.POST("/passwords/temporary", m.Controller.CreateTemporaryPassword)
+		group.POST("/passwords/sync", m.Controller.SyncPasswords)
+		group.GET("/events", m.Controller.ListEvents)
+	}
+	if m.GuestController != nil {
+		group.POST("/guests", m.GuestController.CreateGuestAccess)
+		group.GET("/guests", m.GuestController.ListGuestAccesses)
+		group.DELETE("/guests/:guest_id", m.GuestController.RevokeGuestAccess)
+	}
+}
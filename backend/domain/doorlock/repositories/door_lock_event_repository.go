@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"sensio/domain/doorlock/entities"
+
+	"gorm.io/gorm"
+)
+
+// IDoorLockEventRepository defines the interface for door lock event storage operations
+type IDoorLockEventRepository interface {
+	Save(event *entities.DoorLockEvent) error
+	GetLatest(deviceID, code string) (*entities.DoorLockEvent, error)
+	GetByDeviceID(terminalID, deviceID, category string, limit int) ([]entities.DoorLockEvent, error)
+}
+
+// DoorLockEventRepository handles persistent storage of door lock events using GORM
+type DoorLockEventRepository struct {
+	db *gorm.DB
+}
+
+// NewDoorLockEventRepository creates a new instance of DoorLockEventRepository
+func NewDoorLockEventRepository(db *gorm.DB) *DoorLockEventRepository {
+	return &DoorLockEventRepository{db: db}
+}
+
+// Save persists an event to the database (Upsert)
+func (r *DoorLockEventRepository) Save(event *entities.DoorLockEvent) error {
+	return r.db.Save(event).Error
+}
+
+// GetLatest retrieves the most recent event recorded for a code of a lock
+func (r *DoorLockEventRepository) GetLatest(deviceID, code string) (*entities.DoorLockEvent, error) {
+	var event entities.DoorLockEvent
+	if err := r.db.Where("device_id = ? AND code = ?", deviceID, code).Order("occurred_at DESC").First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// GetByDeviceID retrieves the latest events of a lock of the terminal, newest first.
+// An empty category returns every category.
+func (r *DoorLockEventRepository) GetByDeviceID(terminalID, deviceID, category string, limit int) ([]entities.DoorLockEvent, error) {
+	var events []entities.DoorLockEvent
+	query := r.db.Where("terminal_id = ? AND device_id = ?", terminalID, deviceID)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	err := query.Order("occurred_at DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"sensio/domain/doorlock/entities"
+
+	"gorm.io/gorm"
+)
+
+// IDoorLockPasswordRepository defines the interface for door lock password storage operations
+type IDoorLockPasswordRepository interface {
+	Save(password *entities.DoorLockPassword) error
+	GetByID(terminalID, id string) (*entities.DoorLockPassword, error)
+	GetByDeviceID(terminalID, deviceID string) ([]entities.DoorLockPassword, error)
+	GetPending() ([]entities.DoorLockPassword, error)
+	GetPendingByDeviceID(deviceID string) ([]entities.DoorLockPassword, error)
+}
+
+// DoorLockPasswordRepository handles persistent storage of door lock passwords using GORM
+type DoorLockPasswordRepository struct {
+	db *gorm.DB
+}
+
+// NewDoorLockPasswordRepository creates a new instance of DoorLockPasswordRepository
+func NewDoorLockPasswordRepository(db *gorm.DB) *DoorLockPasswordRepository {
+	return &DoorLockPasswordRepository{db: db}
+}
+
+// Save persists a password to the database (Upsert)
+func (r *DoorLockPasswordRepository) Save(password *entities.DoorLockPassword) error {
+	return r.db.Save(password).Error
+}
+
+// GetByID retrieves a password by its ID and TerminalID
+func (r *DoorLockPasswordRepository) GetByID(terminalID, id string) (*entities.DoorLockPassword, error) {
+	var password entities.DoorLockPassword
+	if err := r.db.Where("id = ? AND terminal_id = ?", id, terminalID).First(&password).Error; err != nil {
+		return nil, err
+	}
+	return &password, nil
+}
+
+// GetByDeviceID retrieves the passwords created for a lock of the terminal, newest first
+func (r *DoorLockPasswordRepository) GetByDeviceID(terminalID, deviceID string) ([]entities.DoorLockPassword, error) {
+	var passwords []entities.DoorLockPassword
+	err := r.db.Where("terminal_id = ? AND device_id = ?", terminalID, deviceID).
+		Order("created_at DESC").
+		Find(&passwords).Error
+	return passwords, err
+}
+
+// GetPending retrieves every password waiting to be synced, oldest first
+func (r *DoorLockPasswordRepository) GetPending() ([]entities.DoorLockPassword, error) {
+	var passwords []entities.DoorLockPassword
+	err := r.db.Where("status = ?", entities.SyncStatusPending).Order("created_at").Find(&passwords).Error
+	return passwords, err
+}
+
+// GetPendingByDeviceID retrieves the passwords of a lock waiting to be synced, oldest first
+func (r *DoorLockPasswordRepository) GetPendingByDeviceID(deviceID string) ([]entities.DoorLockPassword, error) {
+	var passwords []entities.DoorLockPassword
+	err := r.db.Where("device_id = ? AND status = ?", deviceID, entities.SyncStatusPending).Order("created_at").Find(&passwords).Error
+	return passwords, err
+}
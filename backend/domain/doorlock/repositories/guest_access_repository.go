@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"sensio/domain/doorlock/entities"
+
+	"gorm.io/gorm"
+)
+
+// IGuestAccessRepository defines the interface for guest access storage operations
+type IGuestAccessRepository interface {
+	Save(guest *entities.GuestAccess) error
+	GetByID(terminalID, id string) (*entities.GuestAccess, error)
+	GetByDeviceID(terminalID, deviceID string) ([]entities.GuestAccess, error)
+	GetOpen() ([]entities.GuestAccess, error)
+}
+
+// GuestAccessRepository handles persistent storage of guest accesses using GORM
+type GuestAccessRepository struct {
+	db *gorm.DB
+}
+
+// NewGuestAccessRepository creates a new instance of GuestAccessRepository
+func NewGuestAccessRepository(db *gorm.DB) *GuestAccessRepository {
+	return &GuestAccessRepository{db: db}
+}
+
+// Save persists a guest access to the database (Upsert)
+func (r *GuestAccessRepository) Save(guest *entities.GuestAccess) error {
+	return r.db.Save(guest).Error
+}
+
+// GetByID retrieves a guest access by its ID and TerminalID
+func (r *GuestAccessRepository) GetByID(terminalID, id string) (*entities.GuestAccess, error) {
+	var guest entities.GuestAccess
+	if err := r.db.Where("id = ? AND terminal_id = ?", id, terminalID).First(&guest).Error; err != nil {
+		return nil, err
+	}
+	return &guest, nil
+}
+
+// GetByDeviceID retrieves the guest accesses of a lock of the terminal, latest check-in first
+func (r *GuestAccessRepository) GetByDeviceID(terminalID, deviceID string) ([]entities.GuestAccess, error) {
+	var guests []entities.GuestAccess
+	err := r.db.Where("terminal_id = ? AND device_id = ?", terminalID, deviceID).
+		Order("check_in_at DESC").
+		Find(&guests).Error
+	return guests, err
+}
+
+// GetOpen retrieves every guest access still scheduled or being revoked, earliest check-out first
+func (r *GuestAccessRepository) GetOpen() ([]entities.GuestAccess, error) {
+	var guests []entities.GuestAccess
+	err := r.db.Where("status IN ?", []entities.GuestAccessStatus{entities.GuestAccessScheduled, entities.GuestAccessRevoking}).
+		Order("check_out_at").
+		Find(&guests).Error
+	return guests, err
+}
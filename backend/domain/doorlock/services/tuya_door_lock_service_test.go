@@ -0,0 +1,38 @@
+package services
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEncryptTicketPassword(t *testing.T) {
+	clientSecret := "0123456789abcdef0123456789abcdef"
+	originalKey := "fedcba9876543210"
+
+	// Tuya sends the ticket key encrypted with the client secret
+	encryptedKey, err := aesECBEncrypt([]byte(originalKey), []byte(clientSecret))
+	if err != nil {
+		t.Fatalf("aesECBEncrypt failed: %v", err)
+	}
+
+	got, err := encryptTicketPassword("1234567", hex.EncodeToString(encryptedKey), clientSecret)
+	if err != nil {
+		t.Fatalf("encryptTicketPassword failed: %v", err)
+	}
+	if got != strings.ToUpper(got) {
+		t.Errorf("expected upper-case hex, got %s", got)
+	}
+
+	raw, _ := hex.DecodeString(got)
+	plain, err := aesECBDecrypt(raw, []byte(originalKey))
+	if err != nil || string(plain) != "1234567" {
+		t.Errorf("expected the password to decrypt with the ticket key, got %q (%v)", plain, err)
+	}
+}
+
+func TestEncryptTicketPassword_InvalidKey(t *testing.T) {
+	if _, err := encryptTicketPassword("1234567", "not-hex", "0123456789abcdef0123456789abcdef"); err == nil {
+		t.Error("expected an error for a malformed ticket key")
+	}
+}
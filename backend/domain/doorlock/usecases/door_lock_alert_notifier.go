@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	mail_dtos "sensio/domain/mail/dtos"
+	terminal_entities "sensio/domain/terminal/terminal/entities"
+	"strings"
+	"time"
+)
+
+// alertTTL bounds how long an alert waits in the MQTT outbox while the broker is down
+const alertTTL = time.Hour
+
+// TerminalLookup resolves the terminals that share a room with the lock's terminal
+type TerminalLookup interface {
+	GetByID(id string) (*terminal_entities.Terminal, error)
+	GetByRoomID(roomID string) ([]terminal_entities.Terminal, error)
+}
+
+// MqttPublisher publishes alert payloads (implemented by MqttService)
+type MqttPublisher interface {
+	Publish(topic string, qos byte, retained bool, payload interface{}) error
+}
+
+// MailByMacSender emails the customer of a terminal (implemented by MailSendByMacUseCase)
+type MailByMacSender interface {
+	SendMailByMac(macAddress string, req *mail_dtos.SendMailByMacRequestDTO) (string, error)
+}
+
+// DoorLockAlertNotifier publishes lock alarms to every terminal of the room and emails the customer
+type DoorLockAlertNotifier struct {
+	terminals TerminalLookup
+	mqtt      MqttPublisher
+	mail      MailByMacSender
+	env       string
+}
+
+// NewDoorLockAlertNotifier creates a new instance of DoorLockAlertNotifier; mail may be nil to disable emails
+func NewDoorLockAlertNotifier(terminals TerminalLookup, mqtt MqttPublisher, mail MailByMacSender, env string) *DoorLockAlertNotifier {
+	return &DoorLockAlertNotifier{
+		terminals: terminals,
+		mqtt:      mqtt,
+		mail:      mail,
+		env:       env,
+	}
+}
+
+// Notify fans the event out to the room over MQTT, then emails the customer of the lock's terminal
+func (n *DoorLockAlertNotifier) Notify(event *entities.DoorLockEvent, deviceName string) error {
+	owner, err := n.terminals.GetByID(event.TerminalID)
+	if err != nil {
+		return fmt.Errorf("failed to lookup terminal %s: %w", event.TerminalID, err)
+	}
+
+	targets := []terminal_entities.Terminal{*owner}
+	if owner.RoomID != "" {
+		if roomTerminals, err := n.terminals.GetByRoomID(owner.RoomID); err == nil && len(roomTerminals) > 0 {
+			targets = roomTerminals
+		}
+	}
+
+	payload, err := json.Marshal(dtos.DoorLockAlertPayload{
+		EventID:    event.ID,
+		TerminalID: event.TerminalID,
+		DeviceID:   event.DeviceID,
+		DeviceName: deviceName,
+		Category:   event.Category,
+		AlarmType:  event.AlarmType,
+		Severity:   event.Severity,
+		Value:      event.Value,
+		OccurredAt: event.OccurredAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert payload: %w", err)
+	}
+
+	var failed []string
+	for _, t := range targets {
+		topic := fmt.Sprintf("users/%s/%s/doorlock/alert", t.MacAddress, n.env)
+		if err := infrastructure.PublishWithOptions(n.mqtt, topic, 1, false, payload, infrastructure.MqttPublishOptions{TTL: alertTTL}); err != nil {
+			utils.LogError("DoorLockAlertNotifier: failed to publish to %s: %v", topic, err)
+			failed = append(failed, topic)
+		}
+	}
+	if len(failed) == len(targets) {
+		return fmt.Errorf("failed to publish alert to %s", strings.Join(failed, ", "))
+	}
+
+	if n.mail != nil {
+		_, err := n.mail.SendMailByMac(owner.MacAddress, &mail_dtos.SendMailByMacRequestDTO{
+			Subject:  fmt.Sprintf("Door lock alert: %s", alertTitle(event)),
+			Template: "door_lock_alert",
+			Data: map[string]interface{}{
+				"alert_title": alertTitle(event),
+				"device_name": deviceName,
+				"terminal":    owner.Name,
+				"severity":    event.Severity,
+				"value":       event.Value,
+				"occurred_at": event.OccurredAt.Format("2006-01-02 15:04:05 MST"),
+			},
+		})
+		if err != nil {
+			// MQTT already reached the room; a failed email does not fail the alert
+			utils.LogError("DoorLockAlertNotifier: failed to queue alert email for %s: %v", owner.MacAddress, err)
+		}
+	}
+	return nil
+}
+
+func alertTitle(event *entities.DoorLockEvent) string {
+	switch {
+	case event.Category == entities.EventCategoryHijack:
+		return "duress unlock (hijack)"
+	case event.AlarmType == "low_battery":
+		return "low battery"
+	default:
+		return strings.ReplaceAll(event.AlarmType, "_", " ")
+	}
+}
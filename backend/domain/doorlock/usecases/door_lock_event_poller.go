@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/entities"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	"sync"
+	"time"
+)
+
+// DoorLockEventPoller reads lock statuses back from the Tuya cloud so events that no terminal
+// reports over MQTT (e.g. a fingerprint unlock at the door) are still recorded. Changed values
+// are written to the device statuses and announced to the listener with source "poll".
+type DoorLockEventPoller struct {
+	devRepo    device_repositories.IDeviceRepository
+	statusRepo device_status_repositories.IDeviceStatusRepository
+	devices    DeviceOnlineChecker
+	tokens     AccessTokenProvider
+	listener   device_status_usecases.DeviceStatusListener
+	interval   time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewDoorLockEventPoller creates a new instance of DoorLockEventPoller
+func NewDoorLockEventPoller(devRepo device_repositories.IDeviceRepository, statusRepo device_status_repositories.IDeviceStatusRepository, devices DeviceOnlineChecker, tokens AccessTokenProvider, interval time.Duration) *DoorLockEventPoller {
+	if interval <= 0 {
+		interval = 2 * time.Minute
+	}
+	return &DoorLockEventPoller{
+		devRepo:    devRepo,
+		statusRepo: statusRepo,
+		devices:    devices,
+		tokens:     tokens,
+		interval:   interval,
+		stop:       make(chan struct{}),
+	}
+}
+
+// SetStatusListener registers the listener notified for every changed status
+func (p *DoorLockEventPoller) SetStatusListener(listener device_status_usecases.DeviceStatusListener) {
+	p.listener = listener
+}
+
+// Start runs the poll loop in the background until Stop is called
+func (p *DoorLockEventPoller) Start() {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.Tick()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	utils.LogInfo("DoorLockEventPoller: started (interval=%s)", p.interval)
+}
+
+// Stop terminates the poll loop
+func (p *DoorLockEventPoller) Stop() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// Tick polls every registered lock once
+func (p *DoorLockEventPoller) Tick() {
+	devices, err := p.devRepo.GetAll()
+	if err != nil {
+		utils.LogError("DoorLockEventPoller: failed to load devices: %v", err)
+		return
+	}
+
+	var locks []device_entities.Device
+	for _, d := range devices {
+		if entities.IsLockCategory(d.Category) {
+			locks = append(locks, d)
+		}
+	}
+	if len(locks) == 0 {
+		return
+	}
+
+	token, err := p.tokens.GetTuyaAccessToken()
+	if err != nil {
+		utils.LogError("DoorLockEventPoller: failed to get Tuya access token: %v", err)
+		return
+	}
+	for _, lock := range locks {
+		if err := p.PollDevice(token, lock); err != nil {
+			utils.LogWarn("DoorLockEventPoller: failed to poll lock %s: %v", lock.ID, err)
+		}
+	}
+}
+
+// PollDevice fetches the statuses of one lock and announces the event codes whose value changed.
+// An unlock code seen for the first time only sets the baseline: its value is the last user,
+// so it cannot tell whether the unlock is new.
+func (p *DoorLockEventPoller) PollDevice(accessToken string, lock device_entities.Device) error {
+	remote, err := p.devices.GetDeviceByID(accessToken, lock.ID, "")
+	if err != nil {
+		return err
+	}
+	if remote == nil {
+		return fmt.Errorf("device not returned by Tuya")
+	}
+
+	for _, s := range remote.Status {
+		if _, ok := entities.ClassifyStatus(s.Code, fmt.Sprintf("%v", s.Value)); !ok && !isEventLevelCode(s.Code) {
+			continue
+		}
+
+		value := fmt.Sprintf("%v", s.Value)
+		previous, known := "", false
+		if existing, err := p.statusRepo.GetByDeviceIDAndCode(lock.ID, s.Code); err == nil && existing != nil {
+			previous, known = existing.Value, true
+		}
+		if known && previous == value {
+			continue
+		}
+		if err := p.statusRepo.Upsert(&device_status_entities.DeviceStatus{DeviceID: lock.ID, Code: s.Code, Value: value}); err != nil {
+			return err
+		}
+		if !known && entities.IsMomentary(s.Code) {
+			continue
+		}
+
+		if p.listener != nil {
+			p.listener.OnDeviceStatusChanged(device_status_usecases.DeviceStatusChange{
+				TerminalID:    lock.TerminalID,
+				DeviceID:      lock.ID,
+				Code:          s.Code,
+				Value:         value,
+				PreviousValue: previous,
+				Source:        device_status_usecases.StatusSourcePoll,
+				ChangedAt:     time.Now(),
+			})
+		}
+	}
+	return nil
+}
+
+// isEventLevelCode reports codes whose "nothing happened" value (hijack false, empty alarm)
+// must still be stored so the next alarm is seen as a change
+func isEventLevelCode(code string) bool {
+	return code == entities.CodeAlarmLock || code == entities.CodeHijack || code == entities.CodeDoorbell
+}
@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	"sensio/domain/doorlock/repositories"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxEventLimit = 500
+
+var eventCategories = map[string]bool{
+	entities.EventCategoryUnlock:   true,
+	entities.EventCategoryState:    true,
+	entities.EventCategoryAlarm:    true,
+	entities.EventCategoryHijack:   true,
+	entities.EventCategoryBattery:  true,
+	entities.EventCategoryDoorbell: true,
+}
+
+// AlertNotifier delivers warning and critical lock events to the people in the room
+type AlertNotifier interface {
+	Notify(event *entities.DoorLockEvent, deviceName string) error
+}
+
+// DoorLockEventUseCase records the events reported by locks and raises alerts for alarms.
+// It listens to every device status write; statuses of devices that are not locks are ignored.
+type DoorLockEventUseCase struct {
+	repo     repositories.IDoorLockEventRepository
+	devRepo  device_repositories.IDeviceRepository
+	notifier AlertNotifier
+	cooldown time.Duration
+
+	mu         sync.Mutex
+	lastAlerts map[string]time.Time // deviceID/alarm type -> last notification
+}
+
+// NewDoorLockEventUseCase creates a new instance of DoorLockEventUseCase.
+// The notifier may be nil; the same alarm of a lock is notified at most once per cooldown.
+func NewDoorLockEventUseCase(repo repositories.IDoorLockEventRepository, devRepo device_repositories.IDeviceRepository, notifier AlertNotifier, cooldown time.Duration) *DoorLockEventUseCase {
+	return &DoorLockEventUseCase{
+		repo:       repo,
+		devRepo:    devRepo,
+		notifier:   notifier,
+		cooldown:   cooldown,
+		lastAlerts: make(map[string]time.Time),
+	}
+}
+
+// OnDeviceStatusChanged ingests the change asynchronously so the write path is never blocked
+func (uc *DoorLockEventUseCase) OnDeviceStatusChanged(change device_status_usecases.DeviceStatusChange) {
+	go func() {
+		if _, err := uc.Ingest(change); err != nil {
+			utils.LogError("DoorLockEventUseCase: failed to ingest %s of device %s: %v", change.Code, change.DeviceID, err)
+		}
+	}()
+}
+
+// Ingest classifies a status change and records it as an event. It returns nil without error
+// when the change is not a lock event or repeats the last recorded level (battery, lock state);
+// unlocks, alarms, hijacks and doorbells are occurrences and always recorded.
+func (uc *DoorLockEventUseCase) Ingest(change device_status_usecases.DeviceStatusChange) (*entities.DoorLockEvent, error) {
+	event, ok := entities.ClassifyStatus(change.Code, change.Value)
+	if !ok {
+		return nil, nil
+	}
+
+	device, err := uc.devRepo.GetByID(change.DeviceID)
+	if err != nil || device == nil || !entities.IsLockCategory(device.Category) {
+		return nil, nil
+	}
+
+	if !entities.IsMomentary(change.Code) && !event.IsEdge() {
+		previous := change.PreviousValue
+		if previous == "" {
+			if latest, err := uc.repo.GetLatest(change.DeviceID, change.Code); err == nil && latest != nil {
+				previous = latest.Value
+			}
+		}
+		// State events store the classified lock state rather than the raw value
+		if previous == change.Value || previous == event.Value {
+			return nil, nil
+		}
+	}
+
+	occurredAt := change.ChangedAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	event.ID = uuid.New().String()
+	event.TerminalID = change.TerminalID
+	event.DeviceID = change.DeviceID
+	event.Source = change.Source
+	event.OccurredAt = occurredAt
+	if err := uc.repo.Save(&event); err != nil {
+		return nil, fmt.Errorf("failed to save event: %w", err)
+	}
+
+	if event.ShouldNotify() && uc.notifier != nil && uc.claimAlert(&event) {
+		if err := uc.notifier.Notify(&event, device.Name); err != nil {
+			utils.LogError("DoorLockEventUseCase: failed to notify %s alarm of device %s: %v", event.Category, event.DeviceID, err)
+			return &event, nil
+		}
+		event.Notified = true
+		if err := uc.repo.Save(&event); err != nil {
+			return nil, fmt.Errorf("failed to save event: %w", err)
+		}
+	}
+	return &event, nil
+}
+
+// ListEvents returns the latest events of a lock, newest first, optionally filtered by category
+func (uc *DoorLockEventUseCase) ListEvents(terminalID, deviceID, category string, limit int) ([]dtos.DoorLockEventResponseDTO, error) {
+	if category != "" && !eventCategories[category] {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "category", Message: "category must be one of unlock, state, alarm, hijack, battery, doorbell"},
+		})
+	}
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > maxEventLimit {
+		limit = maxEventLimit
+	}
+	events, err := uc.repo.GetByDeviceID(terminalID, deviceID, category, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.DoorLockEventResponseDTO, 0, len(events))
+	for _, e := range events {
+		result = append(result, dtos.DoorLockEventResponseDTO{
+			ID:           e.ID,
+			DeviceID:     e.DeviceID,
+			Code:         e.Code,
+			Value:        e.Value,
+			Category:     e.Category,
+			Severity:     e.Severity,
+			UnlockMethod: e.UnlockMethod,
+			UserID:       e.UserID,
+			AlarmType:    e.AlarmType,
+			Source:       e.Source,
+			Notified:     e.Notified,
+			OccurredAt:   e.OccurredAt.Format(time.RFC3339),
+		})
+	}
+	return result, nil
+}
+
+// claimAlert reports whether the alarm may be notified now and starts its cooldown.
+// Cooldowns that have ended are dropped so the map only holds recent alarms.
+func (uc *DoorLockEventUseCase) claimAlert(event *entities.DoorLockEvent) bool {
+	key := event.DeviceID + "/" + event.AlarmType
+	now := time.Now()
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	for k, last := range uc.lastAlerts {
+		if now.Sub(last) >= uc.cooldown {
+			delete(uc.lastAlerts, k)
+		}
+	}
+	if last, ok := uc.lastAlerts[key]; ok && now.Sub(last) < uc.cooldown {
+		return false
+	}
+	uc.lastAlerts[key] = now
+	return true
+}
@@ -0,0 +1,226 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/doorlock/entities"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEventRepo struct {
+	mu     sync.Mutex
+	events []entities.DoorLockEvent
+}
+
+func (r *fakeEventRepo) Save(e *entities.DoorLockEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.events {
+		if r.events[i].ID == e.ID {
+			r.events[i] = *e
+			return nil
+		}
+	}
+	r.events = append(r.events, *e)
+	return nil
+}
+
+func (r *fakeEventRepo) GetLatest(deviceID, code string) (*entities.DoorLockEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if r.events[i].DeviceID == deviceID && r.events[i].Code == code {
+			e := r.events[i]
+			return &e, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeEventRepo) GetByDeviceID(terminalID, deviceID, category string, limit int) ([]entities.DoorLockEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entities.DoorLockEvent
+	for i := len(r.events) - 1; i >= 0 && len(out) < limit; i-- {
+		e := r.events[i]
+		if e.TerminalID == terminalID && e.DeviceID == deviceID && (category == "" || e.Category == category) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+type fakeNotifier struct {
+	notified []string
+	err      error
+}
+
+func (n *fakeNotifier) Notify(event *entities.DoorLockEvent, deviceName string) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.notified = append(n.notified, deviceName+"/"+event.AlarmType)
+	return nil
+}
+
+func newTestEventUseCase(notifier AlertNotifier) (*DoorLockEventUseCase, *fakeEventRepo) {
+	repo := &fakeEventRepo{}
+	devRepo := &fakeDeviceRepo{devices: map[string]device_entities.Device{
+		"lock-1":   {ID: "lock-1", TerminalID: "term-1", Name: "Front door", Category: "jtmspro"},
+		"sensor-1": {ID: "sensor-1", TerminalID: "term-1", Category: "wsdcg"},
+	}}
+	return NewDoorLockEventUseCase(repo, devRepo, notifier, time.Minute), repo
+}
+
+func lockChange(code, value, previous string) device_status_usecases.DeviceStatusChange {
+	return device_status_usecases.DeviceStatusChange{
+		TerminalID:    "term-1",
+		DeviceID:      "lock-1",
+		Code:          code,
+		Value:         value,
+		PreviousValue: previous,
+		Source:        device_status_usecases.StatusSourceMQTT,
+		ChangedAt:     time.Now(),
+	}
+}
+
+func TestClassifyStatus(t *testing.T) {
+	unlock, ok := entities.ClassifyStatus("unlock_fingerprint", "3")
+	require.True(t, ok)
+	assert.Equal(t, entities.EventCategoryUnlock, unlock.Category)
+	assert.Equal(t, "fingerprint", unlock.UnlockMethod)
+	assert.Equal(t, "3", unlock.UserID)
+	assert.False(t, unlock.ShouldNotify())
+
+	alarm, ok := entities.ClassifyStatus("alarm_lock", "wrong_finger")
+	require.True(t, ok)
+	assert.Equal(t, entities.SeverityWarning, alarm.Severity)
+
+	hijack, ok := entities.ClassifyStatus("hijack", "true")
+	require.True(t, ok)
+	assert.Equal(t, entities.SeverityCritical, hijack.Severity)
+
+	battery, ok := entities.ClassifyStatus("residual_electricity", "15")
+	require.True(t, ok)
+	assert.Equal(t, "low_battery", battery.AlarmType)
+
+	state, ok := entities.ClassifyStatus("lock_motor_state", "true")
+	require.True(t, ok)
+	assert.Equal(t, string(entities.LockStateUnlocked), state.Value)
+
+	_, ok = entities.ClassifyStatus("hijack", "false")
+	assert.False(t, ok)
+	_, ok = entities.ClassifyStatus("switch_1", "true")
+	assert.False(t, ok)
+}
+
+func TestIngest_RecordsUnlocksAndSkipsRepeatedLevels(t *testing.T) {
+	uc, repo := newTestEventUseCase(nil)
+
+	// Each unlock is an occurrence, even by the same user
+	for i := 0; i < 2; i++ {
+		event, err := uc.Ingest(lockChange("unlock_card", "2", "2"))
+		require.NoError(t, err)
+		require.NotNil(t, event)
+	}
+
+	// A battery level reported twice is recorded once
+	event, err := uc.Ingest(lockChange("battery_state", "middle", "high"))
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	event, err = uc.Ingest(lockChange("battery_state", "middle", "middle"))
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	// Without a previous status value the last event is used
+	event, err = uc.Ingest(lockChange("battery_state", "middle", ""))
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	// Statuses of devices that are not locks are ignored
+	sensor := lockChange("battery_state", "low", "high")
+	sensor.DeviceID = "sensor-1"
+	event, err = uc.Ingest(sensor)
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	unlocks, err := uc.ListEvents("term-1", "lock-1", entities.EventCategoryUnlock, 0)
+	require.NoError(t, err)
+	assert.Len(t, unlocks, 2)
+	assert.Len(t, repo.events, 3)
+}
+
+func TestIngest_NotifiesAlarmsWithCooldown(t *testing.T) {
+	notifier := &fakeNotifier{}
+	uc, _ := newTestEventUseCase(notifier)
+
+	event, err := uc.Ingest(lockChange("hijack", "true", "false"))
+	require.NoError(t, err)
+	assert.True(t, event.Notified)
+
+	// The same alarm again within the cooldown is recorded but not notified
+	_, err = uc.Ingest(lockChange("hijack", "false", "true"))
+	require.NoError(t, err)
+	event, err = uc.Ingest(lockChange("hijack", "true", "false"))
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.False(t, event.Notified)
+
+	// A different alarm has its own cooldown; doorbells are never notified
+	_, err = uc.Ingest(lockChange("alarm_lock", "pry", ""))
+	require.NoError(t, err)
+	_, err = uc.Ingest(lockChange("doorbell", "true", "false"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Front door/hijack", "Front door/pry"}, notifier.notified)
+}
+
+func TestIngest_RecordsRepeatedAlarms(t *testing.T) {
+	uc, repo := newTestEventUseCase(nil)
+
+	// A lock reports the same alarm again when it happens again, with or without a previous value
+	for _, previous := range []string{"", "wrong_finger", ""} {
+		event, err := uc.Ingest(lockChange("alarm_lock", "wrong_finger", previous))
+		require.NoError(t, err)
+		require.NotNil(t, event)
+	}
+	assert.Len(t, repo.events, 3)
+}
+
+func TestClaimAlert_EvictsEndedCooldowns(t *testing.T) {
+	uc, _ := newTestEventUseCase(nil)
+	uc.lastAlerts["lock-9/pry"] = time.Now().Add(-2 * time.Minute)
+	uc.lastAlerts["lock-1/hijack"] = time.Now()
+
+	assert.True(t, uc.claimAlert(&entities.DoorLockEvent{DeviceID: "lock-1", AlarmType: "pry"}))
+	assert.False(t, uc.claimAlert(&entities.DoorLockEvent{DeviceID: "lock-1", AlarmType: "hijack"}))
+	assert.NotContains(t, uc.lastAlerts, "lock-9/pry")
+	assert.Len(t, uc.lastAlerts, 2)
+}
+
+func TestIngest_FailedNotificationKeepsEvent(t *testing.T) {
+	uc, repo := newTestEventUseCase(&fakeNotifier{err: errors.New("broker down")})
+
+	event, err := uc.Ingest(lockChange("battery_state", "low", "middle"))
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.False(t, event.Notified)
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, entities.SeverityWarning, repo.events[0].Severity)
+}
+
+func TestListEvents_RejectsUnknownCategoryAndForeignLock(t *testing.T) {
+	uc, _ := newTestEventUseCase(nil)
+
+	_, err := uc.ListEvents("term-1", "lock-1", "party", 10)
+	assert.Error(t, err)
+
+	_, err = uc.ListEvents("term-2", "lock-1", "", 10)
+	assert.ErrorIs(t, err, ErrLockNotFound)
+}
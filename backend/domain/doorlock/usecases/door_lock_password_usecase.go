@@ -0,0 +1,268 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	"sensio/domain/doorlock/repositories"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	generatedPasswordLength     = 7
+	minPasswordLength           = 6
+	maxPasswordLength           = 10
+	maxTemporaryPasswordMinutes = 365 * 24 * 60
+)
+
+// DoorLockPasswordUseCase creates dynamic and temporary door lock passwords.
+// Dynamic passwords are computed by the Tuya cloud and work while the lock is offline.
+// Temporary passwords must be written to the lock: when it is offline the password is
+// stored as pending_sync and the DoorLockSyncWorker pushes it once the lock is reachable.
+type DoorLockPasswordUseCase struct {
+	repo      repositories.IDoorLockPasswordRepository
+	devRepo   device_repositories.IDeviceRepository
+	online    DeviceOnlineChecker
+	generator PasswordGenerator
+	worker    *DoorLockSyncWorker
+	now       func() time.Time
+}
+
+// NewDoorLockPasswordUseCase creates a new instance of DoorLockPasswordUseCase
+func NewDoorLockPasswordUseCase(
+	repo repositories.IDoorLockPasswordRepository,
+	devRepo device_repositories.IDeviceRepository,
+	online DeviceOnlineChecker,
+	generator PasswordGenerator,
+	worker *DoorLockSyncWorker,
+) *DoorLockPasswordUseCase {
+	return &DoorLockPasswordUseCase{
+		repo:      repo,
+		devRepo:   devRepo,
+		online:    online,
+		generator: generator,
+		worker:    worker,
+		now:       time.Now,
+	}
+}
+
+// CreateDynamicPassword generates a one-time password valid for five minutes
+func (uc *DoorLockPasswordUseCase) CreateDynamicPassword(terminalID, deviceID, accessToken string) (*dtos.DoorLockPasswordResponseDTO, error) {
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+
+	generated, err := uc.generator.GenerateDynamicPassword(accessToken, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate dynamic password: %w", err)
+	}
+
+	now := uc.now()
+	password := &entities.DoorLockPassword{
+		ID:           uuid.New().String(),
+		TerminalID:   terminalID,
+		DeviceID:     deviceID,
+		Type:         entities.PasswordTypeDynamic,
+		Value:        generated.Value,
+		ValidMinutes: int(entities.DynamicPasswordValidity / time.Minute),
+		EffectiveAt:  now,
+		ExpireAt:     generated.ExpireAt,
+		Status:       entities.SyncStatusActive,
+	}
+	if err := uc.repo.Save(password); err != nil {
+		return nil, err
+	}
+	return toPasswordDTO(password), nil
+}
+
+// CreateTemporaryPassword registers a reusable password, or queues it when the lock is offline
+func (uc *DoorLockPasswordUseCase) CreateTemporaryPassword(terminalID, deviceID string, req dtos.TemporaryPasswordRequestDTO, accessToken string) (*dtos.DoorLockPasswordResponseDTO, error) {
+	now := uc.now()
+	effectiveAt, err := validateTemporaryPassword(req, now)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+
+	password, err := uc.createTemporary(terminalID, deviceID, req.Name, req.Password, effectiveAt, effectiveAt.Add(time.Duration(req.DurationMinutes)*time.Minute), accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return toPasswordDTO(password), nil
+}
+
+// createTemporary stores a temporary password for the window and pushes it when the lock is online,
+// otherwise it stays pending_sync for the worker. A random password is generated when value is empty.
+func (uc *DoorLockPasswordUseCase) createTemporary(terminalID, deviceID, name, value string, effectiveAt, expireAt time.Time, accessToken string) (*entities.DoorLockPassword, error) {
+	if value == "" {
+		var err error
+		if value, err = randomDigits(generatedPasswordLength); err != nil {
+			return nil, err
+		}
+	}
+
+	password := &entities.DoorLockPassword{
+		ID:           uuid.New().String(),
+		TerminalID:   terminalID,
+		DeviceID:     deviceID,
+		Name:         name,
+		Type:         entities.PasswordTypeTemporary,
+		Value:        value,
+		ValidMinutes: int(expireAt.Sub(effectiveAt).Minutes()),
+		EffectiveAt:  effectiveAt,
+		ExpireAt:     expireAt,
+		Status:       entities.SyncStatusPending,
+	}
+
+	device, err := uc.online.GetDeviceByID(accessToken, deviceID, "")
+	if err != nil {
+		// Treat an unreachable lock like an offline one: the worker retries later
+		utils.LogWarn("DoorLockPasswordUseCase: failed to check lock %s, queueing password: %v", deviceID, err)
+		password.LastError = err.Error()
+	} else if device.Online {
+		generated, err := uc.generator.CreateTemporaryPassword(accessToken, deviceID, password.Name, password.Value, password.EffectiveAt, password.ExpireAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary password: %w", err)
+		}
+		password.Status = entities.SyncStatusActive
+		password.RemotePasswordID = generated.RemotePasswordID
+	} else {
+		utils.LogInfo("DoorLockPasswordUseCase: lock %s is offline, password %s queued for sync", deviceID, password.ID)
+	}
+
+	if err := uc.repo.Save(password); err != nil {
+		return nil, err
+	}
+	return password, nil
+}
+
+// revokeTemporary removes a temporary password from the lock, also once expired so it stops taking
+// a password slot. A password that was never pushed is only marked revoked, under the sync worker
+// lock so the worker cannot push it afterwards.
+func (uc *DoorLockPasswordUseCase) revokeTemporary(terminalID, passwordID, accessToken string) (*entities.DoorLockPassword, error) {
+	uc.worker.mu.Lock()
+	defer uc.worker.mu.Unlock()
+
+	password, err := uc.repo.GetByID(terminalID, passwordID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch password.Status {
+	case entities.SyncStatusActive:
+		if password.RemotePasswordID != "" {
+			if err := uc.generator.DeleteTemporaryPassword(accessToken, password.DeviceID, password.RemotePasswordID); err != nil {
+				return password, fmt.Errorf("failed to delete temporary password: %w", err)
+			}
+		}
+	case entities.SyncStatusRevoked, entities.SyncStatusFailed, entities.SyncStatusExpired:
+		return password, nil
+	}
+
+	password.Status = entities.SyncStatusRevoked
+	password.LastError = ""
+	if err := uc.repo.Save(password); err != nil {
+		return nil, err
+	}
+	return password, nil
+}
+
+// ListPasswords returns the passwords created for a lock, newest first
+func (uc *DoorLockPasswordUseCase) ListPasswords(terminalID, deviceID string) ([]dtos.DoorLockPasswordResponseDTO, error) {
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+	passwords, err := uc.repo.GetByDeviceID(terminalID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.DoorLockPasswordResponseDTO, 0, len(passwords))
+	for i := range passwords {
+		result = append(result, *toPasswordDTO(&passwords[i]))
+	}
+	return result, nil
+}
+
+// SyncPendingPasswords immediately pushes the pending passwords of a lock
+func (uc *DoorLockPasswordUseCase) SyncPendingPasswords(terminalID, deviceID, accessToken string) (*dtos.DoorLockSyncResponseDTO, error) {
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+	return uc.worker.SyncDevice(deviceID, accessToken)
+}
+
+func validateTemporaryPassword(req dtos.TemporaryPasswordRequestDTO, now time.Time) (time.Time, error) {
+	var details []utils.ValidationErrorDetail
+	if req.DurationMinutes <= 0 {
+		details = append(details, utils.ValidationErrorDetail{Field: "duration_minutes", Message: "duration_minutes must be positive"})
+	} else if req.DurationMinutes > maxTemporaryPasswordMinutes {
+		details = append(details, utils.ValidationErrorDetail{Field: "duration_minutes", Message: fmt.Sprintf("duration_minutes must not exceed %d", maxTemporaryPasswordMinutes)})
+	}
+	if req.Password != "" && !isDigits(req.Password, minPasswordLength, maxPasswordLength) {
+		details = append(details, utils.ValidationErrorDetail{Field: "password", Message: fmt.Sprintf("password must be %d to %d digits", minPasswordLength, maxPasswordLength)})
+	}
+
+	effectiveAt := now
+	if req.EffectiveAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.EffectiveAt)
+		if err != nil {
+			details = append(details, utils.ValidationErrorDetail{Field: "effective_at", Message: "effective_at must be an RFC3339 timestamp"})
+		} else if parsed.After(now) {
+			effectiveAt = parsed
+		}
+	}
+
+	if len(details) > 0 {
+		return time.Time{}, utils.NewValidationError("Validation Error", details)
+	}
+	return effectiveAt, nil
+}
+
+func isDigits(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func randomDigits(n int) (string, error) {
+	buf := make([]byte, n)
+	for i := range buf {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		buf[i] = byte('0' + d.Int64())
+	}
+	return string(buf), nil
+}
+
+func toPasswordDTO(p *entities.DoorLockPassword) *dtos.DoorLockPasswordResponseDTO {
+	return &dtos.DoorLockPasswordResponseDTO{
+		ID:           p.ID,
+		DeviceID:     p.DeviceID,
+		Name:         p.Name,
+		Type:         string(p.Type),
+		Value:        p.Value,
+		ValidMinutes: p.ValidMinutes,
+		EffectiveAt:  p.EffectiveAt.Format(time.RFC3339),
+		ExpireAt:     p.ExpireAt.Format(time.RFC3339),
+		Status:       string(p.Status),
+		RetryCount:   p.RetryCount,
+		LastError:    p.LastError,
+		CreatedAt:    p.CreatedAt.Format(time.RFC3339),
+	}
+}
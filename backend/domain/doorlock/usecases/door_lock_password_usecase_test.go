@@ -0,0 +1,268 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePasswordRepo struct {
+	passwords map[string]entities.DoorLockPassword
+}
+
+func newFakePasswordRepo() *fakePasswordRepo {
+	return &fakePasswordRepo{passwords: map[string]entities.DoorLockPassword{}}
+}
+
+func (r *fakePasswordRepo) Save(p *entities.DoorLockPassword) error {
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+	r.passwords[p.ID] = *p
+	return nil
+}
+
+func (r *fakePasswordRepo) GetByID(terminalID, id string) (*entities.DoorLockPassword, error) {
+	p, ok := r.passwords[id]
+	if !ok || p.TerminalID != terminalID {
+		return nil, errors.New("record not found")
+	}
+	return &p, nil
+}
+
+func (r *fakePasswordRepo) GetByDeviceID(terminalID, deviceID string) ([]entities.DoorLockPassword, error) {
+	return r.filter(func(p entities.DoorLockPassword) bool { return p.TerminalID == terminalID && p.DeviceID == deviceID }), nil
+}
+
+func (r *fakePasswordRepo) GetPending() ([]entities.DoorLockPassword, error) {
+	return r.filter(func(p entities.DoorLockPassword) bool { return p.Status == entities.SyncStatusPending }), nil
+}
+
+func (r *fakePasswordRepo) GetPendingByDeviceID(deviceID string) ([]entities.DoorLockPassword, error) {
+	return r.filter(func(p entities.DoorLockPassword) bool {
+		return p.DeviceID == deviceID && p.Status == entities.SyncStatusPending
+	}), nil
+}
+
+func (r *fakePasswordRepo) filter(keep func(entities.DoorLockPassword) bool) []entities.DoorLockPassword {
+	var out []entities.DoorLockPassword
+	for _, p := range r.passwords {
+		if keep(p) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// fakeDeviceRepo only implements the lookup used by the door lock use cases
+type fakeDeviceRepo struct {
+	device_repositories.IDeviceRepository
+	devices map[string]device_entities.Device
+}
+
+func (r *fakeDeviceRepo) GetByID(id string) (*device_entities.Device, error) {
+	d, ok := r.devices[id]
+	if !ok {
+		return nil, errors.New("device not found (cached)")
+	}
+	return &d, nil
+}
+
+type fakeOnlineChecker struct {
+	online bool
+	err    error
+}
+
+func (c *fakeOnlineChecker) GetDeviceByID(accessToken, deviceID, remoteID string) (*tuya_dtos.TuyaDeviceDTO, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &tuya_dtos.TuyaDeviceDTO{ID: deviceID, Online: c.online}, nil
+}
+
+type fakeGenerator struct {
+	created []string
+	deleted []string
+	err     error
+}
+
+func (g *fakeGenerator) GenerateDynamicPassword(accessToken, deviceID string) (*entities.GeneratedPassword, error) {
+	return &entities.GeneratedPassword{Value: "98765432", ExpireAt: time.Now().Add(5 * time.Minute)}, nil
+}
+
+func (g *fakeGenerator) CreateTemporaryPassword(accessToken, deviceID, name, password string, effectiveAt, expireAt time.Time) (*entities.GeneratedPassword, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	g.created = append(g.created, password)
+	return &entities.GeneratedPassword{Value: password, RemotePasswordID: "remote-1", ExpireAt: expireAt}, nil
+}
+
+func (g *fakeGenerator) DeleteTemporaryPassword(accessToken, deviceID, remotePasswordID string) error {
+	if g.err != nil {
+		return g.err
+	}
+	g.deleted = append(g.deleted, remotePasswordID)
+	return nil
+}
+
+type fakeTokens struct{}
+
+func (fakeTokens) GetTuyaAccessToken() (string, error) { return "token", nil }
+
+func newTestPasswordUseCase(online *fakeOnlineChecker, gen *fakeGenerator) (*DoorLockPasswordUseCase, *fakePasswordRepo, *DoorLockSyncWorker) {
+	repo := newFakePasswordRepo()
+	devRepo := &fakeDeviceRepo{devices: map[string]device_entities.Device{
+		"lock-1": {ID: "lock-1", TerminalID: "term-1"},
+	}}
+	worker := NewDoorLockSyncWorker(repo, online, gen, fakeTokens{}, time.Minute, 3)
+	return NewDoorLockPasswordUseCase(repo, devRepo, online, gen, worker), repo, worker
+}
+
+func TestCreateTemporaryPassword_OnlineIsActive(t *testing.T) {
+	gen := &fakeGenerator{}
+	uc, _, _ := newTestPasswordUseCase(&fakeOnlineChecker{online: true}, gen)
+
+	result, err := uc.CreateTemporaryPassword("term-1", "lock-1", dtos.TemporaryPasswordRequestDTO{DurationMinutes: 60}, "token")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(entities.SyncStatusActive), result.Status)
+	assert.Len(t, result.Value, generatedPasswordLength)
+	assert.Equal(t, []string{result.Value}, gen.created)
+}
+
+func TestCreateTemporaryPassword_OfflineIsQueuedAndSynced(t *testing.T) {
+	online := &fakeOnlineChecker{online: false}
+	gen := &fakeGenerator{}
+	uc, repo, worker := newTestPasswordUseCase(online, gen)
+
+	result, err := uc.CreateTemporaryPassword("term-1", "lock-1", dtos.TemporaryPasswordRequestDTO{DurationMinutes: 60, Password: "1234567"}, "token")
+	require.NoError(t, err)
+	assert.Equal(t, string(entities.SyncStatusPending), result.Status)
+	assert.Empty(t, gen.created)
+
+	// Still offline: nothing happens
+	worker.Tick()
+	assert.Equal(t, entities.SyncStatusPending, repo.passwords[result.ID].Status)
+
+	online.online = true
+	worker.Tick()
+	synced := repo.passwords[result.ID]
+	assert.Equal(t, entities.SyncStatusActive, synced.Status)
+	assert.Equal(t, "remote-1", synced.RemotePasswordID)
+	assert.Equal(t, []string{"1234567"}, gen.created)
+}
+
+func TestSyncWorker_ExpiresAndFailsPendingPasswords(t *testing.T) {
+	gen := &fakeGenerator{err: errors.New("device busy")}
+	online := &fakeOnlineChecker{online: true}
+	_, repo, worker := newTestPasswordUseCase(online, gen)
+
+	now := time.Now()
+	_ = repo.Save(&entities.DoorLockPassword{ID: "expired", DeviceID: "lock-1", Status: entities.SyncStatusPending, ExpireAt: now.Add(-time.Minute)})
+	_ = repo.Save(&entities.DoorLockPassword{ID: "retry", DeviceID: "lock-1", Status: entities.SyncStatusPending, ExpireAt: now.Add(time.Hour)})
+
+	result, err := worker.SyncDevice("lock-1", "token")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Expired)
+	assert.Equal(t, 1, result.Pending)
+	assert.Equal(t, entities.SyncStatusExpired, repo.passwords["expired"].Status)
+	assert.Equal(t, "device busy", repo.passwords["retry"].LastError)
+
+	// maxRetries is 3: two more failures, then the next attempt marks it failed
+	worker.Tick()
+	worker.Tick()
+	worker.Tick()
+	assert.Equal(t, entities.SyncStatusFailed, repo.passwords["retry"].Status)
+}
+
+func TestCreateTemporaryPassword_Validation(t *testing.T) {
+	uc, _, _ := newTestPasswordUseCase(&fakeOnlineChecker{online: true}, &fakeGenerator{})
+
+	_, err := uc.CreateTemporaryPassword("term-1", "lock-1", dtos.TemporaryPasswordRequestDTO{DurationMinutes: 0, Password: "12ab"}, "token")
+	var valErr *utils.ValidationError
+	require.ErrorAs(t, err, &valErr)
+	assert.Len(t, valErr.Details, 2)
+
+	_, err = uc.CreateTemporaryPassword("other-terminal", "lock-1", dtos.TemporaryPasswordRequestDTO{DurationMinutes: 60}, "token")
+	assert.ErrorIs(t, err, ErrLockNotFound)
+}
+
+// fakeStatusRepo only implements the lookups and writes used by DoorLockUseCase
+type fakeStatusRepo struct {
+	device_status_repositories.IDeviceStatusRepository
+	values map[string]string
+}
+
+func (r *fakeStatusRepo) GetByDeviceIDAndCode(deviceID, code string) (*device_status_entities.DeviceStatus, error) {
+	v, ok := r.values[deviceID+"/"+code]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &device_status_entities.DeviceStatus{DeviceID: deviceID, Code: code, Value: v, UpdatedAt: time.Now()}, nil
+}
+
+func (r *fakeStatusRepo) Upsert(status *device_status_entities.DeviceStatus) error {
+	r.values[status.DeviceID+"/"+status.Code] = status.Value
+	return nil
+}
+
+type fakeLockCmd struct {
+	commands []tuya_dtos.TuyaCommandDTO
+}
+
+func (c *fakeLockCmd) SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error) {
+	c.commands = append(c.commands, commands...)
+	return true, nil
+}
+
+type recordingListener struct {
+	changes []device_status_usecases.DeviceStatusChange
+}
+
+func (l *recordingListener) OnDeviceStatusChanged(change device_status_usecases.DeviceStatusChange) {
+	l.changes = append(l.changes, change)
+}
+
+func TestDoorLockUseCase_LockUnlockRecordsState(t *testing.T) {
+	devRepo := &fakeDeviceRepo{devices: map[string]device_entities.Device{"lock-1": {ID: "lock-1", TerminalID: "term-1"}}}
+	statusRepo := &fakeStatusRepo{values: map[string]string{}}
+	cmd := &fakeLockCmd{}
+	listener := &recordingListener{}
+	uc := NewDoorLockUseCase(devRepo, statusRepo, cmd)
+	uc.SetStatusListener(listener)
+
+	state, err := uc.GetState("term-1", "lock-1")
+	require.NoError(t, err)
+	assert.Equal(t, string(entities.LockStateUnknown), state.State)
+
+	_, err = uc.Unlock("term-1", "lock-1", "token")
+	require.NoError(t, err)
+	state, _ = uc.GetState("term-1", "lock-1")
+	assert.Equal(t, string(entities.LockStateUnlocked), state.State)
+
+	_, err = uc.Lock("term-1", "lock-1", "token")
+	require.NoError(t, err)
+	state, _ = uc.GetState("term-1", "lock-1")
+	assert.Equal(t, string(entities.LockStateLocked), state.State)
+
+	require.Len(t, cmd.commands, 2)
+	assert.Equal(t, true, cmd.commands[0].Value)
+	assert.Equal(t, false, cmd.commands[1].Value)
+	require.Len(t, listener.changes, 2)
+	assert.Equal(t, "true", listener.changes[1].PreviousValue)
+}
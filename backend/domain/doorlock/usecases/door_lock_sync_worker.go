@@ -0,0 +1,208 @@
+package usecases
+
+import (
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	"sensio/domain/doorlock/repositories"
+	"sync"
+	"time"
+)
+
+// PasswordGenerator creates passwords through the Tuya cloud (implemented by TuyaDoorLockService)
+type PasswordGenerator interface {
+	GenerateDynamicPassword(accessToken, deviceID string) (*entities.GeneratedPassword, error)
+	CreateTemporaryPassword(accessToken, deviceID, name, password string, effectiveAt, expireAt time.Time) (*entities.GeneratedPassword, error)
+	DeleteTemporaryPassword(accessToken, deviceID, remotePasswordID string) error
+}
+
+// DoorLockSyncWorker pushes pending passwords to locks once they come back online.
+// Passwords are grouped per lock so an offline lock costs one status lookup per tick;
+// a password that expired while waiting is marked expired, one that keeps failing is
+// marked failed after maxRetries attempts.
+type DoorLockSyncWorker struct {
+	repo       repositories.IDoorLockPasswordRepository
+	online     DeviceOnlineChecker
+	generator  PasswordGenerator
+	tokens     AccessTokenProvider
+	interval   time.Duration
+	maxRetries int
+	now        func() time.Time
+
+	mu       sync.Mutex // serializes ticks and manual syncs so a password is never pushed twice
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewDoorLockSyncWorker creates a new instance of DoorLockSyncWorker
+func NewDoorLockSyncWorker(repo repositories.IDoorLockPasswordRepository, online DeviceOnlineChecker, generator PasswordGenerator, tokens AccessTokenProvider, interval time.Duration, maxRetries int) *DoorLockSyncWorker {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if maxRetries <= 0 {
+		maxRetries = 10
+	}
+	return &DoorLockSyncWorker{
+		repo:       repo,
+		online:     online,
+		generator:  generator,
+		tokens:     tokens,
+		interval:   interval,
+		maxRetries: maxRetries,
+		now:        time.Now,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start runs the sync loop in the background until Stop is called
+func (w *DoorLockSyncWorker) Start() {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.Tick()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	utils.LogInfo("DoorLockSyncWorker: started (interval=%s, max_retries=%d)", w.interval, w.maxRetries)
+}
+
+// Stop terminates the sync loop
+func (w *DoorLockSyncWorker) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+// Tick tries to sync every pending password
+func (w *DoorLockSyncWorker) Tick() {
+	pending, err := w.repo.GetPending()
+	if err != nil {
+		utils.LogError("DoorLockSyncWorker: failed to load pending passwords: %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	token, err := w.tokens.GetTuyaAccessToken()
+	if err != nil {
+		utils.LogError("DoorLockSyncWorker: failed to get Tuya access token: %v", err)
+		return
+	}
+
+	byDevice := make(map[string][]entities.DoorLockPassword)
+	var order []string
+	for _, p := range pending {
+		if _, ok := byDevice[p.DeviceID]; !ok {
+			order = append(order, p.DeviceID)
+		}
+		byDevice[p.DeviceID] = append(byDevice[p.DeviceID], p)
+	}
+	for _, deviceID := range order {
+		w.syncDevice(token, deviceID, byDevice[deviceID])
+	}
+}
+
+// SyncDevice immediately tries to sync the pending passwords of one lock
+func (w *DoorLockSyncWorker) SyncDevice(deviceID, accessToken string) (*dtos.DoorLockSyncResponseDTO, error) {
+	pending, err := w.repo.GetPendingByDeviceID(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending passwords: %w", err)
+	}
+	return w.syncDevice(accessToken, deviceID, pending), nil
+}
+
+func (w *DoorLockSyncWorker) syncDevice(token, deviceID string, passwords []entities.DoorLockPassword) *dtos.DoorLockSyncResponseDTO {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := &dtos.DoorLockSyncResponseDTO{}
+	if len(passwords) == 0 {
+		return result
+	}
+
+	device, err := w.online.GetDeviceByID(token, deviceID, "")
+	if err != nil {
+		utils.LogWarn("DoorLockSyncWorker: failed to check lock %s: %v", deviceID, err)
+		for i := range passwords {
+			w.recordFailure(&passwords[i], err)
+			w.count(result, passwords[i].Status)
+		}
+		return result
+	}
+	if !device.Online {
+		utils.LogDebug("DoorLockSyncWorker: lock %s is offline, %d password(s) still pending", deviceID, len(passwords))
+		result.Pending = len(passwords)
+		return result
+	}
+
+	result.Online = true
+	for i := range passwords {
+		w.syncPassword(token, &passwords[i])
+		w.count(result, passwords[i].Status)
+	}
+	return result
+}
+
+func (w *DoorLockSyncWorker) syncPassword(token string, p *entities.DoorLockPassword) {
+	// The password may have been revoked since the pending list was loaded
+	if current, err := w.repo.GetByID(p.TerminalID, p.ID); err == nil && current.Status != entities.SyncStatusPending {
+		*p = *current
+		return
+	}
+
+	now := w.now()
+	switch {
+	case p.IsExpired(now):
+		p.Status = entities.SyncStatusExpired
+		utils.LogWarn("DoorLockSyncWorker: password %s expired before it could be synced", p.ID)
+	case p.RetryCount >= w.maxRetries:
+		p.Status = entities.SyncStatusFailed
+		utils.LogWarn("DoorLockSyncWorker: password %s exceeded %d retries", p.ID, w.maxRetries)
+	default:
+		effectiveAt := p.EffectiveAt
+		if effectiveAt.Before(now) {
+			effectiveAt = now
+		}
+		generated, err := w.generator.CreateTemporaryPassword(token, p.DeviceID, p.Name, p.Value, effectiveAt, p.ExpireAt)
+		if err != nil {
+			w.recordFailure(p, err)
+			utils.LogWarn("DoorLockSyncWorker: failed to sync password %s: %v", p.ID, err)
+			return
+		}
+		p.Status = entities.SyncStatusActive
+		p.RemotePasswordID = generated.RemotePasswordID
+		p.LastError = ""
+		utils.LogInfo("DoorLockSyncWorker: synced password %s to lock %s", p.ID, p.DeviceID)
+	}
+	if err := w.repo.Save(p); err != nil {
+		utils.LogError("DoorLockSyncWorker: failed to save password %s: %v", p.ID, err)
+	}
+}
+
+func (w *DoorLockSyncWorker) recordFailure(p *entities.DoorLockPassword, cause error) {
+	p.RetryCount++
+	p.LastError = cause.Error()
+	if err := w.repo.Save(p); err != nil {
+		utils.LogError("DoorLockSyncWorker: failed to save password %s: %v", p.ID, err)
+	}
+}
+
+func (w *DoorLockSyncWorker) count(result *dtos.DoorLockSyncResponseDTO, status entities.SyncStatus) {
+	switch status {
+	case entities.SyncStatusActive:
+		result.Synced++
+	case entities.SyncStatusFailed:
+		result.Failed++
+	case entities.SyncStatusExpired:
+		result.Expired++
+	case entities.SyncStatusRevoked:
+		// Revoked while the sync was running: no longer pending
+	default:
+		result.Pending++
+	}
+}
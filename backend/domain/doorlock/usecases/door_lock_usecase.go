@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrLockNotFound is returned when a device does not exist or belongs to another terminal
+var ErrLockNotFound = errors.New("door lock not found")
+
+// DeviceCommandExecutor sends data point commands to Tuya devices
+type DeviceCommandExecutor interface {
+	SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error)
+}
+
+// DeviceOnlineChecker fetches live device details from Tuya (implemented by TuyaGetDeviceByIDUseCase)
+type DeviceOnlineChecker interface {
+	GetDeviceByID(accessToken, deviceID, remoteID string) (*tuya_dtos.TuyaDeviceDTO, error)
+}
+
+// AccessTokenProvider supplies the Tuya access token used by background syncs
+type AccessTokenProvider interface {
+	GetTuyaAccessToken() (string, error)
+}
+
+// DoorLockUseCase locks and unlocks doors and reports their state from the device statuses
+type DoorLockUseCase struct {
+	devRepo    device_repositories.IDeviceRepository
+	statusRepo device_status_repositories.IDeviceStatusRepository
+	commands   DeviceCommandExecutor
+
+	listener device_status_usecases.DeviceStatusListener
+}
+
+// NewDoorLockUseCase creates a new instance of DoorLockUseCase
+func NewDoorLockUseCase(devRepo device_repositories.IDeviceRepository, statusRepo device_status_repositories.IDeviceStatusRepository, commands DeviceCommandExecutor) *DoorLockUseCase {
+	return &DoorLockUseCase{
+		devRepo:    devRepo,
+		statusRepo: statusRepo,
+		commands:   commands,
+	}
+}
+
+// SetStatusListener registers the listener notified after the lock state is written
+func (uc *DoorLockUseCase) SetStatusListener(listener device_status_usecases.DeviceStatusListener) {
+	uc.listener = listener
+}
+
+// Lock locks the door of a device owned by the terminal
+func (uc *DoorLockUseCase) Lock(terminalID, deviceID, accessToken string) (*dtos.DoorLockStateResponseDTO, error) {
+	return uc.setLocked(terminalID, deviceID, accessToken, true)
+}
+
+// Unlock unlocks the door of a device owned by the terminal
+func (uc *DoorLockUseCase) Unlock(terminalID, deviceID, accessToken string) (*dtos.DoorLockStateResponseDTO, error) {
+	return uc.setLocked(terminalID, deviceID, accessToken, false)
+}
+
+// GetState returns the last recorded lock state
+func (uc *DoorLockUseCase) GetState(terminalID, deviceID string) (*dtos.DoorLockStateResponseDTO, error) {
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+
+	resp := &dtos.DoorLockStateResponseDTO{
+		DeviceID: deviceID,
+		State:    string(entities.LockStateUnknown),
+	}
+	if status, err := uc.statusRepo.GetByDeviceIDAndCode(deviceID, entities.CodeLockMotorState); err == nil && status != nil {
+		resp.State = string(entities.LockStateFromStatus(status.Value))
+		resp.UpdatedAt = status.UpdatedAt.Format(time.RFC3339)
+	}
+	for _, code := range []string{entities.CodeBatteryState, entities.CodeResidualPower} {
+		if status, err := uc.statusRepo.GetByDeviceIDAndCode(deviceID, code); err == nil && status != nil {
+			resp.Battery = status.Value
+			break
+		}
+	}
+	return resp, nil
+}
+
+func (uc *DoorLockUseCase) setLocked(terminalID, deviceID, accessToken string, locked bool) (*dtos.DoorLockStateResponseDTO, error) {
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+
+	// lock_motor_state is the "unlocked" flag: false locks the door, true unlocks it
+	value := !locked
+	success, err := uc.commands.SendSwitchCommand(accessToken, deviceID, []tuya_dtos.TuyaCommandDTO{
+		{Code: entities.CodeLockMotorState, Value: value},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send lock command: %w", err)
+	}
+	if !success {
+		return nil, fmt.Errorf("failed to send lock command: unsuccessful response from Tuya")
+	}
+
+	valStr := fmt.Sprintf("%v", value)
+	previous := ""
+	if existing, err := uc.statusRepo.GetByDeviceIDAndCode(deviceID, entities.CodeLockMotorState); err == nil && existing != nil {
+		previous = existing.Value
+	}
+	if err := uc.statusRepo.Upsert(&device_status_entities.DeviceStatus{DeviceID: deviceID, Code: entities.CodeLockMotorState, Value: valStr}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if uc.listener != nil {
+		uc.listener.OnDeviceStatusChanged(device_status_usecases.DeviceStatusChange{
+			TerminalID:    terminalID,
+			DeviceID:      deviceID,
+			Code:          entities.CodeLockMotorState,
+			Value:         valStr,
+			PreviousValue: previous,
+			Source:        device_status_usecases.StatusSourceAPI,
+			ChangedAt:     now,
+		})
+	}
+
+	utils.LogInfo("DoorLockUseCase: device %s %s by terminal %s", deviceID, entities.LockStateFromStatus(valStr), terminalID)
+	return &dtos.DoorLockStateResponseDTO{
+		DeviceID:  deviceID,
+		State:     string(entities.LockStateFromStatus(valStr)),
+		UpdatedAt: now.Format(time.RFC3339),
+	}, nil
+}
+
+// ownedDevice returns the device when it belongs to the terminal, ErrLockNotFound otherwise
+func ownedDevice(devRepo device_repositories.IDeviceRepository, terminalID, deviceID string) (*device_entities.Device, error) {
+	device, err := devRepo.GetByID(deviceID)
+	if err != nil {
+		// The repository reports cached misses as a plain "device not found" error
+		if errors.Is(err, gorm.ErrRecordNotFound) || strings.Contains(err.Error(), "not found") {
+			return nil, ErrLockNotFound
+		}
+		return nil, err
+	}
+	if device == nil || device.TerminalID != terminalID {
+		return nil, ErrLockNotFound
+	}
+	return device, nil
+}
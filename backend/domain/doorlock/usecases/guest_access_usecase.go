@@ -0,0 +1,204 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	"sensio/domain/doorlock/repositories"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrGuestAccessNotFound is returned when a guest access does not exist for the lock of the terminal
+var ErrGuestAccessNotFound = errors.New("guest access not found")
+
+// GuestAccessUseCase gives guests a lock code valid for their stay. The code is created ahead of
+// time as a temporary password effective from check-in, so an offline lock gets it through the
+// pending-password queue; the GuestAccessWorker emails it once it is on the lock and revokes it
+// at check-out.
+type GuestAccessUseCase struct {
+	repo         repositories.IGuestAccessRepository
+	passwordRepo repositories.IDoorLockPasswordRepository
+	passwords    *DoorLockPasswordUseCase
+	devRepo      device_repositories.IDeviceRepository
+	terminals    TerminalLookup
+	worker       *GuestAccessWorker
+	now          func() time.Time
+}
+
+// NewGuestAccessUseCase creates a new instance of GuestAccessUseCase
+func NewGuestAccessUseCase(
+	repo repositories.IGuestAccessRepository,
+	passwordRepo repositories.IDoorLockPasswordRepository,
+	passwords *DoorLockPasswordUseCase,
+	devRepo device_repositories.IDeviceRepository,
+	terminals TerminalLookup,
+	worker *GuestAccessWorker,
+) *GuestAccessUseCase {
+	return &GuestAccessUseCase{
+		repo:         repo,
+		passwordRepo: passwordRepo,
+		passwords:    passwords,
+		devRepo:      devRepo,
+		terminals:    terminals,
+		worker:       worker,
+		now:          time.Now,
+	}
+}
+
+// CreateGuestAccess creates the guest's code for the check-in/check-out window
+func (uc *GuestAccessUseCase) CreateGuestAccess(terminalID, deviceID string, req dtos.CreateGuestAccessRequestDTO, accessToken string) (*dtos.GuestAccessResponseDTO, error) {
+	now := uc.now()
+	checkIn, checkOut, err := validateGuestAccess(req, now)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+
+	roomID := ""
+	if terminal, err := uc.terminals.GetByID(terminalID); err == nil && terminal != nil {
+		roomID = terminal.RoomID
+	}
+
+	effectiveAt := checkIn
+	if effectiveAt.Before(now) {
+		effectiveAt = now
+	}
+	password, err := uc.passwords.createTemporary(terminalID, deviceID, "Guest: "+req.GuestName, req.Password, effectiveAt, checkOut, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	guest := &entities.GuestAccess{
+		ID:         uuid.New().String(),
+		TerminalID: terminalID,
+		DeviceID:   deviceID,
+		RoomID:     roomID,
+		GuestName:  req.GuestName,
+		GuestEmail: strings.TrimSpace(req.GuestEmail),
+		CheckInAt:  checkIn,
+		CheckOutAt: checkOut,
+		PasswordID: password.ID,
+		Status:     entities.GuestAccessScheduled,
+	}
+	if err := uc.repo.Save(guest); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("GuestAccessUseCase: guest access %s created for lock %s (code %s)", guest.ID, deviceID, password.Status)
+
+	if password.Status == entities.SyncStatusActive {
+		// Email the code right away instead of waiting for the next worker tick
+		go uc.worker.ProcessGuest(terminalID, guest.ID)
+	}
+	return toGuestAccessDTO(guest, password), nil
+}
+
+// ListGuestAccesses returns the guest accesses of a lock, latest check-in first
+func (uc *GuestAccessUseCase) ListGuestAccesses(terminalID, deviceID string) ([]dtos.GuestAccessResponseDTO, error) {
+	if _, err := ownedDevice(uc.devRepo, terminalID, deviceID); err != nil {
+		return nil, err
+	}
+
+	guests, err := uc.repo.GetByDeviceID(terminalID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.GuestAccessResponseDTO, 0, len(guests))
+	for i := range guests {
+		password, _ := uc.passwordRepo.GetByID(terminalID, guests[i].PasswordID)
+		result = append(result, *toGuestAccessDTO(&guests[i], password))
+	}
+	return result, nil
+}
+
+// RevokeGuestAccess removes the guest's code before check-out. When the lock cannot be reached
+// the access stays "revoking" and the worker retries.
+func (uc *GuestAccessUseCase) RevokeGuestAccess(terminalID, deviceID, id, accessToken string) (*dtos.GuestAccessResponseDTO, error) {
+	guest, err := uc.repo.GetByID(terminalID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || strings.Contains(err.Error(), "not found") {
+			return nil, ErrGuestAccessNotFound
+		}
+		return nil, err
+	}
+	if guest.DeviceID != deviceID {
+		return nil, ErrGuestAccessNotFound
+	}
+
+	if guest.IsOpen() {
+		uc.worker.Revoke(guest, accessToken)
+	}
+	password, _ := uc.passwordRepo.GetByID(terminalID, guest.PasswordID)
+	return toGuestAccessDTO(guest, password), nil
+}
+
+func validateGuestAccess(req dtos.CreateGuestAccessRequestDTO, now time.Time) (time.Time, time.Time, error) {
+	var details []utils.ValidationErrorDetail
+	if strings.TrimSpace(req.GuestName) == "" {
+		details = append(details, utils.ValidationErrorDetail{Field: "guest_name", Message: "guest_name is required"})
+	}
+	if _, err := mail.ParseAddress(strings.TrimSpace(req.GuestEmail)); err != nil {
+		details = append(details, utils.ValidationErrorDetail{Field: "guest_email", Message: "guest_email must be a valid email address"})
+	}
+	if req.Password != "" && !isDigits(req.Password, minPasswordLength, maxPasswordLength) {
+		details = append(details, utils.ValidationErrorDetail{Field: "password", Message: fmt.Sprintf("password must be %d to %d digits", minPasswordLength, maxPasswordLength)})
+	}
+
+	checkIn, inErr := time.Parse(time.RFC3339, req.CheckIn)
+	if inErr != nil {
+		details = append(details, utils.ValidationErrorDetail{Field: "check_in", Message: "check_in must be an RFC3339 timestamp"})
+	}
+	checkOut, outErr := time.Parse(time.RFC3339, req.CheckOut)
+	if outErr != nil {
+		details = append(details, utils.ValidationErrorDetail{Field: "check_out", Message: "check_out must be an RFC3339 timestamp"})
+	}
+	if inErr == nil && outErr == nil {
+		switch {
+		case !checkOut.After(checkIn):
+			details = append(details, utils.ValidationErrorDetail{Field: "check_out", Message: "check_out must be after check_in"})
+		case !checkOut.After(now):
+			details = append(details, utils.ValidationErrorDetail{Field: "check_out", Message: "check_out must be in the future"})
+		case checkOut.Sub(checkIn) > maxTemporaryPasswordMinutes*time.Minute:
+			details = append(details, utils.ValidationErrorDetail{Field: "check_out", Message: "stay must not exceed 365 days"})
+		}
+	}
+
+	if len(details) > 0 {
+		return time.Time{}, time.Time{}, utils.NewValidationError("Validation Error", details)
+	}
+	return checkIn, checkOut, nil
+}
+
+func toGuestAccessDTO(g *entities.GuestAccess, password *entities.DoorLockPassword) *dtos.GuestAccessResponseDTO {
+	dto := &dtos.GuestAccessResponseDTO{
+		ID:         g.ID,
+		DeviceID:   g.DeviceID,
+		RoomID:     g.RoomID,
+		GuestName:  g.GuestName,
+		GuestEmail: g.GuestEmail,
+		CheckIn:    g.CheckInAt.Format(time.RFC3339),
+		CheckOut:   g.CheckOutAt.Format(time.RFC3339),
+		Status:     string(g.Status),
+		PasswordID: g.PasswordID,
+		EmailSent:  g.EmailSent,
+		LastError:  g.LastError,
+		CreatedAt:  g.CreatedAt.Format(time.RFC3339),
+	}
+	if password != nil {
+		dto.Code = password.Value
+		dto.CodeStatus = string(password.Status)
+	}
+	if g.RevokedAt != nil {
+		dto.RevokedAt = g.RevokedAt.Format(time.RFC3339)
+	}
+	return dto
+}
@@ -0,0 +1,275 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/dtos"
+	"sensio/domain/doorlock/entities"
+	device_entities "sensio/domain/terminal/device/entities"
+	terminal_entities "sensio/domain/terminal/terminal/entities"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGuestRepo struct {
+	mu     sync.Mutex
+	guests map[string]entities.GuestAccess
+}
+
+func (r *fakeGuestRepo) Save(g *entities.GuestAccess) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.guests[g.ID] = *g
+	return nil
+}
+
+func (r *fakeGuestRepo) GetByID(terminalID, id string) (*entities.GuestAccess, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g, ok := r.guests[id]
+	if !ok || g.TerminalID != terminalID {
+		return nil, errors.New("record not found")
+	}
+	return &g, nil
+}
+
+func (r *fakeGuestRepo) GetByDeviceID(terminalID, deviceID string) ([]entities.GuestAccess, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entities.GuestAccess
+	for _, g := range r.guests {
+		if g.TerminalID == terminalID && g.DeviceID == deviceID {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeGuestRepo) GetOpen() ([]entities.GuestAccess, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entities.GuestAccess
+	for _, g := range r.guests {
+		if g.IsOpen() {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeGuestRepo) get(id string) entities.GuestAccess {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.guests[id]
+}
+
+type fakeGuestMailer struct {
+	mu   sync.Mutex
+	sent []map[string]interface{}
+	err  error
+}
+
+func (m *fakeGuestMailer) SendEmailWithTemplate(to []string, subject string, templateName string, data interface{}, attachmentPath *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, data.(map[string]interface{}))
+	return nil
+}
+
+func (m *fakeGuestMailer) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+type fakeTerminals struct{}
+
+func (fakeTerminals) GetByID(id string) (*terminal_entities.Terminal, error) {
+	return &terminal_entities.Terminal{ID: id, RoomID: "room-7", MacAddress: "AA:BB"}, nil
+}
+
+func (fakeTerminals) GetByRoomID(roomID string) ([]terminal_entities.Terminal, error) {
+	return nil, nil
+}
+
+type guestTestEnv struct {
+	uc        *GuestAccessUseCase
+	guests    *fakeGuestRepo
+	passwords *fakePasswordRepo
+	sync      *DoorLockSyncWorker
+	worker    *GuestAccessWorker
+	mailer    *fakeGuestMailer
+	gen       *fakeGenerator
+	online    *fakeOnlineChecker
+}
+
+func newGuestTestEnv(online bool) *guestTestEnv {
+	env := &guestTestEnv{
+		guests: &fakeGuestRepo{guests: map[string]entities.GuestAccess{}},
+		mailer: &fakeGuestMailer{},
+		gen:    &fakeGenerator{},
+		online: &fakeOnlineChecker{online: online},
+	}
+	var passwordUC *DoorLockPasswordUseCase
+	passwordUC, env.passwords, env.sync = newTestPasswordUseCase(env.online, env.gen)
+	devRepo := &fakeDeviceRepo{devices: map[string]device_entities.Device{
+		"lock-1": {ID: "lock-1", TerminalID: "term-1", Name: "Room 7 door"},
+	}}
+	env.worker = NewGuestAccessWorker(env.guests, env.passwords, passwordUC, devRepo, env.mailer, fakeTokens{}, time.Minute, 3)
+	env.uc = NewGuestAccessUseCase(env.guests, env.passwords, passwordUC, devRepo, fakeTerminals{}, env.worker)
+	return env
+}
+
+func stay(checkIn, checkOut time.Time) dtos.CreateGuestAccessRequestDTO {
+	return dtos.CreateGuestAccessRequestDTO{
+		GuestName:  "Jane Doe",
+		GuestEmail: "jane@example.com",
+		CheckIn:    checkIn.Format(time.RFC3339),
+		CheckOut:   checkOut.Format(time.RFC3339),
+	}
+}
+
+func TestGuestAccess_CreatedAheadOfTimeAndEmailed(t *testing.T) {
+	env := newGuestTestEnv(true)
+	checkIn := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	checkOut := checkIn.Add(48 * time.Hour)
+
+	result, err := env.uc.CreateGuestAccess("term-1", "lock-1", stay(checkIn, checkOut), "token")
+	require.NoError(t, err)
+	assert.Equal(t, string(entities.GuestAccessScheduled), result.Status)
+	assert.Equal(t, string(entities.SyncStatusActive), result.CodeStatus)
+	assert.Equal(t, "room-7", result.RoomID)
+
+	password := env.passwords.passwords[result.PasswordID]
+	assert.True(t, password.EffectiveAt.Equal(checkIn), "code must only work from check-in")
+	assert.True(t, password.ExpireAt.Equal(checkOut))
+
+	require.Eventually(t, func() bool { return env.mailer.count() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, result.Code, env.mailer.sent[0]["code"])
+	assert.Equal(t, "Room 7 door", env.mailer.sent[0]["lock_name"])
+	assert.True(t, env.guests.get(result.ID).EmailSent)
+
+	// Emailed once only
+	env.worker.Tick()
+	assert.Equal(t, 1, env.mailer.count())
+}
+
+func TestGuestAccess_OfflineLockEmailsOnceSynced(t *testing.T) {
+	env := newGuestTestEnv(false)
+	checkIn := time.Now().Add(time.Hour)
+
+	result, err := env.uc.CreateGuestAccess("term-1", "lock-1", stay(checkIn, checkIn.Add(time.Hour)), "token")
+	require.NoError(t, err)
+	assert.Equal(t, string(entities.SyncStatusPending), result.CodeStatus)
+
+	env.worker.Tick()
+	assert.Zero(t, env.mailer.count(), "code is not on the lock yet")
+
+	env.online.online = true
+	env.sync.Tick()
+	env.worker.Tick()
+	assert.Equal(t, 1, env.mailer.count())
+}
+
+func TestGuestAccess_RevokedAtCheckOut(t *testing.T) {
+	env := newGuestTestEnv(true)
+	checkIn := time.Now().Add(-time.Hour)
+	checkOut := time.Now().Add(time.Hour)
+
+	result, err := env.uc.CreateGuestAccess("term-1", "lock-1", stay(checkIn, checkOut), "token")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return env.mailer.count() == 1 }, time.Second, 10*time.Millisecond)
+
+	env.worker.Tick()
+	assert.Equal(t, entities.GuestAccessScheduled, env.guests.get(result.ID).Status)
+
+	env.worker.now = func() time.Time { return checkOut.Add(time.Minute) }
+	env.worker.Tick()
+	guest := env.guests.get(result.ID)
+	assert.Equal(t, entities.GuestAccessRevoked, guest.Status)
+	require.NotNil(t, guest.RevokedAt)
+	assert.Equal(t, []string{"remote-1"}, env.gen.deleted)
+	assert.Equal(t, entities.SyncStatusRevoked, env.passwords.passwords[result.PasswordID].Status)
+}
+
+func TestGuestAccess_RevokePendingCodeIsNeverPushed(t *testing.T) {
+	env := newGuestTestEnv(false)
+	checkIn := time.Now().Add(time.Hour)
+
+	created, err := env.uc.CreateGuestAccess("term-1", "lock-1", stay(checkIn, checkIn.Add(time.Hour)), "token")
+	require.NoError(t, err)
+
+	revoked, err := env.uc.RevokeGuestAccess("term-1", "lock-1", created.ID, "token")
+	require.NoError(t, err)
+	assert.Equal(t, string(entities.GuestAccessRevoked), revoked.Status)
+	assert.Equal(t, string(entities.SyncStatusRevoked), revoked.CodeStatus)
+
+	env.online.online = true
+	env.sync.Tick()
+	assert.Empty(t, env.gen.created)
+	assert.Empty(t, env.gen.deleted)
+}
+
+func TestGuestAccess_FailedRevocationIsRetried(t *testing.T) {
+	env := newGuestTestEnv(true)
+	checkIn := time.Now().Add(time.Hour)
+
+	created, err := env.uc.CreateGuestAccess("term-1", "lock-1", stay(checkIn, checkIn.Add(time.Hour)), "token")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return env.mailer.count() == 1 }, time.Second, 10*time.Millisecond)
+
+	env.gen.err = errors.New("lock unreachable")
+	revoked, err := env.uc.RevokeGuestAccess("term-1", "lock-1", created.ID, "token")
+	require.NoError(t, err)
+	assert.Equal(t, string(entities.GuestAccessRevoking), revoked.Status)
+	assert.Equal(t, "failed to delete temporary password: lock unreachable", revoked.LastError)
+
+	env.gen.err = nil
+	env.worker.Tick()
+	assert.Equal(t, entities.GuestAccessRevoked, env.guests.get(created.ID).Status)
+}
+
+func TestGuestAccess_UnsentCodeIsRemovedAndFailed(t *testing.T) {
+	env := newGuestTestEnv(true)
+	env.mailer.err = errors.New("smtp down")
+	checkIn := time.Now().Add(time.Hour)
+
+	created, err := env.uc.CreateGuestAccess("term-1", "lock-1", stay(checkIn, checkIn.Add(time.Hour)), "token")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return env.guests.get(created.ID).EmailAttempts == 1 }, time.Second, 10*time.Millisecond)
+
+	env.worker.Tick()
+	assert.Equal(t, entities.GuestAccessScheduled, env.guests.get(created.ID).Status)
+	env.worker.Tick()
+
+	// The last retry failed: the code is taken off the lock and the mail error kept
+	guest := env.guests.get(created.ID)
+	assert.Equal(t, entities.GuestAccessFailed, guest.Status)
+	assert.Equal(t, "smtp down", guest.LastError)
+	assert.Equal(t, []string{"remote-1"}, env.gen.deleted)
+
+	env.worker.Tick()
+	assert.Equal(t, 3, env.guests.get(created.ID).EmailAttempts)
+}
+
+func TestGuestAccess_Validation(t *testing.T) {
+	env := newGuestTestEnv(true)
+	now := time.Now()
+
+	req := stay(now.Add(2*time.Hour), now.Add(time.Hour))
+	req.GuestEmail = "not-an-email"
+	_, err := env.uc.CreateGuestAccess("term-1", "lock-1", req, "token")
+	var valErr *utils.ValidationError
+	require.ErrorAs(t, err, &valErr)
+	assert.Len(t, valErr.Details, 2)
+
+	_, err = env.uc.RevokeGuestAccess("term-1", "lock-1", "missing", "token")
+	assert.ErrorIs(t, err, ErrGuestAccessNotFound)
+}
@@ -0,0 +1,236 @@
+package usecases
+
+import (
+	"sensio/domain/common/utils"
+	"sensio/domain/doorlock/entities"
+	"sensio/domain/doorlock/repositories"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	"sync"
+	"time"
+)
+
+// guestCodeTemplate is the mail template (assets/templates/mail) used to send guests their code
+const guestCodeTemplate = "guest_access_code"
+
+// GuestMailer renders and sends a templated email (implemented by MailService)
+type GuestMailer interface {
+	SendEmailWithTemplate(to []string, subject string, templateName string, data interface{}, attachmentPath *string) error
+}
+
+// GuestAccessWorker emails guests their code once it is on the lock and revokes it at check-out.
+// Emails and revocations that fail are retried on the next tick, up to maxRetries times. A code
+// that could never be emailed is removed from the lock and its guest access marked failed.
+type GuestAccessWorker struct {
+	repo         repositories.IGuestAccessRepository
+	passwordRepo repositories.IDoorLockPasswordRepository
+	passwords    *DoorLockPasswordUseCase
+	devRepo      device_repositories.IDeviceRepository
+	mailer       GuestMailer
+	tokens       AccessTokenProvider
+	interval     time.Duration
+	maxRetries   int
+	now          func() time.Time
+
+	mu       sync.Mutex // serializes ticks, immediate emails and manual revocations
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewGuestAccessWorker creates a new instance of GuestAccessWorker
+func NewGuestAccessWorker(
+	repo repositories.IGuestAccessRepository,
+	passwordRepo repositories.IDoorLockPasswordRepository,
+	passwords *DoorLockPasswordUseCase,
+	devRepo device_repositories.IDeviceRepository,
+	mailer GuestMailer,
+	tokens AccessTokenProvider,
+	interval time.Duration,
+	maxRetries int,
+) *GuestAccessWorker {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if maxRetries <= 0 {
+		maxRetries = 10
+	}
+	return &GuestAccessWorker{
+		repo:         repo,
+		passwordRepo: passwordRepo,
+		passwords:    passwords,
+		devRepo:      devRepo,
+		mailer:       mailer,
+		tokens:       tokens,
+		interval:     interval,
+		maxRetries:   maxRetries,
+		now:          time.Now,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start runs the guest loop in the background until Stop is called
+func (w *GuestAccessWorker) Start() {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.Tick()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	utils.LogInfo("GuestAccessWorker: started (interval=%s, max_retries=%d)", w.interval, w.maxRetries)
+}
+
+// Stop terminates the guest loop
+func (w *GuestAccessWorker) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+// Tick processes every guest access that is still scheduled or being revoked
+func (w *GuestAccessWorker) Tick() {
+	guests, err := w.repo.GetOpen()
+	if err != nil {
+		utils.LogError("GuestAccessWorker: failed to load guest accesses: %v", err)
+		return
+	}
+	if len(guests) == 0 {
+		return
+	}
+
+	token, err := w.tokens.GetTuyaAccessToken()
+	if err != nil {
+		utils.LogError("GuestAccessWorker: failed to get Tuya access token: %v", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range guests {
+		w.process(&guests[i], token)
+	}
+}
+
+// ProcessGuest immediately processes one guest access (e.g. to email a code that was just created)
+func (w *GuestAccessWorker) ProcessGuest(terminalID, id string) {
+	token, err := w.tokens.GetTuyaAccessToken()
+	if err != nil {
+		utils.LogError("GuestAccessWorker: failed to get Tuya access token: %v", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	guest, err := w.repo.GetByID(terminalID, id)
+	if err != nil || !guest.IsOpen() {
+		return
+	}
+	w.process(guest, token)
+}
+
+// Revoke removes the guest's code now; the guest is updated in place with the outcome
+func (w *GuestAccessWorker) Revoke(guest *entities.GuestAccess, accessToken string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Reload under the lock: a tick may have revoked it meanwhile
+	if current, err := w.repo.GetByID(guest.TerminalID, guest.ID); err == nil {
+		*guest = *current
+	}
+	if guest.IsOpen() {
+		w.revoke(guest, accessToken)
+	}
+}
+
+func (w *GuestAccessWorker) process(g *entities.GuestAccess, token string) {
+	if g.Status == entities.GuestAccessRevoking || !w.now().Before(g.CheckOutAt) {
+		w.revoke(g, token)
+		return
+	}
+
+	password, err := w.passwordRepo.GetByID(g.TerminalID, g.PasswordID)
+	if err != nil {
+		utils.LogError("GuestAccessWorker: failed to load password of guest access %s: %v", g.ID, err)
+		return
+	}
+
+	switch password.Status {
+	case entities.SyncStatusFailed:
+		g.Status = entities.GuestAccessFailed
+		g.LastError = password.LastError
+		utils.LogWarn("GuestAccessWorker: code of guest access %s could not be written to lock %s", g.ID, g.DeviceID)
+		w.save(g)
+	case entities.SyncStatusActive:
+		if !g.EmailSent && g.EmailAttempts < w.maxRetries {
+			w.sendCode(g, password)
+		}
+		if w.emailFailed(g) {
+			utils.LogWarn("GuestAccessWorker: code of guest access %s could not be emailed, removing it from lock %s", g.ID, g.DeviceID)
+			w.revoke(g, token)
+		}
+	}
+}
+
+// emailFailed reports whether every attempt to email the guest their code failed
+func (w *GuestAccessWorker) emailFailed(g *entities.GuestAccess) bool {
+	return !g.EmailSent && g.EmailAttempts >= w.maxRetries
+}
+
+func (w *GuestAccessWorker) sendCode(g *entities.GuestAccess, password *entities.DoorLockPassword) {
+	lockName := g.DeviceID
+	if device, err := w.devRepo.GetByID(g.DeviceID); err == nil && device != nil && device.Name != "" {
+		lockName = device.Name
+	}
+
+	data := map[string]interface{}{
+		"brand_name": "Sensio",
+		"guest_name": g.GuestName,
+		"code":       password.Value,
+		"lock_name":  lockName,
+		"check_in":   g.CheckInAt.Format("Mon, 02 Jan 2006 15:04 MST"),
+		"check_out":  g.CheckOutAt.Format("Mon, 02 Jan 2006 15:04 MST"),
+	}
+	err := w.mailer.SendEmailWithTemplate([]string{g.GuestEmail}, "Your door access code", guestCodeTemplate, data, nil)
+	if err != nil {
+		g.EmailAttempts++
+		g.LastError = err.Error()
+		utils.LogWarn("GuestAccessWorker: failed to email code of guest access %s (attempt %d): %v", g.ID, g.EmailAttempts, err)
+	} else {
+		g.EmailSent = true
+		g.LastError = ""
+		utils.LogInfo("GuestAccessWorker: emailed code of guest access %s", g.ID)
+	}
+	w.save(g)
+}
+
+func (w *GuestAccessWorker) revoke(g *entities.GuestAccess, token string) {
+	if _, err := w.passwords.revokeTemporary(g.TerminalID, g.PasswordID, token); err != nil {
+		g.RevokeAttempts++
+		g.LastError = err.Error()
+		g.Status = entities.GuestAccessRevoking
+		if g.RevokeAttempts >= w.maxRetries {
+			g.Status = entities.GuestAccessFailed
+		}
+		utils.LogWarn("GuestAccessWorker: failed to revoke guest access %s (attempt %d): %v", g.ID, g.RevokeAttempts, err)
+	} else {
+		now := w.now()
+		g.Status = entities.GuestAccessRevoked
+		g.RevokedAt = &now
+		if w.emailFailed(g) {
+			// The guest never got the code; keep the mail error
+			g.Status = entities.GuestAccessFailed
+		} else {
+			g.LastError = ""
+		}
+		utils.LogInfo("GuestAccessWorker: revoked guest access %s on lock %s", g.ID, g.DeviceID)
+	}
+	w.save(g)
+}
+
+func (w *GuestAccessWorker) save(g *entities.GuestAccess) {
+	if err := w.repo.Save(g); err != nil {
+		utils.LogError("GuestAccessWorker: failed to save guest access %s: %v", g.ID, err)
+	}
+}
@@ -42,6 +42,14 @@ func (s *MailStatusDTO) SetExpiry(expiresAt string, expiresInSeconds int64) {
 	s.ExpiresInSecond = expiresInSeconds
 }
 
+// ApplyJobStatus implements tasks.StatusWithJob interface
+func (s *MailStatusDTO) ApplyJobStatus(status string, lastError string) {
+	s.Status = status
+	if s.Error == "" {
+		s.Error = lastError
+	}
+}
+
 // SwaggerEmailTemplateData represents the expected map structure for the email template (used for Swagger Docs only)
 type SwaggerEmailTemplateData struct {
 	Email            []string `json:"email,omitempty" example:"override@example.com,user2@example.com"`
@@ -20,19 +20,20 @@ type MailModule struct {
 	StatusController    *controllers.MailStatusController
 	UseCase             usecases.MailSendUseCase
 	SendByMacUseCase    usecases.MailSendByMacUseCase
+	Service             *services.MailService
 }
 
-func NewMailModule(cfg *utils.Config, badgerSvc *infrastructure.BadgerService) *MailModule {
+func NewMailModule(cfg *utils.Config, badgerSvc *infrastructure.BadgerService, jobs *tasks.JobQueue) *MailModule {
 	service := services.NewMailService(cfg)
 	externalService := commonServices.NewDeviceInfoExternalService()
 
 	// Task tracking
 	store := tasks.NewStatusStore[dtos.MailStatusDTO]()
 	cache := tasks.NewBadgerTaskCacheFromService(badgerSvc, "cache:mail:task:")
-	statusUC := tasks.NewGenericStatusUseCase(cache, store)
+	statusUC := tasks.NewJobStatusUseCase(cache, store, jobs)
 
-	useCase := usecases.NewMailSendUseCase(service, store, cache)
-	sendByMacUseCase := usecases.NewMailSendByMacUseCase(service, externalService, store, cache)
+	useCase := usecases.NewMailSendUseCase(service, store, cache, jobs)
+	sendByMacUseCase := usecases.NewMailSendByMacUseCase(service, externalService, store, cache, jobs)
 
 	controller := controllers.NewMailSendController(useCase)
 	sendByMacController := controllers.NewMailSendByMacController(sendByMacUseCase)
@@ -44,6 +45,7 @@ func NewMailModule(cfg *utils.Config, badgerSvc *infrastructure.BadgerService) *
 		StatusController:    statusController,
 		UseCase:             useCase,
 		SendByMacUseCase:    sendByMacUseCase,
+		Service:             service,
 	}
 }
 
@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -23,21 +24,34 @@ type mailSendByMacUseCase struct {
 	bigExternalService *commonServices.DeviceInfoExternalService
 	store              *tasks.StatusStore[dtos.MailStatusDTO]
 	cache              *tasks.BadgerTaskCache
+	jobs               *tasks.JobQueue
 }
 
-// NewMailSendByMacUseCase initializes a new mailSendByMacUseCase.
+// mailByMacJob is the payload of a MailByMacJobType job
+type mailByMacJob struct {
+	MacAddress string                       `json:"mac_address"`
+	Request    dtos.SendMailByMacRequestDTO `json:"request"`
+}
+
+// NewMailSendByMacUseCase initializes a new mailSendByMacUseCase and registers its job handler.
 func NewMailSendByMacUseCase(
 	mailService *services.MailService,
 	bigExternalService *commonServices.DeviceInfoExternalService,
 	store *tasks.StatusStore[dtos.MailStatusDTO],
 	cache *tasks.BadgerTaskCache,
+	jobs *tasks.JobQueue,
 ) MailSendByMacUseCase {
-	return &mailSendByMacUseCase{
+	uc := &mailSendByMacUseCase{
 		mailService:        mailService,
 		bigExternalService: bigExternalService,
 		store:              store,
 		cache:              cache,
+		jobs:               jobs,
 	}
+	jobs.Register(MailByMacJobType, uc.runJob, tasks.JobTypeOptions{
+		OnCancel: func(job *tasks.Job) { uc.updateStatus(job.ID, "cancelled", nil, "") },
+	})
+	return uc
 }
 
 func (uc *mailSendByMacUseCase) SendMailByMac(macAddress string, req *dtos.SendMailByMacRequestDTO) (string, error) {
@@ -70,16 +84,33 @@ func (uc *mailSendByMacUseCase) SendMailByMac(macAddress string, req *dtos.SendM
 
 	utils.LogInfo("MailSendByMacUseCase: Started task %s for MAC %s", taskID, macAddress)
 
-	go uc.processAsync(taskID, macAddress, req)
+	if _, err := uc.jobs.Enqueue(MailByMacJobType, taskID, mailByMacJob{MacAddress: macAddress, Request: *req}); err != nil {
+		uc.updateStatus(taskID, "failed", err, "")
+		return "", err
+	}
 
 	return taskID, nil
 }
 
-func (uc *mailSendByMacUseCase) processAsync(taskID string, macAddress string, req *dtos.SendMailByMacRequestDTO) {
+// runJob sends the mail of a queued job; the task is marked failed once no retry is left
+func (uc *mailSendByMacUseCase) runJob(ctx context.Context, job *tasks.Job) error {
+	var payload mailByMacJob
+	if err := job.Decode(&payload); err != nil {
+		uc.updateStatus(job.ID, "failed", err, "")
+		return tasks.Permanent(err)
+	}
+	err := uc.processAsync(job.ID, payload.MacAddress, &payload.Request)
+	if err != nil && (job.LastAttempt() || tasks.IsPermanent(err)) {
+		uc.updateStatus(job.ID, "failed", err, "")
+	}
+	return err
+}
+
+func (uc *mailSendByMacUseCase) processAsync(taskID string, macAddress string, req *dtos.SendMailByMacRequestDTO) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			utils.LogError("Mail Task %s (MAC): Panic recovered: %v", taskID, r)
-			uc.updateStatus(taskID, "failed", fmt.Errorf("internal panic: %v", r), "")
+			err = fmt.Errorf("internal panic: %v", r)
 		}
 	}()
 
@@ -88,8 +119,7 @@ func (uc *mailSendByMacUseCase) processAsync(taskID string, macAddress string, r
 	info, err := uc.bigExternalService.GetDeviceInfoByMac(macAddress)
 	if err != nil {
 		utils.LogError("Mail Task %s (MAC): Failed to fetch device info: %v", taskID, err)
-		uc.updateStatus(taskID, "failed", err, "")
-		return
+		return err
 	}
 
 	// Extract email from external API
@@ -142,8 +172,7 @@ func (uc *mailSendByMacUseCase) processAsync(taskID string, macAddress string, r
 		recipients = []string{strings.TrimSpace(rawRecipientEmail)}
 	default:
 		utils.LogError("Mail Task %s (MAC): Customer email not found in external API and no override provided", taskID)
-		uc.updateStatus(taskID, "failed", fmt.Errorf("customer email not found for this device"), "")
-		return
+		return tasks.Permanent(fmt.Errorf("customer email not found for this device"))
 	}
 
 	templateName := req.Template
@@ -277,15 +306,14 @@ func (uc *mailSendByMacUseCase) processAsync(taskID string, macAddress string, r
 	}
 
 	utils.LogDebug("MailSendByMacUseCase: Sending email to %v for MAC %s with subject: %s", recipients, macAddress, finalSubject)
-	err = uc.mailService.SendEmailWithTemplate(recipients, finalSubject, templateName, templateData, attachmentPath)
-	if err != nil {
+	if err := uc.mailService.SendEmailWithTemplate(recipients, finalSubject, templateName, templateData, attachmentPath); err != nil {
 		utils.LogError("Mail Task %s (MAC): Failed to send email: %v", taskID, err)
-		uc.updateStatus(taskID, "failed", err, "")
-		return
+		return err
 	}
 
 	utils.LogInfo("Mail Task %s (MAC): Email sent successfully", taskID)
 	uc.updateStatus(taskID, "completed", nil, fmt.Sprintf("Email sent to %s", strings.Join(recipients, ", ")))
+	return nil
 }
 
 func (uc *mailSendByMacUseCase) updateStatus(taskID string, statusStr string, err error, result string) {
@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,12 @@ import (
 	"time"
 )
 
+// Job types of the mail usecases; both share the "mail" worker pool
+const (
+	MailJobType      = "mail"
+	MailByMacJobType = "mail.by_mac"
+)
+
 // MailSendUseCase defines the interface for sending emails.
 type MailSendUseCase interface {
 	SendMail(req *dtos.MailSendRequestDTO) (string, error)
@@ -21,15 +28,21 @@ type mailSendUseCase struct {
 	mailService *services.MailService
 	store       *tasks.StatusStore[dtos.MailStatusDTO]
 	cache       *tasks.BadgerTaskCache
+	jobs        *tasks.JobQueue
 }
 
-// NewMailSendUseCase initializes a new mailSendUseCase.
-func NewMailSendUseCase(mailService *services.MailService, store *tasks.StatusStore[dtos.MailStatusDTO], cache *tasks.BadgerTaskCache) MailSendUseCase {
-	return &mailSendUseCase{
+// NewMailSendUseCase initializes a new mailSendUseCase and registers its job handler.
+func NewMailSendUseCase(mailService *services.MailService, store *tasks.StatusStore[dtos.MailStatusDTO], cache *tasks.BadgerTaskCache, jobs *tasks.JobQueue) MailSendUseCase {
+	uc := &mailSendUseCase{
 		mailService: mailService,
 		store:       store,
 		cache:       cache,
+		jobs:        jobs,
 	}
+	jobs.Register(MailJobType, uc.runJob, tasks.JobTypeOptions{
+		OnCancel: func(job *tasks.Job) { uc.updateStatus(job.ID, "cancelled", nil, "") },
+	})
+	return uc
 }
 
 func (uc *mailSendUseCase) SendMail(req *dtos.MailSendRequestDTO) (string, error) {
@@ -54,16 +67,33 @@ func (uc *mailSendUseCase) SendMail(req *dtos.MailSendRequestDTO) (string, error
 
 	utils.LogInfo("MailSendUseCase: Started task %s for recipients %v", taskID, req.To)
 
-	go uc.processAsync(taskID, req)
+	if _, err := uc.jobs.Enqueue(MailJobType, taskID, req); err != nil {
+		uc.updateStatus(taskID, "failed", err, "")
+		return "", err
+	}
 
 	return taskID, nil
 }
 
-func (uc *mailSendUseCase) processAsync(taskID string, req *dtos.MailSendRequestDTO) {
+// runJob sends the mail of a queued job; the task is marked failed once no retry is left
+func (uc *mailSendUseCase) runJob(ctx context.Context, job *tasks.Job) error {
+	var req dtos.MailSendRequestDTO
+	if err := job.Decode(&req); err != nil {
+		uc.updateStatus(job.ID, "failed", err, "")
+		return tasks.Permanent(err)
+	}
+	err := uc.processAsync(job.ID, &req)
+	if err != nil && (job.LastAttempt() || tasks.IsPermanent(err)) {
+		uc.updateStatus(job.ID, "failed", err, "")
+	}
+	return err
+}
+
+func (uc *mailSendUseCase) processAsync(taskID string, req *dtos.MailSendRequestDTO) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			utils.LogError("Mail Task %s: Panic recovered: %v", taskID, r)
-			uc.updateStatus(taskID, "failed", fmt.Errorf("internal panic: %v", r), "")
+			err = fmt.Errorf("internal panic: %v", r)
 		}
 	}()
 
@@ -113,15 +143,14 @@ func (uc *mailSendUseCase) processAsync(taskID string, req *dtos.MailSendRequest
 	}
 	req.Data["has_attachment"] = attachmentPath != nil && *attachmentPath != ""
 
-	err := uc.mailService.SendEmailWithTemplate(req.To, req.Subject, templateName, req.Data, attachmentPath)
-	if err != nil {
+	if err := uc.mailService.SendEmailWithTemplate(req.To, req.Subject, templateName, req.Data, attachmentPath); err != nil {
 		utils.LogError("Mail Task %s: Failed to send email: %v", taskID, err)
-		uc.updateStatus(taskID, "failed", err, "")
-		return
+		return err
 	}
 
 	utils.LogInfo("Mail Task %s: Email sent successfully", taskID)
 	uc.updateStatus(taskID, "completed", nil, fmt.Sprintf("Email sent to %s", strings.Join(req.To, ", ")))
+	return nil
 }
 
 func (uc *mailSendUseCase) updateStatus(taskID string, statusStr string, err error, result string) {
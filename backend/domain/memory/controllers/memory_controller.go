@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+	memory_dtos "sensio/domain/memory/dtos"
+	"sensio/domain/memory/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MemoryController exposes the conversational memory of a terminal
+type MemoryController struct {
+	conversationUC *usecases.ConversationUseCase
+}
+
+// Force Swaggo to detect DTOs
+var _ = memory_dtos.ConversationDTO{}
+
+func NewMemoryController(conversationUC *usecases.ConversationUseCase) *MemoryController {
+	return &MemoryController{conversationUC: conversationUC}
+}
+
+// ListConversations handles GET /api/memory/:terminal_id/conversation
+// @Summary List a terminal's conversations
+// @Description Return one conversation per user of the terminal with its rolling summary and last messages, most recently active first.
+// @Tags 12. Memory
+// @Produce json
+// @Param terminal_id path string true "Terminal ID"
+// @Param uid query string false "Only the conversation of this Tuya UID"
+// @Param limit query int false "Last messages returned per conversation (default 50, max 500)"
+// @Success 200 {object} dtos.StandardResponse{data=[]memory_dtos.ConversationDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/memory/{terminal_id}/conversation [get]
+func (c *MemoryController) ListConversations(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	result, err := c.conversationUC.ListConversations(ctx.Param("terminal_id"), ctx.Query("uid"), limit)
+	if err != nil {
+		respondError(ctx, "ListConversations", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Conversations retrieved successfully",
+		Data:    result,
+	})
+}
+
+// ExportConversation handles GET /api/memory/:terminal_id/conversation/export
+// @Summary Export a terminal's conversations
+// @Description Download every message, summary and remembered fact of the terminal as JSON or a plain-text transcript.
+// @Tags 12. Memory
+// @Produce json
+// @Produce plain
+// @Param terminal_id path string true "Terminal ID"
+// @Param uid query string false "Only the conversation of this Tuya UID"
+// @Param format query string false "json (default) or txt"
+// @Success 200 {object} memory_dtos.ConversationExportDTO
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/memory/{terminal_id}/conversation/export [get]
+func (c *MemoryController) ExportConversation(ctx *gin.Context) {
+	export, err := c.conversationUC.ExportConversation(ctx.Param("terminal_id"), ctx.Query("uid"), ctx.Query("format"))
+	if err != nil {
+		respondError(ctx, "ExportConversation", err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", export.FileName))
+	ctx.Data(http.StatusOK, export.ContentType, export.Content)
+}
+
+// ClearConversation handles DELETE /api/memory/:terminal_id/conversation
+// @Summary Clear a terminal's conversations
+// @Description Forget the messages and summaries of the terminal (one user when uid is set). Remembered facts are kept unless include_facts=true.
+// @Tags 12. Memory
+// @Produce json
+// @Param terminal_id path string true "Terminal ID"
+// @Param uid query string false "Only the conversation of this Tuya UID"
+// @Param include_facts query bool false "Also forget remembered facts and preferences"
+// @Success 200 {object} dtos.StandardResponse{data=memory_dtos.ClearConversationResultDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/memory/{terminal_id}/conversation [delete]
+func (c *MemoryController) ClearConversation(ctx *gin.Context) {
+	includeFacts, _ := strconv.ParseBool(ctx.DefaultQuery("include_facts", "false"))
+	result, err := c.conversationUC.ClearConversation(ctx.Param("terminal_id"), ctx.Query("uid"), includeFacts)
+	if err != nil {
+		respondError(ctx, "ClearConversation", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Conversation cleared successfully",
+		Data:    result,
+	})
+}
+
+// ListFacts handles GET /api/memory/:terminal_id/facts
+// @Summary List remembered facts
+// @Description Return the facts and preferences remembered for the terminal's users, most recently updated first.
+// @Tags 12. Memory
+// @Produce json
+// @Param terminal_id path string true "Terminal ID"
+// @Param uid query string false "Only the facts of this Tuya UID"
+// @Success 200 {object} dtos.StandardResponse{data=[]memory_dtos.MemoryFactDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/memory/{terminal_id}/facts [get]
+func (c *MemoryController) ListFacts(ctx *gin.Context) {
+	result, err := c.conversationUC.ListFacts(ctx.Param("terminal_id"), ctx.Query("uid"))
+	if err != nil {
+		respondError(ctx, "ListFacts", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Facts retrieved successfully",
+		Data:    result,
+	})
+}
+
+// DeleteFact handles DELETE /api/memory/:terminal_id/facts/:fact_id
+// @Summary Forget a remembered fact
+// @Tags 12. Memory
+// @Produce json
+// @Param terminal_id path string true "Terminal ID"
+// @Param fact_id path string true "Fact UUID"
+// @Success 200 {object} dtos.StandardResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/memory/{terminal_id}/facts/{fact_id} [delete]
+func (c *MemoryController) DeleteFact(ctx *gin.Context) {
+	if err := c.conversationUC.DeleteFact(ctx.Param("terminal_id"), ctx.Param("fact_id")); err != nil {
+		respondError(ctx, "DeleteFact", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Fact deleted successfully",
+	})
+}
+
+func respondError(ctx *gin.Context, op string, err error) {
+	var valErr *utils.ValidationError
+	if errors.As(err, &valErr) {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: valErr.Message,
+			Details: valErr.Details,
+		})
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	message := http.StatusText(statusCode)
+	if errors.Is(err, usecases.ErrFactNotFound) {
+		statusCode, message = http.StatusNotFound, http.StatusText(http.StatusNotFound)
+	} else {
+		utils.LogError("MemoryController.%s: %v", op, err)
+	}
+	ctx.JSON(statusCode, dtos.StandardResponse{
+		Status:  false,
+		Message: message,
+	})
+}
@@ -0,0 +1,52 @@
+package dtos
+
+// RecallDTO is what the assistant remembers before answering a user on a terminal
+type RecallDTO struct {
+	History []string // Recent turns, "User: ..." / "Assistant: ..."
+	Context string   // Rolling summary of older turns and remembered facts, empty when there is none
+}
+
+// ConversationMessageDTO represents one turn of a conversation
+type ConversationMessageDTO struct {
+	Seq       int    `json:"seq" example:"1"`
+	Role      string `json:"role" example:"user"` // user, assistant
+	Content   string `json:"content" example:"I like the AC at 24 degrees"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ConversationDTO represents the conversation of one user with a terminal
+type ConversationDTO struct {
+	SessionID    string                   `json:"session_id"`
+	TerminalID   string                   `json:"terminal_id"`
+	UID          string                   `json:"uid"`
+	Summary      string                   `json:"summary,omitempty"` // Rolling summary of the turns no longer sent verbatim
+	MessageCount int                      `json:"message_count"`
+	Messages     []ConversationMessageDTO `json:"messages"`
+	UpdatedAt    string                   `json:"updated_at"`
+}
+
+// MemoryFactDTO represents a remembered fact or preference
+type MemoryFactDTO struct {
+	ID         string `json:"id"`
+	TerminalID string `json:"terminal_id"`
+	UID        string `json:"uid"`
+	Key        string `json:"key" example:"preferred_temperature"`
+	Value      string `json:"value" example:"24°C"`
+	Source     string `json:"source" example:"statement"` // statement, summary
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// ConversationExportDTO is the JSON export of a terminal's memory
+type ConversationExportDTO struct {
+	TerminalID    string            `json:"terminal_id"`
+	ExportedAt    string            `json:"exported_at"`
+	Conversations []ConversationDTO `json:"conversations"`
+	Facts         []MemoryFactDTO   `json:"facts"`
+}
+
+// ClearConversationResultDTO reports what a clear removed
+type ClearConversationResultDTO struct {
+	TerminalID      string `json:"terminal_id"`
+	MessagesDeleted int64  `json:"messages_deleted"`
+	FactsDeleted    int64  `json:"facts_deleted"`
+}
@@ -0,0 +1,65 @@
+package entities
+
+import "time"
+
+// Message roles of a conversation
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Fact sources
+const (
+	FactSourceStatement = "statement" // Said explicitly by the user ("remember that...", "I like 24 degrees")
+	FactSourceSummary   = "summary"   // Extracted by the LLM while summarising older turns
+)
+
+// ConversationSession is the memory of one user talking to one terminal. Messages older than
+// the recent window are folded into Summary so the context sent to the model stays small.
+type ConversationSession struct {
+	ID           string    `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID   string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_conversation_session" json:"terminal_id"`
+	UID          string    `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_conversation_session" json:"uid"`
+	Summary      string    `gorm:"type:text" json:"summary"`
+	MessageCount int       `gorm:"not null;default:0" json:"message_count"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the ConversationSession model
+func (ConversationSession) TableName() string {
+	return "conversation_sessions"
+}
+
+// ConversationMessage is one turn of a session. Seq orders the messages of a session.
+type ConversationMessage struct {
+	ID         string    `gorm:"type:char(36);primaryKey" json:"id"`
+	SessionID  string    `gorm:"type:char(36);not null;index:idx_conversation_message_seq" json:"session_id"`
+	Seq        int       `gorm:"not null;index:idx_conversation_message_seq" json:"seq"`
+	Role       string    `gorm:"type:varchar(16);not null" json:"role"`
+	Content    string    `gorm:"type:text;not null" json:"content"`
+	Summarized bool      `gorm:"not null;default:false" json:"summarized"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the ConversationMessage model
+func (ConversationMessage) TableName() string {
+	return "conversation_messages"
+}
+
+// MemoryFact is a fact or preference remembered about a user of a terminal, unique per key
+type MemoryFact struct {
+	ID         string    `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_memory_fact_key" json:"terminal_id"`
+	UID        string    `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_memory_fact_key" json:"uid"`
+	Key        string    `gorm:"column:fact_key;type:varchar(100);not null;uniqueIndex:idx_memory_fact_key" json:"key"`
+	Value      string    `gorm:"type:text;not null" json:"value"`
+	Source     string    `gorm:"type:varchar(20);not null" json:"source"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the MemoryFact model
+func (MemoryFact) TableName() string {
+	return "memory_facts"
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"sensio/domain/common/utils"
+	"sensio/domain/memory/controllers"
+	"sensio/domain/memory/repositories"
+	"sensio/domain/memory/usecases"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MemoryModule struct {
+	Controller *controllers.MemoryController
+	// MemoryUseCase recalls and records chat turns; pass it to the chat use case
+	MemoryUseCase       *usecases.MemoryUseCase
+	ConversationUseCase *usecases.ConversationUseCase
+}
+
+func NewMemoryModule(db *gorm.DB) *MemoryModule {
+	cfg := utils.GetConfig()
+	conversations := repositories.NewConversationRepository(db)
+	facts := repositories.NewMemoryFactRepository(db)
+
+	conversationUC := usecases.NewConversationUseCase(conversations, facts)
+	return &MemoryModule{
+		Controller:          controllers.NewMemoryController(conversationUC),
+		MemoryUseCase:       usecases.NewMemoryUseCase(conversations, facts, cfg.MemoryRecentTurns, cfg.MemorySummaryBatch, cfg.MemoryMaxFacts),
+		ConversationUseCase: conversationUC,
+	}
+}
+
+func (m *MemoryModule) RegisterRoutes(protected *gin.RouterGroup) {
+	group := protected.Group("/api/memory/:terminal_id")
+	{
+		group.GET("/conversation", m.Controller.ListConversations)
+		group.GET("/conversation/export", m.Controller.ExportConversation)
+		group.DELETE("/conversation", m.Controller.ClearConversation)
+		group.GET("/facts", m.Controller.ListFacts)
+		group.DELETE("/facts/:fact_id", m.Controller.DeleteFact)
+	}
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"errors"
+	"sensio/domain/memory/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IConversationRepository defines the interface for conversation session and message storage
+type IConversationRepository interface {
+	GetOrCreateSession(terminalID, uid string) (*entities.ConversationSession, error)
+	GetSession(terminalID, uid string) (*entities.ConversationSession, error)
+	ListSessions(terminalID, uid string) ([]entities.ConversationSession, error)
+	UpdateSummary(sessionID, summary string) (bool, error)
+	AppendMessages(session *entities.ConversationSession, messages []entities.ConversationMessage) error
+	GetLatestMessages(sessionID string, limit int) ([]entities.ConversationMessage, error)
+	GetMessages(sessionID string) ([]entities.ConversationMessage, error)
+	GetUnsummarizedMessages(sessionID string) ([]entities.ConversationMessage, error)
+	MarkSummarized(sessionID string, uptoSeq int) error
+	DeleteSessions(terminalID, uid string) (int64, error)
+}
+
+// ConversationRepository handles persistent storage of conversations using GORM
+type ConversationRepository struct {
+	db *gorm.DB
+}
+
+// NewConversationRepository creates a new instance of ConversationRepository
+func NewConversationRepository(db *gorm.DB) *ConversationRepository {
+	return &ConversationRepository{db: db}
+}
+
+// GetOrCreateSession returns the session of a user on a terminal, creating it on first use
+func (r *ConversationRepository) GetOrCreateSession(terminalID, uid string) (*entities.ConversationSession, error) {
+	session, err := r.GetSession(terminalID, uid)
+	if err == nil {
+		return session, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	session = &entities.ConversationSession{ID: uuid.New().String(), TerminalID: terminalID, UID: uid}
+	if err := r.db.Create(session).Error; err != nil {
+		// Lost a race against a concurrent first message
+		if existing, getErr := r.GetSession(terminalID, uid); getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetSession retrieves the session of a user on a terminal
+func (r *ConversationRepository) GetSession(terminalID, uid string) (*entities.ConversationSession, error) {
+	var session entities.ConversationSession
+	if err := r.db.Where("terminal_id = ? AND uid = ?", terminalID, uid).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions retrieves the sessions of a terminal, most recently active first. An empty uid lists every user.
+func (r *ConversationRepository) ListSessions(terminalID, uid string) ([]entities.ConversationSession, error) {
+	var sessions []entities.ConversationSession
+	query := r.db.Where("terminal_id = ?", terminalID)
+	if uid != "" {
+		query = query.Where("uid = ?", uid)
+	}
+	err := query.Order("updated_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// UpdateSummary replaces the summary of a session without touching its other columns, so a
+// message count raised meanwhile is kept. It reports false when the session no longer exists.
+func (r *ConversationRepository) UpdateSummary(sessionID, summary string) (bool, error) {
+	result := r.db.Model(&entities.ConversationSession{}).Where("id = ?", sessionID).Update("summary", summary)
+	return result.RowsAffected > 0, result.Error
+}
+
+// AppendMessages numbers the messages after the last one of the session and stores them atomically
+func (r *ConversationRepository) AppendMessages(session *entities.ConversationSession, messages []entities.ConversationMessage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current entities.ConversationSession
+		if err := tx.Where("id = ?", session.ID).First(&current).Error; err != nil {
+			return err
+		}
+		for i := range messages {
+			if messages[i].ID == "" {
+				messages[i].ID = uuid.New().String()
+			}
+			messages[i].SessionID = session.ID
+			messages[i].Seq = current.MessageCount + i + 1
+		}
+		if err := tx.Create(&messages).Error; err != nil {
+			return err
+		}
+		current.MessageCount += len(messages)
+		if err := tx.Model(&current).Update("message_count", current.MessageCount).Error; err != nil {
+			return err
+		}
+		session.MessageCount = current.MessageCount
+		return nil
+	})
+}
+
+// GetLatestMessages retrieves the last limit messages of a session in conversation order
+func (r *ConversationRepository) GetLatestMessages(sessionID string, limit int) ([]entities.ConversationMessage, error) {
+	var messages []entities.ConversationMessage
+	if err := r.db.Where("session_id = ?", sessionID).Order("seq DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// GetMessages retrieves every message of a session in conversation order
+func (r *ConversationRepository) GetMessages(sessionID string) ([]entities.ConversationMessage, error) {
+	var messages []entities.ConversationMessage
+	err := r.db.Where("session_id = ?", sessionID).Order("seq ASC").Find(&messages).Error
+	return messages, err
+}
+
+// GetUnsummarizedMessages retrieves the messages not yet folded into the session summary
+func (r *ConversationRepository) GetUnsummarizedMessages(sessionID string) ([]entities.ConversationMessage, error) {
+	var messages []entities.ConversationMessage
+	err := r.db.Where("session_id = ? AND summarized = ?", sessionID, false).Order("seq ASC").Find(&messages).Error
+	return messages, err
+}
+
+// MarkSummarized flags the messages up to uptoSeq as folded into the summary
+func (r *ConversationRepository) MarkSummarized(sessionID string, uptoSeq int) error {
+	return r.db.Model(&entities.ConversationMessage{}).
+		Where("session_id = ? AND seq <= ?", sessionID, uptoSeq).
+		Update("summarized", true).Error
+}
+
+// DeleteSessions removes the sessions of a terminal with their messages and returns the number of
+// messages removed. An empty uid clears every user of the terminal.
+func (r *ConversationRepository) DeleteSessions(terminalID, uid string) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		sessionIDs := tx.Model(&entities.ConversationSession{}).Select("id").Where("terminal_id = ?", terminalID)
+		if uid != "" {
+			sessionIDs = sessionIDs.Where("uid = ?", uid)
+		}
+		result := tx.Where("session_id IN (?)", sessionIDs).Delete(&entities.ConversationMessage{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		sessions := tx.Where("terminal_id = ?", terminalID)
+		if uid != "" {
+			sessions = sessions.Where("uid = ?", uid)
+		}
+		return sessions.Delete(&entities.ConversationSession{}).Error
+	})
+	return deleted, err
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"errors"
+	"sensio/domain/memory/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IMemoryFactRepository defines the interface for remembered fact storage operations
+type IMemoryFactRepository interface {
+	Upsert(fact *entities.MemoryFact) error
+	List(terminalID, uid string) ([]entities.MemoryFact, error)
+	Delete(terminalID, id string) error
+	DeleteAll(terminalID, uid string) (int64, error)
+	Trim(terminalID, uid string, keep int) error
+}
+
+// MemoryFactRepository handles persistent storage of remembered facts using GORM
+type MemoryFactRepository struct {
+	db *gorm.DB
+}
+
+// NewMemoryFactRepository creates a new instance of MemoryFactRepository
+func NewMemoryFactRepository(db *gorm.DB) *MemoryFactRepository {
+	return &MemoryFactRepository{db: db}
+}
+
+// Upsert stores a fact, replacing the value of an existing fact with the same key
+func (r *MemoryFactRepository) Upsert(fact *entities.MemoryFact) error {
+	var existing entities.MemoryFact
+	err := r.db.Where(map[string]interface{}{"terminal_id": fact.TerminalID, "uid": fact.UID, "fact_key": fact.Key}).First(&existing).Error
+	switch {
+	case err == nil:
+		fact.ID = existing.ID
+		fact.CreatedAt = existing.CreatedAt
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		fact.ID = uuid.New().String()
+	default:
+		return err
+	}
+	return r.db.Save(fact).Error
+}
+
+// List retrieves the facts of a terminal, most recently updated first. An empty uid lists every user.
+func (r *MemoryFactRepository) List(terminalID, uid string) ([]entities.MemoryFact, error) {
+	var facts []entities.MemoryFact
+	query := r.db.Where("terminal_id = ?", terminalID)
+	if uid != "" {
+		query = query.Where("uid = ?", uid)
+	}
+	err := query.Order("updated_at DESC").Find(&facts).Error
+	return facts, err
+}
+
+// Delete removes one fact of a terminal
+func (r *MemoryFactRepository) Delete(terminalID, id string) error {
+	result := r.db.Where("id = ? AND terminal_id = ?", id, terminalID).Delete(&entities.MemoryFact{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteAll removes the facts of a terminal; an empty uid clears every user
+func (r *MemoryFactRepository) DeleteAll(terminalID, uid string) (int64, error) {
+	query := r.db.Where("terminal_id = ?", terminalID)
+	if uid != "" {
+		query = query.Where("uid = ?", uid)
+	}
+	result := query.Delete(&entities.MemoryFact{})
+	return result.RowsAffected, result.Error
+}
+
+// Trim keeps the keep most recently updated facts of a user on a terminal and removes the rest
+func (r *MemoryFactRepository) Trim(terminalID, uid string, keep int) error {
+	var ids []string
+	err := r.db.Model(&entities.MemoryFact{}).
+		Where("terminal_id = ? AND uid = ?", terminalID, uid).
+		Order("updated_at DESC").Pluck("id", &ids).Error
+	if err != nil || len(ids) <= keep {
+		return err
+	}
+	return r.db.Where("id IN ?", ids[keep:]).Delete(&entities.MemoryFact{}).Error
+}
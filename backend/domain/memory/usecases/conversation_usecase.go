@@ -0,0 +1,216 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/memory/dtos"
+	"sensio/domain/memory/entities"
+	"sensio/domain/memory/repositories"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultConversationLimit = 50
+	maxConversationLimit     = 500
+)
+
+// Export formats
+const (
+	ExportFormatJSON = "json"
+	ExportFormatText = "txt"
+)
+
+var ErrFactNotFound = errors.New("memory fact not found")
+
+// ConversationExport is a rendered export ready to be downloaded
+type ConversationExport struct {
+	Content     []byte
+	ContentType string
+	FileName    string
+}
+
+// ConversationUseCase lists, exports and clears what the assistant remembers of a terminal
+type ConversationUseCase struct {
+	conversations repositories.IConversationRepository
+	facts         repositories.IMemoryFactRepository
+}
+
+func NewConversationUseCase(conversations repositories.IConversationRepository, facts repositories.IMemoryFactRepository) *ConversationUseCase {
+	return &ConversationUseCase{
+		conversations: conversations,
+		facts:         facts,
+	}
+}
+
+// ListConversations returns the sessions of a terminal with their last limit messages.
+// An empty uid lists every user of the terminal.
+func (uc *ConversationUseCase) ListConversations(terminalID, uid string, limit int) ([]dtos.ConversationDTO, error) {
+	if limit == 0 {
+		limit = defaultConversationLimit
+	}
+	if limit < 0 || limit > maxConversationLimit {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", maxConversationLimit)},
+		})
+	}
+
+	sessions, err := uc.conversations.ListSessions(terminalID, uid)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.ConversationDTO, 0, len(sessions))
+	for _, session := range sessions {
+		messages, err := uc.conversations.GetLatestMessages(session.ID, limit)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, toConversationDTO(session, messages))
+	}
+	return result, nil
+}
+
+// ExportConversation renders every message, summary and fact of a terminal as JSON or plain text
+func (uc *ConversationUseCase) ExportConversation(terminalID, uid, format string) (*ConversationExport, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = ExportFormatJSON
+	}
+	if format != ExportFormatJSON && format != ExportFormatText {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "format", Message: "format must be json or txt"},
+		})
+	}
+
+	sessions, err := uc.conversations.ListSessions(terminalID, uid)
+	if err != nil {
+		return nil, err
+	}
+	export := dtos.ConversationExportDTO{
+		TerminalID:    terminalID,
+		ExportedAt:    time.Now().UTC().Format(time.RFC3339),
+		Conversations: make([]dtos.ConversationDTO, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		messages, err := uc.conversations.GetMessages(session.ID)
+		if err != nil {
+			return nil, err
+		}
+		export.Conversations = append(export.Conversations, toConversationDTO(session, messages))
+	}
+	if export.Facts, err = uc.ListFacts(terminalID, uid); err != nil {
+		return nil, err
+	}
+
+	fileName := fmt.Sprintf("conversation_%s_%s.%s", terminalID, time.Now().UTC().Format("20060102"), format)
+	if format == ExportFormatText {
+		return &ConversationExport{Content: []byte(renderTranscript(export)), ContentType: "text/plain; charset=utf-8", FileName: fileName}, nil
+	}
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &ConversationExport{Content: content, ContentType: "application/json", FileName: fileName}, nil
+}
+
+// ClearConversation forgets the conversations of a terminal (one user when uid is set), and its
+// remembered facts too when includeFacts is set
+func (uc *ConversationUseCase) ClearConversation(terminalID, uid string, includeFacts bool) (*dtos.ClearConversationResultDTO, error) {
+	result := &dtos.ClearConversationResultDTO{TerminalID: terminalID}
+
+	deleted, err := uc.conversations.DeleteSessions(terminalID, uid)
+	if err != nil {
+		return nil, err
+	}
+	result.MessagesDeleted = deleted
+
+	if includeFacts {
+		if result.FactsDeleted, err = uc.facts.DeleteAll(terminalID, uid); err != nil {
+			return nil, err
+		}
+	}
+	utils.LogInfo("ConversationUseCase: Memory cleared | terminal=%s | uid=%s | messages=%d | facts=%d", terminalID, uid, result.MessagesDeleted, result.FactsDeleted)
+	return result, nil
+}
+
+// ListFacts returns the remembered facts of a terminal; an empty uid lists every user
+func (uc *ConversationUseCase) ListFacts(terminalID, uid string) ([]dtos.MemoryFactDTO, error) {
+	facts, err := uc.facts.List(terminalID, uid)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.MemoryFactDTO, 0, len(facts))
+	for _, f := range facts {
+		result = append(result, dtos.MemoryFactDTO{
+			ID:         f.ID,
+			TerminalID: f.TerminalID,
+			UID:        f.UID,
+			Key:        f.Key,
+			Value:      f.Value,
+			Source:     f.Source,
+			UpdatedAt:  f.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	return result, nil
+}
+
+// DeleteFact forgets one fact of a terminal
+func (uc *ConversationUseCase) DeleteFact(terminalID, id string) error {
+	if err := uc.facts.Delete(terminalID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFactNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func toConversationDTO(session entities.ConversationSession, messages []entities.ConversationMessage) dtos.ConversationDTO {
+	dto := dtos.ConversationDTO{
+		SessionID:    session.ID,
+		TerminalID:   session.TerminalID,
+		UID:          session.UID,
+		Summary:      session.Summary,
+		MessageCount: session.MessageCount,
+		Messages:     make([]dtos.ConversationMessageDTO, 0, len(messages)),
+		UpdatedAt:    session.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, m := range messages {
+		dto.Messages = append(dto.Messages, dtos.ConversationMessageDTO{
+			Seq:       m.Seq,
+			Role:      m.Role,
+			Content:   m.Content,
+			CreatedAt: m.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return dto
+}
+
+func renderTranscript(export dtos.ConversationExportDTO) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Conversation export for terminal %s (%s)\n", export.TerminalID, export.ExportedAt)
+	for _, c := range export.Conversations {
+		fmt.Fprintf(&b, "\n== User %s (%d messages) ==\n", c.UID, c.MessageCount)
+		if c.Summary != "" {
+			fmt.Fprintf(&b, "Summary: %s\n\n", c.Summary)
+		}
+		for _, m := range c.Messages {
+			role := "User"
+			if m.Role == entities.RoleAssistant {
+				role = "Assistant"
+			}
+			fmt.Fprintf(&b, "[%s] %s: %s\n", m.CreatedAt, role, m.Content)
+		}
+	}
+	if len(export.Facts) > 0 {
+		b.WriteString("\n== Remembered facts ==\n")
+		for _, f := range export.Facts {
+			fmt.Fprintf(&b, "- %s: %s\n", f.Key, f.Value)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package usecases
+
+import (
+	"regexp"
+	"sensio/domain/memory/entities"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxFactKeyLength   = 100
+	maxFactValueLength = 500
+)
+
+var (
+	// "I like 24 degrees", "saya biasanya pakai 23 derajat"
+	temperaturePreferencePattern = regexp.MustCompile(`(?i)\b(?:i\s+(?:like|prefer|usually)|i'd\s+like|my\s+(?:usual|favou?rite|preferred)|saya\s+(?:suka|lebih\s+suka|biasanya)|aku\s+(?:suka|lebih\s+suka|biasanya))\b.*?(\d{2}(?:[.,]\d)?)\s*(?:°\s*c?|degrees?|derajat|celsius)`)
+	// "remember that my daughter sleeps at 8pm", "tolong ingat bahwa ..."
+	rememberPattern = regexp.MustCompile(`(?i)^(?:please\s+|tolong\s+)?(?:remember(?:\s+that)?|ingat(?:lah)?(?:\s+bahwa)?|catat(?:\s+bahwa)?)[\s,:]+(.{3,})$`)
+	// "I prefer warm white light", "saya tidak suka lampu terlalu terang"
+	preferencePattern = regexp.MustCompile(`(?i)^(?:i\s+(?:really\s+)?(?:like|love|prefer|hate|don't\s+like)|saya\s+(?:sangat\s+)?(?:suka|lebih\s+suka|tidak\s+suka|benci)|aku\s+(?:suka|lebih\s+suka|tidak\s+suka|benci))\s+(.{2,})$`)
+
+	factKeyInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// ExtractStatementFacts finds facts the user states explicitly in a prompt. Anything subtler is
+// left to the LLM summariser, which sees the whole conversation.
+func ExtractStatementFacts(prompt string) []entities.MemoryFact {
+	statement := strings.TrimRight(strings.TrimSpace(prompt), ".!?")
+	if statement == "" {
+		return nil
+	}
+
+	if m := temperaturePreferencePattern.FindStringSubmatch(statement); m != nil {
+		return []entities.MemoryFact{{
+			Key:    "preferred_temperature",
+			Value:  strings.ReplaceAll(m[1], ",", ".") + "°C",
+			Source: entities.FactSourceStatement,
+		}}
+	}
+	if m := rememberPattern.FindStringSubmatch(statement); m != nil {
+		return []entities.MemoryFact{newStatementFact("note", m[1], m[1])}
+	}
+	if m := preferencePattern.FindStringSubmatch(statement); m != nil {
+		return []entities.MemoryFact{newStatementFact("preference", m[1], statement)}
+	}
+	return nil
+}
+
+func newStatementFact(prefix, subject, value string) entities.MemoryFact {
+	return entities.MemoryFact{
+		Key:    NormalizeFactKey(prefix + " " + firstWords(subject, 5)),
+		Value:  truncate(strings.TrimSpace(value), maxFactValueLength),
+		Source: entities.FactSourceStatement,
+	}
+}
+
+// NormalizeFactKey turns a free-form key into snake_case, the form facts are unique by
+func NormalizeFactKey(key string) string {
+	key = factKeyInvalidChars.ReplaceAllString(strings.ToLower(key), "_")
+	return strings.Trim(truncate(key, maxFactKeyLength), "_")
+}
+
+func firstWords(s string, n int) string {
+	words := strings.Fields(s)
+	if len(words) > n {
+		words = words[:n]
+	}
+	return strings.Join(words, " ")
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	// Do not split a multi-byte character
+	end := max
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
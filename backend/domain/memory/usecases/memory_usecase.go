@@ -0,0 +1,286 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/memory/dtos"
+	"sensio/domain/memory/entities"
+	"sensio/domain/memory/repositories"
+	"sensio/domain/models/rag/skills"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// summarizeTimeout bounds a background summarisation; it runs after the user got the answer
+const summarizeTimeout = 90 * time.Second
+
+// MemoryUseCase gives the assistant a long-term memory per user and terminal: the recent turns
+// verbatim, a rolling LLM summary of everything older, and remembered facts/preferences.
+type MemoryUseCase struct {
+	conversations repositories.IConversationRepository
+	facts         repositories.IMemoryFactRepository
+	recentTurns   int
+	summaryBatch  int
+	maxFacts      int
+
+	summarizing sync.Map // session ID -> struct{}, one summarisation per session at a time
+}
+
+func NewMemoryUseCase(conversations repositories.IConversationRepository, facts repositories.IMemoryFactRepository, recentTurns, summaryBatch, maxFacts int) *MemoryUseCase {
+	if recentTurns <= 0 {
+		recentTurns = 5
+	}
+	if summaryBatch <= 0 {
+		summaryBatch = 10
+	}
+	if maxFacts <= 0 {
+		maxFacts = 30
+	}
+	return &MemoryUseCase{
+		conversations: conversations,
+		facts:         facts,
+		recentTurns:   recentTurns,
+		summaryBatch:  summaryBatch,
+		maxFacts:      maxFacts,
+	}
+}
+
+// Recall returns what the assistant remembers of a user on a terminal
+func (uc *MemoryUseCase) Recall(terminalID, uid string) (*dtos.RecallDTO, error) {
+	recall := &dtos.RecallDTO{}
+
+	facts, err := uc.userFacts(terminalID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := uc.conversations.GetSession(terminalID, uid)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		session = nil
+	case err != nil:
+		return nil, err
+	default:
+		messages, err := uc.conversations.GetLatestMessages(session.ID, uc.recentTurns*2)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range messages {
+			recall.History = append(recall.History, formatTurn(m))
+		}
+	}
+
+	var memory strings.Builder
+	if session != nil && session.Summary != "" {
+		memory.WriteString("Summary of earlier conversation: " + session.Summary)
+	}
+	if len(facts) > 0 {
+		if memory.Len() > 0 {
+			memory.WriteString("\n")
+		}
+		memory.WriteString("Known facts about the user:")
+		for _, f := range facts {
+			memory.WriteString(fmt.Sprintf("\n- %s: %s", f.Key, f.Value))
+		}
+	}
+	recall.Context = memory.String()
+	return recall, nil
+}
+
+// Remember stores a completed exchange and the facts stated in it. Once enough turns fell out of
+// the recent window they are summarised with llm in the background.
+func (uc *MemoryUseCase) Remember(ctx context.Context, llm skills.LLMClient, terminalID, uid, prompt, response string) error {
+	session, err := uc.conversations.GetOrCreateSession(terminalID, uid)
+	if err != nil {
+		return err
+	}
+
+	messages := []entities.ConversationMessage{{Role: entities.RoleUser, Content: prompt}}
+	if response != "" {
+		messages = append(messages, entities.ConversationMessage{Role: entities.RoleAssistant, Content: response})
+	}
+	if err := uc.conversations.AppendMessages(session, messages); err != nil {
+		return err
+	}
+
+	for _, fact := range ExtractStatementFacts(prompt) {
+		fact.TerminalID, fact.UID = terminalID, uid
+		if err := uc.facts.Upsert(&fact); err != nil {
+			utils.LogWarn("MemoryUseCase: Failed to store fact %s for terminal %s: %v", fact.Key, terminalID, err)
+		}
+	}
+	if err := uc.facts.Trim(terminalID, uid, uc.maxFacts); err != nil {
+		utils.LogWarn("MemoryUseCase: Failed to trim facts for terminal %s: %v", terminalID, err)
+	}
+
+	if llm != nil {
+		go uc.summarize(session.ID, terminalID, uid, llm)
+	}
+	return nil
+}
+
+// summarize folds the unsummarised messages outside the recent window into the session summary
+// when there are at least summaryBatch of them
+func (uc *MemoryUseCase) summarize(sessionID, terminalID, uid string, llm skills.LLMClient) {
+	if _, busy := uc.summarizing.LoadOrStore(sessionID, struct{}{}); busy {
+		return
+	}
+	defer uc.summarizing.Delete(sessionID)
+
+	pending, err := uc.conversations.GetUnsummarizedMessages(sessionID)
+	if err != nil {
+		utils.LogWarn("MemoryUseCase: Failed to load messages of session %s: %v", sessionID, err)
+		return
+	}
+	older := len(pending) - uc.recentTurns*2
+	if older < uc.summaryBatch {
+		return
+	}
+	pending = pending[:older]
+
+	session, err := uc.conversations.GetSession(terminalID, uid)
+	if err != nil {
+		utils.LogWarn("MemoryUseCase: Failed to load session %s: %v", sessionID, err)
+		return
+	}
+	facts, err := uc.userFacts(terminalID, uid)
+	if err != nil {
+		utils.LogWarn("MemoryUseCase: Failed to load facts of session %s: %v", sessionID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), summarizeTimeout)
+	defer cancel()
+	start := time.Now()
+	raw, err := llm.CallModel(ctx, buildSummaryPrompt(session.Summary, facts, pending), "low")
+	if err != nil {
+		utils.LogWarn("MemoryUseCase: Summarisation of session %s failed: %v", sessionID, err)
+		return
+	}
+	result, err := parseSummaryResponse(raw)
+	if err != nil {
+		utils.LogWarn("MemoryUseCase: Summarisation of session %s returned invalid JSON: %v", sessionID, err)
+		return
+	}
+
+	// Only the summary is written: the user may have chatted, or cleared the conversation, while the model ran
+	updated, err := uc.conversations.UpdateSummary(sessionID, result.Summary)
+	if err != nil {
+		utils.LogWarn("MemoryUseCase: Failed to save summary of session %s: %v", sessionID, err)
+		return
+	}
+	if !updated {
+		utils.LogInfo("MemoryUseCase: Session %s was cleared during summarisation, summary discarded", sessionID)
+		return
+	}
+	if err := uc.conversations.MarkSummarized(sessionID, pending[len(pending)-1].Seq); err != nil {
+		utils.LogWarn("MemoryUseCase: Failed to mark messages of session %s as summarised: %v", sessionID, err)
+		return
+	}
+	for _, f := range result.Facts {
+		fact := entities.MemoryFact{
+			TerminalID: terminalID,
+			UID:        uid,
+			Key:        NormalizeFactKey(f.Key),
+			Value:      truncate(strings.TrimSpace(f.Value), maxFactValueLength),
+			Source:     entities.FactSourceSummary,
+		}
+		if fact.Key == "" || fact.Value == "" {
+			continue
+		}
+		if err := uc.facts.Upsert(&fact); err != nil {
+			utils.LogWarn("MemoryUseCase: Failed to store fact %s for terminal %s: %v", fact.Key, terminalID, err)
+		}
+	}
+	if err := uc.facts.Trim(terminalID, uid, uc.maxFacts); err != nil {
+		utils.LogWarn("MemoryUseCase: Failed to trim facts for terminal %s: %v", terminalID, err)
+	}
+
+	utils.LogInfo("MemoryUseCase: Session %s summarised | messages=%d | facts=%d | duration_ms=%d", sessionID, len(pending), len(result.Facts), time.Since(start).Milliseconds())
+}
+
+// userFacts returns the facts of exactly one user; the repository treats an empty uid as "every user"
+func (uc *MemoryUseCase) userFacts(terminalID, uid string) ([]entities.MemoryFact, error) {
+	facts, err := uc.facts.List(terminalID, uid)
+	if err != nil || uid != "" {
+		return facts, err
+	}
+	own := facts[:0]
+	for _, f := range facts {
+		if f.UID == "" {
+			own = append(own, f)
+		}
+	}
+	return own, nil
+}
+
+type summaryResult struct {
+	Summary string `json:"summary"`
+	Facts   []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"facts"`
+}
+
+func buildSummaryPrompt(summary string, facts []entities.MemoryFact, messages []entities.ConversationMessage) string {
+	if summary == "" {
+		summary = "(none)"
+	}
+	knownFacts := "(none)"
+	if len(facts) > 0 {
+		lines := make([]string, 0, len(facts))
+		for _, f := range facts {
+			lines = append(lines, fmt.Sprintf("- %s: %s", f.Key, f.Value))
+		}
+		knownFacts = strings.Join(lines, "\n")
+	}
+	turns := make([]string, 0, len(messages))
+	for _, m := range messages {
+		turns = append(turns, formatTurn(m))
+	}
+
+	return fmt.Sprintf(`You maintain the long-term memory of Sensio, a smart home assistant, for one user.
+
+Current summary:
+%s
+
+Known facts:
+%s
+
+Conversation turns to fold into the summary:
+%s
+
+Rewrite the summary so it keeps what is still useful for future conversations (topics, decisions, open requests) in at most 120 words, in the language of the conversation.
+Extract lasting facts and preferences about the user or the home (preferred temperature, names, routines) with snake_case keys; reuse a known key to update its value. Ignore one-off device commands.
+
+Output ONLY JSON: {"summary":"...","facts":[{"key":"preferred_temperature","value":"24°C"}]}`, summary, knownFacts, strings.Join(turns, "\n"))
+}
+
+func parseSummaryResponse(raw string) (*summaryResult, error) {
+	start, end := strings.Index(raw, "{"), strings.LastIndex(raw, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no JSON object in response")
+	}
+	var result summaryResult
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &result); err != nil {
+		return nil, err
+	}
+	result.Summary = strings.TrimSpace(result.Summary)
+	if result.Summary == "" {
+		return nil, fmt.Errorf("empty summary")
+	}
+	return &result, nil
+}
+
+func formatTurn(m entities.ConversationMessage) string {
+	if m.Role == entities.RoleAssistant {
+		return "Assistant: " + m.Content
+	}
+	return "User: " + m.Content
+}
@@ -0,0 +1,285 @@
+package usecases
+
+import (
+	"context"
+	"sensio/domain/memory/entities"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeConversations keeps sessions and messages in memory
+type fakeConversations struct {
+	sessions map[string]*entities.ConversationSession
+	messages map[string][]entities.ConversationMessage
+}
+
+func newFakeConversations() *fakeConversations {
+	return &fakeConversations{sessions: map[string]*entities.ConversationSession{}, messages: map[string][]entities.ConversationMessage{}}
+}
+
+func (r *fakeConversations) GetOrCreateSession(terminalID, uid string) (*entities.ConversationSession, error) {
+	if s, err := r.GetSession(terminalID, uid); err == nil {
+		return s, nil
+	}
+	s := &entities.ConversationSession{ID: terminalID + "/" + uid, TerminalID: terminalID, UID: uid}
+	r.sessions[s.ID] = s
+	return s, nil
+}
+
+func (r *fakeConversations) GetSession(terminalID, uid string) (*entities.ConversationSession, error) {
+	if s, ok := r.sessions[terminalID+"/"+uid]; ok {
+		copied := *s
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeConversations) ListSessions(terminalID, uid string) ([]entities.ConversationSession, error) {
+	var out []entities.ConversationSession
+	for _, s := range r.sessions {
+		if s.TerminalID == terminalID && (uid == "" || s.UID == uid) {
+			out = append(out, *s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UID < out[j].UID })
+	return out, nil
+}
+
+func (r *fakeConversations) UpdateSummary(sessionID, summary string) (bool, error) {
+	s, ok := r.sessions[sessionID]
+	if !ok {
+		return false, nil
+	}
+	s.Summary = summary
+	return true, nil
+}
+
+func (r *fakeConversations) AppendMessages(session *entities.ConversationSession, messages []entities.ConversationMessage) error {
+	stored := r.sessions[session.ID]
+	for _, m := range messages {
+		stored.MessageCount++
+		m.SessionID, m.Seq = session.ID, stored.MessageCount
+		r.messages[session.ID] = append(r.messages[session.ID], m)
+	}
+	session.MessageCount = stored.MessageCount
+	return nil
+}
+
+func (r *fakeConversations) GetLatestMessages(sessionID string, limit int) ([]entities.ConversationMessage, error) {
+	all := r.messages[sessionID]
+	if len(all) > limit {
+		all = all[len(all)-limit:]
+	}
+	return append([]entities.ConversationMessage(nil), all...), nil
+}
+
+func (r *fakeConversations) GetMessages(sessionID string) ([]entities.ConversationMessage, error) {
+	return append([]entities.ConversationMessage(nil), r.messages[sessionID]...), nil
+}
+
+func (r *fakeConversations) GetUnsummarizedMessages(sessionID string) ([]entities.ConversationMessage, error) {
+	var out []entities.ConversationMessage
+	for _, m := range r.messages[sessionID] {
+		if !m.Summarized {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeConversations) MarkSummarized(sessionID string, uptoSeq int) error {
+	for i := range r.messages[sessionID] {
+		if r.messages[sessionID][i].Seq <= uptoSeq {
+			r.messages[sessionID][i].Summarized = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeConversations) DeleteSessions(terminalID, uid string) (int64, error) {
+	var deleted int64
+	for id, s := range r.sessions {
+		if s.TerminalID == terminalID && (uid == "" || s.UID == uid) {
+			deleted += int64(len(r.messages[id]))
+			delete(r.messages, id)
+			delete(r.sessions, id)
+		}
+	}
+	return deleted, nil
+}
+
+// fakeFacts keeps facts in memory in insertion order
+type fakeFacts struct {
+	facts []entities.MemoryFact
+}
+
+func (r *fakeFacts) Upsert(fact *entities.MemoryFact) error {
+	for i, f := range r.facts {
+		if f.TerminalID == fact.TerminalID && f.UID == fact.UID && f.Key == fact.Key {
+			r.facts = append(r.facts[:i], r.facts[i+1:]...)
+			break
+		}
+	}
+	r.facts = append(r.facts, *fact)
+	return nil
+}
+
+func (r *fakeFacts) List(terminalID, uid string) ([]entities.MemoryFact, error) {
+	var out []entities.MemoryFact
+	for i := len(r.facts) - 1; i >= 0; i-- {
+		if f := r.facts[i]; f.TerminalID == terminalID && (uid == "" || f.UID == uid) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeFacts) Delete(terminalID, id string) error { return nil }
+
+func (r *fakeFacts) DeleteAll(terminalID, uid string) (int64, error) { return 0, nil }
+
+func (r *fakeFacts) Trim(terminalID, uid string, keep int) error {
+	if len(r.facts) > keep {
+		r.facts = r.facts[len(r.facts)-keep:]
+	}
+	return nil
+}
+
+// summaryLLM answers every summarisation with a fixed JSON and records the prompt
+type summaryLLM struct {
+	prompt string
+}
+
+func (l *summaryLLM) CallModel(_ context.Context, prompt string, _ string) (string, error) {
+	l.prompt = prompt
+	return "```json\n{\"summary\":\"User asked about the weather and dinner plans.\",\"facts\":[{\"key\":\"Daughter Name\",\"value\":\"Sinta\"}]}\n```", nil
+}
+
+// interruptingLLM runs interrupt while the summary is being generated
+type interruptingLLM struct {
+	summaryLLM
+	interrupt func()
+}
+
+func (l *interruptingLLM) CallModel(ctx context.Context, prompt string, effort string) (string, error) {
+	l.interrupt()
+	return l.summaryLLM.CallModel(ctx, prompt, effort)
+}
+
+func TestExtractStatementFacts(t *testing.T) {
+	cases := []struct {
+		prompt string
+		key    string
+		value  string
+	}{
+		{"I like the AC at 24 degrees.", "preferred_temperature", "24°C"},
+		{"saya biasanya pakai suhu 23,5 derajat", "preferred_temperature", "23.5°C"},
+		{"Remember that my daughter sleeps at 8pm", "note_my_daughter_sleeps_at_8pm", "my daughter sleeps at 8pm"},
+		{"saya lebih suka lampu warna hangat", "preference_lampu_warna_hangat", "saya lebih suka lampu warna hangat"},
+		{"nyalakan AC 24 derajat", "", ""},
+	}
+	for _, tc := range cases {
+		facts := ExtractStatementFacts(tc.prompt)
+		if tc.key == "" {
+			assert.Empty(t, facts, tc.prompt)
+			continue
+		}
+		require.Len(t, facts, 1, tc.prompt)
+		assert.Equal(t, tc.key, facts[0].Key, tc.prompt)
+		assert.Equal(t, tc.value, facts[0].Value, tc.prompt)
+	}
+}
+
+func TestMemory_RecallsRecentTurnsSummaryAndFacts(t *testing.T) {
+	conversations, facts := newFakeConversations(), &fakeFacts{}
+	uc := NewMemoryUseCase(conversations, facts, 2, 4, 10)
+	ctx := context.Background()
+
+	require.NoError(t, uc.Remember(ctx, nil, "term-1", "sg-alice", "I like 24 degrees", "Noted, 24°C it is."))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, uc.Remember(ctx, nil, "term-1", "sg-alice", "question", "answer"))
+	}
+	require.NoError(t, uc.Remember(ctx, nil, "term-1", "sg-bob", "hello", "hi Bob"))
+
+	// Only the recent window is passed verbatim, and users never see each other's turns
+	recall, err := uc.Recall("term-1", "sg-alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"User: question", "Assistant: answer", "User: question", "Assistant: answer"}, recall.History)
+	assert.Contains(t, recall.Context, "preferred_temperature: 24°C")
+
+	// 10 messages, 4 kept verbatim: the 6 older ones are folded into the summary
+	llm := &summaryLLM{}
+	uc.summarize("term-1/sg-alice", "term-1", "sg-alice", llm)
+	assert.Contains(t, llm.prompt, "User: I like 24 degrees")
+	pending, _ := conversations.GetUnsummarizedMessages("term-1/sg-alice")
+	assert.Len(t, pending, 4)
+
+	recall, err = uc.Recall("term-1", "sg-alice")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(recall.Context, "Summary of earlier conversation: User asked about the weather"))
+	assert.Contains(t, recall.Context, "daughter_name: Sinta")
+	assert.Len(t, recall.History, 4)
+
+	// Below the batch size nothing is summarised again
+	llm.prompt = ""
+	uc.summarize("term-1/sg-alice", "term-1", "sg-alice", llm)
+	assert.Empty(t, llm.prompt)
+
+	bob, err := uc.Recall("term-1", "sg-bob")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"User: hello", "Assistant: hi Bob"}, bob.History)
+	assert.Empty(t, bob.Context)
+}
+
+func TestMemory_SummaryKeepsMessagesAppendedMeanwhile(t *testing.T) {
+	conversations, facts := newFakeConversations(), &fakeFacts{}
+	uc := NewMemoryUseCase(conversations, facts, 1, 2, 10)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		require.NoError(t, uc.Remember(ctx, nil, "term-1", "sg-alice", "question", "answer"))
+	}
+
+	// The user keeps chatting while the older turns are summarised
+	llm := &interruptingLLM{interrupt: func() {
+		require.NoError(t, uc.Remember(ctx, nil, "term-1", "sg-alice", "late question", "late answer"))
+	}}
+	uc.summarize("term-1/sg-alice", "term-1", "sg-alice", llm)
+
+	session, err := conversations.GetSession("term-1", "sg-alice")
+	require.NoError(t, err)
+	assert.Equal(t, 6, session.MessageCount, "the message count raised meanwhile is kept")
+	assert.True(t, strings.HasPrefix(session.Summary, "User asked about the weather"))
+
+	require.NoError(t, uc.Remember(ctx, nil, "term-1", "sg-alice", "next", "reply"))
+	messages, _ := conversations.GetMessages("term-1/sg-alice")
+	seqs := make([]int, 0, len(messages))
+	for _, m := range messages {
+		seqs = append(seqs, m.Seq)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, seqs, "sequence numbers are never reused")
+	pending, _ := conversations.GetUnsummarizedMessages("term-1/sg-alice")
+	assert.Len(t, pending, 6, "only the summarised turns are flagged")
+}
+
+func TestMemory_SummaryOfClearedConversationIsDiscarded(t *testing.T) {
+	conversations, facts := newFakeConversations(), &fakeFacts{}
+	uc := NewMemoryUseCase(conversations, facts, 1, 2, 10)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, uc.Remember(context.Background(), nil, "term-1", "sg-alice", "question", "answer"))
+	}
+
+	llm := &interruptingLLM{interrupt: func() {
+		_, err := conversations.DeleteSessions("term-1", "sg-alice")
+		require.NoError(t, err)
+	}}
+	uc.summarize("term-1/sg-alice", "term-1", "sg-alice", llm)
+
+	_, err := conversations.GetSession("term-1", "sg-alice")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "the cleared session is not re-created")
+	assert.Empty(t, facts.facts, "facts of the cleared conversation are not stored")
+}
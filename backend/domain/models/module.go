@@ -9,6 +9,7 @@ import (
 	"sensio/domain/common/utils"
 	pipelineControllers "sensio/domain/models/pipeline/controllers"
 	pipelinedtos "sensio/domain/models/pipeline/dtos"
+	pipelineRepositories "sensio/domain/models/pipeline/repositories"
 	pipelineRoutes "sensio/domain/models/pipeline/routes"
 	pipelineUsecases "sensio/domain/models/pipeline/usecases"
 	ragControllers "sensio/domain/models/rag/controllers"
@@ -28,6 +29,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // providerResolverTerminalRepoWrapper adapts ITerminalRepository to providers.TerminalRepository
@@ -59,13 +61,19 @@ func (w *providerResolverTerminalRepoWrapper) GetByMacAddress(macAddress string)
 func InitModule(
 	protected *gin.RouterGroup,
 	cfg *utils.Config,
+	db *gorm.DB,
 	badger *infrastructure.BadgerService,
-	vectorSvc *infrastructure.VectorService,
+	vectorSvc infrastructure.VectorStore,
 	tuyaAuth tuyaUsecases.TuyaAuthUseCase,
 	tuyaExecutor tuyaUsecases.TuyaDeviceControlExecutor,
 	mqttSvc *infrastructure.MqttService,
+	mqttRouter *infrastructure.MqttRpcRouter,
 	terminalRepo terminalRepositories.ITerminalRepository,
 	saveRecordingUC recordingUsecases.SaveRecordingUseCase,
+	undoer ragUsecases.StateUndoer,
+	memory ragUsecases.ConversationMemory,
+	rooms ragUsecases.RoomController,
+	jobs *tasks.JobQueue,
 ) (whisperUsecases.TranscribeUseCase, whisperUsecases.UploadSessionUseCase, ragUsecases.RefineUseCase, ragUsecases.TranslateUseCase, ragUsecases.SummaryUseCase) {
 
 	// 1. Initialize RAG Sub-module
@@ -83,12 +91,18 @@ func InitModule(
 	// Create provider resolver for terminal-specific provider selection
 	// Wrap terminalRepo to match the interface expected by ProviderResolver
 	providerResolverRepo := &providerResolverTerminalRepoWrapper{terminalRepo}
+	// whisper.cpp is only selectable when a local model is configured
+	var whisperCpp providers.WhisperProvider
+	if cfg.WhisperLocalModel != "" {
+		whisperCpp = commonServices.NewWhisperCppService(cfg)
+	}
 	providerResolver := providers.NewProviderResolver(
 		cfg,
 		geminiService,
 		openaiService,
 		groqService,
 		orionService,
+		whisperCpp,
 		providerResolverRepo,
 	)
 
@@ -154,20 +168,20 @@ func InitModule(
 
 	// Note: fallbackLLM is nil - default flow uses health-aware remote provider chain
 	refineUC := ragUsecases.NewRefineUseCase(ragLlmClient, nil, cfg, refineSkill, providerResolver)
-	translateUC := ragUsecases.NewTranslateUseCase(ragLlmClient, nil, cfg, ragCache, ragStore, mqttSvc, translateSkill, providerResolver)
+	translateUC := ragUsecases.NewTranslateUseCase(ragLlmClient, nil, cfg, ragCache, ragStore, mqttSvc, translateSkill, providerResolver, jobs)
 	guardOrch := ragOrchestrator.NewGuardOrchestrator(guardSkill)
 	fastIntentRouter := ragOrchestrator.NewFastIntentRouter()
 	decisionEngine := ragOrchestrator.NewAssistantDecisionEngine(ragLlmClient)
 	router := ragOrchestrator.NewRouter(skillRegistry, translateUC, guardOrch)
 	pdfRenderer := ragServices.NewHTMLSummaryPDFRenderer()
 	bigExternalService := commonServices.NewDeviceInfoExternalService()
-	summaryUC := ragUsecases.NewSummaryUseCase(ragLlmClient, nil, cfg, ragCache, ragStore, pdfRenderer, bigExternalService, mqttSvc, summarySkill, chunkSkill, structuredExtractionSkill, providerResolver)
-	ragStatusUC := tasks.NewGenericStatusUseCase(ragCache, ragStore)
-	controlUC := ragUsecases.NewControlUseCase(ragLlmClient, nil, cfg, vectorSvc, badger, tuyaExecutor, tuyaAuth, controlSkill, providerResolver)
-	chatUC := ragUsecases.NewChatUseCase(ragLlmClient, nil, cfg, badger, vectorSvc, guardOrch, fastIntentRouter, decisionEngine, providerResolver, controlUC, router)
+	summaryUC := ragUsecases.NewSummaryUseCase(ragLlmClient, nil, cfg, ragCache, ragStore, pdfRenderer, bigExternalService, mqttSvc, summarySkill, chunkSkill, structuredExtractionSkill, providerResolver, jobs)
+	ragStatusUC := tasks.NewJobStatusUseCase(ragCache, ragStore, jobs)
+	controlUC := ragUsecases.NewControlUseCase(ragLlmClient, nil, cfg, vectorSvc, badger, tuyaExecutor, tuyaAuth, controlSkill, providerResolver, memory)
+	chatUC := ragUsecases.NewChatUseCase(ragLlmClient, nil, cfg, badger, vectorSvc, guardOrch, fastIntentRouter, decisionEngine, providerResolver, controlUC, router, undoer, memory, rooms)
 
 	chatController := ragControllers.NewRAGChatController(chatUC, mqttSvc, terminalRepo)
-	if err := chatController.StartMqttSubscription(); err != nil {
+	if err := chatController.RegisterMqttRoutes(mqttRouter); err != nil {
 		utils.LogError("RAG module MQTT subscription failed: %v", err)
 	}
 
@@ -197,14 +211,15 @@ func InitModule(
 	whisperCache := tasks.NewBadgerTaskCacheFromService(badger, "cache:transcribe:task:")
 	whisperStore := tasks.NewStatusStore[whisperDtos.AsyncTranscriptionStatusDTO]()
 
-	transcribeUC := whisperUsecases.NewTranscribeUseCase(defaultWhisperClient, refineUC, whisperStore, whisperCache, cfg, mqttSvc, providerResolver)
+	transcribeUC := whisperUsecases.NewTranscribeUseCase(defaultWhisperClient, refineUC, whisperStore, whisperCache, cfg, mqttSvc, providerResolver, jobs)
 	// Inject all provider services for health-aware fallback chain
-	geminiWhisperModelUC := whisperUsecases.NewTranscribeGeminiModelUseCase(geminiService, whisperStore, whisperCache, cfg)
-	openaiWhisperModelUC := whisperUsecases.NewTranscribeOpenAIModelUseCase(openaiService, whisperStore, whisperCache, cfg)
-	groqWhisperModelUC := whisperUsecases.NewTranscribeGroqModelUseCase(groqService, whisperStore, whisperCache, cfg)
-	orionWhisperModelUC := whisperUsecases.NewTranscribeOrionModelUseCase(orionService, whisperStore, whisperCache, cfg)
+	geminiWhisperModelUC := whisperUsecases.NewTranscribeGeminiModelUseCase(geminiService, whisperStore, whisperCache, cfg, jobs)
+	openaiWhisperModelUC := whisperUsecases.NewTranscribeOpenAIModelUseCase(openaiService, whisperStore, whisperCache, cfg, jobs)
+	groqWhisperModelUC := whisperUsecases.NewTranscribeGroqModelUseCase(groqService, whisperStore, whisperCache, cfg, jobs)
+	orionWhisperModelUC := whisperUsecases.NewTranscribeOrionModelUseCase(orionService, whisperStore, whisperCache, cfg, jobs)
+	whisperCppModelUC := whisperUsecases.NewTranscribeWhisperCppModelUseCase(commonServices.NewWhisperCppService(cfg), whisperStore, whisperCache, cfg, jobs)
 	uploadSessionUC := whisperUsecases.NewUploadSessionUseCase(badger, cfg)
-	whisperStatusUC := tasks.NewGenericStatusUseCase(whisperCache, whisperStore)
+	whisperStatusUC := tasks.NewJobStatusUseCase(whisperCache, whisperStore, jobs)
 
 	if cfg.EnableChunkUpload {
 		cleanupInterval, err := time.ParseDuration(cfg.ChunkUploadCleanupInterval)
@@ -229,7 +244,7 @@ func InitModule(
 	}
 
 	transcribeController := whisperControllers.NewWhisperTranscribeController(transcribeUC, saveRecordingUC, uploadSessionUC, cfg, mqttSvc)
-	if err := transcribeController.StartMqttSubscription(); err != nil {
+	if err := transcribeController.RegisterMqttRoutes(mqttRouter); err != nil {
 		utils.LogError("Whisper module MQTT subscription failed: %v", err)
 	}
 	whisperStatusController := whisperControllers.NewWhisperTranscribeStatusController(whisperStatusUC)
@@ -239,6 +254,7 @@ func InitModule(
 	openaiWhisperController := whisperControllers.NewWhisperModelsOpenAIController(openaiWhisperModelUC, saveRecordingUC, cfg)
 	groqWhisperController := whisperControllers.NewWhisperModelsGroqController(groqWhisperModelUC, saveRecordingUC, cfg)
 	orionWhisperController := whisperControllers.NewWhisperModelsOrionController(orionWhisperModelUC, saveRecordingUC, cfg)
+	whisperCppController := whisperControllers.NewWhisperModelsWhisperCppController(whisperCppModelUC, saveRecordingUC, cfg)
 
 	whisperRoutes.SetupWhisperRoutes(
 		protected,
@@ -248,6 +264,7 @@ func InitModule(
 		openaiWhisperController,
 		groqWhisperController,
 		orionWhisperController,
+		whisperCppController,
 		whisperUploadSessionController,
 	)
 
@@ -255,11 +272,15 @@ func InitModule(
 	pipelineStore := tasks.NewStatusStore[pipelinedtos.PipelineStatusDTO]()
 	pipelineCache := tasks.NewBadgerTaskCacheFromService(badger, "cache:pipeline:task:")
 
-	pipelineUC := pipelineUsecases.NewPipelineUseCase(transcribeUC, translateUC, summaryUC, pipelineCache, pipelineStore, mqttSvc)
-	pipelineStatusUC := tasks.NewGenericStatusUseCase(pipelineCache, pipelineStore)
+	meetingRepo := pipelineRepositories.NewMeetingRepository(db)
+	meetingUC := pipelineUsecases.NewMeetingUseCase(meetingRepo, pdfRenderer)
+
+	pipelineUC := pipelineUsecases.NewPipelineUseCase(transcribeUC, translateUC, summaryUC, pipelineCache, pipelineStore, mqttSvc, meetingUC, jobs)
+	pipelineStatusUC := tasks.NewJobStatusUseCase(pipelineCache, pipelineStore, jobs)
 	pipelineCtrl := pipelineControllers.NewPipelineController(pipelineUC, pipelineStatusUC, saveRecordingUC, uploadSessionUC, cfg)
+	meetingCtrl := pipelineControllers.NewMeetingController(meetingUC)
 
-	pipelineRoutes.SetupPipelineRoutes(protected, pipelineCtrl)
+	pipelineRoutes.SetupPipelineRoutes(protected, pipelineCtrl, meetingCtrl)
 
 	return transcribeUC, uploadSessionUC, refineUC, translateUC, summaryUC
 }
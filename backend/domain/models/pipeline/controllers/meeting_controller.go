@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	commonDtos "sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+	pipelineDtos "sensio/domain/models/pipeline/dtos"
+	pipelineUsecases "sensio/domain/models/pipeline/usecases"
+	ragServices "sensio/domain/models/rag/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Force usage of pipelineDtos for Swagger
+var _ = pipelineDtos.MeetingListResponseDTO{}
+
+// MeetingController serves persisted pipeline meetings
+type MeetingController struct {
+	meetingUC pipelineUsecases.MeetingUseCase
+}
+
+// NewMeetingController creates a new MeetingController instance
+func NewMeetingController(meetingUC pipelineUsecases.MeetingUseCase) *MeetingController {
+	return &MeetingController{meetingUC: meetingUC}
+}
+
+// ListMeetings handles GET /api/models/pipeline/meetings
+// @Summary List persisted meetings
+// @Description Lists meetings produced by pipeline jobs, newest first. Meetings remain available after the task status expires.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Produce json
+// @Param mac_address query string false "Filter by terminal MAC address"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} commonDtos.StandardResponse{data=pipelineDtos.MeetingListResponseDTO}
+// @Failure 500 {object} commonDtos.ErrorResponse
+// @Router /api/models/pipeline/meetings [get]
+func (c *MeetingController) ListMeetings(ctx *gin.Context) {
+	page, limit := parsePagination(ctx)
+	result, err := c.meetingUC.ListMeetings(ctx.Query("mac_address"), page, limit)
+	if err != nil {
+		utils.LogError("MeetingController.ListMeetings: %v", err)
+		ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, commonDtos.StandardResponse{
+		Status:  true,
+		Message: "Meetings retrieved successfully",
+		Data:    result,
+	})
+}
+
+// SearchMeetings handles GET /api/models/pipeline/meetings/search
+// @Summary Search persisted meetings
+// @Description Full-text search across meeting titles, transcripts, translations, summaries and speaker turns.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Search query"
+// @Param mac_address query string false "Filter by terminal MAC address"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} commonDtos.StandardResponse{data=pipelineDtos.MeetingListResponseDTO}
+// @Failure 400 {object} commonDtos.ValidationErrorResponse
+// @Failure 500 {object} commonDtos.ErrorResponse
+// @Router /api/models/pipeline/meetings/search [get]
+func (c *MeetingController) SearchMeetings(ctx *gin.Context) {
+	page, limit := parsePagination(ctx)
+	result, err := c.meetingUC.SearchMeetings(ctx.Query("q"), ctx.Query("mac_address"), page, limit)
+	if err != nil {
+		var valErr *utils.ValidationError
+		if errors.As(err, &valErr) {
+			ctx.JSON(http.StatusBadRequest, commonDtos.StandardResponse{
+				Status:  false,
+				Message: valErr.Message,
+				Details: valErr.Details,
+			})
+			return
+		}
+		utils.LogError("MeetingController.SearchMeetings: %v", err)
+		ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, commonDtos.StandardResponse{
+		Status:  true,
+		Message: "Meetings retrieved successfully",
+		Data:    result,
+	})
+}
+
+// GetMeeting handles GET /api/models/pipeline/meetings/:meeting_id
+// @Summary Get a persisted meeting
+// @Description Returns the full meeting record: transcript, speaker turns, translation, summary and structured artifacts, and the speakers with their talk time. Summary artifacts show the confirmed participant names in place of speaker labels.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Produce json
+// @Param meeting_id path string true "Meeting ID (pipeline task ID)"
+// @Success 200 {object} commonDtos.StandardResponse{data=pipelineDtos.MeetingDetailDTO}
+// @Failure 404 {object} commonDtos.ErrorResponse
+// @Failure 500 {object} commonDtos.ErrorResponse
+// @Router /api/models/pipeline/meetings/{meeting_id} [get]
+func (c *MeetingController) GetMeeting(ctx *gin.Context) {
+	meeting, err := c.meetingUC.GetMeeting(ctx.Param("meeting_id"))
+	if err != nil {
+		if errors.Is(err, pipelineUsecases.ErrMeetingNotFound) {
+			ctx.JSON(http.StatusNotFound, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Meeting not found",
+			})
+			return
+		}
+		utils.LogError("MeetingController.GetMeeting: %v", err)
+		ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, commonDtos.StandardResponse{
+		Status:  true,
+		Message: "Meeting retrieved successfully",
+		Data:    meeting,
+	})
+}
+
+// ExportMeeting handles GET /api/models/pipeline/meetings/:meeting_id/export
+// @Summary Export a meeting
+// @Description Download the transcript and summary of a pipeline meeting as SRT or WebVTT captions, editable DOCX minutes or JSON. Speaker labels, replaced by the confirmed participant names, and timestamps are included where the transcript has them.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Produce json
+// @Produce octet-stream
+// @Param meeting_id path string true "Meeting ID (pipeline task ID)"
+// @Param format query string false "srt, vtt, docx or json (default)"
+// @Success 200 {file} file
+// @Failure 400 {object} commonDtos.ValidationErrorResponse
+// @Failure 404 {object} commonDtos.ErrorResponse
+// @Failure 422 {object} commonDtos.ErrorResponse
+// @Failure 500 {object} commonDtos.ErrorResponse
+// @Router /api/models/pipeline/meetings/{meeting_id}/export [get]
+func (c *MeetingController) ExportMeeting(ctx *gin.Context) {
+	// Also served as /status/:task_id/export, the meeting ID being the pipeline task ID
+	meetingID := ctx.Param("meeting_id")
+	if meetingID == "" {
+		meetingID = ctx.Param("task_id")
+	}
+
+	export, err := c.meetingUC.ExportMeeting(meetingID, ctx.Query("format"))
+	if err != nil {
+		var valErr *utils.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			ctx.JSON(http.StatusBadRequest, commonDtos.StandardResponse{
+				Status:  false,
+				Message: valErr.Message,
+				Details: valErr.Details,
+			})
+		case errors.Is(err, pipelineUsecases.ErrMeetingNotFound):
+			ctx.JSON(http.StatusNotFound, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Meeting not found",
+			})
+		case errors.Is(err, ragServices.ErrNoTimedTranscript):
+			ctx.JSON(http.StatusUnprocessableEntity, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Meeting has no timed utterances to caption; export it as docx or json instead",
+			})
+		default:
+			utils.LogError("MeetingController.ExportMeeting: %v", err)
+			ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Internal Server Error",
+			})
+		}
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", export.FileName))
+	ctx.Header("X-Aligned-Timestamps", strconv.FormatBool(export.AlignedTimestamps))
+	ctx.Data(http.StatusOK, export.ContentType, export.Content)
+}
+
+// GetSpeakers handles GET /api/models/pipeline/meetings/:meeting_id/speakers
+// @Summary List the speakers of a meeting
+// @Description Lists the diarized speakers of a meeting with their talk time, and the participant name mapped to each: suggested from a self-introduction in the transcript, or confirmed by a user.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Produce json
+// @Param meeting_id path string true "Meeting ID (pipeline task ID)"
+// @Success 200 {object} commonDtos.StandardResponse{data=[]pipelineDtos.MeetingSpeakerDTO}
+// @Failure 404 {object} commonDtos.ErrorResponse
+// @Failure 500 {object} commonDtos.ErrorResponse
+// @Router /api/models/pipeline/meetings/{meeting_id}/speakers [get]
+func (c *MeetingController) GetSpeakers(ctx *gin.Context) {
+	speakers, err := c.meetingUC.GetSpeakers(ctx.Param("meeting_id"))
+	if err != nil {
+		if errors.Is(err, pipelineUsecases.ErrMeetingNotFound) {
+			ctx.JSON(http.StatusNotFound, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Meeting not found",
+			})
+			return
+		}
+		utils.LogError("MeetingController.GetSpeakers: %v", err)
+		ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, commonDtos.StandardResponse{
+		Status:  true,
+		Message: "Speakers retrieved successfully",
+		Data:    speakers,
+	})
+}
+
+// UpdateSpeakers handles PUT /api/models/pipeline/meetings/:meeting_id/speakers
+// @Summary Confirm or override speaker names
+// @Description Maps diarized speaker labels to participant names. Listed speakers are confirmed with the given name (an empty name leaves the speaker unnamed); accept_suggestions also confirms the suggestions of the others. The meeting summary and exports are then rendered with the confirmed names.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param meeting_id path string true "Meeting ID (pipeline task ID)"
+// @Param request body pipelineDtos.MeetingSpeakersUpdateDTO true "Speaker name mappings"
+// @Success 200 {object} commonDtos.StandardResponse{data=[]pipelineDtos.MeetingSpeakerDTO}
+// @Failure 400 {object} commonDtos.ValidationErrorResponse
+// @Failure 404 {object} commonDtos.ErrorResponse
+// @Failure 500 {object} commonDtos.ErrorResponse
+// @Router /api/models/pipeline/meetings/{meeting_id}/speakers [put]
+func (c *MeetingController) UpdateSpeakers(ctx *gin.Context) {
+	var req pipelineDtos.MeetingSpeakersUpdateDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error: " + err.Error(),
+		})
+		return
+	}
+
+	speakers, err := c.meetingUC.UpdateSpeakers(ctx.Param("meeting_id"), req)
+	if err != nil {
+		var valErr *utils.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			ctx.JSON(http.StatusBadRequest, commonDtos.StandardResponse{
+				Status:  false,
+				Message: valErr.Message,
+				Details: valErr.Details,
+			})
+		case errors.Is(err, pipelineUsecases.ErrMeetingNotFound):
+			ctx.JSON(http.StatusNotFound, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Meeting not found",
+			})
+		default:
+			utils.LogError("MeetingController.UpdateSpeakers: %v", err)
+			ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Internal Server Error",
+			})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, commonDtos.StandardResponse{
+		Status:  true,
+		Message: "Speakers updated successfully",
+		Data:    speakers,
+	})
+}
+
+func parsePagination(ctx *gin.Context) (int, int) {
+	limitStr := ctx.Query("limit")
+	if limitStr == "" {
+		limitStr = ctx.Query("per_page")
+	}
+	page, _ := strconv.Atoi(ctx.Query("page"))
+	limit, _ := strconv.Atoi(limitStr)
+	return page, limit
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"path/filepath"
 	commonDtos "sensio/domain/common/dtos"
@@ -318,3 +319,67 @@ func (c *PipelineController) CancelTask(ctx *gin.Context) {
 		Message: "Task cancellation requested successfully",
 	})
 }
+
+// RerunTask handles POST /api/models/pipeline/status/:task_id/rerun
+// @Summary Re-run a pipeline task from a stage
+// @Description Re-executes a finished task from the translation or summary stage as a new task, reusing the persisted transcript (and translation when re-running the summary). Overrides replace the values of the original request; provider pins the LLM provider of the rerun. The new task records the original as its parent.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID of the original run"
+// @Param request body pipelineDtos.PipelineRerunRequestDTO true "Stage to re-run from and overrides"
+// @Success 202 {object} commonDtos.StandardResponse{data=pipelineDtos.PipelineResponseDTO}
+// @Failure 400 {object} commonDtos.ValidationErrorResponse
+// @Failure 404 {object} commonDtos.StandardResponse
+// @Failure 409 {object} commonDtos.StandardResponse
+// @Failure 500 {object} commonDtos.ErrorResponse
+// @Router /api/models/pipeline/status/{task_id}/rerun [post]
+func (c *PipelineController) RerunTask(ctx *gin.Context) {
+	var req pipelineDtos.PipelineRerunRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error: " + err.Error(),
+		})
+		return
+	}
+
+	taskID, err := c.pipelineUC.RerunPipeline(ctx.Param("task_id"), req)
+	if err != nil {
+		var valErr *utils.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			ctx.JSON(http.StatusBadRequest, commonDtos.StandardResponse{
+				Status:  false,
+				Message: valErr.Message,
+				Details: valErr.Details,
+			})
+		case errors.Is(err, pipelineUsecases.ErrMeetingNotFound):
+			ctx.JSON(http.StatusNotFound, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Task not found",
+			})
+		case errors.Is(err, pipelineUsecases.ErrTaskNotFinished):
+			ctx.JSON(http.StatusConflict, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Task has not finished yet; cancel it or wait for it to complete before re-running",
+			})
+		default:
+			utils.LogError("PipelineController.RerunTask: %v", err)
+			ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
+				Status:  false,
+				Message: "Pipeline rerun failed: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, commonDtos.StandardResponse{
+		Status:  true,
+		Message: "Pipeline rerun submitted successfully",
+		Data: pipelineDtos.PipelineResponseDTO{
+			TaskID: taskID,
+		},
+	})
+}
@@ -0,0 +1,80 @@
+package dtos
+
+import (
+	ragDtos "sensio/domain/models/rag/dtos"
+	whisperDtos "sensio/domain/models/whisper/dtos"
+)
+
+// MeetingListItemDTO is the slim meeting representation used in list and search results
+type MeetingListItemDTO struct {
+	ID              string   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Title           string   `json:"title,omitempty" example:"Weekly Sync"`
+	MacAddress      string   `json:"mac_address,omitempty"`
+	Status          string   `json:"status" example:"completed"`
+	Language        string   `json:"language,omitempty" example:"id"`
+	TargetLanguage  string   `json:"target_language,omitempty" example:"en"`
+	MeetingDate     string   `json:"meeting_date,omitempty"`
+	Location        string   `json:"location,omitempty"`
+	Participants    []string `json:"participants,omitempty"`
+	StartedAt       string   `json:"started_at" example:"2026-02-21T11:00:00Z"`
+	CompletedAt     string   `json:"completed_at,omitempty"`
+	DurationSeconds float64  `json:"duration_seconds,omitempty"`
+	ParentID        string   `json:"parent_id,omitempty"`
+	RerunFromStage  string   `json:"rerun_from_stage,omitempty" example:"summary"`
+}
+
+// MeetingListResponseDTO represents the response format for a list of meetings
+type MeetingListResponseDTO struct {
+	Meetings []MeetingListItemDTO `json:"meetings"`
+	Total    int                  `json:"total"`
+	Page     int                  `json:"page"`
+	PerPage  int                  `json:"per_page"`
+}
+
+// MeetingDetailDTO is the full persisted meeting with every stage artifact
+type MeetingDetailDTO struct {
+	MeetingListItemDTO
+	Context           string                           `json:"context,omitempty"`
+	Style             string                           `json:"style,omitempty"`
+	TranscriptFormat  string                           `json:"transcript_format,omitempty"`
+	Transcription     string                           `json:"transcription,omitempty"`
+	RefinedText       string                           `json:"refined_text,omitempty"`
+	TranslatedText    string                           `json:"translated_text,omitempty"`
+	Utterances        []whisperDtos.Utterance          `json:"utterances,omitempty"` // Speaker labels as diarized; see speakers for their names
+	AlignedTimestamps bool                             `json:"aligned_timestamps"`   // utterance times come from the audio rather than text length
+	Speakers          []MeetingSpeakerDTO              `json:"speakers,omitempty"`
+	Summary           string                           `json:"summary,omitempty"`
+	SummaryMode       string                           `json:"summary_mode,omitempty"`
+	PDFUrl            string                           `json:"pdf_url,omitempty"`
+	ActionItems       []ragDtos.ActionItem             `json:"action_items,omitempty"`
+	Decisions         []ragDtos.Decision               `json:"decisions,omitempty"`
+	OpenIssues        []ragDtos.OpenIssue              `json:"open_issues,omitempty"`
+	Risks             []ragDtos.Risk                   `json:"risks,omitempty"`
+	CanonicalSummary  *ragDtos.CanonicalMeetingSummary `json:"canonical_summary,omitempty"`
+	Reruns            []string                         `json:"reruns,omitempty"` // IDs of meetings re-executed from this one
+}
+
+// MeetingSpeakerDTO is a diarized speaker of a meeting with its participant name and talk time
+type MeetingSpeakerDTO struct {
+	SpeakerLabel    string  `json:"speaker_label" example:"Speaker 1"`
+	Name            string  `json:"name,omitempty" example:"Budi Santoso"`
+	Source          string  `json:"source,omitempty" example:"suggested"`        // suggested or confirmed; empty when not mapped
+	Confidence      float64 `json:"confidence,omitempty" example:"0.95"`         // Of a suggestion
+	Evidence        string  `json:"evidence,omitempty" example:"nama saya Budi"` // Introduction a suggestion was found in
+	TalkTimeMs      int64   `json:"talk_time_ms" example:"125000"`
+	TalkTimePercent float64 `json:"talk_time_percent" example:"41.7"`
+	Turns           int     `json:"turns" example:"12"`
+	Words           int     `json:"words" example:"310"`
+}
+
+// MeetingSpeakersUpdateDTO confirms or overrides the participant names of a meeting's speakers
+type MeetingSpeakersUpdateDTO struct {
+	Mappings          []MeetingSpeakerMappingDTO `json:"mappings" binding:"dive"`
+	AcceptSuggestions bool                       `json:"accept_suggestions"` // Also confirm the suggestions of speakers not listed in mappings
+}
+
+// MeetingSpeakerMappingDTO names one speaker label
+type MeetingSpeakerMappingDTO struct {
+	SpeakerLabel string `json:"speaker_label" binding:"required" example:"Speaker 1"`
+	Name         string `json:"name" example:"Budi Santoso"` // Empty to leave the speaker unnamed
+}
@@ -6,6 +6,7 @@ type PipelineStageStatus struct {
 	Error           string      `json:"error,omitempty"`
 	StartedAt       string      `json:"started_at,omitempty"`
 	DurationSeconds float64     `json:"duration_seconds,omitempty"`
+	Reused          bool        `json:"reused,omitempty"` // output carried over from the parent run
 }
 
 type PipelineStatusDTO struct {
@@ -17,6 +18,9 @@ type PipelineStatusDTO struct {
 	ExpiresAt       string                         `json:"expires_at,omitempty"`
 	ExpiresInSecond int64                          `json:"expires_in_seconds,omitempty"`
 	MacAddress      string                         `json:"mac_address,omitempty"`
+	ParentTaskID    string                         `json:"parent_task_id,omitempty"`
+	RerunFromStage  string                         `json:"rerun_from_stage,omitempty"`
+	Provider        string                         `json:"provider,omitempty"`
 }
 
 // SetExpiry implements tasks.StatusWithExpiry interface
@@ -25,6 +29,20 @@ func (s *PipelineStatusDTO) SetExpiry(expiresAt string, expiresInSeconds int64)
 	s.ExpiresInSecond = expiresInSeconds
 }
 
+// ApplyJobStatus implements tasks.StatusWithJob interface; stages that had not finished take the job status
+func (s *PipelineStatusDTO) ApplyJobStatus(status string, lastError string) {
+	s.OverallStatus = status
+	for name, stage := range s.Stages {
+		if stage.Status == "pending" || stage.Status == "processing" {
+			stage.Status = status
+			if stage.Error == "" {
+				stage.Error = lastError
+			}
+			s.Stages[name] = stage
+		}
+	}
+}
+
 type PipelineRequestDTO struct {
 	Language       string   `form:"language" json:"language" example:"id"`
 	TargetLanguage string   `form:"target_language" json:"target_language" example:"en"`
@@ -39,6 +57,20 @@ type PipelineRequestDTO struct {
 	MacAddress     string   `form:"mac_address" json:"mac_address"`
 }
 
+// PipelineRerunRequestDTO re-executes a finished task from a stage, reusing the outputs of earlier stages.
+// Omitted overrides keep the values of the original run.
+type PipelineRerunRequestDTO struct {
+	FromStage      string   `json:"from_stage" binding:"required" example:"summary"` // translation, summary
+	TargetLanguage *string  `json:"target_language,omitempty" example:"en"`
+	Context        *string  `json:"context,omitempty"`
+	Style          *string  `json:"style,omitempty" example:"executive"`
+	Date           *string  `json:"date,omitempty"`
+	Location       *string  `json:"location,omitempty"`
+	Participants   []string `json:"participants,omitempty"`
+	Summarize      *bool    `json:"summarize,omitempty"`
+	Provider       string   `json:"provider,omitempty" example:"openai"` // pins the LLM provider for the rerun
+}
+
 type PipelineResponseDTO struct {
 	TaskID     string             `json:"task_id"`
 	TaskStatus *PipelineStatusDTO `json:"task_status,omitempty"`
@@ -0,0 +1,182 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StringList is a slice of strings stored as a JSON text column
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("unsupported type for StringList: %T", value)
+	}
+}
+
+// Meeting is the durable record of a pipeline job. Its ID is the pipeline task ID,
+// so a meeting can be reopened long after the task status has expired from the cache.
+type Meeting struct {
+	ID                string         `gorm:"type:char(36);primaryKey" json:"id"`
+	MacAddress        string         `gorm:"type:varchar(255);index" json:"mac_address"`
+	Title             string         `gorm:"type:varchar(255)" json:"title"`
+	Language          string         `gorm:"type:varchar(16)" json:"language"`
+	TargetLanguage    string         `gorm:"type:varchar(16)" json:"target_language"`
+	Context           string         `gorm:"type:text" json:"context"`
+	Style             string         `gorm:"type:varchar(64)" json:"style"`
+	MeetingDate       string         `gorm:"type:varchar(64)" json:"meeting_date"`
+	Location          string         `gorm:"type:varchar(255)" json:"location"`
+	Participants      StringList     `gorm:"type:text" json:"participants"`
+	Status            string         `gorm:"type:varchar(32);index" json:"status"` // pending, processing, completed, failed, cancelled
+	TranscriptFormat  string         `gorm:"type:varchar(32)" json:"transcript_format"`
+	AlignedTimestamps bool           `gorm:"not null;default:false" json:"aligned_timestamps"` // segment times come from the audio rather than text length
+	Transcription     string         `gorm:"type:longtext" json:"transcription"`
+	RefinedText       string         `gorm:"type:longtext" json:"refined_text"`
+	TranslatedText    string         `gorm:"type:longtext" json:"translated_text"`
+	Summary           string         `gorm:"type:longtext" json:"summary"`
+	SummaryMode       string         `gorm:"type:varchar(64)" json:"summary_mode"`
+	PDFUrl            string         `gorm:"type:varchar(512)" json:"pdf_url"`
+	CanonicalSummary  string         `gorm:"type:longtext" json:"canonical_summary"` // JSON of rag dtos.CanonicalMeetingSummary
+	DurationSeconds   float64        `json:"duration_seconds"`
+	ParentID          string         `gorm:"type:char(36);index" json:"parent_id,omitempty"`     // meeting this run was re-executed from
+	RerunFromStage    string         `gorm:"type:varchar(32)" json:"rerun_from_stage,omitempty"` // first stage executed again by the rerun
+	StartedAt         time.Time      `json:"started_at"`
+	CompletedAt       *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	Segments    []MeetingTranscriptSegment `gorm:"foreignKey:MeetingID" json:"segments,omitempty"`
+	ActionItems []MeetingActionItem        `gorm:"foreignKey:MeetingID" json:"action_items,omitempty"`
+	Decisions   []MeetingDecision          `gorm:"foreignKey:MeetingID" json:"decisions,omitempty"`
+	OpenIssues  []MeetingOpenIssue         `gorm:"foreignKey:MeetingID" json:"open_issues,omitempty"`
+	Risks       []MeetingRisk              `gorm:"foreignKey:MeetingID" json:"risks,omitempty"`
+	Speakers    []MeetingSpeaker           `gorm:"foreignKey:MeetingID" json:"speakers,omitempty"`
+}
+
+// TableName specifies the table name for the Meeting model
+func (Meeting) TableName() string {
+	return "meetings"
+}
+
+// MeetingTranscriptSegment is a single speaker turn (or plain segment) of a meeting transcript
+type MeetingTranscriptSegment struct {
+	ID           uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	MeetingID    string  `gorm:"type:char(36);not null;index" json:"meeting_id"`
+	Position     int     `gorm:"not null" json:"position"`
+	SpeakerLabel string  `gorm:"type:varchar(255)" json:"speaker_label"`
+	StartMs      int64   `json:"start_ms"`
+	EndMs        int64   `json:"end_ms"`
+	Text         string  `gorm:"type:text" json:"text"`
+	Confidence   float64 `json:"confidence"`
+}
+
+// TableName specifies the table name for the MeetingTranscriptSegment model
+func (MeetingTranscriptSegment) TableName() string {
+	return "meeting_transcript_segments"
+}
+
+// MeetingActionItem is an action item extracted by the summary stage
+type MeetingActionItem struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	MeetingID string `gorm:"type:char(36);not null;index" json:"meeting_id"`
+	Position  int    `gorm:"not null" json:"position"`
+	Task      string `gorm:"type:text" json:"task"`
+	PIC       string `gorm:"type:varchar(255)" json:"pic"`
+	Deadline  string `gorm:"type:varchar(255)" json:"deadline"`
+	Status    string `gorm:"type:varchar(64)" json:"status"`
+}
+
+// TableName specifies the table name for the MeetingActionItem model
+func (MeetingActionItem) TableName() string {
+	return "meeting_action_items"
+}
+
+// MeetingDecision is a decision extracted by the summary stage
+type MeetingDecision struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	MeetingID   string `gorm:"type:char(36);not null;index" json:"meeting_id"`
+	Position    int    `gorm:"not null" json:"position"`
+	Description string `gorm:"type:text" json:"description"`
+	Rationale   string `gorm:"type:text" json:"rationale"`
+}
+
+// TableName specifies the table name for the MeetingDecision model
+func (MeetingDecision) TableName() string {
+	return "meeting_decisions"
+}
+
+// MeetingOpenIssue is an unresolved topic extracted by the summary stage
+type MeetingOpenIssue struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	MeetingID   string `gorm:"type:char(36);not null;index" json:"meeting_id"`
+	Position    int    `gorm:"not null" json:"position"`
+	Description string `gorm:"type:text" json:"description"`
+	Owner       string `gorm:"type:varchar(255)" json:"owner"`
+}
+
+// TableName specifies the table name for the MeetingOpenIssue model
+func (MeetingOpenIssue) TableName() string {
+	return "meeting_open_issues"
+}
+
+// MeetingRisk is a risk with its mitigation extracted by the summary stage
+type MeetingRisk struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	MeetingID   string `gorm:"type:char(36);not null;index" json:"meeting_id"`
+	Position    int    `gorm:"not null" json:"position"`
+	Description string `gorm:"type:text" json:"description"`
+	Impact      string `gorm:"type:varchar(64)" json:"impact"`
+	Mitigation  string `gorm:"type:text" json:"mitigation"`
+}
+
+// TableName specifies the table name for the MeetingRisk model
+func (MeetingRisk) TableName() string {
+	return "meeting_risks"
+}
+
+// Speaker mapping sources
+const (
+	SpeakerSourceSuggested = "suggested" // found in a self-introduction of the transcript
+	SpeakerSourceConfirmed = "confirmed" // set or accepted by a user
+)
+
+// MeetingSpeaker maps a diarized speaker label ("Speaker 1") to a participant name.
+// A confirmed mapping with an empty name records that the speaker is deliberately left unnamed.
+type MeetingSpeaker struct {
+	ID           uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	MeetingID    string  `gorm:"type:char(36);not null;index" json:"meeting_id"`
+	SpeakerLabel string  `gorm:"type:varchar(255);not null" json:"speaker_label"`
+	Name         string  `gorm:"type:varchar(255)" json:"name"`
+	Source       string  `gorm:"type:varchar(32)" json:"source"` // suggested, confirmed
+	Confidence   float64 `json:"confidence"`
+	Evidence     string  `gorm:"type:text" json:"evidence"`
+}
+
+// TableName specifies the table name for the MeetingSpeaker model
+func (MeetingSpeaker) TableName() string {
+	return "meeting_speakers"
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"fmt"
+	"sensio/domain/models/pipeline/entities"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MeetingFilter narrows down meeting list and search queries
+type MeetingFilter struct {
+	MacAddress string
+	Status     string
+	ParentID   string // Only reruns derived from this meeting
+	Query      string // Free-text search across title, transcript, summary and segments
+}
+
+// IMeetingRepository defines the interface for meeting storage operations
+type IMeetingRepository interface {
+	Save(meeting *entities.Meeting) error
+	GetByID(id string) (*entities.Meeting, error)
+	List(filter MeetingFilter, offset, limit int) ([]entities.Meeting, int64, error)
+	ReplaceSegments(meetingID string, segments []entities.MeetingTranscriptSegment) error
+	ReplaceSummaryArtifacts(meetingID string, actionItems []entities.MeetingActionItem, decisions []entities.MeetingDecision, openIssues []entities.MeetingOpenIssue, risks []entities.MeetingRisk) error
+	ReplaceSpeakers(meetingID string, speakers []entities.MeetingSpeaker) error
+}
+
+// MeetingRepository handles persistent storage of pipeline meetings using GORM
+type MeetingRepository struct {
+	db *gorm.DB
+}
+
+// NewMeetingRepository creates a new instance of MeetingRepository
+func NewMeetingRepository(db *gorm.DB) *MeetingRepository {
+	return &MeetingRepository{db: db}
+}
+
+// Save upserts the meeting row without touching its child artifacts
+func (r *MeetingRepository) Save(meeting *entities.Meeting) error {
+	if r.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return r.db.Omit(clause.Associations).Save(meeting).Error
+}
+
+// GetByID retrieves a meeting with all its artifacts in their original order
+func (r *MeetingRepository) GetByID(id string) (*entities.Meeting, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var meeting entities.Meeting
+	err := r.db.
+		Preload("Segments", orderByPosition).
+		Preload("ActionItems", orderByPosition).
+		Preload("Decisions", orderByPosition).
+		Preload("OpenIssues", orderByPosition).
+		Preload("Risks", orderByPosition).
+		Preload("Speakers", orderByID).
+		Where("id = ?", id).
+		First(&meeting).Error
+	if err != nil {
+		return nil, err
+	}
+	return &meeting, nil
+}
+
+// List retrieves meetings (newest first) matching the filter, with pagination.
+// Child artifacts are not loaded; use GetByID for the full record.
+func (r *MeetingRepository) List(filter MeetingFilter, offset, limit int) ([]entities.Meeting, int64, error) {
+	if r.db == nil {
+		return nil, 0, fmt.Errorf("database not initialized")
+	}
+
+	query := r.db.Model(&entities.Meeting{})
+	if filter.MacAddress != "" {
+		query = query.Where("mac_address = ?", filter.MacAddress)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ParentID != "" {
+		query = query.Where("parent_id = ?", filter.ParentID)
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		like := "%" + strings.ToLower(q) + "%"
+		segmentMatch := r.db.Model(&entities.MeetingTranscriptSegment{}).
+			Select("meeting_id").
+			Where("LOWER(text) LIKE ? OR LOWER(speaker_label) LIKE ?", like, like)
+		query = query.Where(
+			"LOWER(title) LIKE ? OR LOWER(summary) LIKE ? OR LOWER(transcription) LIKE ? OR LOWER(translated_text) LIKE ? OR id IN (?)",
+			like, like, like, like, segmentMatch,
+		)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var meetings []entities.Meeting
+	query = query.Order("started_at DESC")
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+	if err := query.Find(&meetings).Error; err != nil {
+		return nil, 0, err
+	}
+	return meetings, total, nil
+}
+
+// ReplaceSegments swaps the transcript segments of a meeting in a single transaction
+func (r *MeetingRepository) ReplaceSegments(meetingID string, segments []entities.MeetingTranscriptSegment) error {
+	if r.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("meeting_id = ?", meetingID).Delete(&entities.MeetingTranscriptSegment{}).Error; err != nil {
+			return err
+		}
+		if len(segments) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(segments, 200).Error
+	})
+}
+
+// ReplaceSummaryArtifacts swaps action items, decisions, open issues and risks of a meeting in a single transaction
+func (r *MeetingRepository) ReplaceSummaryArtifacts(
+	meetingID string,
+	actionItems []entities.MeetingActionItem,
+	decisions []entities.MeetingDecision,
+	openIssues []entities.MeetingOpenIssue,
+	risks []entities.MeetingRisk,
+) error {
+	if r.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := replaceRows(tx, meetingID, &entities.MeetingActionItem{}, actionItems); err != nil {
+			return err
+		}
+		if err := replaceRows(tx, meetingID, &entities.MeetingDecision{}, decisions); err != nil {
+			return err
+		}
+		if err := replaceRows(tx, meetingID, &entities.MeetingOpenIssue{}, openIssues); err != nil {
+			return err
+		}
+		return replaceRows(tx, meetingID, &entities.MeetingRisk{}, risks)
+	})
+}
+
+// ReplaceSpeakers swaps the speaker name mappings of a meeting in a single transaction
+func (r *MeetingRepository) ReplaceSpeakers(meetingID string, speakers []entities.MeetingSpeaker) error {
+	if r.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRows(tx, meetingID, &entities.MeetingSpeaker{}, speakers)
+	})
+}
+
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+func replaceRows[T any](tx *gorm.DB, meetingID string, model interface{}, rows []T) error {
+	if err := tx.Where("meeting_id = ?", meetingID).Delete(model).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Create(&rows).Error
+}
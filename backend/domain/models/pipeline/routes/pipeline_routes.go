@@ -9,6 +9,7 @@ import (
 func SetupPipelineRoutes(
 	protected *gin.RouterGroup,
 	pipelineCtrl *controllers.PipelineController,
+	meetingCtrl *controllers.MeetingController,
 ) {
 	// New standard: /api/models/pipeline/*
 	models := protected.Group("/api/models/pipeline")
@@ -17,6 +18,16 @@ func SetupPipelineRoutes(
 		models.POST("/job/by-upload", pipelineCtrl.ExecuteJobByUpload)
 		models.GET("/status/:task_id", pipelineCtrl.GetStatus)
 		models.DELETE("/status/:task_id", pipelineCtrl.CancelTask)
+		models.POST("/status/:task_id/rerun", pipelineCtrl.RerunTask)
+		models.GET("/status/:task_id/export", meetingCtrl.ExportMeeting)
+
+		// Persisted meetings (survive task status TTL)
+		models.GET("/meetings", meetingCtrl.ListMeetings)
+		models.GET("/meetings/search", meetingCtrl.SearchMeetings)
+		models.GET("/meetings/:meeting_id", meetingCtrl.GetMeeting)
+		models.GET("/meetings/:meeting_id/export", meetingCtrl.ExportMeeting)
+		models.GET("/meetings/:meeting_id/speakers", meetingCtrl.GetSpeakers)
+		models.PUT("/meetings/:meeting_id/speakers", meetingCtrl.UpdateSpeakers)
 	}
 
 	// Legacy support: /api/pipeline/* (backward compatibility)
@@ -26,5 +37,7 @@ func SetupPipelineRoutes(
 		legacy.POST("/job/by-upload", pipelineCtrl.ExecuteJobByUpload)
 		legacy.GET("/status/:task_id", pipelineCtrl.GetStatus)
 		legacy.DELETE("/status/:task_id", pipelineCtrl.CancelTask)
+		legacy.POST("/status/:task_id/rerun", pipelineCtrl.RerunTask)
+		legacy.GET("/status/:task_id/export", meetingCtrl.ExportMeeting)
 	}
 }
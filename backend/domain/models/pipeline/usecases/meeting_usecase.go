@@ -0,0 +1,631 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sensio/domain/common/utils"
+	pipelineDtos "sensio/domain/models/pipeline/dtos"
+	"sensio/domain/models/pipeline/entities"
+	"sensio/domain/models/pipeline/repositories"
+	ragDtos "sensio/domain/models/rag/dtos"
+	ragServices "sensio/domain/models/rag/services"
+	whisperDtos "sensio/domain/models/whisper/dtos"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrMeetingNotFound is returned when a meeting does not exist in persistent storage
+var ErrMeetingNotFound = errors.New("meeting not found")
+
+// MeetingArchiver persists pipeline artifacts as durable meeting records.
+// Each Archive method is called once the matching pipeline stage completes;
+// LoadRun reads a finished run back so it can be re-executed from a later stage.
+type MeetingArchiver interface {
+	ArchiveStarted(taskID string, req pipelineDtos.PipelineRequestDTO, startedAt time.Time) error
+	ArchiveLineage(taskID string, parentID string, fromStage string) error
+	ArchiveTranscription(taskID string, result *whisperDtos.AsyncTranscriptionResultDTO) error
+	ArchiveTranslation(taskID string, translatedText string) error
+	ArchiveSummary(taskID string, result *ragDtos.RAGSummaryResponseDTO) error
+	ArchiveOutcome(taskID string, overallStatus string, durationSeconds float64) error
+	LoadRun(taskID string) (*ArchivedRun, error)
+}
+
+// ArchivedRun is a persisted pipeline run: the request that started it and the stage outputs it produced
+type ArchivedRun struct {
+	Request        pipelineDtos.PipelineRequestDTO
+	Status         string
+	Transcription  *whisperDtos.AsyncTranscriptionResultDTO // nil when the run never finished transcribing
+	TranslatedText string
+	Summary        string
+}
+
+// MeetingUseCase exposes persisted meetings to the API
+type MeetingUseCase interface {
+	MeetingArchiver
+	ListMeetings(macAddress string, page, limit int) (*pipelineDtos.MeetingListResponseDTO, error)
+	SearchMeetings(query string, macAddress string, page, limit int) (*pipelineDtos.MeetingListResponseDTO, error)
+	GetMeeting(id string) (*pipelineDtos.MeetingDetailDTO, error)
+	ExportMeeting(id string, format string) (*ragServices.TranscriptExport, error)
+	GetSpeakers(id string) ([]pipelineDtos.MeetingSpeakerDTO, error)
+	UpdateSpeakers(id string, req pipelineDtos.MeetingSpeakersUpdateDTO) ([]pipelineDtos.MeetingSpeakerDTO, error)
+}
+
+type meetingUseCase struct {
+	repo     repositories.IMeetingRepository
+	renderer ragServices.SummaryPDFRenderer
+}
+
+// NewMeetingUseCase creates the meeting usecase. The renderer re-renders the summary PDF once
+// speaker names are confirmed; it may be nil.
+func NewMeetingUseCase(repo repositories.IMeetingRepository, renderer ragServices.SummaryPDFRenderer) MeetingUseCase {
+	return &meetingUseCase{repo: repo, renderer: renderer}
+}
+
+func (u *meetingUseCase) ArchiveStarted(taskID string, req pipelineDtos.PipelineRequestDTO, startedAt time.Time) error {
+	meeting := &entities.Meeting{
+		ID:             taskID,
+		MacAddress:     req.MacAddress,
+		Title:          req.Context,
+		Language:       req.Language,
+		TargetLanguage: req.TargetLanguage,
+		Context:        req.Context,
+		Style:          req.Style,
+		MeetingDate:    req.Date,
+		Location:       req.Location,
+		Participants:   entities.StringList(req.Participants),
+		Status:         "pending",
+		StartedAt:      startedAt.UTC(),
+	}
+	return u.repo.Save(meeting)
+}
+
+func (u *meetingUseCase) ArchiveLineage(taskID string, parentID string, fromStage string) error {
+	meeting, err := u.load(taskID)
+	if err != nil {
+		return err
+	}
+	meeting.ParentID = parentID
+	meeting.RerunFromStage = fromStage
+	return u.repo.Save(meeting)
+}
+
+func (u *meetingUseCase) ArchiveTranscription(taskID string, result *whisperDtos.AsyncTranscriptionResultDTO) error {
+	if result == nil {
+		return nil
+	}
+	meeting, err := u.load(taskID)
+	if err != nil {
+		return err
+	}
+	meeting.Status = "processing"
+	meeting.Transcription = result.Transcription
+	meeting.RefinedText = result.RefinedText
+	meeting.TranscriptFormat = string(result.TranscriptFormat)
+	meeting.AlignedTimestamps = result.AlignedTimestamps
+	if err := u.repo.Save(meeting); err != nil {
+		return err
+	}
+	if err := u.repo.ReplaceSegments(taskID, segmentsFromTranscription(taskID, result)); err != nil {
+		return err
+	}
+	return u.suggestSpeakers(meeting, result.Utterances)
+}
+
+// suggestSpeakers proposes names for the speakers who introduce themselves in the transcript.
+// Confirmed mappings are kept, and no suggestion reuses a confirmed name.
+func (u *meetingUseCase) suggestSpeakers(meeting *entities.Meeting, utterances []whisperDtos.Utterance) error {
+	var speakers []entities.MeetingSpeaker
+	confirmedLabels := make(map[string]bool)
+	confirmedNames := make(map[string]bool)
+	for _, s := range meeting.Speakers {
+		if s.Source == entities.SpeakerSourceConfirmed {
+			s.ID = 0
+			speakers = append(speakers, s)
+			confirmedLabels[s.SpeakerLabel] = true
+			confirmedNames[strings.ToLower(s.Name)] = true
+		}
+	}
+	for _, suggestion := range utils.SuggestSpeakerNames(utterances, meeting.Participants) {
+		if confirmedLabels[suggestion.SpeakerLabel] || confirmedNames[strings.ToLower(suggestion.Name)] {
+			continue
+		}
+		speakers = append(speakers, entities.MeetingSpeaker{
+			MeetingID:    meeting.ID,
+			SpeakerLabel: suggestion.SpeakerLabel,
+			Name:         suggestion.Name,
+			Source:       entities.SpeakerSourceSuggested,
+			Confidence:   suggestion.Confidence,
+			Evidence:     suggestion.Evidence,
+		})
+	}
+	if len(speakers) == 0 && len(meeting.Speakers) == 0 {
+		return nil
+	}
+	if len(speakers) > 0 {
+		utils.LogInfo("Meetings: %d speaker mapping(s) for %s", len(speakers), meeting.ID)
+	}
+	return u.repo.ReplaceSpeakers(meeting.ID, speakers)
+}
+
+func (u *meetingUseCase) ArchiveTranslation(taskID string, translatedText string) error {
+	meeting, err := u.load(taskID)
+	if err != nil {
+		return err
+	}
+	meeting.TranslatedText = translatedText
+	return u.repo.Save(meeting)
+}
+
+func (u *meetingUseCase) ArchiveSummary(taskID string, result *ragDtos.RAGSummaryResponseDTO) error {
+	if result == nil {
+		return nil
+	}
+	meeting, err := u.load(taskID)
+	if err != nil {
+		return err
+	}
+	meeting.Summary = result.Summary
+	meeting.SummaryMode = result.SummaryMode
+	meeting.PDFUrl = result.PDFUrl
+	if result.CanonicalSummary != nil {
+		if b, err := json.Marshal(result.CanonicalSummary); err == nil {
+			meeting.CanonicalSummary = string(b)
+		}
+		if title := strings.TrimSpace(result.CanonicalSummary.Metadata.MeetingTitle); title != "" {
+			meeting.Title = title
+		}
+	}
+	if err := u.repo.Save(meeting); err != nil {
+		return err
+	}
+
+	actionItems := make([]entities.MeetingActionItem, 0, len(result.ActionItems))
+	for i, item := range result.ActionItems {
+		actionItems = append(actionItems, entities.MeetingActionItem{
+			MeetingID: taskID, Position: i + 1, Task: item.Task, PIC: item.PIC, Deadline: item.Deadline, Status: item.Status,
+		})
+	}
+	decisions := make([]entities.MeetingDecision, 0, len(result.Decisions))
+	for i, d := range result.Decisions {
+		decisions = append(decisions, entities.MeetingDecision{
+			MeetingID: taskID, Position: i + 1, Description: d.Description, Rationale: d.Rationale,
+		})
+	}
+	openIssues := make([]entities.MeetingOpenIssue, 0, len(result.OpenIssues))
+	for i, o := range result.OpenIssues {
+		openIssues = append(openIssues, entities.MeetingOpenIssue{
+			MeetingID: taskID, Position: i + 1, Description: o.Description, Owner: o.Owner,
+		})
+	}
+	risks := make([]entities.MeetingRisk, 0, len(result.Risks))
+	for i, r := range result.Risks {
+		risks = append(risks, entities.MeetingRisk{
+			MeetingID: taskID, Position: i + 1, Description: r.Description, Impact: r.Impact, Mitigation: r.Mitigation,
+		})
+	}
+	return u.repo.ReplaceSummaryArtifacts(taskID, actionItems, decisions, openIssues, risks)
+}
+
+func (u *meetingUseCase) ArchiveOutcome(taskID string, overallStatus string, durationSeconds float64) error {
+	meeting, err := u.load(taskID)
+	if err != nil {
+		return err
+	}
+	meeting.Status = overallStatus
+	meeting.DurationSeconds = durationSeconds
+	now := time.Now().UTC()
+	meeting.CompletedAt = &now
+	return u.repo.Save(meeting)
+}
+
+func (u *meetingUseCase) LoadRun(taskID string) (*ArchivedRun, error) {
+	meeting, err := u.load(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &ArchivedRun{
+		Request: pipelineDtos.PipelineRequestDTO{
+			Language:       meeting.Language,
+			TargetLanguage: meeting.TargetLanguage,
+			Context:        meeting.Context,
+			Style:          meeting.Style,
+			Date:           meeting.MeetingDate,
+			Location:       meeting.Location,
+			Participants:   []string(meeting.Participants),
+			Summarize:      meeting.Summary != "",
+			MacAddress:     meeting.MacAddress,
+		},
+		Status:         meeting.Status,
+		TranslatedText: meeting.TranslatedText,
+		Summary:        meeting.Summary,
+	}
+	if meeting.Transcription == "" && meeting.RefinedText == "" {
+		return run, nil
+	}
+
+	// A rerun works on the confirmed participant names, so a new summary assigns owners by name
+	names := speakerNames(meeting)
+	transcription := &whisperDtos.AsyncTranscriptionResultDTO{
+		Transcription:     utils.ReplaceSpeakerLabels(meeting.Transcription, names),
+		RefinedText:       utils.ReplaceSpeakerLabels(meeting.RefinedText, names),
+		DetectedLanguage:  meeting.Language,
+		TranscriptFormat:  whisperDtos.TranscriptFormat(meeting.TranscriptFormat),
+		Utterances:        utils.RenameSpeakers(meetingUtterances(meeting), names),
+		AlignedTimestamps: meeting.AlignedTimestamps,
+	}
+	run.Transcription = transcription
+	run.TranslatedText = utils.ReplaceSpeakerLabels(run.TranslatedText, names)
+	run.Request.Participants = withNames(run.Request.Participants, names)
+	return run, nil
+}
+
+func (u *meetingUseCase) ListMeetings(macAddress string, page, limit int) (*pipelineDtos.MeetingListResponseDTO, error) {
+	return u.list(repositories.MeetingFilter{MacAddress: macAddress}, page, limit)
+}
+
+func (u *meetingUseCase) SearchMeetings(query string, macAddress string, page, limit int) (*pipelineDtos.MeetingListResponseDTO, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "q", Message: "search query is required"},
+		})
+	}
+	return u.list(repositories.MeetingFilter{MacAddress: macAddress, Query: query}, page, limit)
+}
+
+func (u *meetingUseCase) GetMeeting(id string) (*pipelineDtos.MeetingDetailDTO, error) {
+	meeting, err := u.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &pipelineDtos.MeetingDetailDTO{
+		MeetingListItemDTO: toMeetingListItem(*meeting),
+		Context:            meeting.Context,
+		Style:              meeting.Style,
+		TranscriptFormat:   meeting.TranscriptFormat,
+		Transcription:      meeting.Transcription,
+		RefinedText:        meeting.RefinedText,
+		TranslatedText:     meeting.TranslatedText,
+		Summary:            meeting.Summary,
+		SummaryMode:        meeting.SummaryMode,
+		PDFUrl:             meeting.PDFUrl,
+	}
+	if reruns, _, err := u.repo.List(repositories.MeetingFilter{ParentID: id}, 0, 0); err != nil {
+		utils.LogWarn("Meetings: Failed to list reruns of %s: %v", id, err)
+	} else {
+		for _, r := range reruns {
+			detail.Reruns = append(detail.Reruns, r.ID)
+		}
+	}
+	detail.Utterances = meetingUtterances(meeting)
+	detail.AlignedTimestamps = meeting.AlignedTimestamps
+	detail.Speakers = buildSpeakers(meeting)
+
+	// The summary artifacts are rendered with the confirmed participant names in place of speaker labels
+	names := speakerNames(meeting)
+	rename := func(s string) string { return utils.ReplaceSpeakerLabels(s, names) }
+	detail.Summary = rename(meeting.Summary)
+	for _, a := range meeting.ActionItems {
+		detail.ActionItems = append(detail.ActionItems, ragDtos.ActionItem{ID: a.Position, Task: rename(a.Task), PIC: rename(a.PIC), Deadline: a.Deadline, Status: a.Status})
+	}
+	for _, d := range meeting.Decisions {
+		detail.Decisions = append(detail.Decisions, ragDtos.Decision{ID: d.Position, Description: rename(d.Description), Rationale: rename(d.Rationale)})
+	}
+	for _, o := range meeting.OpenIssues {
+		detail.OpenIssues = append(detail.OpenIssues, ragDtos.OpenIssue{ID: o.Position, Description: rename(o.Description), Owner: rename(o.Owner)})
+	}
+	for _, r := range meeting.Risks {
+		detail.Risks = append(detail.Risks, ragDtos.Risk{ID: r.Position, Description: rename(r.Description), Impact: r.Impact, Mitigation: rename(r.Mitigation)})
+	}
+	if meeting.CanonicalSummary != "" {
+		var canonical ragDtos.CanonicalMeetingSummary
+		if err := json.Unmarshal([]byte(meeting.CanonicalSummary), &canonical); err != nil {
+			utils.LogWarn("Meetings: Failed to decode canonical summary for %s: %v", id, err)
+		} else {
+			detail.CanonicalSummary = ragServices.RenameSummarySpeakers(&canonical, names)
+		}
+	}
+	return detail, nil
+}
+
+// ExportMeeting renders the transcript and summary of a meeting as srt, vtt, docx or json,
+// with the confirmed participant names in place of speaker labels. Captions and documents of
+// a transcript whose times were estimated say so.
+func (u *meetingUseCase) ExportMeeting(id string, format string) (*ragServices.TranscriptExport, error) {
+	meeting, err := u.GetMeeting(id)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	for _, s := range meeting.Speakers {
+		if s.Source == entities.SpeakerSourceConfirmed && s.Name != "" {
+			names[s.SpeakerLabel] = s.Name
+		}
+	}
+	transcription := meeting.RefinedText
+	if transcription == "" {
+		transcription = meeting.Transcription
+	}
+	return ragServices.ExportTranscript(&ragServices.TranscriptDocument{
+		ID:                meeting.ID,
+		Title:             meeting.Title,
+		Language:          meeting.Language,
+		Date:              meeting.MeetingDate,
+		Location:          meeting.Location,
+		Participants:      meeting.Participants,
+		Transcription:     utils.ReplaceSpeakerLabels(transcription, names),
+		TranslatedText:    utils.ReplaceSpeakerLabels(meeting.TranslatedText, names),
+		Utterances:        utils.RenameSpeakers(meeting.Utterances, names),
+		AlignedTimestamps: meeting.AlignedTimestamps,
+		Summary:           meeting.CanonicalSummary,
+		SummaryMarkdown:   meeting.Summary,
+	}, format)
+}
+
+// GetSpeakers lists the diarized speakers of a meeting with their participant names and talk time
+func (u *meetingUseCase) GetSpeakers(id string) ([]pipelineDtos.MeetingSpeakerDTO, error) {
+	meeting, err := u.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return buildSpeakers(meeting), nil
+}
+
+// UpdateSpeakers confirms or overrides the participant names of the listed speakers, and optionally
+// accepts the pending suggestions of the others. Confirmed names join the meeting's participants,
+// and the summary PDF is rendered again with the names.
+func (u *meetingUseCase) UpdateSpeakers(id string, req pipelineDtos.MeetingSpeakersUpdateDTO) ([]pipelineDtos.MeetingSpeakerDTO, error) {
+	meeting, err := u.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make(map[string]int)
+	for _, s := range meeting.Segments {
+		if _, ok := order[s.SpeakerLabel]; !ok && s.SpeakerLabel != "" {
+			order[s.SpeakerLabel] = len(order)
+		}
+	}
+	if len(order) == 0 {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "mappings", Message: "meeting has no diarized speakers"},
+		})
+	}
+
+	mappings := make(map[string]entities.MeetingSpeaker)
+	for _, s := range meeting.Speakers {
+		s.ID = 0
+		mappings[s.SpeakerLabel] = s
+	}
+	var details []utils.ValidationErrorDetail
+	for i, m := range req.Mappings {
+		label := strings.TrimSpace(m.SpeakerLabel)
+		if _, ok := order[label]; !ok {
+			details = append(details, utils.ValidationErrorDetail{
+				Field:   fmt.Sprintf("mappings[%d].speaker_label", i),
+				Message: fmt.Sprintf("unknown speaker label %q", m.SpeakerLabel),
+			})
+			continue
+		}
+		mappings[label] = entities.MeetingSpeaker{
+			MeetingID:    id,
+			SpeakerLabel: label,
+			Name:         strings.TrimSpace(m.Name),
+			Source:       entities.SpeakerSourceConfirmed,
+		}
+	}
+	if len(details) > 0 {
+		return nil, utils.NewValidationError("Validation Error", details)
+	}
+
+	confirmedNames := make(map[string]bool)
+	for label, s := range mappings {
+		if s.Source == entities.SpeakerSourceSuggested && req.AcceptSuggestions {
+			s.Source = entities.SpeakerSourceConfirmed
+			mappings[label] = s
+		}
+		if s.Source == entities.SpeakerSourceConfirmed && s.Name != "" {
+			confirmedNames[strings.ToLower(s.Name)] = true
+		}
+	}
+	speakers := make([]entities.MeetingSpeaker, 0, len(mappings))
+	for _, s := range mappings {
+		// A name confirmed for one speaker withdraws the suggestion of that name for another
+		if s.Source == entities.SpeakerSourceSuggested && confirmedNames[strings.ToLower(s.Name)] {
+			continue
+		}
+		speakers = append(speakers, s)
+	}
+	sort.Slice(speakers, func(i, j int) bool {
+		return order[speakers[i].SpeakerLabel] < order[speakers[j].SpeakerLabel]
+	})
+	if err := u.repo.ReplaceSpeakers(id, speakers); err != nil {
+		return nil, fmt.Errorf("failed to save speaker mappings: %w", err)
+	}
+	meeting.Speakers = speakers
+
+	names := speakerNames(meeting)
+	meeting.Participants = entities.StringList(withNames(meeting.Participants, names))
+	if pdfURL, ok := u.renderSummaryPDF(meeting, names); ok {
+		meeting.PDFUrl = pdfURL
+	}
+	if err := u.repo.Save(meeting); err != nil {
+		return nil, fmt.Errorf("failed to save meeting: %w", err)
+	}
+	return buildSpeakers(meeting), nil
+}
+
+// renderSummaryPDF renders the summary of a meeting to a new PDF with the confirmed participant
+// names in place of speaker labels. It returns false when there is no summary or rendering failed,
+// in which case the previous PDF is kept.
+func (u *meetingUseCase) renderSummaryPDF(meeting *entities.Meeting, names map[string]string) (string, bool) {
+	if u.renderer == nil || meeting.Summary == "" {
+		return "", false
+	}
+	language := "Indonesian"
+	if strings.EqualFold(meeting.TargetLanguage, "en") {
+		language = "English"
+	}
+	pdfPath, pdfURL := ragServices.NewSummaryPDFPath()
+	meta := ragServices.SummaryPDFMeta{
+		Language:     language,
+		Context:      meeting.Context,
+		Style:        meeting.Style,
+		Date:         meeting.MeetingDate,
+		Location:     meeting.Location,
+		Participants: strings.Join(meeting.Participants, ", "),
+		CustomerName: "Internal User",
+		CompanyName:  "Sensio",
+	}
+	if err := u.renderer.Render(utils.ReplaceSpeakerLabels(meeting.Summary, names), pdfPath, meta); err != nil {
+		utils.LogWarn("Meetings: Failed to re-render summary PDF of %s: %v", meeting.ID, err)
+		return "", false
+	}
+	return pdfURL, true
+}
+
+func (u *meetingUseCase) list(filter repositories.MeetingFilter, page, limit int) (*pipelineDtos.MeetingListResponseDTO, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	meetings, total, err := u.repo.List(filter, (page-1)*limit, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meetings: %w", err)
+	}
+
+	items := make([]pipelineDtos.MeetingListItemDTO, 0, len(meetings))
+	for _, m := range meetings {
+		items = append(items, toMeetingListItem(m))
+	}
+	return &pipelineDtos.MeetingListResponseDTO{
+		Meetings: items,
+		Total:    int(total),
+		Page:     page,
+		PerPage:  limit,
+	}, nil
+}
+
+func (u *meetingUseCase) load(id string) (*entities.Meeting, error) {
+	meeting, err := u.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMeetingNotFound
+		}
+		return nil, err
+	}
+	return meeting, nil
+}
+
+// meetingUtterances returns the stored speaker turns of a meeting, labelled as diarized
+func meetingUtterances(meeting *entities.Meeting) []whisperDtos.Utterance {
+	var utterances []whisperDtos.Utterance
+	for _, s := range meeting.Segments {
+		utterances = append(utterances, whisperDtos.Utterance{
+			SpeakerLabel: s.SpeakerLabel, StartMs: s.StartMs, EndMs: s.EndMs, Text: s.Text, Confidence: s.Confidence,
+		})
+	}
+	return utterances
+}
+
+// speakerNames maps speaker labels to their confirmed participant names
+func speakerNames(meeting *entities.Meeting) map[string]string {
+	names := make(map[string]string)
+	for _, s := range meeting.Speakers {
+		if s.Source == entities.SpeakerSourceConfirmed && s.Name != "" {
+			names[s.SpeakerLabel] = s.Name
+		}
+	}
+	return names
+}
+
+// buildSpeakers joins the talk time of each diarized speaker with its name mapping
+func buildSpeakers(meeting *entities.Meeting) []pipelineDtos.MeetingSpeakerDTO {
+	mappings := make(map[string]entities.MeetingSpeaker)
+	for _, s := range meeting.Speakers {
+		mappings[s.SpeakerLabel] = s
+	}
+	var speakers []pipelineDtos.MeetingSpeakerDTO
+	for _, stats := range utils.BuildSpeakerStats(meetingUtterances(meeting)) {
+		speaker := pipelineDtos.MeetingSpeakerDTO{
+			SpeakerLabel:    stats.SpeakerLabel,
+			TalkTimeMs:      stats.TalkTimeMs,
+			TalkTimePercent: stats.TalkTimePercent,
+			Turns:           stats.Turns,
+			Words:           stats.Words,
+		}
+		if m, ok := mappings[stats.SpeakerLabel]; ok {
+			speaker.Name = m.Name
+			speaker.Source = m.Source
+			speaker.Confidence = m.Confidence
+			speaker.Evidence = m.Evidence
+		}
+		speakers = append(speakers, speaker)
+	}
+	return speakers
+}
+
+// withNames appends the mapped names missing from the participants, in name order. A name refers
+// to a participant the way a spoken name does, so "Budi" is already listed as "Budi Santoso".
+func withNames(participants []string, names map[string]string) []string {
+	unique := make(map[string]bool)
+	var missing []string
+	for _, name := range names {
+		if _, ok := utils.MatchParticipant(name, participants); !ok && !unique[strings.ToLower(name)] {
+			unique[strings.ToLower(name)] = true
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return append(participants, missing...)
+}
+
+// segmentsFromTranscription flattens utterances (preferred) or plain segments into ordered rows
+func segmentsFromTranscription(meetingID string, result *whisperDtos.AsyncTranscriptionResultDTO) []entities.MeetingTranscriptSegment {
+	rows := make([]entities.MeetingTranscriptSegment, 0, len(result.Utterances))
+	if len(result.Utterances) > 0 {
+		for i, utt := range result.Utterances {
+			rows = append(rows, entities.MeetingTranscriptSegment{
+				MeetingID: meetingID, Position: i + 1, SpeakerLabel: utt.SpeakerLabel,
+				StartMs: utt.StartMs, EndMs: utt.EndMs, Text: utt.Text, Confidence: utt.Confidence,
+			})
+		}
+		return rows
+	}
+	for i, seg := range result.Segments {
+		rows = append(rows, entities.MeetingTranscriptSegment{
+			MeetingID: meetingID, Position: i + 1, StartMs: seg.StartMs, EndMs: seg.EndMs, Text: seg.Text,
+		})
+	}
+	return rows
+}
+
+func toMeetingListItem(m entities.Meeting) pipelineDtos.MeetingListItemDTO {
+	item := pipelineDtos.MeetingListItemDTO{
+		ID:              m.ID,
+		Title:           m.Title,
+		MacAddress:      m.MacAddress,
+		Status:          m.Status,
+		Language:        m.Language,
+		TargetLanguage:  m.TargetLanguage,
+		MeetingDate:     m.MeetingDate,
+		Location:        m.Location,
+		Participants:    []string(m.Participants),
+		StartedAt:       m.StartedAt.Format(time.RFC3339),
+		DurationSeconds: m.DurationSeconds,
+		ParentID:        m.ParentID,
+		RerunFromStage:  m.RerunFromStage,
+	}
+	if m.CompletedAt != nil {
+		item.CompletedAt = m.CompletedAt.Format(time.RFC3339)
+	}
+	return item
+}
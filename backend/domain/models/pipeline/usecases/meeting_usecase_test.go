@@ -0,0 +1,427 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sensio/domain/common/tasks"
+	"sensio/domain/common/utils"
+	pipelineDtos "sensio/domain/models/pipeline/dtos"
+	"sensio/domain/models/pipeline/entities"
+	"sensio/domain/models/pipeline/repositories"
+	ragDtos "sensio/domain/models/rag/dtos"
+	ragServices "sensio/domain/models/rag/services"
+	whisperDtos "sensio/domain/models/whisper/dtos"
+	speechUsecases "sensio/domain/models/whisper/usecases"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeMeetingRepository is an in-memory IMeetingRepository
+type fakeMeetingRepository struct {
+	mu       sync.Mutex
+	meetings map[string]entities.Meeting
+}
+
+func newFakeMeetingRepository() *fakeMeetingRepository {
+	return &fakeMeetingRepository{meetings: make(map[string]entities.Meeting)}
+}
+
+func (r *fakeMeetingRepository) Save(meeting *entities.Meeting) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing := r.meetings[meeting.ID]
+	copied := *meeting
+	copied.Segments = existing.Segments
+	copied.ActionItems = existing.ActionItems
+	copied.Decisions = existing.Decisions
+	copied.OpenIssues = existing.OpenIssues
+	copied.Risks = existing.Risks
+	copied.Speakers = existing.Speakers
+	r.meetings[meeting.ID] = copied
+	return nil
+}
+
+func (r *fakeMeetingRepository) GetByID(id string) (*entities.Meeting, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.meetings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &m, nil
+}
+
+func (r *fakeMeetingRepository) List(filter repositories.MeetingFilter, offset, limit int) ([]entities.Meeting, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entities.Meeting
+	for _, m := range r.meetings {
+		if filter.MacAddress != "" && m.MacAddress != filter.MacAddress {
+			continue
+		}
+		if filter.ParentID != "" && m.ParentID != filter.ParentID {
+			continue
+		}
+		if filter.Query != "" {
+			q := strings.ToLower(filter.Query)
+			hit := strings.Contains(strings.ToLower(m.Summary+m.Transcription+m.Title), q)
+			for _, s := range m.Segments {
+				hit = hit || strings.Contains(strings.ToLower(s.Text), q)
+			}
+			if !hit {
+				continue
+			}
+		}
+		out = append(out, m)
+	}
+	return out, int64(len(out)), nil
+}
+
+func (r *fakeMeetingRepository) ReplaceSegments(meetingID string, segments []entities.MeetingTranscriptSegment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.meetings[meetingID]
+	m.Segments = segments
+	r.meetings[meetingID] = m
+	return nil
+}
+
+func (r *fakeMeetingRepository) ReplaceSummaryArtifacts(meetingID string, actionItems []entities.MeetingActionItem, decisions []entities.MeetingDecision, openIssues []entities.MeetingOpenIssue, risks []entities.MeetingRisk) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.meetings[meetingID]
+	m.ActionItems = actionItems
+	m.Decisions = decisions
+	m.OpenIssues = openIssues
+	m.Risks = risks
+	r.meetings[meetingID] = m
+	return nil
+}
+
+func (r *fakeMeetingRepository) ReplaceSpeakers(meetingID string, speakers []entities.MeetingSpeaker) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.meetings[meetingID]
+	m.Speakers = speakers
+	r.meetings[meetingID] = m
+	return nil
+}
+
+type fakeSummaryRenderer struct {
+	summaries []string
+	meta      ragServices.SummaryPDFMeta
+}
+
+func (r *fakeSummaryRenderer) Render(summary string, path string, meta ragServices.SummaryPDFMeta) error {
+	r.summaries = append(r.summaries, summary)
+	r.meta = meta
+	return nil
+}
+
+func TestMeetingUseCase_ArchiveAndRetrieve(t *testing.T) {
+	repo := newFakeMeetingRepository()
+	uc := NewMeetingUseCase(repo, nil)
+
+	req := pipelineDtos.PipelineRequestDTO{
+		Language:       "id",
+		TargetLanguage: "en",
+		Context:        "Budget review",
+		Participants:   []string{"Alice", "Bob"},
+		MacAddress:     "AA:BB:CC:DD:EE:FF",
+	}
+	assert.NoError(t, uc.ArchiveStarted("task-1", req, time.Now()))
+
+	assert.NoError(t, uc.ArchiveTranscription("task-1", &whisperDtos.AsyncTranscriptionResultDTO{
+		Transcription: "halo semua",
+		Utterances: []whisperDtos.Utterance{
+			{SpeakerLabel: "Speaker 1", StartMs: 0, EndMs: 1500, Text: "Kita bahas anggaran Q3"},
+			{SpeakerLabel: "Speaker 2", StartMs: 1500, EndMs: 3000, Text: "Setuju"},
+		},
+	}))
+	assert.NoError(t, uc.ArchiveTranslation("task-1", "hello everyone"))
+	assert.NoError(t, uc.ArchiveSummary("task-1", &ragDtos.RAGSummaryResponseDTO{
+		Summary:     "# Minutes",
+		ActionItems: []ragDtos.ActionItem{{ID: 7, Task: "Send budget draft", PIC: "Alice"}},
+		Decisions:   []ragDtos.Decision{{ID: 1, Description: "Approve Q3 budget"}},
+		Risks:       []ragDtos.Risk{{ID: 1, Description: "Vendor delay", Impact: "High"}},
+		CanonicalSummary: &ragDtos.CanonicalMeetingSummary{
+			Metadata: ragDtos.SummaryMetadata{MeetingTitle: "Q3 Budget Review", Language: "en"},
+		},
+	}))
+	assert.NoError(t, uc.ArchiveOutcome("task-1", "completed", 12.5))
+
+	detail, err := uc.GetMeeting("task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Q3 Budget Review", detail.Title, "canonical title should replace the request context")
+	assert.Equal(t, "completed", detail.Status)
+	assert.Equal(t, "hello everyone", detail.TranslatedText)
+	assert.Len(t, detail.Utterances, 2)
+	assert.Equal(t, "Speaker 2", detail.Utterances[1].SpeakerLabel)
+	assert.Len(t, detail.ActionItems, 1)
+	assert.Equal(t, 1, detail.ActionItems[0].ID, "action items are renumbered by position")
+	assert.Equal(t, "Alice", detail.ActionItems[0].PIC)
+	assert.Len(t, detail.Decisions, 1)
+	assert.Len(t, detail.Risks, 1)
+	assert.NotNil(t, detail.CanonicalSummary)
+	assert.NotEmpty(t, detail.CompletedAt)
+
+	export, err := uc.ExportMeeting("task-1", "vtt")
+	assert.NoError(t, err)
+	assert.Equal(t, "transcript_task-1.vtt", export.FileName)
+	assert.Contains(t, string(export.Content), "00:00:01.500 --> 00:00:03.000\n<v Speaker 2>Setuju")
+	assert.Contains(t, string(export.Content), "NOTE Timestamps are estimated")
+	assert.False(t, export.AlignedTimestamps)
+	_, err = uc.ExportMeeting("missing", "srt")
+	assert.ErrorIs(t, err, ErrMeetingNotFound)
+
+	found, err := uc.SearchMeetings("anggaran", "", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.Total)
+
+	_, err = uc.SearchMeetings("  ", "", 1, 10)
+	assert.Error(t, err)
+
+	_, err = uc.GetMeeting("missing")
+	assert.ErrorIs(t, err, ErrMeetingNotFound)
+}
+
+func TestMeetingUseCase_SpeakerMapping(t *testing.T) {
+	repo := newFakeMeetingRepository()
+	renderer := &fakeSummaryRenderer{}
+	uc := NewMeetingUseCase(repo, renderer)
+
+	req := pipelineDtos.PipelineRequestDTO{Language: "id", Participants: []string{"Budi Santoso", "Alice"}}
+	assert.NoError(t, uc.ArchiveStarted("task-1", req, time.Now()))
+	transcription := &whisperDtos.AsyncTranscriptionResultDTO{
+		Transcription:     "Speaker 1: Nama saya Budi. Speaker 2: Hi, I'm Alice.",
+		AlignedTimestamps: true,
+		Utterances: []whisperDtos.Utterance{
+			{SpeakerLabel: "Speaker 1", StartMs: 0, EndMs: 3000, Text: "Nama saya Budi, kita mulai."},
+			{SpeakerLabel: "Speaker 2", StartMs: 3000, EndMs: 4000, Text: "Hi, I'm Alice."},
+		},
+	}
+	assert.NoError(t, uc.ArchiveTranscription("task-1", transcription))
+	assert.NoError(t, uc.ArchiveSummary("task-1", &ragDtos.RAGSummaryResponseDTO{
+		Summary:     "| Send the draft | Speaker 2 |",
+		ActionItems: []ragDtos.ActionItem{{Task: "Send the draft", PIC: "Speaker 2"}},
+		CanonicalSummary: &ragDtos.CanonicalMeetingSummary{
+			Metadata:    ragDtos.SummaryMetadata{MeetingTitle: "Kickoff", Language: "id"},
+			ActionItems: []ragDtos.ActionItem{{Task: "Send the draft", PIC: "Speaker 2"}},
+		},
+	}))
+
+	speakers, err := uc.GetSpeakers("task-1")
+	assert.NoError(t, err)
+	assert.Len(t, speakers, 2)
+	assert.Equal(t, "Budi Santoso", speakers[0].Name)
+	assert.Equal(t, entities.SpeakerSourceSuggested, speakers[0].Source)
+	assert.Equal(t, "Nama saya Budi", speakers[0].Evidence)
+	assert.Equal(t, int64(3000), speakers[0].TalkTimeMs)
+	assert.Equal(t, 75.0, speakers[0].TalkTimePercent)
+	assert.Equal(t, "Alice", speakers[1].Name)
+
+	// Suggestions are not applied until confirmed
+	detail, err := uc.GetMeeting("task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Speaker 2", detail.ActionItems[0].PIC)
+
+	_, err = uc.UpdateSpeakers("task-1", pipelineDtos.MeetingSpeakersUpdateDTO{
+		Mappings: []pipelineDtos.MeetingSpeakerMappingDTO{{SpeakerLabel: "Speaker 9", Name: "Nobody"}},
+	})
+	var valErr *utils.ValidationError
+	assert.True(t, errors.As(err, &valErr))
+
+	speakers, err = uc.UpdateSpeakers("task-1", pipelineDtos.MeetingSpeakersUpdateDTO{
+		Mappings:          []pipelineDtos.MeetingSpeakerMappingDTO{{SpeakerLabel: "Speaker 1", Name: "Budi"}},
+		AcceptSuggestions: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Budi", speakers[0].Name)
+	assert.Equal(t, entities.SpeakerSourceConfirmed, speakers[0].Source)
+	assert.Equal(t, entities.SpeakerSourceConfirmed, speakers[1].Source)
+
+	detail, err = uc.GetMeeting("task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Speaker 1", detail.Utterances[0].SpeakerLabel, "utterances keep their diarized labels")
+	assert.Equal(t, "Alice", detail.ActionItems[0].PIC)
+	assert.Equal(t, "| Send the draft | Alice |", detail.Summary)
+	assert.Equal(t, "Alice", detail.CanonicalSummary.ActionItems[0].PIC)
+	assert.Equal(t, []string{"Budi Santoso", "Alice"}, detail.Participants, "Budi is already listed as Budi Santoso")
+
+	// The summary PDF is rendered again with the confirmed names
+	assert.Equal(t, []string{"| Send the draft | Alice |"}, renderer.summaries)
+	assert.Equal(t, "Budi Santoso, Alice", renderer.meta.Participants)
+	assert.True(t, strings.HasPrefix(detail.PDFUrl, "/uploads/reports/summary_"))
+
+	export, err := uc.ExportMeeting("task-1", "srt")
+	assert.NoError(t, err)
+	assert.Contains(t, string(export.Content), "Budi: Nama saya Budi")
+	assert.True(t, export.AlignedTimestamps, "aligned times are kept with the meeting")
+	assert.True(t, detail.AlignedTimestamps)
+
+	run, err := uc.LoadRun("task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Budi: Nama saya Budi. Alice: Hi, I'm Alice.", run.Transcription.Transcription)
+	assert.Equal(t, "Alice", run.Transcription.Utterances[1].SpeakerLabel)
+
+	// Archiving the transcript again keeps the confirmed names
+	assert.NoError(t, uc.ArchiveTranscription("task-1", transcription))
+	speakers, err = uc.GetSpeakers("task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Budi", speakers[0].Name)
+	assert.Equal(t, entities.SpeakerSourceConfirmed, speakers[0].Source)
+}
+
+func TestPipelineUseCase_PersistsMeetingArtifacts(t *testing.T) {
+	repo := newFakeMeetingRepository()
+	meetingUC := NewMeetingUseCase(repo, nil)
+
+	cache := tasks.NewBadgerTaskCache(NewMockBadgerService(), "cache:task:")
+	store := tasks.NewStatusStore[pipelineDtos.PipelineStatusDTO]()
+	pipelineUC := NewPipelineUseCase(
+		&MockTranscribeUseCase{},
+		&MockTranslateUseCase{},
+		&MockSummaryUseCase{},
+		cache,
+		store,
+		&MockMQTTPublisher{},
+		meetingUC,
+		newTestJobQueue(t),
+	)
+
+	audioPath := t.TempDir() + "/meeting.wav"
+	assert.NoError(t, os.WriteFile(audioPath, []byte("fake audio"), 0644))
+
+	taskID, err := pipelineUC.ExecutePipeline(context.Background(), audioPath, pipelineDtos.PipelineRequestDTO{
+		Language:       "id",
+		TargetLanguage: "en",
+		Summarize:      true,
+		MacAddress:     "AA:BB:CC:DD:EE:FF",
+	}, "")
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		m, err := repo.GetByID(taskID)
+		return err == nil && m.Status == "completed"
+	}, 2*time.Second, 20*time.Millisecond)
+
+	detail, err := meetingUC.GetMeeting(taskID)
+	assert.NoError(t, err)
+	assert.Equal(t, "This is a test transcription", detail.Transcription)
+	assert.Equal(t, "This is a test translation", detail.TranslatedText)
+	assert.Equal(t, "This is a test summary", detail.Summary)
+}
+
+// countingTranscribeUseCase counts how often audio is transcribed
+type countingTranscribeUseCase struct {
+	MockTranscribeUseCase
+	calls int32
+}
+
+func (m *countingTranscribeUseCase) TranscribeAudioSync(ctx context.Context, audioPath string, opts speechUsecases.TranscribeOptions) (*whisperDtos.AsyncTranscriptionResultDTO, error) {
+	atomic.AddInt32(&m.calls, 1)
+	return m.MockTranscribeUseCase.TranscribeAudioSync(ctx, audioPath, opts)
+}
+
+// recordingSummaryUseCase records the input of the last summary
+type recordingSummaryUseCase struct {
+	MockSummaryUseCase
+	mu    sync.Mutex
+	text  string
+	style string
+}
+
+func (m *recordingSummaryUseCase) SummarizeTextSync(ctx context.Context, text, language, meetingContext, style, date, location, participants, macAddress string) (*ragDtos.RAGSummaryResponseDTO, error) {
+	m.mu.Lock()
+	m.text, m.style = text, style
+	m.mu.Unlock()
+	return &ragDtos.RAGSummaryResponseDTO{Summary: "Summary in " + style + " style"}, nil
+}
+
+func TestPipelineUseCase_RerunFromSummary(t *testing.T) {
+	repo := newFakeMeetingRepository()
+	meetingUC := NewMeetingUseCase(repo, nil)
+	transcribeUC := &countingTranscribeUseCase{}
+	summaryUC := &recordingSummaryUseCase{}
+
+	store := tasks.NewStatusStore[pipelineDtos.PipelineStatusDTO]()
+	pipelineUC := NewPipelineUseCase(
+		transcribeUC,
+		&MockTranslateUseCase{},
+		summaryUC,
+		tasks.NewBadgerTaskCache(NewMockBadgerService(), "cache:task:"),
+		store,
+		&MockMQTTPublisher{},
+		meetingUC,
+		newTestJobQueue(t),
+	)
+	waitCompleted := func(taskID string) {
+		assert.Eventually(t, func() bool {
+			m, err := repo.GetByID(taskID)
+			return err == nil && m.Status == "completed"
+		}, 2*time.Second, 20*time.Millisecond)
+	}
+
+	audioPath := t.TempDir() + "/meeting.wav"
+	assert.NoError(t, os.WriteFile(audioPath, []byte("fake audio"), 0644))
+	parentID, err := pipelineUC.ExecutePipeline(context.Background(), audioPath, pipelineDtos.PipelineRequestDTO{
+		Language:       "id",
+		TargetLanguage: "en",
+		Style:          "minutes",
+		Summarize:      true,
+	}, "")
+	assert.NoError(t, err)
+	waitCompleted(parentID)
+
+	style := "executive"
+	rerunID, err := pipelineUC.RerunPipeline(parentID, pipelineDtos.PipelineRerunRequestDTO{FromStage: "summary", Style: &style})
+	assert.NoError(t, err)
+	waitCompleted(rerunID)
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&transcribeUC.calls), "the rerun reuses the transcript")
+	summaryUC.mu.Lock()
+	assert.Equal(t, "This is a test translation", summaryUC.text, "the rerun summarizes the persisted translation")
+	assert.Equal(t, "executive", summaryUC.style)
+	summaryUC.mu.Unlock()
+
+	status, _ := store.Get(rerunID)
+	if assert.NotNil(t, status) {
+		assert.Equal(t, parentID, status.ParentTaskID)
+		assert.True(t, status.Stages["transcription"].Reused)
+		assert.True(t, status.Stages["translation"].Reused)
+		assert.False(t, status.Stages["summary"].Reused)
+	}
+
+	rerun, err := meetingUC.GetMeeting(rerunID)
+	assert.NoError(t, err)
+	assert.Equal(t, parentID, rerun.ParentID)
+	assert.Equal(t, "summary", rerun.RerunFromStage)
+	assert.Equal(t, "This is a test transcription", rerun.Transcription)
+	assert.Equal(t, "Summary in executive style", rerun.Summary)
+
+	parent, err := meetingUC.GetMeeting(parentID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{rerunID}, parent.Reruns)
+	assert.Equal(t, "Summary in minutes style", parent.Summary, "the original run is left untouched")
+
+	var valErr *utils.ValidationError
+	_, err = pipelineUC.RerunPipeline(parentID, pipelineDtos.PipelineRerunRequestDTO{FromStage: "transcription"})
+	assert.True(t, errors.As(err, &valErr), "transcription cannot be re-run without the audio")
+	target := "fr"
+	_, err = pipelineUC.RerunPipeline(parentID, pipelineDtos.PipelineRerunRequestDTO{FromStage: "summary", TargetLanguage: &target})
+	assert.True(t, errors.As(err, &valErr), "a new target language needs a new translation")
+	_, err = pipelineUC.RerunPipeline("missing", pipelineDtos.PipelineRerunRequestDTO{FromStage: "summary"})
+	assert.ErrorIs(t, err, ErrMeetingNotFound)
+
+	store.Set(parentID, &pipelineDtos.PipelineStatusDTO{TaskID: parentID, OverallStatus: "processing"})
+	_, err = pipelineUC.RerunPipeline(parentID, pipelineDtos.PipelineRerunRequestDTO{FromStage: "summary"})
+	assert.ErrorIs(t, err, ErrTaskNotFinished)
+}
@@ -5,13 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/providers"
 	"sensio/domain/common/tasks"
 	"sensio/domain/common/utils"
 	pipelineDtos "sensio/domain/models/pipeline/dtos"
 	ragUsecases "sensio/domain/models/rag/usecases"
+	whisperDtos "sensio/domain/models/whisper/dtos"
 	speechUsecases "sensio/domain/models/whisper/usecases"
 	"strings"
-	"sync"
 	"time"
 
 	"encoding/json"
@@ -20,6 +22,32 @@ import (
 	"github.com/google/uuid"
 )
 
+// PipelineJobType is the job type of meeting pipeline runs
+const PipelineJobType = "pipeline"
+
+// ErrTaskNotFinished is returned when a task is re-run before it reached a terminal status
+var ErrTaskNotFinished = errors.New("task has not finished yet")
+
+// rerunStages are the stages a task can be re-executed from. Transcription needs the original
+// audio, which is not kept, and refinement is produced together with the transcript.
+var rerunStages = map[string]bool{"translation": true, "summary": true}
+
+// pipelineJob is the payload of a queued pipeline run
+type pipelineJob struct {
+	InputPath string                          `json:"input_path"`
+	Request   pipelineDtos.PipelineRequestDTO `json:"request"`
+	Rerun     *pipelineRerun                  `json:"rerun,omitempty"`
+}
+
+// pipelineRerun carries the outputs of the parent run that a rerun reuses instead of recomputing
+type pipelineRerun struct {
+	ParentTaskID   string                                   `json:"parent_task_id"`
+	FromStage      string                                   `json:"from_stage"`
+	Provider       string                                   `json:"provider,omitempty"`
+	Transcription  *whisperDtos.AsyncTranscriptionResultDTO `json:"transcription"`
+	TranslatedText string                                   `json:"translated_text,omitempty"` // set when translation is reused
+}
+
 type mqttPublisher interface {
 	Publish(topic string, qos byte, retained bool, payload interface{}) error
 }
@@ -27,33 +55,30 @@ type mqttPublisher interface {
 // PipelineUseCase orchestrates the 4-stage meeting processing pipeline:
 // Transcription -> Refinement -> Translation -> Summary
 //
-// ARCHITECTURE NOTE: Pipeline results are stored in in-memory/cache status stores
-// with TTL (default 24h). Structured artifacts (utterances, segments, action items,
-// decisions, etc.) are EPHEMERAL and will be lost after TTL expiry or server restart.
-//
-// For persistent storage of structured meeting artifacts, future work should:
-// 1. Add tables for transcription_segments, meeting_decisions, action_items, etc.
-// 2. Persist structured results after each stage completion
-// 3. Implement retrieval APIs for historical meeting data
+// ARCHITECTURE NOTE: Live task status is kept in in-memory/cache status stores
+// with TTL (default 24h) for polling. Structured artifacts (utterances, action items,
+// decisions, open issues, risks) are additionally written to the meetings tables
+// through MeetingArchiver as each stage completes, so they survive TTL expiry and
+// restarts and can be reopened via /api/models/pipeline/meetings.
 //
-// Current persistence: Only recording metadata is persisted to DB
-// (see: domain/recordings/entities/recording.go)
+// See: domain/models/pipeline/entities/meeting_entity.go
 type PipelineUseCase interface {
 	ExecutePipeline(ctx context.Context, inputPath string, req pipelineDtos.PipelineRequestDTO, idempotencyKey string) (string, error)
 	ExecutePipelineWithSession(ctx context.Context, inputPath string, req pipelineDtos.PipelineRequestDTO, idempotencyKey string, sessionID string) (string, error)
 	CheckIdempotency(idempotencyKey string, audioHash string, req pipelineDtos.PipelineRequestDTO) (string, bool)
 	CancelTask(taskID string) error
+	RerunPipeline(taskID string, req pipelineDtos.PipelineRerunRequestDTO) (string, error)
 }
 
 type pipelineUseCase struct {
-	transcribeUC   speechUsecases.TranscribeUseCase
-	translateUC    ragUsecases.TranslateUseCase
-	summaryUC      ragUsecases.SummaryUseCase
-	cache          *tasks.BadgerTaskCache
-	store          *tasks.StatusStore[pipelineDtos.PipelineStatusDTO]
-	mqttSvc        mqttPublisher
-	cancelRegistry map[string]context.CancelFunc
-	registryMu     sync.RWMutex
+	transcribeUC speechUsecases.TranscribeUseCase
+	translateUC  ragUsecases.TranslateUseCase
+	summaryUC    ragUsecases.SummaryUseCase
+	cache        *tasks.BadgerTaskCache
+	store        *tasks.StatusStore[pipelineDtos.PipelineStatusDTO]
+	mqttSvc      mqttPublisher
+	meetings     MeetingArchiver
+	jobs         *tasks.JobQueue
 }
 
 func NewPipelineUseCase(
@@ -63,16 +88,35 @@ func NewPipelineUseCase(
 	cache *tasks.BadgerTaskCache,
 	store *tasks.StatusStore[pipelineDtos.PipelineStatusDTO],
 	mqttSvc mqttPublisher,
+	meetings MeetingArchiver,
+	jobs *tasks.JobQueue,
 ) PipelineUseCase {
-	return &pipelineUseCase{
-		transcribeUC:   transcribeUC,
-		translateUC:    translateUC,
-		summaryUC:      summaryUC,
-		cache:          cache,
-		store:          store,
-		mqttSvc:        mqttSvc,
-		cancelRegistry: make(map[string]context.CancelFunc),
+	u := &pipelineUseCase{
+		transcribeUC: transcribeUC,
+		translateUC:  translateUC,
+		summaryUC:    summaryUC,
+		cache:        cache,
+		store:        store,
+		mqttSvc:      mqttSvc,
+		meetings:     meetings,
+		jobs:         jobs,
+	}
+
+	timeout, err := time.ParseDuration(utils.AppConfig.PipelineAsyncTimeout)
+	if err != nil {
+		timeout = 12 * time.Hour
 	}
+	// Pipelines are not retried: a failed stage is reported to the client, which decides to resubmit
+	jobs.Register(PipelineJobType, u.runJob, tasks.JobTypeOptions{
+		MaxAttempts: 1,
+		Timeout:     timeout,
+		OnCancel: func(job *tasks.Job) {
+			if status := u.loadStatus(job.ID); status != nil && !isTerminalStatus(status.OverallStatus) {
+				u.markCancelled(job.ID, status)
+			}
+		},
+	})
+	return u
 }
 
 func (u *pipelineUseCase) CheckIdempotency(idempotencyKey string, audioHash string, req pipelineDtos.PipelineRequestDTO) (string, bool) {
@@ -174,8 +218,108 @@ func (u *pipelineUseCase) ExecutePipelineWithSession(ctx context.Context, inputP
 		defer u.cache.Delete(lockKey)
 	}
 
+	taskID, err := u.startTask(inputPath, req, nil)
+	if err != nil {
+		return "", err
+	}
+	if idempotencyHash != "" {
+		_ = u.cache.Set(idempotencyHash, taskID)
+	}
+	return taskID, nil
+}
+
+// RerunPipeline re-executes a finished task from a later stage as a new task. The persisted outputs of the
+// earlier stages are reused, so the audio is not transcribed again; the new meeting records its parent.
+func (u *pipelineUseCase) RerunPipeline(taskID string, rerunReq pipelineDtos.PipelineRerunRequestDTO) (string, error) {
+	if u.meetings == nil {
+		return "", errors.New("meeting storage is not available")
+	}
+
+	fromStage := strings.ToLower(strings.TrimSpace(rerunReq.FromStage))
+	provider := providers.NormalizeProvider(rerunReq.Provider)
+	var details []utils.ValidationErrorDetail
+	if !rerunStages[fromStage] {
+		details = append(details, utils.ValidationErrorDetail{Field: "from_stage", Message: "must be one of: translation, summary"})
+	}
+	if provider != "" {
+		if !providers.IsValidProvider(provider) {
+			details = append(details, utils.ValidationErrorDetail{Field: "provider", Message: "must be one of: gemini, openai, groq, orion"})
+		} else if err := providers.ValidateProviderConfig(provider, utils.GetConfig()); err != nil {
+			details = append(details, utils.ValidationErrorDetail{Field: "provider", Message: err.Error()})
+		}
+	}
+	if len(details) > 0 {
+		return "", utils.NewValidationError("Validation Error", details)
+	}
+
+	if status := u.loadStatus(taskID); status != nil && !isTerminalStatus(status.OverallStatus) {
+		return "", ErrTaskNotFinished
+	}
+	run, err := u.meetings.LoadRun(taskID)
+	if err != nil {
+		return "", err
+	}
+	if !isTerminalStatus(run.Status) {
+		return "", ErrTaskNotFinished
+	}
+
+	req := run.Request
+	if rerunReq.TargetLanguage != nil && *rerunReq.TargetLanguage != req.TargetLanguage {
+		if fromStage != "translation" {
+			details = append(details, utils.ValidationErrorDetail{Field: "target_language", Message: "can only change when re-running from translation"})
+		}
+		req.TargetLanguage = *rerunReq.TargetLanguage
+	}
+	if rerunReq.Context != nil {
+		req.Context = *rerunReq.Context
+	}
+	if rerunReq.Style != nil {
+		req.Style = *rerunReq.Style
+	}
+	if rerunReq.Date != nil {
+		req.Date = *rerunReq.Date
+	}
+	if rerunReq.Location != nil {
+		req.Location = *rerunReq.Location
+	}
+	if rerunReq.Participants != nil {
+		req.Participants = rerunReq.Participants
+	}
+	if fromStage == "summary" {
+		req.Summarize = true
+	}
+	if rerunReq.Summarize != nil {
+		req.Summarize = *rerunReq.Summarize
+	}
+
+	rerun := &pipelineRerun{ParentTaskID: taskID, FromStage: fromStage, Provider: provider, Transcription: run.Transcription}
+	translates := req.TargetLanguage != "" && req.Language != req.TargetLanguage
+	switch {
+	case run.Transcription == nil:
+		details = append(details, utils.ValidationErrorDetail{Field: "from_stage", Message: "task has no transcript to reuse"})
+	case fromStage == "summary" && !req.Summarize:
+		details = append(details, utils.ValidationErrorDetail{Field: "summarize", Message: "cannot be disabled when re-running from summary"})
+	case fromStage == "summary" && translates && run.TranslatedText == "":
+		details = append(details, utils.ValidationErrorDetail{Field: "from_stage", Message: "task has no translation to reuse; re-run from translation"})
+	}
+	if len(details) > 0 {
+		return "", utils.NewValidationError("Validation Error", details)
+	}
+	if fromStage == "summary" {
+		rerun.TranslatedText = run.TranslatedText
+	}
+	refine := run.Transcription.RefinedText != ""
+	req.Refine = &refine
+
+	utils.LogInfo("Pipeline: Re-running task %s from stage '%s'", taskID, fromStage)
+	return u.startTask("", req, rerun)
+}
+
+// startTask records the initial status and meeting of a new task and queues it for execution
+func (u *pipelineUseCase) startTask(inputPath string, req pipelineDtos.PipelineRequestDTO, rerun *pipelineRerun) (string, error) {
 	taskID := uuid.New().String()
-	now := time.Now().Format(time.RFC3339)
+	startedAt := time.Now()
+	now := startedAt.Format(time.RFC3339)
 
 	status := pipelineDtos.PipelineStatusDTO{
 		TaskID:        taskID,
@@ -201,40 +345,68 @@ func (u *pipelineUseCase) ExecutePipelineWithSession(ctx context.Context, inputP
 		status.Stages["summary"] = pipelineDtos.PipelineStageStatus{Status: "skipped"}
 	}
 
-	u.saveStatus(taskID, status)
-	if idempotencyHash != "" {
-		_ = u.cache.Set(idempotencyHash, taskID)
+	if rerun != nil {
+		status.ParentTaskID = rerun.ParentTaskID
+		status.RerunFromStage = rerun.FromStage
+		status.Provider = rerun.Provider
 	}
 
-	timeout, err := time.ParseDuration(utils.AppConfig.PipelineAsyncTimeout)
-	if err != nil {
-		timeout = 12 * time.Hour
+	u.saveStatus(taskID, status)
+	u.archive(taskID, "started", func(m MeetingArchiver) error {
+		if err := m.ArchiveStarted(taskID, req, startedAt); err != nil || rerun == nil {
+			return err
+		}
+		return m.ArchiveLineage(taskID, rerun.ParentTaskID, rerun.FromStage)
+	})
+
+	if _, err := u.jobs.Enqueue(PipelineJobType, taskID, pipelineJob{InputPath: inputPath, Request: req, Rerun: rerun}); err != nil {
+		u.failStage(taskID, req.MacAddress, "transcription", err)
+		return "", err
 	}
 
 	u.publishEvent(taskID, req.MacAddress, "accepted", "pending", "", "", 0, nil)
 
-	asyncCtx, cancel := context.WithTimeout(context.Background(), timeout)
-	u.registerCancel(taskID, cancel)
-	go func() {
-		defer cancel()
-		defer u.unregisterCancel(taskID)
-		u.runPipelineAsync(asyncCtx, taskID, inputPath, req)
-	}()
-
 	return taskID, nil
 }
 
-func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, inputPath string, req pipelineDtos.PipelineRequestDTO) {
+// runJob runs the pipeline of a queued job and reports a failed stage as the job error
+func (u *pipelineUseCase) runJob(ctx context.Context, job *tasks.Job) error {
+	var payload pipelineJob
+	if err := job.Decode(&payload); err != nil {
+		return tasks.Permanent(err)
+	}
+	if payload.Rerun != nil && payload.Rerun.Provider != "" {
+		ctx = providers.WithProvider(ctx, payload.Rerun.Provider)
+	}
+	u.runPipelineAsync(ctx, job.ID, payload.InputPath, payload.Request, payload.Rerun)
+
+	status := u.loadStatus(job.ID)
+	if status == nil || status.OverallStatus != "failed" {
+		return nil
+	}
+	for name, stage := range status.Stages {
+		if stage.Status == "failed" {
+			return fmt.Errorf("stage '%s' failed: %s", name, stage.Error)
+		}
+	}
+	return errors.New("pipeline failed")
+}
+
+// runPipelineAsync executes the pipeline stages of a task. For a rerun, the stages before
+// rerun.FromStage are not executed again but completed with the outputs of the parent run.
+func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, inputPath string, req pipelineDtos.PipelineRequestDTO, rerun *pipelineRerun) {
 	// Determine if input audio should be preserved (meeting-summary jobs)
 	preserveInputAudio := req.Summarize
-	if preserveInputAudio {
+	if rerun != nil {
+		utils.LogInfo("Pipeline Task %s: Re-running task %s from stage '%s'", taskID, rerun.ParentTaskID, rerun.FromStage)
+	} else if preserveInputAudio {
 		utils.LogInfo("Pipeline Task %s: Preserving input audio (meeting summary) | path=%s", taskID, inputPath)
 	} else {
 		defer os.Remove(inputPath)
 		utils.LogInfo("Pipeline Task %s: Will delete input audio after pipeline | path=%s", taskID, inputPath)
 	}
 
-	status, _ := u.store.Get(taskID)
+	status := u.loadStatus(taskID)
 	if status == nil {
 		return
 	}
@@ -274,7 +446,13 @@ func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, i
 		TerminalContext: []string{req.MacAddress},
 	}
 
-	transResult, err := u.transcribeUC.TranscribeAudioSync(ctx, inputPath, transOpts)
+	var transResult *whisperDtos.AsyncTranscriptionResultDTO
+	var err error
+	if rerun != nil {
+		transResult = rerun.Transcription
+	} else {
+		transResult, err = u.transcribeUC.TranscribeAudioSync(ctx, inputPath, transOpts)
+	}
 	// Check for cancellation immediately after blocking call
 	select {
 	case <-ctx.Done():
@@ -312,8 +490,12 @@ func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, i
 		Status:          "completed",
 		Result:          transResult, // Store full result with utterances, segments, etc.
 		DurationSeconds: time.Since(startTime).Seconds(),
+		Reused:          rerun != nil,
 	}
 	u.saveStatus(taskID, *status)
+	u.archive(taskID, "transcription", func(m MeetingArchiver) error {
+		return m.ArchiveTranscription(taskID, transResult)
+	})
 	u.publishEvent(taskID, req.MacAddress, "stage_update", "processing", "transcription", "completed", 100, nil)
 
 	// Stage 2: Refinement
@@ -339,6 +521,7 @@ func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, i
 			Status:          "completed",
 			Result:          refinedText,
 			DurationSeconds: time.Since(startTime).Seconds(),
+			Reused:          rerun != nil,
 		}
 		u.saveStatus(taskID, *status)
 		u.publishEvent(taskID, req.MacAddress, "stage_update", "processing", "refinement", "completed", 100, nil)
@@ -360,7 +543,13 @@ func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, i
 		u.saveStatus(taskID, *status)
 		u.publishEvent(taskID, req.MacAddress, "stage_update", "processing", "translation", "processing", 0, nil)
 
-		transText, err := u.translateUC.TranslateTextSync(ctx, refinedText, req.TargetLanguage, req.MacAddress)
+		reuseTranslation := rerun != nil && rerun.FromStage == "summary"
+		transText := ""
+		if reuseTranslation {
+			transText = rerun.TranslatedText
+		} else {
+			transText, err = u.translateUC.TranslateTextSync(ctx, refinedText, req.TargetLanguage, req.MacAddress)
+		}
 		// Check for cancellation immediately after blocking call
 		select {
 		case <-ctx.Done():
@@ -378,8 +567,12 @@ func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, i
 			Status:          "completed",
 			Result:          finalText,
 			DurationSeconds: time.Since(startTime).Seconds(),
+			Reused:          reuseTranslation,
 		}
 		u.saveStatus(taskID, *status)
+		u.archive(taskID, "translation", func(m MeetingArchiver) error {
+			return m.ArchiveTranslation(taskID, finalText)
+		})
 		u.publishEvent(taskID, req.MacAddress, "stage_update", "processing", "translation", "completed", 100, nil)
 	}
 
@@ -453,6 +646,9 @@ func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, i
 			DurationSeconds: time.Since(startTime).Seconds(),
 		}
 		u.saveStatus(taskID, *status)
+		u.archive(taskID, "summary", func(m MeetingArchiver) error {
+			return m.ArchiveSummary(taskID, summResult)
+		})
 		u.publishEvent(taskID, req.MacAddress, "stage_update", "processing", "summary", "completed", 100, nil)
 	}
 
@@ -472,12 +668,31 @@ func (u *pipelineUseCase) runPipelineAsync(ctx context.Context, taskID string, i
 	duration := time.Since(start).Seconds()
 	status.DurationSeconds = duration
 	u.saveStatus(taskID, *status)
+	u.archive(taskID, "completed", func(m MeetingArchiver) error {
+		return m.ArchiveOutcome(taskID, "completed", duration)
+	})
 
 	u.publishEvent(taskID, req.MacAddress, "completed", "completed", "", "", 100, nil)
 
 	utils.LogInfo("Pipeline Task %s: completed (Duration: %.2fs)", taskID, duration)
 }
 
+// loadStatus returns the status of a task from the store, or from the cache after a restart
+func (u *pipelineUseCase) loadStatus(taskID string) *pipelineDtos.PipelineStatusDTO {
+	if status, _ := u.store.Get(taskID); status != nil {
+		return status
+	}
+	var cached pipelineDtos.PipelineStatusDTO
+	if _, exists, _ := u.cache.GetWithTTL(taskID, &cached); exists {
+		return &cached
+	}
+	return nil
+}
+
+func isTerminalStatus(status string) bool {
+	return status == "completed" || status == "cancelled" || status == "failed"
+}
+
 func (u *pipelineUseCase) saveStatus(taskID string, status pipelineDtos.PipelineStatusDTO) {
 	ttl, err := time.ParseDuration(utils.AppConfig.TaskStatusTTL)
 	if err != nil {
@@ -487,8 +702,19 @@ func (u *pipelineUseCase) saveStatus(taskID string, status pipelineDtos.Pipeline
 	_ = u.cache.SetWithTTL(taskID, status, ttl)
 }
 
+// archive writes pipeline artifacts to persistent meeting storage.
+// Failures are logged but never fail the pipeline itself; the status store remains authoritative for polling.
+func (u *pipelineUseCase) archive(taskID string, stage string, fn func(MeetingArchiver) error) {
+	if u.meetings == nil {
+		return
+	}
+	if err := fn(u.meetings); err != nil {
+		utils.LogWarn("Pipeline Task %s: Failed to persist meeting artifacts after '%s': %v", taskID, stage, err)
+	}
+}
+
 func (u *pipelineUseCase) failStage(taskID string, macAddress string, stageName string, err error) {
-	status, _ := u.store.Get(taskID)
+	status := u.loadStatus(taskID)
 	if status == nil {
 		return
 	}
@@ -498,6 +724,9 @@ func (u *pipelineUseCase) failStage(taskID string, macAddress string, stageName
 	status.Stages[stageName] = stage
 	status.OverallStatus = "failed"
 	u.saveStatus(taskID, *status)
+	u.archive(taskID, "failed", func(m MeetingArchiver) error {
+		return m.ArchiveOutcome(taskID, "failed", 0)
+	})
 
 	utils.LogError("Pipeline Task %s: Stage '%s' failed: %v", taskID, stageName, err)
 	u.publishEvent(taskID, macAddress, "failed", "failed", stageName, "failed", 0, err)
@@ -523,60 +752,33 @@ func (u *pipelineUseCase) publishEvent(taskID string, macAddress string, event s
 
 	topic := fmt.Sprintf("users/%s/%s/task", macAddress, utils.GetConfig().ApplicationEnvironment)
 	payloadBytes, _ := json.Marshal(taskEvent)
-	_ = u.mqttSvc.Publish(topic, 0, false, payloadBytes)
-}
-
-// registerCancel stores the cancel function for a task
-func (u *pipelineUseCase) registerCancel(taskID string, cancel context.CancelFunc) {
-	u.registryMu.Lock()
-	defer u.registryMu.Unlock()
-	u.cancelRegistry[taskID] = cancel
-}
-
-// unregisterCancel removes the cancel function for a task
-func (u *pipelineUseCase) unregisterCancel(taskID string) {
-	u.registryMu.Lock()
-	defer u.registryMu.Unlock()
-	delete(u.cancelRegistry, taskID)
-}
-
-// getAndRemoveCancel retrieves and removes the cancel function for a task
-func (u *pipelineUseCase) getAndRemoveCancel(taskID string) context.CancelFunc {
-	u.registryMu.Lock()
-	defer u.registryMu.Unlock()
-	cancel := u.cancelRegistry[taskID]
-	delete(u.cancelRegistry, taskID)
-	return cancel
+	// While the broker is down only the latest event of a task is kept for replay
+	_ = infrastructure.PublishWithOptions(u.mqttSvc, topic, 0, false, payloadBytes, infrastructure.MqttPublishOptions{DedupKey: "task:" + taskID})
 }
 
 // CancelTask cancels an active pipeline task
 func (u *pipelineUseCase) CancelTask(taskID string) error {
-	status, found := u.store.Get(taskID)
-	if !found || status == nil {
-		// Also check cache in case store doesn't have it
-		var cachedStatus pipelineDtos.PipelineStatusDTO
-		_, cachedExists, _ := u.cache.GetWithTTL(taskID, &cachedStatus)
-		if !cachedExists {
-			return errors.New("task not found")
-		}
-		status = &cachedStatus
+	status := u.loadStatus(taskID)
+	if status == nil {
+		return errors.New("task not found")
 	}
 
 	// Check if task is already in a terminal state
-	if status.OverallStatus == "completed" || status.OverallStatus == "cancelled" || status.OverallStatus == "failed" {
+	if isTerminalStatus(status.OverallStatus) {
 		// Already terminal - treat as no-op success
 		utils.LogInfo("Pipeline: CancelTask called for task %s already in terminal state: %s", taskID, status.OverallStatus)
 		return nil
 	}
 
-	// Trigger cancellation
-	cancel := u.getAndRemoveCancel(taskID)
-	if cancel != nil {
-		cancel()
+	// Trigger cancellation; a queued job is dropped, a running one stops at its next checkpoint
+	if _, err := u.jobs.Cancel(taskID); err != nil && !errors.Is(err, tasks.ErrJobNotFound) {
+		return err
 	}
 
-	// Mark task as cancelled
-	u.markCancelled(taskID, status)
+	// Mark task as cancelled, unless the queue already did so for a job that had not started
+	if status = u.loadStatus(taskID); status != nil && !isTerminalStatus(status.OverallStatus) {
+		u.markCancelled(taskID, status)
+	}
 
 	utils.LogInfo("Pipeline: Task %s cancelled successfully", taskID)
 	return nil
@@ -597,6 +799,9 @@ func (u *pipelineUseCase) handleCancellation(taskID string, currentStage string,
 	// Set overall status to cancelled
 	status.OverallStatus = "cancelled"
 	u.saveStatus(taskID, *status)
+	u.archive(taskID, "cancelled", func(m MeetingArchiver) error {
+		return m.ArchiveOutcome(taskID, "cancelled", 0)
+	})
 
 	// Publish MQTT cancellation event
 	u.publishCancelledEvent(taskID, currentStage, macAddress)
@@ -624,6 +829,9 @@ func (u *pipelineUseCase) markCancelled(taskID string, status *pipelineDtos.Pipe
 	// Set overall status to cancelled
 	status.OverallStatus = "cancelled"
 	u.saveStatus(taskID, *status)
+	u.archive(taskID, "cancelled", func(m MeetingArchiver) error {
+		return m.ArchiveOutcome(taskID, "cancelled", 0)
+	})
 
 	// Publish MQTT cancellation event
 	u.publishCancelledEvent(taskID, activeStage, status.MacAddress)
@@ -644,7 +852,8 @@ func (u *pipelineUseCase) publishCancelledEvent(taskID string, stage string, mac
 
 	topic := fmt.Sprintf("users/%s/%s/task", macAddress, utils.GetConfig().ApplicationEnvironment)
 	payloadBytes, _ := json.Marshal(taskEvent)
-	_ = u.mqttSvc.Publish(topic, 0, false, payloadBytes)
+	// While the broker is down only the latest event of a task is kept for replay
+	_ = infrastructure.PublishWithOptions(u.mqttSvc, topic, 0, false, payloadBytes, infrastructure.MqttPublishOptions{DedupKey: "task:" + taskID})
 
 	utils.LogInfo("Pipeline: Published cancellation event for task %s on topic %s", taskID, topic)
 }
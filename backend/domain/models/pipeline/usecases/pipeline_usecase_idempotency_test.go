@@ -312,6 +312,8 @@ func TestExecutePipelineWithSession_IdempotencyBehavior(t *testing.T) {
 			cache,
 			store,
 			mockMQTT,
+			nil,
+			newTestJobQueue(t),
 		)
 
 		// Create temporary test audio file
@@ -365,6 +367,8 @@ func TestExecutePipelineWithSession_IdempotencyBehavior(t *testing.T) {
 			cache,
 			store,
 			mockMQTT,
+			nil,
+			newTestJobQueue(t),
 		)
 
 		// Create temporary test audio file
@@ -429,6 +433,8 @@ func TestExecutePipelineWithSession_ActualIdempotency(t *testing.T) {
 		cache,
 		store,
 		mockMQTT,
+		nil,
+		newTestJobQueue(t),
 	)
 
 	// Create a temporary test audio file
@@ -616,6 +622,8 @@ func TestPipelineAudioPreservation(t *testing.T) {
 			cache,
 			store,
 			mockMQTT,
+			nil,
+			newTestJobQueue(t),
 		)
 
 		// Create test audio file
@@ -670,6 +678,8 @@ func TestPipelineAudioPreservation(t *testing.T) {
 			cache,
 			store,
 			mockMQTT,
+			nil,
+			newTestJobQueue(t),
 		)
 
 		// Create test audio file
@@ -710,6 +720,14 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
+// newTestJobQueue returns a started in-memory job queue, stopped when the test ends
+func newTestJobQueue(t *testing.T) *tasks.JobQueue {
+	jobs := tasks.NewJobQueue(nil, tasks.JobQueueConfig{})
+	jobs.Start()
+	t.Cleanup(jobs.Stop)
+	return jobs
+}
+
 // MockMQTTPublisher implements mqttPublisher interface for testing
 type MockMQTTPublisher struct {
 	publishedMessages []MockMQTTMessage
@@ -99,187 +99,121 @@ func NewRAGChatController(chatUC usecases.ChatUseCase, mqttSvc *infrastructure.M
 	}
 }
 
-func (c *RAGChatController) StartMqttSubscription() error {
-	if c.mqttSvc == nil {
+// RegisterMqttRoutes routes users/{mac}/{env}/chat through the MQTT RPC router; answers go to .../chat/answer
+func (c *RAGChatController) RegisterMqttRoutes(router *infrastructure.MqttRpcRouter) error {
+	if router == nil {
 		return nil
 	}
 
-	config := utils.GetConfig()
-	topic := fmt.Sprintf("users/+/%s/chat", config.ApplicationEnvironment)
-	err := c.mqttSvc.Subscribe(topic, 0, func(client mqtt.Client, msg mqtt.Message) {
-		payload := msg.Payload()
-		correlationID := uuid.New().String()
-		startedAt := time.Now()
-		utils.LogInfo("[%s] RAGChat MQTT: Received message on %s, payload size: %d", correlationID, msg.Topic(), len(payload))
-		if len(payload) == 0 {
-			return
-		}
+	if err := router.Handle("users/{mac}/{env}/chat", c.handleMqttChat); err != nil {
+		utils.LogError("RAGChat MQTT: %v", err)
+		return err
+	}
 
-		var req dtos.RAGChatRequestDTO
-		// Strictly JSON unmarshalling as per user request
-		parseStart := time.Now()
-		err := json.Unmarshal(payload, &req)
-		parseDuration := time.Since(parseStart)
-		if err != nil {
-			utils.LogError("[%s] RAGChat MQTT: Failed to unmarshal message: %v | parse_duration_ms=%d", correlationID, err, parseDuration.Milliseconds())
-			return
+	// Subscribe to general task signaling
+	if c.mqttSvc != nil {
+		taskTopic := fmt.Sprintf("users/+/%s/task", utils.GetConfig().ApplicationEnvironment)
+		_ = c.mqttSvc.Subscribe(taskTopic, 0, func(client mqtt.Client, msg mqtt.Message) {
+			payload := msg.Payload()
+			utils.LogInfo("RAG Task Signaling MQTT: Received message on %s: %s", msg.Topic(), string(payload))
+		})
+	}
+	return nil
+}
+
+func (c *RAGChatController) handleMqttChat(rpcReq *infrastructure.MqttRpcRequest) (*infrastructure.MqttRpcResult, error) {
+	handlerStart := time.Now()
+	mac := rpcReq.MAC
+	requestID := rpcReq.RequestID
+
+	var req dtos.RAGChatRequestDTO
+	if err := rpcReq.Bind(&req); err != nil {
+		utils.LogError("[%s] RAGChat MQTT: Failed to unmarshal message: %v", requestID, err)
+		return nil, err
+	}
+
+	if req.Prompt == "" || req.TerminalID == "" {
+		utils.LogError("[%s] RAGChat MQTT: Missing prompt or terminal_id", requestID)
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "prompt", Message: "prompt is required"},
+			{Field: "terminal_id", Message: "terminal_id is required"},
+		})
+	}
+
+	// Debounce: if the Tuya terminal sent BOTH an audio file (whisper) AND text (chat) simultaneously,
+	// give the whisper handler enough time to download audio and set the active flag.
+	whisperCheckStart := time.Now()
+	isWhisperActive := false
+	for i := 0; i < 5; i++ {
+		if _, active := utils.ActiveTranscriptions.Load(mac); active {
+			isWhisperActive = true
+			break
 		}
-		utils.LogDebug("[%s] RAGChat MQTT: Payload parsed | parse_duration_ms=%d", correlationID, parseDuration.Milliseconds())
-
-		// Extract MAC from topic: (optionally $share/group/)users/MAC/env/chat
-		topicParts := strings.Split(msg.Topic(), "/")
-		mac := ""
-		for i, part := range topicParts {
-			if part == "users" && i+1 < len(topicParts) {
-				mac = topicParts[i+1]
-				break
-			}
+		if _, active := utils.ActiveTranscriptions.Load(req.TerminalID); active {
+			isWhisperActive = true
+			break
 		}
-
-		if req.Prompt == "" || req.TerminalID == "" {
-			utils.LogError("[%s] RAGChat MQTT: Missing prompt or terminal_id | total_duration_ms=%d", correlationID, time.Since(startedAt).Milliseconds())
-			if mac != "" {
-				respTopic := fmt.Sprintf("users/%s/%s/chat/answer", mac, utils.GetConfig().ApplicationEnvironment)
-				respData, _ := json.Marshal(commonDtos.StandardResponse{
-					Status:  false,
-					Message: "Validation Error",
-					Details: []utils.ValidationErrorDetail{
-						{Field: "prompt", Message: "prompt is required"},
-						{Field: "terminal_id", Message: "terminal_id is required"},
-					},
-				})
-				if err := c.mqttSvc.Publish(respTopic, 0, false, respData); err != nil {
-					utils.LogError("RAGChat MQTT: Failed to publish validation error response: %v", err)
-				}
-			}
-			return
+		if i < 4 {
+			time.Sleep(30 * time.Millisecond)
 		}
+	}
+	whisperCheckDuration := time.Since(whisperCheckStart)
+
+	if isWhisperActive {
+		utils.LogInfo("[%s] RAGChat MQTT: Dropping text query because a Whisper task is active for Terminal %s/%s | whisper_check_duration_ms=%d", requestID, mac, req.TerminalID, whisperCheckDuration.Milliseconds())
+		return &infrastructure.MqttRpcResult{
+			Message: "Chat request received (sync with active whisper)",
+			Data: dtos.RAGChatResponseDTO{
+				RequestID: requestID,
+				Source:    "MQTT_SYNC_DROP",
+			},
+		}, nil
+	}
 
-		// Debounce: if the Tuya terminal sent BOTH an audio file (whisper) AND text (chat) simultaneously,
-		// we run the chat processing in a goroutine with a start delay. This prevents blocking the MQTT
-		// client thread and gives the whisper handler enough time to download audio and set the active flag.
-		go func(mac string, req dtos.RAGChatRequestDTO) {
-			// Use startedAt from outer scope for true end-to-end timing (includes pre-goroutine parsing)
-			handlerStart := startedAt
-
-			// 1. Resolve requestID early for all response paths
-			requestID := req.RequestID
-			if requestID == "" {
-				requestID = correlationID
-			}
-
-			// 2. Check if Whisper is active (with a small non-blocking retry loop to catch near-simultaneous starts)
-			whisperCheckStart := time.Now()
-			isWhisperActive := false
-			for i := 0; i < 5; i++ {
-				if mac != "" {
-					if _, active := utils.ActiveTranscriptions.Load(mac); active {
-						isWhisperActive = true
-						break
-					}
-				}
-				if req.TerminalID != "" {
-					if _, active := utils.ActiveTranscriptions.Load(req.TerminalID); active {
-						isWhisperActive = true
-						break
-					}
-				}
-				if i < 4 {
-					time.Sleep(30 * time.Millisecond)
-				}
-			}
-			whisperCheckDuration := time.Since(whisperCheckStart)
-
-			if isWhisperActive {
-				utils.LogInfo("[%s] RAGChat MQTT: Dropping text query because a Whisper task is active for Terminal %s/%s | whisper_check_duration_ms=%d", correlationID, mac, req.TerminalID, whisperCheckDuration.Milliseconds())
-				if mac != "" {
-					respTopic := fmt.Sprintf("users/%s/%s/chat/answer", mac, utils.GetConfig().ApplicationEnvironment)
-					respData, _ := json.Marshal(commonDtos.StandardResponse{
-						Status:  true,
-						Message: "Chat request received (sync with active whisper)",
-						Data: dtos.RAGChatResponseDTO{
-							RequestID: requestID,
-							Source:    "MQTT_SYNC_DROP",
-						},
-					})
-					_ = c.mqttSvc.Publish(respTopic, 0, false, respData)
-				}
-				return
-			}
-
-			// Process chat
-			uidResolveStart := time.Now()
-			uid := c.resolveMQTTUID(mac, req.UID)
-			uidResolveDuration := time.Since(uidResolveStart)
-			utils.LogDebug("[%s] RAGChat MQTT: UID resolved | uid_resolve_duration_ms=%d", requestID, uidResolveDuration.Milliseconds())
-
-			utils.LogInfo("[%s] RAGChat MQTT [Handler: StartMqttSubscription]: Starting chat process for UID: %s, Prompt: '%s'", requestID, uid, req.Prompt)
-			chatStart := time.Now()
-			res, err := c.chatUC.Chat(context.Background(), uid, req.TerminalID, req.Prompt, req.Language, requestID)
-			chatDuration := time.Since(chatStart)
-			if err != nil {
-				utils.LogError("[%s] RAGChat MQTT: Chat processing failed: %v | chat_duration_ms=%d | total_duration_ms=%d", requestID, err, chatDuration.Milliseconds(), time.Since(handlerStart).Milliseconds())
-				if mac != "" {
-					respTopic := fmt.Sprintf("users/%s/%s/chat/answer", mac, utils.GetConfig().ApplicationEnvironment)
-					respData, _ := json.Marshal(commonDtos.StandardResponse{
-						Status:  false,
-						Message: "Internal Server Error",
-					})
-					if err := c.mqttSvc.Publish(respTopic, 0, false, respData); err != nil {
-						utils.LogError("[%s] RAGChat MQTT: Failed to publish internal error response: %v", requestID, err)
-					}
-				}
-				return
-			}
-
-			// Add tracking metadata to response
-			// Preserve Source if already set by usecase (e.g., IDEMPOTENCY_CACHED, IDEMPOTENCY_IN_PROGRESS)
-			// Only set channel source if not already set
-			if res.Source == "" {
-				res.Source = "MQTT_SUBSCRIBER"
-			}
-			res.RequestID = requestID
-			res.InstanceID = c.instanceID
-
-			// Publish result back
-			if mac != "" {
-				respTopic := fmt.Sprintf("users/%s/%s/chat/answer", mac, utils.GetConfig().ApplicationEnvironment)
-				// Use req.TerminalID if mac from topic is generic (security/consistency check)
-				if mac != req.TerminalID && req.TerminalID != "" {
-					utils.LogDebug("[%s] [Instance: %s] RAGChat MQTT: Response topic override check: TopicMAC=%s, PayloadID=%s", requestID, c.instanceID, mac, req.TerminalID)
-				}
-
-				resp := commonDtos.StandardResponse{
-					Status:  true,
-					Message: "Chat processed successfully",
-					Data:    res,
-				}
-				respData, _ := json.Marshal(resp)
-				publishStart := time.Now()
-				utils.LogInfo("[%s] [Instance: %s] RAGChat MQTT [Handler: StartMqttSubscription]: Publishing answer to %s. Response: %s", requestID, c.instanceID, respTopic, res.Response)
-				if err := c.mqttSvc.Publish(respTopic, 0, false, respData); err != nil {
-					utils.LogError("[%s] RAGChat MQTT: Failed to publish chat response: %v | total_duration_ms=%d", requestID, err, time.Since(handlerStart).Milliseconds())
-				} else {
-					publishDuration := time.Since(publishStart)
-					utils.LogInfo("[%s] RAGChat MQTT: Request completed successfully | chat_duration_ms=%d | publish_duration_ms=%d | total_duration_ms=%d", requestID, chatDuration.Milliseconds(), publishDuration.Milliseconds(), time.Since(handlerStart).Milliseconds())
-				}
-			}
-		}(mac, req)
-	})
-
-	// Subscribe to general task signaling
-	taskTopic := fmt.Sprintf("users/+/%s/task", config.ApplicationEnvironment)
-	_ = c.mqttSvc.Subscribe(taskTopic, 0, func(client mqtt.Client, msg mqtt.Message) {
-		payload := msg.Payload()
-		utils.LogInfo("RAG Task Signaling MQTT: Received message on %s: %s", msg.Topic(), string(payload))
-	})
+	// Process chat
+	uidResolveStart := time.Now()
+	uid := c.resolveMQTTUID(mac, req.UID)
+	utils.LogDebug("[%s] RAGChat MQTT: UID resolved | uid_resolve_duration_ms=%d", requestID, time.Since(uidResolveStart).Milliseconds())
 
+	utils.LogInfo("[%s] RAGChat MQTT [Handler: handleMqttChat]: Starting chat process for UID: %s, Prompt: '%s'", requestID, uid, req.Prompt)
+	chatStart := time.Now()
+	var res *dtos.RAGChatResponseDTO
+	var err error
+	var stream *mqttChatStream
+	if req.Stream && c.mqttSvc != nil {
+		// Partial tokens go to .../chat/stream; the final answer is still returned on .../chat/answer
+		stream = newMqttChatStream(c.mqttSvc, rpcReq.Topic, requestID)
+		res, err = c.chatUC.ChatStream(context.Background(), uid, req.TerminalID, req.Prompt, req.Language, requestID, stream.Write)
+	} else {
+		res, err = c.chatUC.Chat(context.Background(), uid, req.TerminalID, req.Prompt, req.Language, requestID)
+	}
+	chatDuration := time.Since(chatStart)
 	if err != nil {
-		utils.LogError("RAGChat MQTT: Failed to subscribe to %s: %v", topic, err)
-		return err
+		utils.LogError("[%s] RAGChat MQTT: Chat processing failed: %v | chat_duration_ms=%d | total_duration_ms=%d", requestID, err, chatDuration.Milliseconds(), time.Since(handlerStart).Milliseconds())
+		return nil, infrastructure.NewMqttRpcError(infrastructure.MqttRpcCodeInternal, "Internal Server Error")
 	}
-	utils.LogInfo("RAGChat MQTT: Successfully subscribed to %s", topic)
-	return nil
+
+	// Add tracking metadata to response
+	// Preserve Source if already set by usecase (e.g., IDEMPOTENCY_CACHED, IDEMPOTENCY_IN_PROGRESS)
+	// Only set channel source if not already set
+	if res.Source == "" {
+		res.Source = "MQTT_SUBSCRIBER"
+	}
+	res.RequestID = requestID
+	res.InstanceID = c.instanceID
+	if stream != nil {
+		stream.Done(res)
+	}
+
+	if mac != req.TerminalID {
+		utils.LogDebug("[%s] [Instance: %s] RAGChat MQTT: Response topic override check: TopicMAC=%s, PayloadID=%s", requestID, c.instanceID, mac, req.TerminalID)
+	}
+	utils.LogInfo("[%s] [Instance: %s] RAGChat MQTT: Request completed | chat_duration_ms=%d | total_duration_ms=%d | response: %s", requestID, c.instanceID, chatDuration.Milliseconds(), time.Since(handlerStart).Milliseconds(), res.Response)
+
+	return &infrastructure.MqttRpcResult{
+		Message: "Chat processed successfully",
+		Data:    res,
+	}, nil
 }
 
 // Chat handles the AI Assistant chat/command classification.
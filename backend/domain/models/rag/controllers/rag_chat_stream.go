@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	commonDtos "sensio/domain/common/dtos"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"sensio/domain/models/rag/dtos"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// chatStreamFlushInterval batches MQTT deltas so a fast model does not publish one message per token
+	chatStreamFlushInterval = 100 * time.Millisecond
+	// chatStreamFrameTTL drops queued frames that could no longer be shown while the answer is still on screen
+	chatStreamFrameTTL = 15 * time.Second
+)
+
+// ChatStream streams the AI Assistant answer as Server-Sent Events.
+// @Summary AI Assistant Chat (streaming)
+// @Description Same pipeline as /api/models/rag/chat, answered as text/event-stream. "delta" events carry partial reply text with increasing seq; the final "done" event carries the authoritative response, which replaces the streamed text. Control commands and fast-path answers arrive only in the "done" event. An "error" event ends the stream on failure.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Accept json
+// @Produce text/event-stream
+// @Param request body dtos.RAGChatRequestDTO true "Chat Request"
+// @Success 200 {object} dtos.RAGChatStreamFrameDTO
+// @Failure      400  {object}  commonDtos.ValidationErrorResponse
+// @Router /api/models/rag/chat/stream [post]
+func (c *RAGChatController) ChatStream(ctx *gin.Context) {
+	handlerStart := time.Now()
+
+	var req dtos.RAGChatRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error",
+			Details: []utils.ValidationErrorDetail{
+				{Field: "payload", Message: "Invalid request body: " + err.Error()},
+			},
+		})
+		return
+	}
+
+	uidStr := ctx.GetString("uid")
+	requestID := req.RequestID
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	utils.LogInfo("[%s] RAGChat SSE [Handler: ChatStream]: Starting chat stream for UID: %s, Terminal: %s, Prompt: '%s'", requestID, uidStr, req.TerminalID, req.Prompt)
+
+	seq := 0
+	res, err := c.chatUC.ChatStream(ctx.Request.Context(), uidStr, req.TerminalID, req.Prompt, req.Language, requestID, func(delta string) {
+		seq++
+		ctx.SSEvent("delta", dtos.RAGChatStreamFrameDTO{RequestID: requestID, Seq: seq, Delta: delta})
+		ctx.Writer.Flush()
+	})
+	if err != nil {
+		utils.LogError("[%s] RAGChatController.ChatStream: %v | total_duration_ms=%d", requestID, err, time.Since(handlerStart).Milliseconds())
+		ctx.SSEvent("error", commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Internal Server Error",
+		})
+		ctx.Writer.Flush()
+		return
+	}
+
+	if res.Source == "" {
+		res.Source = "HTTP_HANDLER"
+	}
+	res.RequestID = requestID
+	res.InstanceID = c.instanceID
+
+	seq++
+	ctx.SSEvent("done", dtos.RAGChatStreamFrameDTO{RequestID: requestID, Seq: seq, Done: true, Response: res})
+	ctx.Writer.Flush()
+
+	utils.LogInfo("[%s] RAGChat SSE: Stream completed | frames=%d | total_duration_ms=%d", requestID, seq, time.Since(handlerStart).Milliseconds())
+}
+
+// mqttChatStream publishes partial chat text to users/{mac}/{env}/chat/stream, batching deltas
+// that arrive within chatStreamFlushInterval into a single frame.
+type mqttChatStream struct {
+	mqttSvc   *infrastructure.MqttService
+	topic     string
+	requestID string
+
+	mu        sync.Mutex
+	seq       int
+	pending   strings.Builder
+	lastFlush time.Time
+}
+
+func newMqttChatStream(mqttSvc *infrastructure.MqttService, answerTopic, requestID string) *mqttChatStream {
+	return &mqttChatStream{
+		mqttSvc:   mqttSvc,
+		topic:     strings.TrimSuffix(answerTopic, "/") + "/stream",
+		requestID: requestID,
+		lastFlush: time.Now(),
+	}
+}
+
+// Write queues a delta and publishes the batch once the flush interval has passed
+func (s *mqttChatStream) Write(delta string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending.WriteString(delta)
+	if time.Since(s.lastFlush) >= chatStreamFlushInterval {
+		s.flushLocked()
+	}
+}
+
+// Done publishes what is left and the closing frame with the final response
+func (s *mqttChatStream) Done(res *dtos.RAGChatResponseDTO) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushLocked()
+	s.seq++
+	s.publishLocked(dtos.RAGChatStreamFrameDTO{RequestID: s.requestID, Seq: s.seq, Done: true, Response: res})
+}
+
+func (s *mqttChatStream) flushLocked() {
+	s.lastFlush = time.Now()
+	if s.pending.Len() == 0 {
+		return
+	}
+	s.seq++
+	s.publishLocked(dtos.RAGChatStreamFrameDTO{RequestID: s.requestID, Seq: s.seq, Delta: s.pending.String()})
+	s.pending.Reset()
+}
+
+func (s *mqttChatStream) publishLocked(frame dtos.RAGChatStreamFrameDTO) {
+	payload, err := json.Marshal(frame)
+	if err != nil {
+		utils.LogError("[%s] RAGChat MQTT: Failed to marshal stream frame: %v", s.requestID, err)
+		return
+	}
+	if err := s.mqttSvc.PublishWithOptions(s.topic, 0, false, payload, infrastructure.MqttPublishOptions{TTL: chatStreamFrameTTL}); err != nil {
+		utils.LogWarn("[%s] RAGChat MQTT: Failed to publish stream frame seq=%d: %v", s.requestID, frame.Seq, err)
+	}
+}
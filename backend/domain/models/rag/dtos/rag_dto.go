@@ -137,6 +137,14 @@ func (s *RAGStatusDTO) SetExpiry(expiresAt string, expiresInSeconds int64) {
 	s.ExpiresInSecond = expiresInSeconds
 }
 
+// ApplyJobStatus implements tasks.StatusWithJob interface
+func (s *RAGStatusDTO) ApplyJobStatus(status string, lastError string) {
+	s.Status = status
+	if s.Error == "" {
+		s.Error = lastError
+	}
+}
+
 // RAGProcessResponseDTO is the payload returned by POST /api/rag
 // It contains the generated task id and optionally a `status` DTO when available.
 type RAGProcessResponseDTO struct {
@@ -189,17 +197,31 @@ type RAGChatRequestDTO struct {
 	Language   string `json:"language,omitempty" example:"id"`
 	TerminalID string `json:"terminal_id" binding:"required" example:"tx-1"`
 	UID        string `json:"uid,omitempty" example:"sg1765..."` // Must be Tuya UID (never MAC/terminal identity)
+	// Stream (MQTT only) publishes partial tokens to users/{mac}/{env}/chat/stream before the final answer
+	Stream bool `json:"stream,omitempty" example:"false"`
+}
+
+// RAGChatStreamFrameDTO is one incremental chat frame (SSE "delta"/"done" event data, MQTT chat/stream payload).
+// Frames carry increasing Seq numbers; the Done frame holds the authoritative response, which replaces
+// any streamed text (e.g. when a control command or a fallback provider produced the final answer).
+type RAGChatStreamFrameDTO struct {
+	RequestID string              `json:"request_id"`
+	Seq       int                 `json:"seq" example:"1"`
+	Delta     string              `json:"delta,omitempty" example:"Halo, "`
+	Done      bool                `json:"done"`
+	Response  *RAGChatResponseDTO `json:"response,omitempty"`
 }
 
 type RAGChatResponseDTO struct {
-	Response       string       `json:"response,omitempty"`
-	IsControl      bool         `json:"is_control,omitempty"`
-	IsBlocked      bool         `json:"is_blocked"`
-	Redirect       *RedirectDTO `json:"redirect,omitempty"`
-	HTTPStatusCode int          `json:"-"`                     // HTTP status code to return (not exposed in JSON)
-	RequestID      string       `json:"request_id,omitempty"`  // Tracking ID (echoes request_id from request)
-	Source         string       `json:"source,omitempty"`      // Response source: "HTTP_HANDLER", "MQTT_SUBSCRIBER", "IDEMPOTENCY_CACHED", "IDEMPOTENCY_IN_PROGRESS", "MQTT_SYNC_DROP"
-	InstanceID     string       `json:"instance_id,omitempty"` // Server start time
+	Response       string                   `json:"response,omitempty"`
+	IsControl      bool                     `json:"is_control,omitempty"`
+	IsBlocked      bool                     `json:"is_blocked"`
+	Redirect       *RedirectDTO             `json:"redirect,omitempty"`
+	Actions        []ControlActionResultDTO `json:"actions,omitempty"`     // Per-step results of a multi-device command
+	HTTPStatusCode int                      `json:"-"`                     // HTTP status code to return (not exposed in JSON)
+	RequestID      string                   `json:"request_id,omitempty"`  // Tracking ID (echoes request_id from request)
+	Source         string                   `json:"source,omitempty"`      // Response source: "HTTP_HANDLER", "MQTT_SUBSCRIBER", "IDEMPOTENCY_CACHED", "IDEMPOTENCY_IN_PROGRESS", "MQTT_SYNC_DROP"
+	InstanceID     string                   `json:"instance_id,omitempty"` // Server start time
 
 	// Idempotency Source Contract:
 	// - "IDEMPOTENCY_CACHED": Duplicate request with same request_id, returning cached completed response
@@ -227,6 +249,27 @@ type ControlResultDTO struct {
 	HTTPStatusCode int    `json:"-"`                 // HTTP status code to return (not exposed in JSON)
 }
 
+// ControlActionResultDTO is the outcome of one step of a multi-device control plan.
+type ControlActionResultDTO struct {
+	Device     string `json:"device"`                // device as named in the plan
+	DeviceID   string `json:"device_id,omitempty"`   // resolved device, empty when not found
+	DeviceName string `json:"device_name,omitempty"` // resolved device name
+	Operation  string `json:"operation"`
+	Value      string `json:"value,omitempty"`
+	Switch     int    `json:"switch,omitempty"`
+	Status     string `json:"status"` // "success" | "failed" | "not_found" | "skipped"
+	Message    string `json:"message"`
+}
+
+// ControlPlanResultDTO is the combined outcome of a multi-device control plan.
+type ControlPlanResultDTO struct {
+	Message        string                   `json:"message"` // combined confirmation
+	Succeeded      int                      `json:"succeeded"`
+	Failed         int                      `json:"failed"`
+	Actions        []ControlActionResultDTO `json:"actions"`
+	HTTPStatusCode int                      `json:"-"`
+}
+
 // RAGRawPromptRequestDTO represents a raw prompt request to a specific model.
 type RAGRawPromptRequestDTO struct {
 	Prompt string `json:"prompt" binding:"required" example:"Hello, how are you?"`
@@ -25,6 +25,7 @@ func SetupRAGRoutes(
 		models.POST("/translate", translateController.Translate)
 		models.POST("/summary", summaryController.Summary)
 		models.POST("/chat", chatController.Chat)
+		models.POST("/chat/stream", chatController.ChatStream)
 		models.POST("/control", controlController.Control)
 		models.GET("/:task_id", statusController.GetStatus)
 
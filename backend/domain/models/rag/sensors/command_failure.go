@@ -0,0 +1,29 @@
+package sensors
+
+import (
+	"errors"
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/models/rag/dtos"
+	"strings"
+)
+
+// commandFailure turns a failed SendSwitchCommand into a control result. Commands rejected by
+// the device specification come from the request, not a server fault, so they are reported as 400.
+func commandFailure(err error) *dtos.ControlResultDTO {
+	var valErr *utils.ValidationError
+	if errors.As(err, &valErr) {
+		reasons := make([]string, len(valErr.Details))
+		for i, detail := range valErr.Details {
+			reasons[i] = detail.Message
+		}
+		return &dtos.ControlResultDTO{
+			Message:        "Perintah tidak didukung perangkat: " + strings.Join(reasons, "; "),
+			HTTPStatusCode: 400,
+		}
+	}
+	return &dtos.ControlResultDTO{
+		Message:        fmt.Sprintf("Gagal menjalankan perintah: %v", err),
+		HTTPStatusCode: 500,
+	}
+}
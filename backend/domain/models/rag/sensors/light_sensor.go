@@ -44,10 +44,7 @@ func (s *LightSensor) ExecuteControl(token string, device *tuyaDtos.TuyaDeviceDT
 
 		success, err := executor.SendSwitchCommand(token, device.ID, commands)
 		if err != nil {
-			return &dtos.ControlResultDTO{
-				Message:        fmt.Sprintf("Gagal menjalankan perintah: %v", err),
-				HTTPStatusCode: 500,
-			}, nil
+			return commandFailure(err), nil
 		}
 
 		if !success {
@@ -103,10 +100,7 @@ func (s *LightSensor) ExecuteControl(token string, device *tuyaDtos.TuyaDeviceDT
 
 	success, err := executor.SendSwitchCommand(token, device.ID, commands)
 	if err != nil {
-		return &dtos.ControlResultDTO{
-			Message:        fmt.Sprintf("Gagal menjalankan perintah: %v", err),
-			HTTPStatusCode: 500,
-		}, nil
+		return commandFailure(err), nil
 	}
 
 	if !success {
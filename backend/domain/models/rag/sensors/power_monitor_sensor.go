@@ -79,10 +79,7 @@ func (s *PowerMonitorSensor) ExecuteControl(token string, device *tuyaDtos.TuyaD
 
 	success, err := executor.SendSwitchCommand(token, device.ID, commands)
 	if err != nil {
-		return &dtos.ControlResultDTO{
-			Message:        fmt.Sprintf("Gagal menjalankan perintah: %v", err),
-			HTTPStatusCode: 500,
-		}, nil
+		return commandFailure(err), nil
 	}
 
 	if !success {
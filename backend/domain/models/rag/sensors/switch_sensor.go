@@ -123,11 +123,8 @@ func (s *SwitchSensor) ExecuteControl(token string, device *tuyaDtos.TuyaDeviceD
 
 	success, err := executor.SendSwitchCommand(token, device.ID, commands)
 	if err != nil {
-		// Transport error (network, API failure)
-		return &dtos.ControlResultDTO{
-			Message:        fmt.Sprintf("Gagal menjalankan perintah: %v", err),
-			HTTPStatusCode: 500,
-		}, nil
+		// Spec rejection (400) or transport error (network, API failure)
+		return commandFailure(err), nil
 	}
 
 	if !success {
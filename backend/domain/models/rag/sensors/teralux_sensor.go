@@ -78,10 +78,7 @@ func (s *TerminalSensor) ExecuteControl(token string, device *tuyaDtos.TuyaDevic
 	// Execute command
 	success, err := executor.SendSwitchCommand(token, device.ID, commands)
 	if err != nil {
-		return &dtos.ControlResultDTO{
-			Message:        fmt.Sprintf("Gagal menjalankan perintah: %v", err),
-			HTTPStatusCode: 500,
-		}, nil
+		return commandFailure(err), nil
 	}
 
 	if !success {
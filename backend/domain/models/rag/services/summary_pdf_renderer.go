@@ -15,6 +15,7 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
+	"github.com/google/uuid"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
@@ -34,6 +35,19 @@ type SummaryPDFMeta struct {
 	CompanyName  string
 }
 
+// NewSummaryPDFPath returns a new file path for a summary PDF under uploads/reports and the URL it is served at
+func NewSummaryPDFPath() (string, string) {
+	uuidStr, _ := uuid.NewV7()
+	pdfFilename := fmt.Sprintf("summary_%s.pdf", uuidStr.String())
+	basePath := "."
+	if envPath := utils.FindEnvFile(); envPath != "" {
+		basePath = filepath.Dir(envPath)
+	}
+	pdfPath := filepath.Join(basePath, "uploads", "reports", pdfFilename)
+	_ = os.MkdirAll(filepath.Dir(pdfPath), 0755)
+	return pdfPath, fmt.Sprintf("/uploads/reports/%s", pdfFilename)
+}
+
 type SummaryPDFRenderer interface {
 	Render(summary string, path string, meta SummaryPDFMeta) error
 }
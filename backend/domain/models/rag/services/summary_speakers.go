@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"sensio/domain/common/utils"
+	"sensio/domain/models/rag/dtos"
+)
+
+// RenameSummarySpeakers returns a copy of the summary in which diarized speaker labels, such as the
+// "Speaker 1" an LLM wrote as an action item owner, are replaced by the mapped participant names.
+// Mapped names missing from the participants metadata are added to it.
+func RenameSummarySpeakers(summary *dtos.CanonicalMeetingSummary, names map[string]string) *dtos.CanonicalMeetingSummary {
+	if summary == nil {
+		return nil
+	}
+	rename := func(s string) string { return utils.ReplaceSpeakerLabels(s, names) }
+	renamed := *summary
+
+	renamed.Metadata.Participants = renameAll(summary.Metadata.Participants, rename)
+	mapped := make([]string, 0, len(names))
+	for _, name := range names {
+		mapped = append(mapped, name)
+	}
+	sort.Strings(mapped)
+	for _, name := range mapped {
+		if name != "" && !containsFold(renamed.Metadata.Participants, name) {
+			renamed.Metadata.Participants = append(renamed.Metadata.Participants, name)
+		}
+	}
+	renamed.Agenda = rename(summary.Agenda)
+	renamed.BackgroundAndObjective = rename(summary.BackgroundAndObjective)
+	renamed.AdditionalNotes = rename(summary.AdditionalNotes)
+
+	renamed.MainDiscussionSections = make([]dtos.DiscussionSection, len(summary.MainDiscussionSections))
+	for i, section := range summary.MainDiscussionSections {
+		points := make([]dtos.DiscussionPoint, len(section.KeyPoints))
+		for j, p := range section.KeyPoints {
+			points[j] = dtos.DiscussionPoint{Content: rename(p.Content), Speaker: rename(p.Speaker), Timestamp: p.Timestamp}
+		}
+		renamed.MainDiscussionSections[i] = dtos.DiscussionSection{
+			Title:       rename(section.Title),
+			KeyPoints:   points,
+			Decisions:   renameAll(section.Decisions, rename),
+			ActionItems: renameAll(section.ActionItems, rename),
+		}
+	}
+	renamed.RolesAndResponsibilities = make([]dtos.RoleResponsibility, len(summary.RolesAndResponsibilities))
+	for i, r := range summary.RolesAndResponsibilities {
+		renamed.RolesAndResponsibilities[i] = dtos.RoleResponsibility{Role: rename(r.Role), AssignedTo: rename(r.AssignedTo), Description: rename(r.Description)}
+	}
+	renamed.ActionItems = RenameActionItemSpeakers(summary.ActionItems, names)
+	renamed.DecisionsMade = make([]dtos.Decision, len(summary.DecisionsMade))
+	for i, d := range summary.DecisionsMade {
+		renamed.DecisionsMade[i] = dtos.Decision{ID: d.ID, Description: rename(d.Description), Rationale: rename(d.Rationale)}
+	}
+	renamed.OpenIssues = make([]dtos.OpenIssue, len(summary.OpenIssues))
+	for i, o := range summary.OpenIssues {
+		renamed.OpenIssues[i] = dtos.OpenIssue{ID: o.ID, Description: rename(o.Description), Owner: rename(o.Owner)}
+	}
+	renamed.RisksAndMitigation = make([]dtos.Risk, len(summary.RisksAndMitigation))
+	for i, r := range summary.RisksAndMitigation {
+		renamed.RisksAndMitigation[i] = dtos.Risk{ID: r.ID, Description: rename(r.Description), Impact: r.Impact, Mitigation: rename(r.Mitigation)}
+	}
+	return &renamed
+}
+
+// RenameActionItemSpeakers returns a copy of the action items with speaker labels replaced by names
+func RenameActionItemSpeakers(items []dtos.ActionItem, names map[string]string) []dtos.ActionItem {
+	if items == nil {
+		return nil
+	}
+	renamed := make([]dtos.ActionItem, len(items))
+	for i, item := range items {
+		renamed[i] = item
+		renamed[i].Task = utils.ReplaceSpeakerLabels(item.Task, names)
+		renamed[i].PIC = utils.ReplaceSpeakerLabels(item.PIC, names)
+	}
+	return renamed
+}
+
+func renameAll(values []string, rename func(string) string) []string {
+	if values == nil {
+		return nil
+	}
+	renamed := make([]string, len(values))
+	for i, v := range values {
+		renamed[i] = rename(v)
+	}
+	return renamed
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
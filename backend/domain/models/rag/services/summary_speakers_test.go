@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"sensio/domain/models/rag/dtos"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameSummarySpeakers(t *testing.T) {
+	summary := &dtos.CanonicalMeetingSummary{
+		Metadata: dtos.SummaryMetadata{MeetingTitle: "Kickoff", Participants: []string{"Speaker 1", "Carol"}},
+		MainDiscussionSections: []dtos.DiscussionSection{{
+			Title:     "Budget",
+			KeyPoints: []dtos.DiscussionPoint{{Content: "Speaker 2 raised the vendor cost", Speaker: "Speaker 2"}},
+		}},
+		ActionItems: []dtos.ActionItem{{ID: 1, Task: "Review the quote with Speaker 1", PIC: "Speaker 2"}},
+		OpenIssues:  []dtos.OpenIssue{{ID: 1, Description: "Vendor choice", Owner: "Speaker 10"}},
+	}
+	names := map[string]string{"Speaker 1": "Budi", "Speaker 2": "Alice"}
+
+	renamed := RenameSummarySpeakers(summary, names)
+	assert.Equal(t, []string{"Budi", "Carol", "Alice"}, renamed.Metadata.Participants)
+	assert.Equal(t, "Alice raised the vendor cost", renamed.MainDiscussionSections[0].KeyPoints[0].Content)
+	assert.Equal(t, "Alice", renamed.MainDiscussionSections[0].KeyPoints[0].Speaker)
+	assert.Equal(t, "Review the quote with Budi", renamed.ActionItems[0].Task)
+	assert.Equal(t, "Alice", renamed.ActionItems[0].PIC)
+	assert.Equal(t, "Speaker 10", renamed.OpenIssues[0].Owner)
+
+	// The stored summary is left untouched
+	assert.Equal(t, "Speaker 2", summary.ActionItems[0].PIC)
+	assert.Equal(t, "Speaker 1", summary.Metadata.Participants[0])
+	assert.Nil(t, RenameSummarySpeakers(nil, names))
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"sensio/domain/common/utils"
+	"sensio/domain/models/rag/dtos"
+)
+
+// RenderDOCX renders the document as editable Word minutes: metadata, the summary, talk time per speaker,
+// then the transcript
+func RenderDOCX(doc *TranscriptDocument) ([]byte, error) {
+	d := &docxDocument{}
+
+	title := doc.Title
+	if title == "" && doc.Summary != nil {
+		title = doc.Summary.Metadata.MeetingTitle
+	}
+	if title == "" {
+		title = "Transcript"
+	}
+	d.heading(0, title)
+
+	metadata := []struct{ label, value string }{
+		{"Date", doc.Date},
+		{"Location", doc.Location},
+		{"Participants", strings.Join(doc.Participants, ", ")},
+		{"Language", doc.Language},
+	}
+	for _, m := range metadata {
+		if m.value != "" {
+			d.paragraph(docxRun{text: m.label + ": ", bold: true}, docxRun{text: m.value})
+		}
+	}
+
+	if doc.Summary != nil {
+		writeDocxSummary(d, doc.Summary)
+	} else if strings.TrimSpace(doc.SummaryMarkdown) != "" {
+		d.heading(1, "Summary")
+		writeDocxMarkdown(d, doc.SummaryMarkdown)
+	}
+
+	if stats := utils.BuildSpeakerStats(doc.Utterances); len(stats) > 0 {
+		d.heading(1, "Speakers")
+		var rows [][]string
+		for _, s := range stats {
+			rows = append(rows, []string{
+				s.SpeakerLabel,
+				formatCaptionTime(s.TalkTimeMs, ".")[:8],
+				fmt.Sprintf("%.1f%%", s.TalkTimePercent),
+				fmt.Sprintf("%d", s.Turns),
+			})
+		}
+		d.table([]string{"Speaker", "Talk Time", "Share", "Turns"}, rows)
+	}
+
+	d.heading(1, "Transcript")
+	if len(doc.Utterances) > 0 {
+		if !doc.AlignedTimestamps && doc.Utterances[len(doc.Utterances)-1].EndMs > 0 {
+			d.paragraph(docxRun{text: estimatedTimesNote})
+		}
+		for _, u := range doc.Utterances {
+			var runs []docxRun
+			if u.EndMs > 0 {
+				runs = append(runs, docxRun{text: fmt.Sprintf("[%s] ", formatCaptionTime(u.StartMs, ".")[:8])})
+			}
+			if u.SpeakerLabel != "" {
+				runs = append(runs, docxRun{text: u.SpeakerLabel + ": ", bold: true})
+			}
+			d.paragraph(append(runs, docxRun{text: u.Text})...)
+		}
+	} else {
+		writeDocxText(d, doc.Transcription)
+	}
+
+	if strings.TrimSpace(doc.TranslatedText) != "" {
+		d.heading(1, "Translation")
+		writeDocxText(d, doc.TranslatedText)
+	}
+	return d.bytes()
+}
+
+// writeDocxSummary follows the section order of GenerateMarkdown
+func writeDocxSummary(d *docxDocument, summary *dtos.CanonicalMeetingSummary) {
+	if summary.Agenda != "" {
+		d.heading(1, "Agenda")
+		writeDocxText(d, summary.Agenda)
+	}
+	if summary.BackgroundAndObjective != "" {
+		d.heading(1, "Background & Objective")
+		writeDocxText(d, summary.BackgroundAndObjective)
+	}
+
+	if len(summary.MainDiscussionSections) > 0 {
+		d.heading(1, "Main Discussion")
+		for _, section := range summary.MainDiscussionSections {
+			if section.Title == "" {
+				continue
+			}
+			d.heading(2, section.Title)
+			for _, point := range section.KeyPoints {
+				if point.Content == "" {
+					continue
+				}
+				runs := []docxRun{{text: point.Content}}
+				if point.Speaker != "" {
+					runs = append(runs, docxRun{text: " (" + point.Speaker + ")", italic: true})
+				}
+				d.bullet(runs...)
+			}
+			for i, decision := range section.Decisions {
+				d.paragraph(docxRun{text: fmt.Sprintf("Decision %d: ", i+1), bold: true}, docxRun{text: decision})
+			}
+			for _, item := range section.ActionItems {
+				d.bullet(docxRun{text: "Action: ", bold: true}, docxRun{text: item})
+			}
+		}
+	}
+
+	if len(summary.RolesAndResponsibilities) > 0 {
+		d.heading(1, "Roles & Responsibilities")
+		var rows [][]string
+		for _, role := range summary.RolesAndResponsibilities {
+			if role.Role != "" {
+				rows = append(rows, []string{role.Role, role.AssignedTo, role.Description})
+			}
+		}
+		d.table([]string{"Role", "Assigned To", "Description"}, rows)
+	}
+
+	if len(summary.ActionItems) > 0 {
+		d.heading(1, "Action Items")
+		var rows [][]string
+		for _, item := range summary.ActionItems {
+			if item.Task == "" {
+				continue
+			}
+			status := item.Status
+			if status == "" {
+				status = "Open"
+			}
+			rows = append(rows, []string{fmt.Sprint(len(rows) + 1), item.Task, item.PIC, item.Deadline, status})
+		}
+		d.table([]string{"#", "Task", "PIC", "Deadline", "Status"}, rows)
+	}
+
+	if len(summary.DecisionsMade) > 0 {
+		d.heading(1, "Decisions Made")
+		for i, decision := range summary.DecisionsMade {
+			if decision.Description == "" {
+				continue
+			}
+			d.paragraph(docxRun{text: fmt.Sprintf("%d. %s", i+1, decision.Description), bold: true})
+			if decision.Rationale != "" {
+				d.paragraph(docxRun{text: "Rationale: " + decision.Rationale, italic: true})
+			}
+		}
+	}
+
+	if len(summary.OpenIssues) > 0 {
+		d.heading(1, "Open Issues")
+		for _, issue := range summary.OpenIssues {
+			if issue.Description == "" {
+				continue
+			}
+			runs := []docxRun{{text: issue.Description}}
+			if issue.Owner != "" {
+				runs = append(runs, docxRun{text: " (Owner: " + issue.Owner + ")", italic: true})
+			}
+			d.bullet(runs...)
+		}
+	}
+
+	if len(summary.RisksAndMitigation) > 0 {
+		d.heading(1, "Risks & Mitigation")
+		var rows [][]string
+		for _, risk := range summary.RisksAndMitigation {
+			if risk.Description != "" {
+				rows = append(rows, []string{fmt.Sprint(len(rows) + 1), risk.Description, risk.Impact, risk.Mitigation})
+			}
+		}
+		d.table([]string{"#", "Description", "Impact", "Mitigation"}, rows)
+	}
+
+	if summary.AdditionalNotes != "" {
+		d.heading(1, "Additional Notes")
+		writeDocxText(d, summary.AdditionalNotes)
+	}
+}
+
+// writeDocxMarkdown renders the headings, bullets, tables and paragraphs of a Markdown summary,
+// for meetings summarized before the canonical summary existed
+func writeDocxMarkdown(d *docxDocument, markdown string) {
+	var header []string
+	var rows [][]string
+	flushTable := func() {
+		if header != nil {
+			d.table(header, rows)
+		}
+		header, rows = nil, nil
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "|") {
+			cells := strings.Split(strings.Trim(line, "|"), "|")
+			for i := range cells {
+				cells[i] = stripMarkdown(strings.TrimSpace(cells[i]))
+			}
+			if strings.Trim(line, "|-: ") == "" {
+				continue // header separator
+			}
+			if header == nil {
+				header = cells
+			} else {
+				rows = append(rows, cells)
+			}
+			continue
+		}
+		flushTable()
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			// The document title is already written; shift Markdown headings under "Summary"
+			d.heading(min(level+1, 3), stripMarkdown(strings.TrimSpace(line[level:])))
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			d.bullet(docxRun{text: stripMarkdown(line[2:])})
+		default:
+			d.paragraph(docxRun{text: stripMarkdown(line)})
+		}
+	}
+	flushTable()
+}
+
+func writeDocxText(d *docxDocument, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			d.paragraph(docxRun{text: line})
+		}
+	}
+}
+
+func stripMarkdown(s string) string {
+	return strings.NewReplacer("**", "", "__", "", "\\|", "|", "\\*", "*", "\\_", "_").Replace(s)
+}
+
+type docxRun struct {
+	text         string
+	bold, italic bool
+}
+
+// docxDocument builds the body of a WordprocessingML document
+type docxDocument struct {
+	body strings.Builder
+}
+
+// heading writes the document title (level 0) or a heading of level 1-3
+func (d *docxDocument) heading(level int, text string) {
+	style := "Title"
+	if level > 0 {
+		style = fmt.Sprintf("Heading%d", level)
+	}
+	d.styledParagraph(style, docxRun{text: text})
+}
+
+func (d *docxDocument) paragraph(runs ...docxRun) {
+	d.styledParagraph("", runs...)
+}
+
+func (d *docxDocument) bullet(runs ...docxRun) {
+	d.styledParagraph("ListBullet", append([]docxRun{{text: "•\t"}}, runs...)...)
+}
+
+func (d *docxDocument) styledParagraph(style string, runs ...docxRun) {
+	d.body.WriteString("<w:p>")
+	if style != "" {
+		d.body.WriteString(`<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`)
+	}
+	for _, run := range runs {
+		d.run(run)
+	}
+	d.body.WriteString("</w:p>")
+}
+
+func (d *docxDocument) run(run docxRun) {
+	d.body.WriteString("<w:r>")
+	if run.bold || run.italic {
+		d.body.WriteString("<w:rPr>")
+		if run.bold {
+			d.body.WriteString("<w:b/>")
+		}
+		if run.italic {
+			d.body.WriteString("<w:i/>")
+		}
+		d.body.WriteString("</w:rPr>")
+	}
+	for i, line := range strings.Split(run.text, "\n") {
+		if i > 0 {
+			d.body.WriteString("<w:br/>")
+		}
+		for j, part := range strings.Split(line, "\t") {
+			if j > 0 {
+				d.body.WriteString("<w:tab/>")
+			}
+			if part != "" {
+				d.body.WriteString(`<w:t xml:space="preserve">`)
+				_ = xml.EscapeText(&d.body, []byte(part))
+				d.body.WriteString("</w:t>")
+			}
+		}
+	}
+	d.body.WriteString("</w:r>")
+}
+
+func (d *docxDocument) table(header []string, rows [][]string) {
+	d.body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr>`)
+	writeRow := func(cells []string, bold bool) {
+		d.body.WriteString("<w:tr>")
+		for i := range header {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			d.body.WriteString("<w:tc><w:p>")
+			d.run(docxRun{text: cell, bold: bold})
+			d.body.WriteString("</w:p></w:tc>")
+		}
+		d.body.WriteString("</w:tr>")
+	}
+	writeRow(header, true)
+	for _, row := range rows {
+		writeRow(row, false)
+	}
+	// Word merges adjacent tables without a paragraph in between
+	d.body.WriteString("</w:tbl><w:p/>")
+}
+
+// bytes packages the body into a .docx (Office Open XML) archive
+func (d *docxDocument) bytes() ([]byte, error) {
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		d.body.String() +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="708" w:footer="708" w:gutter="0"/></w:sectPr>` +
+		`</w:body></w:document>`
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxPackageRels},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles},
+		{"word/document.xml", document},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, part := range parts {
+		w, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`</Types>`
+
+const docxPackageRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="22"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="264" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:spacing w:after="240"/></w:pPr><w:rPr><w:b/><w:sz w:val="40"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="360" w:after="120"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:sz w:val="26"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading3"><w:name w:val="heading 3"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="200" w:after="60"/><w:outlineLvl w:val="2"/></w:pPr><w:rPr><w:b/><w:sz w:val="24"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="60"/><w:ind w:left="360" w:hanging="360"/></w:pPr></w:style>` +
+	`<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders>` +
+	`<w:top w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:left w:val="single" w:sz="4" w:space="0" w:color="auto"/>` +
+	`<w:bottom w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:right w:val="single" w:sz="4" w:space="0" w:color="auto"/>` +
+	`<w:insideH w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="auto"/>` +
+	`</w:tblBorders><w:tblCellMar><w:left w:w="108" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>` +
+	`</w:styles>`
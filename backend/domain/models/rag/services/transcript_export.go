@@ -0,0 +1,334 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"sensio/domain/common/utils"
+	"sensio/domain/models/rag/dtos"
+	whisperDtos "sensio/domain/models/whisper/dtos"
+)
+
+const (
+	ExportFormatSRT  = "srt"
+	ExportFormatVTT  = "vtt"
+	ExportFormatDOCX = "docx"
+	ExportFormatJSON = "json"
+
+	docxContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+	// Captions longer than two lines of captionLineChars are split into several cues
+	captionLineChars = 42
+	captionMaxChars  = 2 * captionLineChars
+)
+
+// ErrNoTimedTranscript is returned for caption formats when the transcript has no timed utterances
+var ErrNoTimedTranscript = errors.New("transcript has no timed utterances")
+
+// TranscriptDocument is a transcript with its optional summary, the source of every export format
+type TranscriptDocument struct {
+	ID                string
+	Title             string
+	Language          string
+	Date              string
+	Location          string
+	Participants      []string
+	Transcription     string
+	TranslatedText    string
+	Utterances        []whisperDtos.Utterance
+	AlignedTimestamps bool                          // utterance times come from the audio rather than text length
+	Summary           *dtos.CanonicalMeetingSummary // preferred over SummaryMarkdown when set
+	SummaryMarkdown   string
+}
+
+// TranscriptExport is a rendered export ready to be downloaded
+type TranscriptExport struct {
+	Content           []byte
+	ContentType       string
+	FileName          string
+	AlignedTimestamps bool // the times in the export come from the audio
+}
+
+// estimatedTimesNote tells readers of captions and documents that their times are estimates
+const estimatedTimesNote = "Timestamps are estimated from the text length, not aligned to the audio."
+
+// TranscriptExportDTO is the machine-readable (json) export
+type TranscriptExportDTO struct {
+	ID                string                        `json:"id"`
+	Title             string                        `json:"title,omitempty"`
+	Language          string                        `json:"language,omitempty"`
+	Date              string                        `json:"date,omitempty"`
+	Location          string                        `json:"location,omitempty"`
+	Participants      []string                      `json:"participants,omitempty"`
+	ExportedAt        string                        `json:"exported_at"`
+	AlignedTimestamps bool                          `json:"aligned_timestamps"`
+	Transcription     string                        `json:"transcription"`
+	TranslatedText    string                        `json:"translated_text,omitempty"`
+	Utterances        []TranscriptExportUtterance   `json:"utterances,omitempty"`
+	Speakers          []whisperDtos.SpeakerStats    `json:"speakers,omitempty"` // Talk time per speaker of a diarized transcript
+	Summary           *dtos.CanonicalMeetingSummary `json:"summary,omitempty"`
+	SummaryMarkdown   string                        `json:"summary_markdown,omitempty"`
+}
+
+// TranscriptExportUtterance is a speaker turn of the json export
+type TranscriptExportUtterance struct {
+	Speaker    string  `json:"speaker,omitempty"`
+	StartMs    int64   `json:"start_ms"`
+	EndMs      int64   `json:"end_ms"`
+	Start      string  `json:"start"` // HH:MM:SS.mmm
+	End        string  `json:"end"`
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+var fileNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// TranscriptDocumentFromResult builds the document of a transcription task. Without speaker turns the
+// timed segments are used, so captions can still be produced.
+func TranscriptDocumentFromResult(id string, result *whisperDtos.AsyncTranscriptionResultDTO) *TranscriptDocument {
+	doc := &TranscriptDocument{
+		ID:                id,
+		Language:          result.DetectedLanguage,
+		Transcription:     result.Transcription,
+		Utterances:        result.Utterances,
+		AlignedTimestamps: result.AlignedTimestamps,
+	}
+	if len(doc.Utterances) == 0 {
+		for _, seg := range result.Segments {
+			doc.Utterances = append(doc.Utterances, whisperDtos.Utterance{StartMs: seg.StartMs, EndMs: seg.EndMs, Text: seg.Text})
+		}
+	}
+	return doc
+}
+
+// ExportTranscript renders the document as srt, vtt, docx or json (the default)
+func ExportTranscript(doc *TranscriptDocument, format string) (*TranscriptExport, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = ExportFormatJSON
+	}
+
+	var export TranscriptExport
+	var err error
+	switch format {
+	case ExportFormatSRT:
+		export.ContentType = "application/x-subrip; charset=utf-8"
+		export.Content, err = RenderSRT(doc)
+	case ExportFormatVTT:
+		export.ContentType = "text/vtt; charset=utf-8"
+		export.Content, err = RenderWebVTT(doc)
+	case ExportFormatDOCX:
+		export.ContentType = docxContentType
+		export.Content, err = RenderDOCX(doc)
+	case ExportFormatJSON:
+		export.ContentType = "application/json"
+		export.Content, err = RenderTranscriptJSON(doc)
+	default:
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "format", Message: "format must be srt, vtt, docx or json"},
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := fileNameUnsafe.ReplaceAllString(doc.ID, "_")
+	if name == "" {
+		name = "transcript"
+	}
+	export.FileName = fmt.Sprintf("transcript_%s.%s", name, format)
+	export.AlignedTimestamps = doc.AlignedTimestamps
+	return &export, nil
+}
+
+// RenderSRT renders the utterances as SubRip captions, prefixing each turn with its speaker
+func RenderSRT(doc *TranscriptDocument) ([]byte, error) {
+	cues := captionCues(doc.Utterances)
+	if len(cues) == 0 {
+		return nil, ErrNoTimedTranscript
+	}
+
+	var sb strings.Builder
+	for i, cue := range cues {
+		// "-->" would be read as a timing line
+		text := strings.ReplaceAll(cue.text, "-->", "->")
+		if cue.speaker != "" && cue.first {
+			text = cue.speaker + ": " + text
+		}
+		sb.WriteString(fmt.Sprintf("%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatCaptionTime(cue.startMs, ","),
+			formatCaptionTime(cue.endMs, ","),
+			wrapCaption(text)))
+	}
+	return []byte(sb.String()), nil
+}
+
+// RenderWebVTT renders the utterances as WebVTT captions with the speaker as cue voice.
+// Captions whose times are not aligned to the audio start with a NOTE saying so.
+func RenderWebVTT(doc *TranscriptDocument) ([]byte, error) {
+	cues := captionCues(doc.Utterances)
+	if len(cues) == 0 {
+		return nil, ErrNoTimedTranscript
+	}
+
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n")
+	if doc.Language != "" {
+		sb.WriteString(fmt.Sprintf("Language: %s\n", doc.Language))
+	}
+	sb.WriteString("\n")
+	if !doc.AlignedTimestamps {
+		sb.WriteString("NOTE " + estimatedTimesNote + "\n\n")
+	}
+	for i, cue := range cues {
+		text := escapeVTT(wrapCaption(cue.text))
+		if cue.speaker != "" {
+			text = fmt.Sprintf("<v %s>%s", escapeVTT(cue.speaker), text)
+		}
+		sb.WriteString(fmt.Sprintf("%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatCaptionTime(cue.startMs, "."),
+			formatCaptionTime(cue.endMs, "."),
+			text))
+	}
+	return []byte(sb.String()), nil
+}
+
+// RenderTranscriptJSON renders the document as an indented TranscriptExportDTO
+func RenderTranscriptJSON(doc *TranscriptDocument) ([]byte, error) {
+	export := TranscriptExportDTO{
+		ID:                doc.ID,
+		Title:             doc.Title,
+		Language:          doc.Language,
+		Date:              doc.Date,
+		Location:          doc.Location,
+		Participants:      doc.Participants,
+		ExportedAt:        time.Now().UTC().Format(time.RFC3339),
+		AlignedTimestamps: doc.AlignedTimestamps,
+		Transcription:     doc.Transcription,
+		TranslatedText:    doc.TranslatedText,
+		Speakers:          utils.BuildSpeakerStats(doc.Utterances),
+		Summary:           doc.Summary,
+	}
+	if doc.Summary == nil {
+		export.SummaryMarkdown = doc.SummaryMarkdown
+	}
+	for _, u := range doc.Utterances {
+		export.Utterances = append(export.Utterances, TranscriptExportUtterance{
+			Speaker:    u.SpeakerLabel,
+			StartMs:    u.StartMs,
+			EndMs:      u.EndMs,
+			Start:      formatCaptionTime(u.StartMs, "."),
+			End:        formatCaptionTime(u.EndMs, "."),
+			Text:       u.Text,
+			Confidence: u.Confidence,
+		})
+	}
+	return json.MarshalIndent(export, "", "  ")
+}
+
+type captionCue struct {
+	speaker        string
+	startMs, endMs int64
+	text           string
+	first          bool // first cue of the speaker turn
+}
+
+// captionCues turns utterances into caption cues. Utterances without a time range are skipped, and turns
+// too long for one caption are split at word boundaries with time shared out by text length.
+func captionCues(utterances []whisperDtos.Utterance) []captionCue {
+	var cues []captionCue
+	for _, u := range utterances {
+		text := strings.Join(strings.Fields(u.Text), " ")
+		if text == "" || u.EndMs <= u.StartMs {
+			continue
+		}
+
+		chunks := splitCaption(text)
+		total := 0
+		for _, chunk := range chunks {
+			total += len(chunk)
+		}
+		duration := u.EndMs - u.StartMs
+		start, done := u.StartMs, 0
+		for i, chunk := range chunks {
+			done += len(chunk)
+			end := u.StartMs + duration*int64(done)/int64(total)
+			if i == len(chunks)-1 {
+				end = u.EndMs
+			}
+			cues = append(cues, captionCue{speaker: u.SpeakerLabel, startMs: start, endMs: end, text: chunk, first: i == 0})
+			start = end
+		}
+	}
+	return cues
+}
+
+// splitCaption cuts text into pieces of at most captionMaxChars, preferring sentence ends
+func splitCaption(text string) []string {
+	words := strings.Fields(text)
+	var chunks []string
+	var current []string
+	length := 0
+	for _, word := range words {
+		if length > 0 && length+1+len(word) > captionMaxChars {
+			chunks = append(chunks, strings.Join(current, " "))
+			current, length = nil, 0
+		}
+		if length > 0 {
+			length++
+		}
+		current = append(current, word)
+		length += len(word)
+		if strings.HasSuffix(word, ".") || strings.HasSuffix(word, "?") || strings.HasSuffix(word, "!") {
+			if length >= captionLineChars {
+				chunks = append(chunks, strings.Join(current, " "))
+				current, length = nil, 0
+			}
+		}
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, " "))
+	}
+	return chunks
+}
+
+// wrapCaption breaks a caption into lines of about captionLineChars
+func wrapCaption(text string) string {
+	if len(text) <= captionLineChars {
+		return text
+	}
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > captionLineChars {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatCaptionTime formats milliseconds as HH:MM:SS followed by sep and milliseconds
+func formatCaptionTime(ms int64, sep string) string {
+	if ms < 0 {
+		ms = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+func escapeVTT(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"sensio/domain/common/utils"
+	"sensio/domain/models/rag/dtos"
+	whisperDtos "sensio/domain/models/whisper/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTranscriptDocument() *TranscriptDocument {
+	return &TranscriptDocument{
+		ID:           "task-42",
+		Title:        "Weekly Sync",
+		Language:     "en",
+		Participants: []string{"Alice", "Bob"},
+		Utterances: []whisperDtos.Utterance{
+			{SpeakerLabel: "Speaker 1", StartMs: 0, EndMs: 2500, Text: "Good morning everyone."},
+			{SpeakerLabel: "Speaker 2", StartMs: 2500, EndMs: 3723004, Text: "Morning <all> & welcome."},
+		},
+		AlignedTimestamps: true,
+		Summary: &dtos.CanonicalMeetingSummary{
+			Metadata:    dtos.SummaryMetadata{MeetingTitle: "Weekly Sync", Language: "en"},
+			Agenda:      "Status updates",
+			ActionItems: []dtos.ActionItem{{Task: "Ship the release", PIC: "Alice", Deadline: "Friday"}},
+		},
+	}
+}
+
+func TestRenderSRT(t *testing.T) {
+	content, err := RenderSRT(sampleTranscriptDocument())
+	require.NoError(t, err)
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:02,500\nSpeaker 1: Good morning everyone.\n\n"+
+		"2\n00:00:02,500 --> 01:02:03,004\nSpeaker 2: Morning <all> & welcome.\n\n", string(content))
+}
+
+func TestRenderWebVTT(t *testing.T) {
+	content, err := RenderWebVTT(sampleTranscriptDocument())
+	require.NoError(t, err)
+	assert.Equal(t, "WEBVTT\nLanguage: en\n\n"+
+		"1\n00:00:00.000 --> 00:00:02.500\n<v Speaker 1>Good morning everyone.\n\n"+
+		"2\n00:00:02.500 --> 01:02:03.004\n<v Speaker 2>Morning &lt;all&gt; &amp; welcome.\n\n", string(content))
+}
+
+func TestRenderWebVTT_NotesEstimatedTimes(t *testing.T) {
+	doc := sampleTranscriptDocument()
+	doc.AlignedTimestamps = false
+	content, err := RenderWebVTT(doc)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "WEBVTT\nLanguage: en\n\nNOTE Timestamps are estimated from the text length, not aligned to the audio.\n\n1\n"))
+}
+
+func TestCaptionCues_SplitsLongTurns(t *testing.T) {
+	long := strings.Repeat("word ", 60) // 299 characters
+	cues := captionCues([]whisperDtos.Utterance{
+		{SpeakerLabel: "Speaker 1", StartMs: 1000, EndMs: 31000, Text: long},
+		{StartMs: 0, EndMs: 0, Text: "untimed"},
+	})
+	require.Greater(t, len(cues), 3)
+	assert.True(t, cues[0].first)
+	assert.False(t, cues[1].first)
+	assert.Equal(t, int64(1000), cues[0].startMs)
+	assert.Equal(t, int64(31000), cues[len(cues)-1].endMs)
+	for i, cue := range cues {
+		assert.LessOrEqual(t, len(cue.text), captionMaxChars)
+		if i > 0 {
+			assert.Equal(t, cues[i-1].endMs, cue.startMs, "cues should be contiguous")
+		}
+	}
+	assert.Equal(t, "Good morning everyone, this caption is\nlong enough to wrap.", wrapCaption("Good morning everyone, this caption is long enough to wrap."))
+}
+
+func TestExportTranscript_Errors(t *testing.T) {
+	_, err := ExportTranscript(sampleTranscriptDocument(), "pdf")
+	var valErr *utils.ValidationError
+	assert.True(t, errors.As(err, &valErr))
+
+	untimed := &TranscriptDocument{ID: "task-1", Transcription: "No timing here"}
+	_, err = ExportTranscript(untimed, "srt")
+	assert.ErrorIs(t, err, ErrNoTimedTranscript)
+
+	export, err := ExportTranscript(untimed, "docx")
+	require.NoError(t, err)
+	assert.Equal(t, "transcript_task-1.docx", export.FileName)
+}
+
+func TestExportTranscript_JSON(t *testing.T) {
+	export, err := ExportTranscript(sampleTranscriptDocument(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", export.ContentType)
+	assert.Equal(t, "transcript_task-42.json", export.FileName)
+
+	var decoded TranscriptExportDTO
+	require.NoError(t, json.Unmarshal(export.Content, &decoded))
+	assert.True(t, decoded.AlignedTimestamps)
+	require.Len(t, decoded.Utterances, 2)
+	assert.Equal(t, "Speaker 2", decoded.Utterances[1].Speaker)
+	assert.Equal(t, "01:02:03.004", decoded.Utterances[1].End)
+	require.Len(t, decoded.Speakers, 2)
+	assert.Equal(t, int64(3720504), decoded.Speakers[1].TalkTimeMs)
+	assert.Equal(t, 99.9, decoded.Speakers[1].TalkTimePercent)
+	assert.Equal(t, "Status updates", decoded.Summary.Agenda)
+}
+
+func TestRenderDOCX(t *testing.T) {
+	content, err := RenderDOCX(sampleTranscriptDocument())
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	parts := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		parts[f.Name] = string(data)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/_rels/document.xml.rels", "word/styles.xml", "word/document.xml"} {
+		require.Contains(t, parts, name)
+		decoder := xml.NewDecoder(strings.NewReader(parts[name]))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else {
+				require.NoError(t, err, "%s is not well-formed", name)
+			}
+		}
+	}
+
+	document := parts["word/document.xml"]
+	assert.Contains(t, document, `<w:pStyle w:val="Title"/></w:pPr><w:r><w:t xml:space="preserve">Weekly Sync</w:t>`)
+	assert.Contains(t, document, ">Action Items<")
+	assert.Contains(t, document, ">Ship the release<")
+	assert.Contains(t, document, ">[00:00:02] <")
+	assert.Contains(t, document, ">Talk Time<")
+	assert.Contains(t, document, ">01:02:00<")
+	assert.Contains(t, document, "Morning &lt;all&gt; &amp; welcome.")
+}
+
+func TestRenderDOCX_MarkdownSummary(t *testing.T) {
+	content, err := RenderDOCX(&TranscriptDocument{
+		ID:              "task-7",
+		Transcription:   "Plain transcript",
+		SummaryMarkdown: "# Minutes\n\n- **First** point\n\n| Task | PIC |\n|------|-----|\n| Draft | Bob |",
+	})
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	var document string
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			r, _ := f.Open()
+			data, _ := io.ReadAll(r)
+			document = string(data)
+		}
+	}
+	assert.Contains(t, document, `<w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t xml:space="preserve">Minutes</w:t>`)
+	assert.Contains(t, document, ">First point<")
+	assert.Contains(t, document, "<w:tbl>")
+	assert.Contains(t, document, ">Draft<")
+	assert.Contains(t, document, ">Plain transcript<")
+}
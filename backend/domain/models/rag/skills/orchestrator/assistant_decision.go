@@ -18,6 +18,15 @@ type AssistantDecision struct {
 	ControlPrompt string            `json:"control_prompt,omitempty"` // normalized control command if model wants to specify
 	IsAmbiguous   bool              `json:"is_ambiguous,omitempty"`
 	BlockReason   string            `json:"block_reason,omitempty"`
+	Actions       []DeviceAction    `json:"actions,omitempty"` // one entry per device step when the request targets several devices
+}
+
+// DeviceAction is one step of a multi-device control plan ("turn off the AC and dim the lamp to 30%").
+type DeviceAction struct {
+	Device    string `json:"device"`           // device name or type as the user said it
+	Operation string `json:"operation"`        // same verbs as AssistantDecision.Operation
+	Value     string `json:"value,omitempty"`  // brightness percent, temperature or fan speed
+	Switch    int    `json:"switch,omitempty"` // gang number on a multi-switch device ("switch 3")
 }
 
 // AssistantDecisionEngine interface for the single-decision assistant flow.
@@ -42,6 +51,16 @@ func (e *AssistantDecisionEngineImpl) SetLLM(llm skills.LLMClient) {
 
 // Decide makes a single LLM call to determine intent and generate response.
 func (e *AssistantDecisionEngineImpl) Decide(ctx *skills.SkillContext) (*AssistantDecision, error) {
+	return e.decide(ctx, nil)
+}
+
+// DecideStream is Decide with the reply streamed: onResponse receives the "response" text as the
+// model generates it, but only for chat and identity intents. The returned decision is authoritative.
+func (e *AssistantDecisionEngineImpl) DecideStream(ctx *skills.SkillContext, onResponse func(delta string)) (*AssistantDecision, error) {
+	return e.decide(ctx, onResponse)
+}
+
+func (e *AssistantDecisionEngineImpl) decide(ctx *skills.SkillContext, onResponse func(delta string)) (*AssistantDecision, error) {
 	if ctx == nil || ctx.Prompt == "" {
 		return nil, fmt.Errorf("empty prompt")
 	}
@@ -53,11 +72,18 @@ func (e *AssistantDecisionEngineImpl) Decide(ctx *skills.SkillContext) (*Assista
 	}
 
 	// Build the single decision prompt
-	prompt := e.buildDecisionPrompt(ctx.Prompt, language, ctx.History)
+	prompt := e.buildDecisionPrompt(ctx.Prompt, language, ctx.History, ctx.Memory)
 
 	// Call LLM with strict JSON output requirement
 	model := "high"
-	response, err := e.llm.CallModel(ctx.Ctx, prompt, model)
+	var response string
+	var err error
+	if onResponse != nil {
+		streamer := newDecisionResponseStreamer(onResponse)
+		response, err = skills.StreamModel(ctx.Ctx, e.llm, prompt, model, streamer.Write)
+	} else {
+		response, err = e.llm.CallModel(ctx.Ctx, prompt, model)
+	}
 	if err != nil {
 		utils.LogError("AssistantDecisionEngine: LLM call failed: %v", err)
 		return nil, err
@@ -85,7 +111,7 @@ func (e *AssistantDecisionEngineImpl) Decide(ctx *skills.SkillContext) (*Assista
 }
 
 // buildDecisionPrompt constructs the prompt for the single LLM decision call.
-func (e *AssistantDecisionEngineImpl) buildDecisionPrompt(prompt, language string, history []string) string {
+func (e *AssistantDecisionEngineImpl) buildDecisionPrompt(prompt, language string, history []string, memory string) string {
 	// Language instruction
 	var languageInstruction string
 	if strings.EqualFold(language, "en") || strings.EqualFold(language, "english") {
@@ -110,6 +136,16 @@ Recent Conversation History:
 `, strings.Join(recentHistory, "\n"))
 	}
 
+	// Long-term memory (rolling summary and remembered user facts/preferences)
+	if memory != "" {
+		historyContext = fmt.Sprintf(`
+
+Long-term Memory (use it to personalise the answer, e.g. preferred temperature):
+%s
+
+`, memory) + strings.TrimPrefix(historyContext, "\n\n")
+	}
+
 	return fmt.Sprintf(`You are Sensio, a smart home assistant. Analyze the user's request and respond appropriately.
 
 %sUser Request: "%s"
@@ -124,7 +160,8 @@ Your task is to determine the intent and provide an appropriate response. You MU
   "value_hints": {"key": "value"} (optional, e.g., {"brightness": "50", "temperature": "24"}),
   "control_prompt": "normalized control command" (optional, e.g., "nyalakan lampu ruang tamu"),
   "is_ambiguous": true/false (true if multiple devices match),
-  "block_reason": "reason" (only for blocked intent)
+  "block_reason": "reason" (only for blocked intent),
+  "actions": [{"device": "device name or type", "operation": "...", "value": "...", "switch": 1}] (only for control of several devices or steps)
 }
 
 Intent Guidelines:
@@ -149,6 +186,10 @@ CRITICAL: Match the action verb EXACTLY as the user requested!
 - "device_hints": MUST list the target device(s) - required for control
 - "value_hints": Include numeric values if applicable (e.g., {"temperature": "24"}, {"brightness": "50"})
 - "control_prompt": Optionally provide the full normalized command in Indonesian
+- "actions": When ONE request controls SEVERAL devices or switches, or asks for several steps, list every step in the order the user said them
+  - Each step has its own "device", "operation" and, if needed, "value" (brightness percent, temperature, fan speed) and "switch" (gang number of a multi-switch device)
+  - Still fill "operation" and "device_hints" from the first step
+  - Do NOT use "actions" for a single device or for "all lights" commands
 
 Note: Discovery questions like "Apa aja device yang bisa saya kontrol?" should use intent="chat", not control.
 
@@ -180,6 +221,12 @@ Output: {"intent":"control","response":"Baik, menyalakan AC ruang tamu","operati
 User: "Lampu kamar 50 persen"
 Output: {"intent":"control","response":"Baik, mengatur lampu kamar ke 50 persen","operation":"brightness","device_hints":["lampu kamar"],"value_hints":{"brightness":"50"}}
 
+User: "Turn off the AC and dim the lamp to 30%%"
+Output: {"intent":"control","response":"Okay, turning off the AC and dimming the lamp to 30%%","operation":"matikan","device_hints":["ac","lamp"],"actions":[{"device":"ac","operation":"matikan"},{"device":"lamp","operation":"brightness","value":"30"}]}
+
+User: "Nyalakan switch 1 dan 3"
+Output: {"intent":"control","response":"Baik, menyalakan switch 1 dan 3","operation":"nyalakan","device_hints":["switch"],"actions":[{"device":"switch","operation":"nyalakan","switch":1},{"device":"switch","operation":"nyalakan","switch":3}]}
+
 User: "Kamu siapa?"
 Output: {"intent":"identity","response":"Hai! Saya Sensio, asisten rumah pintar Anda. Saya bisa membantu mengontrol perangkat smart home, merangkum rapat, dan menjawab pertanyaan. Ada yang bisa saya bantu?"}
 
@@ -232,16 +279,24 @@ func (e *AssistantDecisionEngineImpl) validateDecision(decision *AssistantDecisi
 	}
 
 	// Validate operation if present
-	if decision.Operation != "" {
-		validOperations := map[string]bool{
-			"nyalakan":    true,
-			"matikan":     true,
-			"brightness":  true,
-			"temperature": true,
-			"fan_speed":   true,
+	validOperations := map[string]bool{
+		"nyalakan":    true,
+		"matikan":     true,
+		"brightness":  true,
+		"temperature": true,
+		"fan_speed":   true,
+	}
+	if decision.Operation != "" && !validOperations[decision.Operation] {
+		return fmt.Errorf("invalid operation: %s", decision.Operation)
+	}
+
+	// Validate every step of a multi-device plan
+	for i, action := range decision.Actions {
+		if strings.TrimSpace(action.Device) == "" {
+			return fmt.Errorf("action %d requires 'device'", i+1)
 		}
-		if !validOperations[decision.Operation] {
-			return fmt.Errorf("invalid operation: %s", decision.Operation)
+		if !validOperations[action.Operation] {
+			return fmt.Errorf("action %d has invalid operation: %s", i+1, action.Operation)
 		}
 	}
 
@@ -250,8 +305,8 @@ func (e *AssistantDecisionEngineImpl) validateDecision(decision *AssistantDecisi
 		// Control requires either:
 		// 1. control_prompt (full command string), OR
 		// 2. operation + device_hints (structured command)
-		if decision.ControlPrompt != "" {
-			// control_prompt is sufficient on its own
+		if decision.ControlPrompt != "" || len(decision.Actions) > 0 {
+			// control_prompt or an action plan is sufficient on its own
 		} else if decision.Operation != "" {
 			// operation requires device_hints to be valid
 			if len(decision.DeviceHints) == 0 {
@@ -51,6 +51,7 @@ func (b *BaseOrchestrator) Execute(ctx *skills.SkillContext, prompt string) (*sk
 	finalPrompt := prompt
 	finalPrompt = strings.ReplaceAll(finalPrompt, "{{prompt}}", ctx.Prompt)
 	finalPrompt = strings.ReplaceAll(finalPrompt, "{{history}}", strings.Join(ctx.History, "\n"))
+	finalPrompt = strings.ReplaceAll(finalPrompt, "{{memory}}", ctx.Memory)
 	finalPrompt = strings.ReplaceAll(finalPrompt, "{{devices}}", renderRegisteredDevices(ctx))
 
 	// Special handling for Translation placeholders if present
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/utils"
 	"sensio/domain/models/rag/sensors"
 	"sensio/domain/models/rag/skills"
@@ -12,6 +13,11 @@ import (
 	"strings"
 )
 
+const (
+	maxLikelyDevices = 3
+	likelyScoreRatio = 0.85 // Matches scoring within 15% of the best one are flagged as likely
+)
+
 type ControlOrchestrator struct {
 	TuyaExecutor tuyaUsecases.TuyaDeviceControlExecutor
 	TuyaAuth     tuyaUsecases.TuyaAuthUseCase
@@ -49,6 +55,7 @@ func (o *ControlOrchestrator) Execute(ctx *skills.SkillContext, prompt string) (
 	// 3. Normal LLM Flow
 	finalPrompt := strings.ReplaceAll(prompt, "{{prompt}}", ctx.Prompt)
 	finalPrompt = strings.ReplaceAll(finalPrompt, "{{history}}", strings.Join(ctx.History, "\n"))
+	finalPrompt = strings.ReplaceAll(finalPrompt, "{{memory}}", ctx.Memory)
 	finalPrompt = strings.ReplaceAll(finalPrompt, "{{devices}}", deviceListStr)
 
 	res, err := ctx.LLM.CallModel(ctx.Ctx, finalPrompt, "high")
@@ -114,27 +121,40 @@ func (o *ControlOrchestrator) Execute(ctx *skills.SkillContext, prompt string) (
 	}, nil
 }
 
-func (o *ControlOrchestrator) getDevices(ctx *skills.SkillContext) ([]tuyaDtos.TuyaDeviceDTO, string, error) {
+// loadDevices returns the cached device list of the user, or nil when none is cached.
+func (o *ControlOrchestrator) loadDevices(ctx *skills.SkillContext) ([]tuyaDtos.TuyaDeviceDTO, error) {
 	userDevicesID := fmt.Sprintf("tuya:devices:uid:%s", ctx.UID)
 	aggJSON, ok := ctx.Vector.Get(userDevicesID)
 	if !ok {
-		return nil, "No devices connected.", nil
+		return nil, nil
 	}
 
 	var aggResp tuyaDtos.TuyaDevicesResponseDTO
 	if err := json.Unmarshal([]byte(aggJSON), &aggResp); err != nil {
+		return nil, err
+	}
+	return aggResp.Devices, nil
+}
+
+func (o *ControlOrchestrator) getDevices(ctx *skills.SkillContext) ([]tuyaDtos.TuyaDeviceDTO, string, error) {
+	devices, err := o.loadDevices(ctx)
+	if err != nil {
 		return nil, "", err
 	}
+	if devices == nil {
+		return nil, "No devices connected.", nil
+	}
 
 	// Detect "all lights" intent from the prompt
 	isAllLights := o.isAllLightsIntent(ctx.Prompt)
 
 	// Filter devices if "all lights" intent is detected
-	devices := aggResp.Devices
 	if isAllLights {
 		devices = o.filterLampDevices(devices)
 	}
 
+	devices, likely := o.rankDevicesByRelevance(ctx, devices)
+
 	var names []string
 	for _, d := range devices {
 		codes := []string{}
@@ -148,12 +168,64 @@ func (o *ControlOrchestrator) getDevices(ctx *skills.SkillContext) ([]tuyaDtos.T
 		if len(codes) > 0 {
 			controlsStr = fmt.Sprintf(" [Controls: %s]", strings.Join(codes, ", "))
 		}
-		names = append(names, fmt.Sprintf("- %s%s (ID: %s)", d.Name, controlsStr, d.ID))
+		likelyStr := ""
+		if likely[d.ID] {
+			likelyStr = " [likely match]"
+		}
+		names = append(names, fmt.Sprintf("- %s%s (ID: %s)%s", d.Name, controlsStr, d.ID, likelyStr))
 	}
 
 	return devices, strings.Join(names, "\n"), nil
 }
 
+// rankDevicesByRelevance orders devices by semantic similarity to the prompt using the vector
+// store, so the LLM sees the most likely targets first even when their names share no literal
+// word with the prompt ("AC" vs "Air Conditioner"). The best matches are returned as likely.
+// Devices without a similarity match keep their original order after the ranked ones.
+func (o *ControlOrchestrator) rankDevicesByRelevance(ctx *skills.SkillContext, devices []tuyaDtos.TuyaDeviceDTO) ([]tuyaDtos.TuyaDeviceDTO, map[string]bool) {
+	likely := make(map[string]bool)
+	if ctx.Vector == nil || len(devices) < 2 || strings.TrimSpace(ctx.Prompt) == "" {
+		return devices, likely
+	}
+
+	filter := map[string]string{"kind": "device"}
+	if ctx.UID != "" {
+		filter["uid"] = ctx.UID
+	}
+	matches, err := ctx.Vector.Query(ctx.Prompt, infrastructure.VectorQueryOptions{TopK: len(devices), Filter: filter})
+	if err != nil || len(matches) == 0 {
+		return devices, likely
+	}
+
+	byID := make(map[string]int, len(devices))
+	for i, d := range devices {
+		byID[d.ID] = i
+	}
+
+	ranked := make([]tuyaDtos.TuyaDeviceDTO, 0, len(devices))
+	seen := make(map[string]bool, len(devices))
+	topScore := matches[0].Score
+	for _, m := range matches {
+		idx, ok := byID[m.Metadata["device_id"]]
+		if !ok || seen[devices[idx].ID] {
+			continue
+		}
+		seen[devices[idx].ID] = true
+		ranked = append(ranked, devices[idx])
+		if len(likely) < maxLikelyDevices && m.Score >= topScore*likelyScoreRatio {
+			likely[devices[idx].ID] = true
+		}
+	}
+	for _, d := range devices {
+		if !seen[d.ID] {
+			ranked = append(ranked, d)
+		}
+	}
+
+	utils.LogDebug("ControlOrchestrator: similarity ranking top=%s score=%.3f", matches[0].ID, topScore)
+	return ranked, likely
+}
+
 // isAllLightsIntent detects if the prompt indicates an "all lights" command.
 // Returns true only if the prompt contains light-specific phrases (requires "lampu/light/lamp" explicitly).
 // This prevents accidental filtering of non-light devices for generic "all" commands.
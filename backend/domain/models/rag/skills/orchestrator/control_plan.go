@@ -0,0 +1,243 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"sensio/domain/models/rag/dtos"
+	"sensio/domain/models/rag/skills"
+	tuyaDtos "sensio/domain/tuya/dtos"
+	"strconv"
+	"strings"
+)
+
+const (
+	ActionStatusSuccess  = "success"
+	ActionStatusFailed   = "failed"
+	ActionStatusNotFound = "not_found"
+)
+
+// ExecutePlan runs each step of a multi-device plan in order and combines the outcomes into one
+// confirmation. A failed or unresolved step never stops the remaining ones.
+func (o *ControlOrchestrator) ExecutePlan(ctx *skills.SkillContext, actions []DeviceAction) (*dtos.ControlPlanResultDTO, error) {
+	// Unfiltered: "all lights and the AC" must still see the AC
+	devices, err := o.loadDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	isEn := strings.EqualFold(ctx.Language, "en")
+	result := &dtos.ControlPlanResultDTO{Actions: make([]dtos.ControlActionResultDTO, 0, len(actions))}
+	firstFailure := 0
+
+	for _, action := range actions {
+		targets := o.resolvePlanDevices(ctx, action, devices)
+		if len(targets) == 0 {
+			msg := fmt.Sprintf("Perangkat \"%s\" tidak ditemukan.", action.Device)
+			if isEn {
+				msg = fmt.Sprintf("I couldn't find a device matching \"%s\".", action.Device)
+			}
+			result.Actions = append(result.Actions, planActionResult(action, nil, ActionStatusNotFound, msg))
+			result.Failed++
+			if firstFailure == 0 {
+				firstFailure = 404
+			}
+			continue
+		}
+
+		// Sensors read the command from the prompt; the device is passed separately
+		stepCtx := *ctx
+		stepCtx.Prompt = action.controlPrompt()
+		for i := range targets {
+			target := &targets[i]
+			res, err := o.executeControl(&stepCtx, target)
+			if err != nil {
+				utils.LogWarn("ControlOrchestrator: Plan step failed | device_id=%s | operation=%s | error=%v", target.ID, action.Operation, err)
+				result.Actions = append(result.Actions, planActionResult(action, target, ActionStatusFailed, fmt.Sprintf("%s: %v", target.Name, err)))
+				result.Failed++
+				if firstFailure == 0 {
+					firstFailure = 500
+				}
+				continue
+			}
+			if res.HTTPStatusCode >= 400 {
+				result.Actions = append(result.Actions, planActionResult(action, target, ActionStatusFailed, fmt.Sprintf("%s: %s", target.Name, res.Message)))
+				result.Failed++
+				if firstFailure == 0 {
+					firstFailure = res.HTTPStatusCode
+				}
+				continue
+			}
+			result.Actions = append(result.Actions, planActionResult(action, target, ActionStatusSuccess, res.Message))
+			result.Succeeded++
+		}
+	}
+
+	result.HTTPStatusCode = 200
+	if result.Succeeded == 0 && firstFailure != 0 {
+		result.HTTPStatusCode = firstFailure
+	}
+	result.Message = combinePlanMessage(result, isEn)
+	return result, nil
+}
+
+// controlPrompt renders the step as a deterministic command the device sensors understand.
+// Brightness is given in percent and sent on the 0-1000 scale Tuya lights use.
+func (a DeviceAction) controlPrompt() string {
+	parts := []string{a.Operation}
+	if a.Switch > 0 {
+		parts = append(parts, fmt.Sprintf("switch %d", a.Switch))
+	}
+	value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(a.Value), "%"))
+	if a.Operation == "brightness" {
+		if pct, err := strconv.Atoi(value); err == nil && pct >= 0 && pct <= 100 {
+			value = strconv.Itoa(pct * 10)
+		}
+	}
+	if value != "" {
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// resolvePlanDevices finds the device(s) a step refers to: every lamp for "all lights", otherwise
+// the single best match by name, then by shared words, then by similarity search.
+func (o *ControlOrchestrator) resolvePlanDevices(ctx *skills.SkillContext, action DeviceAction, devices []tuyaDtos.TuyaDeviceDTO) []tuyaDtos.TuyaDeviceDTO {
+	hint := strings.ToLower(strings.TrimSpace(action.Device))
+	if hint == "" || len(devices) == 0 {
+		return nil
+	}
+	if o.isAllLightsIntent(hint) {
+		return o.filterLampDevices(devices)
+	}
+
+	// 1. Exact or containing name, on word boundaries so "ac" does not match "Black Lamp"
+	var named []tuyaDtos.TuyaDeviceDTO
+	paddedHint := " " + hint + " "
+	for _, d := range devices {
+		name := strings.ToLower(d.Name)
+		if name == hint {
+			return []tuyaDtos.TuyaDeviceDTO{d}
+		}
+		paddedName := " " + name + " "
+		if strings.Contains(paddedName, paddedHint) || strings.Contains(paddedHint, paddedName) {
+			named = append(named, d)
+		}
+	}
+	if len(named) == 1 {
+		return named
+	}
+
+	// 2. Most shared words, only when one device wins
+	candidates := named
+	if len(candidates) == 0 {
+		candidates = devices
+	}
+	if best := bestWordMatch(hint, candidates); best != nil {
+		return []tuyaDtos.TuyaDeviceDTO{*best}
+	}
+
+	// 3. A gang number only makes sense on a multi-switch device
+	if action.Switch > 0 {
+		var switches []tuyaDtos.TuyaDeviceDTO
+		code := fmt.Sprintf("switch_%d", action.Switch)
+		for _, d := range candidates {
+			for _, st := range d.Status {
+				if st.Code == code {
+					switches = append(switches, d)
+					break
+				}
+			}
+		}
+		if len(switches) == 1 {
+			return switches
+		}
+	}
+
+	// 4. Similarity search ("ac" vs "Air Conditioner")
+	if ctx.Vector != nil {
+		filter := map[string]string{"kind": "device"}
+		if ctx.UID != "" {
+			filter["uid"] = ctx.UID
+		}
+		matches, err := ctx.Vector.Query(action.Device, infrastructure.VectorQueryOptions{TopK: 1, Filter: filter})
+		if err == nil && len(matches) > 0 {
+			for _, d := range candidates {
+				if d.ID == matches[0].Metadata["device_id"] {
+					return []tuyaDtos.TuyaDeviceDTO{d}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// bestWordMatch returns the device sharing the most words with hint, or nil on a tie or no overlap.
+func bestWordMatch(hint string, devices []tuyaDtos.TuyaDeviceDTO) *tuyaDtos.TuyaDeviceDTO {
+	var best *tuyaDtos.TuyaDeviceDTO
+	bestScore, tie := 0, false
+	for i := range devices {
+		nameWords := strings.Fields(strings.ToLower(devices[i].Name))
+		score := 0
+		for _, w := range strings.Fields(hint) {
+			for _, nw := range nameWords {
+				if len(w) > 1 && w == nw {
+					score++
+					break
+				}
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = &devices[i], score, false
+		case score == bestScore && score > 0:
+			tie = true
+		}
+	}
+	if tie {
+		return nil
+	}
+	return best
+}
+
+func planActionResult(action DeviceAction, target *tuyaDtos.TuyaDeviceDTO, status, message string) dtos.ControlActionResultDTO {
+	res := dtos.ControlActionResultDTO{
+		Device:    action.Device,
+		Operation: action.Operation,
+		Value:     action.Value,
+		Switch:    action.Switch,
+		Status:    status,
+		Message:   message,
+	}
+	if target != nil {
+		res.DeviceID = target.ID
+		res.DeviceName = target.Name
+	}
+	return res
+}
+
+// combinePlanMessage leads with an overall outcome and lists one line per step.
+func combinePlanMessage(result *dtos.ControlPlanResultDTO, isEn bool) string {
+	total := result.Succeeded + result.Failed
+	var head string
+	switch {
+	case result.Failed == 0 && isEn:
+		head = fmt.Sprintf("Done, all %d actions completed:", total)
+	case result.Failed == 0:
+		head = fmt.Sprintf("Baik, %d perintah berhasil dijalankan:", total)
+	case result.Succeeded == 0 && isEn:
+		head = "Sorry, none of the actions could be completed:"
+	case result.Succeeded == 0:
+		head = "Maaf, tidak ada perintah yang berhasil dijalankan:"
+	case isEn:
+		head = fmt.Sprintf("%d of %d actions completed:", result.Succeeded, total)
+	default:
+		head = fmt.Sprintf("%d dari %d perintah berhasil dijalankan:", result.Succeeded, total)
+	}
+
+	lines := []string{head}
+	for _, a := range result.Actions {
+		lines = append(lines, "- "+a.Message)
+	}
+	return strings.Join(lines, "\n")
+}
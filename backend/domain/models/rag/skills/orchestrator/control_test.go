@@ -2,11 +2,13 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/json"
 	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/utils"
 	"sensio/domain/models/rag/skills"
 	tuyaDtos "sensio/domain/tuya/dtos"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -393,3 +395,114 @@ func TestGetDevices_EnglishAllLights(t *testing.T) {
 		t.Errorf("Expected 1 device (lamp only), got %d", len(returnedDevices))
 	}
 }
+
+func TestRankDevicesByRelevance_MatchesWithoutSharedWords(t *testing.T) {
+	vector := infrastructure.NewVectorService("")
+	seed := map[string]string{
+		"lamp-1": "Device: Lampu Teras | Category: Light",
+		"fan-1":  "Device: Kipas Angin Ruang Tamu | Category: Fan",
+		"tv-1":   "Device: Smart TV | Category: TV",
+	}
+	for id, doc := range seed {
+		if err := vector.Upsert("tuya:device:"+id, doc, map[string]interface{}{"kind": "device", "uid": "u1", "device_id": id}); err != nil {
+			t.Fatalf("failed to seed vector: %v", err)
+		}
+	}
+
+	devices := []tuyaDtos.TuyaDeviceDTO{
+		{ID: "lamp-1", Name: "Lampu Teras"},
+		{ID: "tv-1", Name: "Smart TV"},
+		{ID: "fan-1", Name: "Kipas Angin Ruang Tamu"},
+	}
+
+	o := NewControlOrchestrator(&MockTuyaDeviceControlExecutor{}, &MockTuyaAuthUseCase{})
+	ranked, likely := o.rankDevicesByRelevance(&skills.SkillContext{UID: "u1", Prompt: "tolong matikan kipasnya", Vector: vector}, devices)
+
+	if len(ranked) != len(devices) {
+		t.Fatalf("expected all %d devices to be kept, got %d", len(devices), len(ranked))
+	}
+	if ranked[0].ID != "fan-1" {
+		t.Errorf("expected fan-1 to be ranked first, got %s", ranked[0].ID)
+	}
+	if !likely["fan-1"] {
+		t.Errorf("expected fan-1 to be flagged as likely, got %v", likely)
+	}
+}
+
+// recordingExecutor records every command sent and rejects commands for the devices in offline
+type recordingExecutor struct {
+	switchCmds map[string][]tuyaDtos.TuyaCommandDTO
+	irCmds     map[string]map[string]int
+	offline    map[string]bool
+}
+
+func (e *recordingExecutor) SendSwitchCommand(accessToken, deviceID string, commands []tuyaDtos.TuyaCommandDTO) (bool, error) {
+	if e.offline[deviceID] {
+		return false, nil
+	}
+	e.switchCmds[deviceID] = append(e.switchCmds[deviceID], commands...)
+	return true, nil
+}
+
+func (e *recordingExecutor) SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error) {
+	e.irCmds[remoteID] = params
+	return true, nil
+}
+
+func TestExecutePlan_RunsEveryStepWithPerActionResults(t *testing.T) {
+	devices := tuyaDtos.TuyaDevicesResponseDTO{Devices: []tuyaDtos.TuyaDeviceDTO{
+		{ID: "ir-1", RemoteID: "ac-1", Name: "Bedroom AC", Category: "infrared_ac"},
+		{ID: "lamp-1", Name: "Desk Lamp", Category: "dj", Status: []tuyaDtos.TuyaDeviceStatusDTO{{Code: "switch_led", Value: true}, {Code: "bright_value_v2", Value: 1000}}},
+		{ID: "gang-1", Name: "Saklar Dapur", Category: "kg", Status: []tuyaDtos.TuyaDeviceStatusDTO{{Code: "switch_1", Value: false}, {Code: "switch_2", Value: false}, {Code: "switch_3", Value: false}}},
+		{ID: "lamp-2", Name: "Porch Light", Category: "dj", Status: []tuyaDtos.TuyaDeviceStatusDTO{{Code: "switch_led", Value: false}}},
+	}}
+	raw, _ := json.Marshal(devices)
+	vector := infrastructure.NewVectorService("")
+	_ = vector.Upsert("tuya:devices:uid:u1", string(raw), nil)
+
+	executor := &recordingExecutor{
+		switchCmds: map[string][]tuyaDtos.TuyaCommandDTO{},
+		irCmds:     map[string]map[string]int{},
+		offline:    map[string]bool{"lamp-2": true},
+	}
+	o := NewControlOrchestrator(executor, &MockTuyaAuthUseCase{})
+	ctx := &skills.SkillContext{Ctx: context.Background(), UID: "u1", Language: "en", Prompt: "turn off the AC, dim the lamp to 30%, switch 1 and 3 on and the porch light and garage door", Vector: vector}
+
+	result, err := o.ExecutePlan(ctx, []DeviceAction{
+		{Device: "ac", Operation: "matikan"},
+		{Device: "lamp", Operation: "brightness", Value: "30%"},
+		{Device: "switch", Operation: "nyalakan", Switch: 1},
+		{Device: "switch", Operation: "nyalakan", Switch: 3},
+		{Device: "porch light", Operation: "nyalakan"},
+		{Device: "garage door", Operation: "nyalakan"},
+	})
+	if err != nil {
+		t.Fatalf("ExecutePlan returned error: %v", err)
+	}
+
+	if executor.irCmds["ac-1"]["power"] != 0 {
+		t.Errorf("expected the AC to be powered off, got %v", executor.irCmds["ac-1"])
+	}
+	if cmds := executor.switchCmds["lamp-1"]; len(cmds) != 1 || cmds[0].Code != "bright_value_v2" || cmds[0].Value != 300 {
+		t.Errorf("expected lamp brightness 300, got %+v", cmds)
+	}
+	if cmds := executor.switchCmds["gang-1"]; len(cmds) != 2 || cmds[0].Code != "switch_1" || cmds[1].Code != "switch_3" {
+		t.Errorf("expected switch_1 and switch_3 on the gang switch, got %+v", cmds)
+	}
+
+	wantStatus := []string{ActionStatusSuccess, ActionStatusSuccess, ActionStatusSuccess, ActionStatusSuccess, ActionStatusFailed, ActionStatusNotFound}
+	if len(result.Actions) != len(wantStatus) {
+		t.Fatalf("expected %d action results, got %d", len(wantStatus), len(result.Actions))
+	}
+	for i, want := range wantStatus {
+		if result.Actions[i].Status != want {
+			t.Errorf("action %d: expected status %s, got %s (%s)", i, want, result.Actions[i].Status, result.Actions[i].Message)
+		}
+	}
+	if result.Succeeded != 4 || result.Failed != 2 || result.HTTPStatusCode != 200 {
+		t.Errorf("expected 4 succeeded, 2 failed and status 200, got %d, %d and %d", result.Succeeded, result.Failed, result.HTTPStatusCode)
+	}
+	if !strings.HasPrefix(result.Message, "4 of 6 actions completed:") {
+		t.Errorf("unexpected combined message: %q", result.Message)
+	}
+}
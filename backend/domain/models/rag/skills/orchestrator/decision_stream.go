@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	decisionIntentPattern   = regexp.MustCompile(`"intent"\s*:\s*"(\w+)"`)
+	decisionResponsePattern = regexp.MustCompile(`"response"\s*:\s*"`)
+)
+
+// streamedIntents are the intents whose "response" is the final answer shown to the user.
+// Control and blocked replies are rewritten by the chat flow, so they are never streamed.
+var streamedIntents = map[string]bool{
+	"chat":     true,
+	"identity": true,
+}
+
+// decisionResponseStreamer extracts the "response" string from a decision JSON while it is
+// still being generated and forwards the newly decoded text once the intent is known.
+type decisionResponseStreamer struct {
+	raw     strings.Builder
+	emitted int
+	onDelta func(delta string)
+}
+
+func newDecisionResponseStreamer(onDelta func(delta string)) *decisionResponseStreamer {
+	return &decisionResponseStreamer{onDelta: onDelta}
+}
+
+// Write appends a raw model chunk and emits any newly available response text.
+func (s *decisionResponseStreamer) Write(chunk string) {
+	s.raw.WriteString(chunk)
+	raw := s.raw.String()
+
+	intent := decisionIntentPattern.FindStringSubmatch(raw)
+	if intent == nil || !streamedIntents[intent[1]] {
+		return
+	}
+	loc := decisionResponsePattern.FindStringIndex(raw)
+	if loc == nil {
+		return
+	}
+
+	text := decodePartialJSONString(raw[loc[1]:])
+	if len(text) > s.emitted {
+		s.onDelta(text[s.emitted:])
+		s.emitted = len(text)
+	}
+}
+
+// decodePartialJSONString decodes the body of a JSON string literal that may be cut off
+// mid-stream. Decoding stops at the closing quote or before an incomplete escape sequence.
+func decodePartialJSONString(body string) string {
+	var out strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c == '"' {
+			break
+		}
+		if c != '\\' {
+			out.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(body) {
+			break
+		}
+		switch esc := body[i+1]; esc {
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case 'r':
+			out.WriteByte('\r')
+		case 'b':
+			out.WriteByte('\b')
+		case 'f':
+			out.WriteByte('\f')
+		case 'u':
+			r, size, ok := decodeUnicodeEscape(body[i:])
+			if !ok {
+				return out.String()
+			}
+			out.WriteRune(r)
+			i += size - 1
+			continue
+		default:
+			out.WriteByte(esc)
+		}
+		i++
+	}
+	return out.String()
+}
+
+// decodeUnicodeEscape decodes a \uXXXX escape (or a surrogate pair) at the start of s,
+// reporting ok=false while it is still incomplete.
+func decodeUnicodeEscape(s string) (rune, int, bool) {
+	if len(s) < 6 {
+		return 0, 0, false
+	}
+	code, err := strconv.ParseUint(s[2:6], 16, 16)
+	if err != nil {
+		return '�', 6, true
+	}
+	r := rune(code)
+	if !utf16.IsSurrogate(r) {
+		return r, 6, true
+	}
+	if len(s) < 12 {
+		return 0, 0, false
+	}
+	if s[6] == '\\' && s[7] == 'u' {
+		if low, err := strconv.ParseUint(s[8:12], 16, 16); err == nil {
+			return utf16.DecodeRune(r, rune(low)), 12, true
+		}
+	}
+	return '�', 6, true
+}
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sensio/domain/models/rag/skills"
+)
+
+// chunkedLLM streams a fixed answer in small fragments
+type chunkedLLM struct {
+	answer string
+	size   int
+}
+
+func (c *chunkedLLM) CallModel(_ context.Context, _ string, _ string) (string, error) {
+	return c.answer, nil
+}
+
+func (c *chunkedLLM) StreamModel(_ context.Context, _ string, _ string, onChunk func(chunk string)) (string, error) {
+	for i := 0; i < len(c.answer); i += c.size {
+		end := i + c.size
+		if end > len(c.answer) {
+			end = len(c.answer)
+		}
+		onChunk(c.answer[i:end])
+	}
+	return c.answer, nil
+}
+
+func TestDecideStream_StreamsChatResponseText(t *testing.T) {
+	answer := `{"intent":"chat","response":"Halo! \"Sensio\" siap\nmembantu é \u00e9 \ud83d\ude00"}`
+	for _, size := range []int{1, 3, 7, len(answer)} {
+		var streamed strings.Builder
+		engine := NewAssistantDecisionEngine(&chunkedLLM{answer: answer, size: size})
+		decision, err := engine.DecideStream(&skills.SkillContext{Ctx: context.Background(), Prompt: "halo"}, func(delta string) {
+			streamed.WriteString(delta)
+		})
+		if err != nil {
+			t.Fatalf("size %d: DecideStream returned error: %v", size, err)
+		}
+		if streamed.String() != decision.Response {
+			t.Fatalf("size %d: streamed %q, decision response %q", size, streamed.String(), decision.Response)
+		}
+	}
+}
+
+func TestDecideStream_DoesNotStreamControlResponse(t *testing.T) {
+	answer := `{"intent":"control","response":"Menyalakan lampu","control_prompt":"nyalakan lampu"}`
+	engine := NewAssistantDecisionEngine(&chunkedLLM{answer: answer, size: 4})
+	decision, err := engine.DecideStream(&skills.SkillContext{Ctx: context.Background(), Prompt: "nyalakan lampu"}, func(delta string) {
+		t.Fatalf("control response must not be streamed, got %q", delta)
+	})
+	if err != nil {
+		t.Fatalf("DecideStream returned error: %v", err)
+	}
+	if decision.Intent != "control" {
+		t.Fatalf("expected control intent, got %q", decision.Intent)
+	}
+}
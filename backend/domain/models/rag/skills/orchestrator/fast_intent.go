@@ -15,12 +15,15 @@ const (
 	FastIntentIdentity  FastIntentType = "identity"
 	FastIntentControl   FastIntentType = "control"
 	FastIntentDiscovery FastIntentType = "discovery"
+	FastIntentUndo      FastIntentType = "undo"
+	FastIntentRoom      FastIntentType = "room"
 )
 
 // FastIntentResult contains the classification result and extracted control data.
 type FastIntentResult struct {
 	Intent       FastIntentType
 	DeviceName   string  // extracted device name if control
+	RoomName     string  // room of a room-wide command, empty for the terminal's own room
 	ActionType   string  // "on", "off", "brightness", "temperature", "fan_speed"
 	Value        string  // extracted value (e.g., "50", "24", "level_2")
 	ValuePercent int     // normalized percentage value if applicable
@@ -35,6 +38,10 @@ type FastIntentRouter struct {
 	temperaturePattern *regexp.Regexp
 	fanSpeedPattern    *regexp.Regexp
 	deviceNamePattern  *regexp.Regexp
+	roomPattern        *regexp.Regexp
+	undoPatterns       []*regexp.Regexp
+	stepPattern        *regexp.Regexp
+	actionablePattern  *regexp.Regexp
 }
 
 // NewFastIntentRouter creates a new fast intent router with pre-compiled patterns.
@@ -44,6 +51,18 @@ func NewFastIntentRouter() *FastIntentRouter {
 		temperaturePattern: regexp.MustCompile(`(?i)(\d+)\s*(derajat|degree|°c|celsius)|temp(?:erature)?\s*(\d+)`),
 		fanSpeedPattern:    regexp.MustCompile(`(?i)(kipas|fan)\s*(level|speed|kecepatan)\s*(\d+)|fan\s*(low|medium|high)|kipas\s*(pelan|sedang|kencang)`),
 		deviceNamePattern:  regexp.MustCompile(`(?i)(lampu|light|ac|kipas|fan|tv|speaker|perangkat|device)\s+([a-z0-9\s]+)`),
+		roomPattern:        regexp.MustCompile(`(?i)(?:everything|all (?:the )?devices|semua perangkat|semuanya|semua)\s+(?:in|inside|di|yang ada di)\s+(?:the\s+)?([a-z0-9\s]+?)\s*(?:please|tolong|ya)?[.!?]*$`),
+		// Whole-utterance undo requests only: "batalkan meeting besok" or "how do I undo a scene" are not
+		undoPatterns: []*regexp.Regexp{
+			// "undo", "undo that please", "tolong batalkan yang tadi", "balikin lagi dong"
+			regexp.MustCompile(`^(?:(?:please|tolong)\s+)?(?:undo|revert|batalkan|urungkan|balikin|kembalikan)(?:\s+(?:the last change|last change|yang tadi|perubahan terakhir|perubahannya|that|it|this|itu|tadi|lagi|aja|saja|dong|ya|please|tolong))*[.!?]*$`),
+			// "put it back", "change it back", "kembalikan lampu seperti semula"
+			regexp.MustCompile(`^(?:(?:please|tolong)\s+)?(?:kembalikan|balikin|put|change|set)(?:\s+[\p{L}\d]+){0,3}?\s+(?:seperti semula|kayak semula|ke semula|like before|back)(?:\s+(?:lagi|dong|ya|please|tolong))*[.!?]*$`),
+		},
+		// Conjunctions and sequencing words that may join two commands; a comma alone does not
+		stepPattern: regexp.MustCompile(`(?:,\s*|\s+)(?:and then|and|then|after that|dan|lalu|terus|kemudian|serta|setelah itu)\s+`),
+		// A clause is actionable when it gives a command or names a target ("... and the fan", "switch 1 and 3")
+		actionablePattern: regexp.MustCompile(`\b(?:nyalakan|matikan|hidupkan|turn|switch|set|atur|adjust|dim|redupkan|naikkan|turunkan|open|close|buka|tutup|lock|unlock|kunci|undo|batalkan|lampu|light|lights|lamp|ac|kipas|fan|tv|speaker|saklar|tirai|curtain|perangkat|device)\b|^\d+\b`),
 	}
 }
 
@@ -67,11 +86,24 @@ func (r *FastIntentRouter) Classify(prompt string) FastIntentResult {
 		}
 	}
 
+	// Check for room-wide prompts before control: "matikan semua perangkat di ruang rapat" names no device
+	if result, ok := r.isRoomPrompt(promptLower); ok {
+		return result
+	}
+
 	// Check for control prompts
 	if result, ok := r.isControlPrompt(promptLower); ok {
 		return result
 	}
 
+	// Check for undo prompts last, so a command that happens to mention "undo" is never reverted
+	if r.isUndoPrompt(promptLower) {
+		return FastIntentResult{
+			Intent:     FastIntentUndo,
+			Confidence: 0.8,
+		}
+	}
+
 	// No fast match
 	return FastIntentResult{
 		Intent:     FastIntentNone,
@@ -149,8 +181,77 @@ func (r *FastIntentRouter) isDiscoveryPrompt(prompt string) bool {
 	return false
 }
 
+// isUndoPrompt checks if the whole prompt is a short request to revert the last device change.
+func (r *FastIntentRouter) isUndoPrompt(prompt string) bool {
+	if len(strings.Fields(prompt)) > 6 {
+		return false
+	}
+	for _, pattern := range r.undoPatterns {
+		if pattern.MatchString(prompt) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMultiStepPrompt checks if the prompt chains several commands or targets ("turn off the AC and
+// dim the lamp", "switch 1 and 3"). Those need a plan from the decision engine, not a single target.
+// Only a conjunction or sequencing word between two actionable clauses counts: "turn on the light,
+// please" and "the living and dining room" are single commands.
+func (r *FastIntentRouter) isMultiStepPrompt(prompt string) bool {
+	actionable := 0
+	for _, clause := range r.stepPattern.Split(prompt, -1) {
+		if r.actionablePattern.MatchString(strings.TrimSpace(clause)) {
+			actionable++
+		}
+	}
+	return actionable >= 2
+}
+
+// isRoomPrompt checks if the prompt turns every device of a room on or off ("turn off everything
+// in meeting room 3", "nyalakan semua perangkat di ruangan ini").
+func (r *FastIntentRouter) isRoomPrompt(prompt string) (FastIntentResult, bool) {
+	if r.isMultiStepPrompt(prompt) {
+		return FastIntentResult{}, false
+	}
+
+	action := ""
+	switch {
+	case strings.Contains(prompt, "nyalakan") || strings.Contains(prompt, "hidupkan") || strings.Contains(prompt, "turn on") || strings.Contains(prompt, "switch on"):
+		action = "on"
+	case strings.Contains(prompt, "matikan") || strings.Contains(prompt, "turn off") || strings.Contains(prompt, "switch off"):
+		action = "off"
+	default:
+		return FastIntentResult{}, false
+	}
+
+	room := ""
+	if !strings.Contains(prompt, "everything here") {
+		matches := r.roomPattern.FindStringSubmatch(prompt)
+		if len(matches) < 2 {
+			return FastIntentResult{}, false
+		}
+		room = strings.TrimSpace(matches[1])
+	}
+	switch room {
+	case "this room", "here", "ruangan ini", "ruang ini", "kamar ini", "sini":
+		room = ""
+	}
+
+	return FastIntentResult{
+		Intent:     FastIntentRoom,
+		RoomName:   room,
+		ActionType: action,
+		Confidence: 0.9,
+	}, true
+}
+
 // isControlPrompt checks if the prompt is a device control command.
 func (r *FastIntentRouter) isControlPrompt(prompt string) (FastIntentResult, bool) {
+	if r.isMultiStepPrompt(prompt) {
+		return FastIntentResult{}, false
+	}
+
 	// Check for on/off commands
 	if strings.Contains(prompt, "nyalakan") || strings.Contains(prompt, "hidupkan") || strings.Contains(prompt, "turn on") {
 		deviceName := r.extractDeviceName(prompt)
@@ -0,0 +1,80 @@
+package orchestrator
+
+import "testing"
+
+func TestFastIntentRouter_Undo(t *testing.T) {
+	router := NewFastIntentRouter()
+
+	undo := []string{
+		"undo",
+		"Undo that please",
+		"Batalkan!",
+		"tolong batalkan yang tadi",
+		"balikin lagi dong",
+		"revert the last change",
+		"put it back",
+		"change it back please",
+		"kembalikan lampu seperti semula",
+	}
+	for _, prompt := range undo {
+		if got := router.Classify(prompt); got.Intent != FastIntentUndo || got.Confidence >= 1.0 {
+			t.Errorf("Classify(%q) = %s (%.2f); want undo below full confidence", prompt, got.Intent, got.Confidence)
+		}
+	}
+
+	notUndo := []string{
+		"batalkan meeting besok",
+		"how do I undo a scene",
+		"bagaimana cara undo di aplikasi",
+		"revert the document to version 2",
+		"balikin buku ke perpustakaan besok pagi ya",
+		"kembalikan uangnya",
+		"matikan lampu kamar lalu undo",
+	}
+	for _, prompt := range notUndo {
+		if got := router.Classify(prompt); got.Intent == FastIntentUndo {
+			t.Errorf("Classify(%q) = undo; want another intent", prompt)
+		}
+	}
+
+	// Control still wins over an undo-like phrase
+	if got := router.Classify("nyalakan lampu kamar"); got.Intent != FastIntentControl {
+		t.Errorf("Classify(control) = %s; want control", got.Intent)
+	}
+}
+
+func TestFastIntentRouter_MultiStep(t *testing.T) {
+	router := NewFastIntentRouter()
+
+	multiStep := []string{
+		"turn off the ac and dim the lamp to 30%",
+		"turn on switch 1 and 3",
+		"nyalakan lampu dan kipas",
+		"matikan ac, kemudian nyalakan tv",
+		"nyalakan lampu teras lalu matikan kipas",
+		"turn on the lamp, then set the ac to 24 degrees",
+	}
+	for _, prompt := range multiStep {
+		if !router.isMultiStepPrompt(prompt) {
+			t.Errorf("isMultiStepPrompt(%q) = false; want true", prompt)
+		}
+	}
+
+	singleStep := []string{
+		"turn on the light, please",
+		"set the ac to 24 degrees, thanks",
+		"nyalakan lampu, ya",
+		"turn on the lamp in the living and dining room",
+		"matikan tv dan terima kasih",
+	}
+	for _, prompt := range singleStep {
+		if router.isMultiStepPrompt(prompt) {
+			t.Errorf("isMultiStepPrompt(%q) = true; want false", prompt)
+		}
+	}
+
+	// A polite comma no longer hides a single command from the fast path
+	if got := router.Classify("nyalakan lampu kamar, ya"); got.Intent != FastIntentControl {
+		t.Errorf("Classify(single command with comma) = %s; want control", got.Intent)
+	}
+}
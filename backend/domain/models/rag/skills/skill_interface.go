@@ -16,6 +16,25 @@ type Healthcheckable interface {
 	HealthCheck() bool
 }
 
+// StreamingLLMClient is implemented by LLM clients that can deliver the answer incrementally.
+// onChunk receives each text fragment as it arrives; the full text is returned at the end.
+type StreamingLLMClient interface {
+	StreamModel(ctx context.Context, prompt string, model string, onChunk func(chunk string)) (string, error)
+}
+
+// StreamModel streams from llm when it supports streaming, otherwise it calls the model and
+// delivers the whole answer as a single chunk.
+func StreamModel(ctx context.Context, llm LLMClient, prompt string, model string, onChunk func(chunk string)) (string, error) {
+	if streamer, ok := llm.(StreamingLLMClient); ok {
+		return streamer.StreamModel(ctx, prompt, model, onChunk)
+	}
+	result, err := llm.CallModel(ctx, prompt, model)
+	if err == nil && result != "" {
+		onChunk(result)
+	}
+	return result, err
+}
+
 // SkillContext holds the shared services and state needed by skills during execution.
 type SkillContext struct {
 	Ctx        context.Context
@@ -24,9 +43,10 @@ type SkillContext struct {
 	Prompt     string
 	Language   string
 	History    []string
+	Memory     string // Long-term memory: summary of older turns and remembered user facts
 	LLM        LLMClient
 	Config     *utils.Config
-	Vector     *infrastructure.VectorService
+	Vector     infrastructure.VectorStore
 	Badger     *infrastructure.BadgerService
 
 	// Metadata for Meeting Summaries / MoM
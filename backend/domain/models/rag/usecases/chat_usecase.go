@@ -3,13 +3,19 @@ package usecases
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/providers"
 	"sensio/domain/common/utils"
+	memoryDtos "sensio/domain/memory/dtos"
 	"sensio/domain/models/rag/dtos"
 	"sensio/domain/models/rag/skills"
 	"sensio/domain/models/rag/skills/orchestrator"
+	roomDtos "sensio/domain/room/dtos"
+	roomUsecases "sensio/domain/room/usecases"
+	snapshotDtos "sensio/domain/snapshot/dtos"
+	snapshotUsecases "sensio/domain/snapshot/usecases"
 	tuyaDtos "sensio/domain/tuya/dtos"
 	"strings"
 	"time"
@@ -17,6 +23,26 @@ import (
 
 type ChatUseCase interface {
 	Chat(ctx context.Context, uid, terminalID, prompt, language, requestID string) (*dtos.RAGChatResponseDTO, error)
+	// ChatStream is Chat with the conversational reply streamed to onDelta while it is generated.
+	// Fast paths, control and blocked replies are not streamed; the returned response is authoritative.
+	ChatStream(ctx context.Context, uid, terminalID, prompt, language, requestID string, onDelta func(delta string)) (*dtos.RAGChatResponseDTO, error)
+}
+
+// ConversationMemory is the long-term chat memory of a user on a terminal
+type ConversationMemory interface {
+	Recall(terminalID, uid string) (*memoryDtos.RecallDTO, error)
+	Remember(ctx context.Context, llm skills.LLMClient, terminalID, uid, prompt, response string) error
+}
+
+// StateUndoer reverts the last device change of a terminal ("undo that")
+type StateUndoer interface {
+	UndoLastChange(terminalID, accessToken string) (*snapshotDtos.UndoResultDTO, error)
+}
+
+// RoomController turns every device of a room on or off ("turn off everything in meeting room 3").
+// An empty room name means the room of the terminal.
+type RoomController interface {
+	ControlRoomByName(terminalID, roomName, action, accessToken string) (*roomDtos.RoomControlResultDTO, error)
 }
 
 type ChatUseCaseImpl struct {
@@ -24,12 +50,15 @@ type ChatUseCaseImpl struct {
 	fallbackLLM      skills.LLMClient
 	config           *utils.Config
 	badger           *infrastructure.BadgerService
-	vector           *infrastructure.VectorService
+	vector           infrastructure.VectorStore
 	guard            *orchestrator.GuardOrchestrator
 	fastIntentRouter *orchestrator.FastIntentRouter
 	decisionEngine   *orchestrator.AssistantDecisionEngineImpl
 	providerResolver providers.ProviderResolver
 	controlUseCase   ControlUseCase // For actual device execution
+	undoer           StateUndoer
+	memory           ConversationMemory
+	rooms            RoomController
 	// Keep orchestrator for backward compatibility during migration
 	orchestrator *orchestrator.Router
 }
@@ -39,13 +68,16 @@ func NewChatUseCase(
 	fallbackLLM skills.LLMClient,
 	cfg *utils.Config,
 	badger *infrastructure.BadgerService,
-	vector *infrastructure.VectorService,
+	vector infrastructure.VectorStore,
 	guard *orchestrator.GuardOrchestrator,
 	fastIntentRouter *orchestrator.FastIntentRouter,
 	decisionEngine *orchestrator.AssistantDecisionEngineImpl,
 	providerResolver providers.ProviderResolver,
 	controlUseCase ControlUseCase,
 	orchestrator *orchestrator.Router, // kept for migration
+	undoer StateUndoer,
+	memory ConversationMemory,
+	rooms RoomController,
 ) ChatUseCase {
 	return &ChatUseCaseImpl{
 		llm:              llm,
@@ -59,10 +91,21 @@ func NewChatUseCase(
 		providerResolver: providerResolver,
 		controlUseCase:   controlUseCase,
 		orchestrator:     orchestrator,
+		undoer:           undoer,
+		memory:           memory,
+		rooms:            rooms,
 	}
 }
 
 func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, language, requestID string) (*dtos.RAGChatResponseDTO, error) {
+	return u.chat(ctx, uid, terminalID, prompt, language, requestID, nil)
+}
+
+func (u *ChatUseCaseImpl) ChatStream(ctx context.Context, uid, terminalID, prompt, language, requestID string, onDelta func(delta string)) (*dtos.RAGChatResponseDTO, error) {
+	return u.chat(ctx, uid, terminalID, prompt, language, requestID, onDelta)
+}
+
+func (u *ChatUseCaseImpl) chat(ctx context.Context, uid, terminalID, prompt, language, requestID string, onDelta func(delta string)) (*dtos.RAGChatResponseDTO, error) {
 	ucStart := time.Now()
 	pipelinePath := "unknown"
 	var controlDuration time.Duration // Track control execution time separately
@@ -126,18 +169,17 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		}
 	}
 
-	// 1. Get History
+	// 1. Recall conversational memory (recent turns, rolling summary, remembered facts)
 	historyStart := time.Now()
-	historyKey := fmt.Sprintf("chat_history:%s", terminalID)
 	var history []string
-	if u.badger != nil {
-		data, _ := u.badger.Get(historyKey)
-		if data != nil {
-			unmarshalStart := time.Now()
-			_ = json.Unmarshal(data, &history)
-			utils.LogDebug("ChatUseCase: History retrieved | key=%s | cache_duration_ms=%d | unmarshal_duration_ms=%d | history_size=%d", historyKey, time.Since(historyStart).Milliseconds()-time.Since(unmarshalStart).Milliseconds(), time.Since(unmarshalStart).Milliseconds(), len(history))
+	var memoryContext string
+	if u.memory != nil {
+		recall, err := u.memory.Recall(terminalID, uid)
+		if err != nil {
+			utils.LogWarn("ChatUseCase: Memory recall failed | terminal=%s | error=%v", terminalID, err)
 		} else {
-			utils.LogDebug("ChatUseCase: History not found | key=%s | duration_ms=%d", historyKey, time.Since(historyStart).Milliseconds())
+			history, memoryContext = recall.History, recall.Context
+			utils.LogDebug("ChatUseCase: Memory recalled | terminal=%s | duration_ms=%d | history_size=%d | has_context=%t", terminalID, time.Since(historyStart).Milliseconds(), len(history), memoryContext != "")
 		}
 	}
 	historyDuration := time.Since(historyStart)
@@ -150,6 +192,7 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		Prompt:     prompt,
 		Language:   language,
 		History:    history,
+		Memory:     memoryContext,
 		LLM:        u.llm,
 		Config:     u.config,
 		Vector:     u.vector,
@@ -169,7 +212,7 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		response := u.getGuardResponse(guardResult, language)
 		isBlocked := guardResult == orchestrator.GuardPureSpam || guardResult == orchestrator.GuardIrrelevant
 
-		u.saveHistoryIfNotBlocked(u.badger, historyKey, history, prompt, response, isBlocked)
+		u.rememberIfNotBlocked(skillCtx, prompt, response, isBlocked)
 		totalDuration := time.Since(ucStart)
 
 		utils.LogInfo("ChatUseCase: Guard blocked request | pipeline_path=%s | guard_duration_ms=%d | total_duration_ms=%d", pipelinePath, guardDuration.Milliseconds(), totalDuration.Milliseconds())
@@ -194,7 +237,7 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		case orchestrator.FastIntentIdentity:
 			pipelinePath = "fast_identity"
 			response := u.getIdentityResponse(language)
-			u.saveHistoryIfNotBlocked(u.badger, historyKey, history, prompt, response, false)
+			u.rememberIfNotBlocked(skillCtx, prompt, response, false)
 			totalDuration := time.Since(ucStart)
 			utils.LogInfo("ChatUseCase: Fast identity route | pipeline_path=%s | fast_intent_duration_ms=%d | total_duration_ms=%d", pipelinePath, fastIntentDuration.Milliseconds(), totalDuration.Milliseconds())
 			resp := &dtos.RAGChatResponseDTO{
@@ -209,7 +252,7 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		case orchestrator.FastIntentDiscovery:
 			pipelinePath = "fast_discovery"
 			response := u.getDiscoveryResponse(skillCtx, language)
-			u.saveHistoryIfNotBlocked(u.badger, historyKey, history, prompt, response, false)
+			u.rememberIfNotBlocked(skillCtx, prompt, response, false)
 			totalDuration := time.Since(ucStart)
 			utils.LogInfo("ChatUseCase: Fast discovery route | pipeline_path=%s | fast_intent_duration_ms=%d | total_duration_ms=%d", pipelinePath, fastIntentDuration.Milliseconds(), totalDuration.Milliseconds())
 			resp := &dtos.RAGChatResponseDTO{
@@ -221,6 +264,24 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 			u.finalizeIdempotency(requestID, terminalID, resp)
 			return resp, nil
 
+		case orchestrator.FastIntentUndo:
+			pipelinePath = "fast_undo"
+			resp := u.executeUndo(terminalID, language)
+			u.rememberIfNotBlocked(skillCtx, prompt, resp.Response, false)
+			totalDuration := time.Since(ucStart)
+			utils.LogInfo("ChatUseCase: Fast undo route | pipeline_path=%s | total_duration_ms=%d", pipelinePath, totalDuration.Milliseconds())
+			u.finalizeIdempotency(requestID, terminalID, resp)
+			return resp, nil
+
+		case orchestrator.FastIntentRoom:
+			pipelinePath = "fast_room_control"
+			resp := u.executeRoomControl(terminalID, fastIntentResult, language)
+			u.rememberIfNotBlocked(skillCtx, prompt, resp.Response, false)
+			totalDuration := time.Since(ucStart)
+			utils.LogInfo("ChatUseCase: Fast room control route | pipeline_path=%s | room=%q | total_duration_ms=%d", pipelinePath, fastIntentResult.RoomName, totalDuration.Milliseconds())
+			u.finalizeIdempotency(requestID, terminalID, resp)
+			return resp, nil
+
 		case orchestrator.FastIntentControl:
 			pipelinePath = "fast_control"
 			// Execute control directly
@@ -230,7 +291,7 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 				utils.LogWarn("ChatUseCase: Fast control execution failed: %v, falling back to decision engine", err)
 				// Fall through to decision engine
 			} else {
-				u.saveHistoryIfNotBlocked(u.badger, historyKey, history, prompt, controlResult.Message, false)
+				u.rememberIfNotBlocked(skillCtx, prompt, controlResult.Message, false)
 				totalDuration := time.Since(ucStart)
 				utils.LogInfo("ChatUseCase: Fast control executed | pipeline_path=%s | control_duration_ms=%d | total_duration_ms=%d", pipelinePath, controlDuration.Milliseconds(), totalDuration.Milliseconds())
 				resp := &dtos.RAGChatResponseDTO{
@@ -252,7 +313,7 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 
 	utils.LogDebug("ChatUseCase: DecisionEngine.Decide starting | llm_provider=%T", u.llm)
 	u.decisionEngine.SetLLM(u.llm)
-	decision, err := u.executeDecisionWithFallback(skillCtx, &totalDecisionDuration)
+	decision, err := u.executeDecisionWithFallback(skillCtx, &totalDecisionDuration, onDelta)
 	totalDecisionDuration += time.Since(decisionStart)
 
 	utils.LogDebug("ChatUseCase: DecisionEngine.Decide completed | duration_ms=%d | err=%v", totalDecisionDuration.Milliseconds(), err)
@@ -261,7 +322,7 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		// Still failed, use service issue fallback
 		pipelinePath = "service_issue"
 		response := u.getServiceIssueResponse(language)
-		u.saveHistoryIfNotBlocked(u.badger, historyKey, history, prompt, response, false)
+		u.rememberIfNotBlocked(skillCtx, prompt, response, false)
 		totalDuration := time.Since(ucStart)
 		utils.LogError("ChatUseCase: Decision engine failed completely | pipeline_path=%s | total_duration_ms=%d", pipelinePath, totalDuration.Milliseconds())
 		resp := &dtos.RAGChatResponseDTO{
@@ -296,7 +357,13 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		pipelinePath = "single_decision_control"
 		// Execute control based on decision hints
 		controlStart := time.Now()
-		controlResult, err := u.executeDecisionControl(skillCtx, decision)
+		var controlResult *skills.SkillResult
+		if len(decision.Actions) > 1 {
+			pipelinePath = "single_decision_control_plan"
+			controlResult, err = u.executeDecisionPlan(skillCtx, decision)
+		} else {
+			controlResult, err = u.executeDecisionControl(skillCtx, decision)
+		}
 		controlDuration := time.Since(controlStart)
 
 		if err != nil {
@@ -320,32 +387,18 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		}
 	}
 
-	// 5. Save History (skip if blocked)
-	historySaveStart := time.Now()
-	if u.badger != nil && !result.IsBlocked {
-		history = append(history, "User: "+prompt, "Assistant: "+result.Message)
-		if len(history) > 20 {
-			history = history[len(history)-20:]
-		}
-		marshalStart := time.Now()
-		data, _ := json.Marshal(history)
-		marshalDuration := time.Since(marshalStart)
-
-		setStart := time.Now()
-		_ = u.badger.Set(historyKey, data)
-		setDuration := time.Since(setStart)
-
-		utils.LogDebug("ChatUseCase: History saved | key=%s | marshal_duration_ms=%d | set_duration_ms=%d | history_size=%d", historyKey, marshalDuration.Milliseconds(), setDuration.Milliseconds(), len(history))
-	}
-	historySaveDuration := time.Since(historySaveStart)
+	// 5. Remember the exchange (skip if blocked)
+	historySaveDuration := u.rememberIfNotBlocked(skillCtx, prompt, result.Message, result.IsBlocked)
 
 	totalDuration := time.Since(ucStart)
 	utils.LogInfo("ChatUseCase: Chat completed | pipeline_path=%s | history_duration_ms=%d | guard_duration_ms=%d | fast_intent_duration_ms=%d | decision_duration_ms=%d | control_duration_ms=%d | history_save_duration_ms=%d | total_duration_ms=%d",
 		pipelinePath, historyDuration.Milliseconds(), guardDuration.Milliseconds(), fastIntentDuration.Milliseconds(), decisionDuration.Milliseconds(), controlDuration.Milliseconds(), historySaveDuration.Milliseconds(), totalDuration.Milliseconds())
 
 	// Handle Redirect for Control
+	// A plan was executed step by step; replaying the raw prompt on /control would run only one step
+	actions, isPlan := result.Data.([]dtos.ControlActionResultDTO)
 	var redirect *dtos.RedirectDTO
-	if result.IsControl && decision != nil && decision.Intent == "control" {
+	if result.IsControl && decision != nil && decision.Intent == "control" && !isPlan {
 		redirect = &dtos.RedirectDTO{
 			Endpoint: "/api/rag/control",
 			Method:   "POST",
@@ -362,6 +415,7 @@ func (u *ChatUseCaseImpl) Chat(ctx context.Context, uid, terminalID, prompt, lan
 		IsControl:      result.IsControl,
 		IsBlocked:      result.IsBlocked,
 		Redirect:       redirect,
+		Actions:        actions,
 		HTTPStatusCode: result.HTTPStatusCode,
 	}
 	u.finalizeIdempotency(requestID, terminalID, resp)
@@ -513,18 +567,16 @@ func (u *ChatUseCaseImpl) getServiceIssueResponse(language string) string {
 	return "Maaf, layanan AI sedang gangguan. Silakan coba lagi sebentar."
 }
 
-// saveHistoryIfNotBlocked saves history if not blocked.
-func (u *ChatUseCaseImpl) saveHistoryIfNotBlocked(badger *infrastructure.BadgerService, historyKey string, history []string, prompt, response string, isBlocked bool) time.Duration {
-	if badger == nil || isBlocked {
+// rememberIfNotBlocked records the exchange in conversational memory unless it was blocked.
+// The LLM that answered also summarises older turns in the background.
+func (u *ChatUseCaseImpl) rememberIfNotBlocked(ctx *skills.SkillContext, prompt, response string, isBlocked bool) time.Duration {
+	if u.memory == nil || isBlocked {
 		return 0
 	}
 	historyStart := time.Now()
-	history = append(history, "User: "+prompt, "Assistant: "+response)
-	if len(history) > 20 {
-		history = history[len(history)-20:]
+	if err := u.memory.Remember(ctx.Ctx, ctx.LLM, ctx.TerminalID, ctx.UID, prompt, response); err != nil {
+		utils.LogWarn("ChatUseCase: Failed to remember exchange | terminal=%s | error=%v", ctx.TerminalID, err)
 	}
-	data, _ := json.Marshal(history)
-	_ = badger.Set(historyKey, data)
 	return time.Since(historyStart)
 }
 
@@ -614,6 +666,37 @@ func (u *ChatUseCaseImpl) executeDecisionControl(ctx *skills.SkillContext, decis
 	}, nil
 }
 
+// executeDecisionPlan executes every step of a multi-device decision and answers with one
+// combined confirmation; the per-step results are returned as the result data.
+func (u *ChatUseCaseImpl) executeDecisionPlan(ctx *skills.SkillContext, decision *orchestrator.AssistantDecision) (*skills.SkillResult, error) {
+	if u.controlUseCase == nil {
+		utils.LogWarn("executeDecisionPlan: Control use case not configured")
+		return &skills.SkillResult{
+			Message:        u.getControlUnavailableResponse(languageFromSkillContext(ctx)),
+			IsControl:      true,
+			HTTPStatusCode: 503,
+		}, nil
+	}
+
+	plan, err := u.controlUseCase.ProcessPlan(ctx.Ctx, ctx.UID, ctx.TerminalID, languageFromSkillContext(ctx), decision.Actions)
+	if err != nil {
+		utils.LogWarn("executeDecisionPlan: Plan execution failed: %v", err)
+		status, message := u.mapControlRuntimeError(err, languageFromSkillContext(ctx))
+		return &skills.SkillResult{
+			Message:        message,
+			IsControl:      true,
+			HTTPStatusCode: status,
+		}, nil
+	}
+
+	return &skills.SkillResult{
+		Message:        plan.Message,
+		Data:           plan.Actions,
+		IsControl:      true,
+		HTTPStatusCode: plan.HTTPStatusCode,
+	}, nil
+}
+
 // buildControlPromptFromDecision reconstructs a deterministic control prompt from structured decision.
 func (u *ChatUseCaseImpl) buildControlPromptFromDecision(decision *orchestrator.AssistantDecision) (string, error) {
 	// 1. Use explicit control_prompt if present (high confidence override)
@@ -673,6 +756,132 @@ func (u *ChatUseCaseImpl) mapControlRuntimeError(err error, language string) (st
 	return 500, "Maaf, terjadi gangguan internal saat memproses perintah kontrol Anda."
 }
 
+// executeUndo reverts the last device change of the terminal and describes the outcome.
+func (u *ChatUseCaseImpl) executeUndo(terminalID, language string) *dtos.RAGChatResponseDTO {
+	isEn := strings.EqualFold(language, "en")
+	resp := &dtos.RAGChatResponseDTO{IsControl: true, HTTPStatusCode: 200}
+
+	if u.undoer == nil {
+		resp.Response = u.getControlUnavailableResponse(language)
+		resp.HTTPStatusCode = 503
+		return resp
+	}
+
+	result, err := u.undoer.UndoLastChange(terminalID, "")
+	switch {
+	case errors.Is(err, snapshotUsecases.ErrNothingToUndo):
+		if isEn {
+			resp.Response = "There is no recent device change to undo."
+		} else {
+			resp.Response = "Tidak ada perubahan perangkat terakhir yang bisa dibatalkan."
+		}
+	case err != nil:
+		utils.LogError("ChatUseCase: Undo failed | terminal_id=%s | error=%v", terminalID, err)
+		resp.HTTPStatusCode = 500
+		if isEn {
+			resp.Response = "Sorry, I couldn't undo the last change."
+		} else {
+			resp.Response = "Maaf, perubahan terakhir gagal dibatalkan."
+		}
+	case result.Reverted == 0:
+		resp.HTTPStatusCode = 500
+		if isEn {
+			resp.Response = "Sorry, the devices did not respond, so the last change was not undone. Please try again."
+		} else {
+			resp.Response = "Maaf, perangkat tidak merespons sehingga perubahan terakhir belum dibatalkan. Silakan coba lagi."
+		}
+	case result.Failed > 0:
+		if isEn {
+			resp.Response = fmt.Sprintf("Undone on %d device(s), but %d device(s) did not respond.", result.Reverted, result.Failed)
+		} else {
+			resp.Response = fmt.Sprintf("Perubahan dibatalkan pada %d perangkat, tetapi %d perangkat tidak merespons.", result.Reverted, result.Failed)
+		}
+	default:
+		if isEn {
+			resp.Response = "Done, I've put it back the way it was."
+		} else {
+			resp.Response = "Baik, perangkat sudah dikembalikan seperti sebelumnya."
+		}
+	}
+	return resp
+}
+
+// executeRoomControl turns every device of a room on or off and reports each device.
+func (u *ChatUseCaseImpl) executeRoomControl(terminalID string, intent orchestrator.FastIntentResult, language string) *dtos.RAGChatResponseDTO {
+	isEn := strings.EqualFold(language, "en")
+	resp := &dtos.RAGChatResponseDTO{IsControl: true, HTTPStatusCode: 200}
+
+	if u.rooms == nil {
+		resp.Response = u.getControlUnavailableResponse(language)
+		resp.HTTPStatusCode = 503
+		return resp
+	}
+
+	result, err := u.rooms.ControlRoomByName(terminalID, intent.RoomName, intent.ActionType, "")
+	switch {
+	case errors.Is(err, roomUsecases.ErrRoomNotFound):
+		resp.HTTPStatusCode = 404
+		if isEn {
+			resp.Response = fmt.Sprintf("I couldn't find a room called \"%s\".", intent.RoomName)
+		} else {
+			resp.Response = fmt.Sprintf("Ruangan \"%s\" tidak ditemukan.", intent.RoomName)
+		}
+		return resp
+	case err != nil:
+		utils.LogError("ChatUseCase: Room control failed | terminal_id=%s | room=%q | error=%v", terminalID, intent.RoomName, err)
+		resp.HTTPStatusCode = 500
+		if isEn {
+			resp.Response = "Sorry, I couldn't control the devices in that room."
+		} else {
+			resp.Response = "Maaf, perangkat di ruangan tersebut gagal dikontrol."
+		}
+		return resp
+	}
+
+	resp.Actions = make([]dtos.ControlActionResultDTO, 0, len(result.Devices))
+	for _, d := range result.Devices {
+		status := orchestrator.ActionStatusSuccess
+		if d.Status != roomUsecases.DeviceStatusSucceeded {
+			status = d.Status
+		}
+		resp.Actions = append(resp.Actions, dtos.ControlActionResultDTO{
+			Device:     d.Name,
+			DeviceID:   d.DeviceID,
+			DeviceName: d.Name,
+			Operation:  result.Action,
+			Status:     status,
+			Message:    d.Error,
+		})
+	}
+	resp.Response = roomControlMessage(result, isEn)
+	if result.Succeeded == 0 && result.Failed > 0 {
+		resp.HTTPStatusCode = 500
+	}
+	return resp
+}
+
+// roomControlMessage summarizes a room command in one sentence.
+func roomControlMessage(result *roomDtos.RoomControlResultDTO, isEn bool) string {
+	state := "dimatikan"
+	if result.Action == "on" {
+		state = "dinyalakan"
+	}
+	switch {
+	case result.Succeeded == 0 && result.Failed == 0 && isEn:
+		return fmt.Sprintf("There are no devices in %s I can turn %s.", result.RoomName, result.Action)
+	case result.Succeeded == 0 && result.Failed == 0:
+		return fmt.Sprintf("Tidak ada perangkat di %s yang bisa %s.", result.RoomName, state)
+	case result.Failed == 0 && isEn:
+		return fmt.Sprintf("Done, I turned %s %d device(s) in %s.", result.Action, result.Succeeded, result.RoomName)
+	case result.Failed == 0:
+		return fmt.Sprintf("Baik, %d perangkat di %s sudah %s.", result.Succeeded, result.RoomName, state)
+	case isEn:
+		return fmt.Sprintf("Turned %s %d device(s) in %s, but %d device(s) did not respond.", result.Action, result.Succeeded, result.RoomName, result.Failed)
+	default:
+		return fmt.Sprintf("%d perangkat di %s sudah %s, tetapi %d perangkat tidak merespons.", result.Succeeded, result.RoomName, state, result.Failed)
+	}
+}
+
 // getControlUnavailableResponse returns a generic unavailable message.
 func (u *ChatUseCaseImpl) getControlUnavailableResponse(language string) string {
 	if strings.EqualFold(language, "en") {
@@ -688,11 +897,24 @@ func languageFromSkillContext(ctx *skills.SkillContext) string {
 	return ctx.Language
 }
 
-// executeDecisionWithFallback executes the decision engine with health-aware remote provider fallback
-func (u *ChatUseCaseImpl) executeDecisionWithFallback(skillCtx *skills.SkillContext, totalDuration *time.Duration) (*orchestrator.AssistantDecision, error) {
+// executeDecisionWithFallback executes the decision engine with health-aware remote provider fallback.
+// With onDelta set the reply is streamed; once text was emitted, fallback attempts no longer stream
+// so the client never sees two answers interleaved.
+func (u *ChatUseCaseImpl) executeDecisionWithFallback(skillCtx *skills.SkillContext, totalDuration *time.Duration, onDelta func(delta string)) (*orchestrator.AssistantDecision, error) {
 	var finalDecision *orchestrator.AssistantDecision
 	var err error
 
+	emitted := false
+	decide := func() (*orchestrator.AssistantDecision, error) {
+		if onDelta == nil || emitted {
+			return u.decisionEngine.Decide(skillCtx)
+		}
+		return u.decisionEngine.DecideStream(skillCtx, func(delta string) {
+			emitted = true
+			onDelta(delta)
+		})
+	}
+
 	if skillCtx.TerminalID != "" {
 		// Use terminal-specific provider preference
 		err = u.providerResolver.ExecuteWithFallbackByTerminal(skillCtx.TerminalID, func(resolvedSet *providers.ResolvedProviderSet) error {
@@ -700,7 +922,7 @@ func (u *ChatUseCaseImpl) executeDecisionWithFallback(skillCtx *skills.SkillCont
 			u.decisionEngine.SetLLM(resolvedSet.LLM)
 
 			decisionStart := time.Now()
-			decision, execErr := u.decisionEngine.Decide(skillCtx)
+			decision, execErr := decide()
 			*totalDuration += time.Since(decisionStart)
 
 			if execErr == nil {
@@ -715,7 +937,7 @@ func (u *ChatUseCaseImpl) executeDecisionWithFallback(skillCtx *skills.SkillCont
 			u.decisionEngine.SetLLM(resolvedSet.LLM)
 
 			decisionStart := time.Now()
-			decision, execErr := u.decisionEngine.Decide(skillCtx)
+			decision, execErr := decide()
 			*totalDuration += time.Since(decisionStart)
 
 			if execErr == nil {
@@ -16,6 +16,7 @@ import (
 // MockControlUseCase is a mock implementation of ControlUseCase for testing
 type MockControlUseCase struct {
 	ProcessControlFunc func(ctx context.Context, uid, terminalID, prompt string) (*dtos.ControlResultDTO, error)
+	ProcessPlanFunc    func(ctx context.Context, uid, terminalID, language string, actions []orchestrator.DeviceAction) (*dtos.ControlPlanResultDTO, error)
 }
 
 func (m *MockControlUseCase) ProcessControl(ctx context.Context, uid, terminalID, prompt string) (*dtos.ControlResultDTO, error) {
@@ -25,6 +26,13 @@ func (m *MockControlUseCase) ProcessControl(ctx context.Context, uid, terminalID
 	return &dtos.ControlResultDTO{Message: "OK", HTTPStatusCode: 200}, nil
 }
 
+func (m *MockControlUseCase) ProcessPlan(ctx context.Context, uid, terminalID, language string, actions []orchestrator.DeviceAction) (*dtos.ControlPlanResultDTO, error) {
+	if m.ProcessPlanFunc != nil {
+		return m.ProcessPlanFunc(ctx, uid, terminalID, language, actions)
+	}
+	return &dtos.ControlPlanResultDTO{Message: "OK", Succeeded: len(actions), HTTPStatusCode: 200}, nil
+}
+
 // MockLLMClient is a mock LLM client for testing
 type MockLLMClient struct{}
 
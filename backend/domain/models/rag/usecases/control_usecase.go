@@ -2,13 +2,13 @@ package usecases
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/providers"
 	"sensio/domain/common/utils"
 	"sensio/domain/models/rag/dtos"
 	"sensio/domain/models/rag/skills"
+	"sensio/domain/models/rag/skills/orchestrator"
 	tuyaUsecases "sensio/domain/tuya/usecases"
 	"strings"
 	"time"
@@ -16,21 +16,25 @@ import (
 
 type ControlUseCase interface {
 	ProcessControl(ctx context.Context, uid, terminalID, prompt string) (*dtos.ControlResultDTO, error)
+	// ProcessPlan executes a multi-device plan step by step; no LLM is involved.
+	ProcessPlan(ctx context.Context, uid, terminalID, language string, actions []orchestrator.DeviceAction) (*dtos.ControlPlanResultDTO, error)
 }
 
 type controlUseCase struct {
 	llm              skills.LLMClient
 	fallbackLLM      skills.LLMClient
 	config           *utils.Config
-	vector           *infrastructure.VectorService
+	vector           infrastructure.VectorStore
 	badger           *infrastructure.BadgerService
 	tuyaExecutor     tuyaUsecases.TuyaDeviceControlExecutor
 	tuyaAuth         tuyaUsecases.TuyaAuthUseCase
 	skill            skills.Skill
 	providerResolver providers.ProviderResolver
+	memory           ConversationMemory
+	planner          *orchestrator.ControlOrchestrator
 }
 
-func NewControlUseCase(llm skills.LLMClient, fallbackLLM skills.LLMClient, cfg *utils.Config, vector *infrastructure.VectorService, badger *infrastructure.BadgerService, tuyaExecutor tuyaUsecases.TuyaDeviceControlExecutor, tuyaAuth tuyaUsecases.TuyaAuthUseCase, skill skills.Skill, providerResolver providers.ProviderResolver) ControlUseCase {
+func NewControlUseCase(llm skills.LLMClient, fallbackLLM skills.LLMClient, cfg *utils.Config, vector infrastructure.VectorStore, badger *infrastructure.BadgerService, tuyaExecutor tuyaUsecases.TuyaDeviceControlExecutor, tuyaAuth tuyaUsecases.TuyaAuthUseCase, skill skills.Skill, providerResolver providers.ProviderResolver, memory ConversationMemory) ControlUseCase {
 	return &controlUseCase{
 		llm:              llm,
 		fallbackLLM:      fallbackLLM,
@@ -41,6 +45,8 @@ func NewControlUseCase(llm skills.LLMClient, fallbackLLM skills.LLMClient, cfg *
 		tuyaAuth:         tuyaAuth,
 		skill:            skill,
 		providerResolver: providerResolver,
+		memory:           memory,
+		planner:          orchestrator.NewControlOrchestrator(tuyaExecutor, tuyaAuth),
 	}
 }
 
@@ -73,21 +79,18 @@ func (u *controlUseCase) ProcessControl(ctx context.Context, uid, terminalID, pr
 		Badger:     u.badger,
 	}
 
-	// Preload history for the skill
+	// Preload conversational memory for the skill
 	historyStart := time.Now()
-	historyKey := fmt.Sprintf("chat_history:%s", terminalID)
-	var history []string
-	if u.badger != nil {
-		data, _ := u.badger.Get(historyKey)
-		if data != nil {
-			_ = json.Unmarshal(data, &history)
-			utils.LogDebug("ControlUseCase: History loaded | key=%s | duration_ms=%d | size=%d", historyKey, time.Since(historyStart).Milliseconds(), len(history))
+	if u.memory != nil {
+		recall, err := u.memory.Recall(terminalID, uid)
+		if err != nil {
+			utils.LogWarn("ControlUseCase: Memory recall failed | terminal=%s | error=%v", terminalID, err)
 		} else {
-			utils.LogDebug("ControlUseCase: History not found | key=%s | duration_ms=%d", historyKey, time.Since(historyStart).Milliseconds())
+			skillCtx.History, skillCtx.Memory = recall.History, recall.Context
+			utils.LogDebug("ControlUseCase: Memory loaded | terminal=%s | duration_ms=%d | size=%d", terminalID, time.Since(historyStart).Milliseconds(), len(recall.History))
 		}
 	}
 	historyDuration := time.Since(historyStart)
-	skillCtx.History = history
 
 	// Execute skill (LLM call happens here)
 	skillStart := time.Now()
@@ -121,6 +124,37 @@ func (u *controlUseCase) ProcessControl(ctx context.Context, uid, terminalID, pr
 
 }
 
+func (u *controlUseCase) ProcessPlan(ctx context.Context, uid, terminalID, language string, actions []orchestrator.DeviceAction) (*dtos.ControlPlanResultDTO, error) {
+	ucStart := time.Now()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("control plan is empty")
+	}
+
+	skillCtx := &skills.SkillContext{
+		Ctx:        ctx,
+		UID:        uid,
+		TerminalID: terminalID,
+		Language:   language,
+		Config:     u.config,
+		Vector:     u.vector,
+		Badger:     u.badger,
+	}
+
+	res, err := u.planner.ExecutePlan(skillCtx, actions)
+	if err != nil {
+		utils.LogError("ControlUseCase: ProcessPlan failed | terminalID=%s | error=%v", terminalID, err)
+		return nil, err
+	}
+
+	utils.LogInfo("ControlUseCase: ProcessPlan completed | terminalID=%s | actions=%d | succeeded=%d | failed=%d | total_duration_ms=%d",
+		terminalID, len(actions), res.Succeeded, res.Failed, time.Since(ucStart).Milliseconds())
+	return res, nil
+}
+
 // executeSkillWithFallback executes the skill with health-aware remote provider fallback
 func (u *controlUseCase) executeSkillWithFallback(ctx context.Context, skillCtx *skills.SkillContext) (*skills.SkillResult, error) {
 	var result *skills.SkillResult
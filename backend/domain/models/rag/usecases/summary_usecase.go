@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sensio/domain/common/providers"
 	commonServices "sensio/domain/common/services"
 	"sensio/domain/common/tasks"
@@ -46,6 +44,9 @@ type HierarchicalSummaryResult struct {
 	Risks             []dtos.Risk               `json:"risks,omitempty"`
 }
 
+// SummaryJobType is the job type of asynchronous summaries
+const SummaryJobType = "summary"
+
 type SummaryUseCase interface {
 	SummarizeText(text string, language string, meetingContext string, style string, date string, location string, participants string, args ...string) (string, error)
 	SummarizeTextWithTrigger(text string, language string, meetingContext string, style string, date string, location string, participants string, trigger string, args ...string) (string, error)
@@ -89,6 +90,18 @@ type summaryUseCase struct {
 	structuredExtractionSkill skills.Skill // For hierarchical map phase JSON extraction
 	providerResolver          providers.ProviderResolver
 	normalizer                *services.SummaryNormalizer // Phase 2: normalize raw LLM output to canonical
+	jobs                      *tasks.JobQueue
+}
+
+// summaryJob is the payload of a SummaryJobType job
+type summaryJob struct {
+	Text           string `json:"text"`
+	Language       string `json:"language"`
+	MeetingContext string `json:"meeting_context,omitempty"`
+	Style          string `json:"style,omitempty"`
+	Date           string `json:"date,omitempty"`
+	Location       string `json:"location,omitempty"`
+	Participants   string `json:"participants,omitempty"`
 }
 
 func NewSummaryUseCase(
@@ -104,8 +117,9 @@ func NewSummaryUseCase(
 	chunkSkill skills.Skill,
 	structuredExtractionSkill skills.Skill,
 	providerResolver providers.ProviderResolver,
+	jobs *tasks.JobQueue,
 ) SummaryUseCase {
-	return &summaryUseCase{
+	u := &summaryUseCase{
 		llm:                       llm,
 		fallbackLLM:               fallbackLLM,
 		config:                    cfg,
@@ -121,7 +135,10 @@ func NewSummaryUseCase(
 		structuredExtractionSkill: structuredExtractionSkill,
 		providerResolver:          providerResolver,
 		normalizer:                services.NewSummaryNormalizer(),
+		jobs:                      jobs,
 	}
+	jobs.Register(SummaryJobType, u.runJob, tasks.JobTypeOptions{OnCancel: u.markCancelled})
+	return u
 }
 
 func (u *summaryUseCase) summaryInternal(ctx context.Context, text string, language string, meetingContext string, style string, date string, location string, participants string, macAddress string) (*dtos.RAGSummaryResponseDTO, error) {
@@ -193,7 +210,7 @@ func (u *summaryUseCase) summaryInternal(ctx context.Context, text string, langu
 
 		if macAddress != "" {
 			// ExplicitMode: resolve by MAC address (may be explicit or default for this terminal)
-			err = u.providerResolver.ExecuteWithFallbackByMac(macAddress, func(rs *providers.ResolvedProviderSet) error {
+			err = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallbackByMac(macAddress, func(rs *providers.ResolvedProviderSet) error {
 				resolvedSet = rs
 				skillCtx := &skills.SkillContext{
 					Ctx:          ctx,
@@ -215,7 +232,7 @@ func (u *summaryUseCase) summaryInternal(ctx context.Context, text string, langu
 			})
 		} else {
 			// DefaultMode: standard health-aware fallback
-			err = u.providerResolver.ExecuteWithFallback(func(rs *providers.ResolvedProviderSet) error {
+			err = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallback(func(rs *providers.ResolvedProviderSet) error {
 				resolvedSet = rs
 				skillCtx := &skills.SkillContext{
 					Ctx:          ctx,
@@ -363,14 +380,7 @@ func (u *summaryUseCase) summaryInternal(ctx context.Context, text string, langu
 	}
 
 	// PDF Generation
-	uuidStr, _ := uuid.NewV7()
-	pdfFilename := fmt.Sprintf("summary_%s.pdf", uuidStr.String())
-	basePath := "."
-	if envPath := utils.FindEnvFile(); envPath != "" {
-		basePath = filepath.Dir(envPath)
-	}
-	pdfPath := filepath.Join(basePath, "uploads", "reports", pdfFilename)
-	_ = os.MkdirAll(filepath.Dir(pdfPath), 0755)
+	pdfPath, pdfFileURL := services.NewSummaryPDFPath()
 
 	pdfUrl := ""
 	if u.renderer != nil {
@@ -391,7 +401,7 @@ func (u *summaryUseCase) summaryInternal(ctx context.Context, text string, langu
 			fmt.Printf("[WARNING] PDF generation skipped: %v\n", err)
 			pdfUrl = "" // No PDF available
 		} else {
-			pdfUrl = fmt.Sprintf("/uploads/reports/%s", pdfFilename)
+			pdfUrl = pdfFileURL
 		}
 	}
 
@@ -492,7 +502,7 @@ func (u *summaryUseCase) summarizeInChunks(ctx context.Context, text string, lan
 
 		if macAddress != "" {
 			// Use terminal-specific provider preference
-			err = u.providerResolver.ExecuteWithFallbackByMac(macAddress, func(resolvedSet *providers.ResolvedProviderSet) error {
+			err = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallbackByMac(macAddress, func(resolvedSet *providers.ResolvedProviderSet) error {
 				sCtx := &skills.SkillContext{
 					Ctx:      ctx,
 					Prompt:   chunk,
@@ -509,7 +519,7 @@ func (u *summaryUseCase) summarizeInChunks(ctx context.Context, text string, lan
 			})
 		} else {
 			// Use standard health-aware fallback
-			err = u.providerResolver.ExecuteWithFallback(func(resolvedSet *providers.ResolvedProviderSet) error {
+			err = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallback(func(resolvedSet *providers.ResolvedProviderSet) error {
 				sCtx := &skills.SkillContext{
 					Ctx:      ctx,
 					Prompt:   chunk,
@@ -581,7 +591,7 @@ func (u *summaryUseCase) summarizeHierarchical(ctx context.Context, text string,
 
 		var err error
 		if macAddress != "" {
-			err = u.providerResolver.ExecuteWithFallbackByMac(macAddress, func(resolvedSet *providers.ResolvedProviderSet) error {
+			err = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallbackByMac(macAddress, func(resolvedSet *providers.ResolvedProviderSet) error {
 				sCtx := &skills.SkillContext{
 					Ctx:      ctx,
 					Prompt:   extractPrompt,
@@ -599,7 +609,7 @@ func (u *summaryUseCase) summarizeHierarchical(ctx context.Context, text string,
 				return execErr
 			})
 		} else {
-			err = u.providerResolver.ExecuteWithFallback(func(resolvedSet *providers.ResolvedProviderSet) error {
+			err = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallback(func(resolvedSet *providers.ResolvedProviderSet) error {
 				sCtx := &skills.SkillContext{
 					Ctx:      ctx,
 					Prompt:   extractPrompt,
@@ -667,7 +677,7 @@ func (u *summaryUseCase) summarizeHierarchical(ctx context.Context, text string,
 	var reduceErr error
 
 	if macAddress != "" {
-		reduceErr = u.providerResolver.ExecuteWithFallbackByMac(macAddress, func(resolvedSet *providers.ResolvedProviderSet) error {
+		reduceErr = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallbackByMac(macAddress, func(resolvedSet *providers.ResolvedProviderSet) error {
 			sCtx := &skills.SkillContext{
 				Ctx:          ctx,
 				Prompt:       reducePrompt,
@@ -687,7 +697,7 @@ func (u *summaryUseCase) summarizeHierarchical(ctx context.Context, text string,
 			return execErr
 		})
 	} else {
-		reduceErr = u.providerResolver.ExecuteWithFallback(func(resolvedSet *providers.ResolvedProviderSet) error {
+		reduceErr = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallback(func(resolvedSet *providers.ResolvedProviderSet) error {
 			sCtx := &skills.SkillContext{
 				Ctx:          ctx,
 				Prompt:       reducePrompt,
@@ -1124,24 +1134,46 @@ func (u *summaryUseCase) summarizeWithTrigger(ctx context.Context, text string,
 	}
 
 	u.store.Set(taskID, status)
+	_ = u.cache.Set(taskID, status)
 	if idempHash != "" {
 		_ = u.cache.Set(idempHash, taskID)
 	}
 
-	// Use a background context as parent if none provided
-	if ctx == nil {
-		ctx = context.Background()
+	payload := summaryJob{
+		Text:           text,
+		Language:       language,
+		MeetingContext: meetingContext,
+		Style:          style,
+		Date:           date,
+		Location:       location,
+		Participants:   participants,
+	}
+	if _, err := u.jobs.Enqueue(SummaryJobType, taskID, payload); err != nil {
+		status.Status = "failed"
+		status.Error = err.Error()
+		u.saveStatus(taskID, status)
+		return "", err
 	}
-
-	go u.runSummaryAsync(ctx, taskID, text, language, meetingContext, style, date, location, participants)
 
 	return taskID, nil
 }
 
-func (u *summaryUseCase) runSummaryAsync(ctx context.Context, taskID string, text string, language string, meetingContext string, style string, date string, location string, participants string) {
-	status, _ := u.store.Get(taskID)
+// runJob summarizes the text of a queued job
+func (u *summaryUseCase) runJob(ctx context.Context, job *tasks.Job) error {
+	var payload summaryJob
+	if err := job.Decode(&payload); err != nil {
+		return tasks.Permanent(err)
+	}
+	return u.runSummaryAsync(ctx, job, payload)
+}
+
+// runSummaryAsync runs one attempt of a summary task. The task stays "processing" while a retry
+// is left, and is marked failed after the last attempt.
+func (u *summaryUseCase) runSummaryAsync(ctx context.Context, job *tasks.Job, p summaryJob) error {
+	taskID := job.ID
+	status := u.loadStatus(taskID)
 	if status == nil {
-		return
+		return tasks.Permanent(fmt.Errorf("summary task %s not found", taskID))
 	}
 
 	// Check for early cancellation
@@ -1149,17 +1181,20 @@ func (u *summaryUseCase) runSummaryAsync(ctx context.Context, taskID string, tex
 	case <-ctx.Done():
 		status.Status = "failed"
 		status.Error = "task cancelled: " + ctx.Err().Error()
-		u.store.Set(taskID, status)
-		return
+		u.saveStatus(taskID, status)
+		return ctx.Err()
 	default:
 	}
 
 	status.Status = "processing"
 	u.store.Set(taskID, status)
 
-	res, err := u.summaryInternal(ctx, text, language, meetingContext, style, date, location, participants, status.MacAddress)
+	res, err := u.summaryInternal(ctx, p.Text, p.Language, p.MeetingContext, p.Style, p.Date, p.Location, p.Participants, status.MacAddress)
 	if err != nil {
-		utils.LogError("RAG Summary Task %s failed: %v", taskID, err)
+		utils.LogError("RAG Summary Task %s failed (attempt %d/%d): %v", taskID, job.Attempts, job.MaxAttempts, err)
+		if !job.LastAttempt() {
+			return err
+		}
 		status.Status = "failed"
 		status.Error = err.Error()
 	} else {
@@ -1190,6 +1225,35 @@ func (u *summaryUseCase) runSummaryAsync(ctx context.Context, taskID string, tex
 		}
 	}
 
+	u.saveStatus(taskID, status)
+	return err
+}
+
+// markCancelled records a cancelled summary job in the task status
+func (u *summaryUseCase) markCancelled(job *tasks.Job) {
+	status := u.loadStatus(job.ID)
+	if status == nil || status.Status == "completed" {
+		return
+	}
+	status.Status = "cancelled"
+	status.Error = "task cancelled by user"
+	u.saveStatus(job.ID, status)
+}
+
+// loadStatus reads a task status from memory, or from Badger after a restart
+func (u *summaryUseCase) loadStatus(taskID string) *dtos.RAGStatusDTO {
+	if status, ok := u.store.Get(taskID); ok && status != nil {
+		return status
+	}
+	var cached dtos.RAGStatusDTO
+	if _, found, _ := u.cache.GetWithTTL(taskID, &cached); found {
+		u.store.Set(taskID, &cached)
+		return &cached
+	}
+	return nil
+}
+
+func (u *summaryUseCase) saveStatus(taskID string, status *dtos.RAGStatusDTO) {
 	u.store.Set(taskID, status)
 	_ = u.cache.SetPreserveTTL(taskID, status)
 }
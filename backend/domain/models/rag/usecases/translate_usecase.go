@@ -14,6 +14,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// TranslateJobType is the job type of asynchronous translations
+const TranslateJobType = "translation"
+
 type TranslateUseCase interface {
 	TranslateText(text, targetLang string, args ...string) (string, error)
 	TranslateTextWithTrigger(text, targetLang string, trigger string, args ...string) (string, error)
@@ -29,10 +32,17 @@ type translateUseCase struct {
 	mqttSvc          mqttPublisher
 	skill            skills.Skill
 	providerResolver providers.ProviderResolver
+	jobs             *tasks.JobQueue
+}
+
+// translateJob is the payload of a TranslateJobType job
+type translateJob struct {
+	Text           string `json:"text"`
+	TargetLanguage string `json:"target_language"`
 }
 
-func NewTranslateUseCase(llm skills.LLMClient, fallbackLLM skills.LLMClient, cfg *utils.Config, cache *tasks.BadgerTaskCache, store *tasks.StatusStore[dtos.RAGStatusDTO], mqttSvc mqttPublisher, skill skills.Skill, providerResolver providers.ProviderResolver) TranslateUseCase {
-	return &translateUseCase{
+func NewTranslateUseCase(llm skills.LLMClient, fallbackLLM skills.LLMClient, cfg *utils.Config, cache *tasks.BadgerTaskCache, store *tasks.StatusStore[dtos.RAGStatusDTO], mqttSvc mqttPublisher, skill skills.Skill, providerResolver providers.ProviderResolver, jobs *tasks.JobQueue) TranslateUseCase {
+	u := &translateUseCase{
 		llm:              llm,
 		fallbackLLM:      fallbackLLM,
 		config:           cfg,
@@ -41,7 +51,12 @@ func NewTranslateUseCase(llm skills.LLMClient, fallbackLLM skills.LLMClient, cfg
 		mqttSvc:          mqttSvc,
 		skill:            skill,
 		providerResolver: providerResolver,
+		jobs:             jobs,
 	}
+	jobs.Register(TranslateJobType, u.runJob, tasks.JobTypeOptions{
+		OnCancel: func(job *tasks.Job) { u.updateStatus(job.ID, "cancelled", nil, "") },
+	})
+	return u
 }
 
 // translateInternal (private internal for use by Execute)
@@ -58,7 +73,7 @@ func (u *translateUseCase) translateInternal(ctx context.Context, text, targetLa
 	if len(args) > 0 && args[0] != "" {
 		// Use terminal-specific provider preference
 		macAddress := args[0]
-		err = u.providerResolver.ExecuteWithFallbackByMac(macAddress, func(resolvedSet *providers.ResolvedProviderSet) error {
+		err = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallbackByMac(macAddress, func(resolvedSet *providers.ResolvedProviderSet) error {
 			skillCtx := &skills.SkillContext{
 				Ctx:      ctx,
 				Prompt:   text,
@@ -74,7 +89,7 @@ func (u *translateUseCase) translateInternal(ctx context.Context, text, targetLa
 		})
 	} else {
 		// Use standard health-aware fallback
-		err = u.providerResolver.ExecuteWithFallback(func(resolvedSet *providers.ResolvedProviderSet) error {
+		err = providers.ForContext(ctx, u.providerResolver).ExecuteWithFallback(func(resolvedSet *providers.ResolvedProviderSet) error {
 			skillCtx := &skills.SkillContext{
 				Ctx:      ctx,
 				Prompt:   text,
@@ -164,20 +179,35 @@ func (u *translateUseCase) TranslateTextWithTrigger(text, targetLang string, tri
 		_ = u.cache.Set(idempotencyHash, taskID)
 	}
 
-	go func() {
-		translated, err := u.translateInternal(context.Background(), text, targetLang)
-		if err != nil {
-			utils.LogError("RAG Translate Task %s: Failed with error: %v", taskID, err)
-			u.updateStatus(taskID, "failed", err, "")
-		} else {
-			utils.LogInfo("RAG Translate Task %s: Completed successfully", taskID)
-			u.updateStatus(taskID, "completed", nil, translated)
-		}
-	}()
+	if _, err := u.jobs.Enqueue(TranslateJobType, taskID, translateJob{Text: text, TargetLanguage: targetLang}); err != nil {
+		u.updateStatus(taskID, "failed", err, "")
+		return "", err
+	}
 
 	return taskID, nil
 }
 
+// runJob translates the text of a queued job; the task is marked failed once no retry is left
+func (u *translateUseCase) runJob(ctx context.Context, job *tasks.Job) error {
+	var payload translateJob
+	if err := job.Decode(&payload); err != nil {
+		u.updateStatus(job.ID, "failed", err, "")
+		return tasks.Permanent(err)
+	}
+
+	translated, err := u.translateInternal(ctx, payload.Text, payload.TargetLanguage)
+	if err != nil {
+		utils.LogError("RAG Translate Task %s: Attempt %d/%d failed with error: %v", job.ID, job.Attempts, job.MaxAttempts, err)
+		if job.LastAttempt() {
+			u.updateStatus(job.ID, "failed", err, "")
+		}
+		return err
+	}
+	utils.LogInfo("RAG Translate Task %s: Completed successfully", job.ID)
+	u.updateStatus(job.ID, "completed", nil, translated)
+	return nil
+}
+
 func (u *translateUseCase) updateStatus(taskID string, statusStr string, err error, result string) {
 	// Try to get existing status to preserve StartedAt and MacAddress
 	var existing dtos.RAGStatusDTO
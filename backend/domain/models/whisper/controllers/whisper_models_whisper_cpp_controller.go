@@ -30,21 +30,22 @@ func NewWhisperModelsWhisperCppController(
 	}
 }
 
-// Transcribe handles POST /api/whisper/models/whisper/cpp
+// Transcribe handles POST /api/models/whisper/cpp
 // @Summary Transcribe audio file (Whisper.cpp)
-// @Description Submit audio file for transcription via Whisper.cpp. Processing is asynchronous.
+// @Description Submit audio file for transcription via the local Whisper.cpp model. Processing is asynchronous. The result carries segment timestamps taken from the audio (aligned_timestamps), and speaker turns when diarize is set.
 // @Tags 04. Models
 // @Security BearerAuth
 // @Accept multipart/form-data
 // @Produce json
 // @Param audio formData file true "Audio file (.mp3, .wav, .m4a, .aac, .ogg, .flac)"
 // @Param language formData string false "Language code (e.g. id, en)"
+// @Param diarize formData boolean false "Label speaker turns by clustering voices"
 // @Success 202 {object} commonDtos.StandardResponse{data=dtos.TranscriptionTaskResponseDTO}
 // @Failure      400  {object}  commonDtos.ValidationErrorResponse
 // @Failure 413 {object} commonDtos.StandardResponse
 // @Failure 415 {object} commonDtos.StandardResponse
 // @Failure      500  {object}  commonDtos.ErrorResponse
-// @Router /api/whisper/models/whisper/cpp [post]
+// @Router /api/models/whisper/cpp [post]
 func (c *WhisperModelsWhisperCppController) Transcribe(ctx *gin.Context) {
 	file, err := ctx.FormFile("audio")
 	if err != nil {
@@ -97,8 +98,10 @@ func (c *WhisperModelsWhisperCppController) Transcribe(ctx *gin.Context) {
 
 	finalPath := filepath.Join("uploads", "audio", recording.Filename)
 	language := ctx.PostForm("language")
+	diarizeStr := ctx.PostForm("diarize")
+	diarize := diarizeStr == "true" || diarizeStr == "1"
 
-	taskID, err := c.usecase.TranscribeAsync(finalPath, file.Filename, language, ctx.Request.URL.Path)
+	taskID, err := c.usecase.TranscribeAsync(finalPath, file.Filename, language, diarize, ctx.Request.URL.Path)
 	if err != nil {
 		utils.LogError("WhisperCpp.TranscribeAsync: %v", err)
 		ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
@@ -48,180 +48,126 @@ func NewWhisperTranscribeController(
 	}
 }
 
-func (c *WhisperTranscribeController) StartMqttSubscription() error {
-	if c.mqttSvc == nil {
+// RegisterMqttRoutes routes users/{mac}/{env}/whisper through the MQTT RPC router; answers go to .../whisper/answer
+func (c *WhisperTranscribeController) RegisterMqttRoutes(router *infrastructure.MqttRpcRouter) error {
+	if router == nil {
 		return nil
 	}
 
-	topic := fmt.Sprintf("users/+/%s/whisper", c.config.ApplicationEnvironment)
-	err := c.mqttSvc.Subscribe(topic, 0, func(client mqtt.Client, msg mqtt.Message) {
-		payload := msg.Payload()
-		correlationID := uuid.New().String()
-		handlerStart := time.Now()
-
-		utils.LogInfo("[%s] WhisperTranscribe MQTT: Received message on %s, payload size: %d", correlationID, msg.Topic(), len(payload))
-
-		if len(payload) == 0 {
-			return
-		}
+	if err := router.Handle("users/{mac}/{env}/whisper", c.handleMqttTranscribe); err != nil {
+		utils.LogError("WhisperTranscribe MQTT: %v", err)
+		return err
+	}
 
-		var req dtos.WhisperMqttRequestDTO
-		parseStart := time.Now()
-		if err := json.Unmarshal(payload, &req); err != nil {
-			parseDuration := time.Since(parseStart)
-			utils.LogError("[%s] WhisperTranscribe MQTT: Failed to unmarshal JSON: %v | parse_duration_ms=%d", correlationID, err, parseDuration.Milliseconds())
-			return
-		}
-		parseDuration := time.Since(parseStart)
-		utils.LogDebug("[%s] WhisperTranscribe MQTT: Payload parsed | parse_duration_ms=%d", correlationID, parseDuration.Milliseconds())
-
-		// Extract MAC from topic: (optionally $share/group/)users/MAC/env/whisper
-		topicParts := strings.Split(msg.Topic(), "/")
-		mac := ""
-		for i, part := range topicParts {
-			if part == "users" && i+1 < len(topicParts) {
-				mac = topicParts[i+1]
-				break
-			}
-		}
+	// Subscribe to general task signaling as well
+	if c.mqttSvc != nil {
+		taskTopic := fmt.Sprintf("users/+/%s/task", c.config.ApplicationEnvironment)
+		_ = c.mqttSvc.Subscribe(taskTopic, 0, func(client mqtt.Client, msg mqtt.Message) {
+			payload := msg.Payload()
+			utils.LogInfo("Task Signaling MQTT: Received message on %s: %s", msg.Topic(), string(payload))
+		})
+	}
+	return nil
+}
 
-		if req.Audio == "" || req.TerminalID == "" {
-			utils.LogError("[%s] WhisperTranscribe MQTT: Missing audio or terminal_id | total_duration_ms=%d", correlationID, time.Since(handlerStart).Milliseconds())
-			if mac != "" {
-				c.publishMqttValidationError(mac, "audio/terminal_id", "audio and terminal_id are required")
-			}
-			return
-		}
+func (c *WhisperTranscribeController) handleMqttTranscribe(rpcReq *infrastructure.MqttRpcRequest) (*infrastructure.MqttRpcResult, error) {
+	handlerStart := time.Now()
+	mac := rpcReq.MAC
+	correlationID := rpcReq.RequestID
 
-		// Use TerminalID for the active transcription flag to match TranscribeAudio behavior.
-		targetID := req.TerminalID
-		if targetID == "" {
-			targetID = mac
-		}
+	var req dtos.WhisperMqttRequestDTO
+	if err := rpcReq.Bind(&req); err != nil {
+		utils.LogError("[%s] WhisperTranscribe MQTT: Failed to unmarshal JSON: %v", correlationID, err)
+		return nil, err
+	}
 
-		// Immediately mark as active to prevent chat handler race condition.
-		// It will be deleted either in the defer below (on failure) or by TranscribeAudio async processor.
-		utils.ActiveTranscriptions.Store(targetID, true)
+	if req.Audio == "" || req.TerminalID == "" {
+		utils.LogError("[%s] WhisperTranscribe MQTT: Missing audio or terminal_id", correlationID)
+		return nil, mqttValidationError("audio/terminal_id", "audio and terminal_id are required")
+	}
 
-		// Create a local error flag to determine if we should clean up the transcription flag.
-		// If we successfully start TranscribeAudio, it takes ownership of deleting the flag.
-		var taskStarted bool
-		defer func() {
-			if !taskStarted {
-				utils.ActiveTranscriptions.Delete(targetID)
-			}
-		}()
-
-		// Decode Base64 audio
-		decodeStart := time.Now()
-		audioBytes, err := base64.StdEncoding.DecodeString(req.Audio)
-		decodeDuration := time.Since(decodeStart)
-		if err != nil {
-			utils.LogError("[%s] WhisperTranscribe MQTT: Failed to decode base64: %v | decode_duration_ms=%d", correlationID, err, decodeDuration.Milliseconds())
-			if mac != "" {
-				c.publishMqttValidationError(mac, "audio", "Failed to decode base64 audio")
-			}
-			return
-		}
-		utils.LogDebug("[%s] WhisperTranscribe MQTT: Base64 decoded | decode_duration_ms=%d", correlationID, decodeDuration.Milliseconds())
+	// Use TerminalID for the active transcription flag to match TranscribeAudio behavior.
+	targetID := req.TerminalID
 
-		language := req.Language
-		if language == "" {
-			language = "id"
-		}
+	// Immediately mark as active to prevent chat handler race condition.
+	// It will be deleted either in the defer below (on failure) or by TranscribeAudio async processor.
+	utils.ActiveTranscriptions.Store(targetID, true)
 
-		// Generate a descriptive temporary filename
-		uuidStr, _ := uuid.NewV7()
-		tempFilename := fmt.Sprintf("mqtt_temp_%s_%s.wav", req.TerminalID, uuidStr.String())
-		tempPath := filepath.Join("uploads", "audio", tempFilename)
-
-		// Save audio bytes to disk manually (without DB entry)
-		fileWriteStart := time.Now()
-		if err := os.WriteFile(tempPath, audioBytes, 0644); err != nil {
-			fileWriteDuration := time.Since(fileWriteStart)
-			utils.LogError("[%s] WhisperTranscribe MQTT: Failed to save temporary audio: %v | file_write_duration_ms=%d", correlationID, err, fileWriteDuration.Milliseconds())
-			if mac != "" {
-				c.publishMqttError(mac, "Failed to process audio")
-			}
-			return
-		}
-		fileWriteDuration := time.Since(fileWriteStart)
-		utils.LogDebug("[%s] WhisperTranscribe MQTT: File saved | file_write_duration_ms=%d", correlationID, fileWriteDuration.Milliseconds())
-
-		// Start transcription task using usecase
-		transcribeSubmitStart := time.Now()
-		taskID, err := c.transcribeUC.TranscribeAudio(context.Background(), tempPath, tempFilename, language, usecases.TranscriptionMetadata{
-			UID:         req.UID,
-			TerminalID:  req.TerminalID,
-			MacAddress:  mac,
-			RequestID:   req.RequestID,
-			Source:      "mqtt",
-			Trigger:     "mqtt:tera/transcribe",
-			DeleteAfter: true, // Delete file after transcription
-			Diarize:     req.Diarize,
-		})
-		transcribeSubmitDuration := time.Since(transcribeSubmitStart)
-		if err != nil {
-			utils.LogError("[%s] WhisperTranscribe MQTT: Failed to start transcription: %v | transcribe_submit_duration_ms=%d", correlationID, err, transcribeSubmitDuration.Milliseconds())
-			if mac != "" {
-				c.publishMqttError(mac, "Failed to start transcription task: "+err.Error())
-			}
-			_ = os.Remove(tempPath) // Clean up immediately on error
-			return
+	// If we successfully start TranscribeAudio, it takes ownership of deleting the flag.
+	var taskStarted bool
+	defer func() {
+		if !taskStarted {
+			utils.ActiveTranscriptions.Delete(targetID)
 		}
-		utils.LogInfo("[%s] WhisperTranscribe MQTT: Transcription submitted | transcribe_submit_duration_ms=%d", correlationID, transcribeSubmitDuration.Milliseconds())
+	}()
 
-		taskStarted = true
+	// Decode Base64 audio
+	decodeStart := time.Now()
+	audioBytes, err := base64.StdEncoding.DecodeString(req.Audio)
+	decodeDuration := time.Since(decodeStart)
+	if err != nil {
+		utils.LogError("[%s] WhisperTranscribe MQTT: Failed to decode base64: %v | decode_duration_ms=%d", correlationID, err, decodeDuration.Milliseconds())
+		return nil, mqttValidationError("audio", "Failed to decode base64 audio")
+	}
+	utils.LogDebug("[%s] WhisperTranscribe MQTT: Base64 decoded | decode_duration_ms=%d", correlationID, decodeDuration.Milliseconds())
 
-		// Publish success status with empty RecordingID (since not saved in DB)
-		ackPublishStart := time.Now()
-		ackPublishSuccess := false
-		if mac != "" {
-			ackReqID := req.RequestID
-			if ackReqID == "" {
-				ackReqID = correlationID
-			}
-			publishErr := c.publishMqttResponse(mac, commonDtos.StandardResponse{
-				Status:  true,
-				Message: "Transcription task submitted successfully (Ephemeral)",
-				Data: dtos.TranscriptionTaskResponseDTO{
-					TaskID:      taskID,
-					TaskStatus:  "pending",
-					RecordingID: "", // No DB entry
-					RequestID:   ackReqID,
-					Source:      "MQTT_ACK",
-				},
-			})
-			ackPublishSuccess = (publishErr == nil)
-		}
-		ackPublishDuration := time.Since(ackPublishStart)
+	language := req.Language
+	if language == "" {
+		language = "id"
+	}
 
-		totalDuration := time.Since(handlerStart)
-		utils.LogInfo("[%s] WhisperTranscribe MQTT: Started ephemeral task %s for file %s | parse_duration_ms=%d | decode_duration_ms=%d | file_write_duration_ms=%d | transcribe_submit_duration_ms=%d | ack_publish_duration_ms=%d | ack_publish_success=%v | total_duration_ms=%d", correlationID, taskID, tempFilename, parseDuration.Milliseconds(), decodeDuration.Milliseconds(), fileWriteDuration.Milliseconds(), transcribeSubmitDuration.Milliseconds(), ackPublishDuration.Milliseconds(), ackPublishSuccess, totalDuration.Milliseconds())
-	})
+	// Generate a descriptive temporary filename
+	uuidStr, _ := uuid.NewV7()
+	tempFilename := fmt.Sprintf("mqtt_temp_%s_%s.wav", req.TerminalID, uuidStr.String())
+	tempPath := filepath.Join("uploads", "audio", tempFilename)
 
-	// Subscribe to general task signaling as well
-	taskTopic := fmt.Sprintf("users/+/%s/task", c.config.ApplicationEnvironment)
-	_ = c.mqttSvc.Subscribe(taskTopic, 0, func(client mqtt.Client, msg mqtt.Message) {
-		payload := msg.Payload()
-		utils.LogInfo("Task Signaling MQTT: Received message on %s: %s", msg.Topic(), string(payload))
+	// Save audio bytes to disk manually (without DB entry)
+	fileWriteStart := time.Now()
+	if err := os.WriteFile(tempPath, audioBytes, 0644); err != nil {
+		utils.LogError("[%s] WhisperTranscribe MQTT: Failed to save temporary audio: %v | file_write_duration_ms=%d", correlationID, err, time.Since(fileWriteStart).Milliseconds())
+		return nil, infrastructure.NewMqttRpcError(infrastructure.MqttRpcCodeInternal, "Internal Server Error")
+	}
+	fileWriteDuration := time.Since(fileWriteStart)
+
+	// Start transcription task using usecase
+	transcribeSubmitStart := time.Now()
+	taskID, err := c.transcribeUC.TranscribeAudio(context.Background(), tempPath, tempFilename, language, usecases.TranscriptionMetadata{
+		UID:         req.UID,
+		TerminalID:  req.TerminalID,
+		MacAddress:  mac,
+		RequestID:   req.RequestID,
+		Source:      "mqtt",
+		Trigger:     "mqtt:tera/transcribe",
+		DeleteAfter: true, // Delete file after transcription
+		Diarize:     req.Diarize,
 	})
-
+	transcribeSubmitDuration := time.Since(transcribeSubmitStart)
 	if err != nil {
-		utils.LogError("WhisperTranscribe MQTT: Failed to subscribe to %s: %v", topic, err)
-		return err
+		utils.LogError("[%s] WhisperTranscribe MQTT: Failed to start transcription task: %v | transcribe_submit_duration_ms=%d", correlationID, err, transcribeSubmitDuration.Milliseconds())
+		_ = os.Remove(tempPath) // Clean up immediately on error
+		return nil, infrastructure.NewMqttRpcError(infrastructure.MqttRpcCodeInternal, "Internal Server Error")
 	}
-	utils.LogInfo("WhisperTranscribe MQTT: Successfully subscribed to %s", topic)
-	return nil
-}
 
-func (c *WhisperTranscribeController) publishMqttValidationError(mac, field, message string) {
-	c.publishMqttResponse(mac, commonDtos.StandardResponse{
-		Status:  false,
-		Message: "Validation Error",
-		Details: []utils.ValidationErrorDetail{
-			{Field: field, Message: message},
+	taskStarted = true
+
+	utils.LogInfo("[%s] WhisperTranscribe MQTT: Started ephemeral task %s for file %s | decode_duration_ms=%d | file_write_duration_ms=%d | transcribe_submit_duration_ms=%d | total_duration_ms=%d", correlationID, taskID, tempFilename, decodeDuration.Milliseconds(), fileWriteDuration.Milliseconds(), transcribeSubmitDuration.Milliseconds(), time.Since(handlerStart).Milliseconds())
+
+	// Acknowledge with an empty RecordingID (since not saved in DB)
+	return &infrastructure.MqttRpcResult{
+		Message: "Transcription task submitted successfully (Ephemeral)",
+		Data: dtos.TranscriptionTaskResponseDTO{
+			TaskID:      taskID,
+			TaskStatus:  "pending",
+			RecordingID: "", // No DB entry
+			RequestID:   correlationID,
+			Source:      "MQTT_ACK",
 		},
+	}, nil
+}
+
+func mqttValidationError(field, message string) error {
+	return utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+		{Field: field, Message: message},
 	})
 }
 
@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	commonDtos "sensio/domain/common/dtos"
 	"sensio/domain/common/tasks"
+	"sensio/domain/common/utils"
+	ragServices "sensio/domain/models/rag/services"
 	"sensio/domain/models/whisper/dtos"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -67,3 +72,73 @@ func (c *WhisperTranscribeStatusController) GetStatus(ctx *gin.Context) {
 		Message: "Task not found in any service",
 	})
 }
+
+// Export handles GET /api/models/whisper/transcribe/:transcribe_id/export
+// @Summary Export a transcription
+// @Description Download the result of a completed transcription task as SRT or WebVTT captions, a DOCX document or JSON. Captions use the speaker turns (or timed segments) and their timestamps; check aligned_timestamps in the JSON export to know whether times come from the audio.
+// @Tags 04. Models
+// @Security BearerAuth
+// @Produce json
+// @Produce octet-stream
+// @Param transcribe_id path string true "Task ID"
+// @Param format query string false "srt, vtt, docx or json (default)"
+// @Success 200 {file} file
+// @Failure      400  {object}  commonDtos.ValidationErrorResponse
+// @Failure      404  {object}  commonDtos.ErrorResponse
+// @Failure      409  {object}  commonDtos.ErrorResponse
+// @Failure      422  {object}  commonDtos.ErrorResponse
+// @Failure      500  {object}  commonDtos.ErrorResponse
+// @Router /api/models/whisper/transcribe/{transcribe_id}/export [get]
+func (c *WhisperTranscribeStatusController) Export(ctx *gin.Context) {
+	taskID := ctx.Param("transcribe_id")
+	status, err := c.statusUC.GetTaskStatus(taskID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Task not found in any service",
+		})
+		return
+	}
+	if status.Status != "completed" || status.Result == nil {
+		ctx.JSON(http.StatusConflict, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Task is not completed (status: " + status.Status + ")",
+		})
+		return
+	}
+
+	export, err := ragServices.ExportTranscript(ragServices.TranscriptDocumentFromResult(taskID, status.Result), ctx.Query("format"))
+	if err != nil {
+		respondExportError(ctx, "WhisperTranscribeStatusController.Export", err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", export.FileName))
+	ctx.Header("X-Aligned-Timestamps", strconv.FormatBool(export.AlignedTimestamps))
+	ctx.Data(http.StatusOK, export.ContentType, export.Content)
+}
+
+// respondExportError maps transcript export errors to responses
+func respondExportError(ctx *gin.Context, op string, err error) {
+	var valErr *utils.ValidationError
+	if errors.As(err, &valErr) {
+		ctx.JSON(http.StatusBadRequest, commonDtos.StandardResponse{
+			Status:  false,
+			Message: valErr.Message,
+			Details: valErr.Details,
+		})
+		return
+	}
+	if errors.Is(err, ragServices.ErrNoTimedTranscript) {
+		ctx.JSON(http.StatusUnprocessableEntity, commonDtos.StandardResponse{
+			Status:  false,
+			Message: "Transcript has no timed utterances to caption; export it as docx or json instead",
+		})
+		return
+	}
+	utils.LogError("%s: %v", op, err)
+	ctx.JSON(http.StatusInternalServerError, commonDtos.StandardResponse{
+		Status:  false,
+		Message: "Internal Server Error",
+	})
+}
@@ -2,24 +2,28 @@ package dtos
 
 // Utterance represents a single speaker turn with timing information
 // WARNING: start_ms and end_ms are ESTIMATES based on text length heuristics
-// when not explicitly provided by the transcription provider. They are NOT
-// audio-aligned timestamps and should NOT be treated as precise evidence.
-// Only trust timing data when the provider explicitly returns it.
+// unless the result reports aligned_timestamps (e.g. local whisper.cpp, which
+// returns the decoder's segment offsets). Estimates are NOT audio-aligned and
+// should NOT be treated as precise evidence.
 type Utterance struct {
 	SpeakerLabel string  `json:"speaker_label,omitempty"` // e.g., "Speaker 1", "John Doe"
-	StartMs      int64   `json:"start_ms"`                // Start time in milliseconds (ESTIMATE if provider doesn't supply)
-	EndMs        int64   `json:"end_ms"`                  // End time in milliseconds (ESTIMATE if provider doesn't supply)
+	StartMs      int64   `json:"start_ms"`                // Start time in milliseconds (ESTIMATE unless aligned_timestamps)
+	EndMs        int64   `json:"end_ms"`                  // End time in milliseconds (ESTIMATE unless aligned_timestamps)
 	Text         string  `json:"text"`                    // Transcribed text for this utterance
 	Confidence   float64 `json:"confidence,omitempty"`    // Confidence score (0.0-1.0) if available
+
+	// SpeakerEmbedding is the voice feature vector of the turn, used to cluster speakers
+	// across audio segments. Only set by local diarization and never serialized.
+	SpeakerEmbedding []float64 `json:"-"`
 }
 
 // TranscriptSegment represents a chunk of transcript used in segmented/long-audio transcription
 // WARNING: start_ms and end_ms are ESTIMATES based on cumulative text length heuristics
-// when not explicitly provided by the transcription provider. They are NOT audio-aligned.
+// unless the result reports aligned_timestamps.
 type TranscriptSegment struct {
 	Index      int         `json:"index"`                // Segment index (0, 1, 2, ...)
-	StartMs    int64       `json:"start_ms"`             // Segment start time in milliseconds (ESTIMATE)
-	EndMs      int64       `json:"end_ms"`               // Segment end time in milliseconds (ESTIMATE)
+	StartMs    int64       `json:"start_ms"`             // Segment start time in milliseconds (ESTIMATE unless aligned_timestamps)
+	EndMs      int64       `json:"end_ms"`               // Segment end time in milliseconds (ESTIMATE unless aligned_timestamps)
 	Text       string      `json:"text"`                 // Transcribed text for this segment
 	Utterances []Utterance `json:"utterances,omitempty"` // Utterances within this segment, if available
 }
@@ -41,6 +45,15 @@ type ConfidenceSummary struct {
 	UtterancesCount   int     `json:"utterances_count,omitempty"`
 }
 
+// SpeakerStats is the share of a diarized transcript spoken by one speaker
+type SpeakerStats struct {
+	SpeakerLabel    string  `json:"speaker_label"`
+	TalkTimeMs      int64   `json:"talk_time_ms"`      // Sum of the speaker's turn durations (ESTIMATE unless aligned_timestamps)
+	TalkTimePercent float64 `json:"talk_time_percent"` // Share of the total talk time, 0-100
+	Turns           int     `json:"turns"`
+	Words           int     `json:"words"`
+}
+
 // WhisperResult represents the result of a transcription from any provider
 type WhisperResult struct {
 	Transcription    string `json:"transcription"` // Plain text transcription (backward compatible)
@@ -53,6 +66,7 @@ type WhisperResult struct {
 	Segments          []TranscriptSegment `json:"segments,omitempty"`           // Ordered transcript chunks
 	TranscriptFormat  TranscriptFormat    `json:"transcript_format,omitempty"`  // Structure type
 	ConfidenceSummary *ConfidenceSummary  `json:"confidence_summary,omitempty"` // Provider metadata
+	AlignedTimestamps bool                `json:"aligned_timestamps,omitempty"` // start_ms/end_ms come from the audio, not text length
 }
 
 type WhisperMqttRequestDTO struct {
@@ -100,6 +114,7 @@ type AsyncTranscriptionResultDTO struct {
 	TranscriptFormat     TranscriptFormat    `json:"transcript_format,omitempty"`
 	ConfidenceSummary    *ConfidenceSummary  `json:"confidence_summary,omitempty"`
 	NormalizationApplied bool                `json:"normalization_applied,omitempty"` // Whether safe normalization was applied
+	AlignedTimestamps    bool                `json:"aligned_timestamps,omitempty"`    // start_ms/end_ms come from the audio, not text length
 }
 
 type AsyncTranscriptionStatusDTO struct {
@@ -122,6 +137,14 @@ func (s *AsyncTranscriptionStatusDTO) SetExpiry(expiresAt string, expiresInSecon
 	s.ExpiresInSecond = expiresInSeconds
 }
 
+// ApplyJobStatus implements tasks.StatusWithJob interface
+func (s *AsyncTranscriptionStatusDTO) ApplyJobStatus(status string, lastError string) {
+	s.Status = status
+	if s.Error == "" {
+		s.Error = lastError
+	}
+}
+
 type AsyncTranscriptionProcessStatusResponseDTO struct {
 	TaskID     string                       `json:"task_id"`
 	TaskStatus *AsyncTranscriptionStatusDTO `json:"task_status,omitempty"`
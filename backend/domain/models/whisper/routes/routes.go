@@ -15,6 +15,7 @@ func SetupWhisperRoutes(
 	openaiController *controllers.WhisperModelsOpenAIController,
 	groqController *controllers.WhisperModelsGroqController,
 	orionController *controllers.WhisperModelsOrionController,
+	whisperCppController *controllers.WhisperModelsWhisperCppController,
 	uploadSessionController *controllers.UploadSessionController,
 ) {
 	// New standard: /api/models/whisper/*
@@ -24,6 +25,7 @@ func SetupWhisperRoutes(
 		models.POST("/transcribe", transcribeController.Transcribe)
 		models.POST("/transcribe/by-upload", transcribeController.TranscribeByUpload)
 		models.GET("/transcribe/:transcribe_id", statusController.GetStatus)
+		models.GET("/transcribe/:transcribe_id/export", statusController.Export)
 
 		// Upload session routes
 		uploads := models.Group("/uploads")
@@ -38,6 +40,9 @@ func SetupWhisperRoutes(
 		models.POST("/openai", openaiController.Transcribe)
 		models.POST("/groq", groqController.Transcribe)
 		models.POST("/orion", orionController.Transcribe)
+
+		// Local model route
+		models.POST("/cpp", whisperCppController.Transcribe)
 	}
 
 }
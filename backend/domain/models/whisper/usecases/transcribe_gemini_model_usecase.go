@@ -17,6 +17,9 @@ type geminiServiceClient interface {
 	Transcribe(ctx context.Context, audioPath string, language string, diarize bool) (*dtos.WhisperResult, error)
 }
 
+// transcribeGeminiJobType is the job type of transcriptions sent straight to Gemini
+const transcribeGeminiJobType = TranscribeJobType + ".gemini"
+
 type TranscribeGeminiModelUseCase interface {
 	TranscribeAsync(filePath, fileName, language string, trigger ...string) (string, error)
 }
@@ -26,6 +29,7 @@ type transcribeGeminiModelUseCase struct {
 	store   *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO]
 	cache   *tasks.BadgerTaskCache
 	config  *utils.Config
+	jobs    *tasks.JobQueue
 }
 
 func NewTranscribeGeminiModelUseCase(
@@ -33,13 +37,20 @@ func NewTranscribeGeminiModelUseCase(
 	store *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO],
 	cache *tasks.BadgerTaskCache,
 	cfg *utils.Config,
+	jobs *tasks.JobQueue,
 ) TranscribeGeminiModelUseCase {
-	return &transcribeGeminiModelUseCase{
+	u := &transcribeGeminiModelUseCase{
 		service: service,
 		store:   store,
 		cache:   cache,
 		config:  cfg,
+		jobs:    jobs,
 	}
+	jobs.Register(transcribeGeminiJobType, u.runJob, tasks.JobTypeOptions{
+		MaxAttempts: 1,
+		OnCancel:    func(job *tasks.Job) { u.updateStatus(job.ID, "cancelled", nil, nil) },
+	})
+	return u
 }
 
 func (u *transcribeGeminiModelUseCase) TranscribeAsync(filePath, fileName, language string, trigger ...string) (string, error) {
@@ -62,36 +73,51 @@ func (u *transcribeGeminiModelUseCase) TranscribeAsync(filePath, fileName, langu
 	u.store.Set(taskID, status)
 	_ = u.cache.Set(taskID, status)
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				utils.LogError("Gemini Task %s: Panic recovered: %v", taskID, r)
-				u.updateStatus(taskID, "failed", nil, fmt.Errorf("internal panic: %v", r))
-			}
-		}()
-		// Step 1: Health Check
-		if !u.service.HealthCheck() {
-			utils.LogError("Gemini Task %s: Service health check failed", taskID)
-			u.updateStatus(taskID, "failed", nil, fmt.Errorf("Gemini service health check failed"))
-			return
-		}
+	if _, err := u.jobs.Enqueue(transcribeGeminiJobType, taskID, modelTranscribeJob{FilePath: filePath, Language: language}); err != nil {
+		u.updateStatus(taskID, "failed", nil, err)
+		return "", err
+	}
 
-		// Step 2: Transcribe
-		result, err := u.service.Transcribe(context.Background(), filePath, language, false) // Default false for specialized model usecase
-		if err != nil {
-			utils.LogError("Gemini Task %s: Transcription failed: %v", taskID, err)
-			u.updateStatus(taskID, "failed", nil, err)
-			return
-		}
+	return taskID, nil
+}
+
+// runJob transcribes the audio file of a queued job with Gemini
+func (u *transcribeGeminiModelUseCase) runJob(ctx context.Context, job *tasks.Job) (err error) {
+	var payload modelTranscribeJob
+	if err := job.Decode(&payload); err != nil {
+		return tasks.Permanent(err)
+	}
+	taskID, filePath, language := job.ID, payload.FilePath, payload.Language
 
-		finalResult := &dtos.AsyncTranscriptionResultDTO{
-			Transcription:    result.Transcription,
-			DetectedLanguage: result.DetectedLanguage,
+	defer func() {
+		if r := recover(); r != nil {
+			utils.LogError("Gemini Task %s: Panic recovered: %v", taskID, r)
+			err = fmt.Errorf("internal panic: %v", r)
+			u.updateStatus(taskID, "failed", nil, err)
 		}
-		u.updateStatus(taskID, "completed", finalResult, nil)
 	}()
+	// Step 1: Health Check
+	if !u.service.HealthCheck() {
+		utils.LogError("Gemini Task %s: Service health check failed", taskID)
+		err = fmt.Errorf("Gemini service health check failed")
+		u.updateStatus(taskID, "failed", nil, err)
+		return err
+	}
 
-	return taskID, nil
+	// Step 2: Transcribe
+	result, err := u.service.Transcribe(ctx, filePath, language, false) // Default false for specialized model usecase
+	if err != nil {
+		utils.LogError("Gemini Task %s: Transcription failed: %v", taskID, err)
+		u.updateStatus(taskID, "failed", nil, err)
+		return err
+	}
+
+	finalResult := &dtos.AsyncTranscriptionResultDTO{
+		Transcription:    result.Transcription,
+		DetectedLanguage: result.DetectedLanguage,
+	}
+	u.updateStatus(taskID, "completed", finalResult, nil)
+	return nil
 }
 
 func (u *transcribeGeminiModelUseCase) updateStatus(taskID, statusStr string, result *dtos.AsyncTranscriptionResultDTO, err error) {
@@ -17,6 +17,9 @@ type groqServiceClient interface {
 	Transcribe(ctx context.Context, audioPath string, language string, diarize bool) (*dtos.WhisperResult, error)
 }
 
+// transcribeGroqJobType is the job type of transcriptions sent straight to Groq
+const transcribeGroqJobType = TranscribeJobType + ".groq"
+
 type TranscribeGroqModelUseCase interface {
 	TranscribeAsync(filePath, fileName, language string, trigger ...string) (string, error)
 }
@@ -26,6 +29,7 @@ type transcribeGroqModelUseCase struct {
 	store   *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO]
 	cache   *tasks.BadgerTaskCache
 	config  *utils.Config
+	jobs    *tasks.JobQueue
 }
 
 func NewTranscribeGroqModelUseCase(
@@ -33,13 +37,20 @@ func NewTranscribeGroqModelUseCase(
 	store *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO],
 	cache *tasks.BadgerTaskCache,
 	cfg *utils.Config,
+	jobs *tasks.JobQueue,
 ) TranscribeGroqModelUseCase {
-	return &transcribeGroqModelUseCase{
+	u := &transcribeGroqModelUseCase{
 		service: service,
 		store:   store,
 		cache:   cache,
 		config:  cfg,
+		jobs:    jobs,
 	}
+	jobs.Register(transcribeGroqJobType, u.runJob, tasks.JobTypeOptions{
+		MaxAttempts: 1,
+		OnCancel:    func(job *tasks.Job) { u.updateStatus(job.ID, "cancelled", nil, nil) },
+	})
+	return u
 }
 
 func (u *transcribeGroqModelUseCase) TranscribeAsync(filePath, fileName, language string, trigger ...string) (string, error) {
@@ -62,37 +73,52 @@ func (u *transcribeGroqModelUseCase) TranscribeAsync(filePath, fileName, languag
 	u.store.Set(taskID, status)
 	_ = u.cache.Set(taskID, status)
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				utils.LogError("Groq Task %s: Panic recovered: %v", taskID, r)
-				u.updateStatus(taskID, "failed", nil, fmt.Errorf("internal panic: %v", r))
-			}
-		}()
-
-		// Step 1: Health Check
-		if !u.service.HealthCheck() {
-			utils.LogError("Groq Task %s: Service health check failed", taskID)
-			u.updateStatus(taskID, "failed", nil, fmt.Errorf("Groq service health check failed"))
-			return
-		}
+	if _, err := u.jobs.Enqueue(transcribeGroqJobType, taskID, modelTranscribeJob{FilePath: filePath, Language: language}); err != nil {
+		u.updateStatus(taskID, "failed", nil, err)
+		return "", err
+	}
 
-		// Step 2: Transcribe
-		result, err := u.service.Transcribe(context.Background(), filePath, language, false)
-		if err != nil {
-			utils.LogError("Groq Task %s: Transcription failed: %v", taskID, err)
-			u.updateStatus(taskID, "failed", nil, err)
-			return
-		}
+	return taskID, nil
+}
+
+// runJob transcribes the audio file of a queued job with Groq
+func (u *transcribeGroqModelUseCase) runJob(ctx context.Context, job *tasks.Job) (err error) {
+	var payload modelTranscribeJob
+	if err := job.Decode(&payload); err != nil {
+		return tasks.Permanent(err)
+	}
+	taskID, filePath, language := job.ID, payload.FilePath, payload.Language
 
-		finalResult := &dtos.AsyncTranscriptionResultDTO{
-			Transcription:    result.Transcription,
-			DetectedLanguage: result.DetectedLanguage,
+	defer func() {
+		if r := recover(); r != nil {
+			utils.LogError("Groq Task %s: Panic recovered: %v", taskID, r)
+			err = fmt.Errorf("internal panic: %v", r)
+			u.updateStatus(taskID, "failed", nil, err)
 		}
-		u.updateStatus(taskID, "completed", finalResult, nil)
 	}()
 
-	return taskID, nil
+	// Step 1: Health Check
+	if !u.service.HealthCheck() {
+		utils.LogError("Groq Task %s: Service health check failed", taskID)
+		err = fmt.Errorf("Groq service health check failed")
+		u.updateStatus(taskID, "failed", nil, err)
+		return err
+	}
+
+	// Step 2: Transcribe
+	result, err := u.service.Transcribe(ctx, filePath, language, false)
+	if err != nil {
+		utils.LogError("Groq Task %s: Transcription failed: %v", taskID, err)
+		u.updateStatus(taskID, "failed", nil, err)
+		return err
+	}
+
+	finalResult := &dtos.AsyncTranscriptionResultDTO{
+		Transcription:    result.Transcription,
+		DetectedLanguage: result.DetectedLanguage,
+	}
+	u.updateStatus(taskID, "completed", finalResult, nil)
+	return nil
 }
 
 func (u *transcribeGroqModelUseCase) updateStatus(taskID, statusStr string, result *dtos.AsyncTranscriptionResultDTO, err error) {
@@ -17,6 +17,9 @@ type openAIServiceClient interface {
 	Transcribe(ctx context.Context, audioPath string, language string, diarize bool) (*dtos.WhisperResult, error)
 }
 
+// transcribeOpenAIJobType is the job type of transcriptions sent straight to OpenAI
+const transcribeOpenAIJobType = TranscribeJobType + ".openai"
+
 type TranscribeOpenAIModelUseCase interface {
 	TranscribeAsync(filePath, fileName, language string, trigger ...string) (string, error)
 }
@@ -26,6 +29,7 @@ type transcribeOpenAIModelUseCase struct {
 	store   *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO]
 	cache   *tasks.BadgerTaskCache
 	config  *utils.Config
+	jobs    *tasks.JobQueue
 }
 
 func NewTranscribeOpenAIModelUseCase(
@@ -33,13 +37,20 @@ func NewTranscribeOpenAIModelUseCase(
 	store *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO],
 	cache *tasks.BadgerTaskCache,
 	cfg *utils.Config,
+	jobs *tasks.JobQueue,
 ) TranscribeOpenAIModelUseCase {
-	return &transcribeOpenAIModelUseCase{
+	u := &transcribeOpenAIModelUseCase{
 		service: service,
 		store:   store,
 		cache:   cache,
 		config:  cfg,
+		jobs:    jobs,
 	}
+	jobs.Register(transcribeOpenAIJobType, u.runJob, tasks.JobTypeOptions{
+		MaxAttempts: 1,
+		OnCancel:    func(job *tasks.Job) { u.updateStatus(job.ID, "cancelled", nil, nil) },
+	})
+	return u
 }
 
 func (u *transcribeOpenAIModelUseCase) TranscribeAsync(filePath, fileName, language string, trigger ...string) (string, error) {
@@ -62,36 +73,50 @@ func (u *transcribeOpenAIModelUseCase) TranscribeAsync(filePath, fileName, langu
 	u.store.Set(taskID, status)
 	_ = u.cache.Set(taskID, status)
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				utils.LogError("OpenAI Task %s: Panic recovered: %v", taskID, r)
-				u.updateStatus(taskID, "failed", nil, fmt.Errorf("internal panic: %v", r))
-			}
-		}()
-
-		// Step 1: Best-effort health check.
-		// Do not fail fast on transient health endpoint issues; let real transcribe call decide.
-		if !u.service.HealthCheck() {
-			utils.LogWarn("OpenAI Task %s: Service health check failed, proceeding to transcribe", taskID)
-		}
+	if _, err := u.jobs.Enqueue(transcribeOpenAIJobType, taskID, modelTranscribeJob{FilePath: filePath, Language: language}); err != nil {
+		u.updateStatus(taskID, "failed", nil, err)
+		return "", err
+	}
 
-		// Step 2: Transcribe
-		result, err := u.service.Transcribe(context.Background(), filePath, language, false)
-		if err != nil {
-			utils.LogError("OpenAI Task %s: Transcription failed: %v", taskID, err)
-			u.updateStatus(taskID, "failed", nil, err)
-			return
-		}
+	return taskID, nil
+}
+
+// runJob transcribes the audio file of a queued job with OpenAI
+func (u *transcribeOpenAIModelUseCase) runJob(ctx context.Context, job *tasks.Job) (err error) {
+	var payload modelTranscribeJob
+	if err := job.Decode(&payload); err != nil {
+		return tasks.Permanent(err)
+	}
+	taskID, filePath, language := job.ID, payload.FilePath, payload.Language
 
-		finalResult := &dtos.AsyncTranscriptionResultDTO{
-			Transcription:    result.Transcription,
-			DetectedLanguage: result.DetectedLanguage,
+	defer func() {
+		if r := recover(); r != nil {
+			utils.LogError("OpenAI Task %s: Panic recovered: %v", taskID, r)
+			err = fmt.Errorf("internal panic: %v", r)
+			u.updateStatus(taskID, "failed", nil, err)
 		}
-		u.updateStatus(taskID, "completed", finalResult, nil)
 	}()
 
-	return taskID, nil
+	// Step 1: Best-effort health check.
+	// Do not fail fast on transient health endpoint issues; let real transcribe call decide.
+	if !u.service.HealthCheck() {
+		utils.LogWarn("OpenAI Task %s: Service health check failed, proceeding to transcribe", taskID)
+	}
+
+	// Step 2: Transcribe
+	result, err := u.service.Transcribe(ctx, filePath, language, false)
+	if err != nil {
+		utils.LogError("OpenAI Task %s: Transcription failed: %v", taskID, err)
+		u.updateStatus(taskID, "failed", nil, err)
+		return err
+	}
+
+	finalResult := &dtos.AsyncTranscriptionResultDTO{
+		Transcription:    result.Transcription,
+		DetectedLanguage: result.DetectedLanguage,
+	}
+	u.updateStatus(taskID, "completed", finalResult, nil)
+	return nil
 }
 
 func (u *transcribeOpenAIModelUseCase) updateStatus(taskID, statusStr string, result *dtos.AsyncTranscriptionResultDTO, err error) {
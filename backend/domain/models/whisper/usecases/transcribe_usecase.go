@@ -49,6 +49,9 @@ type TranscribeOptions struct {
 	ForceSegmentConcurrency int
 }
 
+// TranscribeJobType is the job type of asynchronous transcriptions
+const TranscribeJobType = "transcription"
+
 type TranscribeUseCase interface {
 	TranscribeAudio(ctx context.Context, inputPath string, fileName string, language string, metadata ...TranscriptionMetadata) (string, error)
 	TranscribeAudioSync(ctx context.Context, inputPath string, opts TranscribeOptions) (*whisperdtos.AsyncTranscriptionResultDTO, error)
@@ -63,6 +66,21 @@ type transcribeUseCase struct {
 	config           *utils.Config
 	mqttSvc          mqttPublisher
 	providerResolver providers.ProviderResolver
+	jobs             *tasks.JobQueue
+}
+
+// transcribeJob is the payload of a TranscribeJobType job
+type transcribeJob struct {
+	InputPath string                 `json:"input_path"`
+	Language  string                 `json:"language"`
+	Metadata  *TranscriptionMetadata `json:"metadata,omitempty"`
+}
+
+// modelTranscribeJob is the payload of the jobs of the provider-specific transcription usecases
+type modelTranscribeJob struct {
+	FilePath string `json:"file_path"`
+	Language string `json:"language"`
+	Diarize  bool   `json:"diarize,omitempty"`
 }
 
 func NewTranscribeUseCase(
@@ -73,8 +91,9 @@ func NewTranscribeUseCase(
 	config *utils.Config,
 	mqttSvc mqttPublisher,
 	providerResolver providers.ProviderResolver,
+	jobs *tasks.JobQueue,
 ) TranscribeUseCase {
-	return &transcribeUseCase{
+	uc := &transcribeUseCase{
 		whisperClient:    whisperClient,
 		refineUC:         refineUC,
 		store:            store,
@@ -82,7 +101,20 @@ func NewTranscribeUseCase(
 		config:           config,
 		mqttSvc:          mqttSvc,
 		providerResolver: providerResolver,
+		jobs:             jobs,
 	}
+
+	timeout, err := time.ParseDuration(config.TranscribeAsyncTimeout)
+	if err != nil {
+		timeout = 8 * time.Hour
+	}
+	// Not retried: the provider fallback chain already retries, and a rerun repeats the whole recording
+	jobs.Register(TranscribeJobType, uc.runJob, tasks.JobTypeOptions{
+		MaxAttempts: 1,
+		Timeout:     timeout,
+		OnCancel:    uc.markCancelled,
+	})
+	return uc
 }
 
 // transcribeWithFallback attempts transcription respecting terminal AI preferences first, then gracefully failing over to remote provider candidates
@@ -224,19 +256,41 @@ func (uc *transcribeUseCase) TranscribeAudio(ctx context.Context, inputPath stri
 		utils.ActiveTranscriptions.Store(meta.TerminalID, true)
 	}
 
-	timeout, err := time.ParseDuration(uc.config.TranscribeAsyncTimeout)
-	if err != nil {
-		timeout = 8 * time.Hour
+	if _, err := uc.jobs.Enqueue(TranscribeJobType, taskID, transcribeJob{InputPath: inputPath, Language: language, Metadata: meta}); err != nil {
+		if meta != nil && meta.TerminalID != "" && meta.Source == "mqtt" {
+			utils.ActiveTranscriptions.Delete(meta.TerminalID)
+		}
+		uc.updateStatus(taskID, "failed", nil, err)
+		return "", err
 	}
-	asyncCtx, cancel := context.WithTimeout(context.Background(), timeout)
-	go func() {
-		defer cancel()
-		uc.processAsync(asyncCtx, taskID, inputPath, language, meta)
-	}()
 
 	return taskID, nil
 }
 
+// runJob transcribes the audio file of a queued job
+func (uc *transcribeUseCase) runJob(ctx context.Context, job *tasks.Job) error {
+	var payload transcribeJob
+	if err := job.Decode(&payload); err != nil {
+		uc.updateStatus(job.ID, "failed", nil, err)
+		return tasks.Permanent(err)
+	}
+	if _, err := os.Stat(payload.InputPath); err != nil {
+		err = fmt.Errorf("audio file not found")
+		uc.updateStatus(job.ID, "failed", nil, err)
+		return tasks.Permanent(err)
+	}
+	return uc.processAsync(ctx, job.ID, payload.InputPath, payload.Language, payload.Metadata)
+}
+
+// markCancelled records a cancelled transcription job in the task status
+func (uc *transcribeUseCase) markCancelled(job *tasks.Job) {
+	var payload transcribeJob
+	if err := job.Decode(&payload); err == nil && payload.Metadata != nil && payload.Metadata.TerminalID != "" && payload.Metadata.Source == "mqtt" {
+		utils.ActiveTranscriptions.Delete(payload.Metadata.TerminalID)
+	}
+	uc.updateStatus(job.ID, "cancelled", nil, nil)
+}
+
 func (uc *transcribeUseCase) TranscribeAudioSync(ctx context.Context, inputPath string, opts TranscribeOptions) (*whisperdtos.AsyncTranscriptionResultDTO, error) {
 	var rawTranscription string
 	var detectedLang string
@@ -244,6 +298,7 @@ func (uc *transcribeUseCase) TranscribeAudioSync(ctx context.Context, inputPath
 	var resultSegments []whisperdtos.TranscriptSegment
 	var resultTranscriptFormat whisperdtos.TranscriptFormat
 	var resultConfidenceSummary *whisperdtos.ConfidenceSummary
+	var resultAligned bool
 
 	// Resolve provider from terminal context (MAC address) if available
 	resolvedProvider := "unknown"
@@ -317,10 +372,9 @@ func (uc *transcribeUseCase) TranscribeAudioSync(ctx context.Context, inputPath
 			defer utils.CleanupSegments(segments)
 
 			type segResult struct {
-				index int
-				text  string
-				lang  string
-				err   error
+				index  int
+				result *whisperdtos.WhisperResult
+				err    error
 			}
 
 			results := make([]segResult, len(segments))
@@ -368,7 +422,7 @@ func (uc *transcribeUseCase) TranscribeAudioSync(ctx context.Context, inputPath
 					if transErr != nil {
 						results[idx] = segResult{index: idx, err: transErr}
 					} else {
-						results[idx] = segResult{index: idx, text: res.Transcription, lang: res.DetectedLanguage}
+						results[idx] = segResult{index: idx, result: res}
 					}
 
 					completed++
@@ -383,73 +437,21 @@ func (uc *transcribeUseCase) TranscribeAudioSync(ctx context.Context, inputPath
 			}
 			wg.Wait()
 
-			// Merge and Dedup - now preserving segment structure
-			var mergedText string
-			var allSegments []whisperdtos.TranscriptSegment
-			var allUtterances []whisperdtos.Utterance
-
-			// Track cumulative offset for segment timing
-			var cumulativeOffsetMs int64 = 0
-
+			segmentResults := make([]*whisperdtos.WhisperResult, len(results))
 			for i, r := range results {
 				if r.err != nil {
 					return nil, fmt.Errorf("segment %d failed: %w", r.index, r.err)
 				}
-
-				if i == 0 {
-					mergedText = r.text
-					detectedLang = r.lang
-				} else {
-					// Use utility merge function that handles overlap detection
-					overlapChars := utils.FindOverlapLength(mergedText, r.text)
-					if overlapChars > 0 {
-						r.text = r.text[overlapChars:]
-					}
-					mergedText = mergedText + " " + strings.TrimSpace(r.text)
-					if detectedLang == "" && r.lang != "" {
-						detectedLang = r.lang
-					}
-				}
-
-				// Create segment record with timing info (estimated)
-				// WARNING: These are HEURISTIC ESTIMATES based on text length (~10 chars/sec).
-				// They are NOT audio-aligned timestamps. Do NOT treat as precise evidence.
-				segment := whisperdtos.TranscriptSegment{
-					Index:   i,
-					StartMs: cumulativeOffsetMs,
-					EndMs:   cumulativeOffsetMs + int64(len(r.text)*100), // ~10 chars/sec estimate
-					Text:    r.text,
-				}
-				allSegments = append(allSegments, segment)
-
-				// Parse utterances from this segment ONLY if diarization was requested
-				// This prevents false-positive structured output when diarize=false
-				if opts.Diarize {
-					if segmentUtterances := utils.ParseUtterancesFromText(r.text); len(segmentUtterances) > 0 {
-						// Adjust utterance timestamps to global timeline
-						for j := range segmentUtterances {
-							segmentUtterances[j].StartMs += cumulativeOffsetMs
-							segmentUtterances[j].EndMs += cumulativeOffsetMs
-						}
-						segment.Utterances = segmentUtterances
-						allUtterances = append(allUtterances, segmentUtterances...)
-					}
-				}
-
-				cumulativeOffsetMs += int64(len(r.text) * 100)
-			}
-
-			rawTranscription = strings.TrimSpace(mergedText)
-
-			// Store structured results from segmented transcription
-			resultSegments = allSegments
-			resultUtterances = allUtterances
-			if len(allUtterances) > 0 {
-				resultTranscriptFormat = whisperdtos.TranscriptFormatUtteranceList
-				resultConfidenceSummary = utils.BuildConfidenceSummary(allUtterances, len(allSegments))
-			} else {
-				resultTranscriptFormat = whisperdtos.TranscriptFormatPlainText
+				segmentResults[i] = r.result
 			}
+			merged := mergeSegmentResults(segments, segmentResults, opts.Diarize, uc.config.DiarizationMaxSpeakers)
+			rawTranscription = merged.Transcription
+			detectedLang = merged.DetectedLanguage
+			resultSegments = merged.Segments
+			resultUtterances = merged.Utterances
+			resultTranscriptFormat = merged.TranscriptFormat
+			resultConfidenceSummary = merged.ConfidenceSummary
+			resultAligned = merged.AlignedTimestamps
 		}
 	}
 
@@ -471,6 +473,7 @@ func (uc *transcribeUseCase) TranscribeAudioSync(ctx context.Context, inputPath
 		resultSegments = result.Segments
 		resultTranscriptFormat = result.TranscriptFormat
 		resultConfidenceSummary = result.ConfidenceSummary
+		resultAligned = result.AlignedTimestamps
 
 		if opts.ProgressCallback != nil {
 			go opts.ProgressCallback(100)
@@ -505,10 +508,11 @@ func (uc *transcribeUseCase) TranscribeAudioSync(ctx context.Context, inputPath
 		TranscriptFormat:     resultTranscriptFormat,
 		ConfidenceSummary:    resultConfidenceSummary,
 		NormalizationApplied: normalizationApplied,
+		AlignedTimestamps:    resultAligned,
 	}, nil
 }
 
-func (uc *transcribeUseCase) processAsync(ctx context.Context, taskID string, inputPath string, reqLanguage string, metadata *TranscriptionMetadata) {
+func (uc *transcribeUseCase) processAsync(ctx context.Context, taskID string, inputPath string, reqLanguage string, metadata *TranscriptionMetadata) (err error) {
 	defer func() {
 		// Defensive cleanup in case of panic or early exit
 		if metadata != nil && metadata.TerminalID != "" && metadata.Source == "mqtt" {
@@ -516,7 +520,8 @@ func (uc *transcribeUseCase) processAsync(ctx context.Context, taskID string, in
 		}
 		if r := recover(); r != nil {
 			utils.LogError("Transcribe Task %s: Panic recovered: %v", taskID, r)
-			uc.updateStatus(taskID, "failed", nil, fmt.Errorf("internal panic: %v", r))
+			err = fmt.Errorf("internal panic: %v", r)
+			uc.updateStatus(taskID, "failed", nil, err)
 		}
 	}()
 
@@ -549,7 +554,7 @@ func (uc *transcribeUseCase) processAsync(ctx context.Context, taskID string, in
 		if metadata != nil && metadata.DeleteAfter {
 			_ = os.Remove(inputPath)
 		}
-		return
+		return err
 	}
 
 	utils.LogInfo("Transcribe Task %s: Finished successfully", taskID)
@@ -587,6 +592,7 @@ func (uc *transcribeUseCase) processAsync(ctx context.Context, taskID string, in
 		}
 		utils.LogInfo("Transcribe Task %s: Chained result to %s", taskID, chatTopic)
 	}
+	return nil
 }
 
 func (uc *transcribeUseCase) updateStatus(taskID string, statusStr string, result *whisperdtos.AsyncTranscriptionResultDTO, err error) {
@@ -698,6 +704,9 @@ func (uc *transcribeUseCase) getProviderDirectLimit(provider string) int64 {
 		return services.GroqDirectUploadLimitBytes
 	case "orion":
 		return services.OrionDirectUploadLimitBytes
+	case providers.WhisperCppProvider:
+		// No upload involved; long recordings are still segmented so they transcribe in parallel
+		return 20 * 1024 * 1024
 	default:
 		// For local or unknown providers, use conservative 20MB default.
 		// This is safer than allowing potentially oversized uploads.
@@ -757,3 +766,84 @@ func (uc *transcribeUseCase) mergeWithDedup(prev, current string) string {
 
 	return prev + " " + current
 }
+
+// mergeSegmentResults joins the transcripts of consecutive audio segments. Providers that return
+// audio-aligned segments (whisper.cpp) are merged on the real timeline; the timings of the others
+// are estimated from text length.
+func mergeSegmentResults(segments []utils.AudioSegment, results []*whisperdtos.WhisperResult, diarize bool, maxSpeakers int) *whisperdtos.WhisperResult {
+	aligned := true
+	for _, r := range results {
+		aligned = aligned && r.AlignedTimestamps
+	}
+	if aligned {
+		return utils.MergeAlignedResults(segments, results, diarize, maxSpeakers)
+	}
+
+	// Merge and Dedup - now preserving segment structure
+	var mergedText, detectedLang string
+	var allSegments []whisperdtos.TranscriptSegment
+	var allUtterances []whisperdtos.Utterance
+
+	// Track cumulative offset for segment timing
+	var cumulativeOffsetMs int64 = 0
+
+	for i, r := range results {
+		text := r.Transcription
+		if i == 0 {
+			mergedText = text
+			detectedLang = r.DetectedLanguage
+		} else {
+			// Use utility merge function that handles overlap detection
+			overlapChars := utils.FindOverlapLength(mergedText, text)
+			if overlapChars > 0 {
+				text = text[overlapChars:]
+			}
+			mergedText = mergedText + " " + strings.TrimSpace(text)
+			if detectedLang == "" && r.DetectedLanguage != "" {
+				detectedLang = r.DetectedLanguage
+			}
+		}
+
+		// Create segment record with timing info (estimated)
+		// WARNING: These are HEURISTIC ESTIMATES based on text length (~10 chars/sec).
+		// They are NOT audio-aligned timestamps. Do NOT treat as precise evidence.
+		segment := whisperdtos.TranscriptSegment{
+			Index:   i,
+			StartMs: cumulativeOffsetMs,
+			EndMs:   cumulativeOffsetMs + int64(len(text)*100), // ~10 chars/sec estimate
+			Text:    text,
+		}
+		allSegments = append(allSegments, segment)
+
+		// Parse utterances from this segment ONLY if diarization was requested
+		// This prevents false-positive structured output when diarize=false
+		if diarize {
+			if segmentUtterances := utils.ParseUtterancesFromText(text); len(segmentUtterances) > 0 {
+				// Adjust utterance timestamps to global timeline
+				for j := range segmentUtterances {
+					segmentUtterances[j].StartMs += cumulativeOffsetMs
+					segmentUtterances[j].EndMs += cumulativeOffsetMs
+				}
+				segment.Utterances = segmentUtterances
+				allUtterances = append(allUtterances, segmentUtterances...)
+			}
+		}
+
+		cumulativeOffsetMs += int64(len(text) * 100)
+	}
+
+	// Store structured results from segmented transcription
+	merged := &whisperdtos.WhisperResult{
+		Transcription:    strings.TrimSpace(mergedText),
+		DetectedLanguage: detectedLang,
+		Segments:         allSegments,
+		Utterances:       allUtterances,
+	}
+	if len(allUtterances) > 0 {
+		merged.TranscriptFormat = whisperdtos.TranscriptFormatUtteranceList
+		merged.ConfidenceSummary = utils.BuildConfidenceSummary(allUtterances, len(allSegments))
+	} else {
+		merged.TranscriptFormat = whisperdtos.TranscriptFormatPlainText
+	}
+	return merged
+}
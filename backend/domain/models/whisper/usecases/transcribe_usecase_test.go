@@ -1,6 +1,10 @@
 package usecases
 
 import (
+	"context"
+	"sensio/domain/common/providers"
+	"sensio/domain/common/utils"
+	whisperdtos "sensio/domain/models/whisper/dtos"
 	"testing"
 )
 
@@ -59,3 +63,65 @@ func TestMergeWithDedup(t *testing.T) {
 		})
 	}
 }
+
+type alignedWhisper struct{}
+
+// Transcribe returns one aligned segment per call, timed relative to the audio piece it was given
+func (alignedWhisper) Transcribe(ctx context.Context, audioPath string, language string, diarize bool) (*whisperdtos.WhisperResult, error) {
+	return &whisperdtos.WhisperResult{
+		Transcription:     audioPath,
+		DetectedLanguage:  "id",
+		Segments:          []whisperdtos.TranscriptSegment{{StartMs: 1000, EndMs: 4000, Text: audioPath}},
+		AlignedTimestamps: true,
+	}, nil
+}
+
+type whisperCppTerminals struct{}
+
+func (whisperCppTerminals) GetByID(id string) (*providers.Terminal, error) {
+	provider := providers.WhisperCppProvider
+	return &providers.Terminal{AiProvider: &provider}, nil
+}
+
+func (t whisperCppTerminals) GetByMacAddress(macAddress string) (*providers.Terminal, error) {
+	return t.GetByID(macAddress)
+}
+
+func TestSegmentedTranscription_WhisperCppTerminalMergesOnAudioTimeline(t *testing.T) {
+	cfg := &utils.Config{LLMProvider: "openai", OpenAIApiKey: "test-key"}
+	gemini, openai, groq, orion := providers.GetProviderServices(cfg)
+	uc := &transcribeUseCase{
+		providerResolver: providers.NewProviderResolver(cfg, gemini, openai, groq, orion, alignedWhisper{}, whisperCppTerminals{}),
+	}
+
+	// The segments of a pipeline recording, transcribed as TranscribeAudioSync does for the terminal
+	segments := []utils.AudioSegment{
+		{Index: 0, Path: "first part", StartMs: 0, EndMs: 60000},
+		{Index: 1, Path: "second part", StartMs: 55000, EndMs: 120000},
+	}
+	results := make([]*whisperdtos.WhisperResult, len(segments))
+	for i, seg := range segments {
+		res, err := uc.transcribeWithFallback(context.Background(), seg.Path, "id", false, false, true, providers.WhisperCppProvider, "aa:bb")
+		if err != nil {
+			t.Fatalf("segment %d: %v", i, err)
+		}
+		results[i] = res
+	}
+
+	merged := mergeSegmentResults(segments, results, false, 0)
+	if !merged.AlignedTimestamps {
+		t.Fatal("expected whisper.cpp segments to be merged on the audio timeline")
+	}
+	if len(merged.Segments) != 2 || merged.Segments[1].StartMs != 56000 {
+		t.Fatalf("expected the second segment offset by its audio start, got %+v", merged.Segments)
+	}
+	if merged.Transcription != "first part second part" {
+		t.Errorf("unexpected transcription %q", merged.Transcription)
+	}
+
+	// A remote provider in the mix falls back to text-length estimates
+	results[1] = &whisperdtos.WhisperResult{Transcription: "second part"}
+	if estimated := mergeSegmentResults(segments, results, false, 0); estimated.AlignedTimestamps {
+		t.Error("expected unaligned results to be merged as estimates")
+	}
+}
@@ -12,32 +12,48 @@ import (
 	"github.com/google/uuid"
 )
 
+type whisperCppServiceClient interface {
+	HealthCheck() bool
+	Transcribe(ctx context.Context, audioPath string, language string, diarize bool) (*dtos.WhisperResult, error)
+}
+
+// transcribeWhisperCppJobType is the job type of transcriptions run locally with whisper.cpp
+const transcribeWhisperCppJobType = TranscribeJobType + ".whispercpp"
+
 type TranscribeWhisperCppModelUseCase interface {
-	TranscribeAsync(filePath, fileName, language string, trigger ...string) (string, error)
+	TranscribeAsync(filePath, fileName, language string, diarize bool, trigger ...string) (string, error)
 }
 
 type transcribeWhisperCppModelUseCase struct {
-	client WhisperClient
-	store  *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO]
-	cache  *tasks.BadgerTaskCache
-	config *utils.Config
+	service whisperCppServiceClient
+	store   *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO]
+	cache   *tasks.BadgerTaskCache
+	config  *utils.Config
+	jobs    *tasks.JobQueue
 }
 
 func NewTranscribeWhisperCppModelUseCase(
-	client WhisperClient,
+	service whisperCppServiceClient,
 	store *tasks.StatusStore[dtos.AsyncTranscriptionStatusDTO],
 	cache *tasks.BadgerTaskCache,
 	cfg *utils.Config,
+	jobs *tasks.JobQueue,
 ) TranscribeWhisperCppModelUseCase {
-	return &transcribeWhisperCppModelUseCase{
-		client: client,
-		store:  store,
-		cache:  cache,
-		config: cfg,
+	u := &transcribeWhisperCppModelUseCase{
+		service: service,
+		store:   store,
+		cache:   cache,
+		config:  cfg,
+		jobs:    jobs,
 	}
+	jobs.Register(transcribeWhisperCppJobType, u.runJob, tasks.JobTypeOptions{
+		MaxAttempts: 1,
+		OnCancel:    func(job *tasks.Job) { u.updateStatus(job.ID, "cancelled", nil, nil) },
+	})
+	return u
 }
 
-func (u *transcribeWhisperCppModelUseCase) TranscribeAsync(filePath, fileName, language string, trigger ...string) (string, error) {
+func (u *transcribeWhisperCppModelUseCase) TranscribeAsync(filePath, fileName, language string, diarize bool, trigger ...string) (string, error) {
 	if _, err := os.Stat(filePath); err != nil {
 		return "", fmt.Errorf("file not found: %s", filePath)
 	}
@@ -57,30 +73,87 @@ func (u *transcribeWhisperCppModelUseCase) TranscribeAsync(filePath, fileName, l
 	u.store.Set(taskID, status)
 	_ = u.cache.Set(taskID, status)
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				utils.LogError("WhisperCpp Task %s: Panic recovered: %v", taskID, r)
-				u.updateStatus(taskID, "failed", nil, fmt.Errorf("internal panic: %v", r))
-			}
-		}()
+	payload := modelTranscribeJob{FilePath: filePath, Language: language, Diarize: diarize}
+	if _, err := u.jobs.Enqueue(transcribeWhisperCppJobType, taskID, payload); err != nil {
+		u.updateStatus(taskID, "failed", nil, err)
+		return "", err
+	}
 
-		// Step 2: Transcribe
-		result, err := u.client.Transcribe(context.Background(), filePath, language, false)
-		if err != nil {
-			utils.LogError("WhisperCpp Task %s: Transcription failed: %v", taskID, err)
-			u.updateStatus(taskID, "failed", nil, err)
-			return
-		}
+	return taskID, nil
+}
+
+// runJob transcribes the audio file of a queued job with the local whisper.cpp model
+func (u *transcribeWhisperCppModelUseCase) runJob(ctx context.Context, job *tasks.Job) (err error) {
+	var payload modelTranscribeJob
+	if err := job.Decode(&payload); err != nil {
+		return tasks.Permanent(err)
+	}
+	taskID, filePath, language := job.ID, payload.FilePath, payload.Language
 
-		finalResult := &dtos.AsyncTranscriptionResultDTO{
-			Transcription:    result.Transcription,
-			DetectedLanguage: result.DetectedLanguage,
+	defer func() {
+		if r := recover(); r != nil {
+			utils.LogError("WhisperCpp Task %s: Panic recovered: %v", taskID, r)
+			err = fmt.Errorf("internal panic: %v", r)
+			u.updateStatus(taskID, "failed", nil, err)
 		}
-		u.updateStatus(taskID, "completed", finalResult, nil)
 	}()
+	// Step 1: Health Check
+	if !u.service.HealthCheck() {
+		utils.LogError("WhisperCpp Task %s: Service health check failed", taskID)
+		err = fmt.Errorf("Whisper.cpp service health check failed")
+		u.updateStatus(taskID, "failed", nil, err)
+		return err
+	}
 
-	return taskID, nil
+	// Step 2: Transcribe
+	result, err := u.transcribe(ctx, filePath, language, payload.Diarize)
+	if err != nil {
+		utils.LogError("WhisperCpp Task %s: Transcription failed: %v", taskID, err)
+		u.updateStatus(taskID, "failed", nil, err)
+		return err
+	}
+
+	finalResult := &dtos.AsyncTranscriptionResultDTO{
+		Transcription:     result.Transcription,
+		DetectedLanguage:  result.DetectedLanguage,
+		Utterances:        result.Utterances,
+		Segments:          result.Segments,
+		TranscriptFormat:  result.TranscriptFormat,
+		ConfidenceSummary: result.ConfidenceSummary,
+		AlignedTimestamps: result.AlignedTimestamps,
+	}
+	u.updateStatus(taskID, "completed", finalResult, nil)
+	return nil
+}
+
+// transcribe runs whisper.cpp on the whole file, or segment by segment when AUDIO_SEGMENT_ENABLED is set
+// and the audio is longer than one segment. Segments run one at a time since whisper.cpp already uses
+// every core, and are merged on the audio timeline with speakers clustered across the whole recording.
+func (u *transcribeWhisperCppModelUseCase) transcribe(ctx context.Context, filePath, language string, diarize bool) (*dtos.WhisperResult, error) {
+	if !u.config.AudioSegmentEnabled || u.config.AudioSegmentSec <= 0 {
+		return u.service.Transcribe(ctx, filePath, language, diarize)
+	}
+	probe, err := utils.ProbeAudio(filePath)
+	if err != nil || probe.Duration <= float64(u.config.AudioSegmentSec) {
+		return u.service.Transcribe(ctx, filePath, language, diarize)
+	}
+
+	segments, err := utils.SplitAudioSegments(filePath, u.config.AudioSegmentSec, u.config.AudioSegmentOverlapSec)
+	if err != nil {
+		utils.LogWarn("WhisperCpp: Failed to split audio, transcribing the full file: %v", err)
+		return u.service.Transcribe(ctx, filePath, language, diarize)
+	}
+	defer utils.CleanupSegments(segments)
+
+	results := make([]*dtos.WhisperResult, len(segments))
+	for i, seg := range segments {
+		res, err := u.service.Transcribe(ctx, seg.Path, language, diarize)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d failed: %w", seg.Index, err)
+		}
+		results[i] = res
+	}
+	return utils.MergeAlignedResults(segments, results, diarize, u.config.DiarizationMaxSpeakers), nil
 }
 
 func (u *transcribeWhisperCppModelUseCase) updateStatus(taskID, statusStr string, result *dtos.AsyncTranscriptionResultDTO, err error) {
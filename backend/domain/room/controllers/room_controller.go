@@ -0,0 +1,349 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+	room_dtos "sensio/domain/room/dtos"
+	"sensio/domain/room/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomController exposes rooms, their zones and room-wide group control
+type RoomController struct {
+	roomUC    *usecases.RoomUseCase
+	controlUC *usecases.RoomControlUseCase
+}
+
+// Force Swaggo to detect DTOs
+var _ = room_dtos.CreateRoomRequestDTO{}
+
+func NewRoomController(roomUC *usecases.RoomUseCase, controlUC *usecases.RoomControlUseCase) *RoomController {
+	return &RoomController{
+		roomUC:    roomUC,
+		controlUC: controlUC,
+	}
+}
+
+// CreateRoom handles POST /api/rooms
+// @Summary Create a room
+// @Description Register a room. Pass the id terminals already use as room_id to adopt it; otherwise a UUID is generated.
+// @Tags 13. Rooms
+// @Accept json
+// @Produce json
+// @Param request body room_dtos.CreateRoomRequestDTO true "Room"
+// @Success 201 {object} dtos.StandardResponse{data=room_dtos.RoomResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms [post]
+func (c *RoomController) CreateRoom(ctx *gin.Context) {
+	var req room_dtos.CreateRoomRequestDTO
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.roomUC.CreateRoom(&req)
+	if err != nil {
+		respondError(ctx, "CreateRoom", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.StandardResponse{
+		Status:  true,
+		Message: "Room created successfully",
+		Data:    result,
+	})
+}
+
+// ListRooms handles GET /api/rooms
+// @Summary List rooms
+// @Tags 13. Rooms
+// @Produce json
+// @Success 200 {object} dtos.StandardResponse{data=[]room_dtos.RoomResponseDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms [get]
+func (c *RoomController) ListRooms(ctx *gin.Context) {
+	result, err := c.roomUC.ListRooms()
+	if err != nil {
+		respondError(ctx, "ListRooms", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Rooms retrieved successfully",
+		Data:    result,
+	})
+}
+
+// GetRoom handles GET /api/rooms/:room_id
+// @Summary Get a room
+// @Tags 13. Rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Success 200 {object} dtos.StandardResponse{data=room_dtos.RoomResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms/{room_id} [get]
+func (c *RoomController) GetRoom(ctx *gin.Context) {
+	result, err := c.roomUC.GetRoom(ctx.Param("room_id"))
+	if err != nil {
+		respondError(ctx, "GetRoom", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Room retrieved successfully",
+		Data:    result,
+	})
+}
+
+// UpdateRoom handles PUT /api/rooms/:room_id
+// @Summary Update a room
+// @Tags 13. Rooms
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param request body room_dtos.UpdateRoomRequestDTO true "Room"
+// @Success 200 {object} dtos.StandardResponse{data=room_dtos.RoomResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms/{room_id} [put]
+func (c *RoomController) UpdateRoom(ctx *gin.Context) {
+	var req room_dtos.UpdateRoomRequestDTO
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.roomUC.UpdateRoom(ctx.Param("room_id"), &req)
+	if err != nil {
+		respondError(ctx, "UpdateRoom", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Room updated successfully",
+		Data:    result,
+	})
+}
+
+// DeleteRoom handles DELETE /api/rooms/:room_id
+// @Summary Delete a room
+// @Description Delete a room and its zones. Devices assigned to it fall back to the room of their terminal.
+// @Tags 13. Rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Success 200 {object} dtos.StandardResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms/{room_id} [delete]
+func (c *RoomController) DeleteRoom(ctx *gin.Context) {
+	if err := c.roomUC.DeleteRoom(ctx.Param("room_id")); err != nil {
+		respondError(ctx, "DeleteRoom", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Room deleted successfully",
+	})
+}
+
+// CreateZone handles POST /api/rooms/:room_id/zones
+// @Summary Create a zone
+// @Tags 13. Rooms
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param request body room_dtos.ZoneRequestDTO true "Zone"
+// @Success 201 {object} dtos.StandardResponse{data=room_dtos.ZoneResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms/{room_id}/zones [post]
+func (c *RoomController) CreateZone(ctx *gin.Context) {
+	var req room_dtos.ZoneRequestDTO
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.roomUC.CreateZone(ctx.Param("room_id"), &req)
+	if err != nil {
+		respondError(ctx, "CreateZone", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.StandardResponse{
+		Status:  true,
+		Message: "Zone created successfully",
+		Data:    result,
+	})
+}
+
+// UpdateZone handles PUT /api/rooms/:room_id/zones/:zone_id
+// @Summary Rename a zone
+// @Tags 13. Rooms
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param zone_id path string true "Zone UUID"
+// @Param request body room_dtos.ZoneRequestDTO true "Zone"
+// @Success 200 {object} dtos.StandardResponse{data=room_dtos.ZoneResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms/{room_id}/zones/{zone_id} [put]
+func (c *RoomController) UpdateZone(ctx *gin.Context) {
+	var req room_dtos.ZoneRequestDTO
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.roomUC.UpdateZone(ctx.Param("room_id"), ctx.Param("zone_id"), &req)
+	if err != nil {
+		respondError(ctx, "UpdateZone", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Zone updated successfully",
+		Data:    result,
+	})
+}
+
+// DeleteZone handles DELETE /api/rooms/:room_id/zones/:zone_id
+// @Summary Delete a zone
+// @Description Delete a zone. Devices assigned to it stay in the room.
+// @Tags 13. Rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param zone_id path string true "Zone UUID"
+// @Success 200 {object} dtos.StandardResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms/{room_id}/zones/{zone_id} [delete]
+func (c *RoomController) DeleteZone(ctx *gin.Context) {
+	if err := c.roomUC.DeleteZone(ctx.Param("room_id"), ctx.Param("zone_id")); err != nil {
+		respondError(ctx, "DeleteZone", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Zone deleted successfully",
+	})
+}
+
+// ListRoomDevices handles GET /api/rooms/:room_id/devices
+// @Summary List the devices of a room
+// @Description Devices assigned to the room plus the unassigned devices of the terminals placed in it (inherited=true). With zone_id, only the devices assigned to that zone.
+// @Tags 13. Rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param zone_id query string false "Zone UUID"
+// @Success 200 {object} dtos.StandardResponse{data=[]room_dtos.RoomDeviceDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms/{room_id}/devices [get]
+func (c *RoomController) ListRoomDevices(ctx *gin.Context) {
+	result, err := c.roomUC.ListRoomDevices(ctx.Param("room_id"), ctx.Query("zone_id"))
+	if err != nil {
+		respondError(ctx, "ListRoomDevices", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Room devices retrieved successfully",
+		Data:    result,
+	})
+}
+
+// ControlRoom handles POST /api/rooms/:room_id/control
+// @Summary Turn every device of a room or zone on or off
+// @Description Fan the command out to every device of the room (or zone) with a bounded number of concurrent Tuya calls. Door locks are always skipped. The response reports the outcome per device; a failed device never stops the others.
+// @Tags 13. Rooms
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param request body room_dtos.RoomControlRequestDTO true "Group command"
+// @Success 200 {object} dtos.StandardResponse{data=room_dtos.RoomControlResultDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rooms/{room_id}/control [post]
+func (c *RoomController) ControlRoom(ctx *gin.Context) {
+	var req room_dtos.RoomControlRequestDTO
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.controlUC.ControlRoom(ctx.Param("room_id"), req.ZoneID, req.Action, ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "ControlRoom", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Room command sent",
+		Data:    result,
+	})
+}
+
+func bindJSON(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error",
+			Details: []utils.ValidationErrorDetail{
+				{Field: "payload", Message: "Invalid request body: " + err.Error()},
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func respondError(ctx *gin.Context, op string, err error) {
+	var valErr *utils.ValidationError
+	if errors.As(err, &valErr) {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: valErr.Message,
+			Details: valErr.Details,
+		})
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	message := http.StatusText(statusCode)
+	switch {
+	case errors.Is(err, usecases.ErrRoomNotFound):
+		statusCode, message = http.StatusNotFound, "Room not found"
+	case errors.Is(err, usecases.ErrZoneNotFound):
+		statusCode, message = http.StatusNotFound, "Zone not found"
+	default:
+		utils.LogError("RoomController.%s: %v", op, err)
+	}
+	ctx.JSON(statusCode, dtos.StandardResponse{
+		Status:  false,
+		Message: message,
+	})
+}
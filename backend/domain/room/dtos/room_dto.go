@@ -0,0 +1,77 @@
+package dtos
+
+// CreateRoomRequestDTO for POST /api/rooms
+type CreateRoomRequestDTO struct {
+	ID          string `json:"id,omitempty" example:"meeting-room-3"` // Optional: adopt the room_id terminals already use
+	Name        string `json:"name" binding:"required" example:"Meeting Room 3"`
+	Description string `json:"description,omitempty" example:"Second floor, east wing"`
+}
+
+// UpdateRoomRequestDTO for PUT /api/rooms/:room_id
+type UpdateRoomRequestDTO struct {
+	Name        string `json:"name" binding:"required" example:"Meeting Room 3"`
+	Description string `json:"description,omitempty"`
+}
+
+// ZoneRequestDTO for POST and PUT /api/rooms/:room_id/zones
+type ZoneRequestDTO struct {
+	Name string `json:"name" binding:"required" example:"Stage"`
+}
+
+// ZoneResponseDTO represents a zone of a room
+type ZoneResponseDTO struct {
+	ID        string `json:"id"`
+	RoomID    string `json:"room_id"`
+	Name      string `json:"name" example:"Stage"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// RoomResponseDTO represents a room with its zones
+type RoomResponseDTO struct {
+	ID          string            `json:"id" example:"meeting-room-3"`
+	Name        string            `json:"name" example:"Meeting Room 3"`
+	Description string            `json:"description,omitempty"`
+	Zones       []ZoneResponseDTO `json:"zones"`
+	CreatedAt   string            `json:"created_at"`
+	UpdatedAt   string            `json:"updated_at"`
+}
+
+// RoomDeviceDTO is a device of a room
+type RoomDeviceDTO struct {
+	ID         string `json:"id"`
+	TerminalID string `json:"terminal_id"`
+	Name       string `json:"name" example:"Ceiling Light"`
+	Category   string `json:"category" example:"dj"`
+	RemoteID   string `json:"remote_id,omitempty"`
+	ZoneID     string `json:"zone_id,omitempty"`
+	Inherited  bool   `json:"inherited"` // Not assigned explicitly, belongs to the room of its terminal
+}
+
+// RoomControlRequestDTO for POST /api/rooms/:room_id/control
+type RoomControlRequestDTO struct {
+	Action string `json:"action" binding:"required,oneof=on off" example:"off"`
+	ZoneID string `json:"zone_id,omitempty"` // Only the devices assigned to this zone
+}
+
+// RoomDeviceResultDTO reports what happened to one device during a group command
+type RoomDeviceResultDTO struct {
+	DeviceID string                 `json:"device_id"`
+	RemoteID string                 `json:"remote_id,omitempty"`
+	Name     string                 `json:"name"`
+	Status   string                 `json:"status" example:"succeeded"` // succeeded, failed, skipped
+	Sent     map[string]interface{} `json:"sent,omitempty" swaggertype:"object"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// RoomControlResultDTO summarizes a group command sent to a room or zone
+type RoomControlResultDTO struct {
+	RoomID    string                `json:"room_id"`
+	RoomName  string                `json:"room_name"`
+	ZoneID    string                `json:"zone_id,omitempty"`
+	Action    string                `json:"action" example:"off"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Skipped   int                   `json:"skipped"` // Door locks and devices without a power switch
+	Devices   []RoomDeviceResultDTO `json:"devices"`
+}
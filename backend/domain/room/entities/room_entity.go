@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+)
+
+// Room is a physical room ("Meeting Room 3"). Terminals reference it through their RoomID and
+// devices are assigned to it explicitly; a device without a room belongs to its terminal's room.
+type Room struct {
+	ID          string    `gorm:"type:varchar(255);primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Room model
+func (Room) TableName() string {
+	return "rooms"
+}
+
+// Zone is an area inside a room ("Stage", "Front row") that devices can be assigned to
+type Zone struct {
+	ID        string    `gorm:"type:char(36);primaryKey" json:"id"`
+	RoomID    string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_zone_room_name" json:"room_id"`
+	Name      string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_zone_room_name" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Zone model
+func (Zone) TableName() string {
+	return "zones"
+}
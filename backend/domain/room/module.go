@@ -0,0 +1,54 @@
+package room
+
+import (
+	"sensio/domain/common/utils"
+	"sensio/domain/room/controllers"
+	"sensio/domain/room/repositories"
+	"sensio/domain/room/usecases"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	terminal_repositories "sensio/domain/terminal/terminal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RoomModule struct {
+	Controller     *controllers.RoomController
+	RoomUseCase    *usecases.RoomUseCase
+	ControlUseCase *usecases.RoomControlUseCase
+}
+
+func NewRoomModule(
+	db *gorm.DB,
+	deviceRepo device_repositories.IDeviceRepository,
+	terminalRepo terminal_repositories.ITerminalRepository,
+	executor usecases.DeviceCommandExecutor,
+	capabilities usecases.CapabilityLookup,
+	auth usecases.AccessTokenProvider,
+) *RoomModule {
+	repo := repositories.NewRoomRepository(db)
+	roomUC := usecases.NewRoomUseCase(repo, deviceRepo, terminalRepo)
+	controlUC := usecases.NewRoomControlUseCase(roomUC, terminalRepo, executor, capabilities, auth, utils.GetConfig().RoomControlConcurrency)
+
+	return &RoomModule{
+		Controller:     controllers.NewRoomController(roomUC, controlUC),
+		RoomUseCase:    roomUC,
+		ControlUseCase: controlUC,
+	}
+}
+
+func (m *RoomModule) RegisterRoutes(protected *gin.RouterGroup) {
+	group := protected.Group("/api/rooms")
+	{
+		group.POST("", m.Controller.CreateRoom)
+		group.GET("", m.Controller.ListRooms)
+		group.GET("/:room_id", m.Controller.GetRoom)
+		group.PUT("/:room_id", m.Controller.UpdateRoom)
+		group.DELETE("/:room_id", m.Controller.DeleteRoom)
+		group.GET("/:room_id/devices", m.Controller.ListRoomDevices)
+		group.POST("/:room_id/control", m.Controller.ControlRoom)
+		group.POST("/:room_id/zones", m.Controller.CreateZone)
+		group.PUT("/:room_id/zones/:zone_id", m.Controller.UpdateZone)
+		group.DELETE("/:room_id/zones/:zone_id", m.Controller.DeleteZone)
+	}
+}
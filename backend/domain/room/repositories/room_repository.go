@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"sensio/domain/room/entities"
+
+	"gorm.io/gorm"
+)
+
+// IRoomRepository defines the interface for room and zone storage operations
+type IRoomRepository interface {
+	Save(room *entities.Room) error
+	GetByID(id string) (*entities.Room, error)
+	List() ([]entities.Room, error)
+	Delete(id string) error
+
+	SaveZone(zone *entities.Zone) error
+	GetZone(roomID, id string) (*entities.Zone, error)
+	GetZoneByID(id string) (*entities.Zone, error)
+	ListZones(roomID string) ([]entities.Zone, error)
+	DeleteZone(roomID, id string) error
+}
+
+// RoomRepository handles persistent storage of rooms and zones using GORM
+type RoomRepository struct {
+	db *gorm.DB
+}
+
+// NewRoomRepository creates a new instance of RoomRepository
+func NewRoomRepository(db *gorm.DB) *RoomRepository {
+	return &RoomRepository{db: db}
+}
+
+// Save persists a room to the database (Upsert)
+func (r *RoomRepository) Save(room *entities.Room) error {
+	return r.db.Save(room).Error
+}
+
+// GetByID retrieves a room by its ID
+func (r *RoomRepository) GetByID(id string) (*entities.Room, error) {
+	var room entities.Room
+	if err := r.db.Where("id = ?", id).First(&room).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// List retrieves every room ordered by name
+func (r *RoomRepository) List() ([]entities.Room, error) {
+	var rooms []entities.Room
+	err := r.db.Order("name ASC").Find(&rooms).Error
+	return rooms, err
+}
+
+// Delete removes a room together with its zones
+func (r *RoomRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&entities.Room{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("room_id = ?", id).Delete(&entities.Zone{}).Error
+	})
+}
+
+// SaveZone persists a zone to the database (Upsert)
+func (r *RoomRepository) SaveZone(zone *entities.Zone) error {
+	return r.db.Save(zone).Error
+}
+
+// GetZone retrieves a zone of a room
+func (r *RoomRepository) GetZone(roomID, id string) (*entities.Zone, error) {
+	var zone entities.Zone
+	if err := r.db.Where("id = ? AND room_id = ?", id, roomID).First(&zone).Error; err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// GetZoneByID retrieves a zone by its ID regardless of its room
+func (r *RoomRepository) GetZoneByID(id string) (*entities.Zone, error) {
+	var zone entities.Zone
+	if err := r.db.Where("id = ?", id).First(&zone).Error; err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// ListZones retrieves the zones of a room ordered by name
+func (r *RoomRepository) ListZones(roomID string) ([]entities.Zone, error) {
+	var zones []entities.Zone
+	err := r.db.Where("room_id = ?", roomID).Order("name ASC").Find(&zones).Error
+	return zones, err
+}
+
+// DeleteZone removes a zone of a room
+func (r *RoomRepository) DeleteZone(roomID, id string) error {
+	result := r.db.Where("id = ? AND room_id = ?", id, roomID).Delete(&entities.Zone{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
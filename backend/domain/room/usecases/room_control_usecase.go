@@ -0,0 +1,227 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sensio/domain/common/utils"
+	doorlock_entities "sensio/domain/doorlock/entities"
+	"sensio/domain/room/dtos"
+	"sensio/domain/room/entities"
+	device_entities "sensio/domain/terminal/device/entities"
+	terminal_repositories "sensio/domain/terminal/terminal/repositories"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	tuya_entities "sensio/domain/tuya/entities"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	DeviceStatusSucceeded = "succeeded"
+	DeviceStatusFailed    = "failed"
+	DeviceStatusSkipped   = "skipped"
+)
+
+// CapabilityLookup returns the writable functions of a device, nil when it has no specification
+type CapabilityLookup interface {
+	GetCapabilities(accessToken, deviceID string) (*tuya_entities.DeviceCapabilities, error)
+}
+
+// DeviceCommandExecutor sends commands to Tuya devices and IR remotes
+type DeviceCommandExecutor interface {
+	SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error)
+	SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error)
+}
+
+// AccessTokenProvider supplies a Tuya access token for calls made without a request token
+type AccessTokenProvider interface {
+	GetTuyaAccessToken() (string, error)
+}
+
+// lightCategories use switch_led as their power code
+var lightCategories = map[string]bool{"dj": true, "dd": true, "fwd": true, "xdd": true, "dc": true, "tgq": true, "tgkg": true}
+
+// acRemoteCategory is the only IR remote category with a power command (the AC scenes API)
+const acRemoteCategory = "infrared_ac"
+
+// RoomControlUseCase turns every device of a room or zone on or off at once
+type RoomControlUseCase struct {
+	rooms        *RoomUseCase
+	terminalRepo terminal_repositories.ITerminalRepository
+	executor     DeviceCommandExecutor
+	capabilities CapabilityLookup
+	auth         AccessTokenProvider
+	concurrency  int
+}
+
+// NewRoomControlUseCase creates a new instance of RoomControlUseCase
+func NewRoomControlUseCase(rooms *RoomUseCase, terminalRepo terminal_repositories.ITerminalRepository, executor DeviceCommandExecutor, capabilities CapabilityLookup, auth AccessTokenProvider, concurrency int) *RoomControlUseCase {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &RoomControlUseCase{
+		rooms:        rooms,
+		terminalRepo: terminalRepo,
+		executor:     executor,
+		capabilities: capabilities,
+		auth:         auth,
+		concurrency:  concurrency,
+	}
+}
+
+// ControlRoom sends action ("on" or "off") to every device of the room, or of one of its zones.
+// Door locks and IR remotes other than air conditioners are always skipped; a failing device
+// never stops the others.
+func (uc *RoomControlUseCase) ControlRoom(roomID, zoneID, action, accessToken string) (*dtos.RoomControlResultDTO, error) {
+	room, err := uc.rooms.find(roomID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.control(room, zoneID, action, accessToken)
+}
+
+// ControlRoomByName is ControlRoom for spoken requests: roomName is matched against room names
+// and ids, and an empty name means the room of the terminal.
+func (uc *RoomControlUseCase) ControlRoomByName(terminalID, roomName, action, accessToken string) (*dtos.RoomControlResultDTO, error) {
+	if strings.TrimSpace(roomName) != "" {
+		room, err := uc.rooms.findByName(roomName)
+		if err != nil {
+			return nil, err
+		}
+		return uc.control(room, "", action, accessToken)
+	}
+
+	terminal, err := uc.terminalRepo.GetByID(terminalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the room of terminal %s: %w", terminalID, err)
+	}
+	room, err := uc.rooms.find(terminal.RoomID)
+	if errors.Is(err, ErrRoomNotFound) {
+		// Terminals may point at a room nobody registered yet; its devices are still known
+		room = &entities.Room{ID: terminal.RoomID, Name: terminal.RoomID}
+	} else if err != nil {
+		return nil, err
+	}
+	return uc.control(room, "", action, accessToken)
+}
+
+func (uc *RoomControlUseCase) control(room *entities.Room, zoneID, action, accessToken string) (*dtos.RoomControlResultDTO, error) {
+	action = strings.ToLower(strings.TrimSpace(action))
+	if action != "on" && action != "off" {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "action", Message: "action must be 'on' or 'off'"},
+		})
+	}
+
+	devices, err := uc.rooms.roomDevices(room.ID, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" && uc.auth != nil {
+		if accessToken, err = uc.auth.GetTuyaAccessToken(); err != nil {
+			return nil, fmt.Errorf("failed to get access token: %w", err)
+		}
+	}
+
+	result := &dtos.RoomControlResultDTO{
+		RoomID:   room.ID,
+		RoomName: room.Name,
+		ZoneID:   zoneID,
+		Action:   action,
+		Devices:  make([]dtos.RoomDeviceResultDTO, len(devices)),
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, uc.concurrency)
+	for i := range devices {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			result.Devices[i] = uc.controlDevice(&devices[i], action == "on", accessToken)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, d := range result.Devices {
+		switch d.Status {
+		case DeviceStatusSucceeded:
+			result.Succeeded++
+		case DeviceStatusFailed:
+			result.Failed++
+		default:
+			result.Skipped++
+		}
+	}
+	utils.LogInfo("RoomControlUseCase: %s room %s zone=%q | succeeded=%d failed=%d skipped=%d", action, room.ID, zoneID, result.Succeeded, result.Failed, result.Skipped)
+	return result, nil
+}
+
+func (uc *RoomControlUseCase) controlDevice(device *device_entities.Device, on bool, accessToken string) dtos.RoomDeviceResultDTO {
+	item := dtos.RoomDeviceResultDTO{DeviceID: device.ID, RemoteID: device.RemoteID, Name: device.Name}
+	if doorlock_entities.IsLockCategory(device.Category) {
+		item.Status, item.Error = DeviceStatusSkipped, "door locks are never controlled in bulk"
+		return item
+	}
+
+	var success bool
+	var err error
+	if device.RemoteID != "" {
+		if device.RemoteCategory != acRemoteCategory && device.Category != acRemoteCategory {
+			item.Status, item.Error = DeviceStatusSkipped, "only air conditioner remotes are controlled in bulk"
+			return item
+		}
+		power := 0
+		if on {
+			power = 1
+		}
+		item.Sent = map[string]interface{}{"power": power}
+		success, err = uc.executor.SendIRACCommand(accessToken, device.ID, device.RemoteID, map[string]int{"power": power})
+	} else {
+		codes := uc.powerCodes(device, accessToken)
+		if len(codes) == 0 {
+			item.Status, item.Error = DeviceStatusSkipped, "device has no power switch"
+			return item
+		}
+		item.Sent = make(map[string]interface{}, len(codes))
+		commands := make([]tuya_dtos.TuyaCommandDTO, 0, len(codes))
+		for _, code := range codes {
+			item.Sent[code] = on
+			commands = append(commands, tuya_dtos.TuyaCommandDTO{Code: code, Value: on})
+		}
+		success, err = uc.executor.SendSwitchCommand(accessToken, device.ID, commands)
+	}
+
+	switch {
+	case err != nil:
+		utils.LogWarn("RoomControlUseCase: failed to control %s: %v", device.ID, err)
+		item.Status, item.Error = DeviceStatusFailed, err.Error()
+	case !success:
+		item.Status, item.Error = DeviceStatusFailed, "unsuccessful response from Tuya"
+	default:
+		item.Status = DeviceStatusSucceeded
+	}
+	return item
+}
+
+// powerCodes returns the Boolean switch functions of a device (every gang of a multi-switch),
+// falling back to the usual code of its category when the specification is unavailable
+func (uc *RoomControlUseCase) powerCodes(device *device_entities.Device, accessToken string) []string {
+	if uc.capabilities != nil {
+		if caps, err := uc.capabilities.GetCapabilities(accessToken, device.ID); err == nil && caps != nil {
+			var codes []string
+			for code, fn := range caps.Functions {
+				if fn.Type == "Boolean" && strings.HasPrefix(code, "switch") && !strings.Contains(code, "backlight") {
+					codes = append(codes, code)
+				}
+			}
+			sort.Strings(codes)
+			return codes
+		}
+	}
+	if lightCategories[device.Category] {
+		return []string{"switch_led"}
+	}
+	return []string{"switch_1"}
+}
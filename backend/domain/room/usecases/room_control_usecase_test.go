@@ -0,0 +1,234 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/room/entities"
+	"sensio/domain/room/repositories"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	terminal_entities "sensio/domain/terminal/terminal/entities"
+	terminal_repositories "sensio/domain/terminal/terminal/repositories"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	tuya_entities "sensio/domain/tuya/entities"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeRoomRepo only implements the lookups used by room control
+type fakeRoomRepo struct {
+	repositories.IRoomRepository
+	rooms []entities.Room
+	zones []entities.Zone
+}
+
+func (r *fakeRoomRepo) GetByID(id string) (*entities.Room, error) {
+	for _, room := range r.rooms {
+		if room.ID == id {
+			return &room, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRoomRepo) List() ([]entities.Room, error) {
+	return r.rooms, nil
+}
+
+func (r *fakeRoomRepo) GetZone(roomID, id string) (*entities.Zone, error) {
+	for _, zone := range r.zones {
+		if zone.ID == id && zone.RoomID == roomID {
+			return &zone, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeDeviceRepo resolves room membership the way the SQL query does
+type fakeDeviceRepo struct {
+	device_repositories.IDeviceRepository
+	devices []device_entities.Device
+}
+
+func (r *fakeDeviceRepo) GetByRoom(roomID string, terminalIDs []string) ([]device_entities.Device, error) {
+	var out []device_entities.Device
+	for _, d := range r.devices {
+		if d.RoomID == roomID {
+			out = append(out, d)
+			continue
+		}
+		for _, id := range terminalIDs {
+			if d.RoomID == "" && d.TerminalID == id {
+				out = append(out, d)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeDeviceRepo) GetByZoneID(zoneID string) ([]device_entities.Device, error) {
+	var out []device_entities.Device
+	for _, d := range r.devices {
+		if d.ZoneID == zoneID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+type fakeTerminalRepo struct {
+	terminal_repositories.ITerminalRepository
+	terminals []terminal_entities.Terminal
+}
+
+func (r *fakeTerminalRepo) GetByID(id string) (*terminal_entities.Terminal, error) {
+	for _, t := range r.terminals {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTerminalRepo) GetByRoomID(roomID string) ([]terminal_entities.Terminal, error) {
+	var out []terminal_entities.Terminal
+	for _, t := range r.terminals {
+		if t.RoomID == roomID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// fakeHome records the commands sent to each device
+type fakeHome struct {
+	mu      sync.Mutex
+	sent    map[string]interface{}
+	failing map[string]bool
+}
+
+func (h *fakeHome) SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failing[deviceID] {
+		return false, errors.New("device offline")
+	}
+	values := map[string]interface{}{}
+	for _, c := range commands {
+		values[c.Code] = c.Value
+	}
+	h.sent[deviceID] = values
+	return true, nil
+}
+
+func (h *fakeHome) SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sent[remoteID] = params
+	return true, nil
+}
+
+type fakeCapabilities map[string][]string
+
+func (c fakeCapabilities) GetCapabilities(accessToken, deviceID string) (*tuya_entities.DeviceCapabilities, error) {
+	codes, ok := c[deviceID]
+	if !ok {
+		return nil, errors.New("no specification")
+	}
+	caps := &tuya_entities.DeviceCapabilities{DeviceID: deviceID, Functions: map[string]tuya_entities.DeviceCapability{}}
+	for _, code := range codes {
+		typ := "Boolean"
+		if code == "bright_value_v2" {
+			typ = "Integer"
+		}
+		caps.Functions[code] = tuya_entities.DeviceCapability{Code: code, Type: typ}
+	}
+	return caps, nil
+}
+
+func newRoomControlFixture() (*RoomControlUseCase, *fakeHome) {
+	home := &fakeHome{sent: map[string]interface{}{}, failing: map[string]bool{"plug": true}}
+	rooms := &fakeRoomRepo{
+		rooms: []entities.Room{{ID: "mr3", Name: "Meeting Room 3"}, {ID: "lobby", Name: "Lobby"}},
+		zones: []entities.Zone{{ID: "stage", RoomID: "mr3", Name: "Stage"}},
+	}
+	devices := &fakeDeviceRepo{devices: []device_entities.Device{
+		{ID: "lamp", TerminalID: "term-1", Name: "Ceiling Lamp", Category: "dj"},
+		{ID: "gang", TerminalID: "term-2", Name: "Wall Switch", RoomID: "mr3", ZoneID: "stage"},
+		{ID: "ir", TerminalID: "term-1", Name: "AC", RemoteID: "ac-remote", RemoteCategory: "infrared_ac"},
+		{ID: "ir-tv", TerminalID: "term-1", Name: "TV", RemoteID: "tv-remote", RemoteCategory: "infrared_tv"},
+		{ID: "plug", TerminalID: "term-1", Name: "Plug"},
+		{ID: "door", TerminalID: "term-1", Name: "Front Door", Category: "jtmspro"},
+		{ID: "moved", TerminalID: "term-1", Name: "Lobby Lamp", RoomID: "lobby"},
+	}}
+	terminals := &fakeTerminalRepo{terminals: []terminal_entities.Terminal{
+		{ID: "term-1", RoomID: "mr3"},
+		{ID: "term-2", RoomID: "lobby"},
+	}}
+	caps := fakeCapabilities{"gang": {"switch_1", "switch_2", "switch_backlight"}, "lamp": {"switch_led", "bright_value_v2"}}
+
+	roomUC := NewRoomUseCase(rooms, devices, terminals)
+	return NewRoomControlUseCase(roomUC, terminals, home, caps, nil, 2), home
+}
+
+func TestControlRoom_FansOutAndReportsEachDevice(t *testing.T) {
+	uc, home := newRoomControlFixture()
+
+	result, err := uc.ControlRoom("mr3", "", "OFF", "token")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Meeting Room 3", result.RoomName)
+	assert.Equal(t, "off", result.Action)
+	assert.Equal(t, 3, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 2, result.Skipped, "door locks and non-AC remotes are never controlled in bulk")
+
+	statuses := map[string]string{}
+	for _, d := range result.Devices {
+		statuses[d.DeviceID] = d.Status
+	}
+	assert.Equal(t, map[string]string{
+		"lamp":  DeviceStatusSucceeded,
+		"gang":  DeviceStatusSucceeded,
+		"ir":    DeviceStatusSucceeded,
+		"plug":  DeviceStatusFailed,
+		"door":  DeviceStatusSkipped,
+		"ir-tv": DeviceStatusSkipped,
+	}, statuses, "the device moved to the lobby no longer belongs to its terminal's room")
+
+	assert.Equal(t, map[string]interface{}{"switch_led": false}, home.sent["lamp"])
+	assert.Equal(t, map[string]interface{}{"switch_1": false, "switch_2": false}, home.sent["gang"])
+	assert.Equal(t, map[string]int{"power": 0}, home.sent["ac-remote"])
+	assert.NotContains(t, home.sent, "door")
+	assert.NotContains(t, home.sent, "tv-remote", "a TV remote has no AC power command")
+}
+
+func TestControlRoom_ZoneAndSpokenRoom(t *testing.T) {
+	uc, _ := newRoomControlFixture()
+
+	result, err := uc.ControlRoom("mr3", "stage", "on", "token")
+	require.NoError(t, err)
+	require.Len(t, result.Devices, 1)
+	assert.Equal(t, "gang", result.Devices[0].DeviceID)
+
+	_, err = uc.ControlRoom("mr3", "missing", "on", "token")
+	assert.ErrorIs(t, err, ErrZoneNotFound)
+
+	_, err = uc.ControlRoom("mr3", "", "dim", "token")
+	assert.Error(t, err)
+
+	byName, err := uc.ControlRoomByName("term-2", "lobby", "on", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "lobby", byName.RoomID)
+
+	// "this room" is the room of the terminal that heard the request
+	here, err := uc.ControlRoomByName("term-1", "", "on", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "mr3", here.RoomID)
+
+	_, err = uc.ControlRoomByName("term-1", "Kitchen", "on", "token")
+	assert.ErrorIs(t, err, ErrRoomNotFound)
+}
@@ -0,0 +1,361 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/utils"
+	"sensio/domain/room/dtos"
+	"sensio/domain/room/entities"
+	"sensio/domain/room/repositories"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	terminal_repositories "sensio/domain/terminal/terminal/repositories"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRoomNotFound is returned when a room does not exist
+	ErrRoomNotFound = errors.New("room not found")
+	// ErrZoneNotFound is returned when a zone does not exist or belongs to another room
+	ErrZoneNotFound = errors.New("zone not found")
+)
+
+const maxRoomNameLength = 100
+
+// RoomUseCase manages rooms, their zones and the devices that belong to them
+type RoomUseCase struct {
+	repo         repositories.IRoomRepository
+	deviceRepo   device_repositories.IDeviceRepository
+	terminalRepo terminal_repositories.ITerminalRepository
+}
+
+// NewRoomUseCase creates a new instance of RoomUseCase
+func NewRoomUseCase(repo repositories.IRoomRepository, deviceRepo device_repositories.IDeviceRepository, terminalRepo terminal_repositories.ITerminalRepository) *RoomUseCase {
+	return &RoomUseCase{
+		repo:         repo,
+		deviceRepo:   deviceRepo,
+		terminalRepo: terminalRepo,
+	}
+}
+
+// CreateRoom registers a room. The ID may be given to adopt a room_id terminals already use.
+func (uc *RoomUseCase) CreateRoom(req *dtos.CreateRoomRequestDTO) (*dtos.RoomResponseDTO, error) {
+	name, err := validateName("name", req.Name)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.TrimSpace(req.ID)
+	if id == "" {
+		id = uuid.New().String()
+	} else if _, err := uc.repo.GetByID(id); err == nil {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "id", Message: "a room with this id already exists"},
+		})
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := uc.ensureUniqueName(name, id); err != nil {
+		return nil, err
+	}
+
+	room := &entities.Room{ID: id, Name: name, Description: strings.TrimSpace(req.Description)}
+	if err := uc.repo.Save(room); err != nil {
+		return nil, err
+	}
+	return toRoomDTO(room, nil), nil
+}
+
+// ListRooms returns every room with its zones
+func (uc *RoomUseCase) ListRooms() ([]dtos.RoomResponseDTO, error) {
+	rooms, err := uc.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.RoomResponseDTO, 0, len(rooms))
+	for i := range rooms {
+		zones, err := uc.repo.ListZones(rooms[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *toRoomDTO(&rooms[i], zones))
+	}
+	return result, nil
+}
+
+// GetRoom returns a room with its zones
+func (uc *RoomUseCase) GetRoom(id string) (*dtos.RoomResponseDTO, error) {
+	room, err := uc.find(id)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := uc.repo.ListZones(room.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toRoomDTO(room, zones), nil
+}
+
+// UpdateRoom renames a room and updates its description
+func (uc *RoomUseCase) UpdateRoom(id string, req *dtos.UpdateRoomRequestDTO) (*dtos.RoomResponseDTO, error) {
+	room, err := uc.find(id)
+	if err != nil {
+		return nil, err
+	}
+	name, err := validateName("name", req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.ensureUniqueName(name, room.ID); err != nil {
+		return nil, err
+	}
+	room.Name = name
+	room.Description = strings.TrimSpace(req.Description)
+	if err := uc.repo.Save(room); err != nil {
+		return nil, err
+	}
+	return uc.GetRoom(room.ID)
+}
+
+// DeleteRoom removes a room and its zones. Its devices fall back to the room of their terminal.
+func (uc *RoomUseCase) DeleteRoom(id string) error {
+	if err := uc.repo.Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoomNotFound
+		}
+		return err
+	}
+	return uc.deviceRepo.ClearRoomAssignment(id)
+}
+
+// CreateZone adds a zone to a room
+func (uc *RoomUseCase) CreateZone(roomID string, req *dtos.ZoneRequestDTO) (*dtos.ZoneResponseDTO, error) {
+	if _, err := uc.find(roomID); err != nil {
+		return nil, err
+	}
+	name, err := validateName("name", req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.ensureUniqueZoneName(roomID, name, ""); err != nil {
+		return nil, err
+	}
+	zone := &entities.Zone{ID: uuid.New().String(), RoomID: roomID, Name: name}
+	if err := uc.repo.SaveZone(zone); err != nil {
+		return nil, err
+	}
+	dto := toZoneDTO(zone)
+	return &dto, nil
+}
+
+// UpdateZone renames a zone of a room
+func (uc *RoomUseCase) UpdateZone(roomID, zoneID string, req *dtos.ZoneRequestDTO) (*dtos.ZoneResponseDTO, error) {
+	zone, err := uc.findZone(roomID, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	name, err := validateName("name", req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.ensureUniqueZoneName(roomID, name, zone.ID); err != nil {
+		return nil, err
+	}
+	zone.Name = name
+	if err := uc.repo.SaveZone(zone); err != nil {
+		return nil, err
+	}
+	dto := toZoneDTO(zone)
+	return &dto, nil
+}
+
+// DeleteZone removes a zone of a room. Its devices stay in the room.
+func (uc *RoomUseCase) DeleteZone(roomID, zoneID string) error {
+	if err := uc.repo.DeleteZone(roomID, zoneID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrZoneNotFound
+		}
+		return err
+	}
+	return uc.deviceRepo.ClearZoneAssignment(zoneID)
+}
+
+// ListRoomDevices returns the devices of a room, or of one of its zones when zoneID is set
+func (uc *RoomUseCase) ListRoomDevices(roomID, zoneID string) ([]dtos.RoomDeviceDTO, error) {
+	if _, err := uc.find(roomID); err != nil {
+		return nil, err
+	}
+	devices, err := uc.roomDevices(roomID, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.RoomDeviceDTO, 0, len(devices))
+	for _, d := range devices {
+		result = append(result, dtos.RoomDeviceDTO{
+			ID:         d.ID,
+			TerminalID: d.TerminalID,
+			Name:       d.Name,
+			Category:   d.Category,
+			RemoteID:   d.RemoteID,
+			ZoneID:     d.ZoneID,
+			Inherited:  d.RoomID == "",
+		})
+	}
+	return result, nil
+}
+
+// ResolveAssignment checks that the room and zone exist and that the zone lies in the room.
+// It returns the room of the assignment, which is the room of the zone when roomID is empty.
+func (uc *RoomUseCase) ResolveAssignment(roomID, zoneID string) (string, error) {
+	if zoneID != "" {
+		zone, err := uc.repo.GetZoneByID(zoneID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", assignmentError("zone_id", "zone does not exist")
+			}
+			return "", err
+		}
+		if roomID != "" && zone.RoomID != roomID {
+			return "", assignmentError("zone_id", "zone does not belong to the room")
+		}
+		roomID = zone.RoomID
+	}
+	if _, err := uc.repo.GetByID(roomID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", assignmentError("room_id", "room does not exist")
+		}
+		return "", err
+	}
+	return roomID, nil
+}
+
+// roomDevices returns the devices of a zone, or of the room: the ones assigned to it plus the
+// unassigned devices of the terminals placed in it
+func (uc *RoomUseCase) roomDevices(roomID, zoneID string) ([]device_entities.Device, error) {
+	if zoneID != "" {
+		if _, err := uc.findZone(roomID, zoneID); err != nil {
+			return nil, err
+		}
+		return uc.deviceRepo.GetByZoneID(zoneID)
+	}
+
+	terminals, err := uc.terminalRepo.GetByRoomID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	terminalIDs := make([]string, 0, len(terminals))
+	for _, t := range terminals {
+		terminalIDs = append(terminalIDs, t.ID)
+	}
+	return uc.deviceRepo.GetByRoom(roomID, terminalIDs)
+}
+
+// findByName returns the room whose name or id matches, ignoring case
+func (uc *RoomUseCase) findByName(name string) (*entities.Room, error) {
+	name = strings.TrimSpace(name)
+	rooms, err := uc.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range rooms {
+		if strings.EqualFold(rooms[i].Name, name) || strings.EqualFold(rooms[i].ID, name) {
+			return &rooms[i], nil
+		}
+	}
+	return nil, ErrRoomNotFound
+}
+
+func (uc *RoomUseCase) find(id string) (*entities.Room, error) {
+	room, err := uc.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, err
+	}
+	return room, nil
+}
+
+func (uc *RoomUseCase) findZone(roomID, zoneID string) (*entities.Zone, error) {
+	zone, err := uc.repo.GetZone(roomID, zoneID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrZoneNotFound
+		}
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (uc *RoomUseCase) ensureUniqueName(name, exceptID string) error {
+	rooms, err := uc.repo.List()
+	if err != nil {
+		return err
+	}
+	for _, r := range rooms {
+		if r.ID != exceptID && strings.EqualFold(r.Name, name) {
+			return utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+				{Field: "name", Message: "a room with this name already exists"},
+			})
+		}
+	}
+	return nil
+}
+
+func (uc *RoomUseCase) ensureUniqueZoneName(roomID, name, exceptID string) error {
+	zones, err := uc.repo.ListZones(roomID)
+	if err != nil {
+		return err
+	}
+	for _, z := range zones {
+		if z.ID != exceptID && strings.EqualFold(z.Name, name) {
+			return utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+				{Field: "name", Message: "a zone with this name already exists in the room"},
+			})
+		}
+	}
+	return nil
+}
+
+func validateName(field, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > maxRoomNameLength {
+		return "", utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: field, Message: field + " is required and must be at most 100 characters"},
+		})
+	}
+	return value, nil
+}
+
+func assignmentError(field, message string) error {
+	return utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+		{Field: field, Message: message},
+	})
+}
+
+func toRoomDTO(room *entities.Room, zones []entities.Zone) *dtos.RoomResponseDTO {
+	dto := &dtos.RoomResponseDTO{
+		ID:          room.ID,
+		Name:        room.Name,
+		Description: room.Description,
+		Zones:       make([]dtos.ZoneResponseDTO, 0, len(zones)),
+		CreatedAt:   room.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   room.UpdatedAt.Format(time.RFC3339),
+	}
+	for i := range zones {
+		dto.Zones = append(dto.Zones, toZoneDTO(&zones[i]))
+	}
+	return dto
+}
+
+func toZoneDTO(zone *entities.Zone) dtos.ZoneResponseDTO {
+	return dtos.ZoneResponseDTO{
+		ID:        zone.ID,
+		RoomID:    zone.RoomID,
+		Name:      zone.Name,
+		CreatedAt: zone.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: zone.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -1,11 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"sensio/domain/common/dtos"
 	"sensio/domain/common/utils"
 	scene_dtos "sensio/domain/scene/dtos"
-	"sensio/domain/scene/entities"
 	"sensio/domain/scene/usecases"
 
 	"github.com/gin-gonic/gin"
@@ -48,13 +48,19 @@ func (c *SceneAddController) AddScene(ctx *gin.Context) {
 		return
 	}
 
-	actions := make(entities.Actions, len(req.Actions))
-	for i, a := range req.Actions {
-		actions[i] = entities.Action(a)
-	}
+	actions := toActionEntities(req.Actions)
 
-	sceneID, err := c.useCase.AddScene(terminalID, req.Name, actions)
+	sceneID, err := c.useCase.AddScene(terminalID, req.Name, actions, req.ErrorPolicy)
 	if err != nil {
+		var valErr *utils.ValidationError
+		if errors.As(err, &valErr) {
+			ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+				Status:  false,
+				Message: valErr.Message,
+				Details: valErr.Details,
+			})
+			return
+		}
 		utils.LogError("SceneAddController.AddScene: %v", err)
 		ctx.JSON(http.StatusInternalServerError, dtos.StandardResponse{
 			Status:  false,
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"sensio/domain/common/dtos"
 	"sensio/domain/common/utils"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/entities"
 	"sensio/domain/scene/usecases"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +15,9 @@ type SceneControlController struct {
 	useCase *usecases.ControlSceneUseCase
 }
 
+// Force Swaggo to detect DTOs
+var _ = scene_dtos.SceneRunReportDTO{}
+
 func NewSceneControlController(useCase *usecases.ControlSceneUseCase) *SceneControlController {
 	return &SceneControlController{
 		useCase: useCase,
@@ -21,12 +26,14 @@ func NewSceneControlController(useCase *usecases.ControlSceneUseCase) *SceneCont
 
 // ControlScene handles GET /api/terminal/:id/scenes/:scene_id/control
 // @Summary Apply/Trigger a scene
-// @Description Trigger all actions defined in a specific scene
+// @Description Run the actions of a scene (honouring delays, parallel groups, conditions, retries and the error policy) and return a per-action report. The report is also stored as run history and published to users/{mac}/{env}/scene/run.
 // @Tags 03. Scenes
 // @Produce json
 // @Param id path string true "Terminal UUID"
 // @Param scene_id path string true "Scene UUID"
-// @Success 200 {object} dtos.StandardResponse "Scene applied"
+// @Success 200 {object} dtos.StandardResponse{data=scene_dtos.SceneRunReportDTO} "Scene applied"
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.StandardResponse{data=scene_dtos.SceneRunReportDTO} "Some actions failed"
 // @Security BearerAuth
 // @Router /api/terminal/{id}/scenes/{scene_id}/control [get]
 func (c *SceneControlController) ControlScene(ctx *gin.Context) {
@@ -34,8 +41,17 @@ func (c *SceneControlController) ControlScene(ctx *gin.Context) {
 	id := ctx.Param("scene_id")
 	accessToken := ctx.GetString("access_token")
 
-	if err := c.useCase.ControlScene(terminalID, id, accessToken); err != nil {
+	report, err := c.useCase.RunScene(terminalID, id, accessToken, entities.SceneRunSourceAPI)
+	if err != nil {
 		utils.LogError("SceneControlController.ControlScene: %v", err)
+		if report != nil {
+			ctx.JSON(http.StatusInternalServerError, dtos.StandardResponse{
+				Status:  false,
+				Message: "Scene applied with errors",
+				Data:    report,
+			})
+			return
+		}
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "record not found" {
 			statusCode = http.StatusNotFound
@@ -50,5 +66,6 @@ func (c *SceneControlController) ControlScene(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dtos.StandardResponse{
 		Status:  true,
 		Message: "Scene applied successfully",
+		Data:    report,
 	})
 }
@@ -62,6 +62,8 @@ func toSceneItemDTOs(scenes []entities.Scene) []scene_dtos.SceneItemDTO {
 			ID:      s.ID,
 			Name:    s.Name,
 			Actions: toActionDTOs(s.Actions),
+
+			ErrorPolicy: s.ErrorPolicy,
 		}
 	}
 	return result
@@ -49,6 +49,8 @@ func (c *SceneListController) ListScenes(ctx *gin.Context) {
 			TerminalID: s.TerminalID,
 			Name:       s.Name,
 			Actions:    toActionDTOs(s.Actions),
+
+			ErrorPolicy: s.ErrorPolicy,
 		}
 	}
 
@@ -63,11 +65,45 @@ func toActionDTOs(actions entities.Actions) []scene_dtos.ActionDTO {
 	result := make([]scene_dtos.ActionDTO, len(actions))
 	for i, a := range actions {
 		result[i] = scene_dtos.ActionDTO{
-			DeviceID: a.DeviceID,
-			Code:     a.Code,
-			RemoteID: a.RemoteID,
-			Topic:    a.Topic,
-			Value:    a.Value,
+			DeviceID:      a.DeviceID,
+			Code:          a.Code,
+			RemoteID:      a.RemoteID,
+			Topic:         a.Topic,
+			RoomID:        a.RoomID,
+			ZoneID:        a.ZoneID,
+			Value:         a.Value,
+			DelayMs:       a.DelayMs,
+			ParallelGroup: a.ParallelGroup,
+			Retries:       a.Retries,
+			RetryDelayMs:  a.RetryDelayMs,
+		}
+		if a.Condition != nil {
+			cond := scene_dtos.ActionConditionDTO(*a.Condition)
+			result[i].Condition = &cond
+		}
+	}
+	return result
+}
+
+func toActionEntities(actions []scene_dtos.ActionDTO) entities.Actions {
+	result := make(entities.Actions, len(actions))
+	for i, a := range actions {
+		result[i] = entities.Action{
+			DeviceID:      a.DeviceID,
+			Code:          a.Code,
+			RemoteID:      a.RemoteID,
+			Topic:         a.Topic,
+			RoomID:        a.RoomID,
+			ZoneID:        a.ZoneID,
+			Value:         a.Value,
+			DelayMs:       a.DelayMs,
+			ParallelGroup: a.ParallelGroup,
+			Retries:       a.Retries,
+			RetryDelayMs:  a.RetryDelayMs,
+		}
+		if a.Condition != nil {
+			cond := entities.ActionCondition(*a.Condition)
+			result[i].Condition = &cond
 		}
 	}
 	return result
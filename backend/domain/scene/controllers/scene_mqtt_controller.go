@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/entities"
+	"sensio/domain/scene/usecases"
+	"strings"
+)
+
+// SceneMqttController exposes scene listing and triggering to terminals over the MQTT RPC router
+type SceneMqttController struct {
+	listUseCase    *usecases.GetAllScenesUseCase
+	controlUseCase *usecases.ControlSceneUseCase
+	tokens         usecases.AccessTokenProvider
+}
+
+func NewSceneMqttController(listUseCase *usecases.GetAllScenesUseCase, controlUseCase *usecases.ControlSceneUseCase, tokens usecases.AccessTokenProvider) *SceneMqttController {
+	return &SceneMqttController{
+		listUseCase:    listUseCase,
+		controlUseCase: controlUseCase,
+		tokens:         tokens,
+	}
+}
+
+// RegisterMqttRoutes routes users/{mac}/{env}/rpc/scene/list and users/{mac}/{env}/rpc/scene/run
+func (c *SceneMqttController) RegisterMqttRoutes(router *infrastructure.MqttRpcRouter) error {
+	if router == nil {
+		return nil
+	}
+	if err := router.HandleTerminal("users/{mac}/{env}/rpc/scene/list", c.ListScenes); err != nil {
+		return err
+	}
+	return router.HandleTerminal("users/{mac}/{env}/rpc/scene/run", c.RunScene)
+}
+
+// ListScenes returns the scenes of the calling terminal
+func (c *SceneMqttController) ListScenes(req *infrastructure.MqttRpcRequest) (*infrastructure.MqttRpcResult, error) {
+	scenes, err := c.listUseCase.ListScenes(req.Terminal.ID)
+	if err != nil {
+		utils.LogError("SceneMqttController.ListScenes: %v", err)
+		return nil, err
+	}
+
+	response := make([]scene_dtos.SceneResponseDTO, len(scenes))
+	for i, s := range scenes {
+		response[i] = scene_dtos.SceneResponseDTO{
+			ID:          s.ID,
+			TerminalID:  s.TerminalID,
+			Name:        s.Name,
+			Actions:     toActionDTOs(s.Actions),
+			ErrorPolicy: s.ErrorPolicy,
+		}
+	}
+	return &infrastructure.MqttRpcResult{Message: "Scenes retrieved successfully", Data: response}, nil
+}
+
+// RunScene runs a scene of the calling terminal and replies with its run report
+func (c *SceneMqttController) RunScene(req *infrastructure.MqttRpcRequest) (*infrastructure.MqttRpcResult, error) {
+	var body scene_dtos.SceneRunMqttRequestDTO
+	if err := req.Bind(&body); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(body.SceneID) == "" {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "scene_id", Message: "scene_id is required"},
+		})
+	}
+
+	accessToken := ""
+	if c.tokens != nil {
+		token, err := c.tokens.GetTuyaAccessToken()
+		if err != nil {
+			utils.LogWarn("SceneMqttController.RunScene: Failed to get Tuya access token: %v", err)
+		}
+		accessToken = token
+	}
+
+	report, err := c.controlUseCase.RunScene(req.Terminal.ID, body.SceneID, accessToken, entities.SceneRunSourceMqtt)
+	if err != nil {
+		utils.LogError("SceneMqttController.RunScene: %v", err)
+		if report != nil {
+			return nil, &infrastructure.MqttRpcError{
+				Code:    infrastructure.MqttRpcCodeInternal,
+				Message: "Scene applied with errors",
+				Data:    report,
+			}
+		}
+		if err.Error() == "record not found" {
+			return nil, infrastructure.NewMqttRpcError(infrastructure.MqttRpcCodeNotFound, "Scene not found")
+		}
+		return nil, err
+	}
+	return &infrastructure.MqttRpcResult{Message: "Scene applied successfully", Data: report}, nil
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SceneRunsController struct {
+	useCase *usecases.GetSceneRunsUseCase
+}
+
+// Force Swaggo to detect DTOs
+var _ = scene_dtos.SceneRunReportDTO{}
+
+func NewSceneRunsController(useCase *usecases.GetSceneRunsUseCase) *SceneRunsController {
+	return &SceneRunsController{
+		useCase: useCase,
+	}
+}
+
+// ListRuns handles GET /api/terminal/:id/scenes/:scene_id/runs
+// @Summary List scene run history
+// @Description Retrieve the most recent executions of a scene with their per-action results, newest first
+// @Tags 03. Scenes
+// @Produce json
+// @Param id path string true "Terminal UUID"
+// @Param scene_id path string true "Scene UUID"
+// @Param limit query int false "Maximum number of runs" default(20)
+// @Success 200 {object} dtos.StandardResponse{data=[]scene_dtos.SceneRunReportDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/terminal/{id}/scenes/{scene_id}/runs [get]
+func (c *SceneRunsController) ListRuns(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	runs, err := c.useCase.ListRuns(ctx.Param("id"), ctx.Param("scene_id"), limit)
+	if err != nil {
+		c.respondError(ctx, "ListRuns", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Scene runs retrieved successfully",
+		Data:    runs,
+	})
+}
+
+// GetRun handles GET /api/terminal/:id/scenes/:scene_id/runs/:run_id
+// @Summary Get a scene run report
+// @Tags 03. Scenes
+// @Produce json
+// @Param id path string true "Terminal UUID"
+// @Param scene_id path string true "Scene UUID"
+// @Param run_id path string true "Run UUID"
+// @Success 200 {object} dtos.StandardResponse{data=scene_dtos.SceneRunReportDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/terminal/{id}/scenes/{scene_id}/runs/{run_id} [get]
+func (c *SceneRunsController) GetRun(ctx *gin.Context) {
+	run, err := c.useCase.GetRun(ctx.Param("id"), ctx.Param("scene_id"), ctx.Param("run_id"))
+	if err != nil {
+		c.respondError(ctx, "GetRun", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Scene run retrieved successfully",
+		Data:    run,
+	})
+}
+
+func (c *SceneRunsController) respondError(ctx *gin.Context, op string, err error) {
+	statusCode := http.StatusInternalServerError
+	if err.Error() == "record not found" {
+		statusCode = http.StatusNotFound
+	} else {
+		utils.LogError("SceneRunsController.%s: %v", op, err)
+	}
+	ctx.JSON(statusCode, dtos.StandardResponse{
+		Status:  false,
+		Message: http.StatusText(statusCode),
+	})
+}
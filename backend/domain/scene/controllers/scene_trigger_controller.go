@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/usecases"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SceneTriggerController exposes CRUD and run history for scheduled scene triggers
+type SceneTriggerController struct {
+	useCase *usecases.SceneTriggerUseCase
+}
+
+// Force Swaggo to detect DTOs
+var _ = scene_dtos.SceneTriggerRequestDTO{}
+
+func NewSceneTriggerController(useCase *usecases.SceneTriggerUseCase) *SceneTriggerController {
+	return &SceneTriggerController{
+		useCase: useCase,
+	}
+}
+
+// CreateTrigger handles POST /api/scenes/:terminal_id/triggers
+// @Summary Create a scene trigger
+// @Description Schedule a scene with a cron expression, a sunrise/sunset event or a one-shot delay
+// @Tags 03. Scenes
+// @Accept json
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param trigger body scene_dtos.SceneTriggerRequestDTO true "Trigger configuration"
+// @Success 201 {object} dtos.StandardResponse{data=scene_dtos.SceneTriggerIDResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/scenes/{terminal_id}/triggers [post]
+func (c *SceneTriggerController) CreateTrigger(ctx *gin.Context) {
+	var req scene_dtos.SceneTriggerRequestDTO
+	if !bindTriggerRequest(ctx, &req) {
+		return
+	}
+
+	result, err := c.useCase.CreateTrigger(ctx.Param("terminal_id"), req)
+	if err != nil {
+		respondTriggerError(ctx, "CreateTrigger", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.StandardResponse{
+		Status:  true,
+		Message: "Trigger created successfully",
+		Data:    result,
+	})
+}
+
+// ListTriggers handles GET /api/scenes/:terminal_id/triggers
+// @Summary List scene triggers
+// @Description Retrieve all scheduled triggers configured for a terminal
+// @Tags 03. Scenes
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Success 200 {object} dtos.StandardResponse{data=[]scene_dtos.SceneTriggerResponseDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/scenes/{terminal_id}/triggers [get]
+func (c *SceneTriggerController) ListTriggers(ctx *gin.Context) {
+	result, err := c.useCase.ListTriggers(ctx.Param("terminal_id"))
+	if err != nil {
+		respondTriggerError(ctx, "ListTriggers", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Triggers retrieved successfully",
+		Data:    result,
+	})
+}
+
+// GetTrigger handles GET /api/scenes/:terminal_id/triggers/:trigger_id
+// @Summary Get a scene trigger
+// @Tags 03. Scenes
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param trigger_id path string true "Trigger UUID"
+// @Success 200 {object} dtos.StandardResponse{data=scene_dtos.SceneTriggerResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/scenes/{terminal_id}/triggers/{trigger_id} [get]
+func (c *SceneTriggerController) GetTrigger(ctx *gin.Context) {
+	result, err := c.useCase.GetTrigger(ctx.Param("terminal_id"), ctx.Param("trigger_id"))
+	if err != nil {
+		respondTriggerError(ctx, "GetTrigger", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Trigger retrieved successfully",
+		Data:    result,
+	})
+}
+
+// UpdateTrigger handles PUT /api/scenes/:terminal_id/triggers/:trigger_id
+// @Summary Update a scene trigger
+// @Description Replace a trigger's schedule; the next run is recomputed from now
+// @Tags 03. Scenes
+// @Accept json
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param trigger_id path string true "Trigger UUID"
+// @Param trigger body scene_dtos.SceneTriggerRequestDTO true "Trigger configuration"
+// @Success 200 {object} dtos.StandardResponse{data=scene_dtos.SceneTriggerIDResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/scenes/{terminal_id}/triggers/{trigger_id} [put]
+func (c *SceneTriggerController) UpdateTrigger(ctx *gin.Context) {
+	var req scene_dtos.SceneTriggerRequestDTO
+	if !bindTriggerRequest(ctx, &req) {
+		return
+	}
+
+	result, err := c.useCase.UpdateTrigger(ctx.Param("terminal_id"), ctx.Param("trigger_id"), req)
+	if err != nil {
+		respondTriggerError(ctx, "UpdateTrigger", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Trigger updated successfully",
+		Data:    result,
+	})
+}
+
+// DeleteTrigger handles DELETE /api/scenes/:terminal_id/triggers/:trigger_id
+// @Summary Delete a scene trigger
+// @Tags 03. Scenes
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param trigger_id path string true "Trigger UUID"
+// @Success 200 {object} dtos.StandardResponse "Trigger deleted"
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/scenes/{terminal_id}/triggers/{trigger_id} [delete]
+func (c *SceneTriggerController) DeleteTrigger(ctx *gin.Context) {
+	if err := c.useCase.DeleteTrigger(ctx.Param("terminal_id"), ctx.Param("trigger_id")); err != nil {
+		respondTriggerError(ctx, "DeleteTrigger", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Trigger deleted successfully",
+	})
+}
+
+// ListTriggerRuns handles GET /api/scenes/:terminal_id/triggers/:trigger_id/runs
+// @Summary List trigger run history
+// @Description Retrieve the most recent executions of a trigger (succeeded, failed or missed), newest first
+// @Tags 03. Scenes
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param trigger_id path string true "Trigger UUID"
+// @Param limit query int false "Maximum number of runs" default(50)
+// @Success 200 {object} dtos.StandardResponse{data=[]scene_dtos.SceneTriggerRunDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/scenes/{terminal_id}/triggers/{trigger_id}/runs [get]
+func (c *SceneTriggerController) ListTriggerRuns(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	result, err := c.useCase.ListRuns(ctx.Param("terminal_id"), ctx.Param("trigger_id"), limit)
+	if err != nil {
+		respondTriggerError(ctx, "ListTriggerRuns", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Trigger runs retrieved successfully",
+		Data:    result,
+	})
+}
+
+func bindTriggerRequest(ctx *gin.Context, req *scene_dtos.SceneTriggerRequestDTO) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error",
+			Details: []utils.ValidationErrorDetail{
+				{Field: "payload", Message: "Invalid request body: " + err.Error()},
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func respondTriggerError(ctx *gin.Context, op string, err error) {
+	var valErr *utils.ValidationError
+	if errors.As(err, &valErr) {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: valErr.Message,
+			Details: valErr.Details,
+		})
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	if errors.Is(err, usecases.ErrTriggerNotFound) {
+		statusCode = http.StatusNotFound
+	} else {
+		utils.LogError("SceneTriggerController.%s: %v", op, err)
+	}
+	ctx.JSON(statusCode, dtos.StandardResponse{
+		Status:  false,
+		Message: http.StatusText(statusCode),
+	})
+}
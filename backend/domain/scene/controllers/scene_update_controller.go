@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"sensio/domain/common/dtos"
 	"sensio/domain/common/utils"
@@ -56,13 +57,19 @@ func (c *SceneUpdateController) UpdateScene(ctx *gin.Context) {
 
 	var actions entities.Actions
 	if len(req.Actions) > 0 {
-		actions = make(entities.Actions, len(req.Actions))
-		for i, a := range req.Actions {
-			actions[i] = entities.Action(a)
-		}
+		actions = toActionEntities(req.Actions)
 	}
 
-	if err := c.useCase.UpdateScene(terminalID, id, req.Name, actions); err != nil {
+	if err := c.useCase.UpdateScene(terminalID, id, req.Name, actions, req.ErrorPolicy); err != nil {
+		var valErr *utils.ValidationError
+		if errors.As(err, &valErr) {
+			ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+				Status:  false,
+				Message: valErr.Message,
+				Details: valErr.Details,
+			})
+			return
+		}
 		utils.LogError("SceneUpdateController.UpdateScene: %v", err)
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "record not found" {
@@ -1,32 +1,50 @@
 package dtos
 
+// ActionConditionDTO gates an action on the current value of a device status
+type ActionConditionDTO struct {
+	DeviceID string      `json:"device_id"`
+	Code     string      `json:"code" example:"presence_state"`
+	Operator string      `json:"operator" example:"eq"` // eq, neq, gt, gte, lt, lte, contains
+	Value    interface{} `json:"value" swaggertype:"object,string"`
+}
+
 // ActionDTO represents an action in request/response
 type ActionDTO struct {
-	DeviceID string      `json:"device_id,omitempty"`
-	Code     string      `json:"code,omitempty"`
-	RemoteID string      `json:"remote_id,omitempty"`
-	Topic    string      `json:"topic,omitempty"`
-	Value    interface{} `json:"value"`
+	DeviceID      string              `json:"device_id,omitempty"`
+	Code          string              `json:"code,omitempty"`
+	RemoteID      string              `json:"remote_id,omitempty"`
+	Topic         string              `json:"topic,omitempty"`
+	RoomID        string              `json:"room_id,omitempty" example:"meeting-room-3"`
+	ZoneID        string              `json:"zone_id,omitempty"`
+	Value         interface{}         `json:"value"`
+	DelayMs       int                 `json:"delay_ms,omitempty" example:"500"`
+	ParallelGroup string              `json:"parallel_group,omitempty" example:"lights"`
+	Retries       int                 `json:"retries,omitempty" example:"2"`
+	RetryDelayMs  int                 `json:"retry_delay_ms,omitempty" example:"1000"`
+	Condition     *ActionConditionDTO `json:"condition,omitempty"`
 }
 
 // CreateSceneRequestDTO for POST /api/terminal/:id/scenes
 type CreateSceneRequestDTO struct {
-	Name    string      `json:"name" binding:"required"`
-	Actions []ActionDTO `json:"actions"`
+	Name        string      `json:"name" binding:"required"`
+	Actions     []ActionDTO `json:"actions"`
+	ErrorPolicy string      `json:"error_policy,omitempty" binding:"omitempty,oneof=continue stop_on_error" example:"continue"`
 }
 
 // UpdateSceneRequestDTO for PUT /api/terminal/:id/scenes/:scene_id
 type UpdateSceneRequestDTO struct {
-	Name    string      `json:"name" binding:"required" example:"Evening Mode"`
-	Actions []ActionDTO `json:"actions"`
+	Name        string      `json:"name" binding:"required" example:"Evening Mode"`
+	Actions     []ActionDTO `json:"actions"`
+	ErrorPolicy string      `json:"error_policy,omitempty" binding:"omitempty,oneof=continue stop_on_error" example:"stop_on_error"`
 }
 
 // SceneResponseDTO for GET /api/terminal/:id/scenes (includes terminal_id)
 type SceneResponseDTO struct {
-	ID         string      `json:"id"`
-	TerminalID string      `json:"terminal_id"`
-	Name       string      `json:"name"`
-	Actions    []ActionDTO `json:"actions"`
+	ID          string      `json:"id"`
+	TerminalID  string      `json:"terminal_id"`
+	Name        string      `json:"name"`
+	Actions     []ActionDTO `json:"actions"`
+	ErrorPolicy string      `json:"error_policy,omitempty"`
 }
 
 // SceneListResponseDTO for summarized list
@@ -43,9 +61,10 @@ type SceneIDResponseDTO struct {
 
 // SceneItemDTO is a slim scene used inside TerminalScenesDTO (no terminal_id, it's implied by the wrapper)
 type SceneItemDTO struct {
-	ID      string      `json:"id"`
-	Name    string      `json:"name"`
-	Actions []ActionDTO `json:"actions"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Actions     []ActionDTO `json:"actions"`
+	ErrorPolicy string      `json:"error_policy,omitempty"`
 }
 
 // TerminalScenesDTO holds terminal_id and its scenes — used inside the wrapper
@@ -59,3 +78,45 @@ type TerminalScenesDTO struct {
 type TerminalScenesWrapperDTO struct {
 	Terminal TerminalScenesDTO `json:"terminal"`
 }
+
+// SceneActionResultDTO is the outcome of one action within a scene run
+type SceneActionResultDTO struct {
+	Index         int    `json:"index"`
+	DeviceID      string `json:"device_id,omitempty"`
+	Code          string `json:"code,omitempty"`
+	RemoteID      string `json:"remote_id,omitempty"`
+	Topic         string `json:"topic,omitempty"`
+	RoomID        string `json:"room_id,omitempty"`
+	ZoneID        string `json:"zone_id,omitempty"`
+	ParallelGroup string `json:"parallel_group,omitempty"`
+	Status        string `json:"status" example:"succeeded"` // succeeded, failed, skipped
+	Error         string `json:"error,omitempty"`
+	Attempts      int    `json:"attempts"`
+	LatencyMs     int64  `json:"latency_ms"`
+	StartedAt     string `json:"started_at"`
+}
+
+// SceneRunReportDTO is the structured result of a scene execution
+type SceneRunReportDTO struct {
+	RunID      string                 `json:"run_id"`
+	SceneID    string                 `json:"scene_id"`
+	TerminalID string                 `json:"terminal_id"`
+	SceneName  string                 `json:"scene_name"`
+	Source     string                 `json:"source" example:"api"`     // api, schedule, automation, mqtt
+	Status     string                 `json:"status" example:"partial"` // succeeded, partial, failed
+	Stopped    bool                   `json:"stopped"`
+	Succeeded  int                    `json:"succeeded"`
+	Queued     int                    `json:"queued"`
+	Failed     int                    `json:"failed"`
+	Skipped    int                    `json:"skipped"`
+	StartedAt  string                 `json:"started_at"`
+	FinishedAt string                 `json:"finished_at"`
+	DurationMs int64                  `json:"duration_ms"`
+	Actions    []SceneActionResultDTO `json:"actions"`
+}
+
+// SceneRunMqttRequestDTO is the MQTT RPC payload a terminal publishes on users/{mac}/{env}/rpc/scene/run
+type SceneRunMqttRequestDTO struct {
+	RequestID string `json:"request_id,omitempty"`
+	SceneID   string `json:"scene_id"`
+}
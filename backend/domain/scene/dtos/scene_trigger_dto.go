@@ -0,0 +1,51 @@
+package dtos
+
+// SceneTriggerRequestDTO for POST/PUT /api/scenes/:terminal_id/triggers
+type SceneTriggerRequestDTO struct {
+	SceneID       string  `json:"scene_id" binding:"required" example:"b4c1a7de-1111-2222-3333-444455556666"`
+	Name          string  `json:"name" example:"Lights on at sunset"`
+	Type          string  `json:"type" binding:"required,oneof=cron sun delay" example:"sun"`
+	CronExpr      string  `json:"cron_expr,omitempty" example:"30 7 * * 1-5"`
+	SunEvent      string  `json:"sun_event,omitempty" example:"sunset"`
+	Latitude      float64 `json:"latitude,omitempty" example:"-6.2"`
+	Longitude     float64 `json:"longitude,omitempty" example:"106.8"`
+	OffsetMinutes int     `json:"offset_minutes,omitempty" example:"-15"`
+	DelaySeconds  int     `json:"delay_seconds,omitempty" example:"600"`
+	Enabled       *bool   `json:"enabled,omitempty"` // Defaults to true
+}
+
+// SceneTriggerResponseDTO represents a stored trigger
+type SceneTriggerResponseDTO struct {
+	ID            string  `json:"id"`
+	TerminalID    string  `json:"terminal_id"`
+	SceneID       string  `json:"scene_id"`
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`
+	CronExpr      string  `json:"cron_expr,omitempty"`
+	SunEvent      string  `json:"sun_event,omitempty"`
+	Latitude      float64 `json:"latitude,omitempty"`
+	Longitude     float64 `json:"longitude,omitempty"`
+	OffsetMinutes int     `json:"offset_minutes,omitempty"`
+	DelaySeconds  int     `json:"delay_seconds,omitempty"`
+	Timezone      string  `json:"timezone"` // Timezone of the terminal the trigger was scheduled in
+	Enabled       bool    `json:"enabled"`
+	NextRunAt     string  `json:"next_run_at,omitempty"`
+	LastRunAt     string  `json:"last_run_at,omitempty"`
+	LastStatus    string  `json:"last_status,omitempty"`
+}
+
+// SceneTriggerIDResponseDTO for returning just the trigger ID
+type SceneTriggerIDResponseDTO struct {
+	TriggerID string `json:"trigger_id"`
+	NextRunAt string `json:"next_run_at,omitempty"`
+}
+
+// SceneTriggerRunDTO represents a single recorded execution of a trigger
+type SceneTriggerRunDTO struct {
+	ID          uint   `json:"id"`
+	ScheduledAt string `json:"scheduled_at"`
+	StartedAt   string `json:"started_at"`
+	FinishedAt  string `json:"finished_at"`
+	Status      string `json:"status" example:"succeeded"` // succeeded, failed, missed
+	Error       string `json:"error,omitempty"`
+}
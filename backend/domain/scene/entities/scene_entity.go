@@ -9,13 +9,34 @@ import (
 	"gorm.io/gorm"
 )
 
+// Scene error policies
+const (
+	ErrorPolicyContinue    = "continue"      // Run every action regardless of failures (default)
+	ErrorPolicyStopOnError = "stop_on_error" // Skip remaining actions after the first failed step
+)
+
+// ActionCondition gates an action on the current value of a device status
+type ActionCondition struct {
+	DeviceID string      `json:"device_id"`
+	Code     string      `json:"code"`
+	Operator string      `json:"operator"` // eq, neq, gt, gte, lt, lte, contains
+	Value    interface{} `json:"value"`
+}
+
 // Action represents a single instruction within a scene
 type Action struct {
-	DeviceID string      `json:"device_id,omitempty"`
-	Code     string      `json:"code,omitempty"`
-	RemoteID string      `json:"remote_id,omitempty"` // For IR devices
-	Topic    string      `json:"topic,omitempty"`     // For MQTT actions
-	Value    interface{} `json:"value"`
+	DeviceID      string           `json:"device_id,omitempty"`
+	Code          string           `json:"code,omitempty"`
+	RemoteID      string           `json:"remote_id,omitempty"` // For IR devices
+	Topic         string           `json:"topic,omitempty"`     // For MQTT actions
+	RoomID        string           `json:"room_id,omitempty"`   // Every device of the room, Value "on" or "off"
+	ZoneID        string           `json:"zone_id,omitempty"`   // Narrows a room action to one zone
+	Value         interface{}      `json:"value"`
+	DelayMs       int              `json:"delay_ms,omitempty"`       // Wait before running the action
+	ParallelGroup string           `json:"parallel_group,omitempty"` // Consecutive actions sharing a group run concurrently
+	Retries       int              `json:"retries,omitempty"`        // Extra attempts after a failure
+	RetryDelayMs  int              `json:"retry_delay_ms,omitempty"` // Wait between attempts
+	Condition     *ActionCondition `json:"condition,omitempty"`      // Skip the action unless the condition holds
 }
 
 // Actions is a slice of Action that implements Scanner and Valuer for GORM
@@ -26,22 +47,28 @@ func (a Actions) Value() (driver.Value, error) {
 }
 
 func (a *Actions) Scan(value interface{}) error {
-	b, ok := value.([]byte)
-	if !ok {
+	// MySQL returns text columns as []byte, SQLite as string
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
 		return fmt.Errorf("type assertion to []byte failed")
 	}
-	return json.Unmarshal(b, &a)
 }
 
 // Scene represents a collection of actions that can be triggered together
 type Scene struct {
-	ID         string         `gorm:"type:char(36);primaryKey" json:"id"`
-	TerminalID string         `gorm:"type:char(36);not null;index" json:"terminal_id"`
-	Name       string         `gorm:"type:varchar(255);not null" json:"name"`
-	Actions    Actions        `gorm:"type:text;not null" json:"actions"`
-	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID         string  `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID string  `gorm:"type:char(36);not null;index" json:"terminal_id"`
+	Name       string  `gorm:"type:varchar(255);not null" json:"name"`
+	Actions    Actions `gorm:"type:text;not null" json:"actions"`
+	// ErrorPolicy is ErrorPolicyContinue or ErrorPolicyStopOnError; empty means continue
+	ErrorPolicy string         `gorm:"type:varchar(16)" json:"error_policy"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
 // TableName specifies the table name for the Scene model
@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Sources that can start a scene run
+const (
+	SceneRunSourceAPI        = "api"
+	SceneRunSourceSchedule   = "schedule"
+	SceneRunSourceAutomation = "automation"
+	SceneRunSourceMqtt       = "mqtt"
+)
+
+// Scene run and action outcomes
+const (
+	SceneRunSucceeded = "succeeded"
+	SceneRunPartial   = "partial" // Some actions failed
+	SceneRunFailed    = "failed"  // No action succeeded
+
+	ActionSucceeded = "succeeded"
+	ActionQueued    = "queued" // MQTT broker unreachable, published from the outbox once it reconnects
+	ActionFailed    = "failed"
+	ActionSkipped   = "skipped" // Condition not met, or stopped by the error policy
+)
+
+// ActionResult is the outcome of one scene action
+type ActionResult struct {
+	Index         int       `json:"index"`
+	DeviceID      string    `json:"device_id,omitempty"`
+	Code          string    `json:"code,omitempty"`
+	RemoteID      string    `json:"remote_id,omitempty"`
+	Topic         string    `json:"topic,omitempty"`
+	RoomID        string    `json:"room_id,omitempty"`
+	ZoneID        string    `json:"zone_id,omitempty"`
+	ParallelGroup string    `json:"parallel_group,omitempty"`
+	Status        string    `json:"status"`
+	Error         string    `json:"error,omitempty"`
+	Attempts      int       `json:"attempts"`
+	LatencyMs     int64     `json:"latency_ms"`
+	StartedAt     time.Time `json:"started_at"`
+}
+
+// ActionResults is a slice of ActionResult that implements Scanner and Valuer for GORM
+type ActionResults []ActionResult
+
+func (a ActionResults) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+func (a *ActionResults) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return fmt.Errorf("type assertion to []byte failed")
+	}
+}
+
+// SceneRun records one execution of a scene with the result of every action
+type SceneRun struct {
+	ID         string        `gorm:"type:char(36);primaryKey" json:"id"`
+	SceneID    string        `gorm:"type:char(36);not null;index" json:"scene_id"`
+	TerminalID string        `gorm:"type:char(36);not null;index" json:"terminal_id"`
+	SceneName  string        `gorm:"type:varchar(255)" json:"scene_name"`
+	Source     string        `gorm:"type:varchar(16)" json:"source"`
+	Status     string        `gorm:"type:varchar(16);not null" json:"status"`
+	Stopped    bool          `json:"stopped"` // Remaining actions were skipped by stop_on_error
+	Results    ActionResults `gorm:"type:text" json:"results"`
+	StartedAt  time.Time     `gorm:"index" json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	DurationMs int64         `json:"duration_ms"`
+}
+
+// TableName specifies the table name for the SceneRun model
+func (SceneRun) TableName() string {
+	return "scene_runs"
+}
@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Trigger types supported by the scene scheduler
+const (
+	TriggerTypeCron  = "cron"  // Fires on a 5-field cron expression
+	TriggerTypeSun   = "sun"   // Fires at sunrise/sunset (plus offset) for a lat/lon
+	TriggerTypeDelay = "delay" // Fires once, DelaySeconds after it was created or re-armed
+)
+
+// Trigger run outcomes
+const (
+	TriggerRunSucceeded = "succeeded"
+	TriggerRunFailed    = "failed"
+	TriggerRunMissed    = "missed" // Due while the server was down and outside the catch-up window
+)
+
+// SceneTrigger defines when a scene should be run automatically
+type SceneTrigger struct {
+	ID            string         `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID    string         `gorm:"type:char(36);not null;index" json:"terminal_id"`
+	SceneID       string         `gorm:"type:char(36);not null;index" json:"scene_id"`
+	Name          string         `gorm:"type:varchar(255)" json:"name"`
+	Type          string         `gorm:"type:varchar(16);not null" json:"type"`
+	CronExpr      string         `gorm:"type:varchar(128)" json:"cron_expr,omitempty"`
+	SunEvent      string         `gorm:"type:varchar(16)" json:"sun_event,omitempty"` // sunrise, sunset
+	Latitude      float64        `json:"latitude,omitempty"`
+	Longitude     float64        `json:"longitude,omitempty"`
+	OffsetMinutes int            `json:"offset_minutes,omitempty"` // Applied to sun events
+	DelaySeconds  int            `json:"delay_seconds,omitempty"`
+	Timezone      string         `gorm:"type:varchar(64);not null" json:"timezone"` // IANA name, e.g. Asia/Jakarta
+	Enabled       bool           `gorm:"not null" json:"enabled"`
+	NextRunAt     *time.Time     `gorm:"index" json:"next_run_at,omitempty"`
+	LastRunAt     *time.Time     `json:"last_run_at,omitempty"`
+	LastStatus    string         `gorm:"type:varchar(16)" json:"last_status,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// TableName specifies the table name for the SceneTrigger model
+func (SceneTrigger) TableName() string {
+	return "scene_triggers"
+}
+
+// SceneTriggerRun records the outcome of a single scheduled execution
+type SceneTriggerRun struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TriggerID   string    `gorm:"type:char(36);not null;index" json:"trigger_id"`
+	SceneID     string    `gorm:"type:char(36);not null" json:"scene_id"`
+	TerminalID  string    `gorm:"type:char(36);not null" json:"terminal_id"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Status      string    `gorm:"type:varchar(16);not null" json:"status"`
+	Error       string    `gorm:"type:text" json:"error,omitempty"`
+}
+
+// TableName specifies the table name for the SceneTriggerRun model
+func (SceneTriggerRun) TableName() string {
+	return "scene_trigger_runs"
+}
@@ -2,10 +2,13 @@ package scene
 
 import (
 	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
 	"sensio/domain/scene/controllers"
 	"sensio/domain/scene/repositories"
 	"sensio/domain/scene/usecases"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
 	tuyaUsecases "sensio/domain/tuya/usecases"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -18,17 +21,44 @@ type SceneModule struct {
 	UpdateController  *controllers.SceneUpdateController
 	DeleteController  *controllers.SceneDeleteController
 	ControlController *controllers.SceneControlController
+	RunsController    *controllers.SceneRunsController
+	TriggerController *controllers.SceneTriggerController
+	MqttController    *controllers.SceneMqttController
+	Scheduler         *usecases.SceneScheduler
+	ControlUseCase    *usecases.ControlSceneUseCase
 }
 
-func NewSceneModule(db *gorm.DB, tuyaCmd tuyaUsecases.TuyaDeviceControlExecutor, mqttSvc *infrastructure.MqttService) *SceneModule {
+func NewSceneModule(
+	db *gorm.DB,
+	badger *infrastructure.BadgerService,
+	terminalRepo usecases.TerminalLookup,
+	tuyaCmd tuyaUsecases.TuyaDeviceControlExecutor,
+	tuyaAuth tuyaUsecases.TuyaAuthUseCase,
+	mqttSvc *infrastructure.MqttService,
+) *SceneModule {
 	repo := repositories.NewSceneRepository(db)
+	runRepo := repositories.NewSceneRunRepository(db)
+	triggerRepo := repositories.NewSceneTriggerRepository(db)
+	statusRepo := device_status_repositories.NewDeviceStatusRepository(badger)
 
 	addUC := usecases.NewAddSceneUseCase(repo)
 	updateUC := usecases.NewUpdateSceneUseCase(repo)
 	deleteUC := usecases.NewDeleteSceneUseCase(repo)
 	getAllUC := usecases.NewGetAllScenesUseCase(repo)
 	getAllGroupedUC := usecases.NewGetAllGroupedScenesUseCase(repo)
-	controlUC := usecases.NewControlSceneUseCase(repo, tuyaCmd, mqttSvc)
+	controlUC := usecases.NewControlSceneUseCase(repo, runRepo, statusRepo, terminalRepo, tuyaCmd, mqttSvc)
+	runsUC := usecases.NewGetSceneRunsUseCase(repo, runRepo)
+	triggerUC := usecases.NewSceneTriggerUseCase(triggerRepo, repo, terminalRepo)
+
+	cfg := utils.GetConfig()
+	interval, err := time.ParseDuration(cfg.SceneSchedulerInterval)
+	if err != nil {
+		interval = 15 * time.Second
+	}
+	catchUp, err := time.ParseDuration(cfg.SceneSchedulerCatchUpWindow)
+	if err != nil {
+		catchUp = 5 * time.Minute
+	}
 
 	return &SceneModule{
 		AddController:     controllers.NewSceneAddController(addUC),
@@ -37,6 +67,18 @@ func NewSceneModule(db *gorm.DB, tuyaCmd tuyaUsecases.TuyaDeviceControlExecutor,
 		UpdateController:  controllers.NewSceneUpdateController(updateUC),
 		DeleteController:  controllers.NewSceneDeleteController(deleteUC),
 		ControlController: controllers.NewSceneControlController(controlUC),
+		RunsController:    controllers.NewSceneRunsController(runsUC),
+		TriggerController: controllers.NewSceneTriggerController(triggerUC),
+		MqttController:    controllers.NewSceneMqttController(getAllUC, controlUC, tuyaAuth),
+		Scheduler:         usecases.NewSceneScheduler(triggerRepo, controlUC, tuyaAuth, terminalRepo, interval, catchUp),
+		ControlUseCase:    controlUC,
+	}
+}
+
+// RegisterMqttRoutes exposes scene listing and triggering over the MQTT RPC router
+func (m *SceneModule) RegisterMqttRoutes(router *infrastructure.MqttRpcRouter) {
+	if err := m.MqttController.RegisterMqttRoutes(router); err != nil {
+		utils.LogError("Scene module MQTT RPC registration failed: %v", err)
 	}
 }
 
@@ -52,5 +94,18 @@ func (m *SceneModule) RegisterRoutes(protected *gin.RouterGroup) {
 		group.PUT("/:scene_id", m.UpdateController.UpdateScene)
 		group.DELETE("/:scene_id", m.DeleteController.DeleteScene)
 		group.GET("/:scene_id/control", m.ControlController.ControlScene)
+		group.GET("/:scene_id/runs", m.RunsController.ListRuns)
+		group.GET("/:scene_id/runs/:run_id", m.RunsController.GetRun)
+	}
+
+	// Scheduled triggers (cron, sunrise/sunset, delay)
+	triggers := protected.Group("/api/scenes/:terminal_id/triggers")
+	{
+		triggers.POST("", m.TriggerController.CreateTrigger)
+		triggers.GET("", m.TriggerController.ListTriggers)
+		triggers.GET("/:trigger_id", m.TriggerController.GetTrigger)
+		triggers.PUT("/:trigger_id", m.TriggerController.UpdateTrigger)
+		triggers.DELETE("/:trigger_id", m.TriggerController.DeleteTrigger)
+		triggers.GET("/:trigger_id/runs", m.TriggerController.ListTriggerRuns)
 	}
 }
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"sensio/domain/scene/entities"
+
+	"gorm.io/gorm"
+)
+
+// ISceneRunRepository defines the interface for scene run history storage
+type ISceneRunRepository interface {
+	Save(run *entities.SceneRun) error
+	GetByID(terminalID, sceneID, id string) (*entities.SceneRun, error)
+	GetByScene(terminalID, sceneID string, limit int) ([]entities.SceneRun, error)
+}
+
+// SceneRunRepository handles persistent storage of scene run history using GORM
+type SceneRunRepository struct {
+	db *gorm.DB
+}
+
+// NewSceneRunRepository creates a new instance of SceneRunRepository
+func NewSceneRunRepository(db *gorm.DB) *SceneRunRepository {
+	return &SceneRunRepository{db: db}
+}
+
+// Save persists a scene run
+func (r *SceneRunRepository) Save(run *entities.SceneRun) error {
+	return r.db.Save(run).Error
+}
+
+// GetByID retrieves a single run of a scene
+func (r *SceneRunRepository) GetByID(terminalID, sceneID, id string) (*entities.SceneRun, error) {
+	var run entities.SceneRun
+	err := r.db.Where("id = ? AND scene_id = ? AND terminal_id = ?", id, sceneID, terminalID).First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetByScene retrieves the most recent runs of a scene, newest first
+func (r *SceneRunRepository) GetByScene(terminalID, sceneID string, limit int) ([]entities.SceneRun, error) {
+	var runs []entities.SceneRun
+	query := r.db.Where("scene_id = ? AND terminal_id = ?", sceneID, terminalID).Order("started_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"sensio/domain/scene/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ISceneTriggerRepository defines the interface for scene trigger storage operations
+type ISceneTriggerRepository interface {
+	Save(trigger *entities.SceneTrigger) error
+	GetByID(terminalID, id string) (*entities.SceneTrigger, error)
+	GetAll(terminalID string) ([]entities.SceneTrigger, error)
+	GetDue(now time.Time) ([]entities.SceneTrigger, error)
+	Delete(terminalID, id string) error
+	SaveRun(run *entities.SceneTriggerRun) error
+	GetRuns(triggerID string, limit int) ([]entities.SceneTriggerRun, error)
+}
+
+// SceneTriggerRepository handles persistent storage of scene triggers and their run history using GORM
+type SceneTriggerRepository struct {
+	db *gorm.DB
+}
+
+// NewSceneTriggerRepository creates a new instance of SceneTriggerRepository
+func NewSceneTriggerRepository(db *gorm.DB) *SceneTriggerRepository {
+	return &SceneTriggerRepository{db: db}
+}
+
+// Save persists a trigger to the database (Upsert)
+func (r *SceneTriggerRepository) Save(trigger *entities.SceneTrigger) error {
+	return r.db.Save(trigger).Error
+}
+
+// GetByID retrieves a trigger by its ID and TerminalID
+func (r *SceneTriggerRepository) GetByID(terminalID, id string) (*entities.SceneTrigger, error) {
+	var trigger entities.SceneTrigger
+	if err := r.db.Where("id = ? AND terminal_id = ?", id, terminalID).First(&trigger).Error; err != nil {
+		return nil, err
+	}
+	return &trigger, nil
+}
+
+// GetAll retrieves all triggers configured for a terminal
+func (r *SceneTriggerRepository) GetAll(terminalID string) ([]entities.SceneTrigger, error) {
+	var triggers []entities.SceneTrigger
+	if err := r.db.Where("terminal_id = ?", terminalID).Order("created_at").Find(&triggers).Error; err != nil {
+		return nil, err
+	}
+	return triggers, nil
+}
+
+// GetDue retrieves enabled triggers whose next run is at or before now
+func (r *SceneTriggerRepository) GetDue(now time.Time) ([]entities.SceneTrigger, error) {
+	var triggers []entities.SceneTrigger
+	err := r.db.
+		Where("enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now).
+		Order("next_run_at").
+		Find(&triggers).Error
+	if err != nil {
+		return nil, err
+	}
+	return triggers, nil
+}
+
+// Delete removes a trigger if it belongs to the specified terminal
+func (r *SceneTriggerRepository) Delete(terminalID, id string) error {
+	return r.db.Where("id = ? AND terminal_id = ?", id, terminalID).Delete(&entities.SceneTrigger{}).Error
+}
+
+// SaveRun appends a run record
+func (r *SceneTriggerRepository) SaveRun(run *entities.SceneTriggerRun) error {
+	return r.db.Create(run).Error
+}
+
+// GetRuns retrieves the most recent runs of a trigger, newest first
+func (r *SceneTriggerRepository) GetRuns(triggerID string, limit int) ([]entities.SceneTriggerRun, error) {
+	var runs []entities.SceneTriggerRun
+	query := r.db.Where("trigger_id = ?", triggerID).Order("started_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"sensio/domain/common/utils"
 	"sensio/domain/scene/entities"
 	"sensio/domain/scene/repositories"
 
@@ -15,12 +16,17 @@ func NewAddSceneUseCase(repo repositories.ISceneRepository) *AddSceneUseCase {
 	return &AddSceneUseCase{repo: repo}
 }
 
-func (u *AddSceneUseCase) AddScene(terminalID string, name string, actions entities.Actions) (string, error) {
+func (u *AddSceneUseCase) AddScene(terminalID string, name string, actions entities.Actions, errorPolicy string) (string, error) {
+	if details := validateSceneActions(actions); len(details) > 0 {
+		return "", utils.NewValidationError("Validation Error", details)
+	}
+
 	scene := &entities.Scene{
-		ID:         uuid.New().String(),
-		TerminalID: terminalID,
-		Name:       name,
-		Actions:    actions,
+		ID:          uuid.New().String(),
+		TerminalID:  terminalID,
+		Name:        name,
+		Actions:     actions,
+		ErrorPolicy: errorPolicy,
 	}
 
 	if err := u.repo.Save(scene); err != nil {
@@ -1,86 +1,440 @@
 package usecases
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/utils"
+	room_dtos "sensio/domain/room/dtos"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/entities"
 	"sensio/domain/scene/repositories"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	terminal_entities "sensio/domain/terminal/terminal/entities"
 	tuya_dtos "sensio/domain/tuya/dtos"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// sceneTopicOutboxTTL bounds how long a topic action stays queued while the MQTT broker is down
+const sceneTopicOutboxTTL = 5 * time.Minute
+
 // TuyaDeviceControlExecutor defines the interface for controlling Tuya devices
 type TuyaDeviceControlExecutor interface {
 	SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error)
 	SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error)
 }
 
+// DeviceStatusReader reads stored device statuses for conditional actions
+type DeviceStatusReader interface {
+	GetByDeviceIDAndCode(deviceID, code string) (*device_status_entities.DeviceStatus, error)
+}
+
+// TerminalLookup resolves the terminal a run report is published to
+type TerminalLookup interface {
+	GetByID(id string) (*terminal_entities.Terminal, error)
+}
+
+// RoomController turns every device of a room or zone on or off
+type RoomController interface {
+	ControlRoom(roomID, zoneID, action, accessToken string) (*room_dtos.RoomControlResultDTO, error)
+}
+
+// ControlSceneUseCase executes scenes step by step.
+//
+// Actions run in order. Consecutive actions sharing a ParallelGroup form one step and
+// run concurrently; the next step starts when all of them finished. Each action may wait
+// DelayMs first, be skipped by its Condition and be retried Retries times. With the
+// stop_on_error policy, every step after a failed one is skipped.
+// Every execution produces a report that is stored as scene run history and published
+// to users/{mac}/{env}/scene/run.
 type ControlSceneUseCase struct {
-	repo    *repositories.SceneRepository
-	tuyaCmd TuyaDeviceControlExecutor
-	mqttSvc *infrastructure.MqttService
+	repo         repositories.ISceneRepository
+	runRepo      repositories.ISceneRunRepository
+	statusRepo   DeviceStatusReader
+	terminalRepo TerminalLookup
+	tuyaCmd      TuyaDeviceControlExecutor
+	mqttSvc      *infrastructure.MqttService
+	rooms        RoomController
+
+	sleep func(time.Duration)
+	now   func() time.Time
 }
 
 func NewControlSceneUseCase(
-	repo *repositories.SceneRepository,
+	repo repositories.ISceneRepository,
+	runRepo repositories.ISceneRunRepository,
+	statusRepo DeviceStatusReader,
+	terminalRepo TerminalLookup,
 	tuyaCmd TuyaDeviceControlExecutor,
 	mqttSvc *infrastructure.MqttService,
 ) *ControlSceneUseCase {
 	return &ControlSceneUseCase{
-		repo:    repo,
-		tuyaCmd: tuyaCmd,
-		mqttSvc: mqttSvc,
+		repo:         repo,
+		runRepo:      runRepo,
+		statusRepo:   statusRepo,
+		terminalRepo: terminalRepo,
+		tuyaCmd:      tuyaCmd,
+		mqttSvc:      mqttSvc,
+		sleep:        time.Sleep,
+		now:          time.Now,
 	}
 }
 
+// SetRoomController enables room actions, which fan out to every device of a room or zone
+func (u *ControlSceneUseCase) SetRoomController(rooms RoomController) {
+	u.rooms = rooms
+}
+
+// ControlScene runs a scene on behalf of an API caller
 func (u *ControlSceneUseCase) ControlScene(terminalID, id, accessToken string) error {
+	_, err := u.RunScene(terminalID, id, accessToken, entities.SceneRunSourceAPI)
+	return err
+}
+
+// RunScene executes a scene and returns its per-action report.
+// The report is also returned (non-nil) when some actions failed; err is then set as well.
+func (u *ControlSceneUseCase) RunScene(terminalID, id, accessToken, source string) (*scene_dtos.SceneRunReportDTO, error) {
 	scene, err := u.repo.GetByID(terminalID, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var errs []error
-	for _, action := range scene.Actions {
-		if action.Topic != "" {
-			if u.mqttSvc != nil {
-				if err := u.mqttSvc.Publish(action.Topic, 0, false, action.Value); err != nil {
-					utils.LogError("Scene %s: Failed to publish to MQTT topic %s: %v", id, action.Topic, err)
-					errs = append(errs, err)
-				}
+	run := &entities.SceneRun{
+		ID:         uuid.New().String(),
+		SceneID:    scene.ID,
+		TerminalID: terminalID,
+		SceneName:  scene.Name,
+		Source:     source,
+		StartedAt:  u.now(),
+		Results:    make(entities.ActionResults, len(scene.Actions)),
+	}
+
+	for start := 0; start < len(scene.Actions); {
+		end := start + 1
+		if group := scene.Actions[start].ParallelGroup; group != "" {
+			for end < len(scene.Actions) && scene.Actions[end].ParallelGroup == group {
+				end++
 			}
-		} else if action.DeviceID != "" {
-			if action.RemoteID != "" {
-				valInt, ok := utils.ToInt(action.Value)
-				if !ok {
-					err := fmt.Errorf("invalid value for IR command on device %s: %v", action.DeviceID, action.Value)
-					utils.LogWarn("Scene %s: %v", id, err)
-					errs = append(errs, err)
-					continue
-				}
-				params := map[string]int{
-					action.Code: valInt,
-				}
-				_, err := u.tuyaCmd.SendIRACCommand(accessToken, action.DeviceID, action.RemoteID, params)
-				if err != nil {
-					utils.LogError("Scene %s: Failed to send IR command to %s: %v", id, action.DeviceID, err)
-					errs = append(errs, err)
-				}
-			} else {
-				cmd := tuya_dtos.TuyaCommandDTO{
-					Code:  action.Code,
-					Value: action.Value,
-				}
-				_, err := u.tuyaCmd.SendSwitchCommand(accessToken, action.DeviceID, []tuya_dtos.TuyaCommandDTO{cmd})
-				if err != nil {
-					utils.LogError("Scene %s: Failed to send command to %s: %v", id, action.DeviceID, err)
-					errs = append(errs, err)
+		}
+
+		if run.Stopped {
+			for i := start; i < end; i++ {
+				run.Results[i] = newActionResult(i, scene.Actions[i], u.now())
+				run.Results[i].Status = entities.ActionSkipped
+				run.Results[i].Error = "skipped: an earlier action failed (stop_on_error)"
+			}
+			start = end
+			continue
+		}
+
+		if end-start == 1 {
+			run.Results[start] = u.runAction(id, start, scene.Actions[start], accessToken)
+		} else {
+			var wg sync.WaitGroup
+			for i := start; i < end; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					run.Results[i] = u.runAction(id, i, scene.Actions[i], accessToken)
+				}(i)
+			}
+			wg.Wait()
+		}
+
+		if scene.ErrorPolicy == entities.ErrorPolicyStopOnError {
+			for i := start; i < end; i++ {
+				if run.Results[i].Status == entities.ActionFailed {
+					run.Stopped = true
 				}
 			}
 		}
+		start = end
+	}
+
+	run.FinishedAt = u.now()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+
+	report := toSceneRunReport(*run)
+	run.Status = report.Status
+
+	if u.runRepo != nil {
+		if err := u.runRepo.Save(run); err != nil {
+			utils.LogError("Scene %s: Failed to store run history: %v", id, err)
+		}
+	}
+	u.publishReport(terminalID, report)
+
+	if report.Failed > 0 {
+		return &report, fmt.Errorf("triggered %d errors during scene execution", report.Failed)
+	}
+	return &report, nil
+}
+
+func (u *ControlSceneUseCase) runAction(sceneID string, index int, action entities.Action, accessToken string) entities.ActionResult {
+	if action.DelayMs > 0 {
+		u.sleep(time.Duration(action.DelayMs) * time.Millisecond)
+	}
+
+	result := newActionResult(index, action, u.now())
+
+	if action.Condition != nil {
+		ok, reason := u.checkCondition(*action.Condition)
+		if !ok {
+			result.Status = entities.ActionSkipped
+			result.Error = reason
+			return result
+		}
+	}
+
+	var err error
+	queued := false
+	for attempt := 0; attempt <= action.Retries; attempt++ {
+		if attempt > 0 && action.RetryDelayMs > 0 {
+			u.sleep(time.Duration(action.RetryDelayMs) * time.Millisecond)
+		}
+		result.Attempts++
+		if queued, err = u.dispatch(sceneID, index, action, accessToken); err == nil {
+			break
+		}
+		utils.LogWarn("Scene %s: action %d attempt %d failed: %v", sceneID, index, result.Attempts, err)
+		var valErr *utils.ValidationError
+		if errors.As(err, &valErr) {
+			// The device specification rejects the action, retrying cannot succeed
+			break
+		}
+	}
+
+	result.LatencyMs = u.now().Sub(result.StartedAt).Milliseconds()
+	if err != nil {
+		result.Status = entities.ActionFailed
+		result.Error = err.Error()
+		utils.LogError("Scene %s: action %d failed after %d attempt(s): %v", sceneID, index, result.Attempts, err)
+		return result
+	}
+	result.Status = entities.ActionSucceeded
+	if queued {
+		result.Status = entities.ActionQueued
+	}
+	return result
+}
+
+func (u *ControlSceneUseCase) checkCondition(cond entities.ActionCondition) (bool, string) {
+	if u.statusRepo == nil {
+		return false, "condition not evaluated: device status unavailable"
+	}
+	status, err := u.statusRepo.GetByDeviceIDAndCode(cond.DeviceID, cond.Code)
+	if err != nil || status == nil {
+		return false, fmt.Sprintf("condition not met: %s/%s has no reported value", cond.DeviceID, cond.Code)
+	}
+	matched, err := utils.CompareStatusValue(status.Value, cond.Operator, cond.Value)
+	if err != nil {
+		return false, "condition not evaluated: " + err.Error()
+	}
+	if !matched {
+		return false, fmt.Sprintf("condition not met: %s is %q", cond.Code, status.Value)
+	}
+	return true, ""
+}
+
+// dispatch runs one action; queued reports a topic action held in the MQTT outbox until the broker is back
+func (u *ControlSceneUseCase) dispatch(sceneID string, index int, action entities.Action, accessToken string) (queued bool, err error) {
+	if action.Topic != "" {
+		if u.mqttSvc == nil {
+			return false, fmt.Errorf("MQTT service unavailable")
+		}
+		// Queued commands are only worth replaying shortly after the scene ran
+		return u.mqttSvc.PublishOrQueue(action.Topic, 0, false, action.Value, infrastructure.MqttPublishOptions{
+			DedupKey: sceneTopicDedupKey(sceneID, index, action),
+			TTL:      sceneTopicOutboxTTL,
+		})
+	}
+	if action.RoomID != "" {
+		return false, u.dispatchRoom(action, accessToken)
+	}
+	if action.DeviceID == "" {
+		return false, fmt.Errorf("action has neither topic, device_id nor room_id")
+	}
+
+	if action.RemoteID != "" {
+		valInt, ok := utils.ToInt(action.Value)
+		if !ok {
+			return false, fmt.Errorf("invalid value for IR command on device %s: %v", action.DeviceID, action.Value)
+		}
+		params := map[string]int{
+			action.Code: valInt,
+		}
+		success, err := u.tuyaCmd.SendIRACCommand(accessToken, action.DeviceID, action.RemoteID, params)
+		if err != nil {
+			return false, err
+		}
+		if !success {
+			return false, fmt.Errorf("unsuccessful response from Tuya")
+		}
+		return false, nil
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("triggered %d errors during scene execution", len(errs))
+	cmd := tuya_dtos.TuyaCommandDTO{
+		Code:  action.Code,
+		Value: action.Value,
+	}
+	success, err := u.tuyaCmd.SendSwitchCommand(accessToken, action.DeviceID, []tuya_dtos.TuyaCommandDTO{cmd})
+	if err != nil {
+		return false, err
 	}
+	if !success {
+		return false, fmt.Errorf("unsuccessful response from Tuya")
+	}
+	return false, nil
+}
+
+// sceneTopicDedupKey identifies one topic action of a scene with its payload, so a queued command only
+// replaces an identical pending one and two actions on the same topic are both kept
+func sceneTopicDedupKey(sceneID string, index int, action entities.Action) string {
+	payload, _ := json.Marshal(action.Value)
+	sum := sha256.Sum256(payload)
+	return fmt.Sprintf("scene-topic:%s:%d:%s", sceneID, index, hex.EncodeToString(sum[:8]))
+}
 
+// dispatchRoom runs a room action; it fails when any device of the room failed, so retries and
+// the stop_on_error policy apply to the room as a whole
+func (u *ControlSceneUseCase) dispatchRoom(action entities.Action, accessToken string) error {
+	if u.rooms == nil {
+		return fmt.Errorf("room control unavailable")
+	}
+	power, ok := roomActionValue(action.Value)
+	if !ok {
+		return fmt.Errorf("invalid value for room %s: %v", action.RoomID, action.Value)
+	}
+	result, err := u.rooms.ControlRoom(action.RoomID, action.ZoneID, power, accessToken)
+	if err != nil {
+		return err
+	}
+	if result.Failed > 0 {
+		var failed []string
+		for _, d := range result.Devices {
+			if d.Status == "failed" {
+				failed = append(failed, d.Name)
+			}
+		}
+		return fmt.Errorf("%d of %d devices failed: %s", result.Failed, result.Succeeded+result.Failed, strings.Join(failed, ", "))
+	}
 	return nil
 }
+
+// roomActionValue reads the power state of a room action: "on"/"off", a boolean or 1/0
+func roomActionValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "on", true
+		}
+		return "off", true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "on", "true", "1":
+			return "on", true
+		case "off", "false", "0":
+			return "off", true
+		}
+		return "", false
+	}
+	if n, ok := utils.ToInt(value); ok && (n == 0 || n == 1) {
+		if n == 1 {
+			return "on", true
+		}
+		return "off", true
+	}
+	return "", false
+}
+
+func (u *ControlSceneUseCase) publishReport(terminalID string, report scene_dtos.SceneRunReportDTO) {
+	if u.mqttSvc == nil || u.terminalRepo == nil {
+		return
+	}
+	terminal, err := u.terminalRepo.GetByID(terminalID)
+	if err != nil || terminal == nil || terminal.MacAddress == "" {
+		utils.LogDebug("Scene %s: run report not published, terminal %s not resolved", report.SceneID, terminalID)
+		return
+	}
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+	topic := fmt.Sprintf("users/%s/%s/scene/run", terminal.MacAddress, utils.GetConfig().ApplicationEnvironment)
+	if err := u.mqttSvc.Publish(topic, 0, false, payload); err != nil {
+		utils.LogWarn("Scene %s: Failed to publish run report: %v", report.SceneID, err)
+	}
+}
+
+func newActionResult(index int, action entities.Action, startedAt time.Time) entities.ActionResult {
+	return entities.ActionResult{
+		Index:         index,
+		DeviceID:      action.DeviceID,
+		Code:          action.Code,
+		RemoteID:      action.RemoteID,
+		Topic:         action.Topic,
+		RoomID:        action.RoomID,
+		ZoneID:        action.ZoneID,
+		ParallelGroup: action.ParallelGroup,
+		StartedAt:     startedAt,
+	}
+}
+
+func toSceneRunReport(run entities.SceneRun) scene_dtos.SceneRunReportDTO {
+	report := scene_dtos.SceneRunReportDTO{
+		RunID:      run.ID,
+		SceneID:    run.SceneID,
+		TerminalID: run.TerminalID,
+		SceneName:  run.SceneName,
+		Source:     run.Source,
+		Stopped:    run.Stopped,
+		StartedAt:  run.StartedAt.UTC().Format(time.RFC3339Nano),
+		FinishedAt: run.FinishedAt.UTC().Format(time.RFC3339Nano),
+		DurationMs: run.DurationMs,
+		Actions:    make([]scene_dtos.SceneActionResultDTO, len(run.Results)),
+	}
+	for i, r := range run.Results {
+		switch r.Status {
+		case entities.ActionSucceeded:
+			report.Succeeded++
+		case entities.ActionQueued:
+			report.Queued++
+		case entities.ActionFailed:
+			report.Failed++
+		default:
+			report.Skipped++
+		}
+		report.Actions[i] = scene_dtos.SceneActionResultDTO{
+			Index:         r.Index,
+			DeviceID:      r.DeviceID,
+			Code:          r.Code,
+			RemoteID:      r.RemoteID,
+			Topic:         r.Topic,
+			RoomID:        r.RoomID,
+			ZoneID:        r.ZoneID,
+			ParallelGroup: r.ParallelGroup,
+			Status:        r.Status,
+			Error:         r.Error,
+			Attempts:      r.Attempts,
+			LatencyMs:     r.LatencyMs,
+			StartedAt:     r.StartedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+
+	switch {
+	case report.Failed == 0:
+		report.Status = entities.SceneRunSucceeded
+	case report.Succeeded+report.Queued > 0:
+		report.Status = entities.SceneRunPartial
+	default:
+		report.Status = entities.SceneRunFailed
+	}
+	return report
+}
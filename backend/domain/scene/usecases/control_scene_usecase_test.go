@@ -0,0 +1,269 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"sensio/domain/scene/entities"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSceneRepo struct {
+	scene *entities.Scene
+}
+
+func (r *fakeSceneRepo) Save(scene *entities.Scene) error { r.scene = scene; return nil }
+func (r *fakeSceneRepo) GetByID(terminalID, id string) (*entities.Scene, error) {
+	if r.scene == nil || r.scene.ID != id {
+		return nil, errors.New("record not found")
+	}
+	return r.scene, nil
+}
+func (r *fakeSceneRepo) GetAll(terminalID string) ([]entities.Scene, error) { return nil, nil }
+func (r *fakeSceneRepo) Delete(terminalID, id string) error                 { return nil }
+func (r *fakeSceneRepo) GetAllGrouped() (map[string][]entities.Scene, error) {
+	return nil, nil
+}
+
+type fakeSceneRunRepo struct {
+	runs []entities.SceneRun
+}
+
+func (r *fakeSceneRunRepo) Save(run *entities.SceneRun) error {
+	r.runs = append(r.runs, *run)
+	return nil
+}
+func (r *fakeSceneRunRepo) GetByID(terminalID, sceneID, id string) (*entities.SceneRun, error) {
+	return nil, errors.New("record not found")
+}
+func (r *fakeSceneRunRepo) GetByScene(terminalID, sceneID string, limit int) ([]entities.SceneRun, error) {
+	return r.runs, nil
+}
+
+type fakeStatusReader map[string]string
+
+func (f fakeStatusReader) GetByDeviceIDAndCode(deviceID, code string) (*device_status_entities.DeviceStatus, error) {
+	v, ok := f[deviceID+"/"+code]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &device_status_entities.DeviceStatus{DeviceID: deviceID, Code: code, Value: v}, nil
+}
+
+// fakeTuyaCmd fails the first failures[deviceID] calls for a device
+type fakeTuyaCmd struct {
+	mu       sync.Mutex
+	failures map[string]int
+	calls    []string
+}
+
+func (f *fakeTuyaCmd) SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, deviceID)
+	if f.failures[deviceID] > 0 {
+		f.failures[deviceID]--
+		return false, errors.New("device offline")
+	}
+	return true, nil
+}
+
+func (f *fakeTuyaCmd) SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error) {
+	return true, nil
+}
+
+func newTestControlUseCase(scene *entities.Scene, cmd *fakeTuyaCmd, statuses fakeStatusReader) (*ControlSceneUseCase, *fakeSceneRunRepo, *[]time.Duration) {
+	runRepo := &fakeSceneRunRepo{}
+	uc := NewControlSceneUseCase(&fakeSceneRepo{scene: scene}, runRepo, statuses, nil, cmd, nil)
+	var mu sync.Mutex
+	slept := &[]time.Duration{}
+	uc.sleep = func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		*slept = append(*slept, d)
+	}
+	return uc, runRepo, slept
+}
+
+func switchAction(deviceID string) entities.Action {
+	return entities.Action{DeviceID: deviceID, Code: "switch_1", Value: true}
+}
+
+func TestRunScene_RunsActionsAndStoresReport(t *testing.T) {
+	lamp := switchAction("lamp")
+	lamp.DelayMs = 1500
+	scene := &entities.Scene{ID: "s1", Name: "Evening", Actions: entities.Actions{switchAction("tv"), lamp}}
+	cmd := &fakeTuyaCmd{}
+	uc, runRepo, slept := newTestControlUseCase(scene, cmd, nil)
+
+	report, err := uc.RunScene("t1", "s1", "token", entities.SceneRunSourceSchedule)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"tv", "lamp"}, cmd.calls)
+	assert.Equal(t, []time.Duration{1500 * time.Millisecond}, *slept)
+	assert.Equal(t, entities.SceneRunSucceeded, report.Status)
+	assert.Equal(t, entities.SceneRunSourceSchedule, report.Source)
+	assert.Equal(t, 2, report.Succeeded)
+	require.Len(t, runRepo.runs, 1)
+	assert.Equal(t, report.RunID, runRepo.runs[0].ID)
+	assert.Equal(t, entities.SceneRunSucceeded, runRepo.runs[0].Status)
+}
+
+func TestRunScene_RetriesFailedAction(t *testing.T) {
+	a := switchAction("lamp")
+	a.Retries = 2
+	a.RetryDelayMs = 200
+	scene := &entities.Scene{ID: "s1", Actions: entities.Actions{a}}
+	cmd := &fakeTuyaCmd{failures: map[string]int{"lamp": 2}}
+	uc, _, slept := newTestControlUseCase(scene, cmd, nil)
+
+	report, err := uc.RunScene("t1", "s1", "", entities.SceneRunSourceAPI)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Actions[0].Attempts)
+	assert.Equal(t, entities.ActionSucceeded, report.Actions[0].Status)
+	assert.Equal(t, []time.Duration{200 * time.Millisecond, 200 * time.Millisecond}, *slept)
+}
+
+func TestRunScene_ContinuePolicyReportsPartialRun(t *testing.T) {
+	scene := &entities.Scene{ID: "s1", Actions: entities.Actions{switchAction("broken"), switchAction("lamp")}}
+	cmd := &fakeTuyaCmd{failures: map[string]int{"broken": 1}}
+	uc, _, _ := newTestControlUseCase(scene, cmd, nil)
+
+	report, err := uc.RunScene("t1", "s1", "", entities.SceneRunSourceAPI)
+	require.Error(t, err)
+	require.NotNil(t, report)
+
+	assert.Equal(t, entities.SceneRunPartial, report.Status)
+	assert.False(t, report.Stopped)
+	assert.Equal(t, entities.ActionFailed, report.Actions[0].Status)
+	assert.Equal(t, entities.ActionSucceeded, report.Actions[1].Status)
+}
+
+func TestRunScene_StopOnErrorSkipsLaterSteps(t *testing.T) {
+	scene := &entities.Scene{
+		ID:          "s1",
+		ErrorPolicy: entities.ErrorPolicyStopOnError,
+		Actions:     entities.Actions{switchAction("broken"), switchAction("lamp"), switchAction("tv")},
+	}
+	cmd := &fakeTuyaCmd{failures: map[string]int{"broken": 1}}
+	uc, _, _ := newTestControlUseCase(scene, cmd, nil)
+
+	report, err := uc.RunScene("t1", "s1", "", entities.SceneRunSourceAPI)
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"broken"}, cmd.calls)
+	assert.True(t, report.Stopped)
+	assert.Equal(t, entities.SceneRunFailed, report.Status)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 2, report.Skipped)
+}
+
+func TestRunScene_ParallelGroupRunsAsOneStep(t *testing.T) {
+	a, b := switchAction("lamp1"), switchAction("lamp2")
+	a.ParallelGroup, b.ParallelGroup = "lights", "lights"
+	scene := &entities.Scene{
+		ID:          "s1",
+		ErrorPolicy: entities.ErrorPolicyStopOnError,
+		Actions:     entities.Actions{a, b, switchAction("tv")},
+	}
+	// One failure inside the group: its sibling still runs, the next step is skipped
+	cmd := &fakeTuyaCmd{failures: map[string]int{"lamp1": 1}}
+	uc, _, _ := newTestControlUseCase(scene, cmd, nil)
+
+	report, err := uc.RunScene("t1", "s1", "", entities.SceneRunSourceAPI)
+	require.Error(t, err)
+
+	assert.ElementsMatch(t, []string{"lamp1", "lamp2"}, cmd.calls)
+	assert.Equal(t, entities.ActionFailed, report.Actions[0].Status)
+	assert.Equal(t, entities.ActionSucceeded, report.Actions[1].Status)
+	assert.Equal(t, entities.ActionSkipped, report.Actions[2].Status)
+	assert.Equal(t, entities.SceneRunPartial, report.Status)
+}
+
+func TestRunScene_ConditionSkipsAction(t *testing.T) {
+	guarded := switchAction("curtain")
+	guarded.Condition = &entities.ActionCondition{DeviceID: "sensor", Code: "presence_state", Operator: "eq", Value: "presence"}
+	allowed := switchAction("lamp")
+	allowed.Condition = &entities.ActionCondition{DeviceID: "sensor", Code: "lux", Operator: "lt", Value: 100}
+	scene := &entities.Scene{ID: "s1", Actions: entities.Actions{guarded, allowed}}
+	statuses := fakeStatusReader{"sensor/presence_state": "none", "sensor/lux": "40"}
+	cmd := &fakeTuyaCmd{}
+	uc, _, _ := newTestControlUseCase(scene, cmd, statuses)
+
+	report, err := uc.RunScene("t1", "s1", "", entities.SceneRunSourceAPI)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"lamp"}, cmd.calls)
+	assert.Equal(t, entities.ActionSkipped, report.Actions[0].Status)
+	assert.Contains(t, report.Actions[0].Error, "condition not met")
+	assert.Equal(t, entities.ActionSucceeded, report.Actions[1].Status)
+	assert.Equal(t, entities.SceneRunSucceeded, report.Status)
+}
+
+func TestRunScene_SceneNotFound(t *testing.T) {
+	uc, runRepo, _ := newTestControlUseCase(nil, &fakeTuyaCmd{}, nil)
+
+	report, err := uc.RunScene("t1", "missing", "", entities.SceneRunSourceAPI)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	assert.Empty(t, runRepo.runs)
+}
+
+func TestValidateSceneActions(t *testing.T) {
+	valid := switchAction("lamp")
+	valid.Condition = &entities.ActionCondition{DeviceID: "sensor", Code: "lux", Operator: "gte", Value: "10"}
+	assert.Empty(t, validateSceneActions(entities.Actions{valid}))
+
+	bad := entities.Action{DelayMs: -1, Retries: 9, Condition: &entities.ActionCondition{Operator: "gt", Value: "bright"}}
+	fields := map[string]bool{}
+	for _, d := range validateSceneActions(entities.Actions{bad}) {
+		fields[d.Field] = true
+	}
+	assert.True(t, fields["actions[0]"])
+	assert.True(t, fields["actions[0].delay_ms"])
+	assert.True(t, fields["actions[0].retries"])
+	assert.True(t, fields["actions[0].condition"])
+	assert.True(t, fields["actions[0].condition.value"])
+}
+
+func TestRunScene_QueuesTopicActionsWhileBrokerIsDown(t *testing.T) {
+	utils.AppConfig = nil
+	cfg := utils.GetConfig()
+	badger, err := infrastructure.NewBadgerService(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = badger.Close() })
+	outbox := infrastructure.NewMqttOutbox(badger, time.Hour, 0)
+	mqttSvc := infrastructure.NewMqttService(cfg) // Never connected
+	mqttSvc.SetOutbox(outbox, time.Hour)
+	t.Cleanup(mqttSvc.Close)
+
+	scene := &entities.Scene{ID: "s1", Name: "Blinds", Actions: entities.Actions{
+		{Topic: "blinds/cmd", Value: "open"},
+		{Topic: "blinds/cmd", Value: "tilt"},
+		switchAction("lamp"),
+	}}
+	uc, _, _ := newTestControlUseCase(scene, &fakeTuyaCmd{}, nil)
+	uc.mqttSvc = mqttSvc
+
+	report, err := uc.RunScene("t1", "s1", "", entities.SceneRunSourceAPI)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ActionQueued, report.Actions[0].Status)
+	assert.Equal(t, entities.ActionQueued, report.Actions[1].Status)
+	assert.Equal(t, entities.ActionSucceeded, report.Actions[2].Status)
+	assert.Equal(t, 2, report.Queued)
+	assert.Equal(t, 1, report.Succeeded)
+	assert.Equal(t, 2, outbox.Len(), "actions on the same topic must both be queued")
+
+	// Running the scene again replaces its identical pending commands instead of adding duplicates
+	_, err = uc.RunScene("t1", "s1", "", entities.SceneRunSourceAPI)
+	require.NoError(t, err)
+	assert.Equal(t, 2, outbox.Len())
+}
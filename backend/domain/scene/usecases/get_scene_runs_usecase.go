@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/repositories"
+)
+
+type GetSceneRunsUseCase struct {
+	repo    repositories.ISceneRepository
+	runRepo repositories.ISceneRunRepository
+}
+
+func NewGetSceneRunsUseCase(repo repositories.ISceneRepository, runRepo repositories.ISceneRunRepository) *GetSceneRunsUseCase {
+	return &GetSceneRunsUseCase{repo: repo, runRepo: runRepo}
+}
+
+// ListRuns returns the most recent run reports of a scene, newest first
+func (u *GetSceneRunsUseCase) ListRuns(terminalID, sceneID string, limit int) ([]scene_dtos.SceneRunReportDTO, error) {
+	if _, err := u.repo.GetByID(terminalID, sceneID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	runs, err := u.runRepo.GetByScene(terminalID, sceneID, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]scene_dtos.SceneRunReportDTO, 0, len(runs))
+	for _, r := range runs {
+		result = append(result, toSceneRunReport(r))
+	}
+	return result, nil
+}
+
+// GetRun returns a single run report
+func (u *GetSceneRunsUseCase) GetRun(terminalID, sceneID, runID string) (*scene_dtos.SceneRunReportDTO, error) {
+	run, err := u.runRepo.GetByID(terminalID, sceneID, runID)
+	if err != nil {
+		return nil, err
+	}
+	report := toSceneRunReport(*run)
+	return &report, nil
+}
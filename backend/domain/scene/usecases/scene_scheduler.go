@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"sensio/domain/common/utils"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/entities"
+	"sensio/domain/scene/repositories"
+	"sync"
+	"time"
+)
+
+// SceneRunner executes a stored scene (implemented by ControlSceneUseCase)
+type SceneRunner interface {
+	RunScene(terminalID, id, accessToken, source string) (*scene_dtos.SceneRunReportDTO, error)
+}
+
+// AccessTokenProvider supplies the Tuya access token used for scheduled runs
+type AccessTokenProvider interface {
+	GetTuyaAccessToken() (string, error)
+}
+
+// SceneScheduler polls persisted triggers and runs their scenes when due.
+// All schedule state lives in the database, so a restart simply resumes from next_run_at;
+// runs that became due while the server was down fire once if still inside the catch-up
+// window and are otherwise recorded as missed.
+type SceneScheduler struct {
+	repo      repositories.ISceneTriggerRepository
+	runner    SceneRunner
+	tokens    AccessTokenProvider
+	terminals TerminalLookup
+	interval  time.Duration
+	catchUp   time.Duration
+	now       func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func NewSceneScheduler(repo repositories.ISceneTriggerRepository, runner SceneRunner, tokens AccessTokenProvider, terminals TerminalLookup, interval, catchUp time.Duration) *SceneScheduler {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	if catchUp < 0 {
+		catchUp = 0
+	}
+	return &SceneScheduler{
+		repo:      repo,
+		runner:    runner,
+		tokens:    tokens,
+		terminals: terminals,
+		interval:  interval,
+		catchUp:   catchUp,
+		now:       time.Now,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start runs the polling loop in the background until Stop is called
+func (s *SceneScheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		s.Tick()
+		for {
+			select {
+			case <-ticker.C:
+				s.Tick()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	utils.LogInfo("SceneScheduler: started (interval=%s, catch-up=%s)", s.interval, s.catchUp)
+}
+
+// Stop terminates the polling loop
+func (s *SceneScheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Tick processes every trigger that is due at the current time
+func (s *SceneScheduler) Tick() {
+	now := s.now()
+	due, err := s.repo.GetDue(now)
+	if err != nil {
+		utils.LogError("SceneScheduler: failed to load due triggers: %v", err)
+		return
+	}
+	for i := range due {
+		s.fire(&due[i], now)
+	}
+}
+
+func (s *SceneScheduler) fire(trigger *entities.SceneTrigger, now time.Time) {
+	scheduledAt := *trigger.NextRunAt
+	run := &entities.SceneTriggerRun{
+		TriggerID:   trigger.ID,
+		SceneID:     trigger.SceneID,
+		TerminalID:  trigger.TerminalID,
+		ScheduledAt: scheduledAt,
+		StartedAt:   now,
+	}
+
+	if now.Sub(scheduledAt) > s.catchUp {
+		run.Status = entities.TriggerRunMissed
+		utils.LogWarn("SceneScheduler: trigger %s missed its run at %s", trigger.ID, scheduledAt.Format(time.RFC3339))
+	} else if err := s.execute(trigger); err != nil {
+		run.Status = entities.TriggerRunFailed
+		run.Error = err.Error()
+		utils.LogError("SceneScheduler: trigger %s failed to run scene %s: %v", trigger.ID, trigger.SceneID, err)
+	} else {
+		run.Status = entities.TriggerRunSucceeded
+		utils.LogInfo("SceneScheduler: trigger %s ran scene %s", trigger.ID, trigger.SceneID)
+	}
+	run.FinishedAt = s.now()
+
+	if err := s.repo.SaveRun(run); err != nil {
+		utils.LogError("SceneScheduler: failed to record run for trigger %s: %v", trigger.ID, err)
+	}
+
+	trigger.LastRunAt = &run.FinishedAt
+	trigger.LastStatus = run.Status
+	trigger.NextRunAt = nil
+	if trigger.Type == entities.TriggerTypeDelay {
+		trigger.Enabled = false
+	} else {
+		// Follows the terminal when its timezone changed since the trigger was armed
+		trigger.Timezone = terminalTimezone(s.terminals, trigger.TerminalID)
+		next, err := NextTriggerRun(*trigger, now)
+		if err != nil {
+			utils.LogError("SceneScheduler: disabling trigger %s: %v", trigger.ID, err)
+			trigger.Enabled = false
+		} else if !next.IsZero() {
+			trigger.NextRunAt = &next
+		}
+	}
+
+	if err := s.repo.Save(trigger); err != nil {
+		utils.LogError("SceneScheduler: failed to update trigger %s: %v", trigger.ID, err)
+	}
+}
+
+func (s *SceneScheduler) execute(trigger *entities.SceneTrigger) error {
+	accessToken := ""
+	if s.tokens != nil {
+		token, err := s.tokens.GetTuyaAccessToken()
+		if err != nil {
+			return err
+		}
+		accessToken = token
+	}
+	_, err := s.runner.RunScene(trigger.TerminalID, trigger.SceneID, accessToken, entities.SceneRunSourceSchedule)
+	return err
+}
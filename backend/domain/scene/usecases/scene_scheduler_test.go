@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"errors"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/entities"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTriggerRepo struct {
+	triggers map[string]*entities.SceneTrigger
+	runs     []entities.SceneTriggerRun
+}
+
+func newFakeTriggerRepo(triggers ...entities.SceneTrigger) *fakeTriggerRepo {
+	r := &fakeTriggerRepo{triggers: map[string]*entities.SceneTrigger{}}
+	for i := range triggers {
+		t := triggers[i]
+		r.triggers[t.ID] = &t
+	}
+	return r
+}
+
+func (r *fakeTriggerRepo) Save(trigger *entities.SceneTrigger) error {
+	t := *trigger
+	r.triggers[t.ID] = &t
+	return nil
+}
+
+func (r *fakeTriggerRepo) GetByID(terminalID, id string) (*entities.SceneTrigger, error) {
+	t, ok := r.triggers[id]
+	if !ok || t.TerminalID != terminalID {
+		return nil, errors.New("record not found")
+	}
+	found := *t
+	return &found, nil
+}
+
+func (r *fakeTriggerRepo) GetAll(terminalID string) ([]entities.SceneTrigger, error) {
+	var out []entities.SceneTrigger
+	for _, t := range r.triggers {
+		if t.TerminalID == terminalID {
+			out = append(out, *t)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeTriggerRepo) GetDue(now time.Time) ([]entities.SceneTrigger, error) {
+	var out []entities.SceneTrigger
+	for _, t := range r.triggers {
+		if t.Enabled && t.NextRunAt != nil && !t.NextRunAt.After(now) {
+			out = append(out, *t)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeTriggerRepo) Delete(terminalID, id string) error {
+	delete(r.triggers, id)
+	return nil
+}
+
+func (r *fakeTriggerRepo) SaveRun(run *entities.SceneTriggerRun) error {
+	r.runs = append(r.runs, *run)
+	return nil
+}
+
+func (r *fakeTriggerRepo) GetRuns(triggerID string, limit int) ([]entities.SceneTriggerRun, error) {
+	return r.runs, nil
+}
+
+type fakeSceneRunner struct {
+	calls []string
+	err   error
+}
+
+func (f *fakeSceneRunner) RunScene(terminalID, id, accessToken, source string) (*scene_dtos.SceneRunReportDTO, error) {
+	f.calls = append(f.calls, id)
+	return &scene_dtos.SceneRunReportDTO{SceneID: id, Source: source}, f.err
+}
+
+func newTestScheduler(repo *fakeTriggerRepo, runner *fakeSceneRunner, now time.Time) *SceneScheduler {
+	s := NewSceneScheduler(repo, runner, nil, nil, time.Second, 5*time.Minute)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestSceneScheduler_CronRunsAndReArms(t *testing.T) {
+	now := time.Date(2026, 3, 2, 7, 30, 10, 0, time.UTC)
+	due := time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC)
+	repo := newFakeTriggerRepo(entities.SceneTrigger{
+		ID: "t1", TerminalID: "term", SceneID: "scene-1", Type: entities.TriggerTypeCron,
+		CronExpr: "30 7 * * *", Timezone: "UTC", Enabled: true, NextRunAt: &due,
+	})
+	runner := &fakeSceneRunner{}
+
+	newTestScheduler(repo, runner, now).Tick()
+
+	assert.Equal(t, []string{"scene-1"}, runner.calls)
+	require.Len(t, repo.runs, 1)
+	assert.Equal(t, entities.TriggerRunSucceeded, repo.runs[0].Status)
+	assert.Equal(t, due, repo.runs[0].ScheduledAt)
+
+	stored := repo.triggers["t1"]
+	require.NotNil(t, stored.NextRunAt)
+	assert.Equal(t, time.Date(2026, 3, 3, 7, 30, 0, 0, time.UTC), *stored.NextRunAt)
+	assert.Equal(t, entities.TriggerRunSucceeded, stored.LastStatus)
+}
+
+func TestSceneScheduler_MissedOutsideCatchUpWindow(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC)
+	repo := newFakeTriggerRepo(entities.SceneTrigger{
+		ID: "t1", TerminalID: "term", SceneID: "scene-1", Type: entities.TriggerTypeCron,
+		CronExpr: "30 7 * * *", Timezone: "UTC", Enabled: true, NextRunAt: &due,
+	})
+	runner := &fakeSceneRunner{}
+
+	newTestScheduler(repo, runner, now).Tick()
+
+	assert.Empty(t, runner.calls)
+	require.Len(t, repo.runs, 1)
+	assert.Equal(t, entities.TriggerRunMissed, repo.runs[0].Status)
+	assert.Equal(t, time.Date(2026, 3, 3, 7, 30, 0, 0, time.UTC), *repo.triggers["t1"].NextRunAt)
+}
+
+func TestSceneScheduler_DelayTriggerFiresOnce(t *testing.T) {
+	now := time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC)
+	due := now.Add(-time.Second)
+	repo := newFakeTriggerRepo(entities.SceneTrigger{
+		ID: "t1", TerminalID: "term", SceneID: "scene-1", Type: entities.TriggerTypeDelay,
+		DelaySeconds: 60, Timezone: "UTC", Enabled: true, NextRunAt: &due,
+	})
+	runner := &fakeSceneRunner{err: errors.New("device offline")}
+	s := newTestScheduler(repo, runner, now)
+
+	s.Tick()
+	s.Tick()
+
+	assert.Len(t, runner.calls, 1)
+	require.Len(t, repo.runs, 1)
+	assert.Equal(t, entities.TriggerRunFailed, repo.runs[0].Status)
+	assert.Equal(t, "device offline", repo.runs[0].Error)
+	assert.False(t, repo.triggers["t1"].Enabled)
+	assert.Nil(t, repo.triggers["t1"].NextRunAt)
+}
@@ -0,0 +1,279 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sensio/domain/common/utils"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/entities"
+	"sensio/domain/scene/repositories"
+	scene_utils "sensio/domain/scene/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrTriggerNotFound is returned when a trigger does not exist for the terminal
+var ErrTriggerNotFound = errors.New("trigger not found")
+
+// SceneTriggerUseCase manages trigger definitions for scheduled scenes.
+// Triggers are scheduled in the timezone of their terminal.
+type SceneTriggerUseCase struct {
+	repo      repositories.ISceneTriggerRepository
+	sceneRepo repositories.ISceneRepository
+	terminals TerminalLookup
+	now       func() time.Time
+}
+
+func NewSceneTriggerUseCase(repo repositories.ISceneTriggerRepository, sceneRepo repositories.ISceneRepository, terminals TerminalLookup) *SceneTriggerUseCase {
+	return &SceneTriggerUseCase{
+		repo:      repo,
+		sceneRepo: sceneRepo,
+		terminals: terminals,
+		now:       time.Now,
+	}
+}
+
+func (u *SceneTriggerUseCase) CreateTrigger(terminalID string, req scene_dtos.SceneTriggerRequestDTO) (*scene_dtos.SceneTriggerIDResponseDTO, error) {
+	trigger := &entities.SceneTrigger{
+		ID:         uuid.New().String(),
+		TerminalID: terminalID,
+	}
+	if err := u.apply(trigger, req); err != nil {
+		return nil, err
+	}
+	if err := u.repo.Save(trigger); err != nil {
+		return nil, err
+	}
+	return &scene_dtos.SceneTriggerIDResponseDTO{TriggerID: trigger.ID, NextRunAt: formatOptionalTime(trigger.NextRunAt)}, nil
+}
+
+func (u *SceneTriggerUseCase) UpdateTrigger(terminalID, id string, req scene_dtos.SceneTriggerRequestDTO) (*scene_dtos.SceneTriggerIDResponseDTO, error) {
+	trigger, err := u.load(terminalID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.apply(trigger, req); err != nil {
+		return nil, err
+	}
+	if err := u.repo.Save(trigger); err != nil {
+		return nil, err
+	}
+	return &scene_dtos.SceneTriggerIDResponseDTO{TriggerID: trigger.ID, NextRunAt: formatOptionalTime(trigger.NextRunAt)}, nil
+}
+
+func (u *SceneTriggerUseCase) GetTrigger(terminalID, id string) (*scene_dtos.SceneTriggerResponseDTO, error) {
+	trigger, err := u.load(terminalID, id)
+	if err != nil {
+		return nil, err
+	}
+	dto := toSceneTriggerDTO(*trigger)
+	return &dto, nil
+}
+
+func (u *SceneTriggerUseCase) ListTriggers(terminalID string) ([]scene_dtos.SceneTriggerResponseDTO, error) {
+	triggers, err := u.repo.GetAll(terminalID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]scene_dtos.SceneTriggerResponseDTO, 0, len(triggers))
+	for _, t := range triggers {
+		result = append(result, toSceneTriggerDTO(t))
+	}
+	return result, nil
+}
+
+func (u *SceneTriggerUseCase) DeleteTrigger(terminalID, id string) error {
+	if _, err := u.load(terminalID, id); err != nil {
+		return err
+	}
+	return u.repo.Delete(terminalID, id)
+}
+
+func (u *SceneTriggerUseCase) ListRuns(terminalID, id string, limit int) ([]scene_dtos.SceneTriggerRunDTO, error) {
+	if _, err := u.load(terminalID, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	runs, err := u.repo.GetRuns(id, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]scene_dtos.SceneTriggerRunDTO, 0, len(runs))
+	for _, r := range runs {
+		result = append(result, scene_dtos.SceneTriggerRunDTO{
+			ID:          r.ID,
+			ScheduledAt: r.ScheduledAt.Format(time.RFC3339),
+			StartedAt:   r.StartedAt.Format(time.RFC3339),
+			FinishedAt:  r.FinishedAt.Format(time.RFC3339),
+			Status:      r.Status,
+			Error:       r.Error,
+		})
+	}
+	return result, nil
+}
+
+// apply validates the request, copies it onto the trigger and re-arms its next run
+func (u *SceneTriggerUseCase) apply(trigger *entities.SceneTrigger, req scene_dtos.SceneTriggerRequestDTO) error {
+	var details []utils.ValidationErrorDetail
+
+	if _, err := u.sceneRepo.GetByID(trigger.TerminalID, req.SceneID); err != nil {
+		details = append(details, utils.ValidationErrorDetail{Field: "scene_id", Message: "scene not found for this terminal"})
+	}
+
+	switch req.Type {
+	case entities.TriggerTypeCron:
+		if _, err := scene_utils.ParseCron(req.CronExpr); err != nil {
+			details = append(details, utils.ValidationErrorDetail{Field: "cron_expr", Message: err.Error()})
+		}
+	case entities.TriggerTypeSun:
+		if req.SunEvent != string(scene_utils.SunEventSunrise) && req.SunEvent != string(scene_utils.SunEventSunset) {
+			details = append(details, utils.ValidationErrorDetail{Field: "sun_event", Message: "must be 'sunrise' or 'sunset'"})
+		}
+		if req.Latitude < -90 || req.Latitude > 90 {
+			details = append(details, utils.ValidationErrorDetail{Field: "latitude", Message: "must be between -90 and 90"})
+		}
+		if req.Longitude < -180 || req.Longitude > 180 {
+			details = append(details, utils.ValidationErrorDetail{Field: "longitude", Message: "must be between -180 and 180"})
+		}
+		if req.OffsetMinutes < -720 || req.OffsetMinutes > 720 {
+			details = append(details, utils.ValidationErrorDetail{Field: "offset_minutes", Message: "must be between -720 and 720"})
+		}
+	case entities.TriggerTypeDelay:
+		if req.DelaySeconds <= 0 {
+			details = append(details, utils.ValidationErrorDetail{Field: "delay_seconds", Message: "must be greater than 0"})
+		}
+	default:
+		details = append(details, utils.ValidationErrorDetail{Field: "type", Message: "must be one of: cron, sun, delay"})
+	}
+
+	if len(details) > 0 {
+		return utils.NewValidationError("Validation Error", details)
+	}
+
+	cronExpr := strings.TrimSpace(req.CronExpr)
+	timezone := terminalTimezone(u.terminals, trigger.TerminalID)
+	enabled := req.Enabled == nil || *req.Enabled
+	rescheduled := trigger.Type != req.Type || trigger.CronExpr != cronExpr || trigger.SunEvent != req.SunEvent ||
+		trigger.Latitude != req.Latitude || trigger.Longitude != req.Longitude || trigger.OffsetMinutes != req.OffsetMinutes ||
+		trigger.DelaySeconds != req.DelaySeconds || trigger.Timezone != timezone || (enabled && !trigger.Enabled)
+
+	trigger.SceneID = req.SceneID
+	trigger.Name = req.Name
+	trigger.Type = req.Type
+	trigger.CronExpr = cronExpr
+	trigger.SunEvent = req.SunEvent
+	trigger.Latitude = req.Latitude
+	trigger.Longitude = req.Longitude
+	trigger.OffsetMinutes = req.OffsetMinutes
+	trigger.DelaySeconds = req.DelaySeconds
+	trigger.Timezone = timezone
+	trigger.Enabled = enabled
+
+	// A new schedule starts afresh, so a delay trigger that already ran can be armed again
+	if rescheduled {
+		trigger.LastRunAt = nil
+		trigger.LastStatus = ""
+	}
+	// An unchanged schedule keeps its fire time, so renaming a pending delay trigger does not postpone it
+	if !trigger.Enabled || rescheduled {
+		trigger.NextRunAt = nil
+	}
+	if trigger.Enabled && trigger.NextRunAt == nil {
+		next, err := NextTriggerRun(*trigger, u.now())
+		if err != nil {
+			return err
+		}
+		if !next.IsZero() {
+			trigger.NextRunAt = &next
+		}
+	}
+	return nil
+}
+
+// terminalTimezone returns the IANA timezone the triggers of a terminal are scheduled in: the
+// terminal's own, or SCENE_SCHEDULER_DEFAULT_TIMEZONE when it has none
+func terminalTimezone(terminals TerminalLookup, terminalID string) string {
+	if terminals != nil {
+		if terminal, err := terminals.GetByID(terminalID); err == nil && terminal != nil && terminal.Timezone != "" {
+			if _, err := time.LoadLocation(terminal.Timezone); err == nil {
+				return terminal.Timezone
+			}
+			utils.LogWarn("Scene triggers: terminal %s has an unknown timezone %q", terminalID, terminal.Timezone)
+		}
+	}
+	return utils.GetConfig().SceneSchedulerDefaultTimezone
+}
+
+func (u *SceneTriggerUseCase) load(terminalID, id string) (*entities.SceneTrigger, error) {
+	trigger, err := u.repo.GetByID(terminalID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTriggerNotFound
+		}
+		return nil, err
+	}
+	return trigger, nil
+}
+
+// NextTriggerRun computes the next instant (strictly after `after`) at which the trigger should fire.
+// A zero time means the trigger has nothing further scheduled (e.g. a delay trigger that already ran).
+func NextTriggerRun(trigger entities.SceneTrigger, after time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(trigger.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q: %w", trigger.Timezone, err)
+	}
+	localAfter := after.In(loc)
+
+	switch trigger.Type {
+	case entities.TriggerTypeCron:
+		schedule, err := scene_utils.ParseCron(trigger.CronExpr)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return schedule.Next(localAfter).UTC(), nil
+	case entities.TriggerTypeSun:
+		offset := time.Duration(trigger.OffsetMinutes) * time.Minute
+		return scene_utils.NextSunEvent(scene_utils.SunEvent(trigger.SunEvent), localAfter, trigger.Latitude, trigger.Longitude, offset).UTC(), nil
+	case entities.TriggerTypeDelay:
+		// One-shot: armed relative to when it was (re)configured, never again after it has run
+		if trigger.LastRunAt != nil && trigger.NextRunAt == nil {
+			return time.Time{}, nil
+		}
+		return after.Add(time.Duration(trigger.DelaySeconds) * time.Second).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported trigger type %q", trigger.Type)
+	}
+}
+
+func toSceneTriggerDTO(t entities.SceneTrigger) scene_dtos.SceneTriggerResponseDTO {
+	return scene_dtos.SceneTriggerResponseDTO{
+		ID:            t.ID,
+		TerminalID:    t.TerminalID,
+		SceneID:       t.SceneID,
+		Name:          t.Name,
+		Type:          t.Type,
+		CronExpr:      t.CronExpr,
+		SunEvent:      t.SunEvent,
+		Latitude:      t.Latitude,
+		Longitude:     t.Longitude,
+		OffsetMinutes: t.OffsetMinutes,
+		DelaySeconds:  t.DelaySeconds,
+		Timezone:      t.Timezone,
+		Enabled:       t.Enabled,
+		NextRunAt:     formatOptionalTime(t.NextRunAt),
+		LastRunAt:     formatOptionalTime(t.LastRunAt),
+		LastStatus:    t.LastStatus,
+	}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
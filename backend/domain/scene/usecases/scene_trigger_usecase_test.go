@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/utils"
+	scene_dtos "sensio/domain/scene/dtos"
+	"sensio/domain/scene/entities"
+	terminal_entities "sensio/domain/terminal/terminal/entities"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTerminalLookup map[string]terminal_entities.Terminal
+
+func (f fakeTerminalLookup) GetByID(id string) (*terminal_entities.Terminal, error) {
+	t, ok := f[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &t, nil
+}
+
+func newTestTriggerUseCase(repo *fakeTriggerRepo, terminals fakeTerminalLookup, now time.Time) *SceneTriggerUseCase {
+	scene := &entities.Scene{ID: "s1", TerminalID: "t1", Name: "Evening"}
+	uc := NewSceneTriggerUseCase(repo, &fakeSceneRepo{scene: scene}, terminals)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestSceneTrigger_UsesTerminalTimezone(t *testing.T) {
+	utils.AppConfig = nil
+	_ = utils.GetConfig()
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	repo := newFakeTriggerRepo()
+	uc := newTestTriggerUseCase(repo, fakeTerminalLookup{"t1": {ID: "t1", Timezone: "Asia/Jakarta"}}, now)
+
+	resp, err := uc.CreateTrigger("t1", scene_dtos.SceneTriggerRequestDTO{SceneID: "s1", Type: entities.TriggerTypeCron, CronExpr: "30 7 * * *"})
+	require.NoError(t, err)
+
+	// 07:30 in Jakarta (UTC+7) is 00:30 UTC
+	assert.Equal(t, "2026-03-02T00:30:00Z", resp.NextRunAt)
+	assert.Equal(t, "Asia/Jakarta", repo.triggers[resp.TriggerID].Timezone)
+
+	// A terminal without a timezone falls back to SCENE_SCHEDULER_DEFAULT_TIMEZONE
+	uc = newTestTriggerUseCase(repo, fakeTerminalLookup{"t1": {ID: "t1"}}, now)
+	resp, err = uc.CreateTrigger("t1", scene_dtos.SceneTriggerRequestDTO{SceneID: "s1", Type: entities.TriggerTypeCron, CronExpr: "30 7 * * *"})
+	require.NoError(t, err)
+	assert.Equal(t, utils.GetConfig().SceneSchedulerDefaultTimezone, repo.triggers[resp.TriggerID].Timezone)
+}
+
+func TestSceneTrigger_UpdateReArmsDelayTriggerThatRan(t *testing.T) {
+	ranAt := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	now := ranAt.Add(time.Hour)
+	repo := newFakeTriggerRepo(entities.SceneTrigger{
+		ID: "tr1", TerminalID: "t1", SceneID: "s1", Type: entities.TriggerTypeDelay,
+		DelaySeconds: 60, Timezone: "UTC", Enabled: false, LastRunAt: &ranAt, LastStatus: entities.TriggerRunSucceeded,
+	})
+	uc := newTestTriggerUseCase(repo, fakeTerminalLookup{"t1": {ID: "t1", Timezone: "UTC"}}, now)
+
+	resp, err := uc.UpdateTrigger("t1", "tr1", scene_dtos.SceneTriggerRequestDTO{SceneID: "s1", Type: entities.TriggerTypeDelay, DelaySeconds: 120})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(2*time.Minute).Format(time.RFC3339), resp.NextRunAt)
+
+	stored := repo.triggers["tr1"]
+	assert.True(t, stored.Enabled)
+	assert.Nil(t, stored.LastRunAt)
+	assert.Empty(t, stored.LastStatus)
+}
+
+func TestSceneTrigger_UpdateKeepsHistoryWhenScheduleIsUnchanged(t *testing.T) {
+	ranAt := time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC)
+	now := ranAt.Add(time.Hour)
+	repo := newFakeTriggerRepo(entities.SceneTrigger{
+		ID: "tr1", TerminalID: "t1", SceneID: "s1", Type: entities.TriggerTypeCron,
+		CronExpr: "30 7 * * *", Timezone: "UTC", Enabled: true, LastRunAt: &ranAt, LastStatus: entities.TriggerRunSucceeded,
+	})
+	uc := newTestTriggerUseCase(repo, fakeTerminalLookup{"t1": {ID: "t1", Timezone: "UTC"}}, now)
+
+	resp, err := uc.UpdateTrigger("t1", "tr1", scene_dtos.SceneTriggerRequestDTO{SceneID: "s1", Name: "Morning", Type: entities.TriggerTypeCron, CronExpr: "30 7 * * *"})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-03T07:30:00Z", resp.NextRunAt)
+	assert.Equal(t, &ranAt, repo.triggers["tr1"].LastRunAt)
+}
+
+func TestSceneTrigger_UpdateKeepsFireTimeOfArmedDelayTrigger(t *testing.T) {
+	armedAt := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	fireAt := armedAt.Add(10 * time.Minute)
+	repo := newFakeTriggerRepo(entities.SceneTrigger{
+		ID: "tr1", TerminalID: "t1", SceneID: "s1", Type: entities.TriggerTypeDelay,
+		DelaySeconds: 600, Timezone: "UTC", Enabled: true, NextRunAt: &fireAt,
+	})
+	uc := newTestTriggerUseCase(repo, fakeTerminalLookup{"t1": {ID: "t1", Timezone: "UTC"}}, armedAt.Add(5*time.Minute))
+
+	resp, err := uc.UpdateTrigger("t1", "tr1", scene_dtos.SceneTriggerRequestDTO{SceneID: "s1", Name: "Lights out", Type: entities.TriggerTypeDelay, DelaySeconds: 600})
+	require.NoError(t, err)
+	assert.Equal(t, fireAt.Format(time.RFC3339), resp.NextRunAt, "a rename does not postpone the pending run")
+	assert.Equal(t, "Lights out", repo.triggers["tr1"].Name)
+}
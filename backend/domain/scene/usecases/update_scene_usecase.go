@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"sensio/domain/common/utils"
 	"sensio/domain/scene/entities"
 	"sensio/domain/scene/repositories"
 )
@@ -13,7 +14,11 @@ func NewUpdateSceneUseCase(repo repositories.ISceneRepository) *UpdateSceneUseCa
 	return &UpdateSceneUseCase{repo: repo}
 }
 
-func (u *UpdateSceneUseCase) UpdateScene(terminalID, id string, name string, actions entities.Actions) error {
+func (u *UpdateSceneUseCase) UpdateScene(terminalID, id string, name string, actions entities.Actions, errorPolicy string) error {
+	if details := validateSceneActions(actions); len(details) > 0 {
+		return utils.NewValidationError("Validation Error", details)
+	}
+
 	scene, err := u.repo.GetByID(terminalID, id)
 	if err != nil {
 		return err
@@ -25,6 +30,9 @@ func (u *UpdateSceneUseCase) UpdateScene(terminalID, id string, name string, act
 	if actions != nil {
 		scene.Actions = actions
 	}
+	if errorPolicy != "" {
+		scene.ErrorPolicy = errorPolicy
+	}
 
 	return u.repo.Save(scene)
 }
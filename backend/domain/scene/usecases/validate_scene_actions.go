@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/scene/entities"
+)
+
+const (
+	maxActionDelayMs = 10 * 60 * 1000 // 10 minutes
+	maxActionRetries = 5
+)
+
+// validateSceneActions checks the execution options of each action
+func validateSceneActions(actions entities.Actions) []utils.ValidationErrorDetail {
+	var details []utils.ValidationErrorDetail
+	for i, a := range actions {
+		field := fmt.Sprintf("actions[%d]", i)
+		if a.Topic == "" && a.DeviceID == "" && a.RoomID == "" {
+			details = append(details, utils.ValidationErrorDetail{Field: field, Message: "either topic, device_id or room_id is required"})
+		}
+		if a.RoomID != "" {
+			if _, ok := roomActionValue(a.Value); !ok {
+				details = append(details, utils.ValidationErrorDetail{Field: field + ".value", Message: "must be \"on\" or \"off\" for a room action"})
+			}
+		} else if a.ZoneID != "" {
+			details = append(details, utils.ValidationErrorDetail{Field: field + ".zone_id", Message: "requires room_id"})
+		}
+		if a.DelayMs < 0 || a.DelayMs > maxActionDelayMs {
+			details = append(details, utils.ValidationErrorDetail{Field: field + ".delay_ms", Message: fmt.Sprintf("must be between 0 and %d", maxActionDelayMs)})
+		}
+		if a.RetryDelayMs < 0 || a.RetryDelayMs > maxActionDelayMs {
+			details = append(details, utils.ValidationErrorDetail{Field: field + ".retry_delay_ms", Message: fmt.Sprintf("must be between 0 and %d", maxActionDelayMs)})
+		}
+		if a.Retries < 0 || a.Retries > maxActionRetries {
+			details = append(details, utils.ValidationErrorDetail{Field: field + ".retries", Message: fmt.Sprintf("must be between 0 and %d", maxActionRetries)})
+		}
+		if c := a.Condition; c != nil {
+			if c.DeviceID == "" || c.Code == "" {
+				details = append(details, utils.ValidationErrorDetail{Field: field + ".condition", Message: "device_id and code are required"})
+			}
+			switch c.Operator {
+			case "eq", "neq", "contains":
+			case "gt", "gte", "lt", "lte":
+				if _, ok := utils.ToFloat(c.Value); !ok {
+					details = append(details, utils.ValidationErrorDetail{Field: field + ".condition.value", Message: "must be numeric for operator " + c.Operator})
+				}
+			default:
+				details = append(details, utils.ValidationErrorDetail{Field: field + ".condition.operator", Message: "must be one of: eq, neq, gt, gte, lt, lte, contains"})
+			}
+		}
+	}
+	return details
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	base := time.Date(2026, 3, 2, 6, 59, 30, 0, jakarta) // Monday
+
+	cases := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2026, 3, 2, 7, 0, 0, 0, jakarta)},
+		{"daily at 07:30", "30 7 * * *", time.Date(2026, 3, 2, 7, 30, 0, 0, jakarta)},
+		{"step minutes", "*/15 * * * *", time.Date(2026, 3, 2, 7, 0, 0, 0, jakarta)},
+		{"weekdays only, next is tomorrow", "0 6 * * 1-5", time.Date(2026, 3, 3, 6, 0, 0, 0, jakarta)},
+		{"sunday as 7", "0 8 * * 7", time.Date(2026, 3, 8, 8, 0, 0, 0, jakarta)},
+		{"list", "0 9,18 * * *", time.Date(2026, 3, 2, 9, 0, 0, 0, jakarta)},
+		{"monthly macro", "@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, jakarta)},
+		{"dom OR dow", "0 0 15 * 3", time.Date(2026, 3, 4, 0, 0, 0, 0, jakarta)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := ParseCron(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, s.Next(base))
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, "expected error for %q", expr)
+	}
+}
+
+func TestParseCron_ImpossibleDate(t *testing.T) {
+	s, err := ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestSunEventTime_Jakarta(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	day := time.Date(2026, 6, 21, 12, 0, 0, 0, jakarta)
+
+	sunrise, ok := SunEventTime(SunEventSunrise, day, -6.2, 106.8)
+	require.True(t, ok)
+	sunset, ok := SunEventTime(SunEventSunset, day, -6.2, 106.8)
+	require.True(t, ok)
+
+	// Jakarta: sunrise around 06:00 WIB, sunset around 17:45 WIB all year round
+	assert.Equal(t, 21, sunrise.Day())
+	assert.InDelta(t, 6*60, sunrise.Hour()*60+sunrise.Minute(), 30)
+	assert.InDelta(t, 17*60+45, sunset.Hour()*60+sunset.Minute(), 30)
+}
+
+func TestNextSunEvent_PolarNight(t *testing.T) {
+	// Tromsø in mid-December: no sunrise; the next one is weeks away
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	require.NoError(t, err)
+	from := time.Date(2026, 12, 10, 12, 0, 0, 0, oslo)
+
+	next := NextSunEvent(SunEventSunrise, from, 69.65, 18.96, 0)
+	require.False(t, next.IsZero())
+	assert.True(t, next.After(time.Date(2027, 1, 10, 0, 0, 0, 0, oslo)))
+}
+
+func TestNextSunEvent_Offset(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	from := time.Date(2026, 6, 21, 12, 0, 0, 0, jakarta)
+
+	sunset, _ := SunEventTime(SunEventSunset, from, -6.2, 106.8)
+	next := NextSunEvent(SunEventSunset, from, -6.2, 106.8, -30*time.Minute)
+	assert.Equal(t, sunset.Add(-30*time.Minute), next)
+}
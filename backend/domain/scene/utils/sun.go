@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"math"
+	"time"
+)
+
+// SunEvent identifies which solar event a trigger follows
+type SunEvent string
+
+const (
+	SunEventSunrise SunEvent = "sunrise"
+	SunEventSunset  SunEvent = "sunset"
+)
+
+// zenithOfficial is the sun's zenith angle at official sunrise/sunset (accounts for refraction and disc radius)
+const zenithOfficial = 90.833
+
+// SunEventTime returns the instant of sunrise or sunset on the calendar date of `date` (in date's location)
+// at the given coordinates. ok is false when the sun does not rise or set that day (polar day/night).
+//
+// Uses the NOAA / "Almanac for Computers" sunrise equation, accurate to roughly a minute
+// for latitudes between the polar circles.
+func SunEventTime(event SunEvent, date time.Time, latitude, longitude float64) (time.Time, bool) {
+	dayOfYear := float64(date.YearDay())
+	lngHour := longitude / 15
+
+	approx := dayOfYear + ((6 - lngHour) / 24)
+	if event == SunEventSunset {
+		approx = dayOfYear + ((18 - lngHour) / 24)
+	}
+
+	meanAnomaly := (0.9856 * approx) - 3.289
+	trueLongitude := normalizeDegrees(meanAnomaly +
+		(1.916 * sinDeg(meanAnomaly)) +
+		(0.020 * sinDeg(2*meanAnomaly)) +
+		282.634)
+
+	rightAscension := normalizeDegrees(radToDeg(math.Atan(0.91764 * tanDeg(trueLongitude))))
+	// Right ascension must be in the same quadrant as the true longitude
+	lQuadrant := math.Floor(trueLongitude/90) * 90
+	raQuadrant := math.Floor(rightAscension/90) * 90
+	rightAscension = (rightAscension + (lQuadrant - raQuadrant)) / 15
+
+	sinDec := 0.39782 * sinDeg(trueLongitude)
+	cosDec := math.Cos(math.Asin(sinDec))
+
+	cosHourAngle := (cosDeg(zenithOfficial) - (sinDec * sinDeg(latitude))) / (cosDec * cosDeg(latitude))
+	if cosHourAngle > 1 || cosHourAngle < -1 {
+		return time.Time{}, false
+	}
+
+	hourAngle := 360 - radToDeg(math.Acos(cosHourAngle))
+	if event == SunEventSunset {
+		hourAngle = radToDeg(math.Acos(cosHourAngle))
+	}
+	hourAngle /= 15
+
+	localMeanTime := hourAngle + rightAscension - (0.06571 * approx) - 6.622
+	utcHours := math.Mod(localMeanTime-lngHour+48, 24)
+
+	y, m, d := date.Date()
+	midnightUTC := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	result := midnightUTC.Add(time.Duration(utcHours * float64(time.Hour))).Truncate(time.Second)
+
+	// The UTC hour may wrap across midnight relative to the local calendar day; pull it back onto that day.
+	localDay := time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+	for result.Before(localDay) {
+		result = result.Add(24 * time.Hour)
+	}
+	for !result.Before(localDay.AddDate(0, 0, 1)) {
+		result = result.Add(-24 * time.Hour)
+	}
+	return result.In(date.Location()), true
+}
+
+// NextSunEvent returns the first occurrence of the event (shifted by offset) strictly after t,
+// searching up to a year ahead. Returns the zero time when none exists (e.g. polar night).
+func NextSunEvent(event SunEvent, t time.Time, latitude, longitude float64, offset time.Duration) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, t.Location())
+	for i := 0; i < 366; i++ {
+		at, ok := SunEventTime(event, day, latitude, longitude)
+		if ok {
+			at = at.Add(offset)
+			if at.After(t) {
+				return at
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+func normalizeDegrees(d float64) float64 {
+	d = math.Mod(d, 360)
+	if d < 0 {
+		d += 360
+	}
+	return d
+}
+
+func degToRad(d float64) float64 { return d * math.Pi / 180 }
+func radToDeg(r float64) float64 { return r * 180 / math.Pi }
+func sinDeg(d float64) float64   { return math.Sin(degToRad(d)) }
+func cosDeg(d float64) float64   { return math.Cos(degToRad(d)) }
+func tanDeg(d float64) float64   { return math.Tan(degToRad(d)) }
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sensio/domain/common/dtos"
+	"sensio/domain/common/utils"
+	snapshot_dtos "sensio/domain/snapshot/dtos"
+	"sensio/domain/snapshot/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SnapshotController exposes home-state snapshots and undo of the last device change
+type SnapshotController struct {
+	snapshotUC *usecases.SnapshotUseCase
+	undoUC     *usecases.UndoUseCase
+}
+
+// Force Swaggo to detect DTOs
+var _ = snapshot_dtos.CreateSnapshotRequestDTO{}
+
+func NewSnapshotController(snapshotUC *usecases.SnapshotUseCase, undoUC *usecases.UndoUseCase) *SnapshotController {
+	return &SnapshotController{
+		snapshotUC: snapshotUC,
+		undoUC:     undoUC,
+	}
+}
+
+// CreateSnapshot handles POST /api/snapshots/:terminal_id
+// @Summary Capture a home snapshot
+// @Description Read the current state of every device of the terminal (live Tuya status, last-known IR AC params) and save it under a name. An existing snapshot with the same name is overwritten.
+// @Tags 11. Snapshots
+// @Accept json
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param request body snapshot_dtos.CreateSnapshotRequestDTO true "Snapshot name"
+// @Success 201 {object} dtos.StandardResponse{data=snapshot_dtos.SnapshotResponseDTO}
+// @Failure      400  {object}  dtos.ValidationErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/snapshots/{terminal_id} [post]
+func (c *SnapshotController) CreateSnapshot(ctx *gin.Context) {
+	var req snapshot_dtos.CreateSnapshotRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: "Validation Error",
+			Details: []utils.ValidationErrorDetail{
+				{Field: "payload", Message: "Invalid request body: " + err.Error()},
+			},
+		})
+		return
+	}
+
+	result, err := c.snapshotUC.CaptureSnapshot(ctx.Param("terminal_id"), req.Name, ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "CreateSnapshot", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.StandardResponse{
+		Status:  true,
+		Message: "Snapshot captured successfully",
+		Data:    result,
+	})
+}
+
+// ListSnapshots handles GET /api/snapshots/:terminal_id
+// @Summary List home snapshots
+// @Tags 11. Snapshots
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Success 200 {object} dtos.StandardResponse{data=[]snapshot_dtos.SnapshotResponseDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/snapshots/{terminal_id} [get]
+func (c *SnapshotController) ListSnapshots(ctx *gin.Context) {
+	result, err := c.snapshotUC.ListSnapshots(ctx.Param("terminal_id"))
+	if err != nil {
+		respondError(ctx, "ListSnapshots", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Snapshots retrieved successfully",
+		Data:    result,
+	})
+}
+
+// GetSnapshot handles GET /api/snapshots/:terminal_id/:snapshot_id
+// @Summary Get a home snapshot
+// @Tags 11. Snapshots
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param snapshot_id path string true "Snapshot UUID"
+// @Success 200 {object} dtos.StandardResponse{data=snapshot_dtos.SnapshotResponseDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/snapshots/{terminal_id}/{snapshot_id} [get]
+func (c *SnapshotController) GetSnapshot(ctx *gin.Context) {
+	result, err := c.snapshotUC.GetSnapshot(ctx.Param("terminal_id"), ctx.Param("snapshot_id"))
+	if err != nil {
+		respondError(ctx, "GetSnapshot", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Snapshot retrieved successfully",
+		Data:    result,
+	})
+}
+
+// DeleteSnapshot handles DELETE /api/snapshots/:terminal_id/:snapshot_id
+// @Summary Delete a home snapshot
+// @Tags 11. Snapshots
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param snapshot_id path string true "Snapshot UUID"
+// @Success 200 {object} dtos.StandardResponse
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/snapshots/{terminal_id}/{snapshot_id} [delete]
+func (c *SnapshotController) DeleteSnapshot(ctx *gin.Context) {
+	if err := c.snapshotUC.DeleteSnapshot(ctx.Param("terminal_id"), ctx.Param("snapshot_id")); err != nil {
+		respondError(ctx, "DeleteSnapshot", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Snapshot deleted successfully",
+	})
+}
+
+// RestoreSnapshot handles POST /api/snapshots/:terminal_id/:snapshot_id/restore
+// @Summary Restore a home snapshot
+// @Description Send each captured device only the codes that differ from its live state. Offline devices are reported and skipped. The restore can be reverted with a single undo.
+// @Tags 11. Snapshots
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Param snapshot_id path string true "Snapshot UUID"
+// @Success 200 {object} dtos.StandardResponse{data=snapshot_dtos.RestoreResultDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/snapshots/{terminal_id}/{snapshot_id}/restore [post]
+func (c *SnapshotController) RestoreSnapshot(ctx *gin.Context) {
+	result, err := c.snapshotUC.RestoreSnapshot(ctx.Param("terminal_id"), ctx.Param("snapshot_id"), ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "RestoreSnapshot", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Snapshot restored",
+		Data:    result,
+	})
+}
+
+// ListChanges handles GET /api/snapshots/:terminal_id/changes
+// @Summary List undoable changes
+// @Description Return the journaled device changes of the terminal, newest first. Only the first entry is reverted by undo.
+// @Tags 11. Snapshots
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Success 200 {object} dtos.StandardResponse{data=[]snapshot_dtos.StateChangeResponseDTO}
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/snapshots/{terminal_id}/changes [get]
+func (c *SnapshotController) ListChanges(ctx *gin.Context) {
+	result, err := c.undoUC.ListChanges(ctx.Param("terminal_id"))
+	if err != nil {
+		respondError(ctx, "ListChanges", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Changes retrieved successfully",
+		Data:    result,
+	})
+}
+
+// Undo handles POST /api/snapshots/:terminal_id/undo
+// @Summary Revert the last change
+// @Description Send the devices touched by the most recent command or snapshot restore back to their previous values
+// @Tags 11. Snapshots
+// @Produce json
+// @Param terminal_id path string true "Terminal UUID"
+// @Success 200 {object} dtos.StandardResponse{data=snapshot_dtos.UndoResultDTO}
+// @Failure      404  {object}  dtos.ErrorResponse
+// @Failure      500  {object}  dtos.ErrorResponse
+// @Security BearerAuth
+// @Router /api/snapshots/{terminal_id}/undo [post]
+func (c *SnapshotController) Undo(ctx *gin.Context) {
+	result, err := c.undoUC.UndoLastChange(ctx.Param("terminal_id"), ctx.GetString("access_token"))
+	if err != nil {
+		respondError(ctx, "Undo", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.StandardResponse{
+		Status:  true,
+		Message: "Last change reverted",
+		Data:    result,
+	})
+}
+
+func respondError(ctx *gin.Context, op string, err error) {
+	var valErr *utils.ValidationError
+	if errors.As(err, &valErr) {
+		ctx.JSON(http.StatusBadRequest, dtos.StandardResponse{
+			Status:  false,
+			Message: valErr.Message,
+			Details: valErr.Details,
+		})
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	message := http.StatusText(statusCode)
+	switch {
+	case errors.Is(err, usecases.ErrSnapshotNotFound):
+		statusCode, message = http.StatusNotFound, http.StatusText(http.StatusNotFound)
+	case errors.Is(err, usecases.ErrNothingToUndo):
+		statusCode, message = http.StatusNotFound, "Nothing to undo"
+	default:
+		utils.LogError("SnapshotController.%s: %v", op, err)
+	}
+	ctx.JSON(statusCode, dtos.StandardResponse{
+		Status:  false,
+		Message: message,
+	})
+}
@@ -0,0 +1,62 @@
+package dtos
+
+// CreateSnapshotRequestDTO for POST /api/snapshots/:terminal_id
+type CreateSnapshotRequestDTO struct {
+	Name string `json:"name" binding:"required" example:"Movie night"` // Capturing an existing name overwrites it
+}
+
+// SnapshotDeviceDTO represents the captured state of one device
+type SnapshotDeviceDTO struct {
+	DeviceID string                 `json:"device_id" example:"bf1234567890abcdef"`
+	RemoteID string                 `json:"remote_id,omitempty"`
+	Name     string                 `json:"name,omitempty" example:"Living Room Lamp"`
+	Values   map[string]interface{} `json:"values" swaggertype:"object"`
+}
+
+// SnapshotResponseDTO represents a saved home snapshot
+type SnapshotResponseDTO struct {
+	ID         string              `json:"id"`
+	TerminalID string              `json:"terminal_id"`
+	Name       string              `json:"name" example:"Movie night"`
+	Devices    []SnapshotDeviceDTO `json:"devices"`
+	Skipped    int                 `json:"skipped"` // Devices whose state could not be read at capture time
+	CreatedAt  string              `json:"created_at"`
+	UpdatedAt  string              `json:"updated_at"`
+}
+
+// DeviceApplyResultDTO reports what happened to one device during a restore or undo
+type DeviceApplyResultDTO struct {
+	DeviceID string                 `json:"device_id"`
+	RemoteID string                 `json:"remote_id,omitempty"`
+	Status   string                 `json:"status" example:"restored"` // restored, reverted, unchanged, offline, failed
+	Sent     map[string]interface{} `json:"sent,omitempty" swaggertype:"object"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// RestoreResultDTO summarizes a snapshot restore
+type RestoreResultDTO struct {
+	SnapshotID string                 `json:"snapshot_id"`
+	Name       string                 `json:"name"`
+	Restored   int                    `json:"restored"`
+	Unchanged  int                    `json:"unchanged"`
+	Failed     int                    `json:"failed"` // Includes offline devices
+	Devices    []DeviceApplyResultDTO `json:"devices"`
+}
+
+// UndoResultDTO summarizes the revert of the last change
+type UndoResultDTO struct {
+	ChangeID  string                 `json:"change_id"`
+	Source    string                 `json:"source" example:"command"` // command, snapshot:<name>
+	ChangedAt string                 `json:"changed_at"`
+	Reverted  int                    `json:"reverted"`
+	Failed    int                    `json:"failed"`
+	Devices   []DeviceApplyResultDTO `json:"devices"`
+}
+
+// StateChangeResponseDTO represents an undoable change of a terminal
+type StateChangeResponseDTO struct {
+	ID        string   `json:"id"`
+	Source    string   `json:"source" example:"command"`
+	DeviceIDs []string `json:"device_ids"`
+	ChangedAt string   `json:"changed_at"`
+}
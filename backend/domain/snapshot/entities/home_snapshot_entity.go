@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DeviceState is the captured state of one device: the writable status codes of a Tuya
+// device, or the last-known params (power, mode, temp, wind) of an IR air conditioner
+type DeviceState struct {
+	DeviceID string                 `json:"device_id"`
+	RemoteID string                 `json:"remote_id,omitempty"` // Set for IR remotes behind an IR hub
+	Name     string                 `json:"name,omitempty"`
+	Values   map[string]interface{} `json:"values"`
+}
+
+// IsIR reports whether the state belongs to an IR remote
+func (s DeviceState) IsIR() bool {
+	return s.RemoteID != ""
+}
+
+// DeviceStates is a slice of DeviceState that implements Scanner and Valuer for GORM
+type DeviceStates []DeviceState
+
+func (d DeviceStates) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+func (d *DeviceStates) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, &d)
+	case string:
+		return json.Unmarshal([]byte(v), &d)
+	default:
+		return fmt.Errorf("type assertion to []byte failed")
+	}
+}
+
+// HomeSnapshot is a named capture of every device state of a terminal
+type HomeSnapshot struct {
+	ID         string       `gorm:"type:char(36);primaryKey" json:"id"`
+	TerminalID string       `gorm:"type:char(36);not null;uniqueIndex:idx_home_snapshot_name" json:"terminal_id"`
+	Name       string       `gorm:"type:varchar(100);not null;uniqueIndex:idx_home_snapshot_name" json:"name"`
+	Devices    DeviceStates `gorm:"type:text;not null" json:"devices"`
+	Skipped    int          `json:"skipped"` // Devices whose state could not be read at capture time
+	CreatedAt  time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the HomeSnapshot model
+func (HomeSnapshot) TableName() string {
+	return "home_snapshots"
+}
+
+// DeviceChange records the values a device had before a command and the values it was sent
+type DeviceChange struct {
+	DeviceID string                 `json:"device_id"`
+	RemoteID string                 `json:"remote_id,omitempty"`
+	Previous map[string]interface{} `json:"previous"`
+	Applied  map[string]interface{} `json:"applied"`
+}
+
+// StateChange is an undoable entry of the change journal of a terminal. A single command
+// produces one device change; restoring a snapshot produces one entry covering every device.
+type StateChange struct {
+	ID         string         `json:"id"`
+	TerminalID string         `json:"terminal_id"`
+	Source     string         `json:"source"` // "command" or "snapshot:<name>"
+	Devices    []DeviceChange `json:"devices"`
+	ChangedAt  time.Time      `json:"changed_at"`
+}
@@ -0,0 +1,69 @@
+package snapshot
+
+import (
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"sensio/domain/snapshot/controllers"
+	"sensio/domain/snapshot/repositories"
+	"sensio/domain/snapshot/usecases"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SnapshotModule struct {
+	Controller      *controllers.SnapshotController
+	SnapshotUseCase *usecases.SnapshotUseCase
+	UndoUseCase     *usecases.UndoUseCase
+	// Executor journals every device command so it can be undone; pass it to other domains
+	// in place of the Tuya executor
+	Executor *usecases.JournalingExecutor
+}
+
+func NewSnapshotModule(
+	db *gorm.DB,
+	badger *infrastructure.BadgerService,
+	deviceRepo device_repositories.IDeviceRepository,
+	tuyaDevices usecases.DeviceReader,
+	lastCommands usecases.LastCommandStore,
+	capabilities usecases.CapabilityLookup,
+	tuyaCmd usecases.DeviceCommandExecutor,
+	tuyaAuth usecases.AccessTokenProvider,
+) *SnapshotModule {
+	cfg := utils.GetConfig()
+	ttl, err := time.ParseDuration(cfg.SnapshotUndoTTL)
+	if err != nil || ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	repo := repositories.NewHomeSnapshotRepository(db)
+	journal := repositories.NewStateChangeJournal(badger, cfg.SnapshotUndoDepth, ttl)
+	reader := usecases.NewDeviceStateReader(tuyaDevices, lastCommands, capabilities)
+	statusRepo := device_status_repositories.NewDeviceStatusRepository(badger)
+
+	snapshotUC := usecases.NewSnapshotUseCase(repo, deviceRepo, reader, tuyaCmd, journal)
+	undoUC := usecases.NewUndoUseCase(journal, tuyaCmd, tuyaAuth)
+
+	return &SnapshotModule{
+		Controller:      controllers.NewSnapshotController(snapshotUC, undoUC),
+		SnapshotUseCase: snapshotUC,
+		UndoUseCase:     undoUC,
+		Executor:        usecases.NewJournalingExecutor(tuyaCmd, reader, statusRepo, deviceRepo, journal),
+	}
+}
+
+func (m *SnapshotModule) RegisterRoutes(protected *gin.RouterGroup) {
+	group := protected.Group("/api/snapshots/:terminal_id")
+	{
+		group.POST("", m.Controller.CreateSnapshot)
+		group.GET("", m.Controller.ListSnapshots)
+		group.GET("/changes", m.Controller.ListChanges)
+		group.POST("/undo", m.Controller.Undo)
+		group.GET("/:snapshot_id", m.Controller.GetSnapshot)
+		group.DELETE("/:snapshot_id", m.Controller.DeleteSnapshot)
+		group.POST("/:snapshot_id/restore", m.Controller.RestoreSnapshot)
+	}
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"sensio/domain/snapshot/entities"
+
+	"gorm.io/gorm"
+)
+
+// IHomeSnapshotRepository defines the interface for home snapshot storage operations
+type IHomeSnapshotRepository interface {
+	Save(snapshot *entities.HomeSnapshot) error
+	GetByID(terminalID, id string) (*entities.HomeSnapshot, error)
+	GetByName(terminalID, name string) (*entities.HomeSnapshot, error)
+	GetByTerminalID(terminalID string) ([]entities.HomeSnapshot, error)
+	Delete(terminalID, id string) error
+}
+
+// HomeSnapshotRepository handles persistent storage of home snapshots using GORM
+type HomeSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewHomeSnapshotRepository creates a new instance of HomeSnapshotRepository
+func NewHomeSnapshotRepository(db *gorm.DB) *HomeSnapshotRepository {
+	return &HomeSnapshotRepository{db: db}
+}
+
+// Save persists a snapshot to the database (Upsert)
+func (r *HomeSnapshotRepository) Save(snapshot *entities.HomeSnapshot) error {
+	return r.db.Save(snapshot).Error
+}
+
+// GetByID retrieves a snapshot by its ID and TerminalID
+func (r *HomeSnapshotRepository) GetByID(terminalID, id string) (*entities.HomeSnapshot, error) {
+	var snapshot entities.HomeSnapshot
+	if err := r.db.Where("id = ? AND terminal_id = ?", id, terminalID).First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetByName retrieves the snapshot a terminal saved under a name
+func (r *HomeSnapshotRepository) GetByName(terminalID, name string) (*entities.HomeSnapshot, error) {
+	var snapshot entities.HomeSnapshot
+	if err := r.db.Where("terminal_id = ? AND name = ?", terminalID, name).First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetByTerminalID retrieves the snapshots of a terminal, most recently updated first
+func (r *HomeSnapshotRepository) GetByTerminalID(terminalID string) ([]entities.HomeSnapshot, error) {
+	var snapshots []entities.HomeSnapshot
+	err := r.db.Where("terminal_id = ?", terminalID).Order("updated_at DESC").Find(&snapshots).Error
+	return snapshots, err
+}
+
+// Delete removes a snapshot of a terminal
+func (r *HomeSnapshotRepository) Delete(terminalID, id string) error {
+	result := r.db.Where("id = ? AND terminal_id = ?", id, terminalID).Delete(&entities.HomeSnapshot{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"encoding/json"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/snapshot/entities"
+	"sync"
+	"time"
+)
+
+const stateChangeJournalKeyPrefix = "snapshot:journal:"
+
+// IStateChangeJournal stores the most recent undoable changes of each terminal
+type IStateChangeJournal interface {
+	Push(change *entities.StateChange) error
+	Latest(terminalID string) (*entities.StateChange, error) // nil when the journal is empty
+	Remove(terminalID, changeID string) error
+	List(terminalID string) ([]entities.StateChange, error) // newest first
+}
+
+// StateChangeJournal keeps a bounded per-terminal change list in Badger. Entries older than the
+// TTL are dropped, so "undo" never reverts a change the user has long forgotten about.
+type StateChangeJournal struct {
+	cache *infrastructure.BadgerService
+	depth int
+	ttl   time.Duration
+	now   func() time.Time
+	mu    sync.Mutex
+}
+
+// NewStateChangeJournal creates a journal keeping at most depth changes per terminal for ttl
+func NewStateChangeJournal(cache *infrastructure.BadgerService, depth int, ttl time.Duration) *StateChangeJournal {
+	if depth <= 0 {
+		depth = 20
+	}
+	return &StateChangeJournal{cache: cache, depth: depth, ttl: ttl, now: time.Now}
+}
+
+// Push appends a change, evicting the oldest entries beyond the journal depth
+func (j *StateChangeJournal) Push(change *entities.StateChange) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	changes, err := j.load(change.TerminalID)
+	if err != nil {
+		return err
+	}
+	changes = append(changes, *change)
+	if len(changes) > j.depth {
+		changes = changes[len(changes)-j.depth:]
+	}
+	return j.store(change.TerminalID, changes)
+}
+
+// Latest returns the most recent change of a terminal
+func (j *StateChangeJournal) Latest(terminalID string) (*entities.StateChange, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	changes, err := j.load(terminalID)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	latest := changes[len(changes)-1]
+	return &latest, nil
+}
+
+// Remove drops a change once it has been undone
+func (j *StateChangeJournal) Remove(terminalID, changeID string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	changes, err := j.load(terminalID)
+	if err != nil {
+		return err
+	}
+	kept := changes[:0]
+	for _, c := range changes {
+		if c.ID != changeID {
+			kept = append(kept, c)
+		}
+	}
+	return j.store(terminalID, kept)
+}
+
+// List returns the undoable changes of a terminal, newest first
+func (j *StateChangeJournal) List(terminalID string) ([]entities.StateChange, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	changes, err := j.load(terminalID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]entities.StateChange, len(changes))
+	for i := range changes {
+		result[len(changes)-1-i] = changes[i]
+	}
+	return result, nil
+}
+
+// load returns the unexpired changes of a terminal, oldest first
+func (j *StateChangeJournal) load(terminalID string) ([]entities.StateChange, error) {
+	data, err := j.cache.Get(stateChangeJournalKeyPrefix + terminalID)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var changes []entities.StateChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, err
+	}
+
+	cutoff := j.now().Add(-j.ttl)
+	fresh := changes[:0]
+	for _, c := range changes {
+		if c.ChangedAt.After(cutoff) {
+			fresh = append(fresh, c)
+		}
+	}
+	return fresh, nil
+}
+
+func (j *StateChangeJournal) store(terminalID string, changes []entities.StateChange) error {
+	key := stateChangeJournalKeyPrefix + terminalID
+	if len(changes) == 0 {
+		return j.cache.Delete(key)
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	return j.cache.SetWithTTL(key, data, j.ttl)
+}
@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/snapshot/entities"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	tuya_entities "sensio/domain/tuya/entities"
+	"sort"
+	"strings"
+)
+
+// irACParams are the params an IR air conditioner remote accepts
+var irACParams = map[string]bool{"power": true, "mode": true, "temp": true, "wind": true}
+
+// DeviceReader reads the live state of a device from Tuya
+type DeviceReader interface {
+	GetDeviceByID(accessToken, deviceID, remoteID string) (*tuya_dtos.TuyaDeviceDTO, error)
+}
+
+// LastCommandStore returns the params last sent to a device; IR remotes cannot report their state
+type LastCommandStore interface {
+	GetDeviceState(deviceID string) (*tuya_dtos.DeviceStateDTO, error)
+}
+
+// CapabilityLookup returns the writable functions of a device, nil when it has no specification
+type CapabilityLookup interface {
+	GetCapabilities(accessToken, deviceID string) (*tuya_entities.DeviceCapabilities, error)
+}
+
+// DeviceCommandExecutor sends commands to Tuya devices and IR remotes
+type DeviceCommandExecutor interface {
+	SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error)
+	SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error)
+}
+
+// AccessTokenProvider supplies a Tuya access token for calls made without a request token
+type AccessTokenProvider interface {
+	GetTuyaAccessToken() (string, error)
+}
+
+// DeviceStateReader captures the current state of a device in a form that can be replayed:
+// only writable codes are kept, so restoring never sends read-only statuses like battery levels.
+type DeviceStateReader struct {
+	devices      DeviceReader
+	lastCommands LastCommandStore
+	capabilities CapabilityLookup
+}
+
+// NewDeviceStateReader creates a new instance of DeviceStateReader
+func NewDeviceStateReader(devices DeviceReader, lastCommands LastCommandStore, capabilities CapabilityLookup) *DeviceStateReader {
+	return &DeviceStateReader{
+		devices:      devices,
+		lastCommands: lastCommands,
+		capabilities: capabilities,
+	}
+}
+
+// Read returns the state of a device and whether it is online. IR remotes use the params last
+// sent to them and fall back to the status Tuya infers for the remote.
+func (r *DeviceStateReader) Read(accessToken, deviceID, remoteID string) (*entities.DeviceState, bool, error) {
+	state := &entities.DeviceState{DeviceID: deviceID, RemoteID: remoteID, Values: map[string]interface{}{}}
+
+	if remoteID != "" && r.lastCommands != nil {
+		if saved, err := r.lastCommands.GetDeviceState(remoteID); err == nil && saved != nil {
+			for _, cmd := range saved.LastCommands {
+				if irACParams[cmd.Code] {
+					state.Values[cmd.Code] = cmd.Value
+				}
+			}
+		}
+		if len(state.Values) > 0 {
+			return state, true, nil
+		}
+	}
+
+	device, err := r.devices.GetDeviceByID(accessToken, deviceID, remoteID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read device %s: %w", deviceID, err)
+	}
+	state.Name = device.Name
+
+	if remoteID != "" {
+		for _, status := range device.Status {
+			if irACParams[status.Code] {
+				state.Values[status.Code] = status.Value
+			}
+		}
+		return state, true, nil
+	}
+
+	var caps *tuya_entities.DeviceCapabilities
+	if r.capabilities != nil {
+		if caps, err = r.capabilities.GetCapabilities(accessToken, deviceID); err != nil {
+			utils.LogWarn("DeviceStateReader: no specification for %s, keeping every status: %v", deviceID, err)
+		}
+	}
+	for _, status := range device.Status {
+		code := status.Code
+		if caps != nil {
+			fn, ok := caps.Lookup(code)
+			if !ok {
+				continue
+			}
+			code = fn.Code
+		}
+		state.Values[code] = status.Value
+	}
+	return state, device.Online, nil
+}
+
+// diffValues returns the target values that differ from the current ones
+func diffValues(current, target map[string]interface{}) map[string]interface{} {
+	diff := map[string]interface{}{}
+	for code, want := range target {
+		if have, ok := lookupValue(current, code); ok && sameValue(have, want) {
+			continue
+		}
+		diff[code] = want
+	}
+	return diff
+}
+
+// lookupValue finds a code, accepting the switch_1/switch1 spellings of multi-gang switches
+func lookupValue(values map[string]interface{}, code string) (interface{}, bool) {
+	if v, ok := values[code]; ok {
+		return v, true
+	}
+	if strings.HasPrefix(code, "switch_") {
+		v, ok := values[strings.Replace(code, "_", "", 1)]
+		return v, ok
+	}
+	if strings.HasPrefix(code, "switch") && len(code) > len("switch") {
+		v, ok := values["switch_"+strings.TrimPrefix(code, "switch")]
+		return v, ok
+	}
+	return nil, false
+}
+
+// sameValue compares values loosely: JSON round trips turn ints into floats and IR params
+// come back from Tuya as strings
+func sameValue(a, b interface{}) bool {
+	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+// applyValues sends values to a device through the executor
+func applyValues(executor DeviceCommandExecutor, accessToken string, state entities.DeviceState, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	codes := make([]string, 0, len(values))
+	for code := range values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var success bool
+	var err error
+	if state.IsIR() {
+		params := make(map[string]int, len(values))
+		for _, code := range codes {
+			v, ok := utils.ToInt(values[code])
+			if !ok {
+				return fmt.Errorf("invalid value for IR param %s: %v", code, values[code])
+			}
+			params[code] = v
+		}
+		success, err = executor.SendIRACCommand(accessToken, state.DeviceID, state.RemoteID, params)
+	} else {
+		commands := make([]tuya_dtos.TuyaCommandDTO, 0, len(values))
+		for _, code := range codes {
+			commands = append(commands, tuya_dtos.TuyaCommandDTO{Code: code, Value: values[code]})
+		}
+		success, err = executor.SendSwitchCommand(accessToken, state.DeviceID, commands)
+	}
+	if err != nil {
+		return err
+	}
+	if !success {
+		return fmt.Errorf("unsuccessful response from Tuya")
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"encoding/json"
+	"sensio/domain/common/utils"
+	doorlock_entities "sensio/domain/doorlock/entities"
+	"sensio/domain/snapshot/entities"
+	"sensio/domain/snapshot/repositories"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JournalingExecutor wraps the Tuya executor so every successful command of a terminal device
+// (REST, MQTT RPC, chat, scenes, automations) is recorded in the change journal with the values
+// it replaced. Commands to door locks and to devices that belong to no terminal are sent without
+// journaling, so an undo can never unlock a door.
+type JournalingExecutor struct {
+	inner    DeviceCommandExecutor
+	reader   *DeviceStateReader
+	statuses StatusCache
+	devRepo  device_repositories.IDeviceRepository
+	journal  repositories.IStateChangeJournal
+	now      func() time.Time
+}
+
+// StatusCache returns the statuses last reported for a device by Tuya pushes and terminals
+type StatusCache interface {
+	GetByDeviceID(deviceID string) ([]device_status_entities.DeviceStatus, error)
+}
+
+// NewJournalingExecutor creates a new instance of JournalingExecutor. statuses may be nil, in
+// which case the replaced values are always read from Tuya.
+func NewJournalingExecutor(inner DeviceCommandExecutor, reader *DeviceStateReader, statuses StatusCache, devRepo device_repositories.IDeviceRepository, journal repositories.IStateChangeJournal) *JournalingExecutor {
+	return &JournalingExecutor{
+		inner:    inner,
+		reader:   reader,
+		statuses: statuses,
+		devRepo:  devRepo,
+		journal:  journal,
+		now:      time.Now,
+	}
+}
+
+// SendSwitchCommand sends the commands and journals the values they replaced
+func (e *JournalingExecutor) SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error) {
+	applied := make(map[string]interface{}, len(commands))
+	for _, cmd := range commands {
+		applied[cmd.Code] = cmd.Value
+	}
+	record := e.prepare(accessToken, deviceID, "", applied)
+
+	success, err := e.inner.SendSwitchCommand(accessToken, deviceID, commands)
+	if err == nil && success {
+		e.commit(record)
+	}
+	return success, err
+}
+
+// SendIRACCommand sends the IR params and journals the params they replaced
+func (e *JournalingExecutor) SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error) {
+	applied := make(map[string]interface{}, len(params))
+	for code, v := range params {
+		applied[code] = v
+	}
+	record := e.prepare(accessToken, infraredID, remoteID, applied)
+
+	success, err := e.inner.SendIRACCommand(accessToken, infraredID, remoteID, params)
+	if err == nil && success {
+		e.commit(record)
+	}
+	return success, err
+}
+
+// prepare reads the values about to be replaced. It returns nil when the change cannot be
+// undone: unknown terminal, unreadable device, or a command that changes nothing.
+func (e *JournalingExecutor) prepare(accessToken, deviceID, remoteID string, applied map[string]interface{}) *entities.StateChange {
+	terminalID := e.terminalOf(deviceID, remoteID)
+	if terminalID == "" {
+		return nil
+	}
+
+	// The cached status keeps commands off a Tuya round trip; only devices without one are read live
+	current := e.cachedValues(deviceID, remoteID, applied)
+	if current == nil {
+		state, _, err := e.reader.Read(accessToken, deviceID, remoteID)
+		if err != nil {
+			utils.LogWarn("JournalingExecutor: change on %s will not be undoable: %v", deviceID, err)
+			return nil
+		}
+		current = state.Values
+	}
+
+	previous := map[string]interface{}{}
+	changed := false
+	for code, value := range applied {
+		prev, ok := lookupValue(current, code)
+		if !ok {
+			continue
+		}
+		previous[code] = prev
+		if !sameValue(prev, value) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	return &entities.StateChange{
+		ID:         uuid.New().String(),
+		TerminalID: terminalID,
+		Source:     "command",
+		Devices: []entities.DeviceChange{{
+			DeviceID: deviceID,
+			RemoteID: remoteID,
+			Previous: previous,
+			Applied:  applied,
+		}},
+	}
+}
+
+// cachedValues returns the cached status of a device when it holds every applied code, nil otherwise.
+// IR remotes report no status; the reader already takes their params from the last command sent.
+func (e *JournalingExecutor) cachedValues(deviceID, remoteID string, applied map[string]interface{}) map[string]interface{} {
+	if e.statuses == nil || remoteID != "" {
+		return nil
+	}
+	statuses, err := e.statuses.GetByDeviceID(deviceID)
+	if err != nil || len(statuses) == 0 {
+		return nil
+	}
+
+	values := make(map[string]interface{}, len(statuses))
+	for _, status := range statuses {
+		// Statuses are stored as text; decode them so an undo sends true rather than "true"
+		var value interface{}
+		if err := json.Unmarshal([]byte(status.Value), &value); err != nil {
+			value = status.Value
+		}
+		values[status.Code] = value
+	}
+	for code := range applied {
+		if _, ok := lookupValue(values, code); !ok {
+			return nil
+		}
+	}
+	return values
+}
+
+func (e *JournalingExecutor) commit(change *entities.StateChange) {
+	if change == nil {
+		return
+	}
+	change.ChangedAt = e.now()
+	if err := e.journal.Push(change); err != nil {
+		utils.LogWarn("JournalingExecutor: failed to journal change on %s: %v", change.Devices[0].DeviceID, err)
+	}
+}
+
+// terminalOf returns the terminal of an undoable device, "" for locks and unknown devices
+func (e *JournalingExecutor) terminalOf(deviceID, remoteID string) string {
+	var device *device_entities.Device
+	var err error
+	if remoteID != "" {
+		device, err = e.devRepo.GetByRemoteID(remoteID)
+	} else {
+		device, err = e.devRepo.GetByID(deviceID)
+	}
+	if err != nil || device == nil || doorlock_entities.IsLockCategory(device.Category) {
+		return ""
+	}
+	return device.TerminalID
+}
@@ -0,0 +1,237 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/utils"
+	doorlock_entities "sensio/domain/doorlock/entities"
+	"sensio/domain/snapshot/dtos"
+	"sensio/domain/snapshot/entities"
+	"sensio/domain/snapshot/repositories"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrSnapshotNotFound is returned when a snapshot does not exist or belongs to another terminal
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+const maxSnapshotNameLength = 100
+
+// SnapshotUseCase captures the state of every device of a terminal under a name and restores it
+// by sending only the codes that differ from the live state
+type SnapshotUseCase struct {
+	repo     repositories.IHomeSnapshotRepository
+	devRepo  device_repositories.IDeviceRepository
+	reader   *DeviceStateReader
+	executor DeviceCommandExecutor // Unjournaled: a restore is journaled as one change
+	journal  repositories.IStateChangeJournal
+	now      func() time.Time
+}
+
+// NewSnapshotUseCase creates a new instance of SnapshotUseCase
+func NewSnapshotUseCase(repo repositories.IHomeSnapshotRepository, devRepo device_repositories.IDeviceRepository, reader *DeviceStateReader, executor DeviceCommandExecutor, journal repositories.IStateChangeJournal) *SnapshotUseCase {
+	return &SnapshotUseCase{
+		repo:     repo,
+		devRepo:  devRepo,
+		reader:   reader,
+		executor: executor,
+		journal:  journal,
+		now:      time.Now,
+	}
+}
+
+// CaptureSnapshot reads every device of the terminal and saves the result under name,
+// overwriting an existing snapshot with the same name
+func (uc *SnapshotUseCase) CaptureSnapshot(terminalID, name, accessToken string) (*dtos.SnapshotResponseDTO, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxSnapshotNameLength {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "name", Message: "name is required and must be at most 100 characters"},
+		})
+	}
+
+	devices, err := uc.devRepo.GetByTerminalID(terminalID)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "terminal_id", Message: "terminal has no devices to capture"},
+		})
+	}
+
+	snapshot, err := uc.repo.GetByName(terminalID, name)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		snapshot = &entities.HomeSnapshot{ID: uuid.New().String(), TerminalID: terminalID, Name: name}
+	}
+	snapshot.Devices = entities.DeviceStates{}
+	snapshot.Skipped = 0
+
+	for _, device := range devices {
+		if doorlock_entities.IsLockCategory(device.Category) {
+			continue // Restoring a snapshot must never unlock a door
+		}
+		state, _, err := uc.reader.Read(accessToken, device.ID, device.RemoteID)
+		if err != nil || len(state.Values) == 0 {
+			utils.LogWarn("SnapshotUseCase: skipping %s in snapshot '%s': %v", device.ID, name, err)
+			snapshot.Skipped++
+			continue
+		}
+		state.Name = device.Name
+		snapshot.Devices = append(snapshot.Devices, *state)
+	}
+
+	if err := uc.repo.Save(snapshot); err != nil {
+		return nil, err
+	}
+	return toSnapshotDTO(snapshot), nil
+}
+
+// ListSnapshots returns the snapshots of a terminal
+func (uc *SnapshotUseCase) ListSnapshots(terminalID string) ([]dtos.SnapshotResponseDTO, error) {
+	snapshots, err := uc.repo.GetByTerminalID(terminalID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.SnapshotResponseDTO, 0, len(snapshots))
+	for i := range snapshots {
+		result = append(result, *toSnapshotDTO(&snapshots[i]))
+	}
+	return result, nil
+}
+
+// GetSnapshot returns a snapshot of a terminal
+func (uc *SnapshotUseCase) GetSnapshot(terminalID, snapshotID string) (*dtos.SnapshotResponseDTO, error) {
+	snapshot, err := uc.find(terminalID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	return toSnapshotDTO(snapshot), nil
+}
+
+// DeleteSnapshot removes a snapshot of a terminal
+func (uc *SnapshotUseCase) DeleteSnapshot(terminalID, snapshotID string) error {
+	if err := uc.repo.Delete(terminalID, snapshotID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSnapshotNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// RestoreSnapshot brings every captured device back to its snapshot state. Each device is sent
+// only the codes that differ from its live state; the whole restore is journaled as one change,
+// so a single undo reverts it.
+func (uc *SnapshotUseCase) RestoreSnapshot(terminalID, snapshotID, accessToken string) (*dtos.RestoreResultDTO, error) {
+	snapshot, err := uc.find(terminalID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dtos.RestoreResultDTO{SnapshotID: snapshot.ID, Name: snapshot.Name, Devices: []dtos.DeviceApplyResultDTO{}}
+	change := &entities.StateChange{
+		ID:         uuid.New().String(),
+		TerminalID: terminalID,
+		Source:     "snapshot:" + snapshot.Name,
+	}
+
+	for _, target := range snapshot.Devices {
+		item := dtos.DeviceApplyResultDTO{DeviceID: target.DeviceID, RemoteID: target.RemoteID}
+
+		current, online, err := uc.reader.Read(accessToken, target.DeviceID, target.RemoteID)
+		switch {
+		case err != nil:
+			item.Status = "failed"
+			item.Error = err.Error()
+		case !online:
+			item.Status = "offline"
+		default:
+			diff := diffValues(current.Values, target.Values)
+			if len(diff) == 0 {
+				item.Status = "unchanged"
+				break
+			}
+			item.Sent = diff
+			if err := applyValues(uc.executor, accessToken, target, diff); err != nil {
+				item.Status = "failed"
+				item.Error = err.Error()
+				break
+			}
+			item.Status = "restored"
+			change.Devices = append(change.Devices, entities.DeviceChange{
+				DeviceID: target.DeviceID,
+				RemoteID: target.RemoteID,
+				Previous: previousValues(current.Values, diff),
+				Applied:  diff,
+			})
+		}
+
+		switch item.Status {
+		case "restored":
+			result.Restored++
+		case "unchanged":
+			result.Unchanged++
+		default:
+			result.Failed++
+		}
+		result.Devices = append(result.Devices, item)
+	}
+
+	if len(change.Devices) > 0 {
+		change.ChangedAt = uc.now()
+		if err := uc.journal.Push(change); err != nil {
+			utils.LogWarn("SnapshotUseCase: restore of '%s' will not be undoable: %v", snapshot.Name, err)
+		}
+	}
+	return result, nil
+}
+
+func (uc *SnapshotUseCase) find(terminalID, snapshotID string) (*entities.HomeSnapshot, error) {
+	snapshot, err := uc.repo.GetByID(terminalID, snapshotID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// previousValues returns the current values of the codes about to be changed
+func previousValues(current, changed map[string]interface{}) map[string]interface{} {
+	previous := map[string]interface{}{}
+	for code := range changed {
+		if v, ok := lookupValue(current, code); ok {
+			previous[code] = v
+		}
+	}
+	return previous
+}
+
+func toSnapshotDTO(snapshot *entities.HomeSnapshot) *dtos.SnapshotResponseDTO {
+	devices := make([]dtos.SnapshotDeviceDTO, 0, len(snapshot.Devices))
+	for _, d := range snapshot.Devices {
+		devices = append(devices, dtos.SnapshotDeviceDTO{
+			DeviceID: d.DeviceID,
+			RemoteID: d.RemoteID,
+			Name:     d.Name,
+			Values:   d.Values,
+		})
+	}
+	return &dtos.SnapshotResponseDTO{
+		ID:         snapshot.ID,
+		TerminalID: snapshot.TerminalID,
+		Name:       snapshot.Name,
+		Devices:    devices,
+		Skipped:    snapshot.Skipped,
+		CreatedAt:  snapshot.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  snapshot.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"sensio/domain/snapshot/entities"
+	"sensio/domain/snapshot/repositories"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	tuya_dtos "sensio/domain/tuya/dtos"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeHome is a Tuya account whose device statuses change when commands are sent
+type fakeHome struct {
+	status  map[string]map[string]interface{}
+	offline map[string]bool
+	sent    []map[string]interface{}
+	reads   int
+}
+
+func (h *fakeHome) GetDeviceByID(accessToken, deviceID, remoteID string) (*tuya_dtos.TuyaDeviceDTO, error) {
+	h.reads++
+	values, ok := h.status[deviceID]
+	if !ok {
+		return nil, errors.New("device not found")
+	}
+	device := &tuya_dtos.TuyaDeviceDTO{ID: deviceID, Online: !h.offline[deviceID]}
+	for code, v := range values {
+		device.Status = append(device.Status, tuya_dtos.TuyaDeviceStatusDTO{Code: code, Value: v})
+	}
+	return device, nil
+}
+
+func (h *fakeHome) SendSwitchCommand(accessToken, deviceID string, commands []tuya_dtos.TuyaCommandDTO) (bool, error) {
+	sent := map[string]interface{}{"device": deviceID}
+	for _, cmd := range commands {
+		h.status[deviceID][cmd.Code] = cmd.Value
+		sent[cmd.Code] = cmd.Value
+	}
+	h.sent = append(h.sent, sent)
+	return true, nil
+}
+
+func (h *fakeHome) SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error) {
+	return false, errors.New("no IR remotes in this home")
+}
+
+// fakeSnapshotRepo keeps snapshots in memory
+type fakeSnapshotRepo struct {
+	snapshots map[string]entities.HomeSnapshot
+}
+
+func (r *fakeSnapshotRepo) Save(snapshot *entities.HomeSnapshot) error {
+	r.snapshots[snapshot.ID] = *snapshot
+	return nil
+}
+
+func (r *fakeSnapshotRepo) GetByID(terminalID, id string) (*entities.HomeSnapshot, error) {
+	s, ok := r.snapshots[id]
+	if !ok || s.TerminalID != terminalID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &s, nil
+}
+
+func (r *fakeSnapshotRepo) GetByName(terminalID, name string) (*entities.HomeSnapshot, error) {
+	for _, s := range r.snapshots {
+		if s.TerminalID == terminalID && s.Name == name {
+			return &s, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSnapshotRepo) GetByTerminalID(terminalID string) ([]entities.HomeSnapshot, error) {
+	return nil, nil
+}
+
+func (r *fakeSnapshotRepo) Delete(terminalID, id string) error {
+	delete(r.snapshots, id)
+	return nil
+}
+
+// fakeDeviceRepo only implements the lookups used by the snapshot use cases
+type fakeDeviceRepo struct {
+	device_repositories.IDeviceRepository
+	devices []device_entities.Device
+}
+
+func (r *fakeDeviceRepo) GetByTerminalID(terminalID string) ([]device_entities.Device, error) {
+	var out []device_entities.Device
+	for _, d := range r.devices {
+		if d.TerminalID == terminalID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeDeviceRepo) GetByID(id string) (*device_entities.Device, error) {
+	for _, d := range r.devices {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, errors.New("device not found")
+}
+
+// fakeStatusCache holds device statuses as the status repository stores them, JSON-encoded text
+type fakeStatusCache map[string][]device_status_entities.DeviceStatus
+
+func (c fakeStatusCache) GetByDeviceID(deviceID string) ([]device_status_entities.DeviceStatus, error) {
+	return c[deviceID], nil
+}
+
+type snapshotFixture struct {
+	home     *fakeHome
+	statuses fakeStatusCache
+	executor *JournalingExecutor
+	snapshot *SnapshotUseCase
+	undo     *UndoUseCase
+}
+
+func newSnapshotFixture(t *testing.T) *snapshotFixture {
+	t.Helper()
+	_ = utils.GetConfig()
+	badger, err := infrastructure.NewBadgerService(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = badger.Close() })
+
+	home := &fakeHome{
+		status: map[string]map[string]interface{}{
+			"lamp":  {"switch_led": true, "bright_value_v2": 800},
+			"plug":  {"switch_1": false},
+			"lock1": {"lock_motor_state": true},
+		},
+		offline: map[string]bool{},
+	}
+	devRepo := &fakeDeviceRepo{devices: []device_entities.Device{
+		{ID: "lamp", TerminalID: "term-1", Name: "Lamp"},
+		{ID: "plug", TerminalID: "term-1", Name: "Plug"},
+		{ID: "lock1", TerminalID: "term-1", Name: "Front door", Category: "jtmspro"},
+	}}
+	journal := repositories.NewStateChangeJournal(badger, 5, time.Hour)
+	reader := NewDeviceStateReader(home, nil, nil)
+	statuses := fakeStatusCache{}
+
+	return &snapshotFixture{
+		home:     home,
+		statuses: statuses,
+		executor: NewJournalingExecutor(home, reader, statuses, devRepo, journal),
+		snapshot: NewSnapshotUseCase(&fakeSnapshotRepo{snapshots: map[string]entities.HomeSnapshot{}}, devRepo, reader, home, journal),
+		undo:     NewUndoUseCase(journal, home, nil),
+	}
+}
+
+func TestRestoreSnapshot_SendsOnlyDifferingCodes(t *testing.T) {
+	f := newSnapshotFixture(t)
+
+	captured, err := f.snapshot.CaptureSnapshot("term-1", " Movie night ", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "Movie night", captured.Name)
+	require.Len(t, captured.Devices, 2, "locks are never captured")
+
+	_, err = f.executor.SendSwitchCommand("token", "lamp", []tuya_dtos.TuyaCommandDTO{{Code: "bright_value_v2", Value: 100}})
+	require.NoError(t, err)
+	f.home.offline["plug"] = true
+	f.home.sent = nil
+
+	result, err := f.snapshot.RestoreSnapshot("term-1", captured.ID, "token")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Restored)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, "offline", result.Devices[1].Status)
+	assert.Equal(t, []map[string]interface{}{{"device": "lamp", "bright_value_v2": 800}}, f.home.sent)
+
+	// Restoring again finds nothing to send
+	f.home.offline["plug"] = false
+	f.home.sent = nil
+	result, err = f.snapshot.RestoreSnapshot("term-1", captured.ID, "token")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Unchanged)
+	assert.Empty(t, f.home.sent)
+
+	_, err = f.snapshot.RestoreSnapshot("term-2", captured.ID, "token")
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+}
+
+func TestUndoLastChange_RevertsCommandsNewestFirst(t *testing.T) {
+	f := newSnapshotFixture(t)
+
+	_, err := f.executor.SendSwitchCommand("token", "plug", []tuya_dtos.TuyaCommandDTO{{Code: "switch_1", Value: true}})
+	require.NoError(t, err)
+	_, err = f.executor.SendSwitchCommand("token", "lamp", []tuya_dtos.TuyaCommandDTO{{Code: "switch_led", Value: false}})
+	require.NoError(t, err)
+	// A no-op command and a lock command leave nothing to undo
+	_, err = f.executor.SendSwitchCommand("token", "lamp", []tuya_dtos.TuyaCommandDTO{{Code: "switch_led", Value: false}})
+	require.NoError(t, err)
+	_, err = f.executor.SendSwitchCommand("token", "lock1", []tuya_dtos.TuyaCommandDTO{{Code: "lock_motor_state", Value: false}})
+	require.NoError(t, err)
+
+	changes, err := f.undo.ListChanges("term-1")
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, []string{"lamp"}, changes[0].DeviceIDs)
+
+	result, err := f.undo.UndoLastChange("term-1", "token")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Reverted)
+	assert.Equal(t, true, f.home.status["lamp"]["switch_led"])
+	assert.Equal(t, true, f.home.status["plug"]["switch_1"])
+
+	_, err = f.undo.UndoLastChange("term-1", "token")
+	require.NoError(t, err)
+	assert.Equal(t, false, f.home.status["plug"]["switch_1"])
+
+	_, err = f.undo.UndoLastChange("term-1", "token")
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+	assert.Equal(t, false, f.home.status["lock1"]["lock_motor_state"], "lock commands are never reverted")
+}
+
+func TestJournalingExecutor_JournalsFromCachedStatus(t *testing.T) {
+	f := newSnapshotFixture(t)
+	f.statuses["lamp"] = []device_status_entities.DeviceStatus{
+		{DeviceID: "lamp", Code: "switch_led", Value: "true"},
+		{DeviceID: "lamp", Code: "bright_value_v2", Value: "800"},
+	}
+
+	_, err := f.executor.SendSwitchCommand("token", "lamp", []tuya_dtos.TuyaCommandDTO{{Code: "switch_led", Value: false}})
+	require.NoError(t, err)
+	assert.Zero(t, f.home.reads, "a cached device is not read from Tuya")
+
+	// A code missing from the cache falls back to a live read
+	_, err = f.executor.SendSwitchCommand("token", "plug", []tuya_dtos.TuyaCommandDTO{{Code: "switch_1", Value: true}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.home.reads)
+
+	_, err = f.undo.UndoLastChange("term-1", "token")
+	require.NoError(t, err)
+	_, err = f.undo.UndoLastChange("term-1", "token")
+	require.NoError(t, err)
+	assert.Equal(t, true, f.home.status["lamp"]["switch_led"], "cached values are replayed with their type")
+	assert.Equal(t, false, f.home.status["plug"]["switch_1"])
+}
@@ -0,0 +1,98 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sensio/domain/common/utils"
+	"sensio/domain/snapshot/dtos"
+	"sensio/domain/snapshot/entities"
+	"sensio/domain/snapshot/repositories"
+	"time"
+)
+
+// ErrNothingToUndo is returned when a terminal has no undoable change left in the journal
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// UndoUseCase reverts the most recent journaled change of a terminal
+type UndoUseCase struct {
+	journal  repositories.IStateChangeJournal
+	executor DeviceCommandExecutor // Unjournaled: reverting must not create a new undo entry
+	auth     AccessTokenProvider
+}
+
+// NewUndoUseCase creates a new instance of UndoUseCase
+func NewUndoUseCase(journal repositories.IStateChangeJournal, executor DeviceCommandExecutor, auth AccessTokenProvider) *UndoUseCase {
+	return &UndoUseCase{
+		journal:  journal,
+		executor: executor,
+		auth:     auth,
+	}
+}
+
+// UndoLastChange sends every device of the latest change back to its previous values. The entry
+// is removed unless every device failed, so a transient Tuya error can be retried.
+func (uc *UndoUseCase) UndoLastChange(terminalID, accessToken string) (*dtos.UndoResultDTO, error) {
+	change, err := uc.journal.Latest(terminalID)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, ErrNothingToUndo
+	}
+
+	if accessToken == "" {
+		if accessToken, err = uc.auth.GetTuyaAccessToken(); err != nil {
+			return nil, fmt.Errorf("failed to get access token: %w", err)
+		}
+	}
+
+	result := &dtos.UndoResultDTO{
+		ChangeID:  change.ID,
+		Source:    change.Source,
+		ChangedAt: change.ChangedAt.Format(time.RFC3339),
+		Devices:   []dtos.DeviceApplyResultDTO{},
+	}
+	for _, device := range change.Devices {
+		item := dtos.DeviceApplyResultDTO{DeviceID: device.DeviceID, RemoteID: device.RemoteID, Sent: device.Previous}
+		state := entities.DeviceState{DeviceID: device.DeviceID, RemoteID: device.RemoteID}
+		if err := applyValues(uc.executor, accessToken, state, device.Previous); err != nil {
+			utils.LogWarn("UndoUseCase: failed to revert %s: %v", device.DeviceID, err)
+			item.Status = "failed"
+			item.Error = err.Error()
+			result.Failed++
+		} else {
+			item.Status = "reverted"
+			result.Reverted++
+		}
+		result.Devices = append(result.Devices, item)
+	}
+
+	if result.Reverted > 0 || len(change.Devices) == 0 {
+		if err := uc.journal.Remove(terminalID, change.ID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ListChanges returns the undoable changes of a terminal, newest first
+func (uc *UndoUseCase) ListChanges(terminalID string) ([]dtos.StateChangeResponseDTO, error) {
+	changes, err := uc.journal.List(terminalID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.StateChangeResponseDTO, 0, len(changes))
+	for _, c := range changes {
+		ids := make([]string, 0, len(c.Devices))
+		for _, d := range c.Devices {
+			ids = append(ids, d.DeviceID)
+		}
+		result = append(result, dtos.StateChangeResponseDTO{
+			ID:        c.ID,
+			Source:    c.Source,
+			DeviceIDs: ids,
+			ChangedAt: c.ChangedAt.Format(time.RFC3339),
+		})
+	}
+	return result, nil
+}
@@ -33,7 +33,7 @@ func NewUpdateDeviceController(useCase *usecases.UpdateDeviceUseCase) *UpdateDev
 
 // UpdateDevice handles PUT /api/devices/:id endpoint
 // @Summary      Update a device
-// @Description  Update an existing device's details by ID
+// @Description  Update an existing device's details by ID, including its room and zone assignment. A device without a room belongs to the room of its terminal.
 // @Tags         02. Terminal
 // @Accept       json
 // @Produce      json
@@ -16,7 +16,9 @@ type CreateDeviceResponseDTO struct {
 
 // UpdateDeviceRequestDTO represents the request body for updating a device
 type UpdateDeviceRequestDTO struct {
-	Name *string `json:"name,omitempty" example:"Updated Device Name"`
+	Name   *string `json:"name,omitempty" example:"Updated Device Name"`
+	RoomID *string `json:"room_id,omitempty" example:"meeting-room-3"` // Empty string unassigns the device and its zone
+	ZoneID *string `json:"zone_id,omitempty"`                          // Empty string unassigns the zone; the room follows the zone
 }
 
 // DeviceFilterDTO represents filter options for listing devices
@@ -32,6 +34,8 @@ type DeviceResponseDTO struct {
 	ID                string    `json:"id"`
 	TerminalID        string    `json:"terminal_id"`
 	Name              string    `json:"name"`
+	RoomID            string    `json:"room_id"`
+	ZoneID            string    `json:"zone_id"`
 	RemoteID          string    `json:"remote_id"`
 	Category          string    `json:"category"`
 	RemoteCategory    string    `json:"remote_category"`
@@ -13,6 +13,8 @@ type Device struct {
 	ID         string                                `gorm:"type:char(36);primaryKey" json:"id"`
 	TerminalID string                                `gorm:"type:char(36);not null;index" json:"terminal_id"`
 	Name       string                                `gorm:"type:varchar(255);not null" json:"name"`
+	RoomID     string                                `gorm:"type:varchar(255);index" json:"room_id"` // Empty: the room of the terminal
+	ZoneID     string                                `gorm:"type:char(36);index" json:"zone_id"`
 	Status     []device_status_entities.DeviceStatus `json:"status" gorm:"foreignKey:DeviceID"`
 	CreatedAt  time.Time                             `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt  time.Time                             `gorm:"autoUpdateTime" json:"updated_at"`
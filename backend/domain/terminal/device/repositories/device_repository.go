@@ -23,6 +23,10 @@ type IDeviceRepository interface {
 	Update(device *entities.Device) error
 	Delete(id string) error
 	GetByRemoteID(remoteID string) (*entities.Device, error)
+	GetByRoom(roomID string, terminalIDs []string) ([]entities.Device, error)
+	GetByZoneID(zoneID string) ([]entities.Device, error)
+	ClearRoomAssignment(roomID string) error
+	ClearZoneAssignment(zoneID string) error
 }
 
 // DeviceRepository handles database operations for Device entities
@@ -228,3 +232,62 @@ func (r *DeviceRepository) GetByRemoteID(remoteID string) (*entities.Device, err
 	}
 	return &device, nil
 }
+
+// GetByRoom retrieves the devices assigned to a room plus the unassigned devices of the given
+// terminals, which belong to the room of their terminal
+func (r *DeviceRepository) GetByRoom(roomID string, terminalIDs []string) ([]entities.Device, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var devices []entities.Device
+	query := r.db.Where("room_id = ?", roomID)
+	if len(terminalIDs) > 0 {
+		query = query.Or("(room_id = '' OR room_id IS NULL) AND terminal_id IN ?", terminalIDs)
+	}
+	err := query.Find(&devices).Error
+	return devices, err
+}
+
+// GetByZoneID retrieves the devices assigned to a zone
+func (r *DeviceRepository) GetByZoneID(zoneID string) ([]entities.Device, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var devices []entities.Device
+	err := r.db.Where("zone_id = ?", zoneID).Find(&devices).Error
+	return devices, err
+}
+
+// ClearRoomAssignment unassigns every device of a room and its zones and invalidates their cache
+func (r *DeviceRepository) ClearRoomAssignment(roomID string) error {
+	return r.clearAssignment("room_id = ?", roomID, map[string]interface{}{"room_id": "", "zone_id": ""})
+}
+
+// ClearZoneAssignment unassigns every device of a zone, keeping their room, and invalidates their cache
+func (r *DeviceRepository) ClearZoneAssignment(zoneID string) error {
+	return r.clearAssignment("zone_id = ?", zoneID, map[string]interface{}{"zone_id": ""})
+}
+
+func (r *DeviceRepository) clearAssignment(condition, value string, updates map[string]interface{}) error {
+	if r.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	var ids []string
+	if err := r.db.Model(&entities.Device{}).Where(condition, value).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.Model(&entities.Device{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// Invalidate cache
+	for _, id := range ids {
+		if err := r.cache.Delete(fmt.Sprintf("device:%s", id)); err != nil {
+			utils.LogWarn("DeviceRepository: Failed to invalidate cache for device ID %s: %v", id, err)
+		}
+	}
+	return nil
+}
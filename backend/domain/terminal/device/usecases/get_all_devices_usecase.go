@@ -62,6 +62,8 @@ func (uc *GetAllDevicesUseCase) ListDevices(filter *dtos.DeviceFilterDTO) (*dtos
 			ID:                item.ID,
 			TerminalID:        item.TerminalID,
 			Name:              item.Name,
+			RoomID:            item.RoomID,
+			ZoneID:            item.ZoneID,
 			RemoteID:          item.RemoteID,
 			Category:          item.Category,
 			RemoteCategory:    item.RemoteCategory,
@@ -30,6 +30,8 @@ func (uc *GetDeviceByIDUseCase) GetDeviceByID(id string) (*dtos.DeviceSingleResp
 			ID:                device.ID,
 			TerminalID:        device.TerminalID,
 			Name:              device.Name,
+			RoomID:            device.RoomID,
+			ZoneID:            device.ZoneID,
 			RemoteID:          device.RemoteID,
 			Category:          device.Category,
 			RemoteCategory:    device.RemoteCategory,
@@ -51,6 +51,8 @@ func (uc *GetDevicesByTerminalIDUseCase) ListDevicesByTerminalID(terminalID stri
 			ID:                item.ID,
 			TerminalID:        item.TerminalID,
 			Name:              item.Name,
+			RoomID:            item.RoomID,
+			ZoneID:            item.ZoneID,
 			RemoteID:          item.RemoteID,
 			Category:          item.Category,
 			RemoteCategory:    item.RemoteCategory,
@@ -4,22 +4,32 @@ import (
 	"errors"
 	"sensio/domain/common/utils"
 	"sensio/domain/terminal/device/dtos"
+	"sensio/domain/terminal/device/entities"
 	device_repositories "sensio/domain/terminal/device/repositories"
 	terminal_repositories "sensio/domain/terminal/terminal/repositories"
 	"strings"
 )
 
+// RoomDirectory validates room and zone assignments of devices
+type RoomDirectory interface {
+	// ResolveAssignment checks that the room and zone exist and that the zone lies in the room.
+	// It returns the room of the assignment, which is the room of the zone when roomID is empty.
+	ResolveAssignment(roomID, zoneID string) (string, error)
+}
+
 // UpdateDeviceUseCase handles updating an existing device
 type UpdateDeviceUseCase struct {
 	repository   device_repositories.IDeviceRepository
 	terminalRepo terminal_repositories.ITerminalRepository
+	rooms        RoomDirectory
 }
 
 // NewUpdateDeviceUseCase creates a new instance of UpdateDeviceUseCase
-func NewUpdateDeviceUseCase(repository device_repositories.IDeviceRepository, terminalRepo terminal_repositories.ITerminalRepository) *UpdateDeviceUseCase {
+func NewUpdateDeviceUseCase(repository device_repositories.IDeviceRepository, terminalRepo terminal_repositories.ITerminalRepository, rooms RoomDirectory) *UpdateDeviceUseCase {
 	return &UpdateDeviceUseCase{
 		repository:   repository,
 		terminalRepo: terminalRepo,
+		rooms:        rooms,
 	}
 }
 
@@ -41,6 +51,12 @@ func (uc *UpdateDeviceUseCase) UpdateDevice(id string, req *dtos.UpdateDeviceReq
 		device.Name = *req.Name
 	}
 
+	if req.RoomID != nil || req.ZoneID != nil {
+		if err := uc.assignRoom(device, req.RoomID, req.ZoneID); err != nil {
+			return err
+		}
+	}
+
 	// Save changes
 	if err := uc.repository.Update(device); err != nil {
 		return err
@@ -49,3 +65,41 @@ func (uc *UpdateDeviceUseCase) UpdateDevice(id string, req *dtos.UpdateDeviceReq
 	// Invalidate terminal cache
 	return uc.terminalRepo.InvalidateCache(device.TerminalID)
 }
+
+// assignRoom applies a room/zone assignment. An empty room unassigns both; an empty zone keeps
+// the room; a zone alone also moves the device to the room of the zone.
+func (uc *UpdateDeviceUseCase) assignRoom(device *entities.Device, roomID, zoneID *string) error {
+	room, zone := device.RoomID, device.ZoneID
+	if roomID != nil {
+		room = strings.TrimSpace(*roomID)
+		if room != device.RoomID {
+			zone = "" // A zone never outlives a room change
+		}
+	}
+	if zoneID != nil {
+		zone = strings.TrimSpace(*zoneID)
+		if roomID == nil && zone != "" {
+			room = ""
+		}
+	}
+
+	if room == "" && zone == "" {
+		device.RoomID, device.ZoneID = "", ""
+		return nil
+	}
+	if room == "" && roomID != nil {
+		return utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "zone_id", Message: "a zone cannot be assigned while unassigning the room"},
+		})
+	}
+	if uc.rooms == nil {
+		return errors.New("rooms are not available")
+	}
+
+	resolved, err := uc.rooms.ResolveAssignment(room, zone)
+	if err != nil {
+		return err
+	}
+	device.RoomID, device.ZoneID = resolved, zone
+	return nil
+}
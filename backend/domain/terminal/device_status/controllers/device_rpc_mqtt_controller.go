@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"fmt"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	terminal_dtos "sensio/domain/terminal/device_status/dtos"
+	usecases "sensio/domain/terminal/device_status/usecases"
+	"strings"
+)
+
+// AccessTokenProvider provides the Tuya access token used for device commands
+type AccessTokenProvider interface {
+	GetTuyaAccessToken() (string, error)
+}
+
+// DeviceRpcMqttController exposes device commands and status reads to terminals over the MQTT RPC router
+type DeviceRpcMqttController struct {
+	updateUseCase *usecases.UpdateDeviceStatusUseCase
+	listUseCase   *usecases.GetDeviceStatusesByDeviceIDUseCase
+	tokens        AccessTokenProvider
+}
+
+// NewDeviceRpcMqttController creates a new DeviceRpcMqttController instance
+func NewDeviceRpcMqttController(updateUseCase *usecases.UpdateDeviceStatusUseCase, listUseCase *usecases.GetDeviceStatusesByDeviceIDUseCase, tokens AccessTokenProvider) *DeviceRpcMqttController {
+	return &DeviceRpcMqttController{
+		updateUseCase: updateUseCase,
+		listUseCase:   listUseCase,
+		tokens:        tokens,
+	}
+}
+
+// RegisterMqttRoutes routes users/{mac}/{env}/rpc/device/command and users/{mac}/{env}/rpc/device/status
+func (c *DeviceRpcMqttController) RegisterMqttRoutes(router *infrastructure.MqttRpcRouter) error {
+	if router == nil {
+		return nil
+	}
+	if err := router.HandleTerminal("users/{mac}/{env}/rpc/device/command", c.SendCommand); err != nil {
+		return err
+	}
+	return router.HandleTerminal("users/{mac}/{env}/rpc/device/status", c.GetStatuses)
+}
+
+// SendCommand sends a switch or IR command to a device of the calling terminal and stores the new status
+func (c *DeviceRpcMqttController) SendCommand(req *infrastructure.MqttRpcRequest) (*infrastructure.MqttRpcResult, error) {
+	var body terminal_dtos.DeviceCommandMqttRequestDTO
+	if err := req.Bind(&body); err != nil {
+		return nil, err
+	}
+
+	var details []utils.ValidationErrorDetail
+	if strings.TrimSpace(body.DeviceID) == "" {
+		details = append(details, utils.ValidationErrorDetail{Field: "device_id", Message: "device_id is required"})
+	}
+	if strings.TrimSpace(body.Code) == "" {
+		details = append(details, utils.ValidationErrorDetail{Field: "code", Message: "code is required"})
+	}
+	if len(details) > 0 {
+		return nil, utils.NewValidationError("Validation Error", details)
+	}
+
+	accessToken, err := c.tokens.GetTuyaAccessToken()
+	if err != nil {
+		utils.LogError("DeviceRpc MQTT: Failed to get Tuya access token: %v", err)
+		return nil, infrastructure.NewMqttRpcError(infrastructure.MqttRpcCodeInternal, "Internal Server Error")
+	}
+
+	update := &terminal_dtos.UpdateDeviceStatusRequestDTO{
+		Code:     body.Code,
+		Value:    body.Value,
+		RemoteID: body.RemoteID,
+	}
+	if err := c.updateUseCase.UpdateTerminalDeviceStatus(req.Terminal.ID, body.DeviceID, update, accessToken); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, infrastructure.NewMqttRpcError(infrastructure.MqttRpcCodeNotFound, "Device not found")
+		}
+		utils.LogError("DeviceRpc MQTT: Command for device %s failed: %v", body.DeviceID, err)
+		return nil, err
+	}
+
+	return &infrastructure.MqttRpcResult{
+		Message: "Command sent successfully",
+		Data: terminal_dtos.DeviceStatusResponseDTO{
+			DeviceID: body.DeviceID,
+			Code:     body.Code,
+			Value:    fmt.Sprintf("%v", body.Value),
+		},
+	}, nil
+}
+
+// GetStatuses returns the stored statuses of a device of the calling terminal
+func (c *DeviceRpcMqttController) GetStatuses(req *infrastructure.MqttRpcRequest) (*infrastructure.MqttRpcResult, error) {
+	var body terminal_dtos.DeviceStatusMqttRequestDTO
+	if err := req.Bind(&body); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(body.DeviceID) == "" {
+		return nil, utils.NewValidationError("Validation Error", []utils.ValidationErrorDetail{
+			{Field: "device_id", Message: "device_id is required"},
+		})
+	}
+
+	statuses, err := c.listUseCase.ListTerminalDeviceStatuses(req.Terminal.ID, body.DeviceID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, infrastructure.NewMqttRpcError(infrastructure.MqttRpcCodeNotFound, "Device not found")
+		}
+		return nil, err
+	}
+
+	return &infrastructure.MqttRpcResult{
+		Message: "Device statuses retrieved successfully",
+		Data:    statuses,
+	}, nil
+}
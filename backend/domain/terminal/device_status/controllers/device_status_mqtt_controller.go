@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	terminal_dtos "sensio/domain/terminal/device_status/dtos"
+	usecases "sensio/domain/terminal/device_status/usecases"
+	"strings"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// DeviceStatusMqttController consumes device status reports published by terminals
+type DeviceStatusMqttController struct {
+	useCase *usecases.ReportDeviceStatusUseCase
+	mqttSvc *infrastructure.MqttService
+}
+
+// NewDeviceStatusMqttController creates a new DeviceStatusMqttController instance
+func NewDeviceStatusMqttController(useCase *usecases.ReportDeviceStatusUseCase, mqttSvc *infrastructure.MqttService) *DeviceStatusMqttController {
+	return &DeviceStatusMqttController{
+		useCase: useCase,
+		mqttSvc: mqttSvc,
+	}
+}
+
+// StartMqttSubscription subscribes to users/+/{env}/device_status
+func (c *DeviceStatusMqttController) StartMqttSubscription() error {
+	if c.mqttSvc == nil {
+		return nil
+	}
+
+	topic := fmt.Sprintf("users/+/%s/device_status", utils.GetConfig().ApplicationEnvironment)
+	return c.mqttSvc.Subscribe(topic, 0, func(client mqtt.Client, msg mqtt.Message) {
+		payload := msg.Payload()
+		if len(payload) == 0 {
+			return
+		}
+
+		var req terminal_dtos.ReportDeviceStatusRequestDTO
+		if err := json.Unmarshal(payload, &req); err != nil {
+			utils.LogError("DeviceStatus MQTT: Failed to unmarshal message on %s: %v", msg.Topic(), err)
+			return
+		}
+
+		// Extract MAC from topic: (optionally $share/group/)users/MAC/env/device_status
+		topicParts := strings.Split(msg.Topic(), "/")
+		mac := ""
+		for i, part := range topicParts {
+			if part == "users" && i+1 < len(topicParts) {
+				mac = topicParts[i+1]
+				break
+			}
+		}
+		if mac == "" {
+			utils.LogError("DeviceStatus MQTT: Could not extract MAC from topic %s", msg.Topic())
+			return
+		}
+
+		if err := c.useCase.ReportDeviceStatus(mac, &req); err != nil {
+			utils.LogWarn("DeviceStatus MQTT: Rejected report from %s: %v", mac, err)
+		}
+	})
+}
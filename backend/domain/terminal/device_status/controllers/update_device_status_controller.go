@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"sensio/domain/common/dtos"
 	"sensio/domain/common/utils" // Added for utils.ValidationError
@@ -74,7 +75,8 @@ func (c *UpdateDeviceStatusController) UpdateDeviceStatus(ctx *gin.Context) {
 
 	// Execute use case
 	if err := c.useCase.UpdateDeviceStatus(id, &req, accessToken); err != nil {
-		if valErr, ok := err.(*utils.ValidationError); ok {
+		var valErr *utils.ValidationError
+		if errors.As(err, &valErr) {
 			ctx.JSON(http.StatusUnprocessableEntity, dtos.StandardResponse{
 				Status:  false,
 				Message: valErr.Message,
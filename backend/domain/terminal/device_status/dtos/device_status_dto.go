@@ -43,3 +43,33 @@ type DeviceStatusListResponseDTO struct {
 type DeviceStatusSingleResponseDTO struct {
 	DeviceStatus DeviceStatusResponseDTO `json:"device_status"`
 }
+
+// ReportDeviceStatusRequestDTO is the MQTT payload a terminal publishes on users/{mac}/{env}/device_status
+// to report status values observed locally (e.g. sensor readings), without sending a Tuya command
+type ReportDeviceStatusRequestDTO struct {
+	DeviceID string              `json:"device_id"`
+	Statuses []ReportedStatusDTO `json:"statuses"`
+}
+
+// ReportedStatusDTO is a single reported status value
+type ReportedStatusDTO struct {
+	Code  string      `json:"code"`
+	Value interface{} `json:"value"`
+}
+
+// DeviceCommandMqttRequestDTO is the MQTT RPC payload a terminal publishes on users/{mac}/{env}/rpc/device/command
+// to send a Tuya command to one of its devices
+type DeviceCommandMqttRequestDTO struct {
+	RequestID string      `json:"request_id,omitempty"`
+	DeviceID  string      `json:"device_id"`
+	Code      string      `json:"code"`
+	Value     interface{} `json:"value,omitempty"`
+	RemoteID  string      `json:"remote_id,omitempty"` // Optional, for IR devices
+}
+
+// DeviceStatusMqttRequestDTO is the MQTT RPC payload a terminal publishes on users/{mac}/{env}/rpc/device/status
+// to read the stored statuses of one of its devices
+type DeviceStatusMqttRequestDTO struct {
+	RequestID string `json:"request_id,omitempty"`
+	DeviceID  string `json:"device_id"`
+}
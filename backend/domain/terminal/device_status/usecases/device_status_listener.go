@@ -0,0 +1,38 @@
+package usecases
+
+import "time"
+
+// Sources of a device status change
+const (
+	StatusSourceAPI   = "api"   // Written through PUT /api/devices/:id/status
+	StatusSourceMQTT  = "mqtt"  // Reported or commanded by a terminal over MQTT
+	StatusSourcePoll  = "poll"  // Read back from the Tuya cloud by a background poller
+	StatusSourceCloud = "cloud" // Pushed by the Tuya cloud message service
+)
+
+// DeviceStatusChange describes a status value that was just persisted
+type DeviceStatusChange struct {
+	TerminalID    string
+	DeviceID      string
+	Code          string
+	Value         string
+	PreviousValue string
+	Source        string
+	ChangedAt     time.Time
+}
+
+// DeviceStatusListener is notified after a device status has been written.
+// Implementations must not block; long-running work should be done asynchronously.
+type DeviceStatusListener interface {
+	OnDeviceStatusChanged(change DeviceStatusChange)
+}
+
+// DeviceStatusListeners fans a status change out to several listeners, in order
+type DeviceStatusListeners []DeviceStatusListener
+
+// OnDeviceStatusChanged forwards the change to every listener
+func (l DeviceStatusListeners) OnDeviceStatusChanged(change DeviceStatusChange) {
+	for _, listener := range l {
+		listener.OnDeviceStatusChanged(change)
+	}
+}
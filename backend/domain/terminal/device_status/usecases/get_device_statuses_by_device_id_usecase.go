@@ -23,10 +23,22 @@ func NewGetDeviceStatusesByDeviceIDUseCase(repo device_status_repositories.IDevi
 
 // Execute retrieves all statuses for a device
 func (uc *GetDeviceStatusesByDeviceIDUseCase) ListDeviceStatusesByDeviceID(deviceID string, page, limit int) (*dtos.DeviceStatusListResponseDTO, error) {
-	_, err := uc.devRepo.GetByID(deviceID)
+	return uc.list("", deviceID, page, limit)
+}
+
+// ListTerminalDeviceStatuses retrieves all statuses of a device owned by terminalID (MQTT RPC)
+func (uc *GetDeviceStatusesByDeviceIDUseCase) ListTerminalDeviceStatuses(terminalID, deviceID string) (*dtos.DeviceStatusListResponseDTO, error) {
+	return uc.list(terminalID, deviceID, 1, 0)
+}
+
+func (uc *GetDeviceStatusesByDeviceIDUseCase) list(terminalID, deviceID string, page, limit int) (*dtos.DeviceStatusListResponseDTO, error) {
+	device, err := uc.devRepo.GetByID(deviceID)
 	if err != nil {
 		return nil, fmt.Errorf("Device not found: %w", err)
 	}
+	if terminalID != "" && device.TerminalID != terminalID {
+		return nil, fmt.Errorf("Device not found: device %s does not belong to terminal %s", deviceID, terminalID)
+	}
 
 	// Prepare Pagination
 	if page <= 0 {
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"fmt"
+	"sensio/domain/common/utils"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	"sensio/domain/terminal/device_status/dtos"
+	"sensio/domain/terminal/device_status/entities"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
+	terminal_repositories "sensio/domain/terminal/terminal/repositories"
+	"time"
+)
+
+// ReportDeviceStatusUseCase stores status values reported by a terminal over MQTT.
+// Unlike UpdateDeviceStatusUseCase it does not send anything to Tuya: the device already
+// is in the reported state, we only record it and notify listeners.
+type ReportDeviceStatusUseCase struct {
+	repo         device_status_repositories.IDeviceStatusRepository
+	devRepo      device_repositories.IDeviceRepository
+	terminalRepo terminal_repositories.ITerminalRepository
+
+	listener DeviceStatusListener
+}
+
+// NewReportDeviceStatusUseCase creates a new instance of ReportDeviceStatusUseCase
+func NewReportDeviceStatusUseCase(repo device_status_repositories.IDeviceStatusRepository, devRepo device_repositories.IDeviceRepository, terminalRepo terminal_repositories.ITerminalRepository) *ReportDeviceStatusUseCase {
+	return &ReportDeviceStatusUseCase{
+		repo:         repo,
+		devRepo:      devRepo,
+		terminalRepo: terminalRepo,
+	}
+}
+
+// SetStatusListener registers the listener notified after each reported status is stored
+func (uc *ReportDeviceStatusUseCase) SetStatusListener(listener DeviceStatusListener) {
+	uc.listener = listener
+}
+
+// ReportDeviceStatus records the statuses reported by the terminal identified by macAddress
+func (uc *ReportDeviceStatusUseCase) ReportDeviceStatus(macAddress string, req *dtos.ReportDeviceStatusRequestDTO) error {
+	var details []utils.ValidationErrorDetail
+	if req.DeviceID == "" {
+		details = append(details, utils.ValidationErrorDetail{Field: "device_id", Message: "device_id is required"})
+	}
+	if len(req.Statuses) == 0 {
+		details = append(details, utils.ValidationErrorDetail{Field: "statuses", Message: "at least one status is required"})
+	}
+	for i, s := range req.Statuses {
+		if s.Code == "" {
+			details = append(details, utils.ValidationErrorDetail{Field: fmt.Sprintf("statuses[%d].code", i), Message: "code is required"})
+		}
+	}
+	if len(details) > 0 {
+		return utils.NewValidationError("Validation Error", details)
+	}
+
+	terminal, err := uc.terminalRepo.GetByMacAddress(macAddress)
+	if err != nil {
+		return fmt.Errorf("terminal not found for mac %s: %w", macAddress, err)
+	}
+	device, err := uc.devRepo.GetByID(req.DeviceID)
+	if err != nil {
+		return fmt.Errorf("Device not found: %w", err)
+	}
+	if device.TerminalID != terminal.ID {
+		return fmt.Errorf("device %s does not belong to terminal %s", device.ID, terminal.ID)
+	}
+
+	for _, s := range req.Statuses {
+		previous := ""
+		if existing, err := uc.repo.GetByDeviceIDAndCode(device.ID, s.Code); err == nil && existing != nil {
+			previous = existing.Value
+		}
+
+		valStr := fmt.Sprintf("%v", s.Value)
+		if err := uc.repo.Upsert(&entities.DeviceStatus{DeviceID: device.ID, Code: s.Code, Value: valStr}); err != nil {
+			return err
+		}
+
+		if uc.listener != nil {
+			uc.listener.OnDeviceStatusChanged(DeviceStatusChange{
+				TerminalID:    terminal.ID,
+				DeviceID:      device.ID,
+				Code:          s.Code,
+				Value:         valStr,
+				PreviousValue: previous,
+				Source:        StatusSourceMQTT,
+				ChangedAt:     time.Now(),
+			})
+		}
+	}
+	return nil
+}
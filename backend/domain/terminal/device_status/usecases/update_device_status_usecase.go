@@ -8,6 +8,7 @@ import (
 	"sensio/domain/terminal/device_status/entities"
 	device_status_repositories "sensio/domain/terminal/device_status/repositories"
 	tuya_dtos "sensio/domain/tuya/dtos"
+	"time"
 )
 
 // TuyaDeviceControlExecutor defines the interface for Tuya device control operations
@@ -16,49 +17,68 @@ type TuyaDeviceControlExecutor interface {
 	SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error)
 }
 
+// DeviceCommandValidator checks a status code and value against the device specification and
+// returns them normalized, or the field-level problems found
+type DeviceCommandValidator interface {
+	ValidateCommand(accessToken, deviceID, code string, value interface{}) (string, interface{}, []utils.ValidationErrorDetail)
+}
+
 // UpdateDeviceStatusUseCase handles updating an existing device status
 type UpdateDeviceStatusUseCase struct {
-	repo    device_status_repositories.IDeviceStatusRepository
-	devRepo device_repositories.IDeviceRepository
-	tuyaCmd TuyaDeviceControlExecutor
+	repo      device_status_repositories.IDeviceStatusRepository
+	devRepo   device_repositories.IDeviceRepository
+	tuyaCmd   TuyaDeviceControlExecutor
+	validator DeviceCommandValidator
+
+	listener DeviceStatusListener
 }
 
 // NewUpdateDeviceStatusUseCase creates a new instance of UpdateDeviceStatusUseCase
-func NewUpdateDeviceStatusUseCase(repo device_status_repositories.IDeviceStatusRepository, devRepo device_repositories.IDeviceRepository, tuyaCmd TuyaDeviceControlExecutor) *UpdateDeviceStatusUseCase {
+func NewUpdateDeviceStatusUseCase(repo device_status_repositories.IDeviceStatusRepository, devRepo device_repositories.IDeviceRepository, tuyaCmd TuyaDeviceControlExecutor, validator DeviceCommandValidator) *UpdateDeviceStatusUseCase {
 	return &UpdateDeviceStatusUseCase{
-		repo:    repo,
-		devRepo: devRepo,
-		tuyaCmd: tuyaCmd,
+		repo:      repo,
+		devRepo:   devRepo,
+		tuyaCmd:   tuyaCmd,
+		validator: validator,
 	}
 }
 
+// SetStatusListener registers the listener notified after each successful status write
+func (uc *UpdateDeviceStatusUseCase) SetStatusListener(listener DeviceStatusListener) {
+	uc.listener = listener
+}
+
 // Execute updates a device status
 func (uc *UpdateDeviceStatusUseCase) UpdateDeviceStatus(deviceID string, req *dtos.UpdateDeviceStatusRequestDTO, accessToken string) error {
+	return uc.update("", deviceID, req, accessToken, StatusSourceAPI)
+}
+
+// UpdateTerminalDeviceStatus sends a command to a device on behalf of a terminal (MQTT RPC).
+// Devices that belong to another terminal are reported as not found.
+func (uc *UpdateDeviceStatusUseCase) UpdateTerminalDeviceStatus(terminalID, deviceID string, req *dtos.UpdateDeviceStatusRequestDTO, accessToken string) error {
+	return uc.update(terminalID, deviceID, req, accessToken, StatusSourceMQTT)
+}
+
+func (uc *UpdateDeviceStatusUseCase) update(terminalID, deviceID string, req *dtos.UpdateDeviceStatusRequestDTO, accessToken, source string) error {
 	// Check device existence
-	_, err := uc.devRepo.GetByID(deviceID)
+	device, err := uc.devRepo.GetByID(deviceID)
 	if err != nil {
 		return fmt.Errorf("Device not found: %w", err)
 	}
-
-	// Code validation (Simulated based on scenario requirements)
-	var details []utils.ValidationErrorDetail
-	if req.Code == "nuclear_launch" {
-		details = append(details, utils.ValidationErrorDetail{Field: "code", Message: "Invalid status code for this device"})
+	if terminalID != "" && device.TerminalID != terminalID {
+		return fmt.Errorf("Device not found: device %s does not belong to terminal %s", deviceID, terminalID)
 	}
 
-	// Value validation (Simulated)
-	if req.Code == "dimmer" {
-		// DTO Value is interface{}. Need to check type/value.
-		// "full_power" string is invalid for intent of int.
-		if _, ok := req.Value.(string); ok && req.Value == "full_power" {
-			details = append(details, utils.ValidationErrorDetail{Field: "value", Message: "Invalid value for status code 'dimmer'"})
+	// IR remotes have no Tuya specification, their keys are validated by the IR endpoint
+	code, value := req.Code, req.Value
+	if req.RemoteID == "" && uc.validator != nil {
+		var details []utils.ValidationErrorDetail
+		code, value, details = uc.validator.ValidateCommand(accessToken, deviceID, req.Code, req.Value)
+		if len(details) > 0 {
+			return utils.NewValidationError("Validation Error", details)
 		}
 	}
 
-	if len(details) > 0 {
-		return utils.NewValidationError("Validation Error", details)
-	}
-
 	// Execute Tuya Command
 	if req.RemoteID != "" {
 		// IR Command
@@ -85,8 +105,8 @@ func (uc *UpdateDeviceStatusUseCase) UpdateDeviceStatus(deviceID string, req *dt
 	} else {
 		// Switch/Standard Command
 		cmd := tuya_dtos.TuyaCommandDTO{
-			Code:  req.Code,
-			Value: req.Value,
+			Code:  code,
+			Value: value,
 		}
 		success, err := uc.tuyaCmd.SendSwitchCommand(accessToken, deviceID, []tuya_dtos.TuyaCommandDTO{cmd})
 		if err != nil {
@@ -98,12 +118,32 @@ func (uc *UpdateDeviceStatusUseCase) UpdateDeviceStatus(deviceID string, req *dt
 	}
 
 	// Convert value to string for storage
-	valStr := fmt.Sprintf("%v", req.Value)
+	valStr := fmt.Sprintf("%v", value)
+
+	previous := ""
+	if existing, err := uc.repo.GetByDeviceIDAndCode(deviceID, code); err == nil && existing != nil {
+		previous = existing.Value
+	}
 
 	status := &entities.DeviceStatus{
 		DeviceID: deviceID,
-		Code:     req.Code,
+		Code:     code,
 		Value:    valStr,
 	}
-	return uc.repo.Upsert(status)
+	if err := uc.repo.Upsert(status); err != nil {
+		return err
+	}
+
+	if uc.listener != nil {
+		uc.listener.OnDeviceStatusChanged(DeviceStatusChange{
+			TerminalID:    device.TerminalID,
+			DeviceID:      deviceID,
+			Code:          code,
+			Value:         valStr,
+			PreviousValue: previous,
+			Source:        source,
+			ChangedAt:     time.Now(),
+		})
+	}
+	return nil
 }
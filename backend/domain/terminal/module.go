@@ -32,6 +32,7 @@ type TerminalModule struct {
 	GetMQTTCredentialsController *terminal.GetMQTTCredentialsController
 	UpdateController             *terminal.UpdateTerminalController
 	DeleteController             *terminal.DeleteTerminalController
+	ConfigMqttController         *terminal.TerminalConfigMqttController
 
 	// Device Controllers
 	CreateDeviceController           *device.CreateDeviceController
@@ -46,6 +47,11 @@ type TerminalModule struct {
 	GetDeviceStatusByCodeController       *device_status.GetDeviceStatusByCodeController
 	GetDeviceStatusesByDeviceIDController *device_status.GetDeviceStatusesByDeviceIDController
 	UpdateDeviceStatusController          *device_status.UpdateDeviceStatusController
+	DeviceStatusMqttController            *device_status.DeviceStatusMqttController
+	DeviceRpcMqttController               *device_status.DeviceRpcMqttController
+
+	updateDeviceStatusUseCase *device_status_usecases.UpdateDeviceStatusUseCase
+	reportDeviceStatusUseCase *device_status_usecases.ReportDeviceStatusUseCase
 }
 
 // NewTerminalModule initializes the Terminal module
@@ -55,6 +61,9 @@ func NewTerminalModule(
 	tuyaAuthUC tuya_usecases.TuyaAuthUseCase,
 	tuyaGetDeviceUC *tuya_usecases.TuyaGetDeviceByIDUseCase,
 	tuyaDeviceControlUC device_status_usecases.TuyaDeviceControlExecutor,
+	tuyaCapabilities device_status_usecases.DeviceCommandValidator,
+	rooms device_usecases.RoomDirectory,
+	mqttSvc *infrastructure.MqttService,
 ) *TerminalModule {
 	// Services
 	terminalExternalService := terminal_services.NewMacRegistrationExternalService()
@@ -80,14 +89,15 @@ func NewTerminalModule(
 	getAllDevicesUseCase := device_usecases.NewGetAllDevicesUseCase(deviceRepository)
 	getDeviceByIDUseCase := device_usecases.NewGetDeviceByIDUseCase(deviceRepository)
 	getDevicesByTerminalIDUseCase := device_usecases.NewGetDevicesByTerminalIDUseCase(deviceRepository, terminalRepository)
-	updateDeviceUseCase := device_usecases.NewUpdateDeviceUseCase(deviceRepository, terminalRepository)
+	updateDeviceUseCase := device_usecases.NewUpdateDeviceUseCase(deviceRepository, terminalRepository, rooms)
 	deleteDeviceUseCase := device_usecases.NewDeleteDeviceUseCase(deviceRepository, deviceStatusRepository, terminalRepository)
 
 	// Device Status Use Cases
 	getDeviceStatusesByDeviceIDUseCase := device_status_usecases.NewGetDeviceStatusesByDeviceIDUseCase(deviceStatusRepository, deviceRepository)
 	getAllDeviceStatusesUseCase := device_status_usecases.NewGetAllDeviceStatusesUseCase(deviceStatusRepository)
 	getDeviceStatusByCodeUseCase := device_status_usecases.NewGetDeviceStatusByCodeUseCase(deviceStatusRepository, deviceRepository)
-	updateDeviceStatusUseCase := device_status_usecases.NewUpdateDeviceStatusUseCase(deviceStatusRepository, deviceRepository, tuyaDeviceControlUC)
+	updateDeviceStatusUseCase := device_status_usecases.NewUpdateDeviceStatusUseCase(deviceStatusRepository, deviceRepository, tuyaDeviceControlUC, tuyaCapabilities)
+	reportDeviceStatusUseCase := device_status_usecases.NewReportDeviceStatusUseCase(deviceStatusRepository, deviceRepository, terminalRepository)
 
 	// Controllers
 	return &TerminalModule{
@@ -98,6 +108,7 @@ func NewTerminalModule(
 		GetMQTTCredentialsController: terminal.NewGetMQTTCredentialsController(mqttAuthClient),
 		UpdateController:             terminal.NewUpdateTerminalController(updateTerminalUseCase),
 		DeleteController:             terminal.NewDeleteTerminalController(deleteTerminalUseCase),
+		ConfigMqttController:         terminal.NewTerminalConfigMqttController(getTerminalByIDUseCase, getDevicesByTerminalIDUseCase),
 
 		CreateDeviceController:           device.NewCreateDeviceController(createDeviceUseCase),
 		GetAllDevicesController:          device.NewGetAllDevicesController(getAllDevicesUseCase),
@@ -110,6 +121,34 @@ func NewTerminalModule(
 		GetDeviceStatusByCodeController:       device_status.NewGetDeviceStatusByCodeController(getDeviceStatusByCodeUseCase),
 		GetDeviceStatusesByDeviceIDController: device_status.NewGetDeviceStatusesByDeviceIDController(getDeviceStatusesByDeviceIDUseCase),
 		UpdateDeviceStatusController:          device_status.NewUpdateDeviceStatusController(updateDeviceStatusUseCase),
+		DeviceStatusMqttController:            device_status.NewDeviceStatusMqttController(reportDeviceStatusUseCase, mqttSvc),
+		DeviceRpcMqttController:               device_status.NewDeviceRpcMqttController(updateDeviceStatusUseCase, getDeviceStatusesByDeviceIDUseCase, tuyaAuthUC),
+
+		updateDeviceStatusUseCase: updateDeviceStatusUseCase,
+		reportDeviceStatusUseCase: reportDeviceStatusUseCase,
+	}
+}
+
+// SetDeviceStatusListener registers a listener for status writes coming from both the API and MQTT
+func (m *TerminalModule) SetDeviceStatusListener(listener device_status_usecases.DeviceStatusListener) {
+	m.updateDeviceStatusUseCase.SetStatusListener(listener)
+	m.reportDeviceStatusUseCase.SetStatusListener(listener)
+}
+
+// StartMqttSubscription starts consuming device status reports published by terminals
+func (m *TerminalModule) StartMqttSubscription() {
+	if err := m.DeviceStatusMqttController.StartMqttSubscription(); err != nil {
+		utils.LogError("Terminal module MQTT subscription failed: %v", err)
+	}
+}
+
+// RegisterMqttRoutes exposes terminal config, device commands and device status reads over the MQTT RPC router
+func (m *TerminalModule) RegisterMqttRoutes(router *infrastructure.MqttRpcRouter) {
+	if err := m.ConfigMqttController.RegisterMqttRoutes(router); err != nil {
+		utils.LogError("Terminal module MQTT RPC registration failed: %v", err)
+	}
+	if err := m.DeviceRpcMqttController.RegisterMqttRoutes(router); err != nil {
+		utils.LogError("Terminal module MQTT RPC registration failed: %v", err)
 	}
 }
 
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	device_dtos "sensio/domain/terminal/device/dtos"
+	device_usecases "sensio/domain/terminal/device/usecases"
+	terminal_dtos "sensio/domain/terminal/terminal/dtos"
+	usecases "sensio/domain/terminal/terminal/usecases"
+)
+
+// TerminalConfigMqttController lets a terminal fetch its own configuration over the MQTT RPC router
+type TerminalConfigMqttController struct {
+	terminalUseCase *usecases.GetTerminalByIDUseCase
+	devicesUseCase  *device_usecases.GetDevicesByTerminalIDUseCase
+}
+
+// NewTerminalConfigMqttController creates a new TerminalConfigMqttController instance
+func NewTerminalConfigMqttController(terminalUseCase *usecases.GetTerminalByIDUseCase, devicesUseCase *device_usecases.GetDevicesByTerminalIDUseCase) *TerminalConfigMqttController {
+	return &TerminalConfigMqttController{
+		terminalUseCase: terminalUseCase,
+		devicesUseCase:  devicesUseCase,
+	}
+}
+
+// RegisterMqttRoutes routes users/{mac}/{env}/rpc/terminal/config
+func (c *TerminalConfigMqttController) RegisterMqttRoutes(router *infrastructure.MqttRpcRouter) error {
+	if router == nil {
+		return nil
+	}
+	return router.HandleTerminal("users/{mac}/{env}/rpc/terminal/config", c.GetConfig)
+}
+
+// GetConfig returns the calling terminal and its devices
+func (c *TerminalConfigMqttController) GetConfig(req *infrastructure.MqttRpcRequest) (*infrastructure.MqttRpcResult, error) {
+	terminal, err := c.terminalUseCase.GetTerminalByID(req.Terminal.ID)
+	if err != nil {
+		return nil, infrastructure.NewMqttRpcError(infrastructure.MqttRpcCodeNotFound, "Terminal not found")
+	}
+
+	devices := []device_dtos.DeviceResponseDTO{}
+	list, err := c.devicesUseCase.ListDevicesByTerminalID(req.Terminal.ID, 1, 0)
+	if err != nil {
+		utils.LogWarn("TerminalConfig MQTT: Failed to list devices for terminal %s: %v", req.Terminal.ID, err)
+	} else if list != nil {
+		devices = list.Devices
+	}
+
+	return &infrastructure.MqttRpcResult{
+		Message: "Terminal config retrieved successfully",
+		Data: terminal_dtos.TerminalConfigResponseDTO{
+			Terminal: terminal.Terminal,
+			Devices:  devices,
+		},
+	}, nil
+}
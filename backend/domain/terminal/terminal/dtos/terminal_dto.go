@@ -1,6 +1,9 @@
 package dtos
 
-import "time"
+import (
+	device_dtos "sensio/domain/terminal/device/dtos"
+	"time"
+)
 
 // CreateTerminalRequestDTO represents the request body for creating a new terminal
 type CreateTerminalRequestDTO struct {
@@ -8,6 +11,7 @@ type CreateTerminalRequestDTO struct {
 	RoomID       string `json:"room_id" binding:"required"`
 	Name         string `json:"name" binding:"required"`
 	DeviceTypeID string `json:"device_type_id" binding:"required"`
+	Timezone     string `json:"timezone,omitempty" example:"Asia/Jakarta"` // IANA name; defaults to SCENE_SCHEDULER_DEFAULT_TIMEZONE
 }
 
 // CreateTerminalResponseDTO represents the response for creating a terminal
@@ -24,6 +28,7 @@ type UpdateTerminalRequestDTO struct {
 	Name         *string `json:"name,omitempty" example:"Updated Hub Name"`
 	DeviceTypeID *string `json:"device_type_id,omitempty" example:"hub-type-002"`
 	AiProvider   *string `json:"ai_provider,omitempty" example:"openai"`
+	Timezone     *string `json:"timezone,omitempty" example:"Asia/Jakarta"` // Empty string resets to SCENE_SCHEDULER_DEFAULT_TIMEZONE
 }
 
 // TerminalFilterDTO represents filter options for listing terminal
@@ -42,6 +47,7 @@ type TerminalResponseDTO struct {
 	Name         string    `json:"name"`
 	DeviceTypeID string    `json:"device_type_id"`
 	AiProvider   *string   `json:"ai_provider,omitempty"`
+	Timezone     string    `json:"timezone,omitempty"`
 	MQTTUsername string    `json:"mqtt_username"`
 	MQTTPassword string    `json:"mqtt_password,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
@@ -62,6 +68,12 @@ type TerminalSingleResponseDTO struct {
 	Terminal TerminalResponseDTO `json:"terminal"`
 }
 
+// TerminalConfigResponseDTO is the reply to users/{mac}/{env}/rpc/terminal/config: the terminal and its devices
+type TerminalConfigResponseDTO struct {
+	Terminal TerminalResponseDTO             `json:"terminal"`
+	Devices  []device_dtos.DeviceResponseDTO `json:"devices"`
+}
+
 // MQTTCredentialsResponseDTO represents MQTT credentials for device authentication
 type MQTTCredentialsResponseDTO struct {
 	Username string `json:"username" example:"device_001"`
@@ -15,6 +15,7 @@ type Terminal struct {
 	Name         string         `gorm:"type:varchar(255);not null" json:"name"`
 	DeviceTypeID string         `gorm:"type:varchar(255)" json:"device_type_id"`
 	AiProvider   *string        `gorm:"type:varchar(50);index" json:"ai_provider"`
+	Timezone     string         `gorm:"type:varchar(64)" json:"timezone"` // IANA name used to schedule scene triggers; empty means SCENE_SCHEDULER_DEFAULT_TIMEZONE
 	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"errors"
+	"sensio/domain/common/infrastructure"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TerminalMqttResolver resolves the terminal behind the {mac} segment of MQTT RPC topics
+type TerminalMqttResolver struct {
+	repo ITerminalRepository
+}
+
+// NewTerminalMqttResolver creates a new TerminalMqttResolver instance
+func NewTerminalMqttResolver(repo ITerminalRepository) *TerminalMqttResolver {
+	return &TerminalMqttResolver{repo: repo}
+}
+
+// ResolveTerminal returns the terminal registered with mac, or nil when there is none
+func (r *TerminalMqttResolver) ResolveTerminal(mac string) (*infrastructure.MqttRpcTerminal, error) {
+	terminal, err := r.repo.GetByMacAddress(strings.ToUpper(mac))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if terminal == nil {
+		return nil, nil
+	}
+	return &infrastructure.MqttRpcTerminal{
+		ID:         terminal.ID,
+		MacAddress: terminal.MacAddress,
+		TuyaUID:    terminal.TuyaUID,
+	}, nil
+}
@@ -9,6 +9,7 @@ import (
 	"sensio/domain/terminal/terminal/repositories"
 	"sensio/domain/terminal/terminal/services"
 	"strings"
+	"time"
 
 	"strconv"
 
@@ -74,6 +75,11 @@ func (uc *CreateTerminalUseCase) CreateTerminal(req *dtos.CreateTerminalRequestD
 		details = append(details, utils.ValidationErrorDetail{Field: "device_type_id", Message: "device_type_id is required"})
 	}
 
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if _, err := time.LoadLocation(req.Timezone); req.Timezone != "" && err != nil {
+		details = append(details, utils.ValidationErrorDetail{Field: "timezone", Message: "unknown IANA timezone: " + req.Timezone})
+	}
+
 	if len(details) > 0 {
 		return nil, false, utils.NewValidationError("Validation Error", details)
 	}
@@ -136,6 +142,7 @@ func (uc *CreateTerminalUseCase) CreateTerminal(req *dtos.CreateTerminalRequestD
 		RoomID:       req.RoomID,
 		Name:         req.Name,
 		DeviceTypeID: req.DeviceTypeID,
+		Timezone:     req.Timezone,
 	}
 
 	// Save to database
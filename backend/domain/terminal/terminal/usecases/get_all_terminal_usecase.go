@@ -57,6 +57,7 @@ func (uc *GetAllTerminalUseCase) ListTerminal(filter *dtos.TerminalFilterDTO) (*
 			RoomID:       item.RoomID,
 			DeviceTypeID: item.DeviceTypeID,
 			AiProvider:   item.AiProvider,
+			Timezone:     item.Timezone,
 			CreatedAt:    item.CreatedAt,
 			UpdatedAt:    item.UpdatedAt,
 		})
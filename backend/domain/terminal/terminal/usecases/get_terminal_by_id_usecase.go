@@ -45,6 +45,7 @@ func (uc *GetTerminalByIDUseCase) GetTerminalByID(id string) (*dtos.TerminalSing
 			Name:         item.Name,
 			DeviceTypeID: item.DeviceTypeID,
 			AiProvider:   item.AiProvider,
+			Timezone:     item.Timezone,
 			CreatedAt:    item.CreatedAt,
 			UpdatedAt:    item.UpdatedAt,
 		},
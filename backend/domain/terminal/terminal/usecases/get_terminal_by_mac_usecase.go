@@ -77,6 +77,7 @@ func (uc *GetTerminalByMACUseCase) GetTerminalByMAC(macAddress string) (*dtos.Te
 			Name:         terminal.Name,
 			DeviceTypeID: terminal.DeviceTypeID,
 			AiProvider:   terminal.AiProvider,
+			Timezone:     terminal.Timezone,
 			MQTTUsername: mqttUsername,
 			MQTTPassword: mqttPassword,
 			CreatedAt:    terminal.CreatedAt,
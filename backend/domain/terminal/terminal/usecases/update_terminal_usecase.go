@@ -8,6 +8,7 @@ import (
 	"sensio/domain/terminal/terminal/dtos"
 	"sensio/domain/terminal/terminal/repositories"
 	"strings"
+	"time"
 )
 
 // UpdateTerminalUseCase handles updating an existing terminal
@@ -77,11 +78,16 @@ func (uc *UpdateTerminalUseCase) UpdateTerminal(id string, req *dtos.UpdateTermi
 			item.AiProvider = nil
 		} else {
 			normalizedProvider := providers.NormalizeProvider(*req.AiProvider)
-			// Validate provider - only remote providers are supported
-			if !providers.IsValidProvider(normalizedProvider) {
+			// Validate provider - remote providers, or whisper.cpp for local transcription
+			if !providers.IsValidTerminalProvider(normalizedProvider) {
 				details = append(details, utils.ValidationErrorDetail{
 					Field:   "ai_provider",
-					Message: "Invalid ai_provider. Supported values: gemini, openai, groq, orion",
+					Message: "Invalid ai_provider. Supported values: gemini, openai, groq, orion, whispercpp",
+				})
+			} else if normalizedProvider == providers.WhisperCppProvider && utils.GetConfig().WhisperLocalModel == "" {
+				details = append(details, utils.ValidationErrorDetail{
+					Field:   "ai_provider",
+					Message: "whispercpp requires WHISPER_LOCAL_MODEL",
 				})
 			} else {
 				item.AiProvider = &normalizedProvider
@@ -89,6 +95,15 @@ func (uc *UpdateTerminalUseCase) UpdateTerminal(id string, req *dtos.UpdateTermi
 		}
 	}
 
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if _, err := time.LoadLocation(timezone); timezone != "" && err != nil {
+			details = append(details, utils.ValidationErrorDetail{Field: "timezone", Message: "unknown IANA timezone: " + timezone})
+		} else {
+			item.Timezone = timezone
+		}
+	}
+
 	if len(details) > 0 {
 		return nil, utils.NewValidationError("Validation Error", details)
 	}
@@ -110,6 +125,7 @@ func (uc *UpdateTerminalUseCase) UpdateTerminal(id string, req *dtos.UpdateTermi
 		Name:         item.Name,
 		DeviceTypeID: item.DeviceTypeID,
 		AiProvider:   item.AiProvider,
+		Timezone:     item.Timezone,
 		CreatedAt:    item.CreatedAt,
 		UpdatedAt:    item.UpdatedAt,
 	}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"sensio/domain/common/dtos"
 	"sensio/domain/common/utils"
@@ -24,7 +25,7 @@ func NewTuyaCommandSwitchController(useCase usecases.TuyaCommandSwitchUseCase) *
 
 // SendSwitchCommand handles the request to send switch commands to a device
 // @Summary      Send Switch Command
-// @Description  Sends a standard switch command (e.g., toggle power) to a specific Tuya device.
+// @Description  Sends a standard switch command (e.g., toggle power) to a specific Tuya device. The code and value are validated and coerced against the device specification.
 // @Tags 01. Tuya
 // @Accept       json
 // @Produce      json
@@ -56,6 +57,15 @@ func (ctrl *TuyaCommandSwitchController) SendSwitchCommand(c *gin.Context) {
 	commands := []tuya_dtos.TuyaCommandDTO{req}
 	success, err := ctrl.useCase.SendSwitchCommand(accessToken, deviceID, commands)
 	if err != nil {
+		var valErr *utils.ValidationError
+		if errors.As(err, &valErr) {
+			c.JSON(http.StatusBadRequest, dtos.StandardResponse{
+				Status:  false,
+				Message: valErr.Message,
+				Details: valErr.Details,
+			})
+			return
+		}
 		utils.LogError("TuyaCommandSwitchController.SendSwitchCommand: %v", err)
 		c.JSON(http.StatusInternalServerError, dtos.StandardResponse{
 			Status:  false,
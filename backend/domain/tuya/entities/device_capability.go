@@ -0,0 +1,271 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Tuya function types as returned by the device specification endpoint
+const (
+	FunctionTypeBoolean = "Boolean"
+	FunctionTypeInteger = "Integer"
+	FunctionTypeEnum    = "Enum"
+	FunctionTypeString  = "String"
+	FunctionTypeJSON    = "Json"
+	FunctionTypeRaw     = "Raw"
+	FunctionTypeBitmap  = "Bitmap"
+)
+
+// DeviceCapability is a single function of a device parsed from its specification Values JSON
+type DeviceCapability struct {
+	Code   string   `json:"code"`
+	Type   string   `json:"type"`
+	Min    *int64   `json:"min,omitempty"`
+	Max    *int64   `json:"max,omitempty"`
+	Scale  int      `json:"scale,omitempty"`
+	Step   int64    `json:"step,omitempty"`
+	Unit   string   `json:"unit,omitempty"`
+	Range  []string `json:"range,omitempty"`
+	MaxLen int      `json:"maxlen,omitempty"`
+}
+
+// DeviceCapabilities are the commandable functions of a device, keyed by code
+type DeviceCapabilities struct {
+	DeviceID  string                      `json:"device_id"`
+	ProductID string                      `json:"product_id,omitempty"`
+	Category  string                      `json:"category"`
+	Functions map[string]DeviceCapability `json:"functions"`
+	ReadOnly  []string                    `json:"read_only,omitempty"` // Status codes the device reports but does not accept
+	DPIDs     map[string]int              `json:"dp_ids,omitempty"`    // Data point id of every function and status code
+}
+
+// capabilityValues mirrors the Values JSON of a Tuya function. Tuya sends numbers for
+// Integer functions but older products report them as strings, hence json.Number.
+type capabilityValues struct {
+	Min    json.Number `json:"min"`
+	Max    json.Number `json:"max"`
+	Scale  json.Number `json:"scale"`
+	Step   json.Number `json:"step"`
+	Unit   string      `json:"unit"`
+	Range  []string    `json:"range"`
+	MaxLen json.Number `json:"maxlen"`
+}
+
+// ParseDeviceCapabilities builds the capabilities of a device from its specification.
+// Functions whose Values cannot be parsed are kept with their type only so that the code is
+// still known but its value is not range checked.
+func ParseDeviceCapabilities(deviceID string, spec TuyaDeviceSpecification) *DeviceCapabilities {
+	caps := &DeviceCapabilities{
+		DeviceID:  deviceID,
+		Category:  spec.Category,
+		Functions: make(map[string]DeviceCapability, len(spec.Functions)),
+	}
+
+	for _, fn := range spec.Functions {
+		capability := DeviceCapability{Code: fn.Code, Type: fn.Type}
+
+		var values capabilityValues
+		if fn.Values != "" && json.Unmarshal([]byte(fn.Values), &values) == nil {
+			switch fn.Type {
+			case FunctionTypeInteger:
+				if v, err := values.Min.Int64(); err == nil {
+					capability.Min = &v
+				}
+				if v, err := values.Max.Int64(); err == nil {
+					capability.Max = &v
+				}
+				if v, err := values.Scale.Int64(); err == nil {
+					capability.Scale = int(v)
+				}
+				if v, err := values.Step.Int64(); err == nil {
+					capability.Step = v
+				}
+				capability.Unit = values.Unit
+			case FunctionTypeEnum:
+				capability.Range = values.Range
+			case FunctionTypeString, FunctionTypeJSON:
+				if v, err := values.MaxLen.Int64(); err == nil {
+					capability.MaxLen = int(v)
+				}
+			}
+		}
+		caps.Functions[fn.Code] = capability
+	}
+	for _, st := range spec.Status {
+		if _, ok := caps.Functions[st.Code]; !ok {
+			caps.ReadOnly = append(caps.ReadOnly, st.Code)
+		}
+	}
+	for _, fn := range append(append([]TuyaDeviceFunction{}, spec.Functions...), spec.Status...) {
+		if fn.DPID > 0 {
+			if caps.DPIDs == nil {
+				caps.DPIDs = make(map[string]int)
+			}
+			caps.DPIDs[fn.Code] = fn.DPID
+		}
+	}
+	return caps
+}
+
+// Lookup returns the function for a command code. Tuya names multi-gang switches either
+// switch_1 or switch1 depending on the product, so both spellings resolve to the same function.
+func (c *DeviceCapabilities) Lookup(code string) (DeviceCapability, bool) {
+	if fn, ok := c.Functions[code]; ok {
+		return fn, true
+	}
+	if strings.HasPrefix(code, "switch_") {
+		fn, ok := c.Functions[strings.Replace(code, "_", "", 1)]
+		return fn, ok
+	}
+	if strings.HasPrefix(code, "switch") && len(code) > len("switch") {
+		fn, ok := c.Functions["switch_"+strings.TrimPrefix(code, "switch")]
+		return fn, ok
+	}
+	return DeviceCapability{}, false
+}
+
+// IsReadOnly reports whether the device only reports the code as a status
+func (c *DeviceCapabilities) IsReadOnly(code string) bool {
+	for _, ro := range c.ReadOnly {
+		if ro == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Coerce converts a command value to the type the function expects and checks it against the
+// specification. It returns the coerced value, or a message describing why the value is invalid.
+func (c DeviceCapability) Coerce(value interface{}) (interface{}, string) {
+	switch c.Type {
+	case FunctionTypeBoolean:
+		return c.coerceBoolean(value)
+	case FunctionTypeInteger:
+		return c.coerceInteger(value)
+	case FunctionTypeEnum:
+		return c.coerceEnum(value)
+	case FunctionTypeString, FunctionTypeJSON:
+		return c.coerceString(value)
+	default:
+		// Raw and Bitmap payloads are device specific and sent as is
+		return value, ""
+	}
+}
+
+func (c DeviceCapability) coerceBoolean(value interface{}) (interface{}, string) {
+	switch v := value.(type) {
+	case bool:
+		return v, ""
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1", "on":
+			return true, ""
+		case "false", "0", "off":
+			return false, ""
+		}
+	case float64:
+		if v == 0 || v == 1 {
+			return v == 1, ""
+		}
+	case int:
+		if v == 0 || v == 1 {
+			return v == 1, ""
+		}
+	}
+	return nil, fmt.Sprintf("value for '%s' must be a boolean", c.Code)
+}
+
+func (c DeviceCapability) coerceInteger(value interface{}) (interface{}, string) {
+	var n float64
+	switch v := value.(type) {
+	case float64:
+		n = v
+	case float32:
+		n = float64(v)
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Sprintf("value for '%s' must be an integer", c.Code)
+		}
+		n = f
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Sprintf("value for '%s' must be an integer", c.Code)
+		}
+		n = f
+	default:
+		return nil, fmt.Sprintf("value for '%s' must be an integer", c.Code)
+	}
+
+	if n != math.Trunc(n) {
+		return nil, fmt.Sprintf("value for '%s' must be an integer", c.Code)
+	}
+	i := int64(n)
+
+	if c.Min != nil && c.Max != nil && (i < *c.Min || i > *c.Max) {
+		return nil, fmt.Sprintf("value for '%s' must be between %d and %d", c.Code, *c.Min, *c.Max)
+	}
+	if c.Min != nil && i < *c.Min {
+		return nil, fmt.Sprintf("value for '%s' must be at least %d", c.Code, *c.Min)
+	}
+	if c.Max != nil && i > *c.Max {
+		return nil, fmt.Sprintf("value for '%s' must be at most %d", c.Code, *c.Max)
+	}
+	if c.Step > 1 {
+		base := int64(0)
+		if c.Min != nil {
+			base = *c.Min
+		}
+		if (i-base)%c.Step != 0 {
+			return nil, fmt.Sprintf("value for '%s' must be a multiple of %d", c.Code, c.Step)
+		}
+	}
+	return i, ""
+}
+
+func (c DeviceCapability) coerceEnum(value interface{}) (interface{}, string) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Sprintf("value for '%s' must be one of: %s", c.Code, strings.Join(c.Range, ", "))
+	}
+	if len(c.Range) == 0 {
+		return s, ""
+	}
+	for _, allowed := range c.Range {
+		if strings.EqualFold(allowed, strings.TrimSpace(s)) {
+			return allowed, ""
+		}
+	}
+	return nil, fmt.Sprintf("value for '%s' must be one of: %s", c.Code, strings.Join(c.Range, ", "))
+}
+
+func (c DeviceCapability) coerceString(value interface{}) (interface{}, string) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case map[string]interface{}, []interface{}:
+		if c.Type != FunctionTypeJSON {
+			return nil, fmt.Sprintf("value for '%s' must be a string", c.Code)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Sprintf("value for '%s' must be valid JSON", c.Code)
+		}
+		s = string(b)
+	default:
+		return nil, fmt.Sprintf("value for '%s' must be a string", c.Code)
+	}
+	if c.MaxLen > 0 && len(s) > c.MaxLen {
+		return nil, fmt.Sprintf("value for '%s' must not exceed %d characters", c.Code, c.MaxLen)
+	}
+	return s, ""
+}
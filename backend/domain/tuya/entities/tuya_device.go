@@ -64,6 +64,7 @@ type TuyaDeviceStatus struct {
 // TuyaDeviceFunction represents a device function
 type TuyaDeviceFunction struct {
 	Code   string `json:"code"`
+	DPID   int    `json:"dp_id"` // Data point id used by the LAN protocol
 	Type   string `json:"type"`
 	Values string `json:"values"` // Changed from map to string because spec API returns JSON string
 }
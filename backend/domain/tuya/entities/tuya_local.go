@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// TuyaLocalEndpoint is where a device answers the LAN protocol, as announced by its UDP broadcast
+type TuyaLocalEndpoint struct {
+	DeviceID   string    `json:"gwId"`
+	IP         string    `json:"ip"`
+	Version    string    `json:"version"`
+	ProductKey string    `json:"productKey"`
+	SeenAt     time.Time `json:"-"`
+}
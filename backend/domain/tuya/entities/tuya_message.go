@@ -0,0 +1,44 @@
+package entities
+
+// Protocols of the Tuya message service payload
+const (
+	TuyaMessageProtocolStatus = 4  // Device status report
+	TuyaMessageProtocolEvent  = 20 // Device event (online, offline, bind, ...)
+)
+
+// TuyaMessage is one message received from the Tuya message service (Pulsar websocket consumer)
+type TuyaMessage struct {
+	MessageID  string            `json:"messageId"`
+	Payload    string            `json:"payload"` // Base64 encoded TuyaMessagePayload
+	Properties map[string]string `json:"properties"`
+}
+
+// TuyaMessagePayload is the signed envelope around the encrypted message data
+type TuyaMessagePayload struct {
+	Data     string `json:"data"`
+	Protocol int    `json:"protocol"`
+	PV       string `json:"pv"`
+	Sign     string `json:"sign"`
+	T        int64  `json:"t"`
+}
+
+// TuyaStatusReport is the decrypted data of a status report (protocol 4)
+type TuyaStatusReport struct {
+	DevID      string                 `json:"devId"`
+	ProductKey string                 `json:"productKey"`
+	Status     []TuyaReportedDPStatus `json:"status"`
+}
+
+// TuyaReportedDPStatus is a single data point value reported by a device
+type TuyaReportedDPStatus struct {
+	Code  string      `json:"code"`
+	Value interface{} `json:"value"`
+	T     int64       `json:"t"`
+}
+
+// TuyaDeviceEvent is the decrypted data of a device event (protocol 20)
+type TuyaDeviceEvent struct {
+	DevID   string `json:"devId"`
+	BizCode string `json:"bizCode"`
+	Ts      int64  `json:"ts"`
+}
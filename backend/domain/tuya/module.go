@@ -3,12 +3,15 @@ package tuya
 import (
 	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/middlewares"
+	"sensio/domain/common/utils"
 	device_repositories "sensio/domain/terminal/device/repositories"
+	device_status_repositories "sensio/domain/terminal/device_status/repositories"
 	terminal_repositories "sensio/domain/terminal/terminal/repositories"
 	"sensio/domain/tuya/controllers"
 	"sensio/domain/tuya/routes"
 	"sensio/domain/tuya/services"
 	"sensio/domain/tuya/usecases"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,10 +30,15 @@ type TuyaModule struct {
 	GetAllDevicesUseCase usecases.TuyaGetAllDevicesUseCase
 	GetDeviceByIDUseCase *usecases.TuyaGetDeviceByIDUseCase
 	DeviceControlUseCase usecases.TuyaDeviceControlExecutor
+	CapabilityRegistry   *usecases.DeviceCapabilityRegistry
+	DeviceStateUseCase   usecases.DeviceStateUseCase
+	MessageConsumer      *usecases.TuyaMessageConsumer // nil unless TUYA_MQ_ENABLED is "true"
+	LocalControl         *usecases.TuyaLocalControl    // nil unless TUYA_LOCAL_ENABLED is "true"
+	LocalDiscovery       *services.TuyaLocalDiscovery  // nil unless TUYA_LOCAL_ENABLED is "true"
 }
 
 // NewTuyaModule initializes the Tuya module
-func NewTuyaModule(badger *infrastructure.BadgerService, vectorSvc *infrastructure.VectorService, deviceRepo *device_repositories.DeviceRepository, terminalRepo *terminal_repositories.TerminalRepository) *TuyaModule {
+func NewTuyaModule(badger *infrastructure.BadgerService, vectorSvc infrastructure.VectorStore, deviceRepo *device_repositories.DeviceRepository, terminalRepo *terminal_repositories.TerminalRepository, mqttSvc usecases.MessagePublisher) *TuyaModule {
 	// Services
 	tuyaAuthService := services.NewTuyaAuthService()
 	tuyaDeviceService := services.NewTuyaDeviceService()
@@ -39,16 +47,52 @@ func NewTuyaModule(badger *infrastructure.BadgerService, vectorSvc *infrastructu
 	tuyaAuthUseCase := usecases.NewTuyaAuthUseCase(tuyaAuthService)
 	deviceStateUseCase := usecases.NewDeviceStateUseCase(badger)
 
-	tuyaGetAllDevicesUseCase := usecases.NewTuyaGetAllDevicesUseCase(tuyaDeviceService, deviceStateUseCase, badger, vectorSvc, deviceRepo, terminalRepo)
+	specCacheTTL, err := time.ParseDuration(utils.GetConfig().TuyaSpecCacheTTL)
+	if err != nil || specCacheTTL <= 0 {
+		specCacheTTL = 24 * time.Hour
+	}
+	capabilityRegistry := usecases.NewDeviceCapabilityRegistry(tuyaDeviceService, badger, specCacheTTL)
+
+	tuyaGetAllDevicesUseCase := usecases.NewTuyaGetAllDevicesUseCase(tuyaDeviceService, deviceStateUseCase, badger, vectorSvc, deviceRepo, terminalRepo, capabilityRegistry)
 	tuyaGetDeviceByIDUseCase := usecases.NewTuyaGetDeviceByIDUseCase(tuyaDeviceService, deviceStateUseCase)
-	tuyaCommandSwitchUseCase := usecases.NewTuyaCommandSwitchUseCase(tuyaDeviceService, deviceStateUseCase)
+	tuyaCommandSwitchUseCase := usecases.NewTuyaCommandSwitchUseCase(tuyaDeviceService, deviceStateUseCase, capabilityRegistry)
 	tuyaSendIRCommandUseCase := usecases.NewTuyaSendIRCommandUseCase(tuyaDeviceService, deviceStateUseCase)
 
+	// LAN control keeps Wi-Fi devices controllable when the site is offline
+	var localControl *usecases.TuyaLocalControl
+	var localDiscovery *services.TuyaLocalDiscovery
+	var localSender usecases.LocalSwitchSender
+	if cfg := utils.GetConfig(); cfg.TuyaLocalEnabled {
+		timeout, err := time.ParseDuration(cfg.TuyaLocalTimeout)
+		if err != nil {
+			timeout = 3 * time.Second
+		}
+		discoveryTTL, err := time.ParseDuration(cfg.TuyaLocalDiscoveryTTL)
+		if err != nil {
+			discoveryTTL = 2 * time.Minute
+		}
+		localDiscovery = services.NewTuyaLocalDiscovery(discoveryTTL)
+		localControl = usecases.NewTuyaLocalControl(deviceRepo, localDiscovery, services.NewTuyaLocalService(timeout), capabilityRegistry, deviceStateUseCase, badger)
+		localSender = localControl
+	}
+
 	// Bridge for shared executor
-	tuyaDeviceControlBridge := usecases.NewTuyaDeviceControlBridge(tuyaCommandSwitchUseCase, tuyaSendIRCommandUseCase, badger)
+	tuyaDeviceControlBridge := usecases.NewTuyaDeviceControlBridge(tuyaCommandSwitchUseCase, tuyaSendIRCommandUseCase, localSender, badger)
 
 	tuyaSensorUseCase := usecases.NewTuyaSensorUseCase(tuyaGetDeviceByIDUseCase)
 
+	// Real-time statuses pushed by the Tuya message service
+	var messageConsumer *usecases.TuyaMessageConsumer
+	if cfg := utils.GetConfig(); cfg.TuyaMQEnabled {
+		reconnectDelay, err := time.ParseDuration(cfg.TuyaMQReconnectDelay)
+		if err != nil {
+			reconnectDelay = 5 * time.Second
+		}
+		transport := services.NewTuyaPulsarTransport(cfg.TuyaMQURL, cfg.TuyaClientID, cfg.TuyaClientSecret, cfg.TuyaMQEnv)
+		statusRepo := device_status_repositories.NewDeviceStatusRepository(badger)
+		messageConsumer = usecases.NewTuyaMessageConsumer(transport, cfg.TuyaClientSecret, deviceStateUseCase, statusRepo, deviceRepo, terminalRepo, mqttSvc, badger, cfg.ApplicationEnvironment, reconnectDelay)
+	}
+
 	// Controllers
 	return &TuyaModule{
 		AuthController:          controllers.NewTuyaAuthController(tuyaAuthUseCase),
@@ -62,6 +106,11 @@ func NewTuyaModule(badger *infrastructure.BadgerService, vectorSvc *infrastructu
 		GetAllDevicesUseCase: tuyaGetAllDevicesUseCase,
 		GetDeviceByIDUseCase: tuyaGetDeviceByIDUseCase,
 		DeviceControlUseCase: tuyaDeviceControlBridge,
+		CapabilityRegistry:   capabilityRegistry,
+		DeviceStateUseCase:   deviceStateUseCase,
+		MessageConsumer:      messageConsumer,
+		LocalControl:         localControl,
+		LocalDiscovery:       localDiscovery,
 	}
 }
 
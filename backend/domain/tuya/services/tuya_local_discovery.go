@@ -0,0 +1,132 @@
+package services
+
+import (
+	"encoding/json"
+	"net"
+	"sensio/domain/common/utils"
+	"sensio/domain/tuya/entities"
+	tuya_utils "sensio/domain/tuya/utils"
+	"sync"
+	"time"
+)
+
+// Broadcast ports: 6666 carries plain 3.1 announcements, 6667 encrypted 3.3/3.4, 7000 3.5
+var tuyaDiscoveryPorts = []string{"6666", "6667", "7000"}
+
+// TuyaLocalDiscovery listens to the UDP broadcasts devices send every few seconds and remembers
+// the IP and protocol version of each one. A device is considered reachable while its last
+// broadcast is younger than the TTL.
+type TuyaLocalDiscovery struct {
+	ttl   time.Duration
+	ports []string
+	now   func() time.Time
+
+	mu        sync.RWMutex
+	endpoints map[string]entities.TuyaLocalEndpoint
+	conns     []net.PacketConn
+	wg        sync.WaitGroup
+	stopOnce  sync.Once
+}
+
+// NewTuyaLocalDiscovery initializes a new instance of TuyaLocalDiscovery.
+//
+// param ttl How long a broadcast keeps a device reachable.
+// return *TuyaLocalDiscovery A pointer to the initialized discovery listener.
+func NewTuyaLocalDiscovery(ttl time.Duration) *TuyaLocalDiscovery {
+	if ttl <= 0 {
+		ttl = 2 * time.Minute
+	}
+	return &TuyaLocalDiscovery{
+		ttl:       ttl,
+		ports:     tuyaDiscoveryPorts,
+		now:       time.Now,
+		endpoints: make(map[string]entities.TuyaLocalEndpoint),
+	}
+}
+
+// Start listens on the broadcast ports in the background until Stop is called.
+// Ports that cannot be bound are logged and skipped.
+func (d *TuyaLocalDiscovery) Start() {
+	for _, port := range d.ports {
+		conn, err := net.ListenPacket("udp4", ":"+port)
+		if err != nil {
+			utils.LogWarn("TuyaLocalDiscovery: cannot listen on udp/%s: %v", port, err)
+			continue
+		}
+		d.conns = append(d.conns, conn)
+		d.wg.Add(1)
+		go d.listen(conn)
+	}
+	utils.LogInfo("TuyaLocalDiscovery: started (ttl=%s)", d.ttl)
+}
+
+// Stop closes the listeners and waits for them to exit
+func (d *TuyaLocalDiscovery) Stop() {
+	d.stopOnce.Do(func() {
+		for _, conn := range d.conns {
+			_ = conn.Close()
+		}
+		d.wg.Wait()
+	})
+}
+
+// Lookup returns the endpoint of a device that broadcast recently
+func (d *TuyaLocalDiscovery) Lookup(deviceID string) (entities.TuyaLocalEndpoint, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	endpoint, ok := d.endpoints[deviceID]
+	if !ok || d.now().Sub(endpoint.SeenAt) > d.ttl {
+		return entities.TuyaLocalEndpoint{}, false
+	}
+	return endpoint, true
+}
+
+// HandleBroadcast records the device announced by one UDP datagram
+func (d *TuyaLocalDiscovery) HandleBroadcast(packet []byte) {
+	endpoint, ok := decodeBroadcast(packet)
+	if !ok || endpoint.DeviceID == "" || endpoint.IP == "" {
+		return
+	}
+	endpoint.SeenAt = d.now()
+
+	d.mu.Lock()
+	_, known := d.endpoints[endpoint.DeviceID]
+	d.endpoints[endpoint.DeviceID] = endpoint
+	d.mu.Unlock()
+	if !known {
+		utils.LogDebug("TuyaLocalDiscovery: found %s at %s (v%s)", endpoint.DeviceID, endpoint.IP, endpoint.Version)
+	}
+}
+
+func (d *TuyaLocalDiscovery) listen(conn net.PacketConn) {
+	defer d.wg.Done()
+	buf := make([]byte, 4096)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		d.HandleBroadcast(append([]byte{}, buf[:n]...))
+	}
+}
+
+// decodeBroadcast tries the codecs of every protocol generation on a datagram
+func decodeBroadcast(packet []byte) (entities.TuyaLocalEndpoint, bool) {
+	codecs := []tuya_utils.LocalCodec{
+		{Version: tuya_utils.LocalVersion33, Key: tuya_utils.LocalUDPKey},
+		{Version: tuya_utils.LocalVersion34, Key: tuya_utils.LocalUDPKey},
+		{Version: tuya_utils.LocalVersion35, Key: tuya_utils.LocalUDPKey},
+		{Version: tuya_utils.LocalVersion31},
+	}
+	for _, codec := range codecs {
+		msg, _, err := codec.Decode(packet)
+		if err != nil || msg == nil {
+			continue
+		}
+		var endpoint entities.TuyaLocalEndpoint
+		if json.Unmarshal(msg.Payload, &endpoint) == nil {
+			return endpoint, true
+		}
+	}
+	return entities.TuyaLocalEndpoint{}, false
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sensio/domain/common/utils"
+	"sensio/domain/tuya/entities"
+	tuya_utils "sensio/domain/tuya/utils"
+	"strconv"
+	"time"
+)
+
+// tuyaLocalPort is the TCP port every Tuya Wi-Fi device listens on
+const tuyaLocalPort = "6668"
+
+// TuyaLocalService controls devices over the Tuya LAN protocol (3.3, 3.4 and 3.5).
+// Each call opens its own encrypted TCP session: devices accept very few concurrent
+// connections, so none is held open between commands.
+type TuyaLocalService struct {
+	timeout time.Duration
+	port    string
+}
+
+// NewTuyaLocalService initializes a new instance of TuyaLocalService.
+//
+// param timeout The deadline for connecting and for each request/response exchange.
+// return *TuyaLocalService A pointer to the initialized service.
+func NewTuyaLocalService(timeout time.Duration) *TuyaLocalService {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &TuyaLocalService{timeout: timeout, port: tuyaLocalPort}
+}
+
+// SetDPs writes data point values on the device.
+//
+// param endpoint The discovered IP and protocol version of the device.
+// param localKey The device local key.
+// param dps The values to set, keyed by data point id.
+// return error An error if the device is unreachable or rejects the command.
+func (s *TuyaLocalService) SetDPs(endpoint entities.TuyaLocalEndpoint, localKey string, dps map[string]interface{}) error {
+	session, err := s.open(endpoint, localKey)
+	if err != nil {
+		return err
+	}
+	defer session.close()
+
+	cmd := tuya_utils.LocalCmdControlNew
+	var payload interface{} = map[string]interface{}{
+		"protocol": 5,
+		"t":        time.Now().Unix(),
+		"data":     map[string]interface{}{"dps": dps},
+	}
+	if endpoint.Version == tuya_utils.LocalVersion33 {
+		cmd = tuya_utils.LocalCmdControl
+		payload = map[string]interface{}{
+			"devId": endpoint.DeviceID,
+			"uid":   endpoint.DeviceID,
+			"t":     strconv.FormatInt(time.Now().Unix(), 10),
+			"dps":   dps,
+		}
+	}
+
+	reply, err := session.request(cmd, payload, cmd)
+	if err != nil {
+		return err
+	}
+	if reply.RetCode != 0 {
+		return fmt.Errorf("device rejected the command (code %d): %s", reply.RetCode, string(reply.Payload))
+	}
+	return nil
+}
+
+// GetDPs reads every data point value of the device.
+//
+// param endpoint The discovered IP and protocol version of the device.
+// param localKey The device local key.
+// return map[string]interface{} The values keyed by data point id.
+// return error An error if the device is unreachable or the reply cannot be decoded.
+func (s *TuyaLocalService) GetDPs(endpoint entities.TuyaLocalEndpoint, localKey string) (map[string]interface{}, error) {
+	session, err := s.open(endpoint, localKey)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+
+	cmd := tuya_utils.LocalCmdDPQueryNew
+	var payload interface{} = map[string]interface{}{}
+	if endpoint.Version == tuya_utils.LocalVersion33 {
+		cmd = tuya_utils.LocalCmdDPQuery
+		now := strconv.FormatInt(time.Now().Unix(), 10)
+		payload = map[string]interface{}{
+			"gwId":  endpoint.DeviceID,
+			"devId": endpoint.DeviceID,
+			"uid":   endpoint.DeviceID,
+			"t":     now,
+			"dps":   map[string]interface{}{},
+		}
+	}
+
+	reply, err := session.request(cmd, payload, cmd, tuya_utils.LocalCmdStatus)
+	if err != nil {
+		return nil, err
+	}
+	return parseLocalDPs(reply.Payload)
+}
+
+// open connects to the device and negotiates a session key on 3.4/3.5
+func (s *TuyaLocalService) open(endpoint entities.TuyaLocalEndpoint, localKey string) (*tuyaLocalSession, error) {
+	switch endpoint.Version {
+	case tuya_utils.LocalVersion33, tuya_utils.LocalVersion34, tuya_utils.LocalVersion35:
+	default:
+		return nil, fmt.Errorf("unsupported protocol version %q", endpoint.Version)
+	}
+	if len(localKey) != 16 {
+		return nil, fmt.Errorf("invalid local key")
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(endpoint.IP, s.port), s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	session := &tuyaLocalSession{
+		conn:     conn,
+		timeout:  s.timeout,
+		localKey: []byte(localKey),
+		codec:    tuya_utils.LocalCodec{Version: endpoint.Version, Key: []byte(localKey)},
+	}
+	if endpoint.Version != tuya_utils.LocalVersion33 {
+		if err := session.negotiate(); err != nil {
+			session.close()
+			return nil, fmt.Errorf("session key negotiation failed: %w", err)
+		}
+	}
+	return session, nil
+}
+
+// tuyaLocalSession is one TCP connection to a device
+type tuyaLocalSession struct {
+	conn     net.Conn
+	timeout  time.Duration
+	localKey []byte
+	codec    tuya_utils.LocalCodec
+	seq      uint32
+	buf      []byte
+}
+
+// negotiate exchanges nonces with the device and switches the codec to the session key
+func (s *tuyaLocalSession) negotiate() error {
+	localNonce := make([]byte, 16)
+	if _, err := rand.Read(localNonce); err != nil {
+		return err
+	}
+	reply, err := s.exchange(tuya_utils.LocalCmdSessKeyNegStart, localNonce, tuya_utils.LocalCmdSessKeyNegResp)
+	if err != nil {
+		return err
+	}
+	remoteNonce, proof, err := tuya_utils.NegotiationNonces(reply.Payload)
+	if err != nil {
+		return err
+	}
+	if string(proof) != string(tuya_utils.HMACSHA256(s.localKey, localNonce)) {
+		return errors.New("device proof does not match the local key")
+	}
+	if err := s.send(tuya_utils.LocalCmdSessKeyNegFinish, tuya_utils.HMACSHA256(s.localKey, remoteNonce)); err != nil {
+		return err
+	}
+
+	sessionKey, err := tuya_utils.LocalSessionKey(s.codec.Version, s.localKey, localNonce, remoteNonce)
+	if err != nil {
+		return err
+	}
+	s.codec.Key = sessionKey
+	return nil
+}
+
+// request sends a JSON payload and waits for a reply with one of the expected commands
+func (s *tuyaLocalSession) request(cmd uint32, payload interface{}, expect ...uint32) (*tuya_utils.LocalMessage, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return s.exchange(cmd, body, expect...)
+}
+
+func (s *tuyaLocalSession) exchange(cmd uint32, body []byte, expect ...uint32) (*tuya_utils.LocalMessage, error) {
+	if err := s.send(cmd, body); err != nil {
+		return nil, err
+	}
+	for {
+		msg, err := s.receive()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range expect {
+			// An empty STATUS frame is only the acknowledgement of a query on some firmwares
+			if msg.Cmd == c && (c != tuya_utils.LocalCmdStatus || len(msg.Payload) > 0) {
+				return msg, nil
+			}
+		}
+		utils.LogDebug("TuyaLocalService: skipping frame cmd=%d while waiting for %v", msg.Cmd, expect)
+	}
+}
+
+func (s *tuyaLocalSession) send(cmd uint32, body []byte) error {
+	s.seq++
+	frame, err := s.codec.Encode(s.seq, cmd, body)
+	if err != nil {
+		return err
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	_, err = s.conn.Write(frame)
+	return err
+}
+
+func (s *tuyaLocalSession) receive() (*tuya_utils.LocalMessage, error) {
+	_ = s.conn.SetReadDeadline(time.Now().Add(s.timeout))
+	chunk := make([]byte, 4096)
+	for {
+		if len(s.buf) > 0 {
+			msg, n, err := s.codec.Decode(s.buf)
+			if !errors.Is(err, tuya_utils.ErrIncompleteFrame) {
+				s.buf = s.buf[n:]
+				return msg, err
+			}
+		}
+		n, err := s.conn.Read(chunk)
+		if n > 0 {
+			s.buf = append(s.buf, chunk[:n]...)
+			continue
+		}
+		if err == io.EOF {
+			return nil, errors.New("device closed the connection")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *tuyaLocalSession) close() {
+	_ = s.conn.Close()
+}
+
+// parseLocalDPs extracts the data points of a 3.3 ({"dps":...}) or 3.4+ ({"data":{"dps":...}}) reply
+func parseLocalDPs(payload []byte) (map[string]interface{}, error) {
+	var reply struct {
+		DPS  map[string]interface{} `json:"dps"`
+		Data struct {
+			DPS map[string]interface{} `json:"dps"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &reply); err != nil {
+		return nil, fmt.Errorf("invalid data point reply: %s", string(payload))
+	}
+	if reply.DPS != nil {
+		return reply.DPS, nil
+	}
+	if reply.Data.DPS != nil {
+		return reply.Data.DPS, nil
+	}
+	return map[string]interface{}{}, nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sensio/domain/tuya/entities"
+	tuya_utils "sensio/domain/tuya/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLocalKey = "0123456789abcdef"
+
+// fakeLocalDevice answers the LAN protocol like a Wi-Fi plug
+type fakeLocalDevice struct {
+	version string
+	mu      sync.Mutex
+	dps     map[string]interface{}
+}
+
+func (d *fakeLocalDevice) serve(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go d.handle(conn)
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	return port
+}
+
+func (d *fakeLocalDevice) handle(conn net.Conn) {
+	defer conn.Close()
+	codec := tuya_utils.LocalCodec{Version: d.version, Key: []byte(testLocalKey)}
+	remoteNonce := []byte("fedcba9876543210")
+	var localNonce []byte
+	var buf []byte
+	chunk := make([]byte, 4096)
+
+	reply := func(seq, cmd uint32, payload []byte) {
+		frame, _ := codec.Encode(seq, cmd, payload)
+		_, _ = conn.Write(frame)
+	}
+	for {
+		msg, n, err := codec.Decode(buf)
+		if errors.Is(err, tuya_utils.ErrIncompleteFrame) {
+			read, err := conn.Read(chunk)
+			if err != nil {
+				return
+			}
+			buf = append(buf, chunk[:read]...)
+			continue
+		}
+		buf = buf[n:]
+		if err != nil {
+			return
+		}
+
+		switch msg.Cmd {
+		case tuya_utils.LocalCmdSessKeyNegStart:
+			localNonce = msg.Payload
+			reply(msg.Seq, tuya_utils.LocalCmdSessKeyNegResp, append(append([]byte{}, remoteNonce...), tuya_utils.HMACSHA256([]byte(testLocalKey), localNonce)...))
+		case tuya_utils.LocalCmdSessKeyNegFinish:
+			if string(msg.Payload) != string(tuya_utils.HMACSHA256([]byte(testLocalKey), remoteNonce)) {
+				return
+			}
+			codec.Key, _ = tuya_utils.LocalSessionKey(d.version, []byte(testLocalKey), localNonce, remoteNonce)
+		case tuya_utils.LocalCmdControl, tuya_utils.LocalCmdControlNew:
+			var body struct {
+				DPS  map[string]interface{} `json:"dps"`
+				Data struct {
+					DPS map[string]interface{} `json:"dps"`
+				} `json:"data"`
+			}
+			_ = json.Unmarshal(msg.Payload, &body)
+			changed := body.DPS
+			if changed == nil {
+				changed = body.Data.DPS
+			}
+			d.mu.Lock()
+			for k, v := range changed {
+				d.dps[k] = v
+			}
+			d.mu.Unlock()
+			status, _ := json.Marshal(map[string]interface{}{"dps": changed})
+			reply(0, tuya_utils.LocalCmdStatus, status)
+			reply(msg.Seq, msg.Cmd, nil)
+		case tuya_utils.LocalCmdDPQuery, tuya_utils.LocalCmdDPQueryNew:
+			d.mu.Lock()
+			var out []byte
+			if d.version == tuya_utils.LocalVersion33 {
+				out, _ = json.Marshal(map[string]interface{}{"devId": "plug", "dps": d.dps})
+			} else {
+				out, _ = json.Marshal(map[string]interface{}{"protocol": 4, "data": map[string]interface{}{"dps": d.dps}})
+			}
+			d.mu.Unlock()
+			reply(msg.Seq, msg.Cmd, out)
+		}
+	}
+}
+
+func TestTuyaLocalService_SetAndGetDPs(t *testing.T) {
+	for _, version := range []string{tuya_utils.LocalVersion33, tuya_utils.LocalVersion34, tuya_utils.LocalVersion35} {
+		t.Run(version, func(t *testing.T) {
+			device := &fakeLocalDevice{version: version, dps: map[string]interface{}{"1": false, "20": "white"}}
+			svc := NewTuyaLocalService(time.Second)
+			svc.port = device.serve(t)
+			endpoint := entities.TuyaLocalEndpoint{DeviceID: "plug", IP: "127.0.0.1", Version: version}
+
+			require.NoError(t, svc.SetDPs(endpoint, testLocalKey, map[string]interface{}{"1": true}))
+
+			dps, err := svc.GetDPs(endpoint, testLocalKey)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"1": true, "20": "white"}, dps)
+
+			err = svc.SetDPs(endpoint, "ffffffffffffffff", map[string]interface{}{"1": false})
+			assert.Error(t, err, "a wrong local key is rejected")
+		})
+	}
+}
+
+func TestTuyaLocalDiscovery_HandleBroadcast(t *testing.T) {
+	discovery := NewTuyaLocalDiscovery(time.Minute)
+	now := time.Now()
+	discovery.now = func() time.Time { return now }
+
+	announce := func(version, id string) {
+		body, _ := json.Marshal(map[string]interface{}{"ip": "192.168.1.20", "gwId": id, "version": version, "productKey": "key"})
+		frame, err := tuya_utils.LocalCodec{Version: version, Key: tuya_utils.LocalUDPKey}.Encode(0, 0x13, body)
+		require.NoError(t, err)
+		discovery.HandleBroadcast(frame)
+	}
+	announce(tuya_utils.LocalVersion33, "plug-33")
+	announce(tuya_utils.LocalVersion34, "plug-34")
+	announce(tuya_utils.LocalVersion35, "plug-35")
+	discovery.HandleBroadcast([]byte("noise"))
+
+	for _, id := range []string{"plug-33", "plug-34", "plug-35"} {
+		endpoint, ok := discovery.Lookup(id)
+		require.True(t, ok, id)
+		assert.Equal(t, "192.168.1.20", endpoint.IP)
+	}
+	endpoint, _ := discovery.Lookup("plug-35")
+	assert.Equal(t, tuya_utils.LocalVersion35, endpoint.Version)
+
+	now = now.Add(2 * time.Minute)
+	_, ok := discovery.Lookup("plug-33")
+	assert.False(t, ok, "devices that stopped broadcasting are no longer reachable")
+}
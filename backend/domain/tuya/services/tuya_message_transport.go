@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sensio/domain/tuya/entities"
+	"sensio/domain/tuya/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TuyaPulsarTransport subscribes to the Tuya message service through the Pulsar websocket API.
+// One connection is one Failover subscription named "{accessID}-sub".
+type TuyaPulsarTransport struct {
+	baseURL   string
+	accessID  string
+	accessKey string
+	env       string
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// NewTuyaPulsarTransport initializes a new instance of TuyaPulsarTransport.
+//
+// param baseURL The regional Pulsar websocket endpoint (e.g. wss://mqe.tuyaus.com:8285/).
+// param accessID The Tuya Client ID.
+// param accessKey The Tuya Client Secret.
+// param env The message channel ("event" or "event-test").
+// return *TuyaPulsarTransport A pointer to the initialized transport.
+func NewTuyaPulsarTransport(baseURL, accessID, accessKey, env string) *TuyaPulsarTransport {
+	return &TuyaPulsarTransport{
+		baseURL:   strings.TrimSuffix(baseURL, "/") + "/",
+		accessID:  accessID,
+		accessKey: accessKey,
+		env:       env,
+	}
+}
+
+// Connect opens the consumer websocket, replacing any previous connection
+func (t *TuyaPulsarTransport) Connect(ctx context.Context) error {
+	topic := fmt.Sprintf("ws/v2/consumer/persistent/%s/out/%s/%s-sub", t.accessID, t.env, t.accessID)
+	url := t.baseURL + topic + "?ackTimeoutMillis=3000&subscriptionType=Failover"
+
+	header := http.Header{}
+	header.Set("Connection", "keep-alive")
+	header.Set("username", t.accessID)
+	header.Set("password", utils.GenerateMessagePassword(t.accessID, t.accessKey))
+
+	dialer := websocket.Dialer{HandshakeTimeout: 30 * time.Second}
+	conn, resp, err := dialer.DialContext(ctx, url, header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to subscribe to Tuya message service (status %d): %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("failed to subscribe to Tuya message service: %w", err)
+	}
+
+	t.mu.Lock()
+	if t.conn != nil {
+		_ = t.conn.Close()
+	}
+	t.conn = conn
+	t.mu.Unlock()
+	return nil
+}
+
+// Receive blocks until the next message arrives or the connection fails
+func (t *TuyaPulsarTransport) Receive(ctx context.Context) (*entities.TuyaMessage, error) {
+	conn := t.current()
+	if conn == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	// Unblock the read when the consumer is stopped
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	var msg entities.TuyaMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message frame: %w", err)
+	}
+	return &msg, nil
+}
+
+// Ack acknowledges a message so Pulsar does not redeliver it
+func (t *TuyaPulsarTransport) Ack(messageID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return fmt.Errorf("not connected")
+	}
+	return t.conn.WriteJSON(map[string]string{"messageId": messageID})
+}
+
+// Close closes the current connection, if any
+func (t *TuyaPulsarTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+func (t *TuyaPulsarTransport) current() *websocket.Conn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn
+}
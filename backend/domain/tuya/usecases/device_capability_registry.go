@@ -0,0 +1,241 @@
+package usecases
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	"sensio/domain/tuya/dtos"
+	"sensio/domain/tuya/entities"
+	tuya_utils "sensio/domain/tuya/utils"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const capabilityCacheKeyPrefix = "device_spec:"
+
+// DeviceSpecificationFetcher retrieves the function specification of a device from Tuya
+type DeviceSpecificationFetcher interface {
+	FetchDeviceSpecification(url string, headers map[string]string) (*entities.TuyaDeviceSpecificationResponse, error)
+}
+
+type cachedCapabilities struct {
+	caps      *entities.DeviceCapabilities
+	expiresAt time.Time
+}
+
+// DeviceCapabilityRegistry caches the parsed function specifications of devices and validates
+// commands against them. Specifications are kept in memory and in Badger, per device and per
+// product once the product of a device is known, so devices of the same model share one fetch.
+//
+// When no specification is available (Tuya unreachable, IR sub-devices, test mode) commands are
+// passed through unchanged: the registry narrows what is sent, it never blocks control on its own.
+type DeviceCapabilityRegistry struct {
+	fetcher DeviceSpecificationFetcher
+	cache   *infrastructure.BadgerService
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu        sync.RWMutex
+	devices   map[string]cachedCapabilities
+	byProduct map[string]cachedCapabilities
+	products  map[string]string // deviceID -> productID
+}
+
+// NewDeviceCapabilityRegistry creates a registry whose entries expire after ttl
+func NewDeviceCapabilityRegistry(fetcher DeviceSpecificationFetcher, cache *infrastructure.BadgerService, ttl time.Duration) *DeviceCapabilityRegistry {
+	return &DeviceCapabilityRegistry{
+		fetcher:   fetcher,
+		cache:     cache,
+		ttl:       ttl,
+		now:       time.Now,
+		devices:   make(map[string]cachedCapabilities),
+		byProduct: make(map[string]cachedCapabilities),
+		products:  make(map[string]string),
+	}
+}
+
+// RememberProduct records the product of a device so its specification can be shared
+func (r *DeviceCapabilityRegistry) RememberProduct(deviceID, productID string) {
+	if deviceID == "" || productID == "" {
+		return
+	}
+	r.mu.Lock()
+	r.products[deviceID] = productID
+	r.mu.Unlock()
+}
+
+// Invalidate drops the cached specification of a device, e.g. after a firmware update
+func (r *DeviceCapabilityRegistry) Invalidate(deviceID string) {
+	r.mu.Lock()
+	delete(r.devices, deviceID)
+	if productID, ok := r.products[deviceID]; ok {
+		delete(r.byProduct, productID)
+	}
+	r.mu.Unlock()
+	_ = r.cache.Delete(capabilityCacheKeyPrefix + deviceID)
+}
+
+// GetCapabilities returns the capabilities of a device, fetching its specification when it is
+// not cached. It returns nil without error when the device has no usable specification.
+func (r *DeviceCapabilityRegistry) GetCapabilities(accessToken, deviceID string) (*entities.DeviceCapabilities, error) {
+	if caps, ok := r.lookup(deviceID); ok {
+		return caps, nil
+	}
+
+	if data, err := r.cache.Get(capabilityCacheKeyPrefix + deviceID); err == nil && data != nil {
+		var caps entities.DeviceCapabilities
+		if err := json.Unmarshal(data, &caps); err == nil {
+			r.store(&caps)
+			return usable(&caps), nil
+		}
+	}
+
+	caps, err := r.fetch(accessToken, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if productID := r.productOf(deviceID); productID != "" {
+		caps.ProductID = productID
+	}
+	r.store(caps)
+	if data, err := json.Marshal(caps); err == nil {
+		_ = r.cache.SetWithTTL(capabilityCacheKeyPrefix+deviceID, data, r.ttl)
+	}
+	return usable(caps), nil
+}
+
+// ValidateCommand validates a single code/value pair. It returns the coerced value, or the
+// field-level problems using the "code" and "value" fields of a status update request.
+func (r *DeviceCapabilityRegistry) ValidateCommand(accessToken, deviceID, code string, value interface{}) (string, interface{}, []utils.ValidationErrorDetail) {
+	caps, err := r.GetCapabilities(accessToken, deviceID)
+	if err != nil {
+		utils.LogWarn("DeviceCapabilityRegistry: no specification for %s, sending %s unvalidated: %v", deviceID, code, err)
+		return code, value, nil
+	}
+	if caps == nil {
+		return code, value, nil
+	}
+	return checkCommand(caps, code, value, "code", "value")
+}
+
+// ValidateCommands validates a command batch and returns it with codes and values normalized
+// to the specification. Problems are reported as a *utils.ValidationError on commands[i].code
+// and commands[i].value.
+func (r *DeviceCapabilityRegistry) ValidateCommands(accessToken, deviceID string, commands []dtos.TuyaCommandDTO) ([]dtos.TuyaCommandDTO, error) {
+	caps, err := r.GetCapabilities(accessToken, deviceID)
+	if err != nil {
+		utils.LogWarn("DeviceCapabilityRegistry: no specification for %s, sending commands unvalidated: %v", deviceID, err)
+		return commands, nil
+	}
+	if caps == nil {
+		return commands, nil
+	}
+
+	coerced := make([]dtos.TuyaCommandDTO, len(commands))
+	var details []utils.ValidationErrorDetail
+	for i, cmd := range commands {
+		prefix := fmt.Sprintf("commands[%d].", i)
+		code, value, problems := checkCommand(caps, cmd.Code, cmd.Value, prefix+"code", prefix+"value")
+		details = append(details, problems...)
+		coerced[i] = dtos.TuyaCommandDTO{Code: code, Value: value}
+	}
+	if len(details) > 0 {
+		return nil, utils.NewValidationError("Validation Error", details)
+	}
+	return coerced, nil
+}
+
+func checkCommand(caps *entities.DeviceCapabilities, code string, value interface{}, codeField, valueField string) (string, interface{}, []utils.ValidationErrorDetail) {
+	fn, ok := caps.Lookup(code)
+	if !ok {
+		msg := "Invalid status code for this device"
+		if caps.IsReadOnly(code) {
+			msg = fmt.Sprintf("'%s' is reported by this device but cannot be set", code)
+		}
+		return code, value, []utils.ValidationErrorDetail{{Field: codeField, Message: msg}}
+	}
+
+	coerced, msg := fn.Coerce(value)
+	if msg != "" {
+		return code, value, []utils.ValidationErrorDetail{{Field: valueField, Message: msg}}
+	}
+	return fn.Code, coerced, nil
+}
+
+// usable hides specifications without functions so callers pass commands through
+func usable(caps *entities.DeviceCapabilities) *entities.DeviceCapabilities {
+	if caps == nil || len(caps.Functions) == 0 {
+		return nil
+	}
+	return caps
+}
+
+func (r *DeviceCapabilityRegistry) lookup(deviceID string) (*entities.DeviceCapabilities, bool) {
+	now := r.now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if entry, ok := r.devices[deviceID]; ok && now.Before(entry.expiresAt) {
+		return usable(entry.caps), true
+	}
+	if productID, ok := r.products[deviceID]; ok {
+		if entry, ok := r.byProduct[productID]; ok && now.Before(entry.expiresAt) {
+			return usable(entry.caps), true
+		}
+	}
+	return nil, false
+}
+
+func (r *DeviceCapabilityRegistry) store(caps *entities.DeviceCapabilities) {
+	entry := cachedCapabilities{caps: caps, expiresAt: r.now().Add(r.ttl)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.devices[caps.DeviceID] = entry
+	if caps.ProductID != "" && len(caps.Functions) > 0 {
+		r.byProduct[caps.ProductID] = entry
+	}
+}
+
+func (r *DeviceCapabilityRegistry) productOf(deviceID string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.products[deviceID]
+}
+
+// fetch calls GET /v1.0/iot-03/devices/{device_id}/specification
+func (r *DeviceCapabilityRegistry) fetch(accessToken, deviceID string) (*entities.DeviceCapabilities, error) {
+	config := utils.GetConfig()
+	urlPath := fmt.Sprintf("/v1.0/iot-03/devices/%s/specification", deviceID)
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	h := sha256.New()
+	h.Write([]byte(""))
+	contentHash := hex.EncodeToString(h.Sum(nil))
+	stringToSign := tuya_utils.GenerateTuyaStringToSign("GET", contentHash, "", urlPath)
+	signature := tuya_utils.GenerateTuyaSignature(config.TuyaClientID, config.TuyaClientSecret, accessToken, timestamp, stringToSign)
+
+	headers := map[string]string{
+		"client_id":    config.TuyaClientID,
+		"sign":         signature,
+		"t":            timestamp,
+		"sign_method":  "HMAC-SHA256",
+		"access_token": accessToken,
+	}
+
+	resp, err := r.fetcher.FetchDeviceSpecification(config.TuyaBaseURL+urlPath, headers)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("specification request failed: %s (code: %d)", resp.Msg, resp.Code)
+	}
+
+	caps := entities.ParseDeviceCapabilities(deviceID, resp.Result)
+	utils.LogDebug("DeviceCapabilityRegistry: cached %d functions for %s (category=%s)", len(caps.Functions), deviceID, caps.Category)
+	return caps, nil
+}
@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/utils"
+	"sensio/domain/tuya/dtos"
+	"sensio/domain/tuya/entities"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSpecFetcher struct {
+	spec  entities.TuyaDeviceSpecification
+	err   error
+	calls int
+}
+
+func (f *fakeSpecFetcher) FetchDeviceSpecification(url string, headers map[string]string) (*entities.TuyaDeviceSpecificationResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &entities.TuyaDeviceSpecificationResponse{Success: true, Result: f.spec}, nil
+}
+
+func dimmerSpec() entities.TuyaDeviceSpecification {
+	return entities.TuyaDeviceSpecification{
+		Category: "dj",
+		Functions: []entities.TuyaDeviceFunction{
+			{Code: "switch_led", Type: "Boolean", Values: "{}"},
+			{Code: "bright_value_v2", Type: "Integer", Values: `{"min":10,"max":1000,"scale":0,"step":1}`},
+			{Code: "work_mode", Type: "Enum", Values: `{"range":["white","colour","scene"]}`},
+			{Code: "switch1", Type: "Boolean", Values: "{}"},
+		},
+		Status: []entities.TuyaDeviceFunction{
+			{Code: "switch_led", Type: "Boolean"},
+			{Code: "battery_percentage", Type: "Integer", Values: `{"min":0,"max":100}`},
+		},
+	}
+}
+
+func TestCapabilityRegistry_ValidateCommandsCoercesValues(t *testing.T) {
+	fetcher := &fakeSpecFetcher{spec: dimmerSpec()}
+	registry := NewDeviceCapabilityRegistry(fetcher, nil, time.Hour)
+
+	commands, err := registry.ValidateCommands("token", "dev-1", []dtos.TuyaCommandDTO{
+		{Code: "switch_led", Value: "on"},
+		{Code: "bright_value_v2", Value: "500"},
+		{Code: "work_mode", Value: "Colour"},
+		{Code: "switch_1", Value: 1.0},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []dtos.TuyaCommandDTO{
+		{Code: "switch_led", Value: true},
+		{Code: "bright_value_v2", Value: int64(500)},
+		{Code: "work_mode", Value: "colour"},
+		{Code: "switch1", Value: true},
+	}, commands)
+
+	// Second validation is served from the cache
+	_, err = registry.ValidateCommands("token", "dev-1", commands)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetcher.calls)
+}
+
+func TestCapabilityRegistry_ValidateCommandsReportsFieldDetails(t *testing.T) {
+	registry := NewDeviceCapabilityRegistry(&fakeSpecFetcher{spec: dimmerSpec()}, nil, time.Hour)
+
+	_, err := registry.ValidateCommands("token", "dev-1", []dtos.TuyaCommandDTO{
+		{Code: "nuclear_launch", Value: true},
+		{Code: "bright_value_v2", Value: "full_power"},
+		{Code: "bright_value_v2", Value: 5},
+		{Code: "battery_percentage", Value: 50},
+	})
+
+	var valErr *utils.ValidationError
+	require.ErrorAs(t, err, &valErr)
+	assert.Equal(t, []utils.ValidationErrorDetail{
+		{Field: "commands[0].code", Message: "Invalid status code for this device"},
+		{Field: "commands[1].value", Message: "value for 'bright_value_v2' must be an integer"},
+		{Field: "commands[2].value", Message: "value for 'bright_value_v2' must be between 10 and 1000"},
+		{Field: "commands[3].code", Message: "'battery_percentage' is reported by this device but cannot be set"},
+	}, valErr.Details)
+}
+
+func TestCapabilityRegistry_PassesThroughWithoutSpecification(t *testing.T) {
+	commands := []dtos.TuyaCommandDTO{{Code: "anything", Value: "x"}}
+
+	empty := NewDeviceCapabilityRegistry(&fakeSpecFetcher{}, nil, time.Hour)
+	result, err := empty.ValidateCommands("token", "ir-1", commands)
+	require.NoError(t, err)
+	assert.Equal(t, commands, result)
+
+	failing := NewDeviceCapabilityRegistry(&fakeSpecFetcher{err: errors.New("timeout")}, nil, time.Hour)
+	code, value, details := failing.ValidateCommand("token", "dev-1", "anything", "x")
+	assert.Empty(t, details)
+	assert.Equal(t, "anything", code)
+	assert.Equal(t, "x", value)
+}
+
+func TestCapabilityRegistry_SharesSpecificationPerProduct(t *testing.T) {
+	fetcher := &fakeSpecFetcher{spec: dimmerSpec()}
+	registry := NewDeviceCapabilityRegistry(fetcher, nil, time.Hour)
+	registry.RememberProduct("dev-1", "prod-dimmer")
+	registry.RememberProduct("dev-2", "prod-dimmer")
+
+	_, _, details := registry.ValidateCommand("token", "dev-1", "switch_led", true)
+	require.Empty(t, details)
+	_, _, details = registry.ValidateCommand("token", "dev-2", "work_mode", "disco")
+	require.Len(t, details, 1)
+	assert.Equal(t, "value", details[0].Field)
+	assert.Equal(t, 1, fetcher.calls)
+}
@@ -23,18 +23,32 @@ type TuyaCommandSwitchUseCase interface {
 type tuyaCommandSwitchUseCase struct {
 	service       *services.TuyaDeviceService
 	deviceStateUC DeviceStateUseCase
+	capabilities  *DeviceCapabilityRegistry
 }
 
 // NewTuyaCommandSwitchUseCase initializes a new tuyaCommandSwitchUseCase.
-func NewTuyaCommandSwitchUseCase(service *services.TuyaDeviceService, deviceStateUC DeviceStateUseCase) TuyaCommandSwitchUseCase {
+// capabilities may be nil, in which case commands are sent without spec validation.
+func NewTuyaCommandSwitchUseCase(service *services.TuyaDeviceService, deviceStateUC DeviceStateUseCase, capabilities *DeviceCapabilityRegistry) TuyaCommandSwitchUseCase {
 	return &tuyaCommandSwitchUseCase{
 		service:       service,
 		deviceStateUC: deviceStateUC,
+		capabilities:  capabilities,
 	}
 }
 
 // SendSwitchCommand sends switch commands to a specific device.
+// Commands are validated and coerced against the device specification first; invalid commands
+// are rejected with a *utils.ValidationError without calling Tuya.
 func (uc *tuyaCommandSwitchUseCase) SendSwitchCommand(accessToken, deviceID string, commands []dtos.TuyaCommandDTO) (bool, error) {
+	if uc.capabilities != nil {
+		validated, err := uc.capabilities.ValidateCommands(accessToken, deviceID, commands)
+		if err != nil {
+			utils.LogWarn("SendCommand: rejected commands for %s: %v", deviceID, err)
+			return false, err
+		}
+		commands = validated
+	}
+
 	config := utils.GetConfig()
 	urlPath := fmt.Sprintf("/v1.0/iot-03/devices/%s/commands", deviceID)
 	fullURL := config.TuyaBaseURL + urlPath
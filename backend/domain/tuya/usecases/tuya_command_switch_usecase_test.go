@@ -137,6 +137,7 @@ func TestSendSwitchCommand_StateNotSavedOnResultFalse(t *testing.T) {
 	_ = NewTuyaCommandSwitchUseCase(
 		(*services.TuyaDeviceService)(nil), // We'll use mock
 		NewDeviceStateUseCase((*infrastructure.BadgerService)(nil)),
+		nil,
 	)
 
 	// Use reflection or type assertion to inject mock - for this test we'll test the logic
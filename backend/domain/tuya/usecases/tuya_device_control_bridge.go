@@ -3,6 +3,7 @@ package usecases
 import (
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/utils"
@@ -18,17 +19,26 @@ type TuyaDeviceControlExecutor interface {
 	SendIRACCommand(accessToken, infraredID, remoteID string, params map[string]int) (bool, error)
 }
 
+// LocalSwitchSender commands devices over the LAN (implemented by TuyaLocalControl)
+type LocalSwitchSender interface {
+	Reachable(deviceID string) bool
+	SendSwitchCommand(accessToken, deviceID string, commands []dtos.TuyaCommandDTO) (bool, error)
+}
+
 type tuyaDeviceControlBridge struct {
 	sendCommandUC   TuyaCommandSwitchUseCase
 	sendIRCommandUC TuyaSendIRCommandUseCase
+	local           LocalSwitchSender
 	badger          *infrastructure.BadgerService
 }
 
 // NewTuyaDeviceControlBridge creates a bridge that implements both command types.
-func NewTuyaDeviceControlBridge(sendCommandUC TuyaCommandSwitchUseCase, sendIRCommandUC TuyaSendIRCommandUseCase, badger *infrastructure.BadgerService) TuyaDeviceControlExecutor {
+// Switch commands prefer local when it is set and the device is reachable on the LAN; local may be nil.
+func NewTuyaDeviceControlBridge(sendCommandUC TuyaCommandSwitchUseCase, sendIRCommandUC TuyaSendIRCommandUseCase, local LocalSwitchSender, badger *infrastructure.BadgerService) TuyaDeviceControlExecutor {
 	return &tuyaDeviceControlBridge{
 		sendCommandUC:   sendCommandUC,
 		sendIRCommandUC: sendIRCommandUC,
+		local:           local,
 		badger:          badger,
 	}
 }
@@ -55,7 +65,7 @@ func (b *tuyaDeviceControlBridge) SendSwitchCommand(accessToken, deviceID string
 			return true, nil
 		}
 
-		success, err := b.sendCommandUC.SendSwitchCommand(accessToken, deviceID, commands)
+		success, err := b.sendSwitch(accessToken, deviceID, commands)
 		if err != nil {
 			// Transport/runtime error (network, API failure) - clear guard immediately to allow retry
 			utils.LogDebug("ControlGuard: Clearing guard due to transport error | deviceID=%s | error=%v", deviceID, err)
@@ -66,6 +76,22 @@ func (b *tuyaDeviceControlBridge) SendSwitchCommand(accessToken, deviceID string
 		}
 		return success, err
 	}
+	return b.sendSwitch(accessToken, deviceID, commands)
+}
+
+// sendSwitch tries the LAN first and falls back to the cloud unless the commands are invalid
+func (b *tuyaDeviceControlBridge) sendSwitch(accessToken, deviceID string, commands []dtos.TuyaCommandDTO) (bool, error) {
+	if b.local != nil && b.local.Reachable(deviceID) {
+		success, err := b.local.SendSwitchCommand(accessToken, deviceID, commands)
+		if err == nil && success {
+			return true, nil
+		}
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			return false, err
+		}
+		utils.LogWarn("LocalControl: falling back to cloud | deviceID=%s | error=%v", deviceID, err)
+	}
 	return b.sendCommandUC.SendSwitchCommand(accessToken, deviceID, commands)
 }
 
@@ -27,13 +27,14 @@ type tuyaGetAllDevicesUseCase struct {
 	service       *services.TuyaDeviceService
 	deviceStateUC DeviceStateUseCase
 	cache         *infrastructure.BadgerService
-	vectorSvc     *infrastructure.VectorService
+	vectorSvc     infrastructure.VectorStore
 	deviceRepo    *device_repositories.DeviceRepository
 	terminalRepo  *terminal_repositories.TerminalRepository
+	capabilities  *DeviceCapabilityRegistry
 }
 
 // NewTuyaGetAllDevicesUseCase initializes a new TuyaGetAllDevicesUseCase.
-func NewTuyaGetAllDevicesUseCase(service *services.TuyaDeviceService, deviceStateUC DeviceStateUseCase, cache *infrastructure.BadgerService, vectorSvc *infrastructure.VectorService, deviceRepo *device_repositories.DeviceRepository, terminalRepo *terminal_repositories.TerminalRepository) TuyaGetAllDevicesUseCase {
+func NewTuyaGetAllDevicesUseCase(service *services.TuyaDeviceService, deviceStateUC DeviceStateUseCase, cache *infrastructure.BadgerService, vectorSvc infrastructure.VectorStore, deviceRepo *device_repositories.DeviceRepository, terminalRepo *terminal_repositories.TerminalRepository, capabilities *DeviceCapabilityRegistry) TuyaGetAllDevicesUseCase {
 	return &tuyaGetAllDevicesUseCase{
 		service:       service,
 		deviceStateUC: deviceStateUC,
@@ -41,6 +42,7 @@ func NewTuyaGetAllDevicesUseCase(service *services.TuyaDeviceService, deviceStat
 		vectorSvc:     vectorSvc,
 		deviceRepo:    deviceRepo,
 		terminalRepo:  terminalRepo,
+		capabilities:  capabilities,
 	}
 }
 
@@ -187,6 +189,10 @@ func (uc *tuyaGetAllDevicesUseCase) GetAllDevices(accessToken, uid string, page,
 	}
 
 	for _, device := range devicesResponse.Result {
+		if uc.capabilities != nil {
+			uc.capabilities.RememberProduct(device.ID, device.ProductID)
+		}
+
 		// Use real-time status if available, fallback to list status
 		isOnline := device.Online
 		if val, ok := statusMap[device.ID]; ok {
@@ -437,7 +443,7 @@ func (uc *tuyaGetAllDevicesUseCase) populateVectorDB(uid string, resp *dtos.Tuya
 		snapshot := uc.buildAssistantSafeSnapshot(resp)
 		if aggB, err := json.Marshal(snapshot); err == nil {
 			aggID := fmt.Sprintf("tuya:devices:uid:%s", uid)
-			if err := uc.vectorSvc.Upsert(aggID, string(aggB), nil); err != nil {
+			if err := uc.vectorSvc.Put(aggID, string(aggB)); err != nil {
 				utils.LogError("populateVectorDB: failed to upsert assistant aggregate doc: %v", err)
 			} else {
 				utils.LogInfo("populateVectorDB: updated assistant aggregate with %d devices for user %s | vector_snapshot_scope=full | assistant_safe_device_count=%d", snapshot.TotalDevices, uid, snapshot.TotalDevices)
@@ -458,12 +464,22 @@ func (uc *tuyaGetAllDevicesUseCase) populateVectorDB(uid string, resp *dtos.Tuya
 		roomID := "Unknown Room"
 		hubName := "Unknown Hub"
 
+		// Metadata is used as filter by similarity queries (uid, terminal_id, category, room)
+		metadata := map[string]interface{}{
+			"kind":      "device",
+			"uid":       uid,
+			"device_id": d.ID,
+			"category":  d.Category,
+		}
+
 		// Try to enrich with DB context if repositories are available
 		if uc.deviceRepo != nil && uc.terminalRepo != nil {
 			if devEntity, err := uc.deviceRepo.GetByID(d.ID); err == nil && devEntity != nil {
+				metadata["terminal_id"] = devEntity.TerminalID
 				if hubEntity, err := uc.terminalRepo.GetByID(devEntity.TerminalID); err == nil && hubEntity != nil {
 					roomID = hubEntity.RoomID
 					hubName = hubEntity.Name
+					metadata["room"] = hubEntity.RoomID
 				}
 			}
 		}
@@ -472,7 +488,7 @@ func (uc *tuyaGetAllDevicesUseCase) populateVectorDB(uid string, resp *dtos.Tuya
 			d.Name, friendlyCategory, roomID, d.ProductName, hubName, d.ID)
 
 		dID := fmt.Sprintf("tuya:device:%s", d.ID)
-		if err := uc.vectorSvc.Upsert(dID, searchDoc, nil); err != nil {
+		if err := uc.vectorSvc.Upsert(dID, searchDoc, metadata); err != nil {
 			utils.LogError("populateVectorDB: failed to upsert device doc %s: %v", d.ID, err)
 		}
 	}
@@ -0,0 +1,167 @@
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	device_entities "sensio/domain/terminal/device/entities"
+	"sensio/domain/tuya/dtos"
+	"sensio/domain/tuya/entities"
+	"strconv"
+)
+
+const localDataPointsKeyPrefix = "tuya_local:dps:"
+
+// LocalDeviceTransport reads and writes data points over the LAN (implemented by TuyaLocalService)
+type LocalDeviceTransport interface {
+	SetDPs(endpoint entities.TuyaLocalEndpoint, localKey string, dps map[string]interface{}) error
+	GetDPs(endpoint entities.TuyaLocalEndpoint, localKey string) (map[string]interface{}, error)
+}
+
+// LocalEndpointResolver returns where a device answers locally (implemented by TuyaLocalDiscovery)
+type LocalEndpointResolver interface {
+	Lookup(deviceID string) (entities.TuyaLocalEndpoint, bool)
+}
+
+// LocalKeyLookup resolves the registered device holding the local key
+type LocalKeyLookup interface {
+	GetByID(id string) (*device_entities.Device, error)
+}
+
+// TuyaLocalControl sends commands to Wi-Fi devices over the Tuya LAN protocol, so they stay
+// controllable when the site loses internet access. A device is reachable locally when it
+// broadcast on the LAN recently and its local key is known; gateway sub-devices always go through
+// the cloud.
+//
+// Commands are mapped to data point ids with the device specification. The mapping is also kept
+// in Badger without TTL, so devices stay controllable after the specification cache expires offline.
+type TuyaLocalControl struct {
+	devices      LocalKeyLookup
+	endpoints    LocalEndpointResolver
+	transport    LocalDeviceTransport
+	capabilities *DeviceCapabilityRegistry
+	states       DeviceStateUseCase
+	cache        *infrastructure.BadgerService
+}
+
+// NewTuyaLocalControl creates a new instance of TuyaLocalControl
+func NewTuyaLocalControl(devices LocalKeyLookup, endpoints LocalEndpointResolver, transport LocalDeviceTransport, capabilities *DeviceCapabilityRegistry, states DeviceStateUseCase, cache *infrastructure.BadgerService) *TuyaLocalControl {
+	return &TuyaLocalControl{
+		devices:      devices,
+		endpoints:    endpoints,
+		transport:    transport,
+		capabilities: capabilities,
+		states:       states,
+		cache:        cache,
+	}
+}
+
+// Reachable reports whether the device can be commanded over the LAN right now
+func (c *TuyaLocalControl) Reachable(deviceID string) bool {
+	_, _, ok := c.resolve(deviceID)
+	return ok
+}
+
+// SendSwitchCommand validates the commands against the device specification and writes them
+// as data points. Validation problems are returned as a *utils.ValidationError.
+func (c *TuyaLocalControl) SendSwitchCommand(accessToken, deviceID string, commands []dtos.TuyaCommandDTO) (bool, error) {
+	endpoint, localKey, ok := c.resolve(deviceID)
+	if !ok {
+		return false, fmt.Errorf("device %s is not reachable on the LAN", deviceID)
+	}
+	if c.capabilities != nil {
+		validated, err := c.capabilities.ValidateCommands(accessToken, deviceID, commands)
+		if err != nil {
+			return false, err
+		}
+		commands = validated
+	}
+
+	ids := c.dataPoints(accessToken, deviceID)
+	dps := make(map[string]interface{}, len(commands))
+	for _, cmd := range commands {
+		id, ok := ids[cmd.Code]
+		if !ok {
+			return false, fmt.Errorf("no data point known for '%s'", cmd.Code)
+		}
+		dps[strconv.Itoa(id)] = cmd.Value
+	}
+
+	if err := c.transport.SetDPs(endpoint, localKey, dps); err != nil {
+		return false, err
+	}
+	utils.LogDebug("TuyaLocalControl: sent %d data points to %s at %s (v%s)", len(dps), deviceID, endpoint.IP, endpoint.Version)
+
+	if c.states != nil {
+		stateCommands := make([]dtos.DeviceStateCommandDTO, len(commands))
+		for i, cmd := range commands {
+			stateCommands[i] = dtos.DeviceStateCommandDTO(cmd)
+		}
+		if err := c.states.SaveDeviceState(deviceID, stateCommands); err != nil {
+			utils.LogWarn("TuyaLocalControl: failed to save device state for %s: %v", deviceID, err)
+		}
+	}
+	return true, nil
+}
+
+// GetStatus reads the current values of the device over the LAN, by status code.
+// Data points without a known code are left out.
+func (c *TuyaLocalControl) GetStatus(accessToken, deviceID string) ([]dtos.DeviceStateCommandDTO, error) {
+	endpoint, localKey, ok := c.resolve(deviceID)
+	if !ok {
+		return nil, fmt.Errorf("device %s is not reachable on the LAN", deviceID)
+	}
+	dps, err := c.transport.GetDPs(endpoint, localKey)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make(map[string]string)
+	for code, id := range c.dataPoints(accessToken, deviceID) {
+		codes[strconv.Itoa(id)] = code
+	}
+	var statuses []dtos.DeviceStateCommandDTO
+	for id, value := range dps {
+		if code, ok := codes[id]; ok {
+			statuses = append(statuses, dtos.DeviceStateCommandDTO{Code: code, Value: value})
+		}
+	}
+	return statuses, nil
+}
+
+func (c *TuyaLocalControl) resolve(deviceID string) (entities.TuyaLocalEndpoint, string, bool) {
+	endpoint, ok := c.endpoints.Lookup(deviceID)
+	if !ok {
+		return endpoint, "", false
+	}
+	device, err := c.devices.GetByID(deviceID)
+	if err != nil || device == nil || device.LocalKey == "" || device.GatewayID != "" {
+		return endpoint, "", false
+	}
+	return endpoint, device.LocalKey, true
+}
+
+// dataPoints returns the data point id of every code, from the specification or the last known mapping
+func (c *TuyaLocalControl) dataPoints(accessToken, deviceID string) map[string]int {
+	key := localDataPointsKeyPrefix + deviceID
+	var stored []byte
+	if data, err := c.cache.Get(key); err == nil {
+		stored = data
+	}
+
+	if c.capabilities != nil {
+		if caps, err := c.capabilities.GetCapabilities(accessToken, deviceID); err == nil && caps != nil && len(caps.DPIDs) > 0 {
+			if data, err := json.Marshal(caps.DPIDs); err == nil && string(data) != string(stored) {
+				_ = c.cache.SetPersistent(key, data)
+			}
+			return caps.DPIDs
+		}
+	}
+
+	ids := map[string]int{}
+	if stored != nil {
+		_ = json.Unmarshal(stored, &ids)
+	}
+	return ids
+}
@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"errors"
+	"sensio/domain/common/utils"
+	"sensio/domain/tuya/dtos"
+	"sensio/domain/tuya/entities"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEndpoints map[string]entities.TuyaLocalEndpoint
+
+func (e fakeEndpoints) Lookup(deviceID string) (entities.TuyaLocalEndpoint, bool) {
+	endpoint, ok := e[deviceID]
+	return endpoint, ok
+}
+
+type fakeLocalTransport struct {
+	sent map[string]map[string]interface{}
+	err  error
+}
+
+func (f *fakeLocalTransport) SetDPs(endpoint entities.TuyaLocalEndpoint, localKey string, dps map[string]interface{}) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent[endpoint.DeviceID] = dps
+	return nil
+}
+
+func (f *fakeLocalTransport) GetDPs(endpoint entities.TuyaLocalEndpoint, localKey string) (map[string]interface{}, error) {
+	return map[string]interface{}{"20": true, "22": 500.0, "99": "unknown"}, f.err
+}
+
+type fakeCloudSwitch struct {
+	calls []string
+}
+
+func (f *fakeCloudSwitch) SendSwitchCommand(accessToken, deviceID string, commands []dtos.TuyaCommandDTO) (bool, error) {
+	f.calls = append(f.calls, deviceID)
+	return true, nil
+}
+
+func newLocalControlFixture() (TuyaDeviceControlExecutor, *TuyaLocalControl, *fakeLocalTransport, *fakeCloudSwitch) {
+	spec := dimmerSpec()
+	spec.Functions[0].DPID = 20
+	spec.Functions[1].DPID = 22
+	registry := NewDeviceCapabilityRegistry(&fakeSpecFetcher{spec: spec}, nil, time.Hour)
+
+	devices := fakeMessageDevices{
+		"lamp":   {ID: "lamp", LocalKey: "0123456789abcdef"},
+		"zigbee": {ID: "zigbee", LocalKey: "0123456789abcdef", GatewayID: "hub"},
+		"remote": {ID: "remote", LocalKey: "0123456789abcdef"},
+	}
+	endpoints := fakeEndpoints{
+		"lamp":   {DeviceID: "lamp", IP: "192.168.1.20", Version: "3.4"},
+		"zigbee": {DeviceID: "zigbee", IP: "192.168.1.21", Version: "3.4"},
+	}
+	transport := &fakeLocalTransport{sent: map[string]map[string]interface{}{}}
+	cloud := &fakeCloudSwitch{}
+
+	local := NewTuyaLocalControl(devices, endpoints, transport, registry, nil, nil)
+	return NewTuyaDeviceControlBridge(cloud, nil, local, nil), local, transport, cloud
+}
+
+func TestDeviceControlBridge_PrefersLocalControl(t *testing.T) {
+	bridge, local, transport, cloud := newLocalControlFixture()
+
+	ok, err := bridge.SendSwitchCommand("token", "lamp", []dtos.TuyaCommandDTO{{Code: "switch_led", Value: "on"}, {Code: "bright_value_v2", Value: 500}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"20": true, "22": int64(500)}, transport.sent["lamp"])
+	assert.Empty(t, cloud.calls)
+
+	// Gateway sub-devices and devices that never broadcast go through the cloud
+	_, _ = bridge.SendSwitchCommand("token", "zigbee", []dtos.TuyaCommandDTO{{Code: "switch_led", Value: true}})
+	_, _ = bridge.SendSwitchCommand("token", "remote", []dtos.TuyaCommandDTO{{Code: "switch_led", Value: true}})
+	assert.Equal(t, []string{"zigbee", "remote"}, cloud.calls)
+
+	statuses, err := local.GetStatus("token", "lamp")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []dtos.DeviceStateCommandDTO{{Code: "switch_led", Value: true}, {Code: "bright_value_v2", Value: 500.0}}, statuses)
+}
+
+func TestDeviceControlBridge_FallsBackToCloud(t *testing.T) {
+	bridge, _, transport, cloud := newLocalControlFixture()
+
+	// Invalid commands are not retried through the cloud
+	_, err := bridge.SendSwitchCommand("token", "lamp", []dtos.TuyaCommandDTO{{Code: "bright_value_v2", Value: 5}})
+	var valErr *utils.ValidationError
+	require.ErrorAs(t, err, &valErr)
+	assert.Empty(t, cloud.calls)
+
+	// Codes without a known data point cannot be sent locally
+	ok, err := bridge.SendSwitchCommand("token", "lamp", []dtos.TuyaCommandDTO{{Code: "work_mode", Value: "white"}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"lamp"}, cloud.calls)
+
+	transport.err = errors.New("i/o timeout")
+	ok, err = bridge.SendSwitchCommand("token", "lamp", []dtos.TuyaCommandDTO{{Code: "switch_led", Value: false}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"lamp", "lamp"}, cloud.calls)
+}
@@ -0,0 +1,382 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sensio/domain/common/utils"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	terminal_entities "sensio/domain/terminal/terminal/entities"
+	"sensio/domain/tuya/dtos"
+	"sensio/domain/tuya/entities"
+	tuya_utils "sensio/domain/tuya/utils"
+	"sync"
+	"time"
+)
+
+// maxMessageReconnectDelay caps the exponential backoff between subscription attempts
+const maxMessageReconnectDelay = time.Minute
+
+// deviceListCachePrefix prefixes the device lists cached by GetAllDevices, keyed by Tuya user
+const deviceListCachePrefix = "cache:tuya:devices:"
+
+// TuyaMessageTransport delivers raw messages of the Tuya message service.
+// TuyaPulsarTransport implements it over websocket; tests use an in-memory broker.
+type TuyaMessageTransport interface {
+	Connect(ctx context.Context) error
+	Receive(ctx context.Context) (*entities.TuyaMessage, error)
+	Ack(messageID string) error
+	Close() error
+}
+
+// MessageDeviceLookup resolves the registered device of a Tuya device ID
+type MessageDeviceLookup interface {
+	GetByID(id string) (*device_entities.Device, error)
+}
+
+// MessageTerminalLookup resolves the terminal that owns a device
+type MessageTerminalLookup interface {
+	GetByID(id string) (*terminal_entities.Terminal, error)
+}
+
+// DeviceStatusStore persists the per-code device statuses (implemented by DeviceStatusRepository)
+type DeviceStatusStore interface {
+	GetByDeviceIDAndCode(deviceID, code string) (*device_status_entities.DeviceStatus, error)
+	Upsert(status *device_status_entities.DeviceStatus) error
+}
+
+// MessagePublisher publishes change events to terminals (implemented by MqttService)
+type MessagePublisher interface {
+	Publish(topic string, qos byte, retained bool, payload interface{}) error
+}
+
+// DeviceCacheInvalidator drops cached device lists so the next read reflects pushed statuses
+type DeviceCacheInvalidator interface {
+	ClearWithPrefix(prefix string) error
+}
+
+// DeviceStatusEvent is published on users/{mac}/{env}/device/status when the cloud pushes a change
+type DeviceStatusEvent struct {
+	DeviceID  string                       `json:"device_id"`
+	Statuses  []dtos.DeviceStateCommandDTO `json:"statuses,omitempty"`
+	Online    *bool                        `json:"online,omitempty"`
+	Source    string                       `json:"source"`
+	ChangedAt time.Time                    `json:"changed_at"`
+}
+
+// TuyaMessageConsumer subscribes to the Tuya message service and applies pushed device statuses.
+// Status reports update the device state, the device statuses (announced to the listener with
+// source "cloud") and the cached device lists, then are forwarded to the device's terminal over MQTT.
+type TuyaMessageConsumer struct {
+	transport TuyaMessageTransport
+	accessKey string
+	states    DeviceStateUseCase
+	statuses  DeviceStatusStore
+	devices   MessageDeviceLookup
+	terminals MessageTerminalLookup
+	mqtt      MessagePublisher
+	cache     DeviceCacheInvalidator
+	env       string
+	listener  device_status_usecases.DeviceStatusListener
+	delay     time.Duration
+
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTuyaMessageConsumer creates a new instance of TuyaMessageConsumer
+func NewTuyaMessageConsumer(
+	transport TuyaMessageTransport,
+	accessKey string,
+	states DeviceStateUseCase,
+	statuses DeviceStatusStore,
+	devices MessageDeviceLookup,
+	terminals MessageTerminalLookup,
+	mqtt MessagePublisher,
+	cache DeviceCacheInvalidator,
+	env string,
+	reconnectDelay time.Duration,
+) *TuyaMessageConsumer {
+	if reconnectDelay <= 0 {
+		reconnectDelay = 5 * time.Second
+	}
+	return &TuyaMessageConsumer{
+		transport: transport,
+		accessKey: accessKey,
+		states:    states,
+		statuses:  statuses,
+		devices:   devices,
+		terminals: terminals,
+		mqtt:      mqtt,
+		cache:     cache,
+		env:       env,
+		delay:     reconnectDelay,
+		done:      make(chan struct{}),
+	}
+}
+
+// SetStatusListener registers the listener notified for every changed status
+func (c *TuyaMessageConsumer) SetStatusListener(listener device_status_usecases.DeviceStatusListener) {
+	c.listener = listener
+}
+
+// Start subscribes in the background and reconnects with backoff until Stop is called
+func (c *TuyaMessageConsumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go func() {
+		defer close(c.done)
+		c.run(ctx)
+	}()
+	utils.LogInfo("TuyaMessageConsumer: started")
+}
+
+// Stop closes the subscription and waits for the receive loop to exit
+func (c *TuyaMessageConsumer) Stop() {
+	c.stopOnce.Do(func() {
+		if c.cancel == nil {
+			return
+		}
+		c.cancel()
+		<-c.done
+		_ = c.transport.Close()
+	})
+}
+
+func (c *TuyaMessageConsumer) run(ctx context.Context) {
+	delay := c.delay
+	for ctx.Err() == nil {
+		if err := c.transport.Connect(ctx); err != nil {
+			utils.LogWarn("TuyaMessageConsumer: %v (retrying in %s)", err, delay)
+			if !sleepContext(ctx, delay) {
+				return
+			}
+			delay = minDuration(delay*2, maxMessageReconnectDelay)
+			continue
+		}
+		delay = c.delay
+		utils.LogInfo("TuyaMessageConsumer: subscribed")
+
+		for {
+			msg, err := c.transport.Receive(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					utils.LogWarn("TuyaMessageConsumer: connection lost: %v", err)
+				}
+				break
+			}
+			if err := c.HandleMessage(msg); err != nil {
+				utils.LogWarn("TuyaMessageConsumer: dropped message %s: %v", msg.MessageID, err)
+			}
+			// Undecodable messages are acknowledged too: redelivering them would never succeed
+			if err := c.transport.Ack(msg.MessageID); err != nil {
+				utils.LogWarn("TuyaMessageConsumer: failed to ack message %s: %v", msg.MessageID, err)
+			}
+		}
+		_ = c.transport.Close()
+		if !sleepContext(ctx, delay) {
+			return
+		}
+	}
+}
+
+// HandleMessage decodes one message, verifies its signature and decrypts it, then applies it
+func (c *TuyaMessageConsumer) HandleMessage(msg *entities.TuyaMessage) error {
+	raw, err := base64.StdEncoding.DecodeString(msg.Payload)
+	if err != nil {
+		return fmt.Errorf("invalid payload encoding: %w", err)
+	}
+	var payload entities.TuyaMessagePayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	if !tuya_utils.VerifyMessageSign(payload.Data, payload.PV, payload.T, c.accessKey, payload.Sign) {
+		return fmt.Errorf("invalid payload signature")
+	}
+
+	data, err := tuya_utils.DecryptMessageData(payload.Data, c.accessKey, msg.Properties["em"])
+	if err != nil {
+		return fmt.Errorf("failed to decrypt data: %w", err)
+	}
+
+	switch payload.Protocol {
+	case entities.TuyaMessageProtocolStatus:
+		var report entities.TuyaStatusReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return fmt.Errorf("invalid status report: %w", err)
+		}
+		return c.applyStatusReport(&report)
+	case entities.TuyaMessageProtocolEvent:
+		var event entities.TuyaDeviceEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("invalid device event: %w", err)
+		}
+		c.applyDeviceEvent(&event)
+		return nil
+	default:
+		utils.LogDebug("TuyaMessageConsumer: ignoring protocol %d", payload.Protocol)
+		return nil
+	}
+}
+
+// applyStatusReport stores the reported values and forwards them to the device's terminal.
+// Devices not registered to a terminal only update the device state.
+func (c *TuyaMessageConsumer) applyStatusReport(report *entities.TuyaStatusReport) error {
+	if report.DevID == "" || len(report.Status) == 0 {
+		return nil
+	}
+
+	commands := make([]dtos.DeviceStateCommandDTO, 0, len(report.Status))
+	for _, s := range report.Status {
+		commands = append(commands, dtos.DeviceStateCommandDTO{Code: s.Code, Value: s.Value})
+	}
+	if err := c.states.SaveDeviceState(report.DevID, commands); err != nil {
+		return fmt.Errorf("failed to save device state: %w", err)
+	}
+
+	device, err := c.devices.GetByID(report.DevID)
+	if err != nil || device == nil {
+		return nil
+	}
+	terminal := c.ownerTerminal(device)
+	c.invalidateDeviceLists(terminal)
+
+	now := time.Now()
+	var changed []dtos.DeviceStateCommandDTO
+	for _, s := range report.Status {
+		value := fmt.Sprintf("%v", s.Value)
+		previous := ""
+		if existing, err := c.statuses.GetByDeviceIDAndCode(device.ID, s.Code); err == nil && existing != nil {
+			if existing.Value == value {
+				continue
+			}
+			previous = existing.Value
+		}
+		if err := c.statuses.Upsert(&device_status_entities.DeviceStatus{DeviceID: device.ID, Code: s.Code, Value: value}); err != nil {
+			return fmt.Errorf("failed to store status %s: %w", s.Code, err)
+		}
+		changed = append(changed, dtos.DeviceStateCommandDTO{Code: s.Code, Value: s.Value})
+
+		if c.listener != nil {
+			c.listener.OnDeviceStatusChanged(device_status_usecases.DeviceStatusChange{
+				TerminalID:    device.TerminalID,
+				DeviceID:      device.ID,
+				Code:          s.Code,
+				Value:         value,
+				PreviousValue: previous,
+				Source:        device_status_usecases.StatusSourceCloud,
+				ChangedAt:     now,
+			})
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	c.publish(terminal, DeviceStatusEvent{
+		DeviceID:  device.ID,
+		Statuses:  changed,
+		Source:    device_status_usecases.StatusSourceCloud,
+		ChangedAt: now,
+	})
+	return nil
+}
+
+// applyDeviceEvent forwards online/offline transitions. Devices added, removed or renamed drop
+// every cached device list; other business events are ignored.
+func (c *TuyaMessageConsumer) applyDeviceEvent(event *entities.TuyaDeviceEvent) {
+	var online bool
+	switch event.BizCode {
+	case "online":
+		online = true
+	case "offline":
+		online = false
+	case "bindUser", "delete", "nameUpdate":
+		// A new device has no terminal yet, so the list of its owner cannot be told apart
+		c.clearDeviceLists(deviceListCachePrefix)
+		return
+	default:
+		return
+	}
+
+	device, err := c.devices.GetByID(event.DevID)
+	if err != nil || device == nil {
+		return
+	}
+	terminal := c.ownerTerminal(device)
+	c.invalidateDeviceLists(terminal)
+	c.publish(terminal, DeviceStatusEvent{
+		DeviceID:  device.ID,
+		Online:    &online,
+		Source:    device_status_usecases.StatusSourceCloud,
+		ChangedAt: time.Now(),
+	})
+}
+
+// ownerTerminal returns the terminal the device is registered to, or nil
+func (c *TuyaMessageConsumer) ownerTerminal(device *device_entities.Device) *terminal_entities.Terminal {
+	if device.TerminalID == "" {
+		return nil
+	}
+	terminal, err := c.terminals.GetByID(device.TerminalID)
+	if err != nil || terminal == nil {
+		utils.LogWarn("TuyaMessageConsumer: terminal %s of device %s not found", device.TerminalID, device.ID)
+		return nil
+	}
+	return terminal
+}
+
+func (c *TuyaMessageConsumer) publish(terminal *terminal_entities.Terminal, event DeviceStatusEvent) {
+	if c.mqtt == nil || terminal == nil {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	topic := fmt.Sprintf("users/%s/%s/device/status", terminal.MacAddress, c.env)
+	if err := c.mqtt.Publish(topic, 0, false, payload); err != nil {
+		utils.LogWarn("TuyaMessageConsumer: failed to publish to %s: %v", topic, err)
+	}
+}
+
+// invalidateDeviceLists drops the cached device lists of the terminal's Tuya user; they embed
+// the statuses just pushed. Lists of other users are left alone.
+func (c *TuyaMessageConsumer) invalidateDeviceLists(terminal *terminal_entities.Terminal) {
+	if terminal == nil || terminal.TuyaUID == "" {
+		return
+	}
+	c.clearDeviceLists(fmt.Sprintf("%suid:%s:", deviceListCachePrefix, terminal.TuyaUID))
+}
+
+func (c *TuyaMessageConsumer) clearDeviceLists(prefix string) {
+	if c.cache == nil {
+		return
+	}
+	if err := c.cache.ClearWithPrefix(prefix); err != nil {
+		utils.LogWarn("TuyaMessageConsumer: failed to clear device list cache: %v", err)
+	}
+}
+
+// sleepContext waits for d and reports false when ctx was cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,251 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sensio/domain/common/infrastructure"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
+	terminal_entities "sensio/domain/terminal/terminal/entities"
+	"sensio/domain/tuya/entities"
+	tuya_utils "sensio/domain/tuya/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAccessKey = "0123456789abcdef0123456789abcdef"
+
+// fakeBroker is an in-memory message service; the first connection attempt fails
+type fakeBroker struct {
+	mu       sync.Mutex
+	queue    chan *entities.TuyaMessage
+	acked    []string
+	connects int
+}
+
+func (b *fakeBroker) Connect(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.connects++
+	if b.connects == 1 {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (b *fakeBroker) Receive(ctx context.Context) (*entities.TuyaMessage, error) {
+	select {
+	case msg := <-b.queue:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *fakeBroker) Ack(messageID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.acked = append(b.acked, messageID)
+	return nil
+}
+
+func (b *fakeBroker) Close() error { return nil }
+
+func (b *fakeBroker) ackCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.acked)
+}
+
+// fakeStatusStore keeps device statuses in memory, keyed by device and code
+type fakeStatusStore map[string]string
+
+func (s fakeStatusStore) GetByDeviceIDAndCode(deviceID, code string) (*device_status_entities.DeviceStatus, error) {
+	value, ok := s[deviceID+"/"+code]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &device_status_entities.DeviceStatus{DeviceID: deviceID, Code: code, Value: value}, nil
+}
+
+func (s fakeStatusStore) Upsert(status *device_status_entities.DeviceStatus) error {
+	s[status.DeviceID+"/"+status.Code] = status.Value
+	return nil
+}
+
+type fakeMessageDevices map[string]device_entities.Device
+
+func (d fakeMessageDevices) GetByID(id string) (*device_entities.Device, error) {
+	if dev, ok := d[id]; ok {
+		return &dev, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+type fakeMessageTerminals struct{}
+
+func (fakeMessageTerminals) GetByID(id string) (*terminal_entities.Terminal, error) {
+	return &terminal_entities.Terminal{ID: id, MacAddress: "AA:BB", TuyaUID: "u1"}, nil
+}
+
+type publishedMessage struct {
+	topic   string
+	payload []byte
+}
+
+type fakeMessagePublisher struct {
+	mu   sync.Mutex
+	sent []publishedMessage
+}
+
+func (p *fakeMessagePublisher) Publish(topic string, qos byte, retained bool, payload interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, publishedMessage{topic: topic, payload: payload.([]byte)})
+	return nil
+}
+
+type recordingListener struct {
+	mu      sync.Mutex
+	changes []device_status_usecases.DeviceStatusChange
+}
+
+func (l *recordingListener) OnDeviceStatusChanged(change device_status_usecases.DeviceStatusChange) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, change)
+}
+
+// encodeMessage builds a message the way the Tuya message service sends it
+func encodeMessage(t *testing.T, id string, protocol int, data interface{}, encryption string) *entities.TuyaMessage {
+	plain, err := json.Marshal(data)
+	require.NoError(t, err)
+	encrypted, err := tuya_utils.EncryptMessageData(plain, testAccessKey, encryption, []byte("0123456789ab"))
+	require.NoError(t, err)
+	ts := time.Now().UnixMilli()
+	sign := tuya_utils.GenerateMessageSign(encrypted, "2.0", ts, testAccessKey)
+	payload, err := json.Marshal(entities.TuyaMessagePayload{Data: encrypted, Protocol: protocol, PV: "2.0", T: ts, Sign: sign})
+	require.NoError(t, err)
+	return &entities.TuyaMessage{
+		MessageID:  id,
+		Payload:    base64.StdEncoding.EncodeToString(payload),
+		Properties: map[string]string{"em": encryption},
+	}
+}
+
+// forgeMessage builds a message encrypted with the access key but signed without it
+func forgeMessage(t *testing.T, id string, data interface{}) *entities.TuyaMessage {
+	msg := encodeMessage(t, id, entities.TuyaMessageProtocolStatus, data, tuya_utils.MessageEncryptionECB)
+	raw, err := base64.StdEncoding.DecodeString(msg.Payload)
+	require.NoError(t, err)
+	var payload entities.TuyaMessagePayload
+	require.NoError(t, json.Unmarshal(raw, &payload))
+	payload.Sign = tuya_utils.GenerateMessageSign(payload.Data, payload.PV, payload.T, "")
+	raw, err = json.Marshal(payload)
+	require.NoError(t, err)
+	msg.Payload = base64.StdEncoding.EncodeToString(raw)
+	return msg
+}
+
+func TestDecryptMessageData_RoundTrip(t *testing.T) {
+	for _, mode := range []string{tuya_utils.MessageEncryptionECB, tuya_utils.MessageEncryptionGCM} {
+		encrypted, err := tuya_utils.EncryptMessageData([]byte(`{"devId":"d1"}`), testAccessKey, mode, []byte("0123456789ab"))
+		require.NoError(t, err)
+		plain, err := tuya_utils.DecryptMessageData(encrypted, testAccessKey, mode)
+		require.NoError(t, err, mode)
+		assert.Equal(t, `{"devId":"d1"}`, string(plain), mode)
+
+		_, err = tuya_utils.DecryptMessageData(encrypted, "ffffffffffffffffffffffffffffffff", mode)
+		assert.Error(t, err, "a wrong access key must not decrypt (%s)", mode)
+	}
+	assert.Len(t, tuya_utils.GenerateMessagePassword("id", "key"), 16)
+}
+
+func TestTuyaMessageConsumer_AppliesPushedStatuses(t *testing.T) {
+	badger, err := infrastructure.NewBadgerService(t.TempDir())
+	require.NoError(t, err)
+	defer badger.Close()
+
+	statusRepo := fakeStatusStore{}
+	states := NewDeviceStateUseCase(badger)
+	require.NoError(t, badger.Set("cache:tuya:devices:uid:u1:cat::page:0:limit:0", []byte("{}")))
+	require.NoError(t, badger.Set("cache:tuya:devices:uid:u2:cat::page:0:limit:0", []byte("{}")))
+
+	broker := &fakeBroker{queue: make(chan *entities.TuyaMessage, 8)}
+	mqtt := &fakeMessagePublisher{}
+	listener := &recordingListener{}
+	devices := fakeMessageDevices{"lamp": {ID: "lamp", TerminalID: "term-1"}}
+
+	consumer := NewTuyaMessageConsumer(broker, testAccessKey, states, statusRepo, devices, fakeMessageTerminals{}, mqtt, badger, "dev", time.Millisecond)
+	consumer.SetStatusListener(listener)
+
+	report := map[string]interface{}{"devId": "lamp", "status": []map[string]interface{}{{"code": "switch_led", "value": true, "t": 1}}}
+	broker.queue <- encodeMessage(t, "m1", entities.TuyaMessageProtocolStatus, report, tuya_utils.MessageEncryptionGCM)
+	broker.queue <- encodeMessage(t, "m2", entities.TuyaMessageProtocolStatus, report, tuya_utils.MessageEncryptionECB)
+	broker.queue <- encodeMessage(t, "m3", entities.TuyaMessageProtocolEvent, map[string]interface{}{"devId": "lamp", "bizCode": "offline"}, "")
+	broker.queue <- encodeMessage(t, "m4", entities.TuyaMessageProtocolStatus, map[string]interface{}{"devId": "unknown", "status": []map[string]interface{}{{"code": "switch_1", "value": false}}}, "")
+	broker.queue <- &entities.TuyaMessage{MessageID: "m5", Payload: "not base64"}
+	broker.queue <- forgeMessage(t, "m6", map[string]interface{}{"devId": "lamp", "status": []map[string]interface{}{{"code": "switch_led", "value": false}}})
+
+	consumer.Start()
+	require.Eventually(t, func() bool { return broker.ackCount() == 6 }, 2*time.Second, 5*time.Millisecond)
+	consumer.Stop()
+
+	assert.Equal(t, []string{"m1", "m2", "m3", "m4", "m5", "m6"}, broker.acked, "undecodable messages are acknowledged too")
+	assert.GreaterOrEqual(t, broker.connects, 2, "the consumer reconnects after a failed attempt")
+
+	assert.Equal(t, fakeStatusStore{"lamp/switch_led": "true"}, statusRepo, "unregistered devices have no status rows and unsigned messages are dropped")
+
+	state, err := states.GetDeviceState("unknown")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "switch_1", state.LastCommands[0].Code, "unregistered devices still update the device state")
+
+	cached, _ := badger.Get("cache:tuya:devices:uid:u1:cat::page:0:limit:0")
+	assert.Nil(t, cached, "device lists of the owner are invalidated")
+	cached, _ = badger.Get("cache:tuya:devices:uid:u2:cat::page:0:limit:0")
+	assert.NotNil(t, cached, "device lists of other users are kept")
+
+	require.Len(t, listener.changes, 1, "the repeated value is not a change")
+	assert.Equal(t, device_status_usecases.StatusSourceCloud, listener.changes[0].Source)
+	assert.Equal(t, "term-1", listener.changes[0].TerminalID)
+
+	require.Len(t, mqtt.sent, 2)
+	assert.Equal(t, "users/AA:BB/dev/device/status", mqtt.sent[0].topic)
+	var statusEvent, onlineEvent DeviceStatusEvent
+	require.NoError(t, json.Unmarshal(mqtt.sent[0].payload, &statusEvent))
+	require.NoError(t, json.Unmarshal(mqtt.sent[1].payload, &onlineEvent))
+	assert.Equal(t, "switch_led", statusEvent.Statuses[0].Code)
+	require.NotNil(t, onlineEvent.Online)
+	assert.False(t, *onlineEvent.Online)
+}
+
+func TestTuyaMessageConsumer_VerifiesSignBeforeDecrypting(t *testing.T) {
+	consumer := NewTuyaMessageConsumer(nil, testAccessKey, nil, fakeStatusStore{}, fakeMessageDevices{}, fakeMessageTerminals{}, nil, nil, "dev", time.Millisecond)
+
+	err := consumer.HandleMessage(forgeMessage(t, "m1", map[string]interface{}{"devId": "lamp"}))
+	assert.EqualError(t, err, "invalid payload signature")
+}
+
+func TestTuyaMessageConsumer_DeviceChangesClearAllLists(t *testing.T) {
+	badger, err := infrastructure.NewBadgerService(t.TempDir())
+	require.NoError(t, err)
+	defer badger.Close()
+	require.NoError(t, badger.Set("cache:tuya:devices:uid:u1:cat::page:0:limit:0", []byte("{}")))
+	require.NoError(t, badger.Set("cache:tuya:devices:uid:u2:cat::page:0:limit:0", []byte("{}")))
+
+	consumer := NewTuyaMessageConsumer(nil, testAccessKey, nil, fakeStatusStore{}, fakeMessageDevices{}, fakeMessageTerminals{}, nil, badger, "dev", time.Millisecond)
+	require.NoError(t, consumer.HandleMessage(encodeMessage(t, "m1", entities.TuyaMessageProtocolEvent, map[string]interface{}{"devId": "new", "bizCode": "bindUser"}, "")))
+
+	for _, uid := range []string{"u1", "u2"} {
+		cached, _ := badger.Get("cache:tuya:devices:uid:" + uid + ":cat::page:0:limit:0")
+		assert.Nil(t, cached, "a bound device may belong to any user (%s)", uid)
+	}
+}
@@ -0,0 +1,380 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// Tuya LAN protocol versions
+const (
+	LocalVersion31 = "3.1"
+	LocalVersion33 = "3.3"
+	LocalVersion34 = "3.4"
+	LocalVersion35 = "3.5"
+)
+
+// Tuya LAN protocol commands
+const (
+	LocalCmdSessKeyNegStart  uint32 = 0x03
+	LocalCmdSessKeyNegResp   uint32 = 0x04
+	LocalCmdSessKeyNegFinish uint32 = 0x05
+	LocalCmdControl          uint32 = 0x07
+	LocalCmdStatus           uint32 = 0x08
+	LocalCmdHeartBeat        uint32 = 0x09
+	LocalCmdDPQuery          uint32 = 0x0a
+	LocalCmdControlNew       uint32 = 0x0d
+	LocalCmdDPQueryNew       uint32 = 0x10
+)
+
+const (
+	prefix55AA uint32 = 0x000055AA
+	suffix55AA uint32 = 0x0000AA55
+	prefix6699 uint32 = 0x00006699
+	suffix6699 uint32 = 0x00009966
+
+	header55AALen  = 16
+	header6699Len  = 18
+	gcmNonceLen    = 12
+	gcmTagLen      = 16
+	versionHdrLen  = 15 // "3.x" followed by 12 zero bytes
+	maxLocalFrame  = 64 * 1024
+	negotiationLen = 48 // remote nonce + HMAC of the local nonce
+)
+
+// ErrIncompleteFrame is returned by Decode when more bytes are needed
+var ErrIncompleteFrame = errors.New("incomplete frame")
+
+// LocalUDPKey decrypts the discovery broadcasts of 3.3+ devices: md5("yGAdlopoPVldABfn")
+var LocalUDPKey = func() []byte {
+	sum := md5.Sum([]byte("yGAdlopoPVldABfn"))
+	return sum[:]
+}()
+
+// LocalMessage is a decoded LAN protocol message
+type LocalMessage struct {
+	Seq     uint32
+	Cmd     uint32
+	RetCode uint32
+	Payload []byte // Decrypted, without version header and return code
+}
+
+// LocalCodec encodes and decodes the frames of one protocol version.
+// 3.1/3.3 use 55AA frames with CRC32, 3.4 adds HMAC-SHA256 and 3.5 uses 6699 frames with AES-GCM.
+// Key is the device local key, or the session key once a 3.4/3.5 session is negotiated.
+type LocalCodec struct {
+	Version string
+	Key     []byte
+}
+
+// Encode builds one frame for the command, encrypting the plaintext as the version requires
+func (c LocalCodec) Encode(seq, cmd uint32, plaintext []byte) ([]byte, error) {
+	switch c.Version {
+	case LocalVersion35:
+		if versionHeaderRequired(cmd) {
+			plaintext = append(c.versionHeader(), plaintext...)
+		}
+		return c.encode6699(seq, cmd, plaintext)
+	case LocalVersion34:
+		if versionHeaderRequired(cmd) {
+			plaintext = append(c.versionHeader(), plaintext...)
+		}
+		body, err := EncryptECB(c.Key, plaintext)
+		if err != nil {
+			return nil, err
+		}
+		return c.encode55AA(seq, cmd, body, true), nil
+	case LocalVersion33:
+		body, err := EncryptECB(c.Key, plaintext)
+		if err != nil {
+			return nil, err
+		}
+		if versionHeaderRequired(cmd) {
+			body = append(c.versionHeader(), body...)
+		}
+		return c.encode55AA(seq, cmd, body, false), nil
+	case LocalVersion31:
+		return c.encode55AA(seq, cmd, plaintext, false), nil
+	default:
+		return nil, fmt.Errorf("unsupported protocol version %q", c.Version)
+	}
+}
+
+// Decode parses the first frame of data. It returns the message and the number of bytes consumed,
+// or ErrIncompleteFrame when data does not yet hold a whole frame.
+func (c LocalCodec) Decode(data []byte) (*LocalMessage, int, error) {
+	if c.Version == LocalVersion35 {
+		return c.decode6699(data)
+	}
+	return c.decode55AA(data)
+}
+
+func (c LocalCodec) versionHeader() []byte {
+	hdr := make([]byte, versionHdrLen)
+	copy(hdr, c.Version)
+	return hdr
+}
+
+func (c LocalCodec) encode55AA(seq, cmd uint32, body []byte, withHMAC bool) []byte {
+	trailer := 4
+	if withHMAC {
+		trailer = sha256.Size
+	}
+	frame := make([]byte, header55AALen, header55AALen+len(body)+trailer+4)
+	binary.BigEndian.PutUint32(frame[0:], prefix55AA)
+	binary.BigEndian.PutUint32(frame[4:], seq)
+	binary.BigEndian.PutUint32(frame[8:], cmd)
+	binary.BigEndian.PutUint32(frame[12:], uint32(len(body)+trailer+4))
+	frame = append(frame, body...)
+	if withHMAC {
+		mac := hmac.New(sha256.New, c.Key)
+		mac.Write(frame)
+		frame = mac.Sum(frame)
+	} else {
+		frame = binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(frame))
+	}
+	return binary.BigEndian.AppendUint32(frame, suffix55AA)
+}
+
+func (c LocalCodec) decode55AA(data []byte) (*LocalMessage, int, error) {
+	start := bytes.Index(data, []byte{0x00, 0x00, 0x55, 0xAA})
+	if start < 0 || len(data)-start < header55AALen {
+		return nil, 0, ErrIncompleteFrame
+	}
+	data = data[start:]
+	length := int(binary.BigEndian.Uint32(data[12:]))
+	if length > maxLocalFrame {
+		return nil, 0, fmt.Errorf("frame length %d exceeds limit", length)
+	}
+	total := header55AALen + length
+	if len(data) < total {
+		return nil, 0, ErrIncompleteFrame
+	}
+	frame := data[:total]
+	if binary.BigEndian.Uint32(frame[total-4:]) != suffix55AA {
+		return nil, start + total, fmt.Errorf("invalid frame suffix")
+	}
+
+	trailer := 4
+	if c.Version == LocalVersion34 {
+		trailer = sha256.Size
+	}
+	if length < trailer+4 {
+		return nil, start + total, fmt.Errorf("frame too short")
+	}
+	bodyEnd := total - 4 - trailer
+	if c.Version == LocalVersion34 {
+		mac := hmac.New(sha256.New, c.Key)
+		mac.Write(frame[:bodyEnd])
+		if !hmac.Equal(mac.Sum(nil), frame[bodyEnd:total-4]) {
+			return nil, start + total, fmt.Errorf("HMAC mismatch")
+		}
+	} else if crc32.ChecksumIEEE(frame[:bodyEnd]) != binary.BigEndian.Uint32(frame[bodyEnd:]) {
+		return nil, start + total, fmt.Errorf("CRC mismatch")
+	}
+
+	msg := &LocalMessage{
+		Seq: binary.BigEndian.Uint32(frame[4:]),
+		Cmd: binary.BigEndian.Uint32(frame[8:]),
+	}
+	body := frame[header55AALen:bodyEnd]
+
+	// Replies carry a 4 byte return code before the (optionally version-prefixed) ciphertext
+	if c.Version != LocalVersion31 && len(body) >= 4 && body[0] == 0 && body[1] == 0 && body[2] == 0 {
+		if rest := len(body) - 4; rest%aes.BlockSize == 0 || rest%aes.BlockSize == versionHdrLen%aes.BlockSize {
+			msg.RetCode = binary.BigEndian.Uint32(body)
+			body = body[4:]
+		}
+	}
+	if c.Version == LocalVersion31 {
+		if len(body) >= 4 && body[0] == 0 && body[1] == 0 && body[2] == 0 && body[len(body)-1] == '}' {
+			msg.RetCode = binary.BigEndian.Uint32(body)
+			body = body[4:]
+		}
+		msg.Payload = body
+		return msg, start + total, nil
+	}
+
+	body = c.stripVersionHeader(body)
+	if len(body) == 0 || body[0] == '{' {
+		msg.Payload = body
+		return msg, start + total, nil
+	}
+	plain, err := DecryptECB(c.Key, body)
+	if err != nil {
+		return nil, start + total, err
+	}
+	msg.Payload = c.stripVersionHeader(plain)
+	return msg, start + total, nil
+}
+
+func (c LocalCodec) encode6699(seq, cmd uint32, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(c.Key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcmNonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, header6699Len)
+	binary.BigEndian.PutUint32(header[0:], prefix6699)
+	binary.BigEndian.PutUint32(header[6:], seq)
+	binary.BigEndian.PutUint32(header[10:], cmd)
+	binary.BigEndian.PutUint32(header[14:], uint32(gcmNonceLen+len(plaintext)+gcmTagLen))
+
+	frame := append(header, nonce...)
+	frame = gcm.Seal(frame, nonce, plaintext, header[4:])
+	return binary.BigEndian.AppendUint32(frame, suffix6699), nil
+}
+
+func (c LocalCodec) decode6699(data []byte) (*LocalMessage, int, error) {
+	start := bytes.Index(data, []byte{0x00, 0x00, 0x66, 0x99})
+	if start < 0 || len(data)-start < header6699Len {
+		return nil, 0, ErrIncompleteFrame
+	}
+	data = data[start:]
+	length := int(binary.BigEndian.Uint32(data[14:]))
+	if length > maxLocalFrame {
+		return nil, 0, fmt.Errorf("frame length %d exceeds limit", length)
+	}
+	total := header6699Len + length + 4
+	if len(data) < total {
+		return nil, 0, ErrIncompleteFrame
+	}
+	frame := data[:total]
+	if binary.BigEndian.Uint32(frame[total-4:]) != suffix6699 {
+		return nil, start + total, fmt.Errorf("invalid frame suffix")
+	}
+	if length < gcmNonceLen+gcmTagLen {
+		return nil, start + total, fmt.Errorf("frame too short")
+	}
+
+	gcm, err := newGCM(c.Key)
+	if err != nil {
+		return nil, start + total, err
+	}
+	nonce := frame[header6699Len : header6699Len+gcmNonceLen]
+	plain, err := gcm.Open(nil, nonce, frame[header6699Len+gcmNonceLen:total-4], frame[4:header6699Len])
+	if err != nil {
+		return nil, start + total, fmt.Errorf("failed to decrypt frame: %w", err)
+	}
+
+	msg := &LocalMessage{
+		Seq: binary.BigEndian.Uint32(frame[6:]),
+		Cmd: binary.BigEndian.Uint32(frame[10:]),
+	}
+	if len(plain) >= 4 && plain[0] == 0 && plain[1] == 0 && plain[2] == 0 {
+		msg.RetCode = binary.BigEndian.Uint32(plain)
+		plain = plain[4:]
+	}
+	msg.Payload = c.stripVersionHeader(plain)
+	return msg, start + total, nil
+}
+
+func (c LocalCodec) stripVersionHeader(data []byte) []byte {
+	if len(data) >= versionHdrLen && bytes.HasPrefix(data, []byte(c.Version)) {
+		return data[versionHdrLen:]
+	}
+	return data
+}
+
+// versionHeaderRequired reports whether the command payload is prefixed with the "3.x" header
+func versionHeaderRequired(cmd uint32) bool {
+	switch cmd {
+	case LocalCmdDPQuery, LocalCmdDPQueryNew, LocalCmdHeartBeat,
+		LocalCmdSessKeyNegStart, LocalCmdSessKeyNegResp, LocalCmdSessKeyNegFinish:
+		return false
+	}
+	return true
+}
+
+// NegotiationNonces splits a session key negotiation response into the device nonce and the
+// HMAC it computed over our nonce, tolerating a leading return code
+func NegotiationNonces(payload []byte) (remoteNonce, localHMAC []byte, err error) {
+	if len(payload) < negotiationLen {
+		return nil, nil, fmt.Errorf("negotiation response too short (%d bytes)", len(payload))
+	}
+	payload = payload[len(payload)-negotiationLen:]
+	return payload[:16], payload[16:], nil
+}
+
+// LocalSessionKey derives the session key of a 3.4/3.5 connection from both nonces
+func LocalSessionKey(version string, localKey, localNonce, remoteNonce []byte) ([]byte, error) {
+	if len(localNonce) != 16 || len(remoteNonce) != 16 {
+		return nil, fmt.Errorf("nonces must be 16 bytes")
+	}
+	mixed := make([]byte, 16)
+	for i := range mixed {
+		mixed[i] = localNonce[i] ^ remoteNonce[i]
+	}
+	if version == LocalVersion35 {
+		gcm, err := newGCM(localKey)
+		if err != nil {
+			return nil, err
+		}
+		return gcm.Seal(nil, localNonce[:gcmNonceLen], mixed, nil)[:16], nil
+	}
+	block, err := aes.NewCipher(localKey)
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 16)
+	block.Encrypt(key, mixed)
+	return key, nil
+}
+
+// HMACSHA256 returns the HMAC-SHA256 of data, as exchanged during session negotiation
+func HMACSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// EncryptECB encrypts data with AES-128-ECB and PKCS#7 padding
+func EncryptECB(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	pad := aes.BlockSize - len(data)%aes.BlockSize
+	padded := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	out := make([]byte, len(padded))
+	for i := 0; i < len(padded); i += aes.BlockSize {
+		block.Encrypt(out[i:i+aes.BlockSize], padded[i:i+aes.BlockSize])
+	}
+	return out, nil
+}
+
+// DecryptECB decrypts AES-128-ECB data and removes its PKCS#7 padding
+func DecryptECB(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext is not a multiple of the block size")
+	}
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Decrypt(out[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	return unpadPKCS5(out)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,162 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	automation_entities "sensio/domain/automation/entities"
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+	doorlock_entities "sensio/domain/doorlock/entities"
+	memory_entities "sensio/domain/memory/entities"
+	pipeline_entities "sensio/domain/models/pipeline/entities"
+	recordings_entities "sensio/domain/recordings/entities"
+	room_entities "sensio/domain/room/entities"
+	scene_entities "sensio/domain/scene/entities"
+	snapshot_entities "sensio/domain/snapshot/entities"
+	device_entities "sensio/domain/terminal/device/entities"
+	device_status_entities "sensio/domain/terminal/device_status/entities"
+	terminal_entities "sensio/domain/terminal/terminal/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// useTempSQLiteDB points InitDB at a fresh SQLite file when no DB_DRIVER is configured,
+// so the e2e suites run without a MySQL server. Returns false when a configured database is used.
+func useTempSQLiteDB(t *testing.T) bool {
+	if os.Getenv("DB_DRIVER") != "" {
+		return false
+	}
+	t.Setenv("GO_TEST", "true")
+	t.Setenv("DB_DRIVER", infrastructure.DBDriverSQLite)
+	t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "sensio-e2e.db"))
+	utils.AppConfig = nil
+	return true
+}
+
+// migrateTestDB applies the migrations of the repository to the initialized database
+func migrateTestDB(t *testing.T, db *gorm.DB) *infrastructure.Migrator {
+	migrator, err := infrastructure.NewMigrator(db, os.DirFS(filepath.Join("..", "migrations")), utils.GetConfig().DBDriver)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	return migrator
+}
+
+// TestStorage_CoreEntities checks that the core tables migrate and round-trip on the configured backend
+func TestStorage_CoreEntities(t *testing.T) {
+	useTempSQLiteDB(t)
+	db, err := infrastructure.InitDB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = infrastructure.CloseDB() })
+
+	migrateTestDB(t, db)
+	// mqtt_users belongs to the EMQX authentication schema, not to the migrations
+	require.NoError(t, db.AutoMigrate(&terminal_entities.MQTTUser{}))
+
+	terminal := terminal_entities.Terminal{ID: "6f1c2a3b-0000-4000-8000-000000000001", MacAddress: "AA:BB:CC:00:11:22", RoomID: "living-room", Name: "Living Room Hub"}
+	require.NoError(t, db.Create(&terminal).Error)
+	assert.Error(t, db.Create(&terminal_entities.Terminal{ID: "6f1c2a3b-0000-4000-8000-000000000002", MacAddress: terminal.MacAddress, RoomID: "x", Name: "x"}).Error,
+		"mac_address is unique")
+
+	mqttUser := terminal_entities.MQTTUser{Username: "mqtt_" + terminal.ID, Password: "hashed"}
+	require.NoError(t, db.Create(&mqttUser).Error)
+	assert.NotZero(t, mqttUser.ID, "mqtt_users ids are auto-incremented")
+
+	device := device_entities.Device{ID: "6f1c2a3b-0000-4000-8000-0000000000d1", TerminalID: terminal.ID, Name: "Lamp", RemoteID: "tuya-lamp"}
+	require.NoError(t, db.Create(&device).Error)
+	require.NoError(t, db.Create(&[]device_status_entities.DeviceStatus{
+		{DeviceID: device.ID, Code: "switch_led", Value: "true"},
+		{DeviceID: device.ID, Code: "bright_value", Value: "500"},
+	}).Error)
+	require.NoError(t, db.Save(&device_status_entities.DeviceStatus{DeviceID: device.ID, Code: "switch_led", Value: "false"}).Error)
+
+	var loaded device_entities.Device
+	require.NoError(t, db.Preload("Status").First(&loaded, "id = ?", device.ID).Error)
+	assert.Len(t, loaded.Status, 2)
+	for _, status := range loaded.Status {
+		if status.Code == "switch_led" {
+			assert.Equal(t, "false", status.Value, "statuses are keyed by device and code")
+		}
+	}
+
+	scene := scene_entities.Scene{ID: "6f1c2a3b-0000-4000-8000-0000000000s1", TerminalID: terminal.ID, Name: "Movie Night",
+		Actions: scene_entities.Actions{{DeviceID: device.ID, Code: "switch_led", Value: false}}}
+	require.NoError(t, db.Create(&scene).Error)
+	var loadedScene scene_entities.Scene
+	require.NoError(t, db.First(&loadedScene, "id = ?", scene.ID).Error)
+	assert.Equal(t, "Movie Night", loadedScene.Name)
+	assert.Len(t, loadedScene.Actions, 1)
+	require.NoError(t, db.Exec("UPDATE scenes SET actions = ? WHERE id = ?", `[{"device_id":"`+device.ID+`","value":true}]`, scene.ID).Error)
+	require.NoError(t, db.First(&loadedScene, "id = ?", scene.ID).Error, "actions written as text are read back")
+	assert.Equal(t, true, loadedScene.Actions[0].Value)
+	assert.WithinDuration(t, time.Now(), loadedScene.CreatedAt, time.Minute)
+
+	require.NoError(t, db.Delete(&terminal).Error)
+	assert.Error(t, db.First(&terminal_entities.Terminal{}, "id = ?", terminal.ID).Error, "deleted terminals are soft-deleted")
+}
+
+// TestStorage_MigrationsCoverEntities checks that the migrations create every column of the persisted
+// entities, so the schema no longer depends on AutoMigrate, and that they roll back cleanly
+func TestStorage_MigrationsCoverEntities(t *testing.T) {
+	temporary := useTempSQLiteDB(t)
+	db, err := infrastructure.InitDB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = infrastructure.CloseDB() })
+
+	migrator := migrateTestDB(t, db)
+	entities := []interface{}{
+		&terminal_entities.Terminal{},
+		&device_entities.Device{},
+		&device_status_entities.DeviceStatus{},
+		&scene_entities.Scene{},
+		&scene_entities.SceneTrigger{},
+		&scene_entities.SceneTriggerRun{},
+		&scene_entities.SceneRun{},
+		&automation_entities.AutomationRule{},
+		&doorlock_entities.DoorLockPassword{},
+		&doorlock_entities.DoorLockEvent{},
+		&doorlock_entities.GuestAccess{},
+		&snapshot_entities.HomeSnapshot{},
+		&room_entities.Room{},
+		&room_entities.Zone{},
+		&memory_entities.ConversationSession{},
+		&memory_entities.ConversationMessage{},
+		&memory_entities.MemoryFact{},
+		&recordings_entities.Recording{},
+		&pipeline_entities.Meeting{},
+		&pipeline_entities.MeetingTranscriptSegment{},
+		&pipeline_entities.MeetingActionItem{},
+		&pipeline_entities.MeetingDecision{},
+		&pipeline_entities.MeetingOpenIssue{},
+		&pipeline_entities.MeetingRisk{},
+		&pipeline_entities.MeetingSpeaker{},
+	}
+	for _, entity := range entities {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(entity))
+		require.True(t, db.Migrator().HasTable(entity), "missing table %s", stmt.Schema.Table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" {
+				assert.True(t, db.Migrator().HasColumn(entity, field.DBName), "missing column %s.%s", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+
+	// Rolling back wipes the schema, so only do it on the throwaway database
+	if !temporary {
+		return
+	}
+	_, err = migrator.To(0)
+	require.NoError(t, err)
+	for _, table := range []string{"terminal", "devices", "meetings", "rooms", "recordings"} {
+		assert.False(t, db.Migrator().HasTable(table), "table %s survives a full rollback", table)
+	}
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, len(migrator.Migrations()), applied)
+}
@@ -7,58 +7,146 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/middlewares"
+	"sensio/domain/common/utils"
+	"sensio/domain/terminal"
+	device_repositories "sensio/domain/terminal/device/repositories"
+	terminal_controllers "sensio/domain/terminal/terminal/controllers"
 	"sensio/domain/terminal/terminal/entities"
+	terminal_repositories "sensio/domain/terminal/terminal/repositories"
+	terminal_services "sensio/domain/terminal/terminal/services"
+	terminal_usecases "sensio/domain/terminal/terminal/usecases"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 )
 
+// fakeMacRegistration stands in for the external MAC registration API
+type fakeMacRegistration struct{}
+
+func (fakeMacRegistration) ProcInsertMacAddress(roomID int, macAddress string, deviceTypeID int) error {
+	return nil
+}
+
+// fakeTuyaToken lets the Bearer middleware pass without a Tuya account
+type fakeTuyaToken struct{}
+
+func (fakeTuyaToken) GetTuyaAccessToken() (string, error) {
+	return "test-tuya-token", nil
+}
+
+// newFakeMqttAuthServer serves the EMQX auth service endpoints used by the terminal bootstrap
+func newFakeMqttAuthServer() *httptest.Server {
+	var mu sync.Mutex
+	users := map[string]string{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/mqtt/create":
+			var req struct{ Username, Password string }
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if _, ok := users[req.Username]; ok {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			users[req.Username] = req.Password
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/mqtt/users/"):
+			username := strings.TrimPrefix(r.URL.Path, "/mqtt/users/")
+			password, ok := users[username]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": true,
+				"data":    map[string]string{"username": username, "password": password},
+			})
+		case r.Method == http.MethodDelete:
+			delete(users, strings.TrimPrefix(r.URL.Path, "/mqtt/"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
 // TerminalBootstrapE2ETestSuite runs end-to-end tests for the terminal bootstrap flow.
 // This tests the full flow: Register -> Get by MAC -> MQTT Credentials
 type TerminalBootstrapE2ETestSuite struct {
 	suite.Suite
 	router         *gin.Engine
 	db             *gorm.DB
+	mqttAuth       *httptest.Server
 	apiKey         string
+	bearer         string
 	testTerminalID string
 }
 
 // SetupSuite runs once before all tests in the suite.
 func (suite *TerminalBootstrapE2ETestSuite) SetupSuite() {
-	// Initialize test database
-	testDB, err := infrastructure.InitDB()
-	if err != nil {
-		suite.T().Fatalf("Failed to initialize database: %v", err)
+	t := suite.T()
+
+	// Get API key from env
+	suite.apiKey = os.Getenv("SENSIO_API_KEY")
+	if suite.apiKey == "" {
+		suite.apiKey = "test-api-key"
 	}
+	suite.mqttAuth = newFakeMqttAuthServer()
+	t.Setenv("API_KEY", suite.apiKey)
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	t.Setenv("EMQX_AUTH_BASE_URL", suite.mqttAuth.URL)
+
+	// Initialize test database: a temp SQLite file unless DB_DRIVER selects another backend
+	useTempSQLiteDB(t)
+	utils.AppConfig = nil
+	testDB, err := infrastructure.InitDB()
+	require.NoError(t, err, "Failed to initialize database")
 	suite.db = testDB
 
 	// Auto-migrate test tables
 	err = testDB.AutoMigrate(&entities.Terminal{}, &entities.MQTTUser{})
-	if err != nil {
-		suite.T().Fatalf("Failed to migrate test tables: %v", err)
-	}
+	require.NoError(t, err, "Failed to migrate test tables")
+
+	badger, err := infrastructure.NewBadgerService(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = badger.Close() })
 
-	// Initialize router
+	suite.bearer, err = utils.GenerateToken("e2e-user")
+	require.NoError(t, err)
+
+	// Register the terminal routes as main does: bootstrap behind the API key, the rest behind the Bearer token.
+	// Only the external MAC registration is replaced; MQTT credentials go to the fake auth service.
 	gin.SetMode(gin.TestMode)
 	suite.router = gin.New()
-
-	// Get API key from env
-	suite.apiKey = os.Getenv("SENSIO_API_KEY")
-	if suite.apiKey == "" {
-		suite.apiKey = "test-api-key"
-	}
+	protected := suite.router.Group("/")
+	protected.Use(middlewares.AuthMiddleware(fakeTuyaToken{}))
+
+	module := terminal.NewTerminalModule(badger, device_repositories.NewDeviceRepository(badger), nil, nil, nil, nil, nil, nil)
+	cfg := utils.GetConfig()
+	createUC := terminal_usecases.NewCreateTerminalUseCase(
+		terminal_repositories.NewTerminalRepository(badger),
+		fakeMacRegistration{},
+		terminal_services.NewMqttAuthClient(cfg.EmqxAuthBaseURL, cfg.EmqxAuthApiKey),
+	)
+	module.CreateController = terminal_controllers.NewCreateTerminalController(createUC)
+	module.RegisterRoutes(suite.router, protected)
 }
 
 // TearDownSuite runs once after all tests in the suite.
 func (suite *TerminalBootstrapE2ETestSuite) TearDownSuite() {
+	suite.mqttAuth.Close()
+
 	// Clean up test data
 	if suite.testTerminalID != "" {
-		suite.db.Delete(&entities.Terminal{}, suite.testTerminalID)
+		suite.db.Delete(&entities.Terminal{}, "id = ?", suite.testTerminalID)
 	}
 
 	// Close database connection
@@ -79,9 +167,9 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminalBootstrap_FullFlow() {
 	suite.T().Run("Step 1: Register terminal", func(t *testing.T) {
 		payload := map[string]string{
 			"mac_address":    "AA:BB:CC:DD:EE:FF",
-			"room_id":        "room-test-001",
+			"room_id":        "1",
 			"name":           "Test Terminal E2E",
-			"device_type_id": "hub-type-test",
+			"device_type_id": "1",
 		}
 
 		body, _ := json.Marshal(payload)
@@ -97,12 +185,13 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminalBootstrap_FullFlow() {
 		var response map[string]interface{}
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.True(t, response["status"].(bool))
+		assert.Equal(t, true, response["status"])
 		assert.Equal(t, "Terminal created successfully", response["message"])
 
 		// Extract terminal ID for later tests
-		data := response["data"].(map[string]interface{})
-		suite.testTerminalID = data["terminal_id"].(string)
+		data, ok := response["data"].(map[string]interface{})
+		require.True(t, ok, "data should be an object: %s", w.Body.String())
+		suite.testTerminalID, _ = data["terminal_id"].(string)
 		assert.NotEmpty(t, suite.testTerminalID)
 
 		// Verify MQTT credentials are present
@@ -123,11 +212,14 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminalBootstrap_FullFlow() {
 		var response map[string]interface{}
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.True(t, response["status"].(bool))
+		assert.Equal(t, true, response["status"])
 		assert.Equal(t, "Terminal retrieved successfully", response["message"])
 
-		// Verify response structure
-		data := response["data"].(map[string]interface{})
+		// Verify response structure: the terminal is wrapped in data.terminal
+		data, ok := response["data"].(map[string]interface{})
+		require.True(t, ok, "data should be an object: %s", w.Body.String())
+		data, ok = data["terminal"].(map[string]interface{})
+		require.True(t, ok, "data.terminal should be an object: %s", w.Body.String())
 		assert.Equal(t, suite.testTerminalID, data["id"])
 		assert.Equal(t, "AA:BB:CC:DD:EE:FF", data["mac_address"])
 		assert.Equal(t, "Test Terminal E2E", data["name"])
@@ -137,20 +229,17 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminalBootstrap_FullFlow() {
 	suite.T().Run("Step 3: Get MQTT credentials", func(t *testing.T) {
 		// First, we need to get the MQTT username from the terminal
 		terminal := &entities.Terminal{}
-		err := suite.db.First(terminal, suite.testTerminalID).Error
-		assert.NoError(t, err)
+		err := suite.db.First(terminal, "id = ?", suite.testTerminalID).Error
+		require.NoError(t, err)
 
-		mqttUsername := fmt.Sprintf("mqtt_%s", terminal.ID)
-
-		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/mqtt/users/%s", mqttUsername), nil)
-		req.Header.Set("Authorization", "Bearer test-token")
+		// The MQTT username of a terminal is its MAC address
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/mqtt/users/%s", terminal.MacAddress), nil)
+		req.Header.Set("Authorization", "Bearer "+suite.bearer)
 
 		w := httptest.NewRecorder()
 		suite.router.ServeHTTP(w, req)
 
-		// Note: This might return 404 if MQTT user wasn't created in test mode
-		// The important part is testing the endpoint structure
-		assert.NotEqual(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
 	})
 }
 
@@ -168,7 +257,7 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_UpdateTerminalResponseC
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/terminal/%s", suite.testTerminalID), bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Authorization", "Bearer "+suite.bearer)
 
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
@@ -180,12 +269,12 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_UpdateTerminalResponseC
 	assert.NoError(suite.T(), err)
 
 	// Verify response structure matches contract
-	assert.True(suite.T(), response["status"].(bool))
+	assert.Equal(suite.T(), true, response["status"])
 	assert.Equal(suite.T(), "Updated successfully", response["message"])
 
 	// Data should contain terminal information, not be null
 	data, ok := response["data"].(map[string]interface{})
-	assert.True(suite.T(), ok, "Data should be a map, not null")
+	require.True(suite.T(), ok, "Data should be a map, not null")
 	assert.Equal(suite.T(), suite.testTerminalID, data["id"])
 	assert.Equal(suite.T(), "Updated Terminal Name", data["name"])
 }
@@ -193,8 +282,18 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_UpdateTerminalResponseC
 // TestTerminal_ErrorResponses tests that error responses are consistent
 // and don't leak internal implementation details.
 func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_ErrorResponses() {
+	suite.T().Run("Register terminal without API key", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/terminal", bytes.NewReader([]byte(`{}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
 	suite.T().Run("Get non-existent terminal by MAC", func(t *testing.T) {
-		req, _ := http.NewRequest(http.MethodGet, "/api/terminal/mac/ZZ:ZZ:ZZ:ZZ:ZZ:ZZ", nil)
+		req, _ := http.NewRequest(http.MethodGet, "/api/terminal/mac/11:22:33:44:55:66", nil)
 		req.Header.Set("X-API-KEY", suite.apiKey)
 
 		w := httptest.NewRecorder()
@@ -206,7 +305,7 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_ErrorResponses() {
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
 
-		assert.False(t, response["status"].(bool))
+		assert.Equal(t, false, response["status"])
 		assert.Equal(t, "Terminal not found", response["message"])
 
 		// Ensure no internal error details are leaked
@@ -223,7 +322,7 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_ErrorResponses() {
 		body, _ := json.Marshal(payload)
 		req, _ := http.NewRequest(http.MethodPut, "/api/terminal/non-existent-id", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer test-token")
+		req.Header.Set("Authorization", "Bearer "+suite.bearer)
 
 		w := httptest.NewRecorder()
 		suite.router.ServeHTTP(w, req)
@@ -234,7 +333,7 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_ErrorResponses() {
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
 
-		assert.False(t, response["status"].(bool))
+		assert.Equal(t, false, response["status"])
 		// Should return generic "Not Found" message, not internal details
 		assert.Equal(t, "Not Found", response["message"])
 	})
@@ -245,9 +344,9 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_ValidationErrors() {
 	suite.T().Run("Invalid MAC address format", func(t *testing.T) {
 		payload := map[string]string{
 			"mac_address":    "invalid-mac",
-			"room_id":        "room-test-001",
+			"room_id":        "1",
 			"name":           "Test Terminal",
-			"device_type_id": "hub-type-test",
+			"device_type_id": "1",
 		}
 
 		body, _ := json.Marshal(payload)
@@ -264,7 +363,7 @@ func (suite *TerminalBootstrapE2ETestSuite) TestTerminal_ValidationErrors() {
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
 
-		assert.False(t, response["status"].(bool))
+		assert.Equal(t, false, response["status"])
 		assert.Equal(t, "Validation Error", response["message"])
 	})
 }
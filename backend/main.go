@@ -10,21 +10,25 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"sensio/domain/automation"
 	"sensio/domain/common"
 	"sensio/domain/common/infrastructure"
 	"sensio/domain/common/middlewares"
+	"sensio/domain/common/services"
+	"sensio/domain/common/tasks"
 	"sensio/domain/common/utils"
+	"sensio/domain/doorlock"
 	"sensio/domain/mail"
+	"sensio/domain/memory"
 	"sensio/domain/models"
 	models_v1 "sensio/domain/models-v1"
 	"sensio/domain/recordings"
-	recordings_entities "sensio/domain/recordings/entities"
+	"sensio/domain/room"
 	"sensio/domain/scene"
-	scene_entities "sensio/domain/scene/entities"
+	"sensio/domain/snapshot"
 	"sensio/domain/terminal"
-	device_entities "sensio/domain/terminal/device/entities"
 	device_repositories "sensio/domain/terminal/device/repositories"
-	terminal_entities "sensio/domain/terminal/terminal/entities"
+	device_status_usecases "sensio/domain/terminal/device_status/usecases"
 	terminal_repositories "sensio/domain/terminal/terminal/repositories"
 	"sensio/domain/tuya"
 )
@@ -75,6 +79,12 @@ import (
 
 // @tag.name 08. Common
 // @tag.description Common endpoints (Health, Cache, External APIs)
+
+// @tag.name 09. Automations
+// @tag.description Condition-based automation rules driven by device status changes
+
+// @tag.name 10. Door Locks
+// @tag.description Smart door lock control and password management
 func main() {
 	// CLI: Healthcheck
 	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
@@ -92,6 +102,14 @@ func main() {
 		os.Exit(0)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(os.Args[2:]); err != nil {
+			utils.LogError("migrate: %v", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if err := run(); err != nil {
 		utils.LogError("FATAL: %v", err)
 		os.Exit(1)
@@ -109,16 +127,10 @@ func run() error {
 	defer func() { _ = infrastructure.CloseDB() }()
 	utils.LogInfo("Database initialized successfully")
 
-	// Auto Migrate Entities
-	if err := infrastructure.DB.AutoMigrate(
-		&terminal_entities.Terminal{},
-		&device_entities.Device{},
-		&scene_entities.Scene{},
-		&recordings_entities.Recording{},
-	); err != nil {
-		return fmt.Errorf("failed to auto-migrate entities: %w", err)
+	// Apply pending schema migrations
+	if err := applyMigrations(); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
 	}
-	utils.LogInfo("Entities auto-migrated successfully")
 
 	router := gin.Default()
 	router.Use(middlewares.CorsMiddleware())
@@ -132,11 +144,29 @@ func run() error {
 		defer func() { _ = badgerService.Close() }()
 	}
 
-	// Initialize Vector DB
-	vectorService := infrastructure.NewVectorService("./tmp/vector/store.json")
+	// Initialize Vector DB (provider embeddings with local hashing fallback)
+	embedder := services.NewEmbedderFromConfig(utils.GetConfig())
+	if embedder != nil {
+		utils.LogInfo("Startup: Vector store embedder is '%s'", embedder.Name())
+	} else {
+		utils.LogInfo("Startup: Vector store uses the local hashing embedder")
+	}
+	vectorService := infrastructure.NewVectorServiceWithEmbedder("./tmp/vector/store.json", embedder)
 
 	// Initialize MQTT Service
 	mqttService := infrastructure.NewMqttService(utils.GetConfig())
+	if cfg := utils.GetConfig(); cfg.MqttOutboxEnabled && badgerService != nil {
+		// Durable outbox: publishes made while the broker is down are replayed in order on reconnect
+		outboxTTL, err := time.ParseDuration(cfg.MqttOutboxTTL)
+		if err != nil {
+			outboxTTL = 24 * time.Hour
+		}
+		retryInterval, err := time.ParseDuration(cfg.MqttOutboxRetryInterval)
+		if err != nil {
+			retryInterval = 10 * time.Second
+		}
+		mqttService.SetOutbox(infrastructure.NewMqttOutbox(badgerService, outboxTTL, cfg.MqttOutboxMaxMessages), retryInterval)
+	}
 	if err := mqttService.Connect(); err != nil {
 		utils.LogError("Warning: Failed to connect to MQTT: %v", err)
 	} else {
@@ -147,12 +177,30 @@ func run() error {
 	deviceRepo := device_repositories.NewDeviceRepository(badgerService)
 	terminalRepo := terminal_repositories.NewTerminalRepository(badgerService)
 
+	// MQTT RPC router: terminals call backend operations on users/{mac}/{env}/... and receive replies on .../answer
+	mqttRouter := infrastructure.NewMqttRpcRouter(mqttService, utils.GetConfig().ApplicationEnvironment, terminal_repositories.NewTerminalMqttResolver(terminalRepo))
+
 	// Initialize Modules
-	commonModule := common.NewCommonModule(badgerService, vectorService, mqttService, terminalRepo)
-	tuyaModule := tuya.NewTuyaModule(badgerService, vectorService, deviceRepo, terminalRepo)
-	mailModule := mail.NewMailModule(utils.GetConfig(), badgerService)
+	// Background jobs survive restarts in Badger; handlers are registered by the modules below
+	jobQueue := tasks.NewJobQueueFromConfig(badgerService, utils.GetConfig())
+
+	commonModule := common.NewCommonModule(badgerService, vectorService, mqttService, terminalRepo, jobQueue)
+	tuyaModule := tuya.NewTuyaModule(badgerService, vectorService, deviceRepo, terminalRepo, mqttService)
+	if tuyaModule.LocalDiscovery != nil {
+		tuyaModule.LocalDiscovery.Start()
+		defer tuyaModule.LocalDiscovery.Stop()
+	}
+	mailModule := mail.NewMailModule(utils.GetConfig(), badgerService, jobQueue)
 
-	terminalModule := terminal.NewTerminalModule(badgerService, deviceRepo, tuyaModule.AuthUseCase, tuyaModule.GetDeviceByIDUseCase, tuyaModule.DeviceControlUseCase)
+	// Device commands issued through the snapshot executor are journaled for "undo"
+	snapshotModule := snapshot.NewSnapshotModule(infrastructure.DB, badgerService, deviceRepo, tuyaModule.GetDeviceByIDUseCase, tuyaModule.DeviceStateUseCase, tuyaModule.CapabilityRegistry, tuyaModule.DeviceControlUseCase, tuyaModule.AuthUseCase)
+
+	memoryModule := memory.NewMemoryModule(infrastructure.DB)
+
+	// Rooms validate the room/zone assignment of terminal devices
+	roomModule := room.NewRoomModule(infrastructure.DB, deviceRepo, terminalRepo, snapshotModule.Executor, tuyaModule.CapabilityRegistry, tuyaModule.AuthUseCase)
+
+	terminalModule := terminal.NewTerminalModule(badgerService, deviceRepo, tuyaModule.AuthUseCase, tuyaModule.GetDeviceByIDUseCase, snapshotModule.Executor, tuyaModule.CapabilityRegistry, roomModule.RoomUseCase, mqttService)
 	// Register Routes
 	protected := router.Group("/")
 	protected.Use(middlewares.AuthMiddleware(tuyaModule.AuthUseCase))
@@ -174,6 +222,15 @@ func run() error {
 	// 3a. Mail Routes
 	mailModule.RegisterRoutes(protected)
 
+	// 3b. Snapshot Routes (capture, restore, undo)
+	snapshotModule.RegisterRoutes(protected)
+
+	// 3c. Conversational Memory Routes (list, export, clear)
+	memoryModule.RegisterRoutes(protected)
+
+	// 3d. Room Routes (rooms, zones, room-wide control)
+	roomModule.RegisterRoutes(protected)
+
 	// 4. Recordings Module
 	recordingsModule := recordings.NewRecordingsModule(badgerService)
 	recordingsModule.RegisterRoutes(router, protected)
@@ -230,13 +287,19 @@ func run() error {
 	models.InitModule(
 		protected,
 		scfg,
+		infrastructure.DB,
 		badgerService,
 		vectorService,
 		tuyaModule.AuthUseCase,
-		tuyaModule.DeviceControlUseCase,
+		snapshotModule.Executor,
 		mqttService,
+		mqttRouter,
 		terminalRepo,
 		recordingsModule.SaveRecordingUseCase,
+		snapshotModule.UndoUseCase,
+		memoryModule.MemoryUseCase,
+		roomModule.ControlUseCase,
+		jobQueue,
 	)
 
 	// 5b. Models-v1 Module (v1 routes: /api/models/v1/...)
@@ -244,8 +307,52 @@ func run() error {
 	models_v1.InitModule(protected, scfg)
 
 	// 6. Scene Module
-	sceneModule := scene.NewSceneModule(infrastructure.DB, tuyaModule.DeviceControlUseCase, mqttService)
+	sceneModule := scene.NewSceneModule(infrastructure.DB, badgerService, terminalRepo, snapshotModule.Executor, tuyaModule.AuthUseCase, mqttService)
+	sceneModule.ControlUseCase.SetRoomController(roomModule.ControlUseCase)
 	sceneModule.RegisterRoutes(protected)
+	sceneModule.RegisterMqttRoutes(mqttRouter)
+	if scfg.SceneSchedulerEnabled {
+		sceneModule.Scheduler.Start()
+		defer sceneModule.Scheduler.Stop()
+	}
+
+	// 7. Automation Module (rules evaluated on every device status write, API and MQTT)
+	automationModule := automation.NewAutomationModule(infrastructure.DB, badgerService, deviceRepo, sceneModule.ControlUseCase, snapshotModule.Executor, tuyaModule.AuthUseCase)
+	automationModule.RegisterRoutes(protected)
+
+	// 8. Door Lock Module (lock state lives in device statuses, so automations see lock changes).
+	// Lock commands bypass the undo journal: "undo that" must never unlock a door.
+	doorLockModule := doorlock.NewDoorLockModule(infrastructure.DB, badgerService, deviceRepo, tuyaModule.DeviceControlUseCase, tuyaModule.GetDeviceByIDUseCase, tuyaModule.AuthUseCase, terminalRepo, mqttService, mailModule.SendByMacUseCase, mailModule.Service)
+	doorLockModule.RegisterRoutes(protected)
+	if scfg.DoorLockSyncEnabled {
+		doorLockModule.SyncWorker.Start()
+		defer doorLockModule.SyncWorker.Stop()
+	}
+	if doorLockModule.GuestWorker != nil {
+		doorLockModule.GuestWorker.Start()
+		defer doorLockModule.GuestWorker.Stop()
+	}
+
+	// Every status write (API, MQTT, lock commands, lock polling, cloud pushes) feeds automations and the lock event log
+	statusListeners := device_status_usecases.DeviceStatusListeners{automationModule.Engine, doorLockModule.EventUseCase}
+	terminalModule.SetDeviceStatusListener(statusListeners)
+	doorLockModule.LockUseCase.SetStatusListener(statusListeners)
+	if doorLockModule.EventPoller != nil {
+		doorLockModule.EventPoller.SetStatusListener(statusListeners)
+		doorLockModule.EventPoller.Start()
+		defer doorLockModule.EventPoller.Stop()
+	}
+	if tuyaModule.MessageConsumer != nil {
+		tuyaModule.MessageConsumer.SetStatusListener(statusListeners)
+		tuyaModule.MessageConsumer.Start()
+		defer tuyaModule.MessageConsumer.Stop()
+	}
+	terminalModule.StartMqttSubscription()
+	terminalModule.RegisterMqttRoutes(mqttRouter)
+
+	// Every task type has registered its handler by now: resume jobs restored from Badger
+	jobQueue.Start()
+	defer jobQueue.Stop()
 
 	// Register Health at the end so it appears last in Swagger
 	router.GET("/api/health", commonModule.HealthController.CheckHealth)
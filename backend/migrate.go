@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"sensio/domain/common/infrastructure"
+	"sensio/domain/common/utils"
+)
+
+const migrateUsage = "usage: migrate up|down|status|to N|force N"
+
+// legacyBaselineVersion is the schema of databases created before versions were tracked: migrations
+// 1 to 6 were applied by hand and start-up only auto-migrated the terminal, device, scene and recording tables
+const legacyBaselineVersion = 6
+
+// adoptLegacySchema sets a database with tables but no schema version to legacyBaselineVersion
+func adoptLegacySchema(migrator *infrastructure.Migrator) error {
+	adopted, err := migrator.AdoptBaseline(legacyBaselineVersion, "terminal")
+	if err != nil {
+		return err
+	}
+	if adopted {
+		utils.LogInfo("Database has tables but no schema version, recorded as version %d", legacyBaselineVersion)
+	}
+	return nil
+}
+
+// runMigrateCommand implements the `migrate` subcommand of the server binary
+func runMigrateCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	switch args[0] {
+	case "up", "down", "status", "to", "force":
+	default:
+		return errors.New(migrateUsage)
+	}
+	utils.LoadConfig()
+	if _, err := infrastructure.InitDB(); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer func() { _ = infrastructure.CloseDB() }()
+
+	migrator, err := infrastructure.NewDefaultMigrator()
+	if err != nil {
+		return err
+	}
+
+	parseVersion := func() (int, error) {
+		if len(args) != 2 {
+			return 0, errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return 0, fmt.Errorf("invalid version %q", args[1])
+		}
+		return version, nil
+	}
+
+	if args[0] == "up" || args[0] == "to" {
+		if err := adoptLegacySchema(migrator); err != nil {
+			return err
+		}
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		if err := migrator.Down(); err != nil {
+			return err
+		}
+		fmt.Println("Rolled back 1 migration")
+	case "to":
+		version, err := parseVersion()
+		if err != nil {
+			return err
+		}
+		count, err := migrator.To(version)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Ran %d migration(s), now at version %d\n", count, version)
+	case "force":
+		version, err := parseVersion()
+		if err != nil {
+			return err
+		}
+		if err := migrator.Force(version); err != nil {
+			return err
+		}
+		fmt.Printf("Schema version set to %d\n", version)
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Version: %d (latest %d)\n", status.Version, status.Latest)
+		if status.Dirty {
+			fmt.Println("Dirty:   yes, repair the schema and run `migrate force N`")
+		}
+		for _, migration := range status.Pending {
+			fmt.Printf("Pending: %06d_%s\n", migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// applyMigrations brings the schema to the latest migration at startup. It refuses to start on a dirty
+// schema. A database whose tables predate version tracking is first recorded at legacyBaselineVersion,
+// so only the later migrations run.
+func applyMigrations() error {
+	migrator, err := infrastructure.NewDefaultMigrator()
+	if err != nil {
+		return err
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w at version %d: repair the schema, then run `migrate force %d`", infrastructure.ErrDirtyDatabase, status.Version, status.Version)
+	}
+	if err := adoptLegacySchema(migrator); err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		utils.LogInfo("Applied %d database migration(s), schema at version %d", applied, status.Latest)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+-- Drop meeting artifact tables (children first)
+DROP TABLE IF EXISTS meeting_risks;
+DROP TABLE IF EXISTS meeting_open_issues;
+DROP TABLE IF EXISTS meeting_decisions;
+DROP TABLE IF EXISTS meeting_action_items;
+DROP TABLE IF EXISTS meeting_transcript_segments;
+DROP TABLE IF EXISTS meetings;
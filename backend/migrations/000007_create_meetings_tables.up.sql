@@ -0,0 +1,98 @@
+-- Create meetings table (one row per pipeline job)
+CREATE TABLE IF NOT EXISTS meetings (
+    id CHAR(36) PRIMARY KEY,
+    mac_address VARCHAR(255),
+    title VARCHAR(255),
+    language VARCHAR(16),
+    target_language VARCHAR(16),
+    context TEXT,
+    style VARCHAR(64),
+    meeting_date VARCHAR(64),
+    location VARCHAR(255),
+    participants TEXT,
+    status VARCHAR(32),
+    transcript_format VARCHAR(32),
+    transcription LONGTEXT,
+    refined_text LONGTEXT,
+    translated_text LONGTEXT,
+    summary LONGTEXT,
+    summary_mode VARCHAR(64),
+    pdf_url VARCHAR(512),
+    canonical_summary LONGTEXT,
+    duration_seconds DOUBLE,
+    started_at TIMESTAMP NULL DEFAULT NULL,
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_meetings_mac_address ON meetings(mac_address);
+CREATE INDEX idx_meetings_status ON meetings(status);
+CREATE INDEX idx_meetings_deleted_at ON meetings(deleted_at);
+
+-- Create meeting_transcript_segments table
+CREATE TABLE IF NOT EXISTS meeting_transcript_segments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    speaker_label VARCHAR(255),
+    start_ms BIGINT,
+    end_ms BIGINT,
+    text TEXT,
+    confidence DOUBLE,
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_transcript_segments_meeting_id ON meeting_transcript_segments(meeting_id);
+
+-- Create meeting_action_items table
+CREATE TABLE IF NOT EXISTS meeting_action_items (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    task TEXT,
+    pic VARCHAR(255),
+    deadline VARCHAR(255),
+    status VARCHAR(64),
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_action_items_meeting_id ON meeting_action_items(meeting_id);
+
+-- Create meeting_decisions table
+CREATE TABLE IF NOT EXISTS meeting_decisions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    description TEXT,
+    rationale TEXT,
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_decisions_meeting_id ON meeting_decisions(meeting_id);
+
+-- Create meeting_open_issues table
+CREATE TABLE IF NOT EXISTS meeting_open_issues (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    description TEXT,
+    owner VARCHAR(255),
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_open_issues_meeting_id ON meeting_open_issues(meeting_id);
+
+-- Create meeting_risks table
+CREATE TABLE IF NOT EXISTS meeting_risks (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    description TEXT,
+    impact VARCHAR(64),
+    mitigation TEXT,
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_risks_meeting_id ON meeting_risks(meeting_id);
@@ -0,0 +1,93 @@
+-- SQLite variant of 000007_create_meetings_tables.up.sql (AUTOINCREMENT primary keys)
+CREATE TABLE IF NOT EXISTS meetings (
+    id CHAR(36) PRIMARY KEY,
+    mac_address VARCHAR(255),
+    title VARCHAR(255),
+    language VARCHAR(16),
+    target_language VARCHAR(16),
+    context TEXT,
+    style VARCHAR(64),
+    meeting_date VARCHAR(64),
+    location VARCHAR(255),
+    participants TEXT,
+    status VARCHAR(32),
+    transcript_format VARCHAR(32),
+    transcription TEXT,
+    refined_text TEXT,
+    translated_text TEXT,
+    summary TEXT,
+    summary_mode VARCHAR(64),
+    pdf_url VARCHAR(512),
+    canonical_summary TEXT,
+    duration_seconds DOUBLE,
+    started_at TIMESTAMP NULL DEFAULT NULL,
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_meetings_mac_address ON meetings(mac_address);
+CREATE INDEX idx_meetings_status ON meetings(status);
+CREATE INDEX idx_meetings_deleted_at ON meetings(deleted_at);
+
+CREATE TABLE IF NOT EXISTS meeting_transcript_segments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    speaker_label VARCHAR(255),
+    start_ms BIGINT,
+    end_ms BIGINT,
+    text TEXT,
+    confidence DOUBLE,
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_transcript_segments_meeting_id ON meeting_transcript_segments(meeting_id);
+
+CREATE TABLE IF NOT EXISTS meeting_action_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    task TEXT,
+    pic VARCHAR(255),
+    deadline VARCHAR(255),
+    status VARCHAR(64),
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_action_items_meeting_id ON meeting_action_items(meeting_id);
+
+CREATE TABLE IF NOT EXISTS meeting_decisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    description TEXT,
+    rationale TEXT,
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_decisions_meeting_id ON meeting_decisions(meeting_id);
+
+CREATE TABLE IF NOT EXISTS meeting_open_issues (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    description TEXT,
+    owner VARCHAR(255),
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_open_issues_meeting_id ON meeting_open_issues(meeting_id);
+
+CREATE TABLE IF NOT EXISTS meeting_risks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    meeting_id CHAR(36) NOT NULL,
+    position INT NOT NULL,
+    description TEXT,
+    impact VARCHAR(64),
+    mitigation TEXT,
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_risks_meeting_id ON meeting_risks(meeting_id);
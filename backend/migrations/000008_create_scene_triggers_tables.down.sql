@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS scene_trigger_runs;
+DROP TABLE IF EXISTS scene_triggers;
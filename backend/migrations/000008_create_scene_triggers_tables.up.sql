@@ -0,0 +1,42 @@
+-- Create scene_triggers table (scheduled scene execution)
+CREATE TABLE IF NOT EXISTS scene_triggers (
+    id CHAR(36) PRIMARY KEY,
+    terminal_id CHAR(36) NOT NULL,
+    scene_id CHAR(36) NOT NULL,
+    name VARCHAR(255),
+    type VARCHAR(16) NOT NULL,
+    cron_expr VARCHAR(128),
+    sun_event VARCHAR(16),
+    latitude DOUBLE,
+    longitude DOUBLE,
+    offset_minutes BIGINT,
+    delay_seconds BIGINT,
+    timezone VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    next_run_at TIMESTAMP NULL DEFAULT NULL,
+    last_run_at TIMESTAMP NULL DEFAULT NULL,
+    last_status VARCHAR(16),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_scene_triggers_terminal_id ON scene_triggers(terminal_id);
+CREATE INDEX idx_scene_triggers_scene_id ON scene_triggers(scene_id);
+CREATE INDEX idx_scene_triggers_next_run_at ON scene_triggers(next_run_at);
+CREATE INDEX idx_scene_triggers_deleted_at ON scene_triggers(deleted_at);
+
+-- Create scene_trigger_runs table (outcome of each scheduled execution)
+CREATE TABLE IF NOT EXISTS scene_trigger_runs (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    trigger_id CHAR(36) NOT NULL,
+    scene_id CHAR(36) NOT NULL,
+    terminal_id CHAR(36) NOT NULL,
+    scheduled_at TIMESTAMP NULL DEFAULT NULL,
+    started_at TIMESTAMP NULL DEFAULT NULL,
+    finished_at TIMESTAMP NULL DEFAULT NULL,
+    status VARCHAR(16) NOT NULL,
+    error TEXT
+);
+
+CREATE INDEX idx_scene_trigger_runs_trigger_id ON scene_trigger_runs(trigger_id);
@@ -0,0 +1,19 @@
+-- Create automation_rules table (condition-based automations on device status changes)
+CREATE TABLE IF NOT EXISTS automation_rules (
+    id CHAR(36) PRIMARY KEY,
+    terminal_id CHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    conditions TEXT NOT NULL,
+    actions TEXT NOT NULL,
+    debounce_seconds BIGINT,
+    cooldown_seconds BIGINT,
+    last_fired_at TIMESTAMP NULL DEFAULT NULL,
+    last_result TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_automation_rules_terminal_id ON automation_rules(terminal_id);
+CREATE INDEX idx_automation_rules_deleted_at ON automation_rules(deleted_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS scene_runs;
+ALTER TABLE scenes DROP COLUMN error_policy;
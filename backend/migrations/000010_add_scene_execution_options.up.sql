@@ -0,0 +1,20 @@
+-- Scene error policy and per-run execution history
+ALTER TABLE scenes ADD COLUMN error_policy VARCHAR(16);
+
+CREATE TABLE IF NOT EXISTS scene_runs (
+    id CHAR(36) PRIMARY KEY,
+    scene_id CHAR(36) NOT NULL,
+    terminal_id CHAR(36) NOT NULL,
+    scene_name VARCHAR(255),
+    source VARCHAR(16),
+    status VARCHAR(16) NOT NULL,
+    stopped BOOLEAN,
+    results TEXT,
+    started_at TIMESTAMP NULL DEFAULT NULL,
+    finished_at TIMESTAMP NULL DEFAULT NULL,
+    duration_ms BIGINT
+);
+
+CREATE INDEX idx_scene_runs_scene_id ON scene_runs(scene_id);
+CREATE INDEX idx_scene_runs_terminal_id ON scene_runs(terminal_id);
+CREATE INDEX idx_scene_runs_started_at ON scene_runs(started_at);
@@ -0,0 +1,4 @@
+ALTER TABLE devices DROP COLUMN zone_id;
+ALTER TABLE devices DROP COLUMN room_id;
+DROP TABLE IF EXISTS zones;
+DROP TABLE IF EXISTS rooms;
@@ -0,0 +1,7 @@
+-- SQLite cannot drop an indexed column, so the indexes go first
+DROP INDEX IF EXISTS idx_devices_zone_id;
+DROP INDEX IF EXISTS idx_devices_room_id;
+ALTER TABLE devices DROP COLUMN zone_id;
+ALTER TABLE devices DROP COLUMN room_id;
+DROP TABLE IF EXISTS zones;
+DROP TABLE IF EXISTS rooms;
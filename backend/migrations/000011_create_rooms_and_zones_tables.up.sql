@@ -0,0 +1,28 @@
+-- Create rooms table
+CREATE TABLE IF NOT EXISTS rooms (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_rooms_name ON rooms(name);
+
+-- Create zones table (named areas inside a room)
+CREATE TABLE IF NOT EXISTS zones (
+    id CHAR(36) PRIMARY KEY,
+    room_id VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_zone_room_name ON zones(room_id, name);
+
+-- Device placement; an empty room_id means the room of the terminal
+ALTER TABLE devices ADD COLUMN room_id VARCHAR(255);
+ALTER TABLE devices ADD COLUMN zone_id CHAR(36);
+
+CREATE INDEX idx_devices_room_id ON devices(room_id);
+CREATE INDEX idx_devices_zone_id ON devices(zone_id);
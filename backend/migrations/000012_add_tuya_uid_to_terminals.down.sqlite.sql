@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_terminal_tuya_uid;
+ALTER TABLE terminal DROP COLUMN tuya_uid;
@@ -0,0 +1,3 @@
+ALTER TABLE terminal ADD COLUMN tuya_uid VARCHAR(255);
+
+CREATE INDEX idx_terminal_tuya_uid ON terminal(tuya_uid);
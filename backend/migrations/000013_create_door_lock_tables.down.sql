@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS door_lock_guest_accesses;
+DROP TABLE IF EXISTS door_lock_events;
+DROP TABLE IF EXISTS door_lock_passwords;
@@ -0,0 +1,75 @@
+-- Create door_lock_passwords table (temporary passwords synced to Tuya locks)
+CREATE TABLE IF NOT EXISTS door_lock_passwords (
+    id CHAR(36) PRIMARY KEY,
+    terminal_id CHAR(36) NOT NULL,
+    device_id VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    type VARCHAR(20) NOT NULL,
+    value VARCHAR(32) NOT NULL,
+    valid_minutes BIGINT,
+    effective_at DATETIME NULL DEFAULT NULL,
+    expire_at DATETIME NULL DEFAULT NULL,
+    status VARCHAR(20) NOT NULL,
+    remote_password_id VARCHAR(64),
+    retry_count BIGINT,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_door_lock_passwords_terminal_id ON door_lock_passwords(terminal_id);
+CREATE INDEX idx_door_lock_passwords_device_id ON door_lock_passwords(device_id);
+CREATE INDEX idx_door_lock_passwords_expire_at ON door_lock_passwords(expire_at);
+CREATE INDEX idx_door_lock_passwords_status ON door_lock_passwords(status);
+CREATE INDEX idx_door_lock_passwords_deleted_at ON door_lock_passwords(deleted_at);
+
+-- Create door_lock_events table (unlock and alarm history)
+CREATE TABLE IF NOT EXISTS door_lock_events (
+    id CHAR(36) PRIMARY KEY,
+    terminal_id CHAR(36) NOT NULL,
+    device_id VARCHAR(64) NOT NULL,
+    code VARCHAR(64) NOT NULL,
+    value VARCHAR(255),
+    category VARCHAR(20) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    unlock_method VARCHAR(32),
+    user_id VARCHAR(64),
+    alarm_type VARCHAR(64),
+    source VARCHAR(20) NOT NULL,
+    notified BOOLEAN,
+    occurred_at DATETIME NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_door_lock_events_terminal_id ON door_lock_events(terminal_id);
+CREATE INDEX idx_door_lock_events_device_time ON door_lock_events(device_id, occurred_at);
+CREATE INDEX idx_door_lock_events_category ON door_lock_events(category);
+
+-- Create door_lock_guest_accesses table (guest stays with a generated password)
+CREATE TABLE IF NOT EXISTS door_lock_guest_accesses (
+    id CHAR(36) PRIMARY KEY,
+    terminal_id CHAR(36) NOT NULL,
+    device_id VARCHAR(64) NOT NULL,
+    room_id VARCHAR(255),
+    guest_name VARCHAR(255) NOT NULL,
+    guest_email VARCHAR(255) NOT NULL,
+    check_in_at DATETIME NOT NULL,
+    check_out_at DATETIME NOT NULL,
+    password_id CHAR(36) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    email_sent BOOLEAN,
+    email_attempts BIGINT,
+    revoke_attempts BIGINT,
+    last_error TEXT,
+    revoked_at DATETIME NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_door_lock_guest_accesses_terminal_id ON door_lock_guest_accesses(terminal_id);
+CREATE INDEX idx_door_lock_guest_accesses_device_id ON door_lock_guest_accesses(device_id);
+CREATE INDEX idx_door_lock_guest_accesses_check_out_at ON door_lock_guest_accesses(check_out_at);
+CREATE INDEX idx_door_lock_guest_accesses_status ON door_lock_guest_accesses(status);
+CREATE INDEX idx_door_lock_guest_accesses_deleted_at ON door_lock_guest_accesses(deleted_at);
@@ -0,0 +1,12 @@
+-- Create home_snapshots table (named captures of device states)
+CREATE TABLE IF NOT EXISTS home_snapshots (
+    id CHAR(36) PRIMARY KEY,
+    terminal_id CHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    devices TEXT NOT NULL,
+    skipped BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_home_snapshot_name ON home_snapshots(terminal_id, name);
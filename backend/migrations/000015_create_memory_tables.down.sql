@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS memory_facts;
+DROP TABLE IF EXISTS conversation_messages;
+DROP TABLE IF EXISTS conversation_sessions;
@@ -0,0 +1,39 @@
+-- Create conversation_sessions table (one rolling session per terminal and user)
+CREATE TABLE IF NOT EXISTS conversation_sessions (
+    id CHAR(36) PRIMARY KEY,
+    terminal_id VARCHAR(64) NOT NULL,
+    uid VARCHAR(64) NOT NULL DEFAULT '',
+    summary TEXT,
+    message_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_conversation_session ON conversation_sessions(terminal_id, uid);
+
+-- Create conversation_messages table
+CREATE TABLE IF NOT EXISTS conversation_messages (
+    id CHAR(36) PRIMARY KEY,
+    session_id CHAR(36) NOT NULL,
+    seq BIGINT NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    content TEXT NOT NULL,
+    summarized BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_conversation_message_seq ON conversation_messages(session_id, seq);
+
+-- Create memory_facts table (long-term facts remembered per terminal and user)
+CREATE TABLE IF NOT EXISTS memory_facts (
+    id CHAR(36) PRIMARY KEY,
+    terminal_id VARCHAR(64) NOT NULL,
+    uid VARCHAR(64) NOT NULL DEFAULT '',
+    fact_key VARCHAR(100) NOT NULL,
+    value TEXT NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_memory_fact_key ON memory_facts(terminal_id, uid, fact_key);
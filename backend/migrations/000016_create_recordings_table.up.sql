@@ -0,0 +1,11 @@
+-- Create recordings table (metadata of uploaded audio; Badger only caches lookups)
+CREATE TABLE IF NOT EXISTS recordings (
+    id VARCHAR(191) PRIMARY KEY,
+    filename VARCHAR(255),
+    original_name VARCHAR(255),
+    audio_url VARCHAR(512),
+    mac_address VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_recordings_mac_address ON recordings(mac_address);
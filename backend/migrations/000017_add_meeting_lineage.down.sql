@@ -0,0 +1,2 @@
+ALTER TABLE meetings DROP COLUMN rerun_from_stage;
+ALTER TABLE meetings DROP COLUMN parent_id;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_meetings_parent_id;
+ALTER TABLE meetings DROP COLUMN rerun_from_stage;
+ALTER TABLE meetings DROP COLUMN parent_id;
@@ -0,0 +1,5 @@
+-- Pipeline reruns: the meeting a run was re-executed from and its first re-executed stage
+ALTER TABLE meetings ADD COLUMN parent_id CHAR(36);
+ALTER TABLE meetings ADD COLUMN rerun_from_stage VARCHAR(32);
+
+CREATE INDEX idx_meetings_parent_id ON meetings(parent_id);
@@ -0,0 +1,13 @@
+-- Participant names mapped to the diarized speaker labels of a meeting
+CREATE TABLE IF NOT EXISTS meeting_speakers (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    meeting_id CHAR(36) NOT NULL,
+    speaker_label VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    source VARCHAR(32),
+    confidence DOUBLE,
+    evidence TEXT,
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_speakers_meeting_id ON meeting_speakers(meeting_id);
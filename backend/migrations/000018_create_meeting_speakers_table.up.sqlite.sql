@@ -0,0 +1,13 @@
+-- SQLite variant of 000018_create_meeting_speakers_table.up.sql (AUTOINCREMENT primary key)
+CREATE TABLE IF NOT EXISTS meeting_speakers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    meeting_id CHAR(36) NOT NULL,
+    speaker_label VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    source VARCHAR(32),
+    confidence DOUBLE,
+    evidence TEXT,
+    FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meeting_speakers_meeting_id ON meeting_speakers(meeting_id);
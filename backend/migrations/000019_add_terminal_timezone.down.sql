@@ -0,0 +1 @@
+ALTER TABLE terminal DROP COLUMN timezone;
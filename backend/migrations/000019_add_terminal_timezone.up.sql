@@ -0,0 +1,2 @@
+-- IANA timezone of the terminal, used to schedule its scene triggers
+ALTER TABLE terminal ADD COLUMN timezone VARCHAR(64);
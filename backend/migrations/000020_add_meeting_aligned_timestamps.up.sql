@@ -0,0 +1,2 @@
+-- Whether the stored segment times come from the audio (e.g. whisper.cpp) rather than text length
+ALTER TABLE meetings ADD COLUMN aligned_timestamps BOOLEAN NOT NULL DEFAULT FALSE;